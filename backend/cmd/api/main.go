@@ -2,18 +2,21 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Schedule series timezones must resolve in slim images
 
 	"fitness-training-backend/internal/config"
 	"fitness-training-backend/internal/database"
 	"fitness-training-backend/internal/middleware"
+	"fitness-training-backend/internal/realtime"
+	"fitness-training-backend/internal/repository"
 	"fitness-training-backend/internal/routes"
+	"fitness-training-backend/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
@@ -51,6 +54,12 @@ func main() {
 		}
 	}
 
+	// Connect the notification stream pub/sub
+	if err := realtime.Connect(cfg.Realtime, database.DB, cfg.GetDSN()); err != nil {
+		log.Fatal("❌ Failed to start realtime broker:", err)
+	}
+	log.Printf("📡 Realtime broker: %s", cfg.Realtime.Broker)
+
 	// Initialize Gin router
 	router := gin.New()
 
@@ -67,8 +76,54 @@ func main() {
 		})
 	})
 
+	// Media storage
+	mediaStore, err := service.NewMediaStorage(cfg)
+	if err != nil {
+		log.Fatal("❌ Failed to set up media storage:", err)
+	}
+	log.Printf("🗂️  Media storage: %s", cfg.Media.Storage)
+
 	// Setup routes
-	routes.SetupRoutes(router, cfg)
+	routes.SetupRoutes(router, cfg, mediaStore)
+
+	// Start background workers
+	reminders := service.NewReminderDispatcher(repository.NewScheduleRepository(database.DB), cfg.Reminder)
+	if cfg.Reminder.Enabled {
+		reminders.Start()
+		log.Printf("🔔 Session reminders enabled (lead times %v, every %s)", cfg.Reminder.LeadTimes, cfg.Reminder.Interval)
+	}
+
+	programProgress := service.NewProgramProgressWorker(repository.NewProgramRepository(database.DB), cfg.ProgramProgress)
+	if cfg.ProgramProgress.Enabled {
+		programProgress.Start()
+		log.Printf("📈 Program progress tracking enabled (every %s)", cfg.ProgramProgress.Interval)
+	}
+
+	mediaCleanup := service.NewMediaCleanupWorker(repository.NewMediaRepository(database.DB), mediaStore, cfg.Media)
+	if cfg.Media.CleanupEnabled {
+		mediaCleanup.Start()
+		log.Printf("🧹 Media cleanup enabled (orphans after %s, every %s)", cfg.Media.OrphanTTL, cfg.Media.CleanupInterval)
+	}
+
+	senders, err := service.NewDeliverySenders(cfg.Delivery)
+	if err != nil {
+		log.Fatal("❌ Invalid notification channel configuration:", err)
+	}
+	delivery := service.NewDeliveryWorker(
+		repository.NewNotificationDeliveryRepository(database.DB),
+		repository.NewPushSubscriptionRepository(database.DB),
+		repository.NewNotificationSettingsRepository(database.DB),
+		senders,
+		cfg,
+	)
+	if cfg.Delivery.Enabled {
+		delivery.Start()
+		channels := make([]string, 0, len(senders))
+		for _, sender := range senders {
+			channels = append(channels, sender.Channel())
+		}
+		log.Printf("📨 Notification delivery enabled (channels %v, every %s)", channels, cfg.Delivery.Interval)
+	}
 
 	// Create HTTP server
 	srv := &http.Server{
@@ -99,6 +154,17 @@ func main() {
 
 	log.Println("🛑 Shutting down server...")
 
+	// Let a reminder run and sends in progress finish before the database closes
+	reminders.Stop()
+	programProgress.Stop()
+	mediaCleanup.Stop()
+	delivery.Stop()
+
+	// End open notification streams so Shutdown does not wait on them
+	if err := realtime.Close(); err != nil {
+		log.Println("⚠️  Failed to close realtime broker:", err)
+	}
+
 	// Graceful shutdown with 5 second timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
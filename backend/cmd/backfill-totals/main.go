@@ -0,0 +1,120 @@
+// Command backfill-totals recomputes the cached totals of existing session
+// cards and their exercises from the logged sets. It is safe to run more
+// than once; cards whose totals are already correct are left untouched.
+//
+//	go run ./cmd/backfill-totals [-dry-run] [-batch 100]
+package main
+
+import (
+	"flag"
+	"log"
+	"math"
+
+	"fitness-training-backend/internal/config"
+	"fitness-training-backend/internal/database"
+	"fitness-training-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	cardColumns     = []string{"total_exercises", "total_sets", "total_volume"}
+	exerciseColumns = []string{"total_sets", "total_reps", "total_weight", "total_volume", "total_duration", "total_distance"}
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report cards that would change without writing")
+	batchSize := flag.Int("batch", 100, "cards per transaction")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("❌ Failed to load configuration:", err)
+	}
+
+	if err := database.Connect(cfg); err != nil {
+		log.Fatal("❌ Failed to connect to database:", err)
+	}
+	defer database.Close()
+
+	var scanned, changed int
+
+	result := database.DB.
+		Preload("Exercises.Sets").
+		Order("id").
+		FindInBatches(&[]models.SessionCard{}, *batchSize, func(batch *gorm.DB, _ int) error {
+			cards := *batch.Statement.Dest.(*[]models.SessionCard)
+
+			return database.Transaction(func(tx *gorm.DB) error {
+				for i := range cards {
+					scanned++
+					updated, err := backfillCard(tx, &cards[i], *dryRun)
+					if err != nil {
+						return err
+					}
+					if updated {
+						changed++
+					}
+				}
+				return nil
+			})
+		})
+	if result.Error != nil {
+		log.Fatal("❌ Backfill failed:", result.Error)
+	}
+
+	if *dryRun {
+		log.Printf("✅ Dry run: %d of %d session cards would change", changed, scanned)
+		return
+	}
+	log.Printf("✅ Recomputed totals: %d of %d session cards changed", changed, scanned)
+}
+
+// backfillCard recomputes one card and writes the totals that differ
+func backfillCard(tx *gorm.DB, card *models.SessionCard, dryRun bool) (bool, error) {
+	before := *card
+	beforeExercises := make([]models.SessionExercise, len(card.Exercises))
+	copy(beforeExercises, card.Exercises)
+
+	card.RecalculateTotals()
+
+	changed := false
+	for i := range card.Exercises {
+		exercise := &card.Exercises[i]
+		old := beforeExercises[i]
+		if old.TotalSets == exercise.TotalSets && old.TotalReps == exercise.TotalReps &&
+			sameAmount(old.TotalWeight, exercise.TotalWeight) && sameAmount(old.TotalVolume, exercise.TotalVolume) &&
+			old.TotalDuration == exercise.TotalDuration && sameAmount(old.TotalDistance, exercise.TotalDistance) {
+			continue
+		}
+		changed = true
+		if dryRun {
+			continue
+		}
+		if err := tx.Model(exercise).Select(exerciseColumns).Updates(exercise).Error; err != nil {
+			return false, err
+		}
+	}
+
+	if before.TotalExercises != card.TotalExercises || before.TotalSets != card.TotalSets ||
+		!sameAmount(before.TotalVolume, card.TotalVolume) {
+		changed = true
+		if !dryRun {
+			if err := tx.Model(card).Select(cardColumns).Updates(card).Error; err != nil {
+				return false, err
+			}
+		}
+	}
+
+	if changed && dryRun {
+		log.Printf("Session card %d: %d exercises, %d sets, %.2f kg -> %d exercises, %d sets, %.2f kg",
+			card.ID, before.TotalExercises, before.TotalSets, before.TotalVolume,
+			card.TotalExercises, card.TotalSets, card.TotalVolume)
+	}
+	return changed, nil
+}
+
+// sameAmount compares values stored as DECIMAL(10,2)
+func sameAmount(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 0.005
+}
@@ -0,0 +1,22 @@
+// Command vapid-keys prints a new VAPID key pair for Web Push notifications,
+// ready to paste into the environment.
+//
+//	go run ./cmd/vapid-keys
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"fitness-training-backend/pkg/notify"
+)
+
+func main() {
+	publicKey, privateKey, err := notify.GenerateVAPIDKeys()
+	if err != nil {
+		log.Fatal("❌ Failed to generate VAPID keys:", err)
+	}
+
+	fmt.Printf("VAPID_PUBLIC_KEY=%s\n", publicKey)
+	fmt.Printf("VAPID_PRIVATE_KEY=%s\n", privateKey)
+}
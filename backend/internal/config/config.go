@@ -8,6 +8,9 @@ import (
 	"strings"
 	"time"
 
+	"fitness-training-backend/pkg/notify"
+	"fitness-training-backend/pkg/storage"
+
 	"github.com/joho/godotenv"
 )
 
@@ -21,12 +24,18 @@ type Config struct {
 	Logging  LoggingConfig
 	RateLimit RateLimitConfig
 	Frontend FrontendConfig
+	Reminder ReminderConfig
+	Delivery DeliveryConfig
+	Realtime RealtimeConfig
+	ProgramProgress ProgramProgressConfig
+	Media    MediaConfig
 }
 
 type ServerConfig struct {
-	Host string
-	Port string
-	Env  string // development, staging, production
+	Host      string
+	Port      string
+	Env       string // development, staging, production
+	PublicURL string // externally reachable base URL, used in links such as calendar feeds
 }
 
 type DatabaseConfig struct {
@@ -81,6 +90,59 @@ type FrontendConfig struct {
 	URL string
 }
 
+type ReminderConfig struct {
+	Enabled   bool
+	LeadTimes []time.Duration // how long before a session to remind, e.g. 24h and 2h
+	Interval  time.Duration   // how often to look for due reminders
+}
+
+// DeliveryConfig configures sending notifications by email, web push and
+// LINE. A channel is enabled when its credentials are set.
+type DeliveryConfig struct {
+	Enabled     bool
+	Interval    time.Duration // how often to drain the outbox
+	MaxAttempts int           // attempts before a delivery is marked failed
+	BatchSize   int
+
+	SMTP    notify.SMTPConfig
+	WebPush notify.WebPushConfig
+	LINE    notify.LINEConfig
+}
+
+// RealtimeConfig configures the pub/sub that feeds the notification stream.
+// "memory" only reaches clients of the same process; use "postgres" when
+// running more than one API replica.
+type RealtimeConfig struct {
+	Broker    string        // memory or postgres
+	Channel   string        // LISTEN/NOTIFY channel of the postgres broker
+	Heartbeat time.Duration // keepalive interval of open streams
+}
+
+// ProgramProgressConfig configures the background refresh of program
+// assignments (current week, adherence, behind-plan flags)
+type ProgramProgressConfig struct {
+	Enabled  bool
+	Interval time.Duration // how often to refresh active assignments
+}
+
+// MediaConfig configures uploads. Files are kept in a local directory served
+// by the API, or in an S3-compatible bucket (AWS S3, MinIO...) when Storage
+// is "s3".
+type MediaConfig struct {
+	Storage      string        // local or s3
+	LocalDir     string        // directory of the local storage
+	SigningKey   string        // signs local download URLs; defaults to JWT_SECRET
+	URLExpiry    time.Duration // lifetime of signed download URLs
+	MaxImageSize int64         // bytes
+	MaxVideoSize int64         // bytes
+
+	CleanupEnabled  bool
+	CleanupInterval time.Duration // how often to look for orphaned files
+	OrphanTTL       time.Duration // grace period of uploads not used anywhere yet
+
+	S3 storage.S3Config
+}
+
 // Load loads configuration from environment variables
 func Load() (*Config, error) {
 	// Load .env file if exists (ignored in production)
@@ -90,9 +152,10 @@ func Load() (*Config, error) {
 
 	cfg := &Config{
 		Server: ServerConfig{
-			Host: getEnv("SERVER_HOST", "0.0.0.0"),
-			Port: getEnv("SERVER_PORT", "8080"),
-			Env:  getEnv("SERVER_ENV", "development"),
+			Host:      getEnv("SERVER_HOST", "0.0.0.0"),
+			Port:      getEnv("SERVER_PORT", "8080"),
+			Env:       getEnv("SERVER_ENV", "development"),
+			PublicURL: strings.TrimRight(getEnv("SERVER_PUBLIC_URL", "http://localhost:8080"), "/"),
 		},
 		Database: DatabaseConfig{
 			Host:            getEnv("DB_HOST", "localhost"),
@@ -138,6 +201,61 @@ func Load() (*Config, error) {
 		Frontend: FrontendConfig{
 			URL: getEnv("FRONTEND_URL", "http://localhost:5173"),
 		},
+		Reminder: ReminderConfig{
+			Enabled:   getEnvAsBool("REMINDER_ENABLED", true),
+			LeadTimes: getEnvAsDurations("REMINDER_LEAD_TIMES", "24h,2h"),
+			Interval:  getEnvAsDuration("REMINDER_INTERVAL", "1m"),
+		},
+		Delivery: DeliveryConfig{
+			Enabled:     getEnvAsBool("DELIVERY_ENABLED", true),
+			Interval:    getEnvAsDuration("DELIVERY_INTERVAL", "10s"),
+			MaxAttempts: getEnvAsInt("DELIVERY_MAX_ATTEMPTS", 8),
+			BatchSize:   getEnvAsInt("DELIVERY_BATCH_SIZE", 50),
+			SMTP: notify.SMTPConfig{
+				Host:     getEnv("SMTP_HOST", ""),
+				Port:     getEnv("SMTP_PORT", "587"),
+				Username: getEnv("SMTP_USERNAME", ""),
+				Password: getEnv("SMTP_PASSWORD", ""),
+				From:     getEnv("SMTP_FROM", ""),
+			},
+			WebPush: notify.WebPushConfig{
+				PublicKey:  getEnv("VAPID_PUBLIC_KEY", ""),
+				PrivateKey: getEnv("VAPID_PRIVATE_KEY", ""),
+				Subject:    getEnv("VAPID_SUBJECT", ""),
+			},
+			LINE: notify.LINEConfig{
+				ChannelAccessToken: getEnv("LINE_CHANNEL_ACCESS_TOKEN", ""),
+				APIURL:             getEnv("LINE_API_URL", "https://api.line.me"),
+			},
+		},
+		Realtime: RealtimeConfig{
+			Broker:    getEnv("REALTIME_BROKER", "memory"),
+			Channel:   getEnv("REALTIME_CHANNEL", "realtime_events"),
+			Heartbeat: getEnvAsDuration("REALTIME_HEARTBEAT", "25s"),
+		},
+		ProgramProgress: ProgramProgressConfig{
+			Enabled:  getEnvAsBool("PROGRAM_PROGRESS_ENABLED", true),
+			Interval: getEnvAsDuration("PROGRAM_PROGRESS_INTERVAL", "1h"),
+		},
+		Media: MediaConfig{
+			Storage:         getEnv("MEDIA_STORAGE", "local"),
+			LocalDir:        getEnv("MEDIA_LOCAL_DIR", "./uploads"),
+			SigningKey:      getEnv("MEDIA_SIGNING_KEY", getEnv("JWT_SECRET", "")),
+			URLExpiry:       getEnvAsDuration("MEDIA_URL_EXPIRY", "15m"),
+			MaxImageSize:    int64(getEnvAsInt("MEDIA_MAX_IMAGE_SIZE", 10<<20)),  // 10 MB
+			MaxVideoSize:    int64(getEnvAsInt("MEDIA_MAX_VIDEO_SIZE", 200<<20)), // 200 MB
+			CleanupEnabled:  getEnvAsBool("MEDIA_CLEANUP_ENABLED", true),
+			CleanupInterval: getEnvAsDuration("MEDIA_CLEANUP_INTERVAL", "1h"),
+			OrphanTTL:       getEnvAsDuration("MEDIA_ORPHAN_TTL", "24h"),
+			S3: storage.S3Config{
+				Endpoint:        getEnv("S3_ENDPOINT", "https://s3.amazonaws.com"),
+				Region:          getEnv("S3_REGION", "us-east-1"),
+				Bucket:          getEnv("S3_BUCKET", ""),
+				AccessKeyID:     getEnv("S3_ACCESS_KEY_ID", ""),
+				SecretAccessKey: getEnv("S3_SECRET_ACCESS_KEY", ""),
+				PathStyle:       getEnvAsBool("S3_PATH_STYLE", false),
+			},
+		},
 	}
 
 	// Validate required fields
@@ -154,6 +272,49 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("JWT_SECRET must be set and changed from default")
 	}
 
+	if c.Reminder.Enabled && c.Reminder.Interval <= 0 {
+		return fmt.Errorf("REMINDER_INTERVAL must be a positive duration")
+	}
+
+	if c.Delivery.Enabled {
+		if c.Delivery.Interval <= 0 || c.Delivery.MaxAttempts < 1 || c.Delivery.BatchSize < 1 {
+			return fmt.Errorf("DELIVERY_INTERVAL, DELIVERY_MAX_ATTEMPTS and DELIVERY_BATCH_SIZE must be positive")
+		}
+		if c.Delivery.SMTP.Host != "" && c.Delivery.SMTP.From == "" {
+			return fmt.Errorf("SMTP_FROM must be set when SMTP_HOST is")
+		}
+		if c.Delivery.WebPush.PrivateKey != "" && (c.Delivery.WebPush.PublicKey == "" || c.Delivery.WebPush.Subject == "") {
+			return fmt.Errorf("VAPID_PUBLIC_KEY and VAPID_SUBJECT must be set when VAPID_PRIVATE_KEY is")
+		}
+	}
+
+	if c.Realtime.Broker != "memory" && c.Realtime.Broker != "postgres" {
+		return fmt.Errorf("REALTIME_BROKER must be memory or postgres")
+	}
+	if c.Realtime.Heartbeat <= 0 {
+		return fmt.Errorf("REALTIME_HEARTBEAT must be a positive duration")
+	}
+
+	if c.ProgramProgress.Enabled && c.ProgramProgress.Interval <= 0 {
+		return fmt.Errorf("PROGRAM_PROGRESS_INTERVAL must be a positive duration")
+	}
+
+	switch c.Media.Storage {
+	case "local":
+	case "s3":
+		if c.Media.S3.Bucket == "" || c.Media.S3.AccessKeyID == "" || c.Media.S3.SecretAccessKey == "" {
+			return fmt.Errorf("S3_BUCKET, S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY must be set when MEDIA_STORAGE is s3")
+		}
+	default:
+		return fmt.Errorf("MEDIA_STORAGE must be local or s3")
+	}
+	if c.Media.URLExpiry <= 0 || c.Media.MaxImageSize <= 0 || c.Media.MaxVideoSize <= 0 {
+		return fmt.Errorf("MEDIA_URL_EXPIRY, MEDIA_MAX_IMAGE_SIZE and MEDIA_MAX_VIDEO_SIZE must be positive")
+	}
+	if c.Media.CleanupEnabled && (c.Media.CleanupInterval <= 0 || c.Media.OrphanTTL <= 0) {
+		return fmt.Errorf("MEDIA_CLEANUP_INTERVAL and MEDIA_ORPHAN_TTL must be positive durations")
+	}
+
 	if c.Server.Env == "production" {
 		if !c.Cookie.Secure {
 			log.Println("Warning: COOKIE_SECURE should be true in production")
@@ -226,6 +387,18 @@ func getEnvAsDuration(key, defaultValue string) time.Duration {
 	return 0
 }
 
+// getEnvAsDurations parses a comma-separated list such as "24h,2h", skipping
+// invalid or non-positive entries
+func getEnvAsDurations(key, defaultValue string) []time.Duration {
+	var durations []time.Duration
+	for _, value := range strings.Split(getEnv(key, defaultValue), ",") {
+		if duration, err := time.ParseDuration(strings.TrimSpace(value)); err == nil && duration > 0 {
+			durations = append(durations, duration)
+		}
+	}
+	return durations
+}
+
 func getEnvAsSlice(key string, defaultValue []string) []string {
 	valueStr := getEnv(key, "")
 	if valueStr == "" {
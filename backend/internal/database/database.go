@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
@@ -77,18 +78,28 @@ func AutoMigrate() error {
 		// Core
 		&models.User{},
 		&models.RefreshToken{},
+		&models.CalendarFeed{},
 		
 		// Roles
 		&models.Trainer{},
 		&models.Trainee{},
+		&models.TrainerAvailability{},
+		&models.TrainerTimeOff{},
 		
 		// Programs
 		&models.Program{},
+		&models.ProgramVersion{},
 		&models.ProgramAssignment{},
+		&models.ProgramDay{},
+		&models.PlannedExercise{},
+		&models.PrescribedSet{},
+		&models.ProgramProgressNote{},
 		
 		// Schedules & Sessions
 		&models.Location{},
+		&models.ScheduleSeries{},
 		&models.Schedule{},
+		&models.BookingRequest{},
 		&models.SessionCard{},
 		&models.SessionExercise{},
 		&models.ExerciseSet{},
@@ -98,10 +109,25 @@ func AutoMigrate() error {
 		
 		// Metrics & Progress
 		&models.Metric{},
+		&models.MetricGoal{},
 		&models.Achievement{},
+		&models.PersonalRecord{},
+		&models.AchievementRule{},
 		
 		// Notifications
 		&models.Notification{},
+		&models.NotificationDelivery{},
+		&models.PushSubscription{},
+		&models.NotificationSettings{},
+		&models.NotificationPreference{},
+		
+		// Messaging
+		&models.Conversation{},
+		&models.Message{},
+		&models.MessageAttachment{},
+		
+		// Media
+		&models.MediaFile{},
 	)
 
 	if err != nil {
@@ -142,7 +168,35 @@ func GetDB() *gorm.DB {
 	return DB
 }
 
-// Transaction executes a function within a database transaction
+// Transaction executes a function within a database transaction. Functions
+// registered with AfterCommit run once it has committed.
 func Transaction(fn func(*gorm.DB) error) error {
-	return DB.Transaction(fn)
+	hooks := &afterCommitHooks{}
+	ctx := context.WithValue(context.Background(), afterCommitKey{}, hooks)
+	if err := DB.WithContext(ctx).Transaction(fn); err != nil {
+		return err
+	}
+	for _, hook := range hooks.fns {
+		hook()
+	}
+	return nil
+}
+
+type afterCommitKey struct{}
+
+type afterCommitHooks struct {
+	fns []func()
+}
+
+// AfterCommit runs fn once the Transaction that tx belongs to has committed,
+// or right away when tx is not part of one. Used for side effects other
+// processes must not see before the data they refer to, e.g. realtime events.
+func AfterCommit(tx *gorm.DB, fn func()) {
+	if tx != nil && tx.Statement != nil && tx.Statement.Context != nil {
+		if hooks, ok := tx.Statement.Context.Value(afterCommitKey{}).(*afterCommitHooks); ok {
+			hooks.fns = append(hooks.fns, fn)
+			return
+		}
+	}
+	fn()
 }
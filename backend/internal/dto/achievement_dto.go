@@ -0,0 +1,45 @@
+package dto
+
+// ==========================================
+// ACHIEVEMENT RULE DTOs
+// ==========================================
+
+// AchievementRuleResponse represents a built-in or trainer-defined rule
+type AchievementRuleResponse struct {
+	ID                uint    `json:"id,omitempty"` // 0 for built-in rules
+	Key               string  `json:"key"`
+	BuiltIn           bool    `json:"builtIn"`
+	TraineeID         *uint   `json:"traineeId"` // nil = all clients
+	Type              string  `json:"type"`      // 'streak', 'milestone', 'pr', 'completion'
+	Title             string  `json:"title"`
+	Description       *string `json:"description"`
+	Metric            string  `json:"metric"`
+	Threshold         float32 `json:"threshold"`
+	ExerciseLibraryID *uint   `json:"exerciseLibraryId,omitempty"`
+	BadgeIcon         *string `json:"badgeIcon"`
+	BadgeColor        *string `json:"badgeColor"`
+	IsActive          bool    `json:"isActive"`
+}
+
+// CreateAchievementRuleRequest represents request to define a custom rule
+type CreateAchievementRuleRequest struct {
+	TraineeID         *uint   `json:"traineeId"` // omit for all clients
+	Title             string  `json:"title" binding:"required,max=255"`
+	Description       *string `json:"description"`
+	Metric            string  `json:"metric" binding:"required,oneof=completed_sessions daily_streak weekly_streak total_hours programs_completed pr_count weight_lost"`
+	Threshold         float32 `json:"threshold" binding:"required,gt=0"`
+	ExerciseLibraryID *uint   `json:"exerciseLibraryId"` // pr_count only
+	BadgeIcon         *string `json:"badgeIcon"`
+	BadgeColor        *string `json:"badgeColor" binding:"omitempty,hexcolor,max=7"`
+}
+
+// UpdateAchievementRuleRequest represents request to update a custom rule.
+// Already awarded achievements are kept.
+type UpdateAchievementRuleRequest struct {
+	Title       *string  `json:"title" binding:"omitempty,max=255"`
+	Description *string  `json:"description"`
+	Threshold   *float32 `json:"threshold" binding:"omitempty,gt=0"`
+	BadgeIcon   *string  `json:"badgeIcon"`
+	BadgeColor  *string  `json:"badgeColor" binding:"omitempty,hexcolor,max=7"`
+	IsActive    *bool    `json:"isActive"`
+}
@@ -46,6 +46,9 @@ type UserInfo struct {
 	EmailVerified bool       `json:"emailVerified"`
 	LastLoginAt   *time.Time `json:"lastLoginAt"`
 	
+	// Units metrics and exercise sets are shown in
+	Units UnitPreferences `json:"units"`
+	
 	// Role-specific data
 	Trainer *TrainerInfo `json:"trainer,omitempty"`
 	Trainee *TraineeInfo `json:"trainee,omitempty"`
@@ -53,6 +56,23 @@ type UserInfo struct {
 	CreatedAt time.Time `json:"createdAt"`
 }
 
+// UnitPreferences are the units a user enters and reads metrics and exercise
+// sets in
+type UnitPreferences struct {
+	Weight   string `json:"weight"`   // 'kg', 'lb'
+	Distance string `json:"distance"` // 'km', 'mi'
+	Length   string `json:"length"`   // 'cm', 'in'
+}
+
+// UpdateUnitPreferencesRequest changes unit preferences. System sets all
+// three at once; the other fields override it.
+type UpdateUnitPreferencesRequest struct {
+	System   *string `json:"system" binding:"omitempty,oneof=metric imperial"`
+	Weight   *string `json:"weight" binding:"omitempty,oneof=kg lb"`
+	Distance *string `json:"distance" binding:"omitempty,oneof=km mi"`
+	Length   *string `json:"length" binding:"omitempty,oneof=cm in"`
+}
+
 // TrainerInfo represents trainer-specific information
 type TrainerInfo struct {
 	ID              uint     `json:"id"`
@@ -68,6 +88,7 @@ type TrainerInfo struct {
 // TraineeInfo represents trainee-specific information
 type TraineeInfo struct {
 	ID               uint     `json:"id"`
+	MemberID         *string  `json:"memberId"`
 	Height           float32  `json:"height"`
 	Weight           float32  `json:"weight"`
 	Goals            []string `json:"goals"`
@@ -76,6 +97,8 @@ type TraineeInfo struct {
 	CompletedSessions int     `json:"completedSessions"`
 	CurrentStreak    int      `json:"currentStreak"`
 	TrainerName      *string  `json:"trainerName,omitempty"`
+	PreferredWeekdays []int   `json:"preferredWeekdays"`
+	PreferredTime    *string  `json:"preferredTime"`
 }
 
 // RefreshTokenRequest represents refresh token request
@@ -90,3 +113,15 @@ type GoogleCallbackResponse struct {
 	User         *UserInfo `json:"user"`
 	IsNewUser    bool      `json:"isNewUser"`
 }
+
+// SessionResponse represents a signed-in device (one refresh token family)
+type SessionResponse struct {
+	ID           string    `json:"id"` // family ID, stable across token rotations
+	DeviceType   *string   `json:"deviceType"`
+	DeviceName   *string   `json:"deviceName"`
+	IPAddress    *string   `json:"ipAddress"`
+	UserAgent    *string   `json:"userAgent"`
+	LastActiveAt time.Time `json:"lastActiveAt"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+	IsCurrent    bool      `json:"isCurrent"`
+}
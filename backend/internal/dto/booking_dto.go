@@ -0,0 +1,100 @@
+package dto
+
+import "time"
+
+// ==========================================
+// AVAILABILITY DTOs
+// ==========================================
+
+// AvailabilityWindow represents a weekly window in which a trainer takes sessions
+type AvailabilityWindow struct {
+	Weekday    int    `json:"weekday" binding:"min=0,max=6"` // 0 = Sunday
+	StartTime  string `json:"startTime" binding:"required"`  // HH:MM
+	EndTime    string `json:"endTime" binding:"required"`    // HH:MM
+	LocationID *uint  `json:"locationId"`                    // omit for any location
+}
+
+// UpdateAvailabilityRequest replaces a trainer's weekly availability
+type UpdateAvailabilityRequest struct {
+	Windows       []AvailabilityWindow `json:"windows" binding:"dive"`
+	BufferMinutes *int                 `json:"bufferMinutes" binding:"omitempty,min=0,max=120"`
+}
+
+// AvailabilityResponse represents a trainer's weekly availability
+type AvailabilityResponse struct {
+	Windows       []AvailabilityWindow `json:"windows"`
+	BufferMinutes int                  `json:"bufferMinutes"`
+	Timezone      string               `json:"timezone"`
+}
+
+// CreateTimeOffRequest represents request to block a period
+type CreateTimeOffRequest struct {
+	StartAt    time.Time `json:"startAt" binding:"required"`
+	EndAt      time.Time `json:"endAt" binding:"required"`
+	LocationID *uint     `json:"locationId"` // omit for all locations
+	Reason     *string   `json:"reason"`
+}
+
+// TimeOffResponse represents a blocked period
+type TimeOffResponse struct {
+	ID         uint      `json:"id"`
+	StartAt    time.Time `json:"startAt"`
+	EndAt      time.Time `json:"endAt"`
+	LocationID *uint     `json:"locationId"`
+	Reason     *string   `json:"reason"`
+}
+
+// AvailableSlot represents a bookable slot
+type AvailableSlot struct {
+	Date       string    `json:"date"`      // YYYY-MM-DD
+	StartTime  string    `json:"startTime"` // HH:MM
+	EndTime    string    `json:"endTime"`   // HH:MM
+	StartsAt   time.Time `json:"startsAt"`
+	LocationID *uint     `json:"locationId"`
+}
+
+// ==========================================
+// BOOKING REQUEST DTOs
+// ==========================================
+
+// CreateBookingRequest represents a trainee's request for a slot
+type CreateBookingRequest struct {
+	TrainerID  uint      `json:"trainerId" binding:"required"`
+	LocationID *uint     `json:"locationId"`
+	Date       time.Time `json:"date" binding:"required"`
+	Time       string    `json:"time" binding:"required"` // HH:MM format
+	Duration   int       `json:"duration" binding:"required,min=15,max=240"`
+	Notes      *string   `json:"notes"`
+}
+
+// RespondBookingRequest represents the trainer's note when confirming or declining
+type RespondBookingRequest struct {
+	Note  *string `json:"note"`
+	Title *string `json:"title"` // Schedule title on confirmation
+}
+
+// BookingRequestResponse represents a booking request
+type BookingRequestResponse struct {
+	ID         uint      `json:"id"`
+	Status     string    `json:"status"`
+	Date       time.Time `json:"date"`
+	Time       string    `json:"time"`
+	Duration   int       `json:"duration"`
+	LocationID *uint     `json:"locationId"`
+	Notes      *string   `json:"notes"`
+
+	Trainer *ParticipantInfo `json:"trainer,omitempty"`
+	Trainee *ParticipantInfo `json:"trainee,omitempty"`
+
+	ResponseNote *string    `json:"responseNote"`
+	RespondedAt  *time.Time `json:"respondedAt"`
+	ScheduleID   *uint      `json:"scheduleId"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+// ParticipantInfo represents the other party of a booking request
+type ParticipantInfo struct {
+	ID           uint    `json:"id"`
+	Name         string  `json:"name"`
+	ProfileImage *string `json:"profileImage"`
+}
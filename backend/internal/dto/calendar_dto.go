@@ -0,0 +1,47 @@
+package dto
+
+import "time"
+
+// ==========================================
+// CALENDAR FEED DTOs
+// ==========================================
+
+// CalendarFeedResponse represents a user's .ics feed. Only its token's hash
+// is stored, so the subscription URLs are returned when the feed is created
+// or rotated and left out afterwards.
+type CalendarFeedResponse struct {
+	URL            string     `json:"url,omitempty"`       // https://.../feeds/<token>.ics
+	WebcalURL      string     `json:"webcalUrl,omitempty"` // webcal:// variant for one-click subscribe
+	CreatedAt      time.Time  `json:"createdAt"`
+	LastAccessedAt *time.Time `json:"lastAccessedAt"`
+}
+
+// ==========================================
+// CALENDAR IMPORT DTOs
+// ==========================================
+
+// ImportSchedulesRequest represents the form fields of an .ics import
+type ImportSchedulesRequest struct {
+	// Assign every event to this client; otherwise events are matched to
+	// clients by attendee email
+	TraineeID *uint  `form:"traineeId"`
+	Timezone  string `form:"timezone"` // for floating times, defaults to Asia/Bangkok
+	DryRun    bool   `form:"dryRun"`   // report what would be created without saving
+}
+
+// ImportSchedulesResponse reports the outcome of an .ics import
+type ImportSchedulesResponse struct {
+	DryRun    bool                  `json:"dryRun"`
+	Total     int                   `json:"total"` // events in the file
+	Created   []ScheduleResponse    `json:"created"`
+	Conflicts []ScheduleImportIssue `json:"conflicts"`
+	Skipped   []ScheduleImportIssue `json:"skipped"`
+}
+
+// ScheduleImportIssue describes an event that was not imported
+type ScheduleImportIssue struct {
+	UID     string     `json:"uid"`
+	Summary string     `json:"summary"`
+	Start   *time.Time `json:"start"`
+	Reason  string     `json:"reason"`
+}
@@ -82,6 +82,9 @@ type ExerciseLibraryResponse struct {
 	IsPublic     bool     `json:"isPublic"`
 	UsageCount   int      `json:"usageCount"`
 	
+	// Ownership relative to the trainer searching: own, library or shared
+	Ownership string `json:"ownership,omitempty"`
+	
 	// Creator info (if private exercise)
 	Creator *struct {
 		ID   uint   `json:"id"`
@@ -91,6 +94,19 @@ type ExerciseLibraryResponse struct {
 	CreatedAt time.Time `json:"createdAt"`
 }
 
+// ExerciseSearchResponse represents a page of exercise search results with
+// the number of matches per facet value
+type ExerciseSearchResponse struct {
+	PaginatedResponse
+	Facets map[string][]FacetValueResponse `json:"facets"`
+}
+
+// FacetValueResponse represents a facet value and its number of matches
+type FacetValueResponse struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
 // ErrorResponse represents error response
 type ErrorResponse struct {
 	Code    string      `json:"code"`
@@ -0,0 +1,30 @@
+package dto
+
+import "time"
+
+// ==========================================
+// MEDIA DTOs
+// ==========================================
+
+// UploadMediaRequest holds the form fields sent with an upload. Location
+// images name the location they are added to.
+type UploadMediaRequest struct {
+	Purpose    string `form:"purpose" binding:"required,oneof=exercise location profile message"`
+	LocationID *uint  `form:"locationId"`
+}
+
+// MediaResponse represents an uploaded file. URL and the variant URLs are
+// stable links that redirect to a signed, time-limited download URL: store
+// them in exercises, locations or profiles.
+type MediaResponse struct {
+	ID          uint              `json:"id"`
+	URL         string            `json:"url"`
+	Purpose     string            `json:"purpose"`
+	FileName    string            `json:"fileName"`
+	ContentType string            `json:"contentType"`
+	SizeBytes   int64             `json:"sizeBytes"`
+	Width       *int              `json:"width"`
+	Height      *int              `json:"height"`
+	Variants    map[string]string `json:"variants"` // thumb and medium, for images larger than them
+	CreatedAt   time.Time         `json:"createdAt"`
+}
@@ -0,0 +1,88 @@
+package dto
+
+import "time"
+
+// ==========================================
+// CONVERSATION DTOs
+// ==========================================
+
+// StartConversationRequest opens the thread with a client (trainers) or with
+// the assigned trainer (trainees, no body needed)
+type StartConversationRequest struct {
+	TraineeID *uint `json:"traineeId"`
+}
+
+// ConversationResponse represents a thread in the inbox
+type ConversationResponse struct {
+	ID            uint             `json:"id"`
+	Trainer       ParticipantInfo  `json:"trainer"`
+	Trainee       ParticipantInfo  `json:"trainee"`
+	CanSend       bool             `json:"canSend"` // false once the trainee has another trainer
+	UnreadCount   int64            `json:"unreadCount"`
+	LastMessage   *MessageResponse `json:"lastMessage"`
+	LastMessageAt *time.Time       `json:"lastMessageAt"`
+	CreatedAt     time.Time        `json:"createdAt"`
+}
+
+// ==========================================
+// MESSAGE DTOs
+// ==========================================
+
+// MessageAttachmentRequest references a file the sender uploaded with
+// purpose "message"; its name, type and size are taken from the upload
+type MessageAttachmentRequest struct {
+	MediaID uint `json:"mediaId" binding:"required"`
+}
+
+// SendMessageRequest represents a new message. It needs a body or at least
+// one attachment.
+type SendMessageRequest struct {
+	Body          string                     `json:"body" binding:"max=4000"`
+	ScheduleID    *uint                      `json:"scheduleId"`
+	SessionCardID *uint                      `json:"sessionCardId"`
+	Attachments   []MessageAttachmentRequest `json:"attachments" binding:"max=10,dive"`
+}
+
+// MarkConversationReadRequest marks messages as read up to a message; omit
+// messageId to mark them all
+type MarkConversationReadRequest struct {
+	MessageID *uint `json:"messageId"`
+}
+
+// MarkConversationReadResponse reports the read receipts that were set
+type MarkConversationReadResponse struct {
+	Marked int64     `json:"marked"`
+	ReadAt time.Time `json:"readAt"`
+}
+
+// MessageAttachmentResponse represents an attachment
+type MessageAttachmentResponse struct {
+	ID          uint    `json:"id"`
+	URL         string  `json:"url"`
+	FileName    string  `json:"fileName"`
+	ContentType *string `json:"contentType"`
+	SizeBytes   *int64  `json:"sizeBytes"`
+}
+
+// MessageResponse represents a message
+type MessageResponse struct {
+	ID             uint                        `json:"id"`
+	ConversationID uint                        `json:"conversationId"`
+	SenderUserID   uint                        `json:"senderUserId"`
+	Mine           bool                        `json:"mine"`
+	Body           string                      `json:"body"`
+	ScheduleID     *uint                       `json:"scheduleId"`
+	SessionCardID  *uint                       `json:"sessionCardId"`
+	Attachments    []MessageAttachmentResponse `json:"attachments"`
+	ReadAt         *time.Time                  `json:"readAt"`
+	CreatedAt      time.Time                   `json:"createdAt"`
+}
+
+// MessagePageResponse is one page of a conversation. With ?before= (or no
+// cursor) messages are newest first and NextCursor fetches older ones; with
+// ?after= they are oldest first and NextCursor fetches newer ones.
+type MessagePageResponse struct {
+	Data       []MessageResponse `json:"data"`
+	NextCursor *uint             `json:"nextCursor"`
+	HasMore    bool              `json:"hasMore"`
+}
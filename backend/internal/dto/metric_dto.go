@@ -0,0 +1,130 @@
+package dto
+
+import "time"
+
+// ==========================================
+// METRIC ANALYTICS DTOs
+// ==========================================
+
+// SetMetricGoalRequest sets a trainee's goal for a recorded or derived metric,
+// replacing their goal for the same type and measurement
+type SetMetricGoalRequest struct {
+	Type            string     `json:"type" binding:"required,oneof=weight body_fat muscle_mass measurement bmi lean_mass waist_to_height"`
+	MeasurementType *string    `json:"measurementType"` // required for type = 'measurement'
+	TargetValue     float32    `json:"targetValue" binding:"required,gt=0"`
+	Unit            *string    `json:"unit"` // unit of targetValue, defaults to the trainer's preference
+	TargetDate      *time.Time `json:"targetDate"`
+	Notes           *string    `json:"notes"`
+}
+
+// MetricAnalyticsResponse is a trainee's body metric history as trends,
+// with their goals
+type MetricAnalyticsResponse struct {
+	TraineeID uint                   `json:"traineeId"`
+	Height    *float32               `json:"height"` // in the preferred length unit, used for BMI and waist-to-height ratio
+	Series    []MetricSeriesResponse `json:"series"`
+	Goals     []MetricGoalResponse   `json:"goals"`
+}
+
+// MetricSeriesResponse is the trend of one metric within the requested
+// range. Derived series are computed from recorded ones.
+type MetricSeriesResponse struct {
+	Type            string  `json:"type"`
+	MeasurementType *string `json:"measurementType"`
+	Unit            string  `json:"unit"`
+	Derived         bool    `json:"derived"`
+
+	Points []MetricPointResponse `json:"points"`
+
+	Latest       MetricValueResponse `json:"latest"`
+	Min          MetricValueResponse `json:"min"`
+	Max          MetricValueResponse `json:"max"`
+	Change       float32             `json:"change"`       // latest minus first point in range
+	WeeklyChange *float32            `json:"weeklyChange"` // trend over the last 4 weeks, null without enough data
+}
+
+// MetricPointResponse is one day of a series, readings of the same day
+// averaged
+type MetricPointResponse struct {
+	Date           time.Time `json:"date"`
+	Value          float32   `json:"value"`
+	RollingAverage float32   `json:"rollingAverage"` // mean of the last 7 days
+}
+
+// MetricValueResponse is a value with the day it was measured
+type MetricValueResponse struct {
+	Date  time.Time `json:"date"`
+	Value float32   `json:"value"`
+}
+
+// MetricGoalResponse is a goal with the progress towards it
+type MetricGoalResponse struct {
+	ID              uint       `json:"id"`
+	Type            string     `json:"type"`
+	MeasurementType *string    `json:"measurementType"`
+	TargetValue     float32    `json:"targetValue"`
+	Unit            string     `json:"unit"`
+	TargetDate      *time.Time `json:"targetDate"`
+	Notes           *string    `json:"notes"`
+	SetBy           *string    `json:"setBy"` // Name of who set it
+
+	// Progress
+	Status               string     `json:"status"`  // 'reached', 'on_track', 'behind', 'off_track' or 'insufficient_data'
+	Current              *float32   `json:"current"` // latest value of the metric
+	Remaining            *float32   `json:"remaining"`
+	ProjectedDate        *time.Time `json:"projectedDate"`        // when the current trend reaches the target
+	RequiredWeeklyChange *float32   `json:"requiredWeeklyChange"` // to reach the target by targetDate
+
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ==========================================
+// METRIC IMPORT DTOs
+// ==========================================
+
+// MetricImportMapping adjusts how a body-composition export is read. It is
+// sent as JSON in the "mapping" form field of an import; every field is
+// optional and the defaults read InBody (LookinBody) exports. Column fields
+// name the header a value is read from, "" ignores the column.
+type MetricImportMapping struct {
+	MemberID   *string `json:"memberId"`
+	Phone      *string `json:"phone"`
+	Email      *string `json:"email"`
+	Date       *string `json:"date"`
+	Weight     *string `json:"weight"`
+	BodyFat    *string `json:"bodyFat"`
+	MuscleMass *string `json:"muscleMass"`
+
+	// Measurement type (e.g. 'waist') to header, added to the default
+	// circumferences; "" drops a default one
+	Measurements map[string]string `json:"measurements"`
+
+	WeightUnit *string `json:"weightUnit"` // of weight and muscle mass columns, kg by default
+	LengthUnit *string `json:"lengthUnit"` // of measurement columns, cm by default
+	DayFirst   *bool   `json:"dayFirst"`   // read 03/04/2026 as 3 April (default) rather than March 4
+}
+
+// MetricImportResponse is the outcome of previewing or committing an import.
+// A preview lists what committing the same file would store.
+type MetricImportResponse struct {
+	Committed   bool                      `json:"committed"`
+	TotalRows   int                       `json:"totalRows"`
+	MatchedRows int                       `json:"matchedRows"`
+	Created     int                       `json:"created"`    // metrics stored, or to be stored when previewing
+	Duplicates  int                       `json:"duplicates"` // metrics already imported
+	Columns     map[string]string         `json:"columns"`    // field to the header it was read from
+	Rows        []MetricImportRowResponse `json:"rows"`
+}
+
+// MetricImportRowResponse is one data row of the export
+type MetricImportRowResponse struct {
+	Row         int              `json:"row"`       // line in the file, the header being line 1
+	Status      string           `json:"status"`    // 'ready', 'imported', 'duplicate', 'unmatched', 'invalid'
+	MatchedBy   *string          `json:"matchedBy"` // 'memberId', 'email' or 'phone'
+	TraineeID   *uint            `json:"traineeId"`
+	TraineeName *string          `json:"traineeName"`
+	Date        *time.Time       `json:"date"`
+	Metrics     []MetricResponse `json:"metrics"` // new metrics of the row, in the trainer's units
+	Duplicates  int              `json:"duplicates"`
+	Errors      []string         `json:"errors"`
+}
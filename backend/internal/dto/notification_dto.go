@@ -0,0 +1,100 @@
+package dto
+
+// ==========================================
+// NOTIFICATION CHANNEL DTOs
+// ==========================================
+
+// NotificationChannelsResponse reports which external channels can reach
+// the current user
+type NotificationChannelsResponse struct {
+	Email NotificationChannelStatus `json:"email"`
+	Push  PushChannelStatus         `json:"push"`
+	LINE  NotificationChannelStatus `json:"line"`
+}
+
+// NotificationChannelStatus describes one channel. Available means the server
+// is configured to send through it; Connected means the user can be reached.
+type NotificationChannelStatus struct {
+	Available bool `json:"available"`
+	Connected bool `json:"connected"`
+}
+
+// PushChannelStatus adds what the browser needs to subscribe
+type PushChannelStatus struct {
+	NotificationChannelStatus
+	PublicKey     string `json:"publicKey,omitempty"` // VAPID applicationServerKey
+	Subscriptions int    `json:"subscriptions"`
+}
+
+// PushSubscriptionRequest is the browser's PushSubscription.toJSON()
+type PushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint" binding:"required,url"`
+	Keys     struct {
+		P256dh string `json:"p256dh" binding:"required"`
+		Auth   string `json:"auth" binding:"required"`
+	} `json:"keys" binding:"required"`
+}
+
+// DeletePushSubscriptionRequest identifies the browser to unsubscribe
+type DeletePushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint" binding:"required"`
+}
+
+// LinkLINEAccountRequest links the user's LINE account, e.g. from LIFF
+// liff.getProfile() after they added the Official Account as a friend
+type LinkLINEAccountRequest struct {
+	LineUserID string `json:"lineUserId" binding:"required,startswith=U,len=33"`
+}
+
+// ==========================================
+// NOTIFICATION PREFERENCE DTOs
+// ==========================================
+
+// NotificationPreferencesResponse is the current user's delivery preferences
+type NotificationPreferencesResponse struct {
+	Timezone    string                        `json:"timezone"`
+	QuietHours  QuietHours                    `json:"quietHours"`
+	DailyDigest DailyDigest                   `json:"dailyDigest"`
+	Types       map[string]ChannelPreferences `json:"types"` // schedule, progress, achievement, system, message
+}
+
+// QuietHours holds back email, push and LINE between Start and End (HH:MM,
+// may wrap midnight); in-app notifications still arrive
+type QuietHours struct {
+	Enabled bool   `json:"enabled"`
+	Start   string `json:"start,omitempty"`
+	End     string `json:"end,omitempty"`
+}
+
+// DailyDigest sends low-priority notifications together once a day at Time
+// (HH:MM) instead of one by one
+type DailyDigest struct {
+	Enabled bool   `json:"enabled"`
+	Time    string `json:"time"`
+}
+
+// ChannelPreferences switches the channels of one notification type
+type ChannelPreferences struct {
+	InApp bool `json:"inApp"`
+	Email bool `json:"email"`
+	Push  bool `json:"push"`
+	LINE  bool `json:"line"`
+}
+
+// UpdateNotificationPreferencesRequest changes the fields that are present;
+// omitted fields and types keep their current values
+type UpdateNotificationPreferencesRequest struct {
+	Timezone    *string                                    `json:"timezone"`
+	QuietHours  *QuietHours                                `json:"quietHours"`
+	DailyDigest *DailyDigest                               `json:"dailyDigest"`
+	Types       map[string]UpdateChannelPreferencesRequest `json:"types"`
+}
+
+// UpdateChannelPreferencesRequest switches channels of one type; omitted
+// channels are unchanged
+type UpdateChannelPreferencesRequest struct {
+	InApp *bool `json:"inApp"`
+	Email *bool `json:"email"`
+	Push  *bool `json:"push"`
+	LINE  *bool `json:"line"`
+}
@@ -0,0 +1,264 @@
+package dto
+
+import "time"
+
+// ==========================================
+// PROGRAM PLAN DTOs
+// ==========================================
+
+// PrescribedSetRequest is the target of one set. It needs reps, a duration
+// or a distance; weight and percentOneRm are alternatives.
+type PrescribedSetRequest struct {
+	Reps         *int     `json:"reps" binding:"omitempty,min=1,max=100"`
+	RepsMax      *int     `json:"repsMax" binding:"omitempty,min=1,max=100"` // with reps: a rep range
+	Duration     *int     `json:"duration" binding:"omitempty,min=1"`        // seconds
+	Distance     *float32 `json:"distance" binding:"omitempty,gt=0"`         // in the trainer's distance unit
+	Weight       *float32 `json:"weight" binding:"omitempty,min=0,max=1000"` // in the trainer's weight unit
+	PercentOneRM *float32 `json:"percentOneRm" binding:"omitempty,gt=0,max=120"`
+	RPE          *float32 `json:"rpe" binding:"omitempty,min=1,max=10"`
+}
+
+// PlannedExerciseRequest is an exercise of a program day; sets are numbered
+// in order
+type PlannedExerciseRequest struct {
+	ExerciseLibraryID uint                   `json:"exerciseLibraryId" binding:"required"`
+	Notes             *string                `json:"notes"`
+	RestDuration      *int                   `json:"restDuration" binding:"omitempty,min=0,max=1800"` // seconds
+	Sets              []PrescribedSetRequest `json:"sets" binding:"required,min=1,max=20,dive"`
+}
+
+// ProgramDayRequest is the content of a program day; exercises are ordered
+// as sent and replace the existing ones
+type ProgramDayRequest struct {
+	Name      string                   `json:"name" binding:"required,max=255"`
+	Weekday   *int                     `json:"weekday" binding:"omitempty,min=0,max=6"` // 0 = Sunday
+	Duration  int                      `json:"duration" binding:"omitempty,min=5,max=480"`
+	Notes     *string                  `json:"notes"`
+	Exercises []PlannedExerciseRequest `json:"exercises" binding:"max=30,dive"`
+}
+
+// CreateProgramDayRequest adds a day to a week, in the first free slot
+// unless day is given
+type CreateProgramDayRequest struct {
+	Day *int `json:"day" binding:"omitempty,min=1"`
+	ProgramDayRequest
+}
+
+// CopyProgramWeekRequest copies a week over other weeks
+type CopyProgramWeekRequest struct {
+	ToWeeks []int `json:"toWeeks" binding:"required,min=1,dive,min=1"`
+}
+
+// PrescribedSetResponse represents the target of one set
+type PrescribedSetResponse struct {
+	SetNumber    int      `json:"setNumber"`
+	Reps         *int     `json:"reps"`
+	RepsMax      *int     `json:"repsMax"`
+	Duration     *int     `json:"duration"`
+	Distance     *float32 `json:"distance"`
+	Weight       *float32 `json:"weight"`
+	PercentOneRM *float32 `json:"percentOneRm"`
+	RPE          *float32 `json:"rpe"`
+}
+
+// PlannedExerciseResponse represents an exercise of a program day
+type PlannedExerciseResponse struct {
+	ID                uint                    `json:"id"`
+	ExerciseLibraryID uint                    `json:"exerciseLibraryId"`
+	ExerciseName      string                  `json:"exerciseName"`
+	Category          string                  `json:"category"`
+	Order             int                     `json:"order"`
+	Notes             *string                 `json:"notes"`
+	RestDuration      *int                    `json:"restDuration"`
+	Sets              []PrescribedSetResponse `json:"sets"`
+}
+
+// ProgramDayResponse represents a program day
+type ProgramDayResponse struct {
+	ID        uint                      `json:"id"`
+	Week      int                       `json:"week"`
+	Day       int                       `json:"day"`
+	Name      string                    `json:"name"`
+	Weekday   *int                      `json:"weekday"`
+	Duration  int                       `json:"duration"`
+	Notes     *string                   `json:"notes"`
+	Exercises []PlannedExerciseResponse `json:"exercises"`
+}
+
+// ProgramWeekResponse represents the days of one program week
+type ProgramWeekResponse struct {
+	Week int                  `json:"week"`
+	Days []ProgramDayResponse `json:"days"`
+}
+
+// ==========================================
+// ASSIGNMENT DTOs
+// ==========================================
+
+// TrainerAssignmentResponse represents an assignment in the trainer's list
+type TrainerAssignmentResponse struct {
+	ProgramAssignmentResponse
+	ProgramID   uint                `json:"programId"`
+	ProgramName string              `json:"programName"`
+	Trainee     ScheduleTraineeInfo `json:"trainee"`
+}
+
+// ==========================================
+// PROGRESS NOTE DTOs
+// ==========================================
+
+// CreateProgressNoteRequest represents a note on an assignment's progress
+type CreateProgressNoteRequest struct {
+	Week *int       `json:"week" binding:"omitempty,min=1"`
+	Date *time.Time `json:"date"` // defaults to today
+	Note string     `json:"note" binding:"required,max=4000"`
+}
+
+// ProgressNoteResponse represents a progress note
+type ProgressNoteResponse struct {
+	ID         uint      `json:"id"`
+	Week       *int      `json:"week"`
+	Date       time.Time `json:"date"`
+	Note       string    `json:"note"`
+	RecordedBy string    `json:"recordedBy"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// ==========================================
+// PROGRESSION DTOs
+// ==========================================
+
+// SessionSuggestionResponse proposes the sets of an upcoming program session
+type SessionSuggestionResponse struct {
+	ScheduleID   uint                         `json:"scheduleId"`
+	TraineeID    uint                         `json:"traineeId"`
+	ProgramDayID uint                         `json:"programDayId"`
+	Scheme       string                       `json:"scheme"`
+	WeightUnit   string                       `json:"weightUnit"`   // the trainer's, as the sets are entered in
+	DistanceUnit string                       `json:"distanceUnit"` // the trainer's
+	Exercises    []ExerciseSuggestionResponse `json:"exercises"`
+}
+
+// ExerciseSuggestionResponse proposes the sets of one planned exercise. It
+// has the shape of an exercise in CreateSessionCardRequest, so it can be
+// edited and sent back.
+type ExerciseSuggestionResponse struct {
+	ExerciseLibraryID uint                   `json:"exerciseLibraryId"`
+	Name              string                 `json:"name"`
+	Category          *string                `json:"category"`
+	ExerciseOrder     int                    `json:"exerciseOrder"`
+	Sets              []SuggestedSetResponse `json:"sets"`
+	EstimatedOneRM    *float32               `json:"estimatedOneRm"` // in weightUnit, from recent sessions
+	LastPerformed     *time.Time             `json:"lastPerformed"`
+	Reasoning         []string               `json:"reasoning"`
+}
+
+// SuggestedSetResponse is the proposed target of one set
+type SuggestedSetResponse struct {
+	SetNumber    int      `json:"setNumber"`
+	Reps         *int     `json:"reps"`
+	RepsMax      *int     `json:"repsMax"`
+	Weight       *float32 `json:"weight"`   // in weightUnit
+	Duration     *int     `json:"duration"` // seconds
+	Distance     *float32 `json:"distance"` // in distanceUnit
+	RestDuration *int     `json:"restDuration"`
+	TargetRPE    *float32 `json:"targetRpe"`
+}
+
+// ==========================================
+// VERSION DTOs
+// ==========================================
+
+// ProgramVersionResponse represents one version of a program; weeks are only
+// included for a single version
+type ProgramVersionResponse struct {
+	Version              int                   `json:"version"`
+	Current              bool                  `json:"current"`
+	Name                 string                `json:"name"`
+	Description          *string               `json:"description"`
+	TotalWeeks           int                   `json:"totalWeeks"`
+	SessionsPerWeek      int                   `json:"sessionsPerWeek"`
+	Goals                []string              `json:"goals"`
+	TargetFitnessLevel   *string               `json:"targetFitnessLevel"`
+	AdherenceThreshold   float32               `json:"adherenceThreshold"`
+	ProgressionScheme    string                `json:"progressionScheme"`
+	ProgressionIncrement float32               `json:"progressionIncrement"`
+	TotalAssignments     int                   `json:"totalAssignments"`
+	CompletionRate       float32               `json:"completionRate"`
+	CreatedAt            time.Time             `json:"createdAt"`
+	Weeks                []ProgramWeekResponse `json:"weeks,omitempty"`
+}
+
+// ProgramVersionDiffResponse lists what changed from one version to another
+type ProgramVersionDiffResponse struct {
+	From   int                  `json:"from"`
+	To     int                  `json:"to"`
+	Fields []ProgramFieldChange `json:"fields"`
+	Days   []ProgramDayChange   `json:"days"`
+}
+
+// ProgramFieldChange is a program setting that changed
+type ProgramFieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// ProgramDayChange is a plan day added, removed or changed
+type ProgramDayChange struct {
+	Week    int      `json:"week"`
+	Day     int      `json:"day"`
+	Change  string   `json:"change"` // 'added', 'removed', 'changed'
+	Name    string   `json:"name"`
+	Details []string `json:"details,omitempty"`
+}
+
+// MigrateAssignmentsRequest moves assignments of older versions forward
+type MigrateAssignmentsRequest struct {
+	AssignmentIDs []uint `json:"assignmentIds" binding:"required,min=1,max=100"`
+}
+
+// AssignmentMigrationResponse reports how an assignment's sessions followed
+// it to the new version
+type AssignmentMigrationResponse struct {
+	AssignmentID uint     `json:"assignmentId"`
+	FromVersion  int      `json:"fromVersion"`
+	ToVersion    int      `json:"toVersion"`
+	Updated      int      `json:"updated"`   // upcoming sessions moved to the new plan
+	Cancelled    int      `json:"cancelled"` // upcoming sessions whose day was removed
+	Added        int      `json:"added"`     // sessions laid out for new days
+	Unplaced     []string `json:"unplaced"`  // new days that could not be laid out
+}
+
+// ==========================================
+// CATALOG DTOs
+// ==========================================
+
+// CatalogProgramResponse represents a program shared in the catalog. Weeks
+// (the newest version's plan) are only included for a single program.
+type CatalogProgramResponse struct {
+	ProgramResponse
+	Visibility       string                      `json:"visibility"`
+	Version          int                         `json:"version"`
+	Equipment        []string                    `json:"equipment"` // needed by the plan's exercises
+	TotalAssignments int                         `json:"totalAssignments"`
+	CompletionRate   float32                     `json:"completionRate"`
+	CloneCount       int                         `json:"cloneCount"`
+	ClonedFrom       *ProgramAttributionResponse `json:"clonedFrom,omitempty"`
+	UpdatedAt        time.Time                   `json:"updatedAt"`
+	Weeks            []ProgramWeekResponse       `json:"weeks,omitempty"`
+}
+
+// ProgramAttributionResponse credits the author of the program a clone was
+// made from
+type ProgramAttributionResponse struct {
+	ProgramID *uint            `json:"programId"` // nil once the source is deleted
+	Version   *int             `json:"version"`
+	Author    *ParticipantInfo `json:"author"` // nil once the author's account is deleted
+}
+
+// CloneProgramRequest represents request to copy a program into the caller's
+// account
+type CloneProgramRequest struct {
+	Name *string `json:"name" binding:"omitempty,min=1,max=255"` // defaults to the source's name
+}
@@ -18,6 +18,10 @@ type ScheduleResponse struct {
 	Status      string     `json:"status"`
 	SessionType *string    `json:"sessionType"`
 	
+	// Recurrence
+	SeriesID    *uint `json:"seriesId,omitempty"`
+	IsException bool  `json:"isException,omitempty"`
+	
 	// Trainer info
 	Trainer struct {
 		ID           uint    `json:"id"`
@@ -25,6 +29,9 @@ type ScheduleResponse struct {
 		ProfileImage *string `json:"profileImage"`
 	} `json:"trainer"`
 	
+	// Trainee info (filled for trainer views)
+	Trainee *ScheduleTraineeInfo `json:"trainee,omitempty"`
+	
 	// Location info
 	Location *struct {
 		ID      uint    `json:"id"`
@@ -34,13 +41,21 @@ type ScheduleResponse struct {
 	} `json:"location,omitempty"`
 	
 	// Related program
-	ProgramName *string `json:"programName,omitempty"`
+	ProgramName  *string `json:"programName,omitempty"`
+	ProgramDayID *uint   `json:"programDayId,omitempty"`
 	
 	Notes *string `json:"notes"`
 	
 	CreatedAt time.Time `json:"createdAt"`
 }
 
+// ScheduleTraineeInfo represents the trainee attending a schedule
+type ScheduleTraineeInfo struct {
+	ID           uint    `json:"id"`
+	Name         string  `json:"name"`
+	ProfileImage *string `json:"profileImage"`
+}
+
 // ProgramResponse represents a program (READ-ONLY for trainee)
 type ProgramResponse struct {
 	ID          uint    `json:"id"`
@@ -79,6 +94,14 @@ type ProgramAssignmentResponse struct {
 	TotalSessions      int       `json:"totalSessions"`
 	Status             string    `json:"status"`
 	Notes              *string   `json:"notes"`
+	ProgramVersion     int       `json:"programVersion,omitempty"` // version the assignment is pinned to
+	
+	// Adherence
+	AdherenceRate   *float32   `json:"adherenceRate"`
+	BehindPlan      bool       `json:"behindPlan"`
+	BehindPlanSince *time.Time `json:"behindPlanSince"`
+	
+	ProgressNotes []ProgressNoteResponse `json:"progressNotes,omitempty"`
 }
 
 // SessionCardResponse represents a session card (READ-ONLY)
@@ -89,6 +112,7 @@ type SessionCardResponse struct {
 	Duration         int       `json:"duration"` // minutes
 	OverallFeedback  *string   `json:"overallFeedback"`
 	NextSessionGoals []string  `json:"nextSessionGoals"`
+	Source           *string   `json:"source"` // 'gpx', 'tcx' or 'fit' for imported workouts
 	
 	// Trainer
 	Trainer struct {
@@ -100,7 +124,11 @@ type SessionCardResponse struct {
 	// Stats
 	TotalExercises int     `json:"totalExercises"`
 	TotalSets      int     `json:"totalSets"`
-	TotalVolume    float32 `json:"totalVolume"` // kg
+	TotalVolume    float32 `json:"totalVolume"` // in weightUnit
+	
+	// Units weights and distances are shown in
+	WeightUnit   string `json:"weightUnit"`   // 'kg' or 'lb'
+	DistanceUnit string `json:"distanceUnit"` // 'km' or 'mi'
 	
 	// Rating
 	TrainerRating *int `json:"trainerRating"`
@@ -127,6 +155,16 @@ type SessionExerciseResponse struct {
 	TotalWeight float32 `json:"totalWeight"`
 	TotalVolume float32 `json:"totalVolume"`
 	
+	// Cardio Stats
+	TotalDuration int     `json:"totalDuration"` // seconds
+	TotalDistance float32 `json:"totalDistance"` // in the session's distanceUnit
+	Pace          *int    `json:"pace"`          // seconds per distanceUnit
+	
+	// Heart Rate
+	AvgHeartRate   *int    `json:"avgHeartRate"`
+	MaxHeartRate   *int    `json:"maxHeartRate"`
+	HeartRateZones []int64 `json:"heartRateZones"` // seconds in zones 1-5 (50-60% ... 90-100% of max heart rate)
+	
 	// Personal Record
 	IsPR   bool    `json:"isPR"`
 	PRNote *string `json:"prNote"`
@@ -142,11 +180,14 @@ type ExerciseSetResponse struct {
 	
 	// Weight Training
 	Reps   *int     `json:"reps"`
-	Weight *float32 `json:"weight"` // kg
+	Weight *float32 `json:"weight"` // in the session's weightUnit
 	
 	// Cardio
-	Duration *int     `json:"duration"` // seconds
-	Distance *float32 `json:"distance"` // km
+	Duration     *int     `json:"duration"` // seconds
+	Distance     *float32 `json:"distance"` // in the session's distanceUnit
+	Pace         *int     `json:"pace"`     // seconds per distanceUnit
+	AvgHeartRate *int     `json:"avgHeartRate"`
+	MaxHeartRate *int     `json:"maxHeartRate"`
 	
 	// Rest
 	RestDuration *int `json:"restDuration"` // seconds
@@ -197,6 +238,12 @@ type StatsResponse struct {
 	UpcomingSessions   int       `json:"upcomingSessions"`
 	LastSessionDate    *time.Time `json:"lastSessionDate"`
 	
+	// Weekly Consistency
+	WeeklyStreakTarget     int     `json:"weeklyStreakTarget"` // sessions per ISO week
+	CurrentWeeklyStreak    int     `json:"currentWeeklyStreak"`
+	LongestWeeklyStreak    int     `json:"longestWeeklyStreak"`
+	AverageSessionsPerWeek float32 `json:"averageSessionsPerWeek"`
+	
 	// Current Program
 	CurrentProgram *struct {
 		ID                 uint    `json:"id"`
@@ -222,6 +269,28 @@ type AchievementResponse struct {
 	AchievedAt  time.Time `json:"achievedAt"`
 }
 
+// ExerciseRecordsResponse lists the current personal records of one exercise
+type ExerciseRecordsResponse struct {
+	ExerciseLibraryID uint                     `json:"exerciseLibraryId"`
+	ExerciseName      string                   `json:"exerciseName"`
+	Category          string                   `json:"category"`
+	Records           []PersonalRecordResponse `json:"records"`
+}
+
+// PersonalRecordResponse represents one personal record and where it was set
+type PersonalRecordResponse struct {
+	Type   string   `json:"type"` // 'max_weight', 'reps_at_weight', 'estimated_1rm', 'max_distance', 'best_pace'
+	Value  float32  `json:"value"`
+	Unit   string   `json:"unit"` // 'kg'/'lb', 'reps', 'km'/'mi', 'sec/km'/'sec/mi'
+	Weight *float32 `json:"weight,omitempty"`
+	Reps   *int     `json:"reps,omitempty"`
+	
+	// Session that set the record
+	SessionCardID uint      `json:"sessionCardId"`
+	SessionTitle  string    `json:"sessionTitle"`
+	AchievedAt    time.Time `json:"achievedAt"`
+}
+
 // ProfileResponse represents trainee profile (READ-ONLY)
 type ProfileResponse struct {
 	User    UserInfo     `json:"user"`
@@ -9,9 +9,10 @@ import "time"
 
 // CreateClientRequest represents request to add a new client
 type CreateClientRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Name     string `json:"name" binding:"required,min=2"`
-	Password string `json:"password" binding:"required,min=8"`
+	Email    string  `json:"email" binding:"required,email"`
+	Name     string  `json:"name" binding:"required,min=2"`
+	Password string  `json:"password" binding:"required,min=8"`
+	MemberID *string `json:"memberId" binding:"omitempty,max=50"` // gym membership number
 	
 	// Physical Info
 	Height *float32 `json:"height" binding:"omitempty,min=0"`
@@ -39,6 +40,8 @@ type CreateClientRequest struct {
 
 // UpdateClientRequest represents request to update client
 type UpdateClientRequest struct {
+	MemberID *string `json:"memberId" binding:"omitempty,max=50"` // "" clears it
+	
 	// Physical Info
 	Height *float32 `json:"height" binding:"omitempty,min=0"`
 	Weight *float32 `json:"weight" binding:"omitempty,min=0"`
@@ -61,6 +64,13 @@ type UpdateClientRequest struct {
 	EmergencyContactName         *string `json:"emergencyContactName"`
 	EmergencyContactPhone        *string `json:"emergencyContactPhone"`
 	EmergencyContactRelationship *string `json:"emergencyContactRelationship"`
+
+	// Sessions per ISO week that keep the weekly streak going
+	WeeklyStreakTarget *int `json:"weeklyStreakTarget" binding:"omitempty,min=1,max=14"`
+
+	// Preferred training days and time, used when laying out assigned programs
+	PreferredWeekdays []int   `json:"preferredWeekdays" binding:"omitempty,max=7,dive,min=0,max=6"` // 0 = Sunday
+	PreferredTime     *string `json:"preferredTime"`                                               // HH:MM, "" clears it
 }
 
 // CreateScheduleRequest represents request to create schedule
@@ -89,6 +99,63 @@ type UpdateScheduleRequest struct {
 	PlannedExercises []string   `json:"plannedExercises"`
 	Status           *string    `json:"status" binding:"omitempty,oneof=scheduled confirmed completed cancelled no_show"`
 	Notes            *string    `json:"notes"`
+	
+	// Recurring schedules: which occurrences the edit applies to
+	Scope string `json:"scope" binding:"omitempty,oneof=this following all"` // default 'this'
+}
+
+// CancelScheduleRequest represents request to cancel a schedule
+type CancelScheduleRequest struct {
+	Reason *string `json:"reason"`
+	Scope  string  `json:"scope" binding:"omitempty,oneof=this following all"` // default 'this'
+}
+
+// CreateScheduleSeriesRequest represents request to create a recurring schedule
+type CreateScheduleSeriesRequest struct {
+	TraineeID           uint       `json:"traineeId" binding:"required"`
+	LocationID          *uint      `json:"locationId"`
+	ProgramAssignmentID *uint      `json:"programAssignmentId"`
+	
+	// Recurrence
+	Frequency string     `json:"frequency" binding:"required,oneof=weekly biweekly"`
+	Weekdays  []int      `json:"weekdays" binding:"required,min=1,max=7,dive,min=0,max=6"` // 0 = Sunday
+	StartDate time.Time  `json:"startDate" binding:"required"`
+	Count     *int       `json:"count" binding:"omitempty,min=1"`
+	Until     *time.Time `json:"until"`
+	Timezone  string     `json:"timezone"` // IANA name, defaults to Asia/Bangkok
+	
+	// Occurrence template
+	Time             string   `json:"time" binding:"required"` // HH:MM format
+	Duration         int      `json:"duration" binding:"required,min=1"`
+	Title            string   `json:"title" binding:"required"`
+	Description      *string  `json:"description"`
+	SessionType      *string  `json:"sessionType"`
+	PlannedExercises []string `json:"plannedExercises"`
+}
+
+// ScheduleSeriesResponse represents a recurring schedule and its occurrences
+type ScheduleSeriesResponse struct {
+	ID        uint                 `json:"id"`
+	Trainee   *ScheduleTraineeInfo `json:"trainee,omitempty"`
+	LocationID *uint               `json:"locationId"`
+	
+	Frequency string     `json:"frequency"`
+	Weekdays  []int      `json:"weekdays"`
+	StartDate time.Time  `json:"startDate"`
+	Count     *int       `json:"count"`
+	Until     *time.Time `json:"until"`
+	Timezone  string     `json:"timezone"`
+	
+	Time             string   `json:"time"`
+	Duration         int      `json:"duration"`
+	Title            string   `json:"title"`
+	Description      *string  `json:"description"`
+	SessionType      *string  `json:"sessionType"`
+	PlannedExercises []string `json:"plannedExercises"`
+	
+	Status      string             `json:"status"`
+	Occurrences []ScheduleResponse `json:"occurrences,omitempty"`
+	CreatedAt   time.Time          `json:"createdAt"`
 }
 
 // CreateSessionCardRequest represents request to create session card
@@ -98,7 +165,7 @@ type CreateSessionCardRequest struct {
 	OverallFeedback  *string  `json:"overallFeedback"`
 	NextSessionGoals []string `json:"nextSessionGoals"`
 	TraineeRating    *int     `json:"traineeRating" binding:"omitempty,min=1,max=5"`
-	Exercises        []CreateSessionExerciseRequest `json:"exercises" binding:"required,min=1"`
+	Exercises        []CreateSessionExerciseRequest `json:"exercises" binding:"required,min=1,dive"`
 }
 
 // CreateSessionExerciseRequest represents exercise in session card
@@ -109,9 +176,12 @@ type CreateSessionExerciseRequest struct {
 	ExerciseOrder     int     `json:"exerciseOrder" binding:"required"`
 	Notes             *string `json:"notes"`
 	FormNotes         *string `json:"formNotes"`
-	IsPR              bool    `json:"isPR"`
-	PRNote            *string `json:"prNote"`
-	Sets              []CreateExerciseSetRequest `json:"sets" binding:"required,min=1"`
+	Sets              []CreateExerciseSetRequest `json:"sets" binding:"required,min=1,dive"`
+	
+	// Heart rate, kept when editing an imported workout
+	AvgHeartRate   *int    `json:"avgHeartRate" binding:"omitempty,min=20,max=250"`
+	MaxHeartRate   *int    `json:"maxHeartRate" binding:"omitempty,min=20,max=250"`
+	HeartRateZones []int64 `json:"heartRateZones" binding:"omitempty,len=5,dive,min=0"` // seconds in zones 1-5
 }
 
 // CreateExerciseSetRequest represents a set in an exercise
@@ -121,8 +191,10 @@ type CreateExerciseSetRequest struct {
 	Weight       *float32 `json:"weight" binding:"omitempty,min=0"`
 	Duration     *int     `json:"duration" binding:"omitempty,min=0"`
 	Distance     *float32 `json:"distance" binding:"omitempty,min=0"`
+	AvgHeartRate *int     `json:"avgHeartRate" binding:"omitempty,min=20,max=250"`
+	MaxHeartRate *int     `json:"maxHeartRate" binding:"omitempty,min=20,max=250"`
 	RestDuration *int     `json:"restDuration" binding:"omitempty,min=0"`
-	Completed    bool     `json:"completed"`
+	Completed    *bool    `json:"completed"` // defaults to true
 	RPE          *int     `json:"rpe" binding:"omitempty,min=1,max=10"`
 	Notes        *string  `json:"notes"`
 }
@@ -134,6 +206,9 @@ type UpdateSessionCardRequest struct {
 	NextSessionGoals []string `json:"nextSessionGoals"`
 	TrainerRating    *int     `json:"trainerRating" binding:"omitempty,min=1,max=5"`
 	TraineeRating    *int     `json:"traineeRating" binding:"omitempty,min=1,max=5"`
+	
+	// Replaces all exercises and sets when present; totals are recalculated
+	Exercises []CreateSessionExerciseRequest `json:"exercises" binding:"omitempty,min=1,dive"`
 }
 
 // CreateProgramRequest represents request to create program
@@ -144,7 +219,10 @@ type CreateProgramRequest struct {
 	SessionsPerWeek    int      `json:"sessionsPerWeek" binding:"required,min=1"`
 	Goals              []string `json:"goals"`
 	TargetFitnessLevel *string  `json:"targetFitnessLevel" binding:"omitempty,oneof=beginner intermediate advanced"`
-	WeeklySchedule     *string  `json:"weeklySchedule"` // JSON string
+	AdherenceThreshold *float32 `json:"adherenceThreshold" binding:"omitempty,min=0,max=100"` // %, defaults to 70
+	ProgressionScheme    *string  `json:"progressionScheme" binding:"omitempty,oneof=double_progression rpe percent_1rm"` // defaults to double_progression
+	ProgressionIncrement *float32 `json:"progressionIncrement" binding:"omitempty,gt=0,max=50"`                           // kg, defaults to 2.5
+	Visibility           *string  `json:"visibility" binding:"omitempty,oneof=private gym public"`                         // defaults to private
 }
 
 // UpdateProgramRequest represents request to update program
@@ -155,16 +233,51 @@ type UpdateProgramRequest struct {
 	SessionsPerWeek    *int     `json:"sessionsPerWeek" binding:"omitempty,min=1"`
 	Goals              []string `json:"goals"`
 	TargetFitnessLevel *string  `json:"targetFitnessLevel" binding:"omitempty,oneof=beginner intermediate advanced"`
-	WeeklySchedule     *string  `json:"weeklySchedule"`
 	Status             *string  `json:"status" binding:"omitempty,oneof=draft active archived"`
+	AdherenceThreshold *float32 `json:"adherenceThreshold" binding:"omitempty,min=0,max=100"`
+	ProgressionScheme    *string  `json:"progressionScheme" binding:"omitempty,oneof=double_progression rpe percent_1rm"`
+	ProgressionIncrement *float32 `json:"progressionIncrement" binding:"omitempty,gt=0,max=50"`
+	Visibility           *string  `json:"visibility" binding:"omitempty,oneof=private gym public"`
 }
 
 // AssignProgramRequest represents request to assign program to trainee
 type AssignProgramRequest struct {
 	TraineeID     uint      `json:"traineeId" binding:"required"`
 	StartDate     time.Time `json:"startDate" binding:"required"`
-	TotalSessions int       `json:"totalSessions" binding:"required,min=1"`
+	TotalSessions int       `json:"totalSessions" binding:"omitempty,min=1"` // defaults to the sessions laid out
 	Notes         *string   `json:"notes"`
+	
+	// Schedule generation; weekdays and time default to the client's preferences
+	GenerateSchedules *bool   `json:"generateSchedules"` // default true
+	Weekdays          []int   `json:"weekdays" binding:"omitempty,max=7,dive,min=0,max=6"` // 0 = Sunday
+	Time              *string `json:"time"` // HH:MM
+	LocationID        *uint   `json:"locationId"`
+}
+
+// TrainerProgramResponse represents a program as seen by its owner
+type TrainerProgramResponse struct {
+	ProgramResponse
+	Status           string    `json:"status"`
+	Version          int       `json:"version"` // newest version
+	TotalAssignments int       `json:"totalAssignments"`
+	CompletionRate   float32   `json:"completionRate"`
+	AdherenceThreshold float32 `json:"adherenceThreshold"`
+	ProgressionScheme    string  `json:"progressionScheme"`
+	ProgressionIncrement float32 `json:"progressionIncrement"`
+	Visibility           string  `json:"visibility"`
+	CloneCount           int     `json:"cloneCount"`
+	ClonedFrom           *ProgramAttributionResponse `json:"clonedFrom,omitempty"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+// CreateMetricRequest represents request to record a client metric
+type CreateMetricRequest struct {
+	Date            time.Time `json:"date" binding:"required"`
+	Type            string    `json:"type" binding:"required,oneof=weight body_fat muscle_mass measurement"`
+	Value           float32   `json:"value" binding:"required,gt=0"`
+	Unit            string    `json:"unit" binding:"required"`
+	MeasurementType *string   `json:"measurementType"`
+	Notes           *string   `json:"notes"`
 }
 
 // CreateExerciseRequest represents request to create exercise in library
@@ -0,0 +1,13 @@
+package dto
+
+// ==========================================
+// WORKOUT IMPORT DTOs
+// ==========================================
+
+// WorkoutImportResponse is the session card created from an activity file
+type WorkoutImportResponse struct {
+	Session         SessionCardResponse `json:"session"`
+	Sport           string              `json:"sport"` // running, cycling, swimming, walking, hiking, rowing or other
+	ScheduleID      uint                `json:"scheduleId"`
+	ScheduleMatched bool                `json:"scheduleMatched"` // false when a schedule was created for the workout
+}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/service"
+	"fitness-training-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AchievementHandler handles earned achievements and achievement rules
+type AchievementHandler struct {
+	achievementService service.AchievementService
+}
+
+// NewAchievementHandler creates a new achievement handler
+func NewAchievementHandler(achievementService service.AchievementService) *AchievementHandler {
+	return &AchievementHandler{achievementService: achievementService}
+}
+
+// ==========================================
+// EARNED ACHIEVEMENTS
+// ==========================================
+
+// GetAchievements handles GET /trainee/achievements
+func (h *AchievementHandler) GetAchievements(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	achievements, err := h.achievementService.GetAchievements(userID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, achievements)
+}
+
+// GetClientAchievements handles GET /trainer/clients/:id/achievements
+func (h *AchievementHandler) GetClientAchievements(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	traineeID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	achievements, err := h.achievementService.GetClientAchievements(userID, traineeID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, achievements)
+}
+
+// ==========================================
+// RULES (TRAINER)
+// ==========================================
+
+// GetRules handles GET /trainer/achievement-rules
+func (h *AchievementHandler) GetRules(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	rules, err := h.achievementService.GetRules(userID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, rules)
+}
+
+// CreateRule handles POST /trainer/achievement-rules
+func (h *AchievementHandler) CreateRule(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateAchievementRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	rule, err := h.achievementService.CreateRule(userID, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.Created(c, rule)
+}
+
+// UpdateRule handles PATCH /trainer/achievement-rules/:id
+func (h *AchievementHandler) UpdateRule(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	ruleID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateAchievementRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	rule, err := h.achievementService.UpdateRule(userID, ruleID, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, rule)
+}
+
+// DeleteRule handles DELETE /trainer/achievement-rules/:id
+func (h *AchievementHandler) DeleteRule(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	ruleID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.achievementService.DeleteRule(userID, ruleID); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.NoContent(c)
+}
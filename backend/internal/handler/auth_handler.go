@@ -0,0 +1,334 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"fitness-training-backend/internal/config"
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/middleware"
+	"fitness-training-backend/internal/service"
+	"fitness-training-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	authCookieName       = "auth_token"
+	refreshCookieName    = "refresh_token"
+	oauthStateCookieName = "oauth_state"
+	refreshCookiePath    = "/api/v1/auth"
+)
+
+// AuthHandler handles authentication endpoints
+type AuthHandler struct {
+	authService service.AuthService
+	cfg         *config.Config
+}
+
+// NewAuthHandler creates a new auth handler
+func NewAuthHandler(authService service.AuthService, cfg *config.Config) *AuthHandler {
+	return &AuthHandler{
+		authService: authService,
+		cfg:         cfg,
+	}
+}
+
+// Register handles POST /auth/register
+func (h *AuthHandler) Register(c *gin.Context) {
+	var req dto.RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	resp, err := h.authService.Register(&req, clientInfo(c))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	h.setAuthCookies(c, resp.AccessToken, resp.RefreshToken)
+	utils.Created(c, resp)
+}
+
+// Login handles POST /auth/login
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req dto.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	resp, err := h.authService.Login(&req, clientInfo(c))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	h.setAuthCookies(c, resp.AccessToken, resp.RefreshToken)
+	utils.OK(c, resp)
+}
+
+// Logout handles POST /auth/logout
+// The session behind the refresh cookie is revoked so the token cannot be reused.
+func (h *AuthHandler) Logout(c *gin.Context) {
+	if refreshToken, err := c.Cookie(refreshCookieName); err == nil {
+		if err := h.authService.Logout(refreshToken); err != nil {
+			handleServiceError(c, err)
+			return
+		}
+	}
+
+	h.clearAuthCookies(c)
+	utils.SuccessResponse(c, http.StatusOK, nil, "Logged out successfully")
+}
+
+// Me handles GET /auth/me
+func (h *AuthHandler) Me(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.authService.GetCurrentUser(userID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, user)
+}
+
+// RefreshToken handles POST /auth/refresh
+// The refresh token is read from the cookie first, then from the JSON body.
+func (h *AuthHandler) RefreshToken(c *gin.Context) {
+	refreshToken, err := c.Cookie(refreshCookieName)
+	if err != nil || refreshToken == "" {
+		var req dto.RefreshTokenRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ValidationError(c, err.Error())
+			return
+		}
+		refreshToken = req.RefreshToken
+	}
+
+	resp, err := h.authService.RefreshToken(refreshToken, clientInfo(c))
+	if err != nil {
+		h.clearAuthCookies(c)
+		handleServiceError(c, err)
+		return
+	}
+
+	h.setAuthCookies(c, resp.AccessToken, resp.RefreshToken)
+	utils.OK(c, resp)
+}
+
+// ==========================================
+// SESSIONS
+// ==========================================
+
+// GetSessions handles GET /auth/sessions
+func (h *AuthHandler) GetSessions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.authService.GetSessions(userID, middleware.GetSessionID(c))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, sessions)
+}
+
+// RevokeSession handles DELETE /auth/sessions/:id
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	sessionID := c.Param("id")
+	if sessionID == "" {
+		utils.BadRequest(c, "Invalid session id")
+		return
+	}
+
+	if err := h.authService.RevokeSession(userID, sessionID); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.NoContent(c)
+}
+
+// RevokeOtherSessions handles DELETE /auth/sessions
+// Signs out every device except the one making the request.
+func (h *AuthHandler) RevokeOtherSessions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.authService.RevokeOtherSessions(userID, middleware.GetSessionID(c)); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.NoContent(c)
+}
+
+// GetUnits handles GET /me/units
+func (h *AuthHandler) GetUnits(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	prefs, err := h.authService.GetUnits(userID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, prefs)
+}
+
+// UpdateUnits handles PUT /me/units
+func (h *AuthHandler) UpdateUnits(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateUnitPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	prefs, err := h.authService.UpdateUnits(userID, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, prefs)
+}
+
+// GoogleLogin handles GET /auth/google/login
+func (h *AuthHandler) GoogleLogin(c *gin.Context) {
+	if h.cfg.Google.ClientID == "" {
+		utils.ErrorResponse(c, http.StatusServiceUnavailable, "OAUTH_DISABLED", "Google login is not configured", nil)
+		return
+	}
+
+	state, err := randomState()
+	if err != nil {
+		utils.InternalError(c, "Failed to start Google login")
+		return
+	}
+
+	h.setCookie(c, oauthStateCookieName, state, 600, refreshCookiePath)
+	c.Redirect(http.StatusTemporaryRedirect, h.authService.GoogleLoginURL(state))
+}
+
+// GoogleCallback handles GET /auth/google/callback
+func (h *AuthHandler) GoogleCallback(c *gin.Context) {
+	state, err := c.Cookie(oauthStateCookieName)
+	if err != nil || state == "" || state != c.Query("state") {
+		utils.BadRequest(c, "Invalid OAuth state")
+		return
+	}
+	h.setCookie(c, oauthStateCookieName, "", -1, refreshCookiePath)
+
+	code := c.Query("code")
+	if code == "" {
+		utils.BadRequest(c, "Missing authorization code")
+		return
+	}
+
+	resp, err := h.authService.GoogleCallback(c.Request.Context(), code, clientInfo(c))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	h.setAuthCookies(c, resp.AccessToken, resp.RefreshToken)
+	utils.OK(c, resp)
+}
+
+// ==========================================
+// COOKIE HELPERS
+// ==========================================
+
+func (h *AuthHandler) setAuthCookies(c *gin.Context, accessToken, refreshToken string) {
+	h.setCookie(c, authCookieName, accessToken, int(h.cfg.JWT.AccessTokenExpiry.Seconds()), "/")
+	h.setCookie(c, refreshCookieName, refreshToken, int(h.cfg.JWT.RefreshTokenExpiry.Seconds()), refreshCookiePath)
+}
+
+func (h *AuthHandler) clearAuthCookies(c *gin.Context) {
+	h.setCookie(c, authCookieName, "", -1, "/")
+	h.setCookie(c, refreshCookieName, "", -1, refreshCookiePath)
+}
+
+func (h *AuthHandler) setCookie(c *gin.Context, name, value string, maxAge int, path string) {
+	c.SetSameSite(parseSameSite(h.cfg.Cookie.SameSite))
+	c.SetCookie(name, value, maxAge, path, h.cfg.Cookie.Domain, h.cfg.Cookie.Secure, h.cfg.Cookie.HTTPOnly)
+}
+
+func parseSameSite(value string) http.SameSite {
+	switch strings.ToLower(value) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
+
+// clientInfo describes the requesting device. Apps send X-Device-Type and
+// X-Device-Name; browsers fall back to sniffing the User-Agent.
+func clientInfo(c *gin.Context) service.ClientInfo {
+	userAgent := c.Request.UserAgent()
+
+	deviceType := strings.ToLower(c.GetHeader("X-Device-Type"))
+	switch deviceType {
+	case "web", "ios", "android":
+	default:
+		deviceType = "web"
+		if strings.Contains(userAgent, "iPhone") || strings.Contains(userAgent, "iPad") {
+			deviceType = "ios"
+		} else if strings.Contains(userAgent, "Android") {
+			deviceType = "android"
+		}
+	}
+
+	return service.ClientInfo{
+		DeviceType: &deviceType,
+		DeviceName: optionalString(c.GetHeader("X-Device-Name")),
+		IPAddress:  optionalString(c.ClientIP()),
+		UserAgent:  optionalString(userAgent),
+	}
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func randomState() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,284 @@
+package handler
+
+import (
+	"strconv"
+
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/service"
+	"fitness-training-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BookingHandler handles availability, slot and booking request endpoints
+type BookingHandler struct {
+	bookingService service.BookingService
+}
+
+// NewBookingHandler creates a new booking handler
+func NewBookingHandler(bookingService service.BookingService) *BookingHandler {
+	return &BookingHandler{bookingService: bookingService}
+}
+
+// ==========================================
+// AVAILABILITY (TRAINER)
+// ==========================================
+
+// GetAvailability handles GET /trainer/availability
+func (h *BookingHandler) GetAvailability(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	availability, err := h.bookingService.GetAvailability(userID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, availability)
+}
+
+// UpdateAvailability handles PUT /trainer/availability
+func (h *BookingHandler) UpdateAvailability(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateAvailabilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	availability, err := h.bookingService.UpdateAvailability(userID, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, availability)
+}
+
+// GetTimeOff handles GET /trainer/time-off
+func (h *BookingHandler) GetTimeOff(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	periods, err := h.bookingService.GetTimeOff(userID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, periods)
+}
+
+// CreateTimeOff handles POST /trainer/time-off
+func (h *BookingHandler) CreateTimeOff(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateTimeOffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	timeOff, err := h.bookingService.CreateTimeOff(userID, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.Created(c, timeOff)
+}
+
+// DeleteTimeOff handles DELETE /trainer/time-off/:id
+func (h *BookingHandler) DeleteTimeOff(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	timeOffID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.bookingService.DeleteTimeOff(userID, timeOffID); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.NoContent(c)
+}
+
+// ==========================================
+// OPEN SLOTS
+// ==========================================
+
+// GetOpenSlots handles GET /common/trainers/:id/slots?fromDate=&toDate=&duration=60&locationId=
+func (h *BookingHandler) GetOpenSlots(c *gin.Context) {
+	trainerID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	duration, err := strconv.Atoi(c.DefaultQuery("duration", "60"))
+	if err != nil || duration < 15 || duration > 240 {
+		utils.BadRequest(c, "duration must be between 15 and 240 minutes")
+		return
+	}
+
+	var locationID *uint
+	if value := c.Query("locationId"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			utils.BadRequest(c, "Invalid locationId")
+			return
+		}
+		location := uint(id)
+		locationID = &location
+	}
+
+	slots, err := h.bookingService.GetOpenSlots(trainerID, from, to, duration, locationID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, slots)
+}
+
+// ==========================================
+// BOOKING REQUESTS (TRAINEE)
+// ==========================================
+
+// CreateBookingRequest handles POST /trainee/booking-requests
+func (h *BookingHandler) CreateBookingRequest(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	request, err := h.bookingService.CreateBookingRequest(userID, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.Created(c, request)
+}
+
+// GetTraineeBookingRequests handles GET /trainee/booking-requests?status=pending
+func (h *BookingHandler) GetTraineeBookingRequests(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	requests, err := h.bookingService.GetTraineeBookingRequests(userID, c.Query("status"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, requests)
+}
+
+// CancelBookingRequest handles DELETE /trainee/booking-requests/:id
+func (h *BookingHandler) CancelBookingRequest(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	requestID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.bookingService.CancelBookingRequest(userID, requestID); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.NoContent(c)
+}
+
+// ==========================================
+// BOOKING REQUESTS (TRAINER)
+// ==========================================
+
+// GetTrainerBookingRequests handles GET /trainer/booking-requests?status=pending
+func (h *BookingHandler) GetTrainerBookingRequests(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	requests, err := h.bookingService.GetTrainerBookingRequests(userID, c.Query("status"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, requests)
+}
+
+// ConfirmBookingRequest handles POST /trainer/booking-requests/:id/confirm
+func (h *BookingHandler) ConfirmBookingRequest(c *gin.Context) {
+	h.respond(c, h.bookingService.ConfirmBookingRequest)
+}
+
+// DeclineBookingRequest handles POST /trainer/booking-requests/:id/decline
+func (h *BookingHandler) DeclineBookingRequest(c *gin.Context) {
+	h.respond(c, h.bookingService.DeclineBookingRequest)
+}
+
+func (h *BookingHandler) respond(c *gin.Context, action func(uint, uint, *dto.RespondBookingRequest) (*dto.BookingRequestResponse, error)) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	requestID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	// The body is optional
+	var req dto.RespondBookingRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ValidationError(c, err.Error())
+			return
+		}
+	}
+
+	request, err := action(userID, requestID, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, request)
+}
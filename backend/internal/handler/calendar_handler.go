@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/service"
+	"fitness-training-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxCalendarImportSize = 1 << 20 // 1 MB
+
+// CalendarHandler handles iCalendar feed and import endpoints
+type CalendarHandler struct {
+	calendarService service.CalendarService
+}
+
+// NewCalendarHandler creates a new calendar handler
+func NewCalendarHandler(calendarService service.CalendarService) *CalendarHandler {
+	return &CalendarHandler{calendarService: calendarService}
+}
+
+// ==========================================
+// FEED MANAGEMENT
+// ==========================================
+
+// GetFeed handles GET /me/calendar-feed
+func (h *CalendarHandler) GetFeed(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	feed, err := h.calendarService.GetFeed(userID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, feed)
+}
+
+// RotateFeed handles POST /me/calendar-feed/rotate
+func (h *CalendarHandler) RotateFeed(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	feed, err := h.calendarService.RotateFeed(userID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, feed)
+}
+
+// DeleteFeed handles DELETE /me/calendar-feed
+func (h *CalendarHandler) DeleteFeed(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.calendarService.DeleteFeed(userID); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.NoContent(c)
+}
+
+// ==========================================
+// PUBLIC FEED
+// ==========================================
+
+// Feed handles GET /calendar/feeds/:token (e.g. /calendar/feeds/abc123.ics)
+// The token in the URL is the only credential, as calendar apps cannot log in.
+func (h *CalendarHandler) Feed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	body, err := h.calendarService.RenderFeed(token)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", body)
+}
+
+// ==========================================
+// IMPORT
+// ==========================================
+
+// ImportSchedules handles POST /trainer/schedules/import
+// Multipart form: file (.ics), traineeId, timezone, dryRun.
+func (h *CalendarHandler) ImportSchedules(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxCalendarImportSize+64*1024)
+
+	var req dto.ImportSchedulesRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.BadRequest(c, "An .ics file is required in the \"file\" field")
+		return
+	}
+	if fileHeader.Size > maxCalendarImportSize {
+		utils.BadRequest(c, "Calendar file is too large (max 1 MB)")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.BadRequest(c, "Failed to read calendar file")
+		return
+	}
+	defer file.Close()
+
+	resp, err := h.calendarService.ImportSchedules(userID, &req, file)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	if req.DryRun {
+		utils.OK(c, resp)
+		return
+	}
+	utils.Created(c, resp)
+}
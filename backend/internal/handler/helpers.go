@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"fitness-training-backend/internal/middleware"
+	apperrors "fitness-training-backend/pkg/errors"
+	"fitness-training-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+	dateLayout      = "2006-01-02"
+)
+
+// currentUserID returns the authenticated user ID or writes a 401
+func currentUserID(c *gin.Context) (uint, bool) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.Unauthorized(c, "Authentication required")
+		return 0, false
+	}
+	return userID, true
+}
+
+// parseIDParam parses a numeric path parameter or writes a 400
+func parseIDParam(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil || id == 0 {
+		utils.BadRequest(c, "Invalid "+name)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// parsePagination reads page/pageSize query params with sane defaults
+func parsePagination(c *gin.Context) (int, int) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", strconv.Itoa(defaultPageSize)))
+	if err != nil || pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	return page, pageSize
+}
+
+// parseScheduleFilters reads fromDate/toDate (YYYY-MM-DD) and status query params
+func parseScheduleFilters(c *gin.Context) (map[string]interface{}, bool) {
+	filters := map[string]interface{}{}
+
+	for _, key := range []string{"fromDate", "toDate"} {
+		value := c.Query(key)
+		if value == "" {
+			continue
+		}
+		date, err := time.Parse(dateLayout, value)
+		if err != nil {
+			utils.BadRequest(c, key+" must be in YYYY-MM-DD format")
+			return nil, false
+		}
+		filters[key] = date
+	}
+
+	if status := c.Query("status"); status != "" {
+		filters["status"] = status
+	}
+
+	return filters, true
+}
+
+// parseDateRange reads optional fromDate/toDate (YYYY-MM-DD) query params
+func parseDateRange(c *gin.Context) (*time.Time, *time.Time, bool) {
+	var from, to *time.Time
+	for key, target := range map[string]**time.Time{"fromDate": &from, "toDate": &to} {
+		value := c.Query(key)
+		if value == "" {
+			continue
+		}
+		date, err := time.Parse(dateLayout, value)
+		if err != nil {
+			utils.BadRequest(c, key+" must be in YYYY-MM-DD format")
+			return nil, nil, false
+		}
+		*target = &date
+	}
+	return from, to, true
+}
+
+// parseListQuery reads a comma-separated query param as lowercase values
+func parseListQuery(c *gin.Context, name string) []string {
+	values := make([]string, 0)
+	for _, item := range strings.Split(c.Query(name), ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+// handleServiceError maps service errors to HTTP responses
+func handleServiceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, apperrors.ErrNotFound),
+		errors.Is(err, apperrors.ErrUserNotFound),
+		errors.Is(err, apperrors.ErrNoActiveProgram):
+		utils.NotFound(c, err.Error())
+	case errors.Is(err, apperrors.ErrInvalidCredentials),
+		errors.Is(err, apperrors.ErrUnauthorized),
+		errors.Is(err, apperrors.ErrInvalidToken),
+		errors.Is(err, apperrors.ErrExpiredToken),
+		errors.Is(err, apperrors.ErrTokenReused):
+		utils.Unauthorized(c, err.Error())
+	case errors.Is(err, apperrors.ErrForbidden),
+		errors.Is(err, apperrors.ErrClientNotAssigned):
+		utils.Forbidden(c, err.Error())
+	case errors.Is(err, apperrors.ErrEmailAlreadyExists),
+		errors.Is(err, apperrors.ErrAlreadyExists),
+		errors.Is(err, apperrors.ErrConflict),
+		errors.Is(err, apperrors.ErrScheduleConflict):
+		utils.Conflict(c, err.Error())
+	case errors.Is(err, apperrors.ErrWeakPassword),
+		errors.Is(err, apperrors.ErrInvalidEmail),
+		errors.Is(err, apperrors.ErrInvalidInput),
+		errors.Is(err, apperrors.ErrMissingField),
+		errors.Is(err, apperrors.ErrProgramNotActive):
+		utils.BadRequest(c, err.Error())
+	case errors.Is(err, apperrors.ErrFileTooLarge):
+		utils.ErrorResponse(c, http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE", err.Error(), nil)
+	case errors.Is(err, apperrors.ErrUnsupportedMedia):
+		utils.ErrorResponse(c, http.StatusUnsupportedMediaType, "UNSUPPORTED_MEDIA_TYPE", err.Error(), nil)
+	default:
+		log.Printf("❌ %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", apperrors.ErrInternalServer.Error(), nil)
+	}
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+	"fitness-training-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LocationHandler handles location endpoints
+type LocationHandler struct {
+	locationRepo repository.LocationRepository
+}
+
+// NewLocationHandler creates a new location handler
+func NewLocationHandler(locationRepo repository.LocationRepository) *LocationHandler {
+	return &LocationHandler{locationRepo: locationRepo}
+}
+
+// GetLocations handles GET /common/locations
+// Only active locations are listed unless ?all=true is passed.
+func (h *LocationHandler) GetLocations(c *gin.Context) {
+	var (
+		locations []models.Location
+		err       error
+	)
+
+	if c.Query("all") == "true" {
+		locations, err = h.locationRepo.FindAll()
+	} else {
+		locations, err = h.locationRepo.FindActive()
+	}
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	resp := make([]dto.LocationResponse, 0, len(locations))
+	for i := range locations {
+		resp = append(resp, toLocationResponse(&locations[i]))
+	}
+
+	utils.OK(c, resp)
+}
+
+// GetLocationDetail handles GET /common/locations/:id
+func (h *LocationHandler) GetLocationDetail(c *gin.Context) {
+	locationID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	location, err := h.locationRepo.FindByID(locationID)
+	if err != nil {
+		utils.NotFound(c, "Location not found")
+		return
+	}
+
+	utils.OK(c, toLocationResponse(location))
+}
+
+func toLocationResponse(location *models.Location) dto.LocationResponse {
+	return dto.LocationResponse{
+		ID:            location.ID,
+		Name:          location.Name,
+		Address:       location.Address,
+		Floor:         location.Floor,
+		Building:      location.Building,
+		PhoneNumber:   location.PhoneNumber,
+		Email:         location.Email,
+		Latitude:      location.Latitude,
+		Longitude:     location.Longitude,
+		MapURL:        location.MapURL,
+		OpeningHours:  location.OpeningHours,
+		OperatingDays: location.OperatingDays,
+		Facilities:    location.Facilities,
+		Images:        location.Images,
+		IsActive:      location.IsActive,
+	}
+}
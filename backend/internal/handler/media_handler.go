@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"fitness-training-backend/internal/config"
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/service"
+	apperrors "fitness-training-backend/pkg/errors"
+	"fitness-training-backend/pkg/storage"
+	"fitness-training-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// transferTimeout replaces the server's read and write timeouts for uploads
+// and local downloads, which are too short for videos
+const transferTimeout = 10 * time.Minute
+
+// MediaHandler handles uploads and media downloads
+type MediaHandler struct {
+	mediaService  service.MediaService
+	store         storage.Storage
+	maxUploadSize int64
+	urlExpiry     time.Duration
+}
+
+// NewMediaHandler creates a new media handler
+func NewMediaHandler(mediaService service.MediaService, store storage.Storage, cfg *config.Config) *MediaHandler {
+	maxUploadSize := cfg.Media.MaxImageSize
+	if cfg.Media.MaxVideoSize > maxUploadSize {
+		maxUploadSize = cfg.Media.MaxVideoSize
+	}
+	return &MediaHandler{
+		mediaService:  mediaService,
+		store:         store,
+		maxUploadSize: maxUploadSize + 1<<20, // room for the other form fields
+		urlExpiry:     cfg.Media.URLExpiry,
+	}
+}
+
+// Upload handles POST /media (multipart/form-data: file, purpose, locationId)
+func (h *MediaHandler) Upload(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	controller := http.NewResponseController(c.Writer)
+	_ = controller.SetReadDeadline(time.Now().Add(transferTimeout))
+	_ = controller.SetWriteDeadline(time.Now().Add(transferTimeout))
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadSize)
+
+	var req dto.UploadMediaRequest
+	if err := c.ShouldBind(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			handleServiceError(c, fmt.Errorf("%w: the limit is %d MB", apperrors.ErrFileTooLarge, (h.maxUploadSize>>20)-1))
+			return
+		}
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		utils.BadRequest(c, "file is required")
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	defer file.Close()
+
+	media, err := h.mediaService.Upload(userID, &req, header.Filename, file, header.Size)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.Created(c, media)
+}
+
+// Download handles GET /media/:id?variant=thumb|medium by redirecting to a
+// signed download URL
+func (h *MediaHandler) Download(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	mediaID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	signedURL, err := h.mediaService.DownloadURL(userID, mediaID, c.Query("variant"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	// A cached redirect is followed while the signed URL is still valid
+	c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", int(h.urlExpiry.Seconds()/2)))
+	c.Redirect(http.StatusFound, signedURL)
+}
+
+// ServeFile handles GET /files/*key, the signed download URLs of local
+// storage (?expires=&signature=). Range requests are supported for videos.
+func (h *MediaHandler) ServeFile(c *gin.Context) {
+	local, ok := h.store.(*storage.LocalStorage)
+	if !ok {
+		utils.NotFound(c, "File not found")
+		return
+	}
+
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if err := local.Verify(key, c.Query("expires"), c.Query("signature")); err != nil {
+		utils.Forbidden(c, "Invalid or expired download link")
+		return
+	}
+
+	reader, err := local.Get(c.Request.Context(), key)
+	if errors.Is(err, storage.ErrNotFound) {
+		utils.NotFound(c, "File not found")
+		return
+	}
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	defer reader.Close()
+
+	content, ok := reader.(io.ReadSeeker)
+	if !ok {
+		handleServiceError(c, errors.New("local media file is not seekable"))
+		return
+	}
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(transferTimeout))
+	c.Header("Cache-Control", "private, max-age=3600")
+	c.Header("X-Content-Type-Options", "nosniff")
+	http.ServeContent(c.Writer, c.Request, path.Base(key), time.Time{}, content)
+}
@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"strconv"
+
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/service"
+	"fitness-training-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MessageHandler handles trainer-trainee conversation endpoints
+type MessageHandler struct {
+	messageService service.MessageService
+}
+
+// NewMessageHandler creates a new message handler
+func NewMessageHandler(messageService service.MessageService) *MessageHandler {
+	return &MessageHandler{messageService: messageService}
+}
+
+// ==========================================
+// CONVERSATIONS
+// ==========================================
+
+// GetConversations handles GET /conversations
+func (h *MessageHandler) GetConversations(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	conversations, err := h.messageService.GetConversations(userID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, conversations)
+}
+
+// StartConversation handles POST /conversations
+func (h *MessageHandler) StartConversation(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.StartConversationRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ValidationError(c, err.Error())
+			return
+		}
+	}
+
+	conversation, err := h.messageService.StartConversation(userID, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, conversation)
+}
+
+// GetConversation handles GET /conversations/:id
+func (h *MessageHandler) GetConversation(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	conversationID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	conversation, err := h.messageService.GetConversation(userID, conversationID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, conversation)
+}
+
+// ==========================================
+// MESSAGES
+// ==========================================
+
+// GetMessages handles GET /conversations/:id/messages?before=&after=&limit=
+func (h *MessageHandler) GetMessages(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	conversationID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var cursors [2]uint
+	for i, key := range []string{"before", "after"} {
+		value := c.Query(key)
+		if value == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			utils.BadRequest(c, "Invalid "+key)
+			return
+		}
+		cursors[i] = uint(id)
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil {
+		utils.BadRequest(c, "Invalid limit")
+		return
+	}
+
+	page, err := h.messageService.GetMessages(userID, conversationID, cursors[0], cursors[1], limit)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, page)
+}
+
+// SendMessage handles POST /conversations/:id/messages
+func (h *MessageHandler) SendMessage(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	conversationID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.SendMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	message, err := h.messageService.SendMessage(userID, conversationID, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.Created(c, message)
+}
+
+// MarkAsRead handles POST /conversations/:id/read
+func (h *MessageHandler) MarkAsRead(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	conversationID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.MarkConversationReadRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ValidationError(c, err.Error())
+			return
+		}
+	}
+
+	receipt, err := h.messageService.MarkAsRead(userID, conversationID, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, receipt)
+}
@@ -0,0 +1,338 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"fitness-training-backend/internal/config"
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/realtime"
+	"fitness-training-backend/internal/service"
+	"fitness-training-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationHandler handles the notification stream and channel endpoints
+type NotificationHandler struct {
+	notificationService service.NotificationService
+	cfg                 *config.Config
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(notificationService service.NotificationService, cfg *config.Config) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService, cfg: cfg}
+}
+
+// ==========================================
+// STREAM
+// ==========================================
+
+// streamRetry is the reconnect delay suggested to EventSource clients
+const streamRetry = 5 * time.Second
+
+// Stream handles GET /notifications/stream. It is a Server-Sent Events
+// stream of "notification" events (a NotificationResponse) and "unread"
+// events ({"count": n}). Every event carries the ID of the newest
+// notification sent as its event ID, so a reconnecting client that sends
+// Last-Event-ID (or ?lastEventId=) receives what it missed. Comments are sent
+// as keepalives.
+func (h *NotificationHandler) Stream(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	// Subscribe before reading, so nothing created in between is missed
+	subscription, err := h.notificationService.Subscribe(userID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	defer subscription.Close()
+
+	lastID, resume := lastEventID(c)
+	if !resume {
+		if lastID, err = h.notificationService.GetLatestNotificationID(userID); err != nil {
+			handleServiceError(c, err)
+			return
+		}
+	}
+
+	// The server's WriteTimeout would cut the stream off
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("⚠️  Notification stream cannot clear write deadline: %v", err)
+	}
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no") // nginx
+	c.Status(http.StatusOK)
+
+	stream := &notificationStream{c: c, service: h.notificationService, userID: userID, lastID: lastID}
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", streamRetry.Milliseconds())
+	if resume {
+		if err := stream.sendNew(); err != nil {
+			return
+		}
+	}
+	if err := stream.sendUnread(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(h.cfg.Realtime.Heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+
+		case <-heartbeat.C:
+			if err := stream.comment("heartbeat"); err != nil {
+				return
+			}
+
+		case payload, open := <-subscription.C:
+			if !open {
+				// Dropped for falling behind or shutting down; the client
+				// reconnects and resumes
+				return
+			}
+			var event realtime.Event
+			if err := json.Unmarshal(payload, &event); err != nil {
+				continue
+			}
+			if event.Type == realtime.EventNotification {
+				if event.ID <= stream.lastID {
+					continue
+				}
+				if err := stream.sendNew(); err != nil {
+					return
+				}
+			}
+			if err := stream.sendUnread(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// lastEventID returns the ID a reconnecting client has seen up to
+func lastEventID(c *gin.Context) (uint, bool) {
+	value := c.GetHeader("Last-Event-ID")
+	if value == "" {
+		value = c.Query("lastEventId")
+	}
+	id, err := strconv.ParseUint(value, 10, 0)
+	if err != nil {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// notificationStream writes the events of one connection
+type notificationStream struct {
+	c       *gin.Context
+	service service.NotificationService
+	userID  uint
+	lastID  uint
+}
+
+// sendNew sends the notifications created after lastID
+func (s *notificationStream) sendNew() error {
+	for {
+		notifications, err := s.service.GetNotificationsSince(s.userID, s.lastID)
+		if err != nil {
+			return err
+		}
+		for i := range notifications {
+			s.lastID = notifications[i].ID
+			if err := s.event("notification", notifications[i]); err != nil {
+				return err
+			}
+		}
+		if len(notifications) == 0 {
+			return nil
+		}
+	}
+}
+
+// sendUnread sends the unread count
+func (s *notificationStream) sendUnread() error {
+	count, err := s.service.GetUnreadCount(s.userID)
+	if err != nil {
+		return err
+	}
+	return s.event("unread", gin.H{"count": count})
+}
+
+func (s *notificationStream) event(name string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(s.c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", s.lastID, name, payload); err != nil {
+		return err
+	}
+	s.c.Writer.Flush()
+	return nil
+}
+
+func (s *notificationStream) comment(text string) error {
+	if _, err := fmt.Fprintf(s.c.Writer, ": %s\n\n", text); err != nil {
+		return err
+	}
+	s.c.Writer.Flush()
+	return nil
+}
+
+// ==========================================
+// CHANNELS
+// ==========================================
+
+// GetChannels handles GET /me/notification-channels
+func (h *NotificationHandler) GetChannels(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	channels, err := h.notificationService.GetChannels(userID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, channels)
+}
+
+// ==========================================
+// PREFERENCES
+// ==========================================
+
+// GetPreferences handles GET /me/notification-preferences
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	preferences, err := h.notificationService.GetPreferences(userID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, preferences)
+}
+
+// UpdatePreferences handles PUT /me/notification-preferences
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	preferences, err := h.notificationService.UpdatePreferences(userID, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, preferences)
+}
+
+// ==========================================
+// PUSH & LINE
+// ==========================================
+
+// SubscribePush handles POST /me/push-subscriptions
+func (h *NotificationHandler) SubscribePush(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.PushSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	if err := h.notificationService.SubscribePush(userID, &req, c.Request.UserAgent()); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.NoContent(c)
+}
+
+// UnsubscribePush handles DELETE /me/push-subscriptions
+func (h *NotificationHandler) UnsubscribePush(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.DeletePushSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	if err := h.notificationService.UnsubscribePush(userID, req.Endpoint); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.NoContent(c)
+}
+
+// LinkLINEAccount handles PUT /me/line-account
+func (h *NotificationHandler) LinkLINEAccount(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.LinkLINEAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	if err := h.notificationService.LinkLINEAccount(userID, &req); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.NoContent(c)
+}
+
+// UnlinkLINEAccount handles DELETE /me/line-account
+func (h *NotificationHandler) UnlinkLINEAccount(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.notificationService.UnlinkLINEAccount(userID); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.NoContent(c)
+}
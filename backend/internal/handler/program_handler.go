@@ -0,0 +1,522 @@
+package handler
+
+import (
+	"strconv"
+
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/middleware"
+	"fitness-training-backend/internal/service"
+	"fitness-training-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProgramHandler handles program plan, version, catalog, progress note and
+// load suggestion endpoints
+type ProgramHandler struct {
+	programService service.ProgramService
+}
+
+// NewProgramHandler creates a new program handler
+func NewProgramHandler(programService service.ProgramService) *ProgramHandler {
+	return &ProgramHandler{programService: programService}
+}
+
+// ==========================================
+// PLAN
+// ==========================================
+
+// GetPlan handles GET /trainer/programs/:id/weeks
+func (h *ProgramHandler) GetPlan(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	programID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	weeks, err := h.programService.GetPlan(userID, programID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, weeks)
+}
+
+// GetWeekDays handles GET /trainer/programs/:id/weeks/:week/days
+func (h *ProgramHandler) GetWeekDays(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	programID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	week, ok := parseSlotParam(c, "week")
+	if !ok {
+		return
+	}
+
+	days, err := h.programService.GetWeekDays(userID, programID, week)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, days)
+}
+
+// GetDay handles GET /trainer/programs/:id/weeks/:week/days/:day
+func (h *ProgramHandler) GetDay(c *gin.Context) {
+	userID, programID, week, day, ok := parseDayParams(c)
+	if !ok {
+		return
+	}
+
+	programDay, err := h.programService.GetDay(userID, programID, week, day)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, programDay)
+}
+
+// CreateDay handles POST /trainer/programs/:id/weeks/:week/days
+func (h *ProgramHandler) CreateDay(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	programID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	week, ok := parseSlotParam(c, "week")
+	if !ok {
+		return
+	}
+
+	var req dto.CreateProgramDayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	programDay, err := h.programService.CreateDay(userID, programID, week, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.Created(c, programDay)
+}
+
+// UpdateDay handles PUT /trainer/programs/:id/weeks/:week/days/:day
+func (h *ProgramHandler) UpdateDay(c *gin.Context) {
+	userID, programID, week, day, ok := parseDayParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ProgramDayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	programDay, err := h.programService.UpdateDay(userID, programID, week, day, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, programDay)
+}
+
+// DeleteDay handles DELETE /trainer/programs/:id/weeks/:week/days/:day
+func (h *ProgramHandler) DeleteDay(c *gin.Context) {
+	userID, programID, week, day, ok := parseDayParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.programService.DeleteDay(userID, programID, week, day); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.NoContent(c)
+}
+
+// CopyWeek handles POST /trainer/programs/:id/weeks/:week/copy
+func (h *ProgramHandler) CopyWeek(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	programID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	week, ok := parseSlotParam(c, "week")
+	if !ok {
+		return
+	}
+
+	var req dto.CopyProgramWeekRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	weeks, err := h.programService.CopyWeek(userID, programID, week, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, weeks)
+}
+
+// ==========================================
+// VERSIONS
+// ==========================================
+
+// GetVersions handles GET /trainer/programs/:id/versions
+func (h *ProgramHandler) GetVersions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	programID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	versions, err := h.programService.GetVersions(userID, programID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, versions)
+}
+
+// GetVersion handles GET /trainer/programs/:id/versions/:version
+func (h *ProgramHandler) GetVersion(c *gin.Context) {
+	userID, programID, version, ok := parseVersionParams(c)
+	if !ok {
+		return
+	}
+
+	programVersion, err := h.programService.GetVersion(userID, programID, version)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, programVersion)
+}
+
+// DiffVersions handles GET /trainer/programs/:id/versions/:version/diff
+func (h *ProgramHandler) DiffVersions(c *gin.Context) {
+	userID, programID, version, ok := parseVersionParams(c)
+	if !ok {
+		return
+	}
+
+	from := 0
+	if value := c.Query("from"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			utils.BadRequest(c, "Invalid from version")
+			return
+		}
+		from = parsed
+	}
+
+	diff, err := h.programService.DiffVersions(userID, programID, version, from)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, diff)
+}
+
+// MigrateAssignments handles POST /trainer/programs/:id/versions/:version/migrate
+func (h *ProgramHandler) MigrateAssignments(c *gin.Context) {
+	userID, programID, version, ok := parseVersionParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.MigrateAssignmentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	results, err := h.programService.MigrateAssignments(userID, programID, version, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, results)
+}
+
+// ==========================================
+// CATALOG
+// ==========================================
+
+// GetCatalog handles GET /common/programs
+func (h *ProgramHandler) GetCatalog(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	filters := map[string]interface{}{
+		"goal":  c.Query("goal"),
+		"level": c.Query("level"),
+	}
+	for _, name := range []string{"minWeeks", "maxWeeks"} {
+		if value := c.Query(name); value != "" {
+			weeks, err := strconv.Atoi(value)
+			if err != nil || weeks < 1 {
+				utils.BadRequest(c, "Invalid "+name)
+				return
+			}
+			filters[name] = weeks
+		}
+	}
+	if equipment := parseListQuery(c, "equipment"); len(equipment) > 0 {
+		filters["equipment"] = equipment
+	}
+	page, pageSize := parsePagination(c)
+
+	programs, err := h.programService.GetCatalog(userID, filters, page, pageSize)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, programs)
+}
+
+// GetCatalogProgram handles GET /common/programs/:id
+func (h *ProgramHandler) GetCatalogProgram(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	programID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	program, err := h.programService.GetCatalogProgram(userID, programID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, program)
+}
+
+// CloneProgram handles POST /trainer/programs/:id/clone
+func (h *ProgramHandler) CloneProgram(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	programID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	// The body is optional
+	var req dto.CloneProgramRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ValidationError(c, err.Error())
+			return
+		}
+	}
+
+	program, err := h.programService.CloneProgram(userID, programID, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.Created(c, program)
+}
+
+// ==========================================
+// ASSIGNMENTS
+// ==========================================
+
+// GetAssignments handles GET /trainer/assignments
+func (h *ProgramHandler) GetAssignments(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	filters := map[string]interface{}{}
+	if status := c.Query("status"); status != "" {
+		filters["status"] = status
+	}
+	if value := c.Query("behindPlan"); value != "" {
+		behindPlan, err := strconv.ParseBool(value)
+		if err != nil {
+			utils.BadRequest(c, "behindPlan must be true or false")
+			return
+		}
+		filters["behindPlan"] = behindPlan
+	}
+
+	assignments, err := h.programService.GetAssignments(userID, filters)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, assignments)
+}
+
+// ==========================================
+// PROGRESS NOTES
+// ==========================================
+
+// GetProgressNotes handles GET /trainer/assignments/:id/notes
+func (h *ProgramHandler) GetProgressNotes(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	assignmentID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	notes, err := h.programService.GetProgressNotes(userID, assignmentID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, notes)
+}
+
+// CreateProgressNote handles POST /trainer/assignments/:id/notes
+func (h *ProgramHandler) CreateProgressNote(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	assignmentID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.CreateProgressNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	note, err := h.programService.CreateProgressNote(userID, assignmentID, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.Created(c, note)
+}
+
+// DeleteProgressNote handles DELETE /trainer/assignments/:id/notes/:noteId
+func (h *ProgramHandler) DeleteProgressNote(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	assignmentID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	noteID, ok := parseIDParam(c, "noteId")
+	if !ok {
+		return
+	}
+
+	if err := h.programService.DeleteProgressNote(userID, assignmentID, noteID); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.NoContent(c)
+}
+
+// ==========================================
+// PROGRESSION
+// ==========================================
+
+// GetSessionSuggestions handles GET /trainer/schedules/:id/suggestions
+func (h *ProgramHandler) GetSessionSuggestions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	scheduleID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	suggestion, err := h.programService.SuggestSession(userID, scheduleID, c.Query("scheme"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, suggestion)
+}
+
+// parseSlotParam parses a 1-based week or day path parameter or writes a 400
+func parseSlotParam(c *gin.Context, name string) (int, bool) {
+	value, err := strconv.Atoi(c.Param(name))
+	if err != nil || value < 1 {
+		utils.BadRequest(c, "Invalid "+name)
+		return 0, false
+	}
+	return value, true
+}
+
+// parseDayParams reads the user and the :id/:week/:day path of a program day
+func parseDayParams(c *gin.Context) (userID, programID uint, week, day int, ok bool) {
+	if userID, ok = currentUserID(c); !ok {
+		return
+	}
+	if programID, ok = parseIDParam(c, "id"); !ok {
+		return
+	}
+	if week, ok = parseSlotParam(c, "week"); !ok {
+		return
+	}
+	day, ok = parseSlotParam(c, "day")
+	return
+}
+
+// parseVersionParams reads the user and the :id/:version path of a program
+// version
+func parseVersionParams(c *gin.Context) (userID, programID uint, version int, ok bool) {
+	if userID, ok = currentUserID(c); !ok {
+		return
+	}
+	if programID, ok = parseIDParam(c, "id"); !ok {
+		return
+	}
+	version, ok = parseSlotParam(c, "version")
+	return
+}
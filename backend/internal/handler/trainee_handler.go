@@ -0,0 +1,365 @@
+package handler
+
+import (
+	"strconv"
+
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/service"
+	"fitness-training-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TraineeHandler handles trainee (READ-ONLY) endpoints
+type TraineeHandler struct {
+	traineeService service.TraineeService
+}
+
+// NewTraineeHandler creates a new trainee handler
+func NewTraineeHandler(traineeService service.TraineeService) *TraineeHandler {
+	return &TraineeHandler{traineeService: traineeService}
+}
+
+// ==========================================
+// SCHEDULES
+// ==========================================
+
+// GetUpcomingSchedules handles GET /trainee/schedules/upcoming?days=7
+func (h *TraineeHandler) GetUpcomingSchedules(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", "7"))
+	if err != nil || days <= 0 {
+		days = 7
+	}
+
+	schedules, err := h.traineeService.GetUpcomingSchedules(userID, days)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, schedules)
+}
+
+// GetSchedules handles GET /trainee/schedules
+func (h *TraineeHandler) GetSchedules(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	filters, ok := parseScheduleFilters(c)
+	if !ok {
+		return
+	}
+
+	schedules, err := h.traineeService.GetSchedules(userID, filters)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, schedules)
+}
+
+// GetScheduleDetail handles GET /trainee/schedules/:id
+func (h *TraineeHandler) GetScheduleDetail(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	scheduleID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	schedule, err := h.traineeService.GetScheduleDetail(userID, scheduleID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, schedule)
+}
+
+// ==========================================
+// PROGRAMS
+// ==========================================
+
+// GetCurrentProgram handles GET /trainee/programs/current
+func (h *TraineeHandler) GetCurrentProgram(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	program, err := h.traineeService.GetCurrentProgram(userID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, program)
+}
+
+// GetPrograms handles GET /trainee/programs
+func (h *TraineeHandler) GetPrograms(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	programs, err := h.traineeService.GetPrograms(userID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, programs)
+}
+
+// GetProgramDetail handles GET /trainee/programs/:id
+func (h *TraineeHandler) GetProgramDetail(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	programID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	program, err := h.traineeService.GetProgramDetail(userID, programID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, program)
+}
+
+// ==========================================
+// STATS
+// ==========================================
+
+// GetStats handles GET /trainee/stats
+func (h *TraineeHandler) GetStats(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.traineeService.GetStats(userID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, stats)
+}
+
+// ==========================================
+// NOTIFICATIONS
+// ==========================================
+
+// GetNotifications handles GET /trainee/notifications
+func (h *TraineeHandler) GetNotifications(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	page, pageSize := parsePagination(c)
+
+	notifications, err := h.traineeService.GetNotifications(userID, page, pageSize)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, notifications)
+}
+
+// MarkNotificationAsRead handles PUT /trainee/notifications/:id/read
+func (h *TraineeHandler) MarkNotificationAsRead(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	notificationID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.traineeService.MarkNotificationAsRead(userID, notificationID); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, nil)
+}
+
+// MarkAllNotificationsAsRead handles PUT /trainee/notifications/read-all
+func (h *TraineeHandler) MarkAllNotificationsAsRead(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.traineeService.MarkAllNotificationsAsRead(userID); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, nil)
+}
+
+// ==========================================
+// SESSION CARDS
+// ==========================================
+
+// GetSessions handles GET /trainee/sessions
+func (h *TraineeHandler) GetSessions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	page, pageSize := parsePagination(c)
+
+	sessions, err := h.traineeService.GetSessions(userID, page, pageSize)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, sessions)
+}
+
+// GetSessionDetail handles GET /trainee/sessions/:id
+func (h *TraineeHandler) GetSessionDetail(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	sessionID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	session, err := h.traineeService.GetSessionDetail(userID, sessionID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, session)
+}
+
+// SearchSessions handles GET /trainee/sessions/search
+func (h *TraineeHandler) SearchSessions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	req := dto.SearchSessionsRequest{Page: 1, PageSize: defaultPageSize}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	sessions, err := h.traineeService.SearchSessions(userID, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, sessions)
+}
+
+// ==========================================
+// METRICS & PROFILE
+// ==========================================
+
+// GetMetrics handles GET /trainee/metrics?type=weight
+func (h *TraineeHandler) GetMetrics(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var metricType *string
+	if t := c.Query("type"); t != "" {
+		metricType = &t
+	}
+
+	metrics, err := h.traineeService.GetMetrics(userID, metricType)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, metrics)
+}
+
+// GetMetricAnalytics handles GET /trainee/metrics/analytics?fromDate=&toDate=
+func (h *TraineeHandler) GetMetricAnalytics(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	analytics, err := h.traineeService.GetMetricAnalytics(userID, from, to)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, analytics)
+}
+
+// GetRecords handles GET /trainee/records
+func (h *TraineeHandler) GetRecords(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	records, err := h.traineeService.GetRecords(userID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, records)
+}
+
+// GetProfile handles GET /trainee/me
+func (h *TraineeHandler) GetProfile(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	profile, err := h.traineeService.GetProfile(userID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, profile)
+}
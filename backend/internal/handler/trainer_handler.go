@@ -0,0 +1,1034 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/service"
+	apperrors "fitness-training-backend/pkg/errors"
+	"fitness-training-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrainerHandler handles trainer (FULL CRUD) endpoints
+type TrainerHandler struct {
+	trainerService service.TrainerService
+}
+
+// NewTrainerHandler creates a new trainer handler
+func NewTrainerHandler(trainerService service.TrainerService) *TrainerHandler {
+	return &TrainerHandler{trainerService: trainerService}
+}
+
+// ==========================================
+// DASHBOARD
+// ==========================================
+
+// GetDashboardStats handles GET /trainer/dashboard/stats
+func (h *TrainerHandler) GetDashboardStats(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.trainerService.GetDashboardStats(userID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, stats)
+}
+
+// ==========================================
+// CLIENTS
+// ==========================================
+
+// GetClients handles GET /trainer/clients
+func (h *TrainerHandler) GetClients(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	clients, err := h.trainerService.GetClients(userID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, clients)
+}
+
+// GetClientDetail handles GET /trainer/clients/:id
+func (h *TrainerHandler) GetClientDetail(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	traineeID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	client, err := h.trainerService.GetClientDetail(userID, traineeID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, client)
+}
+
+// AddClient handles POST /trainer/clients
+func (h *TrainerHandler) AddClient(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	client, err := h.trainerService.AddClient(userID, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.Created(c, client)
+}
+
+// UpdateClient handles PATCH /trainer/clients/:id
+func (h *TrainerHandler) UpdateClient(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	traineeID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	client, err := h.trainerService.UpdateClient(userID, traineeID, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, client)
+}
+
+// RemoveClient handles DELETE /trainer/clients/:id
+func (h *TrainerHandler) RemoveClient(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	traineeID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.trainerService.RemoveClient(userID, traineeID); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.NoContent(c)
+}
+
+// GetClientMetrics handles GET /trainer/clients/:id/metrics?type=weight
+func (h *TrainerHandler) GetClientMetrics(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	traineeID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var metricType *string
+	if t := c.Query("type"); t != "" {
+		metricType = &t
+	}
+
+	metrics, err := h.trainerService.GetClientMetrics(userID, traineeID, metricType)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, metrics)
+}
+
+// AddClientMetric handles POST /trainer/clients/:id/metrics
+func (h *TrainerHandler) AddClientMetric(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	traineeID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.CreateMetricRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	metric, err := h.trainerService.AddClientMetric(userID, traineeID, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.Created(c, metric)
+}
+
+// GetClientMetricAnalytics handles GET /trainer/clients/:id/metrics/analytics?fromDate=&toDate=
+func (h *TrainerHandler) GetClientMetricAnalytics(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	traineeID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	analytics, err := h.trainerService.GetClientMetricAnalytics(userID, traineeID, from, to)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, analytics)
+}
+
+// SetClientMetricGoal handles PUT /trainer/clients/:id/metric-goals
+func (h *TrainerHandler) SetClientMetricGoal(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	traineeID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.SetMetricGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	goal, err := h.trainerService.SetClientMetricGoal(userID, traineeID, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, goal)
+}
+
+// DeleteClientMetricGoal handles DELETE /trainer/clients/:id/metric-goals/:goalId
+func (h *TrainerHandler) DeleteClientMetricGoal(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	traineeID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	goalID, ok := parseIDParam(c, "goalId")
+	if !ok {
+		return
+	}
+
+	if err := h.trainerService.DeleteClientMetricGoal(userID, traineeID, goalID); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.NoContent(c)
+}
+
+// maxMetricImportSize limits body-composition exports; a year of daily scans
+// of a large gym is well below it
+const maxMetricImportSize = 5 << 20
+
+// PreviewMetricImport handles POST /trainer/metric-imports/preview: a
+// multipart form with the export as "file" and an optional JSON "mapping"
+func (h *TrainerHandler) PreviewMetricImport(c *gin.Context) {
+	h.importMetrics(c, false)
+}
+
+// CommitMetricImport handles POST /trainer/metric-imports with the same form
+// as the preview
+func (h *TrainerHandler) CommitMetricImport(c *gin.Context) {
+	h.importMetrics(c, true)
+}
+
+func (h *TrainerHandler) importMetrics(c *gin.Context, commit bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxMetricImportSize+1<<20)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			handleServiceError(c, fmt.Errorf("%w: the limit is %d MB", apperrors.ErrFileTooLarge, maxMetricImportSize>>20))
+			return
+		}
+		utils.BadRequest(c, "file is required")
+		return
+	}
+	if header.Size > maxMetricImportSize {
+		handleServiceError(c, fmt.Errorf("%w: the limit is %d MB", apperrors.ErrFileTooLarge, maxMetricImportSize>>20))
+		return
+	}
+
+	var mapping *dto.MetricImportMapping
+	if raw := c.PostForm("mapping"); strings.TrimSpace(raw) != "" {
+		mapping = &dto.MetricImportMapping{}
+		if err := json.Unmarshal([]byte(raw), mapping); err != nil {
+			utils.ValidationError(c, "mapping: "+err.Error())
+			return
+		}
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	defer file.Close()
+
+	var result *dto.MetricImportResponse
+	if commit {
+		result, err = h.trainerService.CommitMetricImport(userID, file, mapping)
+	} else {
+		result, err = h.trainerService.PreviewMetricImport(userID, file, mapping)
+	}
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, result)
+}
+
+// GetClientSessions handles GET /trainer/clients/:id/sessions
+func (h *TrainerHandler) GetClientSessions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	traineeID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	page, pageSize := parsePagination(c)
+
+	sessions, err := h.trainerService.GetClientSessions(userID, traineeID, page, pageSize)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, sessions)
+}
+
+// GetClientRecords handles GET /trainer/clients/:id/records
+func (h *TrainerHandler) GetClientRecords(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	traineeID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	records, err := h.trainerService.GetClientRecords(userID, traineeID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, records)
+}
+
+// ==========================================
+// SCHEDULES
+// ==========================================
+
+// GetSchedules handles GET /trainer/schedules
+func (h *TrainerHandler) GetSchedules(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	filters, ok := parseScheduleFilters(c)
+	if !ok {
+		return
+	}
+
+	schedules, err := h.trainerService.GetSchedules(userID, filters)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, schedules)
+}
+
+// GetScheduleDetail handles GET /trainer/schedules/:id
+func (h *TrainerHandler) GetScheduleDetail(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	scheduleID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	schedule, err := h.trainerService.GetScheduleDetail(userID, scheduleID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, schedule)
+}
+
+// CreateSchedule handles POST /trainer/schedules
+func (h *TrainerHandler) CreateSchedule(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	schedule, err := h.trainerService.CreateSchedule(userID, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.Created(c, schedule)
+}
+
+// UpdateSchedule handles PATCH /trainer/schedules/:id
+// For recurring schedules, "scope" selects this, following or all occurrences.
+func (h *TrainerHandler) UpdateSchedule(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	scheduleID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	schedule, err := h.trainerService.UpdateSchedule(userID, scheduleID, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, schedule)
+}
+
+// CancelSchedule handles DELETE /trainer/schedules/:id
+// An optional JSON body {"reason": "...", "scope": "this|following|all"}
+// records the reason and, for recurring schedules, which occurrences to cancel.
+func (h *TrainerHandler) CancelSchedule(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	scheduleID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.CancelScheduleRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ValidationError(c, err.Error())
+			return
+		}
+	}
+
+	if err := h.trainerService.CancelSchedule(userID, scheduleID, &req); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.NoContent(c)
+}
+
+// GetScheduleSeries handles GET /trainer/schedule-series?status=active
+func (h *TrainerHandler) GetScheduleSeries(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	series, err := h.trainerService.GetScheduleSeries(userID, c.Query("status"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, series)
+}
+
+// GetScheduleSeriesDetail handles GET /trainer/schedule-series/:id
+func (h *TrainerHandler) GetScheduleSeriesDetail(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	seriesID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	series, err := h.trainerService.GetScheduleSeriesDetail(userID, seriesID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, series)
+}
+
+// CreateScheduleSeries handles POST /trainer/schedule-series
+func (h *TrainerHandler) CreateScheduleSeries(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateScheduleSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	series, err := h.trainerService.CreateScheduleSeries(userID, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.Created(c, series)
+}
+
+// ==========================================
+// SESSION CARDS
+// ==========================================
+
+// GetSessions handles GET /trainer/sessions
+func (h *TrainerHandler) GetSessions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	page, pageSize := parsePagination(c)
+
+	sessions, err := h.trainerService.GetSessions(userID, page, pageSize)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, sessions)
+}
+
+// GetSessionDetail handles GET /trainer/sessions/:id
+func (h *TrainerHandler) GetSessionDetail(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	sessionID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	session, err := h.trainerService.GetSessionDetail(userID, sessionID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, session)
+}
+
+// CreateSessionCard handles POST /trainer/sessions
+func (h *TrainerHandler) CreateSessionCard(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateSessionCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	session, err := h.trainerService.CreateSessionCard(userID, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.Created(c, session)
+}
+
+// UpdateSessionCard handles PATCH /trainer/sessions/:id
+func (h *TrainerHandler) UpdateSessionCard(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	sessionID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateSessionCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	session, err := h.trainerService.UpdateSessionCard(userID, sessionID, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, session)
+}
+
+// DeleteSessionCard handles DELETE /trainer/sessions/:id
+func (h *TrainerHandler) DeleteSessionCard(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	sessionID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.trainerService.DeleteSessionCard(userID, sessionID); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.NoContent(c)
+}
+
+// ==========================================
+// PROGRAMS
+// ==========================================
+
+// GetPrograms handles GET /trainer/programs
+func (h *TrainerHandler) GetPrograms(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	programs, err := h.trainerService.GetPrograms(userID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, programs)
+}
+
+// GetProgramDetail handles GET /trainer/programs/:id
+func (h *TrainerHandler) GetProgramDetail(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	programID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	program, err := h.trainerService.GetProgramDetail(userID, programID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, program)
+}
+
+// CreateProgram handles POST /trainer/programs
+func (h *TrainerHandler) CreateProgram(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateProgramRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	program, err := h.trainerService.CreateProgram(userID, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.Created(c, program)
+}
+
+// UpdateProgram handles PATCH /trainer/programs/:id
+func (h *TrainerHandler) UpdateProgram(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	programID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateProgramRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	program, err := h.trainerService.UpdateProgram(userID, programID, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, program)
+}
+
+// DeleteProgram handles DELETE /trainer/programs/:id
+func (h *TrainerHandler) DeleteProgram(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	programID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.trainerService.DeleteProgram(userID, programID); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.NoContent(c)
+}
+
+// AssignProgram handles POST /trainer/programs/:id/assign
+func (h *TrainerHandler) AssignProgram(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	programID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.AssignProgramRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	assignment, err := h.trainerService.AssignProgram(userID, programID, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.Created(c, assignment)
+}
+
+// ==========================================
+// EXERCISE LIBRARY
+// ==========================================
+
+// GetExercises handles GET /trainer/exercises?search=&owner=&category=&muscleGroup=&equipment=&difficulty=&sort=&page=&pageSize=
+func (h *TrainerHandler) GetExercises(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	filters := map[string]interface{}{
+		"search": strings.TrimSpace(c.Query("search")),
+	}
+	switch owner := c.Query("owner"); owner {
+	case "", "mine", "library", "shared":
+		filters["owner"] = owner
+	default:
+		utils.BadRequest(c, "owner must be mine, library or shared")
+		return
+	}
+	for _, name := range []string{"category", "muscleGroup", "equipment", "difficulty"} {
+		if values := parseListQuery(c, name); len(values) > 0 {
+			filters[name] = values
+		}
+	}
+
+	sort := c.Query("sort")
+	switch sort {
+	case "", "relevance", "name", "usage", "newest":
+	default:
+		utils.BadRequest(c, "sort must be relevance, name, usage or newest")
+		return
+	}
+	page, pageSize := parsePagination(c)
+
+	exercises, err := h.trainerService.GetExercises(userID, filters, sort, page, pageSize)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, exercises)
+}
+
+// CreateExercise handles POST /trainer/exercises
+func (h *TrainerHandler) CreateExercise(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateExerciseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	exercise, err := h.trainerService.CreateExercise(userID, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.Created(c, exercise)
+}
+
+// UpdateExercise handles PATCH /trainer/exercises/:id
+func (h *TrainerHandler) UpdateExercise(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	exerciseID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateExerciseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, err.Error())
+		return
+	}
+
+	exercise, err := h.trainerService.UpdateExercise(userID, exerciseID, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, exercise)
+}
+
+// DeleteExercise handles DELETE /trainer/exercises/:id
+func (h *TrainerHandler) DeleteExercise(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	exerciseID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.trainerService.DeleteExercise(userID, exerciseID); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.NoContent(c)
+}
+
+// ==========================================
+// ANALYTICS
+// ==========================================
+
+// GetAnalyticsOverview handles GET /trainer/analytics/overview
+func (h *TrainerHandler) GetAnalyticsOverview(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	overview, err := h.trainerService.GetAnalyticsOverview(userID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, overview)
+}
+
+// GetClientAnalytics handles GET /trainer/analytics/clients/:id
+func (h *TrainerHandler) GetClientAnalytics(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	traineeID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	analytics, err := h.trainerService.GetClientAnalytics(userID, traineeID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, analytics)
+}
+
+// ==========================================
+// COMMON (PUBLIC BROWSING)
+// ==========================================
+
+// GetTrainers handles GET /common/trainers?availability=available
+func (h *TrainerHandler) GetTrainers(c *gin.Context) {
+	filters := map[string]interface{}{
+		"availability": c.Query("availability"),
+	}
+
+	trainers, err := h.trainerService.GetTrainers(filters)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, trainers)
+}
+
+// GetTrainerDetail handles GET /common/trainers/:id
+func (h *TrainerHandler) GetTrainerDetail(c *gin.Context) {
+	trainerID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	trainer, err := h.trainerService.GetTrainerDetail(trainerID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, trainer)
+}
+
+// GetExerciseCategories handles GET /common/exercises/categories
+func (h *TrainerHandler) GetExerciseCategories(c *gin.Context) {
+	categories, err := h.trainerService.GetExerciseCategories()
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.OK(c, categories)
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"fitness-training-backend/internal/service"
+	apperrors "fitness-training-backend/pkg/errors"
+	"fitness-training-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxWorkoutImportSize limits activity files; a FIT file of a day-long ride
+// with one-second records is well below it
+const maxWorkoutImportSize = 10 << 20
+
+// WorkoutHandler handles activity files uploaded by trainees
+type WorkoutHandler struct {
+	workoutService service.WorkoutService
+}
+
+// NewWorkoutHandler creates a new workout handler
+func NewWorkoutHandler(workoutService service.WorkoutService) *WorkoutHandler {
+	return &WorkoutHandler{workoutService: workoutService}
+}
+
+// ImportWorkout handles POST /trainee/workouts/import: a multipart form with
+// a GPX, TCX or FIT file as "file"
+func (h *WorkoutHandler) ImportWorkout(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxWorkoutImportSize+1<<20)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			handleServiceError(c, fmt.Errorf("%w: the limit is %d MB", apperrors.ErrFileTooLarge, maxWorkoutImportSize>>20))
+			return
+		}
+		utils.BadRequest(c, "file is required")
+		return
+	}
+	if header.Size > maxWorkoutImportSize {
+		handleServiceError(c, fmt.Errorf("%w: the limit is %d MB", apperrors.ErrFileTooLarge, maxWorkoutImportSize>>20))
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	defer file.Close()
+
+	result, err := h.workoutService.ImportWorkout(userID, file)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	utils.Created(c, result)
+}
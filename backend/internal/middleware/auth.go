@@ -15,6 +15,8 @@ const (
 	ContextUserRoleKey = "userRole"
 	// ContextUserEmailKey is the key for user email in context
 	ContextUserEmailKey = "userEmail"
+	// ContextSessionIDKey is the key for the login session (refresh token family) in context
+	ContextSessionIDKey = "sessionID"
 )
 
 // AuthMiddleware validates JWT token
@@ -52,6 +54,7 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 		c.Set(ContextUserIDKey, claims.UserID)
 		c.Set(ContextUserRoleKey, claims.Role)
 		c.Set(ContextUserEmailKey, claims.Email)
+		c.Set(ContextSessionIDKey, claims.SessionID)
 		
 		c.Next()
 	}
@@ -84,6 +87,15 @@ func GetUserEmail(c *gin.Context) (string, bool) {
 	return email.(string), true
 }
 
+// GetSessionID retrieves the login session ID from context
+func GetSessionID(c *gin.Context) string {
+	sessionID, exists := c.Get(ContextSessionIDKey)
+	if !exists {
+		return ""
+	}
+	return sessionID.(string)
+}
+
 // OptionalAuth middleware that doesn't require authentication but extracts user if present
 func OptionalAuth(cfg *config.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
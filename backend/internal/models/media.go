@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+)
+
+// MediaFile is an uploaded file kept in media storage. Records refer to it
+// by its stable API URL (/api/v1/media/:id), which redirects to a signed,
+// time-limited download URL. Images also get scaled-down variants, stored
+// as media files of their own pointing at the original.
+type MediaFile struct {
+	ID     uint `gorm:"primaryKey" json:"id"`
+	UserID uint `gorm:"not null;index" json:"userId"` // uploader
+
+	// original, or a scaled-down variant (thumb, medium) of ParentID
+	Variant  string `gorm:"type:varchar(20);not null;default:'original'" json:"variant"`
+	ParentID *uint  `gorm:"index" json:"parentId"`
+
+	// What the file is for (exercise, location, profile or message) and, once
+	// saved there, the exercise, location, user or message using it. Originals
+	// nothing uses are removed by the media cleanup.
+	Purpose    string `gorm:"type:varchar(20);not null;index:idx_media_files_attachment" json:"purpose"`
+	AttachedID *uint  `gorm:"index:idx_media_files_attachment" json:"attachedId"`
+
+	StorageKey  string `gorm:"type:varchar(255);not null;uniqueIndex" json:"-"`
+	FileName    string `gorm:"type:varchar(255);not null;default:''" json:"fileName"` // as uploaded, without its directory
+	ContentType string `gorm:"type:varchar(100);not null" json:"contentType"`
+	SizeBytes   int64  `gorm:"not null" json:"sizeBytes"`
+	Width       *int   `json:"width"`
+	Height      *int   `json:"height"`
+
+	// Timestamps
+	CreatedAt time.Time `gorm:"index" json:"createdAt"`
+
+	// Relationships
+	Variants []MediaFile `gorm:"foreignKey:ParentID" json:"variants,omitempty"`
+}
+
+func (MediaFile) TableName() string {
+	return "media_files"
+}
+
+// VariantNamed returns the variant with the given name, or nil.
+// Variants must be loaded.
+func (m *MediaFile) VariantNamed(name string) *MediaFile {
+	for i := range m.Variants {
+		if m.Variants[i].Variant == name {
+			return &m.Variants[i]
+		}
+	}
+	return nil
+}
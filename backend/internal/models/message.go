@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+)
+
+// Conversation is the message thread between a trainer and one of their
+// trainees. There is one per pair; it stays readable after the trainee
+// moves to another trainer, but only the current pair can write to it.
+type Conversation struct {
+	ID        uint `gorm:"primaryKey" json:"id"`
+	TrainerID uint `gorm:"not null;uniqueIndex:idx_conversations_pair" json:"trainerId"`
+	TraineeID uint `gorm:"not null;uniqueIndex:idx_conversations_pair;index" json:"traineeId"`
+
+	// Cached for sorting the inbox
+	LastMessageAt *time.Time `gorm:"index" json:"lastMessageAt"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Relationships
+	Trainer Trainer `gorm:"foreignKey:TrainerID" json:"trainer"`
+	Trainee Trainee `gorm:"foreignKey:TraineeID" json:"trainee"`
+}
+
+func (Conversation) TableName() string {
+	return "conversations"
+}
+
+// HasParticipant reports whether the user is the trainer or the trainee.
+// Trainer and Trainee must be loaded.
+func (c *Conversation) HasParticipant(userID uint) bool {
+	return c.Trainer.UserID == userID || c.Trainee.UserID == userID
+}
+
+// RecipientUserID returns the user ID of the other participant
+func (c *Conversation) RecipientUserID(senderUserID uint) uint {
+	if c.Trainer.UserID == senderUserID {
+		return c.Trainee.UserID
+	}
+	return c.Trainer.UserID
+}
+
+// Message is one message in a conversation. It can refer to the session it
+// is about and carry attachments.
+type Message struct {
+	ID             uint   `gorm:"primaryKey" json:"id"`
+	ConversationID uint   `gorm:"not null;index" json:"conversationId"`
+	SenderUserID   uint   `gorm:"not null" json:"senderUserId"`
+	Body           string `gorm:"type:text;not null" json:"body"`
+
+	// What the message is about (optional, same trainer and trainee)
+	ScheduleID    *uint `gorm:"index" json:"scheduleId"`
+	SessionCardID *uint `gorm:"index" json:"sessionCardId"`
+
+	// Read receipt: when the recipient read it
+	ReadAt *time.Time `json:"readAt"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Relationships
+	Sender      User                `gorm:"foreignKey:SenderUserID" json:"-"`
+	Attachments []MessageAttachment `gorm:"foreignKey:MessageID" json:"attachments"`
+}
+
+func (Message) TableName() string {
+	return "messages"
+}
+
+// MessageAttachment references an uploaded file by URL
+type MessageAttachment struct {
+	ID          uint    `gorm:"primaryKey" json:"id"`
+	MessageID   uint    `gorm:"not null;index" json:"messageId"`
+	URL         string  `gorm:"type:text;not null" json:"url"`
+	FileName    string  `gorm:"type:varchar(255);not null" json:"fileName"`
+	ContentType *string `gorm:"type:varchar(100)" json:"contentType"`
+	SizeBytes   *int64  `json:"sizeBytes"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (MessageAttachment) TableName() string {
+	return "message_attachments"
+}
@@ -62,15 +62,31 @@ type Program struct {
 	Goals             pq.StringArray `gorm:"type:text[]" json:"goals"`
 	TargetFitnessLevel *string       `gorm:"type:varchar(20)" json:"targetFitnessLevel"` // 'beginner', 'intermediate', 'advanced'
 	
-	// Schedule
-	WeeklySchedule *string `gorm:"type:jsonb" json:"weeklySchedule"` // JSON array
-	
 	// Status
 	Status string `gorm:"type:varchar(20);default:'draft'" json:"status"` // 'draft', 'active', 'archived'
 	
-	// Stats
+	// Sharing: who can browse and clone the program once it is active
+	Visibility string `gorm:"type:varchar(10);not null;default:'private';index" json:"visibility"` // 'private', 'gym' (all trainers), 'public' (anyone)
+	
+	// Attribution of a cloned program
+	ClonedFromID      *uint `gorm:"index" json:"clonedFromId"` // program it was copied from
+	ClonedFromVersion *int  `json:"clonedFromVersion"`
+	OriginalAuthorID  *uint `json:"originalAuthorId"` // trainer who wrote the first program of the chain
+	
+	// Newest version; the content fields above mirror it (see ProgramVersion)
+	Version int `gorm:"not null;default:1" json:"version"`
+	
+	// Stats (all versions)
 	TotalAssignments int     `gorm:"default:0" json:"totalAssignments"`
 	CompletionRate   float32 `gorm:"type:decimal(5,2);default:0.00" json:"completionRate"`
+	CloneCount       int     `gorm:"default:0" json:"cloneCount"`
+	
+	// Adherence (%) below which an assignment is flagged as behind plan
+	AdherenceThreshold float32 `gorm:"type:decimal(5,2);not null;default:70" json:"adherenceThreshold"`
+	
+	// Progressive overload: how the next session's loads are suggested
+	ProgressionScheme    string  `gorm:"type:varchar(20);not null;default:'double_progression'" json:"progressionScheme"` // 'double_progression', 'rpe', 'percent_1rm'
+	ProgressionIncrement float32 `gorm:"type:decimal(5,2);not null;default:2.5" json:"progressionIncrement"`            // kg added when a rep range is mastered
 	
 	// Timestamps
 	CreatedAt time.Time      `json:"createdAt"`
@@ -78,8 +94,10 @@ type Program struct {
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 	
 	// Relationships
-	Trainer     Trainer             `gorm:"foreignKey:TrainerID" json:"trainer"`
-	Assignments []ProgramAssignment `gorm:"foreignKey:ProgramID" json:"-"`
+	Trainer        Trainer             `gorm:"foreignKey:TrainerID" json:"trainer"`
+	OriginalAuthor *Trainer            `gorm:"foreignKey:OriginalAuthorID" json:"-"`
+	Assignments    []ProgramAssignment `gorm:"foreignKey:ProgramID" json:"-"`
+	Versions       []ProgramVersion    `gorm:"foreignKey:ProgramID" json:"-"` // with the weekly plans, see program.go
 }
 
 func (Program) TableName() string {
@@ -92,6 +110,9 @@ type ProgramAssignment struct {
 	ProgramID uint `gorm:"not null;index" json:"programId"`
 	TraineeID uint `gorm:"not null;index" json:"traineeId"`
 	
+	// Version the assignment is pinned to
+	ProgramVersionID uint `gorm:"not null;index" json:"programVersionId"`
+	
 	// Timeline
 	StartDate   time.Time `gorm:"not null" json:"startDate"`
 	EndDate     time.Time `gorm:"not null" json:"endDate"`
@@ -102,12 +123,16 @@ type ProgramAssignment struct {
 	SessionsCompleted  int     `gorm:"default:0" json:"sessionsCompleted"`
 	TotalSessions      int     `gorm:"not null" json:"totalSessions"`
 	
+	// Adherence: completed / due sessions (nil until a session is due)
+	AdherenceRate   *float32   `gorm:"type:decimal(5,2)" json:"adherenceRate"`
+	BehindPlan      bool       `gorm:"default:false" json:"behindPlan"`
+	BehindPlanSince *time.Time `gorm:"type:date" json:"behindPlanSince"`
+	
 	// Status
 	Status string `gorm:"type:varchar(20);default:'active'" json:"status"` // 'active', 'completed', 'paused', 'cancelled'
 	
 	// Notes
-	Notes         *string `gorm:"type:text" json:"notes"`
-	ProgressNotes *string `gorm:"type:jsonb" json:"progressNotes"` // JSON array
+	Notes *string `gorm:"type:text" json:"notes"`
 	
 	// Timestamps
 	CreatedAt time.Time      `json:"createdAt"`
@@ -115,9 +140,11 @@ type ProgramAssignment struct {
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 	
 	// Relationships
-	Program   Program   `gorm:"foreignKey:ProgramID" json:"program"`
-	Trainee   Trainee   `gorm:"foreignKey:TraineeID" json:"trainee"`
-	Schedules []Schedule `gorm:"foreignKey:ProgramAssignmentID" json:"-"`
+	Program       Program               `gorm:"foreignKey:ProgramID" json:"program"`
+	ProgramVersion ProgramVersion       `gorm:"foreignKey:ProgramVersionID" json:"-"`
+	Trainee       Trainee               `gorm:"foreignKey:TraineeID" json:"trainee"`
+	Schedules     []Schedule            `gorm:"foreignKey:ProgramAssignmentID" json:"-"`
+	ProgressNotes []ProgramProgressNote `gorm:"foreignKey:ProgramAssignmentID" json:"progressNotes,omitempty"`
 }
 
 func (ProgramAssignment) TableName() string {
@@ -129,13 +156,17 @@ type SessionCard struct {
 	ID         uint `gorm:"primaryKey" json:"id"`
 	ScheduleID uint `gorm:"uniqueIndex;not null" json:"scheduleId"`
 	TrainerID  uint `gorm:"not null;index" json:"trainerId"`
-	TraineeID  uint `gorm:"not null;index" json:"traineeId"`
+	TraineeID  uint `gorm:"not null;index;uniqueIndex:idx_session_cards_trainee_import" json:"traineeId"`
 	
 	// Session Info
 	Date     time.Time `gorm:"not null;index" json:"date"`
 	Title    string    `gorm:"not null" json:"title"`
 	Duration int       `gorm:"not null" json:"duration"` // minutes
 	
+	// Import (activity file the card was created from)
+	Source    *string `gorm:"type:varchar(10)" json:"source"`                                     // 'gpx', 'tcx', 'fit'; NULL when recorded by the trainer
+	ImportKey *string `gorm:"type:varchar(64);uniqueIndex:idx_session_cards_trainee_import" json:"-"` // Identifies the activity, so it is imported once
+	
 	// Feedback
 	OverallFeedback  *string        `gorm:"type:text" json:"overallFeedback"`
 	NextSessionGoals pq.StringArray `gorm:"type:text[]" json:"nextSessionGoals"`
@@ -144,7 +175,7 @@ type SessionCard struct {
 	TrainerRating *int `json:"trainerRating"` // 1-5
 	TraineeRating *int `json:"traineeRating"` // 1-5
 	
-	// Stats
+	// Stats (Calculated from sets, see RecalculateTotals)
 	TotalExercises int     `gorm:"default:0" json:"totalExercises"`
 	TotalSets      int     `gorm:"default:0" json:"totalSets"`
 	TotalVolume    float32 `gorm:"type:decimal(10,2);default:0.00" json:"totalVolume"` // kg
@@ -165,6 +196,53 @@ func (SessionCard) TableName() string {
 	return "session_cards"
 }
 
+// RecalculateTotals derives the card and exercise totals from the sets.
+// Only completed sets count. Sets without a weight (bodyweight) add reps but
+// no volume; cardio sets add duration and distance. An exercise counts
+// towards TotalExercises once it has a completed set.
+func (c *SessionCard) RecalculateTotals() {
+	c.TotalExercises = 0
+	c.TotalSets = 0
+	c.TotalVolume = 0
+
+	for i := range c.Exercises {
+		exercise := &c.Exercises[i]
+		exercise.TotalSets = 0
+		exercise.TotalReps = 0
+		exercise.TotalWeight = 0
+		exercise.TotalVolume = 0
+		exercise.TotalDuration = 0
+		exercise.TotalDistance = 0
+
+		for _, set := range exercise.Sets {
+			if !set.Completed {
+				continue
+			}
+			exercise.TotalSets++
+
+			if set.Reps != nil && *set.Reps > 0 {
+				exercise.TotalReps += *set.Reps
+				if set.Weight != nil && *set.Weight > 0 {
+					exercise.TotalWeight += *set.Weight
+					exercise.TotalVolume += *set.Weight * float32(*set.Reps)
+				}
+			}
+			if set.Duration != nil && *set.Duration > 0 {
+				exercise.TotalDuration += *set.Duration
+			}
+			if set.Distance != nil && *set.Distance > 0 {
+				exercise.TotalDistance += *set.Distance
+			}
+		}
+
+		if exercise.TotalSets > 0 {
+			c.TotalExercises++
+		}
+		c.TotalSets += exercise.TotalSets
+		c.TotalVolume += exercise.TotalVolume
+	}
+}
+
 // SessionExercise represents an exercise in a session
 type SessionExercise struct {
 	ID            uint  `gorm:"primaryKey" json:"id"`
@@ -188,6 +266,15 @@ type SessionExercise struct {
 	TotalWeight float32 `gorm:"type:decimal(10,2);default:0.00" json:"totalWeight"`
 	TotalVolume float32 `gorm:"type:decimal(10,2);default:0.00" json:"totalVolume"`
 	
+	// Cardio Stats (Calculated from sets)
+	TotalDuration int     `gorm:"default:0" json:"totalDuration"`                               // seconds
+	TotalDistance float32 `gorm:"type:decimal(10,2);default:0.00" json:"totalDistance"` // km
+	
+	// Heart Rate (from imported activities)
+	AvgHeartRate   *int          `json:"avgHeartRate"` // bpm
+	MaxHeartRate   *int          `json:"maxHeartRate"`
+	HeartRateZones pq.Int64Array `gorm:"type:integer[]" json:"heartRateZones"` // seconds in zones 1-5
+	
 	// Personal Records
 	IsPR   bool    `gorm:"default:false" json:"isPR"`
 	PRNote *string `gorm:"type:text" json:"prNote"`
@@ -219,14 +306,16 @@ type ExerciseSet struct {
 	Weight *float32 `gorm:"type:decimal(6,2)" json:"weight"` // kg
 	
 	// Cardio/Endurance
-	Duration *int     `json:"duration"` // seconds
-	Distance *float32 `gorm:"type:decimal(6,2)" json:"distance"` // km
+	Duration     *int     `json:"duration"` // seconds
+	Distance     *float32 `gorm:"type:decimal(6,2)" json:"distance"` // km
+	AvgHeartRate *int     `json:"avgHeartRate"` // bpm
+	MaxHeartRate *int     `json:"maxHeartRate"`
 	
 	// Rest
 	RestDuration *int `json:"restDuration"` // seconds
 	
 	// Completion
-	Completed bool `gorm:"default:true" json:"completed"`
+	Completed bool `json:"completed"` // no GORM default tag: GORM would store false as the default
 	
 	// RPE (Rate of Perceived Exertion)
 	RPE *int `json:"rpe"` // 1-10
@@ -295,7 +384,7 @@ func (ExerciseLibrary) TableName() string {
 // Metric represents a body measurement
 type Metric struct {
 	ID        uint `gorm:"primaryKey" json:"id"`
-	TraineeID uint `gorm:"not null;index" json:"traineeId"`
+	TraineeID uint `gorm:"not null;index;uniqueIndex:idx_metrics_trainee_import" json:"traineeId"`
 	
 	// Measurement
 	Date  time.Time `gorm:"not null;index" json:"date"`
@@ -312,19 +401,53 @@ type Metric struct {
 	// Recorded By
 	RecordedBy *uint `json:"recordedBy"` // user_id
 	
+	// Identifies a value imported from a device export, so importing the
+	// same file again adds nothing
+	ImportKey *string `gorm:"type:varchar(64);uniqueIndex:idx_metrics_trainee_import" json:"-"`
+	
 	// Timestamps
 	CreatedAt time.Time      `json:"createdAt"`
 	UpdatedAt time.Time      `json:"updatedAt"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 	
 	// Relationships
-	Trainee Trainee `gorm:"foreignKey:TraineeID" json:"-"`
+	Trainee  Trainee `gorm:"foreignKey:TraineeID" json:"-"`
+	Recorder *User   `gorm:"foreignKey:RecordedBy" json:"-"`
 }
 
 func (Metric) TableName() string {
 	return "metrics"
 }
 
+// MetricGoal is a trainer-set target for one of a trainee's body metrics,
+// either a recorded type or a derived one ('bmi', 'lean_mass',
+// 'waist_to_height'). A trainee has one goal per type and measurement.
+type MetricGoal struct {
+	ID        uint `gorm:"primaryKey" json:"id"`
+	TraineeID uint `gorm:"not null;uniqueIndex:idx_metric_goals_unique" json:"traineeId"`
+	
+	// Target
+	Type            string     `gorm:"type:varchar(30);not null;uniqueIndex:idx_metric_goals_unique" json:"type"`
+	MeasurementType string     `gorm:"type:varchar(50);not null;default:'';uniqueIndex:idx_metric_goals_unique" json:"measurementType"` // '' unless type = 'measurement'
+	TargetValue     float32    `gorm:"type:decimal(8,2);not null" json:"targetValue"`
+	TargetDate      *time.Time `gorm:"type:date" json:"targetDate"`
+	Notes           *string    `gorm:"type:text" json:"notes"`
+	
+	// Set By
+	SetBy *uint `json:"setBy"` // user_id
+	
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	
+	// Relationships
+	Setter *User `gorm:"foreignKey:SetBy" json:"-"`
+}
+
+func (MetricGoal) TableName() string {
+	return "metric_goals"
+}
+
 // Notification represents a notification
 type Notification struct {
 	ID     uint `gorm:"primaryKey" json:"id"`
@@ -364,10 +487,67 @@ func (Notification) TableName() string {
 	return "notifications"
 }
 
+// NotificationDelivery is the outbox entry for sending a notification through
+// one external channel. It is written in the same transaction as the
+// notification, so a send survives API restarts and is retried with backoff.
+type NotificationDelivery struct {
+	ID             uint   `gorm:"primaryKey" json:"id"`
+	NotificationID uint   `gorm:"not null;uniqueIndex:idx_notification_deliveries_channel" json:"notificationId"`
+	UserID         uint   `gorm:"not null;index" json:"userId"`
+	Channel        string `gorm:"type:varchar(20);not null;uniqueIndex:idx_notification_deliveries_channel" json:"channel"` // 'email', 'push', 'line'
+		
+	// Status
+	Status        string     `gorm:"type:varchar(20);not null;default:'pending';index:idx_notification_deliveries_due" json:"status"` // 'pending', 'sending', 'sent', 'failed', 'skipped'
+	Attempts      int        `gorm:"default:0" json:"attempts"`
+	NextAttemptAt time.Time  `gorm:"not null;index:idx_notification_deliveries_due" json:"nextAttemptAt"`
+	LastError     *string    `gorm:"type:text" json:"lastError"`
+	SentAt        *time.Time `json:"sentAt"`
+		
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+		
+	// Relationships
+	Notification Notification `gorm:"foreignKey:NotificationID" json:"-"`
+}
+
+func (NotificationDelivery) TableName() string {
+	return "notification_deliveries"
+}
+
+// PushSubscription is a browser registered for Web Push. A user has one per
+// browser or device; subscriptions the push service reports gone are removed.
+type PushSubscription struct {
+	ID     uint `gorm:"primaryKey" json:"id"`
+	UserID uint `gorm:"not null;index" json:"userId"`
+		
+	// Subscription (PushSubscription.toJSON() in the browser)
+	Endpoint string `gorm:"type:text;uniqueIndex;not null" json:"endpoint"`
+	P256dh   string `gorm:"type:varchar(255);not null" json:"-"`
+	Auth     string `gorm:"type:varchar(255);not null" json:"-"`
+		
+	UserAgent *string `gorm:"type:text" json:"userAgent"`
+		
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+		
+	// Relationships
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+func (PushSubscription) TableName() string {
+	return "push_subscriptions"
+}
+
 // Achievement represents an achievement/badge
 type Achievement struct {
 	ID        uint `gorm:"primaryKey" json:"id"`
-	TraineeID uint `gorm:"not null;index" json:"traineeId"`
+	TraineeID uint `gorm:"not null;index;uniqueIndex:idx_achievements_trainee_rule" json:"traineeId"`
+	
+	// Rule that awarded it: built-in key (e.g. 'sessions_10') or 'custom_<rule id>'.
+	// PRs use 'pr_<session card>_<exercise>_<record type>'.
+	RuleKey *string `gorm:"type:varchar(50);uniqueIndex:idx_achievements_trainee_rule" json:"ruleKey,omitempty"`
 	
 	// Achievement Info
 	Type        string  `gorm:"not null" json:"type"` // 'streak', 'milestone', 'pr', 'completion'
@@ -395,14 +575,81 @@ func (Achievement) TableName() string {
 	return "achievements"
 }
 
+// PersonalRecord is the current best of a trainee for one exercise and record
+// type. It is rebuilt from session history whenever a session card changes.
+type PersonalRecord struct {
+	ID                uint `gorm:"primaryKey" json:"id"`
+	TraineeID         uint `gorm:"not null;index" json:"traineeId"`
+	ExerciseLibraryID uint `gorm:"not null;index" json:"exerciseLibraryId"`
+	
+	// Record
+	RecordType string   `gorm:"type:varchar(30);not null" json:"recordType"` // 'max_weight', 'reps_at_weight', 'estimated_1rm', 'max_distance', 'best_pace'
+	Value      float32  `gorm:"type:decimal(10,2);not null" json:"value"`    // kg, reps, km or seconds per km
+	Weight     float32  `gorm:"type:decimal(6,2);default:0" json:"weight"`   // kg lifted (reps_at_weight, max_weight, estimated_1rm)
+	Reps       *int     `json:"reps"`
+	
+	// Where it was set
+	SessionCardID     uint      `gorm:"not null" json:"sessionCardId"`
+	SessionExerciseID uint      `gorm:"not null" json:"sessionExerciseId"`
+	AchievedAt        time.Time `gorm:"type:date;not null" json:"achievedAt"`
+	
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	
+	// Relationships
+	ExerciseLibrary ExerciseLibrary `gorm:"foreignKey:ExerciseLibraryID" json:"-"`
+	SessionCard     SessionCard     `gorm:"foreignKey:SessionCardID" json:"-"`
+}
+
+func (PersonalRecord) TableName() string {
+	return "personal_records"
+}
+
+// AchievementRule is a trainer-defined achievement for their clients, awarded
+// once per trainee when Metric reaches Threshold. Built-in rules live in code.
+type AchievementRule struct {
+	ID        uint  `gorm:"primaryKey" json:"id"`
+	TrainerID uint  `gorm:"not null;index" json:"trainerId"`
+	TraineeID *uint `gorm:"index" json:"traineeId"` // NULL = all of the trainer's clients
+	
+	// Rule
+	Title       string  `gorm:"not null" json:"title"`
+	Description *string `gorm:"type:text" json:"description"`
+	Metric      string  `gorm:"type:varchar(30);not null" json:"metric"` // 'completed_sessions', 'daily_streak', 'weekly_streak', 'total_hours', 'programs_completed', 'pr_count', 'weight_lost'
+	Threshold   float32 `gorm:"type:decimal(10,2);not null" json:"threshold"`
+	
+	// Only count PRs on this exercise (metric = 'pr_count')
+	ExerciseLibraryID *uint `json:"exerciseLibraryId"`
+	
+	// Badge
+	BadgeIcon  *string `gorm:"type:text" json:"badgeIcon"`
+	BadgeColor *string `gorm:"type:varchar(7)" json:"badgeColor"`
+	
+	// Status
+	IsActive bool `gorm:"default:true" json:"isActive"`
+	
+	// Timestamps
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (AchievementRule) TableName() string {
+	return "achievement_rules"
+}
+
 // RefreshToken represents a JWT refresh token
 type RefreshToken struct {
 	ID     uint `gorm:"primaryKey" json:"id"`
 	UserID uint `gorm:"not null;index" json:"userId"`
 	
-	// Token
+	// Token (SHA-256 hash of the opaque token, never the token itself)
 	Token string `gorm:"uniqueIndex;not null" json:"-"`
 	
+	// Rotation: every token issued from one login shares a family
+	FamilyID     string `gorm:"type:varchar(64);not null;index" json:"familyId"`
+	ReplacedByID *uint  `json:"-"`
+	
 	// Expiry
 	ExpiresAt time.Time `gorm:"not null;index" json:"expiresAt"`
 	
@@ -413,8 +660,9 @@ type RefreshToken struct {
 	UserAgent  *string `gorm:"type:text" json:"userAgent"`
 	
 	// Status
-	IsRevoked bool       `gorm:"default:false" json:"isRevoked"`
-	RevokedAt *time.Time `json:"revokedAt"`
+	IsRevoked     bool       `gorm:"default:false" json:"isRevoked"`
+	RevokedAt     *time.Time `json:"revokedAt"`
+	RevokedReason *string    `gorm:"type:varchar(50)" json:"revokedReason"` // 'rotated', 'logout', 'signed_out', 'reuse_detected'
 	
 	// Timestamps
 	CreatedAt time.Time `json:"createdAt"`
@@ -426,3 +674,31 @@ type RefreshToken struct {
 func (RefreshToken) TableName() string {
 	return "refresh_tokens"
 }
+
+// IsExpired checks if the refresh token is past its expiry
+func (t *RefreshToken) IsExpired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
+
+// CalendarFeed is the secret token behind a user's read-only .ics feed
+type CalendarFeed struct {
+	ID     uint `gorm:"primaryKey" json:"id"`
+	UserID uint `gorm:"not null;uniqueIndex" json:"userId"`
+	
+	// SHA-256 of the token in the feed URL; the URL is only shown when the
+	// feed is created or rotated
+	TokenHash string `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	
+	LastAccessedAt *time.Time `json:"lastAccessedAt"`
+	
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	
+	// Relationships
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+func (CalendarFeed) TableName() string {
+	return "calendar_feeds"
+}
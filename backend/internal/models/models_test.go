@@ -0,0 +1,87 @@
+package models
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openTestDB(t *testing.T, dsn string, dryRun bool) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: dsn}), &gorm.Config{
+		DryRun:                 dryRun,
+		DisableAutomaticPing:   dryRun,
+		SkipDefaultTransaction: dryRun,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	return db
+}
+
+func TestExerciseSetInsertsCompleted(t *testing.T) {
+	db := openTestDB(t, "host=localhost", true)
+
+	stmt := db.Create(&ExerciseSet{SessionExerciseID: 1, SetNumber: 1, Completed: false}).Statement
+	sql := stmt.SQL.String()
+	if !strings.Contains(sql, `"completed"`) {
+		t.Fatalf("completed is left to the column default: %s", sql)
+	}
+	found := false
+	for _, v := range stmt.Vars {
+		if b, ok := v.(bool); ok {
+			if b {
+				t.Fatalf("completed inserted as true: %v", stmt.Vars)
+			}
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("completed not bound: %v", stmt.Vars)
+	}
+}
+
+// TestExerciseSetIncompleteRoundTrip saves and reloads an incomplete set. It
+// needs a scratch PostgreSQL database in TEST_DATABASE_DSN; everything it
+// creates is rolled back.
+func TestExerciseSetIncompleteRoundTrip(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+	tx := openTestDB(t, dsn, false).Begin()
+	defer tx.Rollback()
+
+	// A private schema with the column default of the real table
+	for _, sql := range []string{
+		"CREATE SCHEMA models_test",
+		"SET LOCAL search_path TO models_test",
+	} {
+		if err := tx.Exec(sql).Error; err != nil {
+			t.Fatalf("%s: %v", sql, err)
+		}
+	}
+	if err := tx.Migrator().CreateTable(&ExerciseSet{}); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	if err := tx.Exec("ALTER TABLE exercise_sets ALTER COLUMN completed SET DEFAULT TRUE").Error; err != nil {
+		t.Fatalf("set default: %v", err)
+	}
+
+	set := ExerciseSet{SessionExerciseID: 1, SetNumber: 1, Completed: false}
+	if err := tx.Create(&set).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	var reloaded ExerciseSet
+	if err := tx.First(&reloaded, set.ID).Error; err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if reloaded.Completed {
+		t.Fatal("incomplete set reloaded as completed")
+	}
+}
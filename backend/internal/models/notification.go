@@ -0,0 +1,173 @@
+package models
+
+import (
+	"time"
+)
+
+// NotificationTypes are the values of Notification.Type
+var NotificationTypes = []string{"schedule", "progress", "achievement", "system", "message"}
+
+// IsNotificationType reports whether value is one of NotificationTypes
+func IsNotificationType(value string) bool {
+	for _, notificationType := range NotificationTypes {
+		if notificationType == value {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationSettings are a user's delivery preferences. Users without a
+// saved row get every notification on every channel at any time.
+type NotificationSettings struct {
+	ID     uint `gorm:"primaryKey" json:"id"`
+	UserID uint `gorm:"not null;uniqueIndex" json:"userId"`
+
+	// Quiet hours and the digest time are local to this zone
+	Timezone string `gorm:"type:varchar(50);not null" json:"timezone"`
+
+	// Quiet hours ("HH:MM", may wrap midnight); email, push and LINE wait
+	// until they end. NULL = no quiet hours.
+	QuietHoursStart *string `gorm:"type:varchar(5)" json:"quietHoursStart"`
+	QuietHoursEnd   *string `gorm:"type:varchar(5)" json:"quietHoursEnd"`
+
+	// Daily digest: low-priority notifications are held and sent together
+	DailyDigest  bool       `gorm:"not null" json:"dailyDigest"`
+	DigestTime   string     `gorm:"type:varchar(5);not null" json:"digestTime"` // HH:MM
+	LastDigestAt *time.Time `json:"lastDigestAt"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Per-type channel switches (types without a row allow every channel)
+	Preferences []NotificationPreference `gorm:"foreignKey:UserID;references:UserID" json:"preferences"`
+}
+
+func (NotificationSettings) TableName() string {
+	return "notification_settings"
+}
+
+// NotificationPreference switches the channels of one notification type
+type NotificationPreference struct {
+	ID     uint   `gorm:"primaryKey" json:"id"`
+	UserID uint   `gorm:"not null;uniqueIndex:idx_notification_preferences_type" json:"userId"`
+	Type   string `gorm:"type:varchar(20);not null;uniqueIndex:idx_notification_preferences_type" json:"type"`
+
+	// Channels (no gorm defaults: false must be stored as false)
+	InApp bool `gorm:"not null" json:"inApp"`
+	Email bool `gorm:"not null" json:"email"`
+	Push  bool `gorm:"not null" json:"push"`
+	LINE  bool `gorm:"column:line;not null" json:"line"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
+
+// DefaultNotificationSettings are used until a user saves their own
+func DefaultNotificationSettings(userID uint) *NotificationSettings {
+	return &NotificationSettings{
+		UserID:     userID,
+		Timezone:   DefaultTimezone,
+		DigestTime: "08:00",
+	}
+}
+
+// Allows reports whether notifications of a type may be sent through a
+// channel ('in_app', 'email', 'push' or 'line')
+func (s *NotificationSettings) Allows(notificationType, channel string) bool {
+	for i := range s.Preferences {
+		if s.Preferences[i].Type == notificationType {
+			return s.Preferences[i].Allows(channel)
+		}
+	}
+	return true
+}
+
+// Allows reports whether the channel is switched on
+func (p *NotificationPreference) Allows(channel string) bool {
+	switch channel {
+	case "in_app":
+		return p.InApp
+	case "email":
+		return p.Email
+	case "push":
+		return p.Push
+	case "line":
+		return p.LINE
+	}
+	return true
+}
+
+// Location is the settings' time zone, or the gym's if it cannot be loaded
+func (s *NotificationSettings) Location() *time.Location {
+	if loc, err := time.LoadLocation(s.Timezone); err == nil && s.Timezone != "" {
+		return loc
+	}
+	loc, err := time.LoadLocation(DefaultTimezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// QuietUntil returns when the quiet hours containing t end, or false when t
+// is outside quiet hours
+func (s *NotificationSettings) QuietUntil(t time.Time) (time.Time, bool) {
+	if s.QuietHoursStart == nil || s.QuietHoursEnd == nil {
+		return time.Time{}, false
+	}
+	start, err1 := time.Parse("15:04", *s.QuietHoursStart)
+	end, err2 := time.Parse("15:04", *s.QuietHoursEnd)
+	if err1 != nil || err2 != nil {
+		return time.Time{}, false
+	}
+
+	local := t.In(s.Location())
+	now := local.Hour()*60 + local.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+
+	days := 0
+	switch {
+	case from == to:
+		return time.Time{}, false
+	case from < to: // e.g. 13:00-15:00
+		if now < from || now >= to {
+			return time.Time{}, false
+		}
+	case now >= from: // e.g. 22:00-07:00, before midnight
+		days = 1
+	case now >= to: // e.g. 22:00-07:00, between 07:00 and 22:00
+		return time.Time{}, false
+	}
+
+	return time.Date(local.Year(), local.Month(), local.Day()+days, end.Hour(), end.Minute(), 0, 0, local.Location()), true
+}
+
+// DigestDue returns today's digest time once it has passed, unless a digest
+// was already sent since then
+func (s *NotificationSettings) DigestDue(now time.Time) (time.Time, bool) {
+	if !s.DailyDigest {
+		return time.Time{}, false
+	}
+	digestTime, err := time.Parse("15:04", s.DigestTime)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	local := now.In(s.Location())
+	at := time.Date(local.Year(), local.Month(), local.Day(), digestTime.Hour(), digestTime.Minute(), 0, 0, local.Location())
+	if now.Before(at) {
+		return time.Time{}, false
+	}
+	if s.LastDigestAt != nil && !s.LastDigestAt.Before(at) {
+		return time.Time{}, false
+	}
+	return at, true
+}
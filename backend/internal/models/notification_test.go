@@ -0,0 +1,125 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func loadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func quietHours(start, end string) *NotificationSettings {
+	settings := DefaultNotificationSettings(1)
+	settings.QuietHoursStart, settings.QuietHoursEnd = &start, &end
+	return settings
+}
+
+func TestQuietUntil(t *testing.T) {
+	bangkok := loadLocation(t, DefaultTimezone)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 3, day, hour, minute, 0, 0, bangkok)
+	}
+
+	tests := []struct {
+		name     string
+		settings *NotificationSettings
+		now      time.Time
+		want     time.Time // zero = not quiet
+	}{
+		{"no quiet hours", DefaultNotificationSettings(1), at(20, 23, 0), time.Time{}},
+		{"invalid time", quietHours("25:00", "07:00"), at(20, 23, 0), time.Time{}},
+		{"start equals end", quietHours("08:00", "08:00"), at(20, 8, 0), time.Time{}},
+
+		// 22:00-07:00 crosses midnight
+		{"before midnight", quietHours("22:00", "07:00"), at(20, 23, 30), at(21, 7, 0)},
+		{"at the start", quietHours("22:00", "07:00"), at(20, 22, 0), at(21, 7, 0)},
+		{"a minute before the start", quietHours("22:00", "07:00"), at(20, 21, 59), time.Time{}},
+		{"after midnight", quietHours("22:00", "07:00"), at(21, 3, 0), at(21, 7, 0)},
+		{"a minute before the end", quietHours("22:00", "07:00"), at(21, 6, 59), at(21, 7, 0)},
+		{"at the end", quietHours("22:00", "07:00"), at(21, 7, 0), time.Time{}},
+		{"during the day", quietHours("22:00", "07:00"), at(21, 12, 0), time.Time{}},
+		{"across a month end", quietHours("22:00", "07:00"), at(31, 23, 0), time.Date(2024, 4, 1, 7, 0, 0, 0, bangkok)},
+
+		// 13:00-15:00 within a day
+		{"at the start of a day window", quietHours("13:00", "15:00"), at(20, 13, 0), at(20, 15, 0)},
+		{"inside a day window", quietHours("13:00", "15:00"), at(20, 14, 59), at(20, 15, 0)},
+		{"at the end of a day window", quietHours("13:00", "15:00"), at(20, 15, 0), time.Time{}},
+		{"before a day window", quietHours("13:00", "15:00"), at(20, 12, 59), time.Time{}},
+
+		// 16:00 UTC is 23:00 in Bangkok
+		{"compared in the user's zone", quietHours("22:00", "07:00"), time.Date(2024, 3, 20, 16, 0, 0, 0, time.UTC), at(21, 7, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			until, quiet := tt.settings.QuietUntil(tt.now)
+			if quiet != !tt.want.IsZero() || !until.Equal(tt.want) {
+				t.Errorf("QuietUntil(%v) = %v, %v; want %v", tt.now, until, quiet, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuietUntilAcrossDSTChange(t *testing.T) {
+	newYork := loadLocation(t, "America/New_York")
+	settings := quietHours("22:00", "07:00")
+	settings.Timezone = "America/New_York"
+
+	// Clocks went forward at 02:00 on 10 March 2024
+	until, quiet := settings.QuietUntil(time.Date(2024, 3, 9, 23, 0, 0, 0, newYork))
+	if want := time.Date(2024, 3, 10, 7, 0, 0, 0, newYork); !quiet || !until.Equal(want) {
+		t.Errorf("QuietUntil = %v, %v; want %v", until, quiet, want)
+	}
+	if _, offset := until.Zone(); offset != -4*3600 {
+		t.Errorf("quiet hours end at UTC%+d, want daylight time", offset/3600)
+	}
+}
+
+func TestDigestDue(t *testing.T) {
+	bangkok := loadLocation(t, DefaultTimezone)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 3, day, hour, minute, 0, 0, bangkok)
+	}
+	digest := func(digestTime string, lastDigestAt *time.Time) *NotificationSettings {
+		settings := DefaultNotificationSettings(1)
+		settings.DailyDigest = true
+		settings.DigestTime = digestTime
+		settings.LastDigestAt = lastDigestAt
+		return settings
+	}
+	sent := func(t time.Time) *time.Time { return &t }
+
+	tests := []struct {
+		name     string
+		settings *NotificationSettings
+		now      time.Time
+		want     time.Time // zero = not due
+	}{
+		{"digest off", DefaultNotificationSettings(1), at(20, 12, 0), time.Time{}},
+		{"invalid time", digest("8am", nil), at(20, 12, 0), time.Time{}},
+		{"a minute early", digest("08:00", nil), at(20, 7, 59), time.Time{}},
+		{"at the digest time", digest("08:00", nil), at(20, 8, 0), at(20, 8, 0)},
+		{"later in the day", digest("08:00", nil), at(20, 12, 0), at(20, 8, 0)},
+		{"last sent yesterday", digest("08:00", sent(at(19, 8, 1))), at(20, 12, 0), at(20, 8, 0)},
+		{"already sent today", digest("08:00", sent(at(20, 8, 1))), at(20, 12, 0), time.Time{}},
+		{"sent exactly at the digest time", digest("08:00", sent(at(20, 8, 0))), at(20, 12, 0), time.Time{}},
+		// The digest time was moved later after this morning's digest
+		{"sent earlier today", digest("08:00", sent(at(20, 6, 0))), at(20, 12, 0), at(20, 8, 0)},
+		{"before the digest time after yesterday's", digest("08:00", sent(at(19, 8, 0))), at(20, 7, 0), time.Time{}},
+		// 01:30 UTC is 08:30 in Bangkok
+		{"compared in the user's zone", digest("08:00", nil), time.Date(2024, 3, 20, 1, 30, 0, 0, time.UTC), at(20, 8, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			due, ok := tt.settings.DigestDue(tt.now)
+			if ok != !tt.want.IsZero() || !due.Equal(tt.want) {
+				t.Errorf("DigestDue(%v) = %v, %v; want %v", tt.now, due, ok, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ProgramVersion is an immutable snapshot of a program's content and weekly
+// plan. Assignments stay pinned to the version they started on. The newest
+// version is edited in place until it is first assigned; after that, editing
+// the program copies it into a new version.
+type ProgramVersion struct {
+	ID        uint `gorm:"primaryKey" json:"id"`
+	ProgramID uint `gorm:"not null;uniqueIndex:idx_program_versions_number" json:"programId"`
+	Version   int  `gorm:"not null;uniqueIndex:idx_program_versions_number" json:"version"` // 1, 2, ...
+
+	// Content, as on Program
+	Name                 string         `gorm:"not null" json:"name"`
+	Description          *string        `gorm:"type:text" json:"description"`
+	TotalWeeks           int            `gorm:"not null" json:"totalWeeks"`
+	SessionsPerWeek      int            `gorm:"not null" json:"sessionsPerWeek"`
+	Goals                pq.StringArray `gorm:"type:text[]" json:"goals"`
+	TargetFitnessLevel   *string        `gorm:"type:varchar(20)" json:"targetFitnessLevel"`
+	AdherenceThreshold   float32        `gorm:"type:decimal(5,2);not null;default:70" json:"adherenceThreshold"`
+	ProgressionScheme    string         `gorm:"type:varchar(20);not null;default:'double_progression'" json:"progressionScheme"`
+	ProgressionIncrement float32        `gorm:"type:decimal(5,2);not null;default:2.5" json:"progressionIncrement"`
+
+	// Stats of the assignments pinned to this version
+	TotalAssignments int     `gorm:"default:0" json:"totalAssignments"`
+	CompletionRate   float32 `gorm:"type:decimal(5,2);default:0.00" json:"completionRate"` // % of finished assignments completed
+
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Relationships
+	Days []ProgramDay `gorm:"foreignKey:ProgramVersionID" json:"days,omitempty"`
+}
+
+func (ProgramVersion) TableName() string {
+	return "program_versions"
+}
+
+// ProgramDay is one training day of a program week. Day orders the sessions
+// within the week (1..SessionsPerWeek); Weekday is the preferred weekday.
+type ProgramDay struct {
+	ID               uint `gorm:"primaryKey" json:"id"`
+	ProgramID        uint `gorm:"not null;index" json:"programId"`
+	ProgramVersionID uint `gorm:"not null;uniqueIndex:idx_program_days_version_slot" json:"programVersionId"`
+	Week             int  `gorm:"not null;uniqueIndex:idx_program_days_version_slot" json:"week"` // 1..TotalWeeks
+	Day              int  `gorm:"not null;uniqueIndex:idx_program_days_version_slot" json:"day"`  // 1..SessionsPerWeek
+
+	// Day Info
+	Name     string  `gorm:"not null" json:"name"`     // focus, e.g. "Upper Body - Push"
+	Weekday  *int    `json:"weekday"`                  // 0 = Sunday; nil = any day
+	Duration int     `gorm:"not null" json:"duration"` // minutes
+	Notes    *string `gorm:"type:text" json:"notes"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Relationships
+	Exercises []PlannedExercise `gorm:"foreignKey:ProgramDayID" json:"exercises"`
+}
+
+func (ProgramDay) TableName() string {
+	return "program_days"
+}
+
+// PlannedExercise is an exercise from the library planned for a program day
+type PlannedExercise struct {
+	ID                uint `gorm:"primaryKey" json:"id"`
+	ProgramDayID      uint `gorm:"not null;index" json:"programDayId"`
+	ExerciseLibraryID uint `gorm:"not null;index" json:"exerciseLibraryId"`
+	Order             int  `gorm:"column:order_index;not null" json:"order"`
+
+	Notes        *string `gorm:"type:text" json:"notes"`
+	RestDuration *int    `json:"restDuration"` // seconds between sets
+
+	// Relationships
+	ExerciseLibrary *ExerciseLibrary `gorm:"foreignKey:ExerciseLibraryID" json:"-"`
+	Sets            []PrescribedSet  `gorm:"foreignKey:PlannedExerciseID" json:"sets"`
+}
+
+func (PlannedExercise) TableName() string {
+	return "planned_exercises"
+}
+
+// PrescribedSet is the target of one set. Reps (or a Reps-RepsMax range),
+// Duration or Distance say how much; Weight or PercentOneRM how heavy; RPE
+// how hard.
+type PrescribedSet struct {
+	ID                uint `gorm:"primaryKey" json:"id"`
+	PlannedExerciseID uint `gorm:"not null;index" json:"plannedExerciseId"`
+	SetNumber         int  `gorm:"not null" json:"setNumber"`
+
+	// Volume
+	Reps     *int     `json:"reps"`
+	RepsMax  *int     `json:"repsMax"`                           // upper end of a rep range
+	Duration *int     `json:"duration"`                          // seconds
+	Distance *float32 `gorm:"type:decimal(6,2)" json:"distance"` // km
+
+	// Intensity
+	Weight       *float32 `gorm:"type:decimal(6,2)" json:"weight"` // kg
+	PercentOneRM *float32 `gorm:"column:percent_one_rm;type:decimal(5,2)" json:"percentOneRm"`
+	RPE          *float32 `gorm:"column:rpe;type:decimal(3,1)" json:"rpe"` // 1-10
+}
+
+func (PrescribedSet) TableName() string {
+	return "prescribed_sets"
+}
+
+// ProgramProgressNote is a trainer's note on how an assignment is going
+type ProgramProgressNote struct {
+	ID                  uint      `gorm:"primaryKey" json:"id"`
+	ProgramAssignmentID uint      `gorm:"not null;index" json:"programAssignmentId"`
+	Week                *int      `json:"week"`
+	Date                time.Time `gorm:"type:date;not null" json:"date"`
+	Note                string    `gorm:"type:text;not null" json:"note"`
+
+	// Author; RecordedBy keeps the name for notes converted from the old
+	// progress_notes JSON, which have no user
+	RecordedByUserID *uint  `json:"recordedByUserId"`
+	RecordedBy       string `gorm:"not null" json:"recordedBy"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (ProgramProgressNote) TableName() string {
+	return "program_progress_notes"
+}
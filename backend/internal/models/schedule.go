@@ -7,6 +7,10 @@ import (
 	"gorm.io/gorm"
 )
 
+// DefaultTimezone is the gym's time zone. Schedule dates and times are
+// stored as local values in it.
+const DefaultTimezone = "Asia/Bangkok"
+
 // Schedule represents a training session schedule
 type Schedule struct {
 	ID                   uint   `gorm:"primaryKey" json:"id"`
@@ -14,6 +18,15 @@ type Schedule struct {
 	TraineeID            uint   `gorm:"not null;index" json:"traineeId"`
 	LocationID           *uint  `json:"locationId"`
 	ProgramAssignmentID  *uint  `json:"programAssignmentId"`
+	ProgramDayID         *uint  `gorm:"index" json:"programDayId"` // Planned day the session was laid out from
+	
+	// Recurrence (nil for one-off schedules)
+	SeriesID       *uint      `gorm:"index" json:"seriesId"`
+	OccurrenceDate *time.Time `gorm:"type:date" json:"occurrenceDate"` // Date the series originally generated
+	IsException    bool       `gorm:"default:false" json:"isException"` // Edited individually
+	
+	// Import (UID of the iCalendar event this schedule was created from)
+	ExternalUID *string `gorm:"type:varchar(255);index" json:"-"`
 	
 	// Schedule Info
 	Date     time.Time `gorm:"not null;index" json:"date"` // YYYY-MM-DD
@@ -39,8 +52,12 @@ type Schedule struct {
 	SessionCardID *uint `json:"sessionCardId"` // Link to session_cards after completion
 	
 	// Reminders
-	ReminderSent   bool       `gorm:"default:false" json:"reminderSent"`
-	ReminderSentAt *time.Time `json:"reminderSentAt"`
+	ReminderSent      bool          `gorm:"default:false" json:"reminderSent"`
+	ReminderSentAt    *time.Time    `json:"reminderSentAt"`
+	ReminderLeadsSent pq.Int64Array `gorm:"type:integer[]" json:"-"` // lead times (minutes) already covered
+	
+	// Calendar SEQUENCE, bumped by a trigger whenever the event changes
+	Revision int `gorm:"not null;default:0" json:"-"`
 	
 	// Timestamps
 	CreatedAt time.Time      `json:"createdAt"`
@@ -52,7 +69,9 @@ type Schedule struct {
 	Trainee           Trainee            `gorm:"foreignKey:TraineeID" json:"trainee"`
 	Location          *Location          `gorm:"foreignKey:LocationID" json:"location,omitempty"`
 	ProgramAssignment *ProgramAssignment `gorm:"foreignKey:ProgramAssignmentID" json:"-"`
+	ProgramDay        *ProgramDay        `gorm:"foreignKey:ProgramDayID" json:"-"`
 	SessionCard       *SessionCard       `gorm:"foreignKey:SessionCardID" json:"-"`
+	Series            *ScheduleSeries    `gorm:"foreignKey:SeriesID" json:"-"`
 }
 
 // TableName specifies the table name
@@ -60,6 +79,51 @@ func (Schedule) TableName() string {
 	return "schedules"
 }
 
+// ScheduleSeries represents a recurring schedule whose occurrences are
+// materialised into the schedules table
+type ScheduleSeries struct {
+	ID                  uint  `gorm:"primaryKey" json:"id"`
+	TrainerID           uint  `gorm:"not null;index" json:"trainerId"`
+	TraineeID           uint  `gorm:"not null;index" json:"traineeId"`
+	LocationID          *uint `json:"locationId"`
+	ProgramAssignmentID *uint `json:"programAssignmentId"`
+	
+	// Recurrence Rule
+	Frequency string        `gorm:"type:varchar(20);not null" json:"frequency"` // 'weekly', 'biweekly'
+	Weekdays  pq.Int64Array `gorm:"type:integer[];not null" json:"weekdays"`   // 0 = Sunday ... 6 = Saturday
+	StartDate time.Time     `gorm:"type:date;not null" json:"startDate"`
+	Count     *int          `json:"count"`                   // Total occurrences, or
+	Until     *time.Time    `gorm:"type:date" json:"until"` // last possible date (inclusive)
+	Timezone  string        `gorm:"type:varchar(64);not null;default:'Asia/Bangkok'" json:"timezone"`
+	
+	// Occurrence Template
+	Time             string         `gorm:"type:time;not null" json:"time"` // HH:MM (local to Timezone)
+	Duration         int            `gorm:"not null" json:"duration"`       // minutes
+	Title            string         `gorm:"not null" json:"title"`
+	Description      *string        `gorm:"type:text" json:"description"`
+	SessionType      *string        `gorm:"type:varchar(50)" json:"sessionType"`
+	PlannedExercises pq.StringArray `gorm:"type:text[]" json:"plannedExercises"`
+	
+	// Status
+	Status string `gorm:"type:varchar(20);default:'active';index" json:"status"` // 'active', 'cancelled'
+	
+	// Timestamps
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	
+	// Relationships
+	Trainer     Trainer    `gorm:"foreignKey:TrainerID" json:"-"`
+	Trainee     Trainee    `gorm:"foreignKey:TraineeID" json:"-"`
+	Location    *Location  `gorm:"foreignKey:LocationID" json:"-"`
+	Occurrences []Schedule `gorm:"foreignKey:SeriesID" json:"-"`
+}
+
+// TableName specifies the table name
+func (ScheduleSeries) TableName() string {
+	return "schedule_series"
+}
+
 // IsUpcoming checks if schedule is upcoming
 func (s *Schedule) IsUpcoming() bool {
 	now := time.Now()
@@ -75,7 +139,56 @@ func (s *Schedule) CanBeCancelled() bool {
 	return s.Status == "scheduled" || s.Status == "confirmed"
 }
 
+// ResetReminders lets the reminder dispatcher remind again, e.g. after the
+// session was moved
+func (s *Schedule) ResetReminders() {
+	s.ReminderSent = false
+	s.ReminderSentAt = nil
+	s.ReminderLeadsSent = nil
+}
+
 // CanBeCompleted checks if schedule can be marked as completed
 func (s *Schedule) CanBeCompleted() bool {
 	return s.Status == "confirmed" && !s.IsUpcoming()
 }
+
+// BookingRequest is a trainee's request for an open slot. A Schedule is only
+// created once the trainer confirms it.
+type BookingRequest struct {
+	ID         uint  `gorm:"primaryKey" json:"id"`
+	TrainerID  uint  `gorm:"not null;index" json:"trainerId"`
+	TraineeID  uint  `gorm:"not null;index" json:"traineeId"`
+	LocationID *uint `json:"locationId"`
+	
+	// Requested Slot
+	Date     time.Time `gorm:"type:date;not null" json:"date"`
+	Time     string    `gorm:"type:time;not null" json:"time"` // HH:MM
+	Duration int       `gorm:"not null" json:"duration"`       // minutes
+	Notes    *string   `gorm:"type:text" json:"notes"`         // Message from the trainee
+	
+	// Decision
+	Status       string     `gorm:"type:varchar(20);default:'pending';index" json:"status"` // 'pending', 'confirmed', 'declined', 'cancelled'
+	ResponseNote *string    `gorm:"type:text" json:"responseNote"`
+	RespondedAt  *time.Time `json:"respondedAt"`
+	ScheduleID   *uint      `json:"scheduleId"` // Set on confirmation
+	
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	
+	// Relationships
+	Trainer  Trainer   `gorm:"foreignKey:TrainerID" json:"-"`
+	Trainee  Trainee   `gorm:"foreignKey:TraineeID" json:"-"`
+	Location *Location `gorm:"foreignKey:LocationID" json:"-"`
+	Schedule *Schedule `gorm:"foreignKey:ScheduleID" json:"-"`
+}
+
+// TableName specifies the table name
+func (BookingRequest) TableName() string {
+	return "booking_requests"
+}
+
+// IsPending checks if the request still awaits the trainer's decision
+func (b *BookingRequest) IsPending() bool {
+	return b.Status == "pending"
+}
@@ -9,9 +9,10 @@ import (
 
 // Trainee represents a fitness trainee (client)
 type Trainee struct {
-	ID        uint  `gorm:"primaryKey" json:"id"`
-	UserID    uint  `gorm:"uniqueIndex;not null" json:"userId"`
-	TrainerID *uint `json:"trainerId"` // Assigned trainer (nullable)
+	ID        uint    `gorm:"primaryKey" json:"id"`
+	UserID    uint    `gorm:"uniqueIndex;not null" json:"userId"`
+	TrainerID *uint   `json:"trainerId"` // Assigned trainer (nullable)
+	MemberID  *string `gorm:"type:varchar(50);uniqueIndex" json:"memberId"` // Gym membership number, as on body-composition exports
 	
 	// Physical Info
 	Height float32 `gorm:"type:decimal(5,2)" json:"height"` // cm
@@ -41,11 +42,21 @@ type Trainee struct {
 	TotalSessions      int     `gorm:"default:0" json:"totalSessions"`
 	CompletedSessions  int     `gorm:"default:0" json:"completedSessions"`
 	CancelledSessions  int     `gorm:"default:0" json:"cancelledSessions"`
-	CurrentStreak      int     `gorm:"default:0" json:"currentStreak"`
+	CurrentStreak      int     `gorm:"default:0" json:"currentStreak"` // consecutive days
 	LongestStreak      int     `gorm:"default:0" json:"longestStreak"`
 	TotalWorkoutHours  float32 `gorm:"type:decimal(10,2);default:0.00" json:"totalWorkoutHours"`
 	LastSessionDate    *time.Time `json:"lastSessionDate"`
 	
+	// Weekly Consistency (Cached, see stats.Compute)
+	WeeklyStreakTarget     int     `gorm:"default:1" json:"weeklyStreakTarget"` // sessions per ISO week
+	CurrentWeeklyStreak    int     `gorm:"default:0" json:"currentWeeklyStreak"`
+	LongestWeeklyStreak    int     `gorm:"default:0" json:"longestWeeklyStreak"`
+	AverageSessionsPerWeek float32 `gorm:"type:decimal(5,2);default:0.00" json:"averageSessionsPerWeek"`
+	
+	// Scheduling Preferences (used to lay out assigned programs)
+	PreferredWeekdays pq.Int64Array `gorm:"type:integer[]" json:"preferredWeekdays"` // 0 = Sunday ... 6 = Saturday
+	PreferredTime     *string       `gorm:"type:time" json:"preferredTime"`          // HH:MM
+	
 	// Timestamps
 	CreatedAt time.Time      `json:"createdAt"`
 	UpdatedAt time.Time      `json:"updatedAt"`
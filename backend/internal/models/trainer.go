@@ -24,8 +24,9 @@ type Trainer struct {
 	TotalClients int     `gorm:"default:0" json:"totalClients"`
 	
 	// Availability
-	Availability string          `gorm:"type:varchar(20);default:'available'" json:"availability"` // 'available', 'busy', 'unavailable'
-	WorkingHours *string         `gorm:"type:jsonb" json:"workingHours"` // {"monday": "09:00-18:00"}
+	Availability  string  `gorm:"type:varchar(20);default:'available'" json:"availability"` // 'available', 'busy', 'unavailable'
+	WorkingHours  *string `gorm:"type:jsonb" json:"workingHours"` // Deprecated: use AvailabilityWindows
+	BufferMinutes int     `gorm:"default:15" json:"bufferMinutes"` // Gap kept free between sessions
 	
 	// Social Media
 	InstagramURL *string `gorm:"type:varchar(255)" json:"instagramUrl"`
@@ -38,15 +39,61 @@ type Trainer struct {
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 	
 	// Relationships
-	User              User                `gorm:"foreignKey:UserID" json:"user"`
-	Trainees          []Trainee           `gorm:"foreignKey:TrainerID" json:"-"`
-	Programs          []Program           `gorm:"foreignKey:TrainerID" json:"-"`
-	Schedules         []Schedule          `gorm:"foreignKey:TrainerID" json:"-"`
-	SessionCards      []SessionCard       `gorm:"foreignKey:TrainerID" json:"-"`
-	ExerciseLibraries []ExerciseLibrary   `gorm:"foreignKey:TrainerID" json:"-"`
+	User                User                  `gorm:"foreignKey:UserID" json:"user"`
+	Trainees            []Trainee             `gorm:"foreignKey:TrainerID" json:"-"`
+	Programs            []Program             `gorm:"foreignKey:TrainerID" json:"-"`
+	Schedules           []Schedule            `gorm:"foreignKey:TrainerID" json:"-"`
+	SessionCards        []SessionCard         `gorm:"foreignKey:TrainerID" json:"-"`
+	ExerciseLibraries   []ExerciseLibrary     `gorm:"foreignKey:TrainerID" json:"-"`
+	AvailabilityWindows []TrainerAvailability `gorm:"foreignKey:TrainerID" json:"-"`
+	TimeOff             []TrainerTimeOff      `gorm:"foreignKey:TrainerID" json:"-"`
 }
 
 // TableName specifies the table name
 func (Trainer) TableName() string {
 	return "trainers"
 }
+
+// TrainerAvailability is a recurring weekly window in which a trainer takes
+// sessions, optionally tied to one location
+type TrainerAvailability struct {
+	ID         uint  `gorm:"primaryKey" json:"id"`
+	TrainerID  uint  `gorm:"not null;index" json:"trainerId"`
+	LocationID *uint `json:"locationId"` // nil = any location
+	
+	Weekday   int    `gorm:"not null" json:"weekday"`             // 0 = Sunday ... 6 = Saturday
+	StartTime string `gorm:"type:time;not null" json:"startTime"` // HH:MM
+	EndTime   string `gorm:"type:time;not null" json:"endTime"`   // HH:MM
+	
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	
+	// Relationships
+	Location *Location `gorm:"foreignKey:LocationID" json:"-"`
+}
+
+// TableName specifies the table name
+func (TrainerAvailability) TableName() string {
+	return "trainer_availabilities"
+}
+
+// TrainerTimeOff blocks a period (holiday, sick leave) in which no slots are offered
+type TrainerTimeOff struct {
+	ID         uint  `gorm:"primaryKey" json:"id"`
+	TrainerID  uint  `gorm:"not null;index" json:"trainerId"`
+	LocationID *uint `json:"locationId"` // nil = all locations
+	
+	StartAt time.Time `gorm:"not null;index" json:"startAt"`
+	EndAt   time.Time `gorm:"not null" json:"endAt"`
+	Reason  *string   `gorm:"type:text" json:"reason"`
+	
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// TableName specifies the table name
+func (TrainerTimeOff) TableName() string {
+	return "trainer_time_off"
+}
@@ -3,6 +3,8 @@ package models
 import (
 	"time"
 
+	"fitness-training-backend/pkg/units"
+
 	"gorm.io/gorm"
 )
 
@@ -21,6 +23,14 @@ type User struct {
 	PhoneNumber  *string `gorm:"type:varchar(20)" json:"phoneNumber"`
 	DateOfBirth  *time.Time `json:"dateOfBirth"`
 	Gender       *string `gorm:"type:varchar(10);check:gender IN ('male','female','other')" json:"gender"`
+		
+	// LINE Messaging API user ID, linked from the LINE app (notifications)
+	LineUserID *string `gorm:"type:varchar(64);uniqueIndex" json:"-"`
+	
+	// Units values are entered and shown in; they are stored in kg, km and cm
+	WeightUnit   string `gorm:"type:varchar(2);not null;default:'kg'" json:"weightUnit"`   // 'kg', 'lb'
+	DistanceUnit string `gorm:"type:varchar(2);not null;default:'km'" json:"distanceUnit"` // 'km', 'mi'
+	LengthUnit   string `gorm:"type:varchar(2);not null;default:'cm'" json:"lengthUnit"`   // 'cm', 'in'
 	
 	// OAuth fields
 	OAuthProvider      *string    `gorm:"type:varchar(50)" json:"oauthProvider"` // 'google', 'facebook', null
@@ -79,3 +89,12 @@ func (u *User) IsTrainee() bool {
 func (u *User) IsAdmin() bool {
 	return u.Role == "admin"
 }
+
+// Units returns the user's unit preferences; unset ones are metric
+func (u *User) Units() units.Preferences {
+	return units.Preferences{
+		Weight:   units.Unit(u.WeightUnit),
+		Distance: units.Unit(u.DistanceUnit),
+		Length:   units.Unit(u.LengthUnit),
+	}
+}
@@ -0,0 +1,90 @@
+// Package realtime publishes per-user events to open notification streams.
+// Events are nudges that carry IDs only: streams read the data itself from
+// the database, so a missed event is caught up on the next one or on
+// reconnect.
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+
+	"fitness-training-backend/internal/config"
+	"fitness-training-backend/pkg/pubsub"
+
+	"gorm.io/gorm"
+)
+
+// Event types
+const (
+	EventNotification = "notification" // a notification was created
+	EventRead         = "read"         // notifications were marked as read
+)
+
+// Event is published on a user's topic
+type Event struct {
+	Type string `json:"type"`
+	ID   uint   `json:"id,omitempty"`
+}
+
+var (
+	mu     sync.RWMutex
+	broker pubsub.Broker = pubsub.NewMemoryBroker()
+)
+
+// Connect replaces the default in-memory broker with the configured one
+func Connect(cfg config.RealtimeConfig, db *gorm.DB, dsn string) error {
+	if cfg.Broker != "postgres" {
+		return nil
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	postgresBroker, err := pubsub.NewPostgresBroker(sqlDB, dsn, cfg.Channel)
+	if err != nil {
+		return fmt.Errorf("failed to start realtime broker: %w", err)
+	}
+
+	mu.Lock()
+	previous := broker
+	broker = postgresBroker
+	mu.Unlock()
+	return previous.Close()
+}
+
+// Close shuts the broker down, ending every open stream
+func Close() error {
+	mu.RLock()
+	defer mu.RUnlock()
+	return broker.Close()
+}
+
+// Subscribe returns the events of one user
+func Subscribe(userID uint) (*pubsub.Subscription, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	return broker.Subscribe(userTopic(userID))
+}
+
+// Publish sends an event to the user's streams. Failures are logged: the
+// stream catches up on the next event.
+func Publish(userID uint, event Event) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+	if err := broker.Publish(context.Background(), userTopic(userID), payload); err != nil {
+		log.Printf("⚠️  Failed to publish %s event for user %d: %v", event.Type, userID, err)
+	}
+}
+
+func userTopic(userID uint) string {
+	return fmt.Sprintf("user:%d", userID)
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"fitness-training-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AchievementRepository handles achievement persistence
+type AchievementRepository interface {
+	FindByTraineeID(traineeID uint, limit int) ([]models.Achievement, error)
+	FindRuleKeys(traineeID uint) ([]string, error)
+	Create(achievement *models.Achievement) error
+	CreateOnce(achievement *models.Achievement) (bool, error)
+}
+
+type achievementRepository struct {
+	db *gorm.DB
+}
+
+// NewAchievementRepository creates a new achievement repository
+func NewAchievementRepository(db *gorm.DB) AchievementRepository {
+	return &achievementRepository{db: db}
+}
+
+// FindByTraineeID lists the latest achievements of a trainee
+func (r *achievementRepository) FindByTraineeID(traineeID uint, limit int) ([]models.Achievement, error) {
+	var achievements []models.Achievement
+	err := r.db.
+		Where("trainee_id = ?", traineeID).
+		Order("achieved_at DESC, id DESC").
+		Limit(limit).
+		Find(&achievements).Error
+	return achievements, err
+}
+
+// FindRuleKeys lists the rules a trainee has already been awarded
+func (r *achievementRepository) FindRuleKeys(traineeID uint) ([]string, error) {
+	var keys []string
+	err := r.db.Model(&models.Achievement{}).
+		Where("trainee_id = ? AND rule_key IS NOT NULL", traineeID).
+		Pluck("rule_key", &keys).Error
+	return keys, err
+}
+
+// Create stores a new achievement
+func (r *achievementRepository) Create(achievement *models.Achievement) error {
+	return r.db.Create(achievement).Error
+}
+
+// CreateOnce stores a rule achievement unless the trainee already has one for
+// the same rule. It reports whether a row was inserted.
+func (r *achievementRepository) CreateOnce(achievement *models.Achievement) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "trainee_id"}, {Name: "rule_key"}},
+		DoNothing: true,
+	}).Create(achievement)
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"fitness-training-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AchievementRuleRepository handles trainer-defined achievement rules
+type AchievementRuleRepository interface {
+	FindByID(id uint) (*models.AchievementRule, error)
+	FindByTrainerID(trainerID uint) ([]models.AchievementRule, error)
+	FindActiveForTrainee(trainerID, traineeID uint) ([]models.AchievementRule, error)
+	Create(rule *models.AchievementRule) error
+	Update(rule *models.AchievementRule) error
+	Delete(id uint) error
+}
+
+type achievementRuleRepository struct {
+	db *gorm.DB
+}
+
+// NewAchievementRuleRepository creates a new achievement rule repository
+func NewAchievementRuleRepository(db *gorm.DB) AchievementRuleRepository {
+	return &achievementRuleRepository{db: db}
+}
+
+func (r *achievementRuleRepository) FindByID(id uint) (*models.AchievementRule, error) {
+	var rule models.AchievementRule
+	err := r.db.First(&rule, id).Error
+	return &rule, err
+}
+
+func (r *achievementRuleRepository) FindByTrainerID(trainerID uint) ([]models.AchievementRule, error) {
+	var rules []models.AchievementRule
+	err := r.db.Where("trainer_id = ?", trainerID).Order("created_at DESC").Find(&rules).Error
+	return rules, err
+}
+
+// FindActiveForTrainee lists the active rules of a trainer that apply to one
+// client: rules for all clients plus rules targeting that client
+func (r *achievementRuleRepository) FindActiveForTrainee(trainerID, traineeID uint) ([]models.AchievementRule, error) {
+	var rules []models.AchievementRule
+	err := r.db.
+		Where("trainer_id = ? AND is_active", trainerID).
+		Where("trainee_id IS NULL OR trainee_id = ?", traineeID).
+		Order("id").
+		Find(&rules).Error
+	return rules, err
+}
+
+func (r *achievementRuleRepository) Create(rule *models.AchievementRule) error {
+	return r.db.Create(rule).Error
+}
+
+func (r *achievementRuleRepository) Update(rule *models.AchievementRule) error {
+	return r.db.Omit(clause.Associations).Save(rule).Error
+}
+
+func (r *achievementRuleRepository) Delete(id uint) error {
+	return r.db.Delete(&models.AchievementRule{}, id).Error
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"fitness-training-backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AvailabilityRepository handles trainer availability and time-off data access
+type AvailabilityRepository interface {
+	// Weekly windows
+	FindByTrainerID(trainerID uint) ([]models.TrainerAvailability, error)
+	ReplaceForTrainer(trainerID uint, windows []models.TrainerAvailability) error
+
+	// Time off
+	FindTimeOffByID(id uint) (*models.TrainerTimeOff, error)
+	FindTimeOff(trainerID uint, from, to time.Time) ([]models.TrainerTimeOff, error)
+	CreateTimeOff(timeOff *models.TrainerTimeOff) error
+	DeleteTimeOff(id uint) error
+}
+
+type availabilityRepository struct {
+	db *gorm.DB
+}
+
+// NewAvailabilityRepository creates a new availability repository
+func NewAvailabilityRepository(db *gorm.DB) AvailabilityRepository {
+	return &availabilityRepository{db: db}
+}
+
+// FindByTrainerID lists the weekly windows of a trainer
+func (r *availabilityRepository) FindByTrainerID(trainerID uint) ([]models.TrainerAvailability, error) {
+	var windows []models.TrainerAvailability
+	err := r.db.
+		Where("trainer_id = ?", trainerID).
+		Order("weekday ASC, start_time ASC").
+		Find(&windows).Error
+	return windows, err
+}
+
+// ReplaceForTrainer swaps the trainer's weekly windows for a new set.
+// Call inside a transaction so readers never see an empty week.
+func (r *availabilityRepository) ReplaceForTrainer(trainerID uint, windows []models.TrainerAvailability) error {
+	if err := r.db.Where("trainer_id = ?", trainerID).Delete(&models.TrainerAvailability{}).Error; err != nil {
+		return err
+	}
+	if len(windows) == 0 {
+		return nil
+	}
+	return r.db.Create(&windows).Error
+}
+
+// FindTimeOffByID finds a time-off period by ID
+func (r *availabilityRepository) FindTimeOffByID(id uint) (*models.TrainerTimeOff, error) {
+	var timeOff models.TrainerTimeOff
+	err := r.db.First(&timeOff, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &timeOff, nil
+}
+
+// FindTimeOff lists the time-off periods overlapping [from, to)
+func (r *availabilityRepository) FindTimeOff(trainerID uint, from, to time.Time) ([]models.TrainerTimeOff, error) {
+	var periods []models.TrainerTimeOff
+	err := r.db.
+		Where("trainer_id = ? AND start_at < ? AND end_at > ?", trainerID, to, from).
+		Order("start_at ASC").
+		Find(&periods).Error
+	return periods, err
+}
+
+// CreateTimeOff creates a time-off period
+func (r *availabilityRepository) CreateTimeOff(timeOff *models.TrainerTimeOff) error {
+	return r.db.Create(timeOff).Error
+}
+
+// DeleteTimeOff deletes a time-off period
+func (r *availabilityRepository) DeleteTimeOff(id uint) error {
+	return r.db.Delete(&models.TrainerTimeOff{}, id).Error
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"fitness-training-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BookingRequestRepository handles trainee booking request data access
+type BookingRequestRepository interface {
+	FindByID(id uint) (*models.BookingRequest, error)
+	FindByTrainerID(trainerID uint, status string) ([]models.BookingRequest, error)
+	FindByTraineeID(traineeID uint, status string) ([]models.BookingRequest, error)
+	Create(request *models.BookingRequest) error
+	Update(request *models.BookingRequest) error
+	UpdateIfPending(request *models.BookingRequest) (bool, error)
+}
+
+type bookingRequestRepository struct {
+	db *gorm.DB
+}
+
+// NewBookingRequestRepository creates a new booking request repository
+func NewBookingRequestRepository(db *gorm.DB) BookingRequestRepository {
+	return &bookingRequestRepository{db: db}
+}
+
+// FindByID finds a booking request by ID with preloaded relations
+func (r *bookingRequestRepository) FindByID(id uint) (*models.BookingRequest, error) {
+	var request models.BookingRequest
+	err := r.db.
+		Preload("Trainer.User").
+		Preload("Trainee.User").
+		Preload("Location").
+		First(&request, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// FindByTrainerID lists the requests sent to a trainer, optionally by status
+func (r *bookingRequestRepository) FindByTrainerID(trainerID uint, status string) ([]models.BookingRequest, error) {
+	query := r.db.
+		Preload("Trainee.User").
+		Preload("Location").
+		Where("trainer_id = ?", trainerID)
+
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var requests []models.BookingRequest
+	err := query.Order("date ASC, time ASC").Find(&requests).Error
+	return requests, err
+}
+
+// FindByTraineeID lists the requests a trainee made, optionally by status
+func (r *bookingRequestRepository) FindByTraineeID(traineeID uint, status string) ([]models.BookingRequest, error) {
+	query := r.db.
+		Preload("Trainer.User").
+		Preload("Location").
+		Where("trainee_id = ?", traineeID)
+
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var requests []models.BookingRequest
+	err := query.Order("created_at DESC").Find(&requests).Error
+	return requests, err
+}
+
+// Create creates a booking request
+func (r *bookingRequestRepository) Create(request *models.BookingRequest) error {
+	return r.db.Create(request).Error
+}
+
+// Update updates a booking request
+func (r *bookingRequestRepository) Update(request *models.BookingRequest) error {
+	return r.db.Omit(clause.Associations).Save(request).Error
+}
+
+// UpdateIfPending saves the request's decision only while it is still
+// pending. It reports false when the request was answered or cancelled
+// meanwhile.
+func (r *bookingRequestRepository) UpdateIfPending(request *models.BookingRequest) (bool, error) {
+	result := r.db.Model(&models.BookingRequest{}).
+		Where("id = ? AND status = ?", request.ID, "pending").
+		Updates(map[string]interface{}{
+			"status":        request.Status,
+			"response_note": request.ResponseNote,
+			"responded_at":  request.RespondedAt,
+			"schedule_id":   request.ScheduleID,
+		})
+	return result.RowsAffected == 1, result.Error
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"fitness-training-backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CalendarFeedRepository handles calendar feed token persistence
+type CalendarFeedRepository interface {
+	FindByUserID(userID uint) (*models.CalendarFeed, error)
+	FindByTokenHash(tokenHash string) (*models.CalendarFeed, error)
+	Create(feed *models.CalendarFeed) error
+	Update(feed *models.CalendarFeed) error
+	DeleteByUserID(userID uint) error
+	TouchAccessed(id uint) error
+}
+
+type calendarFeedRepository struct {
+	db *gorm.DB
+}
+
+// NewCalendarFeedRepository creates a new calendar feed repository
+func NewCalendarFeedRepository(db *gorm.DB) CalendarFeedRepository {
+	return &calendarFeedRepository{db: db}
+}
+
+// FindByUserID finds the feed of a user
+func (r *calendarFeedRepository) FindByUserID(userID uint) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	err := r.db.Where("user_id = ?", userID).First(&feed).Error
+	if err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// FindByTokenHash finds a feed by the hash of its URL token, with the owning
+// user
+func (r *calendarFeedRepository) FindByTokenHash(tokenHash string) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	err := r.db.Preload("User").Where("token_hash = ?", tokenHash).First(&feed).Error
+	if err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// Create stores a new feed
+func (r *calendarFeedRepository) Create(feed *models.CalendarFeed) error {
+	return r.db.Create(feed).Error
+}
+
+// Update updates a feed
+func (r *calendarFeedRepository) Update(feed *models.CalendarFeed) error {
+	return r.db.Omit(clause.Associations).Save(feed).Error
+}
+
+// DeleteByUserID removes the feed of a user, invalidating its URL
+func (r *calendarFeedRepository) DeleteByUserID(userID uint) error {
+	return r.db.Where("user_id = ?", userID).Delete(&models.CalendarFeed{}).Error
+}
+
+// TouchAccessed records when a calendar client last fetched the feed
+func (r *calendarFeedRepository) TouchAccessed(id uint) error {
+	return r.db.Model(&models.CalendarFeed{}).Where("id = ?", id).
+		UpdateColumn("last_accessed_at", time.Now()).Error
+}
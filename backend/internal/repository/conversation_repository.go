@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"time"
+
+	"fitness-training-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ConversationRepository handles trainer-trainee message threads
+type ConversationRepository interface {
+	FindByID(id uint) (*models.Conversation, error)
+	FindByTrainerID(trainerID uint) ([]models.Conversation, error)
+	FindByTraineeID(traineeID uint) ([]models.Conversation, error)
+	FindOrCreate(trainerID, traineeID uint) (*models.Conversation, error)
+	TouchLastMessage(id uint, at time.Time) error
+}
+
+type conversationRepository struct {
+	db *gorm.DB
+}
+
+// NewConversationRepository creates a new conversation repository
+func NewConversationRepository(db *gorm.DB) ConversationRepository {
+	return &conversationRepository{db: db}
+}
+
+// FindByID finds a conversation with both participants
+func (r *conversationRepository) FindByID(id uint) (*models.Conversation, error) {
+	var conversation models.Conversation
+	err := r.withParticipants().First(&conversation, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+// FindByTrainerID lists a trainer's conversations, most recent first
+func (r *conversationRepository) FindByTrainerID(trainerID uint) ([]models.Conversation, error) {
+	var conversations []models.Conversation
+	err := r.withParticipants().Where("trainer_id = ?", trainerID).
+		Order("last_message_at DESC NULLS LAST, id DESC").
+		Find(&conversations).Error
+	return conversations, err
+}
+
+// FindByTraineeID lists a trainee's conversations, most recent first
+func (r *conversationRepository) FindByTraineeID(traineeID uint) ([]models.Conversation, error) {
+	var conversations []models.Conversation
+	err := r.withParticipants().Where("trainee_id = ?", traineeID).
+		Order("last_message_at DESC NULLS LAST, id DESC").
+		Find(&conversations).Error
+	return conversations, err
+}
+
+// FindOrCreate returns the pair's conversation, creating it if needed
+func (r *conversationRepository) FindOrCreate(trainerID, traineeID uint) (*models.Conversation, error) {
+	conversation := models.Conversation{TrainerID: trainerID, TraineeID: traineeID}
+	err := r.db.Omit(clause.Associations).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&conversation).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var existing models.Conversation
+	err = r.withParticipants().
+		Where("trainer_id = ? AND trainee_id = ?", trainerID, traineeID).
+		First(&existing).Error
+	if err != nil {
+		return nil, err
+	}
+	return &existing, nil
+}
+
+// TouchLastMessage moves the conversation to the top of the inbox
+func (r *conversationRepository) TouchLastMessage(id uint, at time.Time) error {
+	return r.db.Model(&models.Conversation{}).Where("id = ?", id).
+		Update("last_message_at", at).Error
+}
+
+func (r *conversationRepository) withParticipants() *gorm.DB {
+	return r.db.Preload("Trainer.User").Preload("Trainee.User")
+}
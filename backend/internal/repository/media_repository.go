@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"time"
+
+	"fitness-training-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// MediaRepository handles uploaded media files and their variants
+type MediaRepository interface {
+	FindByID(id uint) (*models.MediaFile, error)
+	Create(file *models.MediaFile) error
+	Attach(purpose string, attachedID, userID uint, ids []uint) (int64, error)
+	Detach(purpose string, attachedID uint, keepIDs []uint) error
+	FindOrphans(uploadedBefore time.Time, limit int) ([]models.MediaFile, error)
+	SentToUser(id, userID uint) (bool, error)
+	Delete(ids []uint) error
+}
+
+type mediaRepository struct {
+	db *gorm.DB
+}
+
+// NewMediaRepository creates a new media repository
+func NewMediaRepository(db *gorm.DB) MediaRepository {
+	return &mediaRepository{db: db}
+}
+
+// FindByID finds a media file with its variants
+func (r *mediaRepository) FindByID(id uint) (*models.MediaFile, error) {
+	var file models.MediaFile
+	if err := r.db.Preload("Variants").First(&file, id).Error; err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// Create stores a media file with its variants
+func (r *mediaRepository) Create(file *models.MediaFile) error {
+	return r.db.Create(file).Error
+}
+
+// Attach marks the user's unattached originals among ids as used by a
+// record and returns how many were attached
+func (r *mediaRepository) Attach(purpose string, attachedID, userID uint, ids []uint) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	result := r.db.Model(&models.MediaFile{}).
+		Where("id IN ? AND parent_id IS NULL AND purpose = ? AND user_id = ?", ids, purpose, userID).
+		Where("attached_id IS NULL OR attached_id = ?", attachedID).
+		Update("attached_id", attachedID)
+	return result.RowsAffected, result.Error
+}
+
+// Detach releases the files attached to a record, except keepIDs. They are
+// removed by the next cleanup unless something else still links to them.
+func (r *mediaRepository) Detach(purpose string, attachedID uint, keepIDs []uint) error {
+	query := r.db.Model(&models.MediaFile{}).Where("purpose = ? AND attached_id = ?", purpose, attachedID)
+	if len(keepIDs) > 0 {
+		query = query.Where("id NOT IN ?", keepIDs)
+	}
+	return query.Update("attached_id", nil).Error
+}
+
+// mediaLinkPattern matches the stable URL of a media file (and its variants)
+// inside a URL column
+const mediaLinkPattern = `'/api/v1/media/' || media_files.id || '([/?#]|$)'`
+
+// FindOrphans returns originals, with their variants, that nothing uses any
+// more: the exercise, location, user or message they were attached to is
+// gone, or they were never attached and were uploaded before uploadedBefore.
+// Exercise media still linked from another exercise (e.g. a copy made when
+// cloning a program) are kept.
+func (r *mediaRepository) FindOrphans(uploadedBefore time.Time, limit int) ([]models.MediaFile, error) {
+	var files []models.MediaFile
+	err := r.db.Preload("Variants").
+		Where("parent_id IS NULL").
+		Where("attached_id IS NOT NULL OR created_at < ?", uploadedBefore).
+		Where(`NOT CASE purpose
+			WHEN 'exercise' THEN
+				EXISTS (SELECT 1 FROM exercise_library e WHERE e.id = media_files.attached_id AND e.deleted_at IS NULL)
+				OR EXISTS (SELECT 1 FROM exercise_library e WHERE e.deleted_at IS NULL AND (
+					e.video_url ~ (` + mediaLinkPattern + `)
+					OR e.thumbnail_url ~ (` + mediaLinkPattern + `)
+					OR EXISTS (SELECT 1 FROM unnest(e.images) AS i(url) WHERE i.url ~ (` + mediaLinkPattern + `))))
+			WHEN 'location' THEN
+				EXISTS (SELECT 1 FROM locations l WHERE l.id = media_files.attached_id AND l.deleted_at IS NULL)
+			WHEN 'profile' THEN
+				EXISTS (SELECT 1 FROM users u WHERE u.id = media_files.attached_id AND u.deleted_at IS NULL)
+			WHEN 'message' THEN
+				EXISTS (SELECT 1 FROM messages m WHERE m.id = media_files.attached_id)
+			ELSE TRUE
+		END`).
+		Order("id ASC").
+		Limit(limit).
+		Find(&files).Error
+	return files, err
+}
+
+// SentToUser reports whether a message attachment was sent in a
+// conversation the user is the trainer or the trainee of
+func (r *mediaRepository) SentToUser(id, userID uint) (bool, error) {
+	var count int64
+	err := r.db.Table("media_files f").
+		Joins("JOIN messages m ON m.id = f.attached_id").
+		Joins("JOIN conversations c ON c.id = m.conversation_id").
+		Joins("JOIN trainers tr ON tr.id = c.trainer_id").
+		Joins("JOIN trainees te ON te.id = c.trainee_id").
+		Where("f.id = ? AND f.purpose = ?", id, "message").
+		Where("tr.user_id = ? OR te.user_id = ?", userID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Delete removes media files and their variants
+func (r *mediaRepository) Delete(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Where("id IN ? OR parent_id IN ?", ids, ids).Delete(&models.MediaFile{}).Error
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"time"
+
+	"fitness-training-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// MessageRepository handles the messages of conversations
+type MessageRepository interface {
+	FindBefore(conversationID, beforeID uint, limit int) ([]models.Message, error)
+	FindAfter(conversationID, afterID uint, limit int) ([]models.Message, error)
+	FindLatest(conversationIDs []uint) (map[uint]models.Message, error)
+	CountUnread(conversationIDs []uint, readerUserID uint) (map[uint]int64, error)
+	Create(message *models.Message) error
+	MarkRead(conversationID, readerUserID, upToID uint, at time.Time) (int64, error)
+}
+
+type messageRepository struct {
+	db *gorm.DB
+}
+
+// NewMessageRepository creates a new message repository
+func NewMessageRepository(db *gorm.DB) MessageRepository {
+	return &messageRepository{db: db}
+}
+
+// FindBefore returns up to limit messages older than beforeID (0 = the
+// newest), newest first
+func (r *messageRepository) FindBefore(conversationID, beforeID uint, limit int) ([]models.Message, error) {
+	query := r.db.Preload("Attachments").Where("conversation_id = ?", conversationID)
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+
+	var messages []models.Message
+	err := query.Order("id DESC").Limit(limit).Find(&messages).Error
+	return messages, err
+}
+
+// FindAfter returns up to limit messages newer than afterID, oldest first
+func (r *messageRepository) FindAfter(conversationID, afterID uint, limit int) ([]models.Message, error) {
+	var messages []models.Message
+	err := r.db.Preload("Attachments").
+		Where("conversation_id = ? AND id > ?", conversationID, afterID).
+		Order("id ASC").Limit(limit).Find(&messages).Error
+	return messages, err
+}
+
+// FindLatest returns the newest message of each conversation
+func (r *messageRepository) FindLatest(conversationIDs []uint) (map[uint]models.Message, error) {
+	latest := make(map[uint]models.Message, len(conversationIDs))
+	if len(conversationIDs) == 0 {
+		return latest, nil
+	}
+
+	var messages []models.Message
+	err := r.db.Preload("Attachments").
+		Where("id IN (?)", r.db.Model(&models.Message{}).
+			Select("MAX(id)").
+			Where("conversation_id IN ?", conversationIDs).
+			Group("conversation_id")).
+		Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, message := range messages {
+		latest[message.ConversationID] = message
+	}
+	return latest, nil
+}
+
+// CountUnread counts, per conversation, the messages sent to the reader that
+// they have not read
+func (r *messageRepository) CountUnread(conversationIDs []uint, readerUserID uint) (map[uint]int64, error) {
+	counts := make(map[uint]int64, len(conversationIDs))
+	if len(conversationIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		ConversationID uint
+		Count          int64
+	}
+	err := r.db.Model(&models.Message{}).
+		Select("conversation_id, COUNT(*) AS count").
+		Where("conversation_id IN ? AND sender_user_id <> ? AND read_at IS NULL", conversationIDs, readerUserID).
+		Group("conversation_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.ConversationID] = row.Count
+	}
+	return counts, nil
+}
+
+// Create stores a message with its attachments
+func (r *messageRepository) Create(message *models.Message) error {
+	return r.db.Omit("Sender").Create(message).Error
+}
+
+// MarkRead sets the read receipt of the unread messages sent to the reader,
+// up to and including upToID (0 = all)
+func (r *messageRepository) MarkRead(conversationID, readerUserID, upToID uint, at time.Time) (int64, error) {
+	query := r.db.Model(&models.Message{}).
+		Where("conversation_id = ? AND sender_user_id <> ? AND read_at IS NULL", conversationID, readerUserID)
+	if upToID > 0 {
+		query = query.Where("id <= ?", upToID)
+	}
+
+	result := query.Update("read_at", at)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"fitness-training-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MetricGoalRepository handles trainer-set body metric goals
+type MetricGoalRepository interface {
+	FindByTraineeID(traineeID uint) ([]models.MetricGoal, error)
+	FindByID(traineeID, id uint) (*models.MetricGoal, error)
+	Save(goal *models.MetricGoal) error
+	Delete(goal *models.MetricGoal) error
+}
+
+type metricGoalRepository struct {
+	db *gorm.DB
+}
+
+// NewMetricGoalRepository creates a new metric goal repository
+func NewMetricGoalRepository(db *gorm.DB) MetricGoalRepository {
+	return &metricGoalRepository{db: db}
+}
+
+// FindByTraineeID lists a trainee's goals with who set them
+func (r *metricGoalRepository) FindByTraineeID(traineeID uint) ([]models.MetricGoal, error) {
+	var goals []models.MetricGoal
+	err := r.db.Preload("Setter").
+		Where("trainee_id = ?", traineeID).
+		Order("type ASC, measurement_type ASC").
+		Find(&goals).Error
+	return goals, err
+}
+
+// FindByID finds one of a trainee's goals
+func (r *metricGoalRepository) FindByID(traineeID, id uint) (*models.MetricGoal, error) {
+	var goal models.MetricGoal
+	if err := r.db.Where("trainee_id = ?", traineeID).First(&goal, id).Error; err != nil {
+		return nil, err
+	}
+	return &goal, nil
+}
+
+// Save creates the goal, or replaces the trainee's goal for the same type and
+// measurement
+func (r *metricGoalRepository) Save(goal *models.MetricGoal) error {
+	return r.db.Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "trainee_id"}, {Name: "type"}, {Name: "measurement_type"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"target_value", "target_date", "notes", "set_by", "updated_at",
+		}),
+	}).Create(goal).Error
+}
+
+// Delete removes a goal
+func (r *metricGoalRepository) Delete(goal *models.MetricGoal) error {
+	return r.db.Delete(goal).Error
+}
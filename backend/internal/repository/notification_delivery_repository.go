@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/pkg/notify"
+
+	"gorm.io/gorm"
+)
+
+// Delivery statuses (NotificationDelivery.Status)
+const (
+	DeliveryPending = "pending"
+	DeliverySending = "sending"
+	DeliverySent    = "sent"
+	DeliveryFailed  = "failed"
+	DeliverySkipped = "skipped"
+
+	// Low-priority deliveries held for the daily digest, and those a digest
+	// has since included
+	DeliveryDigest   = "digest"
+	DeliveryDigested = "digested"
+)
+
+// channelInApp is the in-app notification list (Notification.SentVia)
+const channelInApp = "in_app"
+
+// externalChannels are queued for every notification the user has not muted
+var externalChannels = []string{notify.ChannelEmail, notify.ChannelPush, notify.ChannelLINE}
+
+// NotificationDeliveryRepository handles the notification delivery outbox
+type NotificationDeliveryRepository interface {
+	ClaimDue(now time.Time, lease time.Duration, limit int) ([]models.NotificationDelivery, error)
+	MarkSent(delivery *models.NotificationDelivery, sentAt time.Time) error
+	MarkRetry(id uint, nextAttemptAt time.Time, lastError string) error
+	MarkFailed(id uint, lastError string) error
+	MarkSkipped(id uint, reason string) error
+
+	// Daily digest
+	FindDigestHeld(userID uint) ([]models.NotificationDelivery, error)
+	MarkDigested(ids []uint, digestID uint) error
+	ReleaseDigestHeld(userID uint, now time.Time) error
+}
+
+type notificationDeliveryRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationDeliveryRepository creates a new notification delivery repository
+func NewNotificationDeliveryRepository(db *gorm.DB) NotificationDeliveryRepository {
+	return &notificationDeliveryRepository{db: db}
+}
+
+// ClaimDue locks up to limit due deliveries for this worker, with their
+// notification and user. Claimed rows are leased: if the worker dies before
+// finishing them they become due again once the lease runs out. SKIP LOCKED
+// lets several API replicas drain the outbox without sending twice.
+func (r *notificationDeliveryRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]models.NotificationDelivery, error) {
+	var ids []uint
+	err := r.db.Raw(`
+		UPDATE notification_deliveries
+		SET status = ?, attempts = attempts + 1, next_attempt_at = ?, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM notification_deliveries
+			WHERE status IN (?, ?) AND next_attempt_at <= ?
+			ORDER BY next_attempt_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id`,
+		DeliverySending, now.Add(lease), now,
+		DeliveryPending, DeliverySending, now,
+		limit,
+	).Scan(&ids).Error
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	var deliveries []models.NotificationDelivery
+	err = r.db.Preload("Notification.User").
+		Where("id IN ?", ids).
+		Order("next_attempt_at, id").
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// MarkSent completes a delivery and records the channel in the
+// notification's SentVia
+func (r *notificationDeliveryRepository) MarkSent(delivery *models.NotificationDelivery, sentAt time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.NotificationDelivery{}).Where("id = ?", delivery.ID).
+			Updates(map[string]interface{}{
+				"status":     DeliverySent,
+				"sent_at":    sentAt,
+				"last_error": nil,
+				"updated_at": sentAt,
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(`
+			UPDATE notifications
+			SET sent_via = array_append(COALESCE(sent_via, '{}'), ?::text)
+			WHERE id = ? AND NOT (?::text = ANY(COALESCE(sent_via, '{}')))`,
+			delivery.Channel, delivery.NotificationID, delivery.Channel,
+		).Error
+	})
+}
+
+// MarkRetry schedules another attempt
+func (r *notificationDeliveryRepository) MarkRetry(id uint, nextAttemptAt time.Time, lastError string) error {
+	return r.finish(id, map[string]interface{}{
+		"status":          DeliveryPending,
+		"next_attempt_at": nextAttemptAt,
+		"last_error":      lastError,
+	})
+}
+
+// MarkFailed gives up on a delivery
+func (r *notificationDeliveryRepository) MarkFailed(id uint, lastError string) error {
+	return r.finish(id, map[string]interface{}{
+		"status":     DeliveryFailed,
+		"last_error": lastError,
+	})
+}
+
+// MarkSkipped records that there was nothing to send, e.g. the channel is not
+// configured or the user has no address for it
+func (r *notificationDeliveryRepository) MarkSkipped(id uint, reason string) error {
+	return r.finish(id, map[string]interface{}{
+		"status":     DeliverySkipped,
+		"last_error": reason,
+	})
+}
+
+func (r *notificationDeliveryRepository) finish(id uint, updates map[string]interface{}) error {
+	updates["updated_at"] = time.Now()
+	return r.db.Model(&models.NotificationDelivery{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// FindDigestHeld lists the deliveries waiting for a user's daily digest, with
+// their notifications
+func (r *notificationDeliveryRepository) FindDigestHeld(userID uint) ([]models.NotificationDelivery, error) {
+	var deliveries []models.NotificationDelivery
+	err := r.db.Preload("Notification").
+		Where("user_id = ? AND status = ?", userID, DeliveryDigest).
+		Order("created_at, id").
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// MarkDigested records that a digest notification included the deliveries
+func (r *notificationDeliveryRepository) MarkDigested(ids []uint, digestID uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Model(&models.NotificationDelivery{}).Where("id IN ?", ids).
+		Updates(map[string]interface{}{
+			"status":     DeliveryDigested,
+			"last_error": fmt.Sprintf("included in digest notification %d", digestID),
+			"updated_at": time.Now(),
+		}).Error
+}
+
+// ReleaseDigestHeld sends a user's held deliveries individually, e.g. after
+// they turn the daily digest off
+func (r *notificationDeliveryRepository) ReleaseDigestHeld(userID uint, now time.Time) error {
+	return r.db.Model(&models.NotificationDelivery{}).
+		Where("user_id = ? AND status = ?", userID, DeliveryDigest).
+		Updates(map[string]interface{}{
+			"status":          DeliveryPending,
+			"next_attempt_at": now,
+			"updated_at":      now,
+		}).Error
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"fitness-training-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationSettingsRepository handles notification preferences
+type NotificationSettingsRepository interface {
+	FindByUserID(userID uint) (*models.NotificationSettings, error)
+	FindDigestEnabled() ([]models.NotificationSettings, error)
+	Save(settings *models.NotificationSettings) error
+	ClaimDigest(id uint, digestAt, now time.Time) (bool, error)
+}
+
+type notificationSettingsRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationSettingsRepository creates a new notification settings repository
+func NewNotificationSettingsRepository(db *gorm.DB) NotificationSettingsRepository {
+	return &notificationSettingsRepository{db: db}
+}
+
+// FindByUserID returns a user's settings with their per-type preferences, or
+// the defaults when they never saved any
+func (r *notificationSettingsRepository) FindByUserID(userID uint) (*models.NotificationSettings, error) {
+	var settings models.NotificationSettings
+	err := r.db.Preload("Preferences").Where("user_id = ?", userID).First(&settings).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.DefaultNotificationSettings(userID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// FindDigestEnabled lists the settings of users who get a daily digest
+func (r *notificationSettingsRepository) FindDigestEnabled() ([]models.NotificationSettings, error) {
+	var settings []models.NotificationSettings
+	err := r.db.Where("daily_digest = ?", true).Order("id").Find(&settings).Error
+	return settings, err
+}
+
+// Save creates or replaces a user's settings and per-type preferences
+func (r *notificationSettingsRepository) Save(settings *models.NotificationSettings) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Omit(clause.Associations).Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"timezone", "quiet_hours_start", "quiet_hours_end", "daily_digest", "digest_time", "updated_at",
+			}),
+		}).Create(settings).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", settings.UserID).Delete(&models.NotificationPreference{}).Error; err != nil {
+			return err
+		}
+		if len(settings.Preferences) == 0 {
+			return nil
+		}
+		for i := range settings.Preferences {
+			settings.Preferences[i].ID = 0
+			settings.Preferences[i].UserID = settings.UserID
+		}
+		return tx.Create(&settings.Preferences).Error
+	})
+}
+
+// ClaimDigest records that the digest due at digestAt is being sent. It
+// reports false when another replica already claimed it.
+func (r *notificationSettingsRepository) ClaimDigest(id uint, digestAt, now time.Time) (bool, error) {
+	result := r.db.Model(&models.NotificationSettings{}).
+		Where("id = ? AND (last_digest_at IS NULL OR last_digest_at < ?)", id, digestAt).
+		UpdateColumn("last_digest_at", now)
+	return result.RowsAffected > 0, result.Error
+}
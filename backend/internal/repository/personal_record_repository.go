@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"strconv"
+
+	"fitness-training-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// PersonalRecordRepository handles personal record persistence
+type PersonalRecordRepository interface {
+	FindByTraineeID(traineeID uint) ([]models.PersonalRecord, error)
+	FindExerciseHistory(traineeID, exerciseLibraryID uint) ([]models.SessionExercise, error)
+	ReplaceForExercise(traineeID, exerciseLibraryID uint, records []models.PersonalRecord) error
+	CountPRs(traineeID uint, exerciseLibraryID *uint, minMuscleGroups int) (int64, error)
+}
+
+type personalRecordRepository struct {
+	db *gorm.DB
+}
+
+// NewPersonalRecordRepository creates a new personal record repository
+func NewPersonalRecordRepository(db *gorm.DB) PersonalRecordRepository {
+	return &personalRecordRepository{db: db}
+}
+
+// FindByTraineeID lists the current records of a trainee, grouped by exercise
+func (r *personalRecordRepository) FindByTraineeID(traineeID uint) ([]models.PersonalRecord, error) {
+	var records []models.PersonalRecord
+	err := r.db.
+		Preload("ExerciseLibrary").
+		Preload("SessionCard").
+		Where("trainee_id = ?", traineeID).
+		Order("exercise_library_id, record_type, weight DESC").
+		Find(&records).Error
+	return records, err
+}
+
+// FindExerciseHistory lists every logged instance of an exercise for a trainee
+// in chronological order, skipping deleted session cards
+func (r *personalRecordRepository) FindExerciseHistory(traineeID, exerciseLibraryID uint) ([]models.SessionExercise, error) {
+	var exercises []models.SessionExercise
+	err := r.db.
+		Joins("JOIN session_cards ON session_cards.id = session_exercises.session_card_id AND session_cards.deleted_at IS NULL").
+		Where("session_cards.trainee_id = ? AND session_exercises.exercise_library_id = ?", traineeID, exerciseLibraryID).
+		Preload("Sets").
+		Preload("SessionCard").
+		Order("session_cards.date, session_cards.id, session_exercises.exercise_order, session_exercises.id").
+		Find(&exercises).Error
+	return exercises, err
+}
+
+// ReplaceForExercise swaps the records of one exercise (use inside a transaction)
+func (r *personalRecordRepository) ReplaceForExercise(traineeID, exerciseLibraryID uint, records []models.PersonalRecord) error {
+	if err := r.db.
+		Where("trainee_id = ? AND exercise_library_id = ?", traineeID, exerciseLibraryID).
+		Delete(&models.PersonalRecord{}).Error; err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	return r.db.Create(&records).Error
+}
+
+// CountPRs counts the logged exercises that broke a record, from the PR
+// achievements keyed pr_<session card>_<exercise>_<record type>, optionally
+// only for one exercise or for exercises working at least minMuscleGroups
+// muscle groups. Session exercises only flag the records still standing, so
+// they undercount. Keys are compared as text: rule keys of other achievements
+// are not numbers.
+func (r *personalRecordRepository) CountPRs(traineeID uint, exerciseLibraryID *uint, minMuscleGroups int) (int64, error) {
+	query := r.db.Model(&models.Achievement{}).
+		Select("COUNT(DISTINCT split_part(achievements.rule_key, '_', 2) || '_' || split_part(achievements.rule_key, '_', 3))").
+		Joins("JOIN session_cards ON session_cards.id::text = split_part(achievements.rule_key, '_', 2) AND session_cards.deleted_at IS NULL").
+		Where("achievements.trainee_id = ? AND achievements.type = ? AND achievements.rule_key LIKE ?", traineeID, "pr", `pr\_%`)
+
+	if exerciseLibraryID != nil {
+		query = query.Where("split_part(achievements.rule_key, '_', 3) = ?", strconv.FormatUint(uint64(*exerciseLibraryID), 10))
+	}
+	if minMuscleGroups > 0 {
+		query = query.
+			Joins("JOIN exercise_library ON exercise_library.id::text = split_part(achievements.rule_key, '_', 3)").
+			Where("COALESCE(cardinality(exercise_library.muscle_groups), 0) >= ?", minMuscleGroups)
+	}
+
+	var count int64
+	err := query.Scan(&count).Error
+	return count, err
+}
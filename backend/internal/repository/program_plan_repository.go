@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"fitness-training-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ProgramPlanRepository handles the weekly plan of program versions: days,
+// planned exercises and prescribed sets
+type ProgramPlanRepository interface {
+	FindDays(versionID uint, week int) ([]models.ProgramDay, error)
+	FindDay(versionID uint, week, day int) (*models.ProgramDay, error)
+	FindDayByID(id uint) (*models.ProgramDay, error)
+	CreateDay(day *models.ProgramDay) error
+	ReplaceDay(day *models.ProgramDay) error
+	DeleteDay(id uint) error
+	ReplaceWeek(versionID uint, week int, days []models.ProgramDay) error
+	CountDaysOutside(versionID uint, totalWeeks, sessionsPerWeek int) (int64, error)
+}
+
+type programPlanRepository struct {
+	db *gorm.DB
+}
+
+// NewProgramPlanRepository creates a new program plan repository
+func NewProgramPlanRepository(db *gorm.DB) ProgramPlanRepository {
+	return &programPlanRepository{db: db}
+}
+
+// FindDays returns the days of one week (0 = every week) of a version in
+// order, with their exercises and sets
+func (r *programPlanRepository) FindDays(versionID uint, week int) ([]models.ProgramDay, error) {
+	query := r.withPlan().Where("program_version_id = ?", versionID)
+	if week > 0 {
+		query = query.Where("week = ?", week)
+	}
+
+	var days []models.ProgramDay
+	err := query.Order("week ASC, day ASC").Find(&days).Error
+	return days, err
+}
+
+// FindDay finds a day by its slot
+func (r *programPlanRepository) FindDay(versionID uint, week, day int) (*models.ProgramDay, error) {
+	var programDay models.ProgramDay
+	err := r.withPlan().
+		Where("program_version_id = ? AND week = ? AND day = ?", versionID, week, day).
+		First(&programDay).Error
+	if err != nil {
+		return nil, err
+	}
+	return &programDay, nil
+}
+
+// FindDayByID finds a day with its exercises and sets
+func (r *programPlanRepository) FindDayByID(id uint) (*models.ProgramDay, error) {
+	var programDay models.ProgramDay
+	if err := r.withPlan().First(&programDay, id).Error; err != nil {
+		return nil, err
+	}
+	return &programDay, nil
+}
+
+// CreateDay stores a day with its exercises and sets
+func (r *programPlanRepository) CreateDay(day *models.ProgramDay) error {
+	return r.db.Create(day).Error
+}
+
+// ReplaceDay updates a day and replaces its exercises and sets
+func (r *programPlanRepository) ReplaceDay(day *models.ProgramDay) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := deletePlannedExercises(tx, []uint{day.ID}); err != nil {
+			return err
+		}
+		if err := tx.Omit(clause.Associations).Save(day).Error; err != nil {
+			return err
+		}
+
+		for i := range day.Exercises {
+			day.Exercises[i].ID = 0
+			day.Exercises[i].ProgramDayID = day.ID
+		}
+		if len(day.Exercises) == 0 {
+			return nil
+		}
+		return tx.Create(&day.Exercises).Error
+	})
+}
+
+// DeleteDay deletes a day with its exercises and sets
+func (r *programPlanRepository) DeleteDay(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := deletePlannedExercises(tx, []uint{id}); err != nil {
+			return err
+		}
+		return tx.Delete(&models.ProgramDay{}, id).Error
+	})
+}
+
+// ReplaceWeek replaces every day of a week
+func (r *programPlanRepository) ReplaceWeek(versionID uint, week int, days []models.ProgramDay) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var ids []uint
+		if err := tx.Model(&models.ProgramDay{}).
+			Where("program_version_id = ? AND week = ?", versionID, week).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) > 0 {
+			if err := deletePlannedExercises(tx, ids); err != nil {
+				return err
+			}
+			if err := tx.Delete(&models.ProgramDay{}, ids).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(days) == 0 {
+			return nil
+		}
+		return tx.Create(&days).Error
+	})
+}
+
+// CountDaysOutside counts the days that would not fit a program of the given
+// length
+func (r *programPlanRepository) CountDaysOutside(versionID uint, totalWeeks, sessionsPerWeek int) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.ProgramDay{}).
+		Where("program_version_id = ? AND (week > ? OR day > ?)", versionID, totalWeeks, sessionsPerWeek).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *programPlanRepository) withPlan() *gorm.DB {
+	return r.db.
+		Preload("Exercises", func(db *gorm.DB) *gorm.DB {
+			return db.Order("order_index ASC")
+		}).
+		Preload("Exercises.ExerciseLibrary", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		}).
+		Preload("Exercises.Sets", func(db *gorm.DB) *gorm.DB {
+			return db.Order("set_number ASC")
+		})
+}
+
+// deletePlannedExercises deletes the exercises and sets of program days
+func deletePlannedExercises(tx *gorm.DB, dayIDs []uint) error {
+	exercises := tx.Model(&models.PlannedExercise{}).Select("id").Where("program_day_id IN ?", dayIDs)
+	if err := tx.Where("planned_exercise_id IN (?)", exercises).Delete(&models.PrescribedSet{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("program_day_id IN ?", dayIDs).Delete(&models.PlannedExercise{}).Error
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"fitness-training-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ProgramVersionRepository handles the versions of programs
+type ProgramVersionRepository interface {
+	FindByProgramID(programID uint) ([]models.ProgramVersion, error)
+	FindByID(id uint) (*models.ProgramVersion, error)
+	FindByNumber(programID uint, version int) (*models.ProgramVersion, error)
+	Create(version *models.ProgramVersion) error
+	Update(version *models.ProgramVersion) error
+	CountAssignments(versionID uint) (int64, error)
+	RefreshStats(programID uint) error
+}
+
+type programVersionRepository struct {
+	db *gorm.DB
+}
+
+// NewProgramVersionRepository creates a new program version repository
+func NewProgramVersionRepository(db *gorm.DB) ProgramVersionRepository {
+	return &programVersionRepository{db: db}
+}
+
+// FindByProgramID lists the versions of a program, oldest first
+func (r *programVersionRepository) FindByProgramID(programID uint) ([]models.ProgramVersion, error) {
+	var versions []models.ProgramVersion
+	err := r.db.Where("program_id = ?", programID).Order("version ASC").Find(&versions).Error
+	return versions, err
+}
+
+func (r *programVersionRepository) FindByID(id uint) (*models.ProgramVersion, error) {
+	var version models.ProgramVersion
+	if err := r.db.First(&version, id).Error; err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// FindByNumber finds a version by its number within the program
+func (r *programVersionRepository) FindByNumber(programID uint, version int) (*models.ProgramVersion, error) {
+	var programVersion models.ProgramVersion
+	err := r.db.Where("program_id = ? AND version = ?", programID, version).First(&programVersion).Error
+	if err != nil {
+		return nil, err
+	}
+	return &programVersion, nil
+}
+
+// Create stores a version without its days
+func (r *programVersionRepository) Create(version *models.ProgramVersion) error {
+	return r.db.Omit(clause.Associations).Create(version).Error
+}
+
+func (r *programVersionRepository) Update(version *models.ProgramVersion) error {
+	return r.db.Omit(clause.Associations).Save(version).Error
+}
+
+// CountAssignments counts the assignments pinned to a version, whatever
+// their status
+func (r *programVersionRepository) CountAssignments(versionID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.ProgramAssignment{}).
+		Where("program_version_id = ?", versionID).
+		Count(&count).Error
+	return count, err
+}
+
+// RefreshStats recomputes TotalAssignments and CompletionRate of a program
+// and of each of its versions. The completion rate is the share of finished
+// assignments (completed or cancelled) that were completed.
+func (r *programVersionRepository) RefreshStats(programID uint) error {
+	if err := r.db.Exec(`
+		UPDATE program_versions v SET
+			total_assignments = s.total,
+			completion_rate = s.rate
+		FROM (
+			SELECT v2.id,
+				COUNT(a.id) AS total,
+				COALESCE(ROUND(100.0 * COUNT(a.id) FILTER (WHERE a.status = 'completed')
+					/ NULLIF(COUNT(a.id) FILTER (WHERE a.status IN ('completed', 'cancelled')), 0), 2), 0) AS rate
+			FROM program_versions v2
+			LEFT JOIN program_assignments a ON a.program_version_id = v2.id AND a.deleted_at IS NULL
+			WHERE v2.program_id = ?
+			GROUP BY v2.id
+		) s
+		WHERE v.id = s.id`, programID).Error; err != nil {
+		return err
+	}
+
+	return r.db.Exec(`
+		UPDATE programs SET
+			total_assignments = s.total,
+			completion_rate = s.rate
+		FROM (
+			SELECT COUNT(*) AS total,
+				COALESCE(ROUND(100.0 * COUNT(*) FILTER (WHERE status = 'completed')
+					/ NULLIF(COUNT(*) FILTER (WHERE status IN ('completed', 'cancelled')), 0), 2), 0) AS rate
+			FROM program_assignments
+			WHERE program_id = ? AND deleted_at IS NULL
+		) s
+		WHERE programs.id = ?`, programID, programID).Error
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"fitness-training-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PushSubscriptionRepository handles Web Push subscriptions
+type PushSubscriptionRepository interface {
+	FindByUserID(userID uint) ([]models.PushSubscription, error)
+	Upsert(subscription *models.PushSubscription) error
+	Delete(id uint) error
+	DeleteByEndpoint(userID uint, endpoint string) error
+}
+
+type pushSubscriptionRepository struct {
+	db *gorm.DB
+}
+
+// NewPushSubscriptionRepository creates a new push subscription repository
+func NewPushSubscriptionRepository(db *gorm.DB) PushSubscriptionRepository {
+	return &pushSubscriptionRepository{db: db}
+}
+
+// FindByUserID lists the browsers a user receives push notifications on
+func (r *pushSubscriptionRepository) FindByUserID(userID uint) ([]models.PushSubscription, error) {
+	var subscriptions []models.PushSubscription
+	err := r.db.Where("user_id = ?", userID).Order("id").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// Upsert stores a subscription. Browsers re-subscribe with the same endpoint
+// when keys rotate, and a shared device may move between users.
+func (r *pushSubscriptionRepository) Upsert(subscription *models.PushSubscription) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "endpoint"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "p256dh", "auth", "user_agent", "updated_at"}),
+	}).Create(subscription).Error
+}
+
+// Delete removes a subscription, e.g. one the push service reports gone
+func (r *pushSubscriptionRepository) Delete(id uint) error {
+	return r.db.Delete(&models.PushSubscription{}, id).Error
+}
+
+// DeleteByEndpoint removes a user's subscription when they turn push off
+func (r *pushSubscriptionRepository) DeleteByEndpoint(userID uint, endpoint string) error {
+	return r.db.Where("user_id = ? AND endpoint = ?", userID, endpoint).
+		Delete(&models.PushSubscription{}).Error
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"fitness-training-backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RefreshTokenRepository handles refresh token persistence
+type RefreshTokenRepository interface {
+	Create(token *models.RefreshToken) error
+	FindByHash(hash string) (*models.RefreshToken, error)
+	FindActiveByUserID(userID uint) ([]models.RefreshToken, error)
+
+	// Revocation
+	RevokeIfActive(id uint, reason string, replacedByID *uint) (bool, error)
+	RevokeFamily(userID uint, familyID, reason string) error
+	RevokeAllForUser(userID uint, exceptFamilyID, reason string) error
+}
+
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewRefreshTokenRepository creates a new refresh token repository
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+// Create stores a new refresh token
+func (r *refreshTokenRepository) Create(token *models.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+// FindByHash finds a refresh token by its hash (revoked tokens included)
+func (r *refreshTokenRepository) FindByHash(hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := r.db.Where("token = ?", hash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// FindActiveByUserID lists the live tokens of a user, one per signed-in device
+func (r *refreshTokenRepository) FindActiveByUserID(userID uint) ([]models.RefreshToken, error) {
+	var tokens []models.RefreshToken
+	err := r.db.
+		Where("user_id = ? AND is_revoked = ? AND expires_at > ?", userID, false, time.Now()).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+// RevokeIfActive revokes a token only if it is still active.
+// Returns false when another request already revoked it (concurrent reuse).
+func (r *refreshTokenRepository) RevokeIfActive(id uint, reason string, replacedByID *uint) (bool, error) {
+	result := r.db.Model(&models.RefreshToken{}).
+		Where("id = ? AND is_revoked = ?", id, false).
+		Updates(map[string]interface{}{
+			"is_revoked":     true,
+			"revoked_at":     time.Now(),
+			"revoked_reason": reason,
+			"replaced_by_id": replacedByID,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// RevokeFamily revokes every active token descended from the same login
+func (r *refreshTokenRepository) RevokeFamily(userID uint, familyID, reason string) error {
+	return r.db.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND family_id = ? AND is_revoked = ?", userID, familyID, false).
+		Updates(map[string]interface{}{
+			"is_revoked":     true,
+			"revoked_at":     time.Now(),
+			"revoked_reason": reason,
+		}).Error
+}
+
+// RevokeAllForUser signs a user out of every device except the given family
+func (r *refreshTokenRepository) RevokeAllForUser(userID uint, exceptFamilyID, reason string) error {
+	return r.db.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND family_id <> ? AND is_revoked = ?", userID, exceptFamilyID, false).
+		Updates(map[string]interface{}{
+			"is_revoked":     true,
+			"revoked_at":     time.Now(),
+			"revoked_reason": reason,
+		}).Error
+}
@@ -1,10 +1,15 @@
 package repository
 
 import (
+	"fitness-training-backend/internal/database"
 	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/realtime"
+	"strings"
 	"time"
 
+	"github.com/lib/pq"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ==========================================
@@ -18,6 +23,7 @@ type TrainerRepository interface {
 	Create(trainer *models.Trainer) error
 	Update(trainer *models.Trainer) error
 	GetClients(trainerID uint) ([]models.Trainee, error)
+	RefreshClientCount(trainerID uint) error
 }
 
 type trainerRepository struct {
@@ -57,7 +63,7 @@ func (r *trainerRepository) Create(trainer *models.Trainer) error {
 }
 
 func (r *trainerRepository) Update(trainer *models.Trainer) error {
-	return r.db.Save(trainer).Error
+	return r.db.Omit(clause.Associations).Save(trainer).Error
 }
 
 func (r *trainerRepository) GetClients(trainerID uint) ([]models.Trainee, error) {
@@ -66,6 +72,13 @@ func (r *trainerRepository) GetClients(trainerID uint) ([]models.Trainee, error)
 	return trainees, err
 }
 
+// RefreshClientCount recounts the cached total_clients column
+func (r *trainerRepository) RefreshClientCount(trainerID uint) error {
+	count := r.db.Model(&models.Trainee{}).Select("COUNT(*)").Where("trainer_id = ?", trainerID)
+	return r.db.Model(&models.Trainer{}).Where("id = ?", trainerID).
+		Update("total_clients", count).Error
+}
+
 // ==========================================
 // PROGRAM REPOSITORY
 // ==========================================
@@ -73,6 +86,8 @@ func (r *trainerRepository) GetClients(trainerID uint) ([]models.Trainee, error)
 type ProgramRepository interface {
 	FindByID(id uint) (*models.Program, error)
 	FindByTrainerID(trainerID uint) ([]models.Program, error)
+	FindCatalog(visibilities []string, filters map[string]interface{}, limit, offset int) ([]models.Program, int64, error)
+	FindEquipment(programIDs []uint) (map[uint][]string, error)
 	Create(program *models.Program) error
 	Update(program *models.Program) error
 	Delete(id uint) error
@@ -81,8 +96,18 @@ type ProgramRepository interface {
 	FindAssignmentByID(id uint) (*models.ProgramAssignment, error)
 	FindActiveAssignmentByTraineeID(traineeID uint) (*models.ProgramAssignment, error)
 	FindAssignmentsByTraineeID(traineeID uint) ([]models.ProgramAssignment, error)
+	FindAssignmentsByTrainerID(trainerID uint, filters map[string]interface{}) ([]models.ProgramAssignment, error)
+	FindActiveAssignmentIDs() ([]uint, error)
 	CreateAssignment(assignment *models.ProgramAssignment) error
 	UpdateAssignment(assignment *models.ProgramAssignment) error
+	CountAssignmentSessions(assignmentID uint, today time.Time) (completed int64, due int64, err error)
+	ClaimBehindPlan(assignmentID uint, since time.Time) (bool, error)
+	CountCompletedAssignments(traineeID uint) (int64, error)
+	
+	// Progress Notes
+	FindProgressNotes(assignmentID uint) ([]models.ProgramProgressNote, error)
+	CreateProgressNote(note *models.ProgramProgressNote) error
+	DeleteProgressNote(assignmentID, noteID uint) error
 }
 
 type programRepository struct {
@@ -95,22 +120,97 @@ func NewProgramRepository(db *gorm.DB) ProgramRepository {
 
 func (r *programRepository) FindByID(id uint) (*models.Program, error) {
 	var program models.Program
-	err := r.db.Preload("Trainer.User").First(&program, id).Error
+	err := r.db.Preload("Trainer.User").Preload("OriginalAuthor.User").First(&program, id).Error
 	return &program, err
 }
 
 func (r *programRepository) FindByTrainerID(trainerID uint) ([]models.Program, error) {
 	var programs []models.Program
-	err := r.db.Where("trainer_id = ?", trainerID).Find(&programs).Error
+	err := r.db.Preload("OriginalAuthor.User").Where("trainer_id = ?", trainerID).Find(&programs).Error
 	return programs, err
 }
 
+// FindCatalog pages through the active programs shared with the given
+// visibilities, most cloned first. Filters: goal, level, minWeeks, maxWeeks
+// and equipment (the equipment at hand: programs whose newest plan needs
+// nothing else).
+func (r *programRepository) FindCatalog(visibilities []string, filters map[string]interface{}, limit, offset int) ([]models.Program, int64, error) {
+	query := r.db.Model(&models.Program{}).
+		Where("status = ? AND visibility IN ?", "active", visibilities)
+	
+	if goal, ok := filters["goal"].(string); ok && goal != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM unnest(goals) AS g(name) WHERE lower(g.name) = lower(?))", goal)
+	}
+	if level, ok := filters["level"].(string); ok && level != "" {
+		query = query.Where("target_fitness_level = ?", level)
+	}
+	if minWeeks, ok := filters["minWeeks"].(int); ok {
+		query = query.Where("total_weeks >= ?", minWeeks)
+	}
+	if maxWeeks, ok := filters["maxWeeks"].(int); ok {
+		query = query.Where("total_weeks <= ?", maxWeeks)
+	}
+	if equipment, ok := filters["equipment"].([]string); ok && len(equipment) > 0 {
+		query = query.Where(`NOT EXISTS (
+			SELECT 1 FROM program_versions v
+			JOIN program_days d ON d.program_version_id = v.id
+			JOIN planned_exercises pe ON pe.program_day_id = d.id
+			JOIN exercise_library e ON e.id = pe.exercise_library_id
+			CROSS JOIN LATERAL unnest(e.equipment) AS eq(name)
+			WHERE v.program_id = programs.id AND v.version = programs.version
+			  AND lower(eq.name) <> ALL(?))`, pq.Array(equipment))
+	}
+	
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	
+	var programs []models.Program
+	err := query.Preload("Trainer.User").Preload("OriginalAuthor.User").
+		Order("clone_count DESC, total_assignments DESC, updated_at DESC").
+		Limit(limit).Offset(offset).
+		Find(&programs).Error
+	return programs, total, err
+}
+
+// FindEquipment lists the equipment the exercises of each program's newest
+// plan need
+func (r *programRepository) FindEquipment(programIDs []uint) (map[uint][]string, error) {
+	equipment := make(map[uint][]string, len(programIDs))
+	if len(programIDs) == 0 {
+		return equipment, nil
+	}
+	
+	var rows []struct {
+		ProgramID uint
+		Equipment pq.StringArray `gorm:"type:text[]"`
+	}
+	err := r.db.Raw(`
+		SELECT v.program_id, array_agg(DISTINCT lower(eq.name) ORDER BY lower(eq.name)) AS equipment
+		FROM programs p
+		JOIN program_versions v ON v.program_id = p.id AND v.version = p.version
+		JOIN program_days d ON d.program_version_id = v.id
+		JOIN planned_exercises pe ON pe.program_day_id = d.id
+		JOIN exercise_library e ON e.id = pe.exercise_library_id
+		CROSS JOIN LATERAL unnest(e.equipment) AS eq(name)
+		WHERE p.id IN ?
+		GROUP BY v.program_id`, programIDs).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		equipment[row.ProgramID] = row.Equipment
+	}
+	return equipment, nil
+}
+
 func (r *programRepository) Create(program *models.Program) error {
 	return r.db.Create(program).Error
 }
 
 func (r *programRepository) Update(program *models.Program) error {
-	return r.db.Save(program).Error
+	return r.db.Omit(clause.Associations).Save(program).Error
 }
 
 func (r *programRepository) Delete(id uint) error {
@@ -119,13 +219,13 @@ func (r *programRepository) Delete(id uint) error {
 
 func (r *programRepository) FindAssignmentByID(id uint) (*models.ProgramAssignment, error) {
 	var assignment models.ProgramAssignment
-	err := r.db.Preload("Program").Preload("Trainee.User").First(&assignment, id).Error
+	err := r.db.Preload("Program.Trainer").Preload("ProgramVersion").Preload("Trainee.User").First(&assignment, id).Error
 	return &assignment, err
 }
 
 func (r *programRepository) FindActiveAssignmentByTraineeID(traineeID uint) (*models.ProgramAssignment, error) {
 	var assignment models.ProgramAssignment
-	err := r.db.Preload("Program.Trainer.User").
+	err := r.db.Preload("Program.Trainer.User").Preload("ProgramVersion").Preload("ProgressNotes", orderProgressNotes).
 		Where("trainee_id = ? AND status = ?", traineeID, "active").
 		First(&assignment).Error
 	return &assignment, err
@@ -133,17 +233,112 @@ func (r *programRepository) FindActiveAssignmentByTraineeID(traineeID uint) (*mo
 
 func (r *programRepository) FindAssignmentsByTraineeID(traineeID uint) ([]models.ProgramAssignment, error) {
 	var assignments []models.ProgramAssignment
-	err := r.db.Preload("Program").Where("trainee_id = ?", traineeID).
+	err := r.db.Preload("Program.Trainer.User").Preload("ProgramVersion").Where("trainee_id = ?", traineeID).
 		Order("created_at DESC").Find(&assignments).Error
 	return assignments, err
 }
 
+// FindAssignmentsByTrainerID lists the assignments of a trainer's programs,
+// filtered by status and behindPlan
+func (r *programRepository) FindAssignmentsByTrainerID(trainerID uint, filters map[string]interface{}) ([]models.ProgramAssignment, error) {
+	query := r.db.Preload("Program").Preload("ProgramVersion").Preload("Trainee.User").
+		Joins("JOIN programs ON programs.id = program_assignments.program_id").
+		Where("programs.trainer_id = ?", trainerID)
+
+	if status, ok := filters["status"]; ok {
+		query = query.Where("program_assignments.status = ?", status)
+	}
+	if behindPlan, ok := filters["behindPlan"]; ok {
+		query = query.Where("program_assignments.behind_plan = ?", behindPlan)
+	}
+
+	var assignments []models.ProgramAssignment
+	err := query.Order("program_assignments.start_date DESC, program_assignments.id DESC").
+		Find(&assignments).Error
+	return assignments, err
+}
+
+// FindActiveAssignmentIDs lists the assignments whose progress is tracked
+func (r *programRepository) FindActiveAssignmentIDs() ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.ProgramAssignment{}).Where("status = ?", "active").
+		Order("id").Pluck("id", &ids).Error
+	return ids, err
+}
+
 func (r *programRepository) CreateAssignment(assignment *models.ProgramAssignment) error {
 	return r.db.Create(assignment).Error
 }
 
 func (r *programRepository) UpdateAssignment(assignment *models.ProgramAssignment) error {
-	return r.db.Save(assignment).Error
+	return r.db.Omit(clause.Associations).Save(assignment).Error
+}
+
+// CountAssignmentSessions counts the completed schedules of an assignment and
+// the ones due before today. Due sessions are completed ones plus past ones
+// that were missed: no-shows, still pending, or cancelled by the trainee.
+// Sessions the trainer cancelled are not held against the trainee.
+func (r *programRepository) CountAssignmentSessions(assignmentID uint, today time.Time) (int64, int64, error) {
+	var counts struct {
+		Completed int64
+		Due       int64
+	}
+	err := r.db.Model(&models.Schedule{}).
+		Select(`COUNT(*) FILTER (WHERE schedules.status = 'completed') AS completed,
+			COUNT(*) FILTER (WHERE schedules.status = 'completed' OR (schedules.date < ? AND
+				(schedules.status <> 'cancelled' OR schedules.cancelled_by = trainees.user_id))) AS due`, today).
+		Joins("JOIN trainees ON trainees.id = schedules.trainee_id").
+		Where("schedules.program_assignment_id = ?", assignmentID).
+		Scan(&counts).Error
+	return counts.Completed, counts.Due, err
+}
+
+// ClaimBehindPlan flags an assignment as behind plan. It reports false when
+// it already was, so the trainer is told only once per lapse.
+func (r *programRepository) ClaimBehindPlan(assignmentID uint, since time.Time) (bool, error) {
+	result := r.db.Model(&models.ProgramAssignment{}).
+		Where("id = ? AND behind_plan = ?", assignmentID, false).
+		Updates(map[string]interface{}{
+			"behind_plan":       true,
+			"behind_plan_since": since,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// CountCompletedAssignments counts the programs a trainee has finished
+func (r *programRepository) CountCompletedAssignments(traineeID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.ProgramAssignment{}).
+		Where("trainee_id = ? AND status = ?", traineeID, "completed").
+		Count(&count).Error
+	return count, err
+}
+
+func (r *programRepository) FindProgressNotes(assignmentID uint) ([]models.ProgramProgressNote, error) {
+	var notes []models.ProgramProgressNote
+	err := r.db.Scopes(orderProgressNotes).Where("program_assignment_id = ?", assignmentID).
+		Find(&notes).Error
+	return notes, err
+}
+
+func (r *programRepository) CreateProgressNote(note *models.ProgramProgressNote) error {
+	return r.db.Create(note).Error
+}
+
+func (r *programRepository) DeleteProgressNote(assignmentID, noteID uint) error {
+	result := r.db.Where("program_assignment_id = ?", assignmentID).Delete(&models.ProgramProgressNote{}, noteID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// orderProgressNotes lists progress notes newest first
+func orderProgressNotes(db *gorm.DB) *gorm.DB {
+	return db.Order("date DESC, id DESC")
 }
 
 // ==========================================
@@ -155,8 +350,10 @@ type SessionCardRepository interface {
 	FindByTraineeID(traineeID uint, limit, offset int) ([]models.SessionCard, int64, error)
 	FindByTrainerID(trainerID uint, limit, offset int) ([]models.SessionCard, int64, error)
 	Search(traineeID uint, filters map[string]interface{}) ([]models.SessionCard, error)
+	ExistsByImportKey(traineeID uint, importKey string) (bool, error)
 	Create(sessionCard *models.SessionCard) error
 	Update(sessionCard *models.SessionCard) error
+	ReplaceExercises(sessionCard *models.SessionCard) error
 	Delete(id uint) error
 }
 
@@ -187,7 +384,7 @@ func (r *sessionCardRepository) FindByTraineeID(traineeID uint, limit, offset in
 	
 	err := query.
 		Preload("Trainer.User").
-		Preload("Exercises").
+		Preload("Exercises.Sets").
 		Order("date DESC").
 		Limit(limit).
 		Offset(offset).
@@ -205,7 +402,7 @@ func (r *sessionCardRepository) FindByTrainerID(trainerID uint, limit, offset in
 	
 	err := query.
 		Preload("Trainee.User").
-		Preload("Exercises").
+		Preload("Exercises.Sets").
 		Order("date DESC").
 		Limit(limit).
 		Offset(offset).
@@ -215,7 +412,7 @@ func (r *sessionCardRepository) FindByTrainerID(trainerID uint, limit, offset in
 }
 
 func (r *sessionCardRepository) Search(traineeID uint, filters map[string]interface{}) ([]models.SessionCard, error) {
-	query := r.db.Preload("Trainer.User").Preload("Exercises").
+	query := r.db.Preload("Trainer.User").Preload("Exercises.Sets").
 		Where("trainee_id = ?", traineeID)
 	
 	if fromDate, ok := filters["fromDate"].(time.Time); ok {
@@ -224,18 +421,56 @@ func (r *sessionCardRepository) Search(traineeID uint, filters map[string]interf
 	if toDate, ok := filters["toDate"].(time.Time); ok {
 		query = query.Where("date <= ?", toDate)
 	}
+	if category, ok := filters["category"].(string); ok && category != "" {
+		query = query.Where("id IN (?)", r.db.Model(&models.SessionExercise{}).
+			Select("session_card_id").Where("category = ?", category))
+	}
+	if exerciseName, ok := filters["exerciseName"].(string); ok && exerciseName != "" {
+		query = query.Where("id IN (?)", r.db.Model(&models.SessionExercise{}).
+			Select("session_card_id").Where("name ILIKE ?", "%"+exerciseName+"%"))
+	}
 	
 	var sessionCards []models.SessionCard
 	err := query.Order("date DESC").Find(&sessionCards).Error
 	return sessionCards, err
 }
 
+// ExistsByImportKey checks if the trainee already imported an activity,
+// including into a card since deleted
+func (r *sessionCardRepository) ExistsByImportKey(traineeID uint, importKey string) (bool, error) {
+	var count int64
+	err := r.db.Unscoped().Model(&models.SessionCard{}).
+		Where("trainee_id = ? AND import_key = ?", traineeID, importKey).
+		Count(&count).Error
+	return count > 0, err
+}
+
 func (r *sessionCardRepository) Create(sessionCard *models.SessionCard) error {
 	return r.db.Create(sessionCard).Error
 }
 
 func (r *sessionCardRepository) Update(sessionCard *models.SessionCard) error {
-	return r.db.Save(sessionCard).Error
+	return r.db.Omit(clause.Associations).Save(sessionCard).Error
+}
+
+// ReplaceExercises deletes the card's exercises and sets and stores
+// sessionCard.Exercises in their place (use inside a transaction)
+func (r *sessionCardRepository) ReplaceExercises(sessionCard *models.SessionCard) error {
+	exerciseIDs := r.db.Model(&models.SessionExercise{}).Select("id").Where("session_card_id = ?", sessionCard.ID)
+	if err := r.db.Where("session_exercise_id IN (?)", exerciseIDs).Delete(&models.ExerciseSet{}).Error; err != nil {
+		return err
+	}
+	if err := r.db.Where("session_card_id = ?", sessionCard.ID).Delete(&models.SessionExercise{}).Error; err != nil {
+		return err
+	}
+
+	for i := range sessionCard.Exercises {
+		sessionCard.Exercises[i].SessionCardID = sessionCard.ID
+	}
+	if len(sessionCard.Exercises) == 0 {
+		return nil
+	}
+	return r.db.Create(&sessionCard.Exercises).Error
 }
 
 func (r *sessionCardRepository) Delete(id uint) error {
@@ -247,10 +482,14 @@ func (r *sessionCardRepository) Delete(id uint) error {
 // ==========================================
 
 type NotificationRepository interface {
+	FindByID(id uint) (*models.Notification, error)
 	FindByUserID(userID uint, limit, offset int) ([]models.Notification, int64, error)
 	FindUnreadByUserID(userID uint) ([]models.Notification, error)
 	CountUnread(userID uint) (int64, error)
+	FindSince(userID, afterID uint, limit int) ([]models.Notification, error)
+	LatestID(userID uint) (uint, error)
 	Create(notification *models.Notification) error
+	CreateWithChannels(notification *models.Notification, channels []string) error
 	MarkAsRead(id uint) error
 	MarkAllAsRead(userID uint) error
 }
@@ -263,11 +502,17 @@ func NewNotificationRepository(db *gorm.DB) NotificationRepository {
 	return &notificationRepository{db: db}
 }
 
+func (r *notificationRepository) FindByID(id uint) (*models.Notification, error) {
+	var notification models.Notification
+	err := r.db.First(&notification, id).Error
+	return &notification, err
+}
+
 func (r *notificationRepository) FindByUserID(userID uint, limit, offset int) ([]models.Notification, int64, error) {
 	var notifications []models.Notification
 	var total int64
 	
-	query := r.db.Model(&models.Notification{}).Where("user_id = ?", userID)
+	query := r.db.Model(&models.Notification{}).Scopes(shownInApp).Where("user_id = ?", userID)
 	query.Count(&total)
 	
 	err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&notifications).Error
@@ -276,39 +521,175 @@ func (r *notificationRepository) FindByUserID(userID uint, limit, offset int) ([
 
 func (r *notificationRepository) FindUnreadByUserID(userID uint) ([]models.Notification, error) {
 	var notifications []models.Notification
-	err := r.db.Where("user_id = ? AND is_read = ?", userID, false).
+	err := r.db.Scopes(shownInApp).Where("user_id = ? AND is_read = ?", userID, false).
 		Order("created_at DESC").Find(&notifications).Error
 	return notifications, err
 }
 
 func (r *notificationRepository) CountUnread(userID uint) (int64, error) {
 	var count int64
-	err := r.db.Model(&models.Notification{}).
+	err := r.db.Model(&models.Notification{}).Scopes(shownInApp).
 		Where("user_id = ? AND is_read = ?", userID, false).
 		Count(&count).Error
 	return count, err
 }
 
+// FindSince returns the notifications shown in the app that were created
+// after afterID, oldest first
+func (r *notificationRepository) FindSince(userID, afterID uint, limit int) ([]models.Notification, error) {
+	var notifications []models.Notification
+	err := r.db.Scopes(shownInApp).Where("user_id = ? AND id > ?", userID, afterID).
+		Order("id ASC").Limit(limit).Find(&notifications).Error
+	return notifications, err
+}
+
+// LatestID returns the ID of the user's newest notification, or 0
+func (r *notificationRepository) LatestID(userID uint) (uint, error) {
+	var id uint
+	err := r.db.Model(&models.Notification{}).Where("user_id = ?", userID).
+		Select("COALESCE(MAX(id), 0)").Scan(&id).Error
+	return id, err
+}
+
+// Create stores a notification and queues its delivery through the external
+// channels in the same transaction, following the user's preferences: muted
+// channels are left out, low-priority notifications wait for the daily
+// digest and deliveries due in quiet hours wait until they end. The delivery
+// worker skips channels that are not configured or that the user has no
+// address for. Open notification streams are told once it has committed.
 func (r *notificationRepository) Create(notification *models.Notification) error {
-	return r.db.Create(notification).Error
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		settings, err := NewNotificationSettingsRepository(tx).FindByUserID(notification.UserID)
+		if err != nil {
+			return err
+		}
+		
+		sentVia := pq.StringArray{}
+		for _, channel := range notification.SentVia {
+			if channel != channelInApp {
+				sentVia = append(sentVia, channel)
+			}
+		}
+		if settings.Allows(notification.Type, channelInApp) {
+			sentVia = append(pq.StringArray{channelInApp}, sentVia...)
+		}
+		notification.SentVia = sentVia
+		
+		if err := tx.Create(notification).Error; err != nil {
+			return err
+		}
+		
+		now := notification.CreatedAt
+		quietUntil, quiet := settings.QuietUntil(now)
+		
+		deliveries := make([]models.NotificationDelivery, 0, len(externalChannels))
+		for _, channel := range externalChannels {
+			if !settings.Allows(notification.Type, channel) {
+				continue
+			}
+			
+			delivery := models.NotificationDelivery{
+				NotificationID: notification.ID,
+				UserID:         notification.UserID,
+				Channel:        channel,
+				Status:         DeliveryPending,
+				NextAttemptAt:  now,
+			}
+			switch {
+			case notification.Priority == "low" && settings.DailyDigest:
+				delivery.Status = DeliveryDigest
+			case quiet:
+				delivery.NextAttemptAt = quietUntil
+			}
+			deliveries = append(deliveries, delivery)
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+		return tx.Create(&deliveries).Error
+	})
+	if err != nil {
+		return err
+	}
+	
+	if len(notification.SentVia) > 0 && notification.SentVia[0] == channelInApp {
+		userID, id := notification.UserID, notification.ID
+		database.AfterCommit(r.db, func() {
+			realtime.Publish(userID, realtime.Event{Type: realtime.EventNotification, ID: id})
+		})
+	}
+	return nil
+}
+
+// CreateWithChannels stores a notification that is only delivered through the
+// given external channels, regardless of preferences (e.g. a daily digest)
+func (r *notificationRepository) CreateWithChannels(notification *models.Notification, channels []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(notification).Error; err != nil {
+			return err
+		}
+		
+		deliveries := make([]models.NotificationDelivery, 0, len(channels))
+		for _, channel := range channels {
+			deliveries = append(deliveries, models.NotificationDelivery{
+				NotificationID: notification.ID,
+				UserID:         notification.UserID,
+				Channel:        channel,
+				Status:         DeliveryPending,
+				NextAttemptAt:  notification.CreatedAt,
+			})
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+		return tx.Create(&deliveries).Error
+	})
 }
 
 func (r *notificationRepository) MarkAsRead(id uint) error {
 	now := time.Now()
-	return r.db.Model(&models.Notification{}).Where("id = ?", id).
+	var notification models.Notification
+	result := r.db.Model(&notification).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "user_id"}}}).
+		Where("id = ? AND is_read = ?", id, false).
 		Updates(map[string]interface{}{
 			"is_read": true,
 			"read_at": now,
-		}).Error
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+	
+	r.publishRead(notification.UserID)
+	return nil
 }
 
 func (r *notificationRepository) MarkAllAsRead(userID uint) error {
 	now := time.Now()
-	return r.db.Model(&models.Notification{}).Where("user_id = ? AND is_read = ?", userID, false).
+	result := r.db.Model(&models.Notification{}).Where("user_id = ? AND is_read = ?", userID, false).
 		Updates(map[string]interface{}{
 			"is_read": true,
 			"read_at": now,
-		}).Error
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+	
+	r.publishRead(userID)
+	return nil
+}
+
+// publishRead tells the user's open streams that the unread count changed
+func (r *notificationRepository) publishRead(userID uint) {
+	database.AfterCommit(r.db, func() {
+		realtime.Publish(userID, realtime.Event{Type: realtime.EventRead})
+	})
+}
+
+// shownInApp hides notifications the user muted in the app. Rows created
+// before preferences existed have no SentVia and are shown.
+func shownInApp(db *gorm.DB) *gorm.DB {
+	return db.Where("sent_via IS NULL OR ? = ANY(sent_via)", channelInApp)
 }
 
 // ==========================================
@@ -317,19 +698,25 @@ func (r *notificationRepository) MarkAllAsRead(userID uint) error {
 
 type MetricRepository interface {
 	FindByTraineeID(traineeID uint, metricType *string) ([]models.Metric, error)
+	FindHistory(traineeID uint) ([]models.Metric, error)
+	FindImported(traineeIDs []uint, importKeys []string) ([]models.Metric, error)
 	Create(metric *models.Metric) error
+	CreateImported(metrics []models.Metric) (int64, error)
 }
 
 type metricRepository struct {
 	db *gorm.DB
 }
 
+// metricImportBatchSize bounds the import keys or metrics of one query
+const metricImportBatchSize = 1000
+
 func NewMetricRepository(db *gorm.DB) MetricRepository {
 	return &metricRepository{db: db}
 }
 
 func (r *metricRepository) FindByTraineeID(traineeID uint, metricType *string) ([]models.Metric, error) {
-	query := r.db.Where("trainee_id = ?", traineeID)
+	query := r.db.Preload("Recorder").Where("trainee_id = ?", traineeID)
 	
 	if metricType != nil && *metricType != "" {
 		query = query.Where("type = ?", *metricType)
@@ -340,10 +727,55 @@ func (r *metricRepository) FindByTraineeID(traineeID uint, metricType *string) (
 	return metrics, err
 }
 
+// FindHistory returns every metric of a trainee, oldest first
+func (r *metricRepository) FindHistory(traineeID uint) ([]models.Metric, error) {
+	var metrics []models.Metric
+	err := r.db.Where("trainee_id = ?", traineeID).Order("date ASC, id ASC").Find(&metrics).Error
+	return metrics, err
+}
+
 func (r *metricRepository) Create(metric *models.Metric) error {
 	return r.db.Create(metric).Error
 }
 
+// FindImported returns the imported metrics of the trainees with one of the
+// import keys, including deleted ones, which an import must not bring back
+func (r *metricRepository) FindImported(traineeIDs []uint, importKeys []string) ([]models.Metric, error) {
+	var metrics []models.Metric
+	if len(traineeIDs) == 0 {
+		return metrics, nil
+	}
+	// Large exports have more keys than a query can take parameters
+	for start := 0; start < len(importKeys); start += metricImportBatchSize {
+		end := start + metricImportBatchSize
+		if end > len(importKeys) {
+			end = len(importKeys)
+		}
+		var batch []models.Metric
+		err := r.db.Unscoped().Select("id", "trainee_id", "import_key").
+			Where("trainee_id IN ? AND import_key IN ?", traineeIDs, importKeys[start:end]).
+			Find(&batch).Error
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, batch...)
+	}
+	return metrics, nil
+}
+
+// CreateImported stores imported metrics, skipping those whose trainee and
+// import key are already stored. It returns how many were inserted.
+func (r *metricRepository) CreateImported(metrics []models.Metric) (int64, error) {
+	if len(metrics) == 0 {
+		return 0, nil
+	}
+	result := r.db.Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "trainee_id"}, {Name: "import_key"}},
+		DoNothing: true,
+	}).CreateInBatches(&metrics, metricImportBatchSize)
+	return result.RowsAffected, result.Error
+}
+
 // ==========================================
 // LOCATION REPOSITORY
 // ==========================================
@@ -379,3 +811,238 @@ func (r *locationRepository) FindActive() ([]models.Location, error) {
 	err := r.db.Where("is_active = ?", true).Find(&locations).Error
 	return locations, err
 }
+
+// ==========================================
+// EXERCISE LIBRARY REPOSITORY
+// ==========================================
+
+type ExerciseRepository interface {
+	FindByID(id uint) (*models.ExerciseLibrary, error)
+	FindByName(trainerID uint, name string) (*models.ExerciseLibrary, error)
+	Search(trainerID uint, filters map[string]interface{}, sort string, limit, offset int) ([]models.ExerciseLibrary, int64, error)
+	Facets(trainerID uint, filters map[string]interface{}) (map[string][]FacetCount, error)
+	GetCategories() ([]CategoryCount, error)
+	Create(exercise *models.ExerciseLibrary) error
+	Update(exercise *models.ExerciseLibrary) error
+	Delete(id uint) error
+	RefreshUsage(exerciseIDs []uint) error
+}
+
+// CategoryCount is the number of public exercises in a category
+type CategoryCount struct {
+	Category string
+	Count    int
+}
+
+// FacetCount is the number of exercises with a facet value
+type FacetCount struct {
+	Value string
+	Count int
+}
+
+type exerciseRepository struct {
+	db *gorm.DB
+}
+
+func NewExerciseRepository(db *gorm.DB) ExerciseRepository {
+	return &exerciseRepository{db: db}
+}
+
+func (r *exerciseRepository) FindByID(id uint) (*models.ExerciseLibrary, error) {
+	var exercise models.ExerciseLibrary
+	err := r.db.Preload("Trainer.User").First(&exercise, id).Error
+	return &exercise, err
+}
+
+// FindByName finds the exercise a trainer can use with a name, case ignored,
+// ranked by ownership as in Search
+func (r *exerciseRepository) FindByName(trainerID uint, name string) (*models.ExerciseLibrary, error) {
+	var exercise models.ExerciseLibrary
+	err := r.db.
+		Where("lower(name) = lower(?)", name).
+		Where("is_public = ? OR trainer_id IS NULL OR trainer_id = ?", true, trainerID).
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "CASE WHEN trainer_id = ? THEN 0 WHEN trainer_id IS NULL THEN 1 ELSE 2 END, id",
+			Vars:               []interface{}{trainerID},
+			WithoutParentheses: true,
+		}}).
+		First(&exercise).Error
+	return &exercise, err
+}
+
+// Search pages through the exercises a trainer can use, one per name: their
+// own exercise wins over the shared library's, which wins over another
+// trainer's public one. Filters: search (words matched anywhere in the name
+// or description, accents and case ignored, with typo-tolerant matching of
+// the whole text), owner (mine, library or shared) and the facets category,
+// muscleGroup, equipment and difficulty (lists; any value matches). Sort:
+// relevance (default when searching), name (default), usage or newest.
+func (r *exerciseRepository) Search(trainerID uint, filters map[string]interface{}, sort string, limit, offset int) ([]models.ExerciseLibrary, int64, error) {
+	query := r.filterExercises(trainerID, filters, "")
+	
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	
+	search, _ := filters["search"].(string)
+	switch {
+	case sort == "usage":
+		query = query.Order("usage_count DESC")
+	case sort == "newest":
+		query = query.Order("created_at DESC")
+	case search != "" && (sort == "" || sort == "relevance"):
+		query = query.Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "word_similarity(search_normalize(?), search_normalize(name)) DESC",
+			Vars:               []interface{}{search},
+			WithoutParentheses: true,
+		}})
+	}
+	
+	var exercises []models.ExerciseLibrary
+	err := query.Preload("Trainer.User").
+		Order("name ASC").Order("id ASC").
+		Limit(limit).Offset(offset).
+		Find(&exercises).Error
+	return exercises, total, err
+}
+
+// Facets counts the exercises Search would return for each value of each
+// facet. A facet's own filter is left out of its counts, so they show what
+// picking another value would add.
+func (r *exerciseRepository) Facets(trainerID uint, filters map[string]interface{}) (map[string][]FacetCount, error) {
+	facets := make(map[string][]FacetCount, len(exerciseFacets))
+	for name, facet := range exerciseFacets {
+		query := r.filterExercises(trainerID, filters, name)
+		if facet.array {
+			query = query.
+				Select("lower(f.value) AS value, COUNT(DISTINCT exercise_library.id) AS count").
+				Joins("CROSS JOIN LATERAL unnest(exercise_library." + facet.column + ") AS f(value)").
+				Group("lower(f.value)")
+		} else {
+			query = query.
+				Select(facet.column+" AS value, COUNT(*) AS count").
+				Where(facet.column + " IS NOT NULL").
+				Group(facet.column)
+		}
+		
+		var counts []FacetCount
+		if err := query.Order("count DESC, value ASC").Scan(&counts).Error; err != nil {
+			return nil, err
+		}
+		facets[name] = counts
+	}
+	return facets, nil
+}
+
+// exerciseFacets maps facet filters to their columns
+var exerciseFacets = map[string]struct {
+	column string
+	array  bool
+}{
+	"category":    {column: "category"},
+	"muscleGroup": {column: "muscle_groups", array: true},
+	"equipment":   {column: "equipment", array: true},
+	"difficulty":  {column: "difficulty"},
+}
+
+// filterExercises applies the Search filters, except the facet named skip, to
+// the exercises visible to the trainer. The filters select the rows before
+// they are ranked, so the indexes on the table are used and a name shows the
+// best-ranked exercise that matches (a shared exercise is listed under
+// owner=shared even when the trainer has their own of that name).
+func (r *exerciseRepository) filterExercises(trainerID uint, filters map[string]interface{}, skip string) *gorm.DB {
+	query := r.db.Model(&models.ExerciseLibrary{}).
+		Where("is_public = ? OR trainer_id IS NULL OR trainer_id = ?", true, trainerID)
+	
+	if search, ok := filters["search"].(string); ok && search != "" {
+		// Every word appears (substring match also works for Thai, which has
+		// no spaces between words), or the text is close to the search
+		words := make([]string, 0)
+		vars := make([]interface{}, 0)
+		for _, word := range strings.Fields(search) {
+			words = append(words, "search_normalize(name || ' ' || COALESCE(description, '')) LIKE '%' || search_normalize(?) || '%'")
+			vars = append(vars, escapeLike(word))
+		}
+		query = query.Where("(("+strings.Join(words, " AND ")+") OR search_normalize(?) <% search_normalize(name || ' ' || COALESCE(description, '')))",
+			append(vars, search)...)
+	}
+	
+	switch owner, _ := filters["owner"].(string); owner {
+	case "mine":
+		query = query.Where("trainer_id = ?", trainerID)
+	case "library":
+		query = query.Where("trainer_id IS NULL")
+	case "shared":
+		query = query.Where("trainer_id <> ?", trainerID)
+	}
+	
+	for name, facet := range exerciseFacets {
+		values, _ := filters[name].([]string)
+		if name == skip || len(values) == 0 {
+			continue
+		}
+		if facet.array {
+			query = query.Where("EXISTS (SELECT 1 FROM unnest(exercise_library."+facet.column+") AS f(value) WHERE lower(f.value) = ANY(?))", pq.Array(values))
+		} else {
+			query = query.Where("lower("+facet.column+") = ANY(?)", pq.Array(values))
+		}
+	}
+	
+	// One row per name, ranked by ownership
+	ranked := query.Select(`exercise_library.*, ROW_NUMBER() OVER (
+		PARTITION BY lower(name)
+		ORDER BY CASE WHEN trainer_id = ? THEN 0 WHEN trainer_id IS NULL THEN 1 ELSE 2 END, id
+	) AS name_rank`, trainerID)
+	return r.db.Table("(?) AS exercise_library", ranked).Where("name_rank = 1")
+}
+
+// RefreshUsage recounts the cached usage_count of exercises: the session
+// exercises logged with them plus their planned exercises in the newest
+// version of each program
+func (r *exerciseRepository) RefreshUsage(exerciseIDs []uint) error {
+	if len(exerciseIDs) == 0 {
+		return nil
+	}
+	return r.db.Exec(`
+		UPDATE exercise_library e SET usage_count = (
+			SELECT COUNT(*) FROM session_exercises se
+			JOIN session_cards c ON c.id = se.session_card_id AND c.deleted_at IS NULL
+			WHERE se.exercise_library_id = e.id
+		) + (
+			SELECT COUNT(*) FROM planned_exercises pe
+			JOIN program_days d ON d.id = pe.program_day_id
+			JOIN program_versions v ON v.id = d.program_version_id
+			JOIN programs p ON p.id = v.program_id AND p.version = v.version AND p.deleted_at IS NULL
+			WHERE pe.exercise_library_id = e.id
+		)
+		WHERE e.id IN ?`, exerciseIDs).Error
+}
+
+// escapeLike escapes the LIKE wildcards of a literal
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+func (r *exerciseRepository) GetCategories() ([]CategoryCount, error) {
+	var categories []CategoryCount
+	err := r.db.Model(&models.ExerciseLibrary{}).
+		Select("category, COUNT(*) AS count").
+		Where("is_public = ? OR trainer_id IS NULL", true).
+		Group("category").
+		Order("category ASC").
+		Scan(&categories).Error
+	return categories, err
+}
+
+func (r *exerciseRepository) Create(exercise *models.ExerciseLibrary) error {
+	return r.db.Create(exercise).Error
+}
+
+func (r *exerciseRepository) Update(exercise *models.ExerciseLibrary) error {
+	return r.db.Omit("Trainer").Save(exercise).Error
+}
+
+func (r *exerciseRepository) Delete(id uint) error {
+	return r.db.Delete(&models.ExerciseLibrary{}, id).Error
+}
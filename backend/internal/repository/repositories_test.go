@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+
+	"fitness-training-backend/internal/models"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	return db
+}
+
+// The filters must select rows inside the ranked subquery: outside it they
+// cannot use the indexes and hide shared exercises named like the trainer's
+func TestFilterExercisesRanksMatchingRows(t *testing.T) {
+	repo := &exerciseRepository{db: dryRunDB(t)}
+	filters := map[string]interface{}{
+		"search":      "squat",
+		"owner":       "shared",
+		"category":    []string{"strength"},
+		"muscleGroup": []string{"legs"},
+	}
+
+	var exercises []models.ExerciseLibrary
+	sql := repo.filterExercises(7, filters, "").Find(&exercises).Statement.SQL.String()
+
+	inner, outer, found := strings.Cut(sql, ") AS exercise_library")
+	if !found {
+		t.Fatalf("no ranked subquery: %s", sql)
+	}
+	for _, predicate := range []string{"search_normalize", "trainer_id <> ", "lower(category)", "unnest(exercise_library.muscle_groups)"} {
+		if !strings.Contains(inner, predicate) {
+			t.Errorf("%q is not applied before ranking: %s", predicate, sql)
+		}
+		if strings.Contains(outer, predicate) {
+			t.Errorf("%q is applied after ranking: %s", predicate, sql)
+		}
+	}
+	if !strings.Contains(inner, `"deleted_at" IS NULL`) {
+		t.Errorf("deleted exercises are ranked: %s", sql)
+	}
+	if !strings.Contains(outer, "name_rank = 1") {
+		t.Errorf("ranked rows are not reduced to one per name: %s", sql)
+	}
+}
+
+// Saving the whole row would overwrite reminders claimed concurrently
+func TestScheduleUpdateColumnsWritesOnlyThoseColumns(t *testing.T) {
+	db := dryRunDB(t)
+	var sql string
+	db.Callback().Update().After("gorm:update").Register("test:capture", func(tx *gorm.DB) {
+		sql = tx.Statement.SQL.String()
+	})
+
+	schedule := &models.Schedule{ID: 3, Title: "Leg day", Duration: 60, Status: "scheduled"}
+	if err := NewScheduleRepository(db).UpdateColumns(schedule, "title", "duration"); err != nil {
+		t.Fatal(err)
+	}
+
+	set, where, found := strings.Cut(sql, " WHERE ")
+	if !found {
+		t.Fatalf("no WHERE clause: %s", sql)
+	}
+	for _, column := range []string{`"title"=`, `"duration"=`, `"updated_at"=`} {
+		if !strings.Contains(set, column) {
+			t.Errorf("%s is not written: %s", column, sql)
+		}
+	}
+	for _, column := range []string{"reminder", `"status"`, `"date"`, `"notes"`} {
+		if strings.Contains(set, column) {
+			t.Errorf("%s is written: %s", column, sql)
+		}
+	}
+	if !strings.Contains(where, `"id" = $`) {
+		t.Errorf("update is not limited to the schedule: %s", sql)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"fitness-training-backend/internal/models"
 	"time"
 
+	"github.com/lib/pq"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ScheduleRepository handles schedule data access
@@ -16,12 +18,28 @@ type ScheduleRepository interface {
 	
 	// Write operations (Trainer only)
 	Create(schedule *models.Schedule) error
-	Update(schedule *models.Schedule) error
+	UpdateColumns(schedule *models.Schedule, columns ...string) error
 	Delete(id uint) error
 	
 	// Trainer operations
 	FindByTrainerID(trainerID uint, filters map[string]interface{}) ([]models.Schedule, error)
 	CheckConflict(trainerID uint, date time.Time, timeStr string, duration int, excludeID *uint) (bool, error)
+	
+	// Recurring series
+	FindBySeriesID(seriesID uint, fromDate *time.Time) ([]models.Schedule, error)
+	CountBySeriesBefore(seriesID uint, date time.Time) (int64, error)
+	MoveToSeries(fromSeriesID, toSeriesID uint, fromDate time.Time) error
+	
+	// Calendar import
+	ExistsByExternalUID(trainerID uint, uid string) (bool, error)
+	
+	// Program assignments
+	FindByAssignment(assignmentID uint) ([]models.Schedule, error)
+	CancelPendingByAssignment(assignmentID uint, fromDate time.Time, reason string, cancelledBy uint) (int64, error)
+	
+	// Reminders
+	FindPendingBetween(fromDate, toDate time.Time) ([]models.Schedule, error)
+	ClaimReminder(scheduleID uint, leadMinutes int, coveredLeads []int64, sentAt time.Time) (bool, error)
 }
 
 type scheduleRepository struct {
@@ -52,6 +70,7 @@ func (r *scheduleRepository) FindByTraineeID(traineeID uint, filters map[string]
 	query := r.db.
 		Preload("Trainer.User").
 		Preload("Location").
+		Preload("Series").
 		Where("trainee_id = ?", traineeID)
 	
 	// Apply filters
@@ -92,9 +111,10 @@ func (r *scheduleRepository) Create(schedule *models.Schedule) error {
 	return r.db.Create(schedule).Error
 }
 
-// Update updates schedule (Trainer only)
-func (r *scheduleRepository) Update(schedule *models.Schedule) error {
-	return r.db.Save(schedule).Error
+// UpdateColumns writes only the given columns of schedule, so that columns
+// changed concurrently (e.g. reminder claims) are not overwritten
+func (r *scheduleRepository) UpdateColumns(schedule *models.Schedule, columns ...string) error {
+	return r.db.Model(schedule).Omit(clause.Associations).Select(columns).Updates(schedule).Error
 }
 
 // Delete soft deletes schedule (Trainer only)
@@ -107,6 +127,7 @@ func (r *scheduleRepository) FindByTrainerID(trainerID uint, filters map[string]
 	query := r.db.
 		Preload("Trainee.User").
 		Preload("Location").
+		Preload("Series").
 		Where("trainer_id = ?", trainerID)
 	
 	// Apply filters
@@ -125,21 +146,130 @@ func (r *scheduleRepository) FindByTrainerID(trainerID uint, filters map[string]
 	return schedules, err
 }
 
-// CheckConflict checks if there's a scheduling conflict
+// CheckConflict checks if another active schedule of the trainer overlaps
+// the [date+time, date+time+duration) slot
 func (r *scheduleRepository) CheckConflict(trainerID uint, date time.Time, timeStr string, duration int, excludeID *uint) (bool, error) {
+	// Values read back from the TIME column carry seconds
+	clock, err := time.Parse("15:04", timeStr)
+	if err != nil {
+		if clock, err = time.Parse("15:04:05", timeStr); err != nil {
+			return false, err
+		}
+	}
+	
+	start := time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), 0, 0, time.UTC)
+	end := start.Add(time.Duration(duration) * time.Minute)
+	
+	// Sessions may run past midnight, so the previous day is checked as well
 	query := r.db.Model(&models.Schedule{}).
-		Where("trainer_id = ? AND date = ? AND status NOT IN ?", 
-			trainerID, date, []string{"cancelled", "completed"})
+		Where("trainer_id = ? AND status NOT IN ?", trainerID, []string{"cancelled", "completed", "no_show"}).
+		Where("date BETWEEN ? AND ?", start.AddDate(0, 0, -1).Format("2006-01-02"), end.Format("2006-01-02")).
+		Where("(date + time) < ?::timestamp AND (date + time + duration * INTERVAL '1 minute') > ?::timestamp",
+			end.Format("2006-01-02 15:04:05"), start.Format("2006-01-02 15:04:05"))
 	
 	if excludeID != nil {
 		query = query.Where("id != ?", *excludeID)
 	}
 	
 	var count int64
-	err := query.Count(&count).Error
+	err = query.Count(&count).Error
+	return count > 0, err
+}
+
+// FindBySeriesID finds the occurrences of a series, optionally from a date onwards
+func (r *scheduleRepository) FindBySeriesID(seriesID uint, fromDate *time.Time) ([]models.Schedule, error) {
+	query := r.db.
+		Preload("Trainee.User").
+		Preload("Location").
+		Where("series_id = ?", seriesID)
 	
-	// TODO: Implement proper time overlap check
-	// For now, just check if there's any session on the same day
+	if fromDate != nil {
+		query = query.Where("occurrence_date >= ?", *fromDate)
+	}
 	
+	var schedules []models.Schedule
+	err := query.Order("occurrence_date ASC").Find(&schedules).Error
+	return schedules, err
+}
+
+// CountBySeriesBefore counts the occurrences a series generated before a date
+func (r *scheduleRepository) CountBySeriesBefore(seriesID uint, date time.Time) (int64, error) {
+	var count int64
+	err := r.db.Unscoped().Model(&models.Schedule{}).
+		Where("series_id = ? AND occurrence_date < ?", seriesID, date).
+		Count(&count).Error
+	return count, err
+}
+
+// MoveToSeries reassigns occurrences from a date onwards to another series
+func (r *scheduleRepository) MoveToSeries(fromSeriesID, toSeriesID uint, fromDate time.Time) error {
+	return r.db.Model(&models.Schedule{}).
+		Where("series_id = ? AND occurrence_date >= ?", fromSeriesID, fromDate).
+		Update("series_id", toSeriesID).Error
+}
+
+// ExistsByExternalUID checks if an iCalendar event was already imported by the trainer
+func (r *scheduleRepository) ExistsByExternalUID(trainerID uint, uid string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Schedule{}).
+		Where("trainer_id = ? AND external_uid = ?", trainerID, uid).
+		Count(&count).Error
 	return count > 0, err
 }
+
+// FindByAssignment lists every session of a program assignment by date, with
+// the program day it was laid out from
+func (r *scheduleRepository) FindByAssignment(assignmentID uint) ([]models.Schedule, error) {
+	var schedules []models.Schedule
+	err := r.db.
+		Preload("ProgramDay").
+		Where("program_assignment_id = ?", assignmentID).
+		Order("date ASC, time ASC").
+		Find(&schedules).Error
+	return schedules, err
+}
+
+// CancelPendingByAssignment cancels the scheduled and confirmed sessions of
+// an assignment dated on or after fromDate
+func (r *scheduleRepository) CancelPendingByAssignment(assignmentID uint, fromDate time.Time, reason string, cancelledBy uint) (int64, error) {
+	result := r.db.Model(&models.Schedule{}).
+		Where("program_assignment_id = ? AND status IN ? AND date >= ?", assignmentID, []string{"scheduled", "confirmed"}, fromDate).
+		Updates(map[string]interface{}{
+			"status":              "cancelled",
+			"cancellation_reason": reason,
+			"cancelled_at":        time.Now(),
+			"cancelled_by":        cancelledBy,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// FindPendingBetween lists scheduled and confirmed sessions dated within the
+// range (inclusive), with what a reminder needs preloaded
+func (r *scheduleRepository) FindPendingBetween(fromDate, toDate time.Time) ([]models.Schedule, error) {
+	var schedules []models.Schedule
+	err := r.db.
+		Preload("Trainer.User").
+		Preload("Trainee.User").
+		Preload("Location").
+		Preload("Series").
+		Where("status IN ? AND date BETWEEN ? AND ?", []string{"scheduled", "confirmed"}, fromDate, toDate).
+		Order("date, time").
+		Find(&schedules).Error
+	return schedules, err
+}
+
+// ClaimReminder atomically marks the reminder for a lead time as sent,
+// together with coveredLeads (longer lead times that are no longer due). It
+// reports false when the session is no longer pending or another process
+// already claimed the reminder, so replicas never send it twice.
+func (r *scheduleRepository) ClaimReminder(scheduleID uint, leadMinutes int, coveredLeads []int64, sentAt time.Time) (bool, error) {
+	result := r.db.Model(&models.Schedule{}).
+		Where("id = ? AND status IN ?", scheduleID, []string{"scheduled", "confirmed"}).
+		Where("NOT (? = ANY(COALESCE(reminder_leads_sent, '{}')))", leadMinutes).
+		Updates(map[string]interface{}{
+			"reminder_sent":       true,
+			"reminder_sent_at":    sentAt,
+			"reminder_leads_sent": gorm.Expr("ARRAY(SELECT DISTINCT unnest(COALESCE(reminder_leads_sent, '{}') || ?::integer[]) ORDER BY 1)", pq.Int64Array(coveredLeads)),
+		})
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"fitness-training-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ScheduleSeriesRepository handles recurring schedule series data access
+type ScheduleSeriesRepository interface {
+	FindByID(id uint) (*models.ScheduleSeries, error)
+	FindByTrainerID(trainerID uint, status string) ([]models.ScheduleSeries, error)
+	Create(series *models.ScheduleSeries) error
+	Update(series *models.ScheduleSeries) error
+}
+
+type scheduleSeriesRepository struct {
+	db *gorm.DB
+}
+
+// NewScheduleSeriesRepository creates a new schedule series repository
+func NewScheduleSeriesRepository(db *gorm.DB) ScheduleSeriesRepository {
+	return &scheduleSeriesRepository{db: db}
+}
+
+// FindByID finds a series by ID with preloaded relations
+func (r *scheduleSeriesRepository) FindByID(id uint) (*models.ScheduleSeries, error) {
+	var series models.ScheduleSeries
+	err := r.db.
+		Preload("Trainer.User").
+		Preload("Trainee.User").
+		Preload("Location").
+		First(&series, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &series, nil
+}
+
+// FindByTrainerID finds the series of a trainer, optionally filtered by status
+func (r *scheduleSeriesRepository) FindByTrainerID(trainerID uint, status string) ([]models.ScheduleSeries, error) {
+	query := r.db.
+		Preload("Trainee.User").
+		Preload("Location").
+		Where("trainer_id = ?", trainerID)
+
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var series []models.ScheduleSeries
+	err := query.Order("start_date DESC").Find(&series).Error
+	return series, err
+}
+
+// Create creates a new series
+func (r *scheduleSeriesRepository) Create(series *models.ScheduleSeries) error {
+	return r.db.Create(series).Error
+}
+
+// Update updates a series
+func (r *scheduleSeriesRepository) Update(series *models.ScheduleSeries) error {
+	return r.db.Omit(clause.Associations).Save(series).Error
+}
@@ -2,9 +2,11 @@ package repository
 
 import (
 	"fitness-training-backend/internal/models"
+	"fitness-training-backend/pkg/stats"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // TraineeRepository handles trainee data access (READ-ONLY for trainee user)
@@ -12,6 +14,7 @@ type TraineeRepository interface {
 	// Read operations (for trainee)
 	FindByID(id uint) (*models.Trainee, error)
 	FindByUserID(userID uint) (*models.Trainee, error)
+	FindByMemberID(memberID string) (*models.Trainee, error)
 	
 	// Write operations (for trainer only)
 	Create(trainee *models.Trainee) error
@@ -20,6 +23,7 @@ type TraineeRepository interface {
 	
 	// Stats
 	GetStats(traineeID uint) (map[string]interface{}, error)
+	ComputeStats(traineeID uint) (*stats.Summary, error)
 	UpdateStats(traineeID uint) error
 }
 
@@ -52,6 +56,16 @@ func (r *traineeRepository) FindByUserID(userID uint) (*models.Trainee, error) {
 	return &trainee, nil
 }
 
+// FindByMemberID finds trainee by gym membership number
+func (r *traineeRepository) FindByMemberID(memberID string) (*models.Trainee, error) {
+	var trainee models.Trainee
+	err := r.db.Where("member_id = ?", memberID).First(&trainee).Error
+	if err != nil {
+		return nil, err
+	}
+	return &trainee, nil
+}
+
 // Create creates a new trainee (Trainer only)
 func (r *traineeRepository) Create(trainee *models.Trainee) error {
 	return r.db.Create(trainee).Error
@@ -59,7 +73,7 @@ func (r *traineeRepository) Create(trainee *models.Trainee) error {
 
 // Update updates trainee (Trainer only)
 func (r *traineeRepository) Update(trainee *models.Trainee) error {
-	return r.db.Save(trainee).Error
+	return r.db.Omit(clause.Associations).Save(trainee).Error
 }
 
 // Delete soft deletes trainee (Trainer only)
@@ -96,49 +110,82 @@ func (r *traineeRepository) GetStats(traineeID uint) (map[string]interface{}, er
 	return stats, nil
 }
 
-// UpdateStats updates trainee statistics (called after session completion)
+// UpdateStats recomputes the cached stat columns of a trainee. Call it in the
+// same transaction whenever a schedule becomes completed, cancelled or
+// no_show, or a session card changes.
 func (r *traineeRepository) UpdateStats(traineeID uint) error {
+	summary, err := r.ComputeStats(traineeID)
+	if err != nil {
+		return err
+	}
+
 	// Count total sessions
 	var totalSessions int64
-	r.db.Model(&models.SessionCard{}).Where("trainee_id = ?", traineeID).Count(&totalSessions)
-	
-	// Count completed sessions
-	var completedSessions int64
-	r.db.Model(&models.Schedule{}).
-		Where("trainee_id = ? AND status = ?", traineeID, "completed").
-		Count(&completedSessions)
-	
+	if err := r.db.Model(&models.SessionCard{}).Where("trainee_id = ?", traineeID).Count(&totalSessions).Error; err != nil {
+		return err
+	}
+
 	// Count cancelled sessions
 	var cancelledSessions int64
-	r.db.Model(&models.Schedule{}).
+	if err := r.db.Model(&models.Schedule{}).
 		Where("trainee_id = ? AND status = ?", traineeID, "cancelled").
-		Count(&cancelledSessions)
-	
-	// Get total workout hours
-	var totalMinutes int64
-	r.db.Model(&models.SessionCard{}).
-		Where("trainee_id = ?", traineeID).
-		Select("COALESCE(SUM(duration), 0)").
-		Scan(&totalMinutes)
-	
-	// Get last session date
-	var lastSessionDate *time.Time
-	r.db.Model(&models.SessionCard{}).
-		Where("trainee_id = ?", traineeID).
-		Select("MAX(date)").
-		Scan(&lastSessionDate)
-	
-	// Calculate streak (simplified - count consecutive days)
-	currentStreak := 0
-	// TODO: Implement streak calculation logic
-	
-	// Update trainee
+		Count(&cancelledSessions).Error; err != nil {
+		return err
+	}
+
 	return r.db.Model(&models.Trainee{}).Where("id = ?", traineeID).Updates(map[string]interface{}{
-		"total_sessions":      totalSessions,
-		"completed_sessions":  completedSessions,
-		"cancelled_sessions":  cancelledSessions,
-		"total_workout_hours": float32(totalMinutes) / 60.0,
-		"last_session_date":   lastSessionDate,
-		"current_streak":      currentStreak,
+		"total_sessions":            totalSessions,
+		"completed_sessions":        summary.TotalSessions,
+		"cancelled_sessions":        cancelledSessions,
+		"total_workout_hours":       summary.TotalHours(),
+		"last_session_date":         summary.LastSessionDate,
+		"current_streak":            summary.CurrentDailyStreak,
+		"longest_streak":            summary.LongestDailyStreak,
+		"current_weekly_streak":     summary.CurrentWeeklyStreak,
+		"longest_weekly_streak":     summary.LongestWeeklyStreak,
+		"average_sessions_per_week": summary.AverageSessionsPerWeek,
 	}).Error
 }
+
+// ComputeStats runs the stats engine on the trainee's completed sessions as
+// of today. The cached columns only change with sessions, so current streaks
+// read from them outlive a trainee who stopped training.
+func (r *traineeRepository) ComputeStats(traineeID uint) (*stats.Summary, error) {
+	var trainee models.Trainee
+	if err := r.db.Select("id", "weekly_streak_target").First(&trainee, traineeID).Error; err != nil {
+		return nil, err
+	}
+
+	sessions, err := r.completedSessions(traineeID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := stats.Compute(sessions, stats.Today(models.DefaultTimezone), stats.Options{
+		WeeklyTarget: trainee.WeeklyStreakTarget,
+	})
+	return &summary, nil
+}
+
+// completedSessions lists completed schedules with their actual duration:
+// the session card's when one was recorded, the planned one otherwise
+func (r *traineeRepository) completedSessions(traineeID uint) ([]stats.Session, error) {
+	var rows []struct {
+		Date    time.Time
+		Minutes int
+	}
+	err := r.db.Table("schedules").
+		Select("schedules.date AS date, COALESCE(session_cards.duration, schedules.duration) AS minutes").
+		Joins("LEFT JOIN session_cards ON session_cards.schedule_id = schedules.id AND session_cards.deleted_at IS NULL").
+		Where("schedules.trainee_id = ? AND schedules.status = ? AND schedules.deleted_at IS NULL", traineeID, "completed").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]stats.Session, 0, len(rows))
+	for _, row := range rows {
+		sessions = append(sessions, stats.Session{Date: row.Date, Minutes: row.Minutes})
+	}
+	return sessions, nil
+}
@@ -2,8 +2,10 @@ package repository
 
 import (
 	"fitness-training-backend/internal/models"
+	"fitness-training-backend/pkg/units"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // UserRepository handles user data access
@@ -12,9 +14,12 @@ type UserRepository interface {
 	FindByID(id uint) (*models.User, error)
 	FindByEmail(email string) (*models.User, error)
 	FindByOAuth(provider, oauthID string) (*models.User, error)
+	FindByLineUserID(lineUserID string) (*models.User, error)
 	Update(user *models.User) error
 	Delete(id uint) error
 	UpdateLastLogin(id uint) error
+	UpdateLineUserID(id uint, lineUserID *string) error
+	UpdateUnits(id uint, prefs units.Preferences) error
 	
 	// Preload relationships
 	FindByIDWithRelations(id uint) (*models.User, error)
@@ -64,9 +69,19 @@ func (r *userRepository) FindByOAuth(provider, oauthID string) (*models.User, er
 	return &user, nil
 }
 
+// FindByLineUserID finds the user a LINE account is linked to
+func (r *userRepository) FindByLineUserID(lineUserID string) (*models.User, error) {
+	var user models.User
+	err := r.db.Where("line_user_id = ?", lineUserID).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 // Update updates user
 func (r *userRepository) Update(user *models.User) error {
-	return r.db.Save(user).Error
+	return r.db.Omit(clause.Associations).Save(user).Error
 }
 
 // Delete soft deletes user
@@ -79,6 +94,20 @@ func (r *userRepository) UpdateLastLogin(id uint) error {
 	return r.db.Model(&models.User{}).Where("id = ?", id).Update("last_login_at", gorm.Expr("NOW()")).Error
 }
 
+// UpdateLineUserID links (or with nil, unlinks) a LINE account
+func (r *userRepository) UpdateLineUserID(id uint, lineUserID *string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Update("line_user_id", lineUserID).Error
+}
+
+// UpdateUnits saves a user's unit preferences
+func (r *userRepository) UpdateUnits(id uint, prefs units.Preferences) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"weight_unit":   string(prefs.Weight),
+		"distance_unit": string(prefs.Distance),
+		"length_unit":   string(prefs.Length),
+	}).Error
+}
+
 // FindByIDWithRelations finds user with trainer/trainee relations
 func (r *userRepository) FindByIDWithRelations(id uint) (*models.User, error) {
 	var user models.User
@@ -7,33 +7,67 @@ import (
 	"fitness-training-backend/internal/middleware"
 	"fitness-training-backend/internal/repository"
 	"fitness-training-backend/internal/service"
+	"fitness-training-backend/pkg/storage"
 
 	"github.com/gin-gonic/gin"
 )
 
 // SetupRoutes configures all API routes
-func SetupRoutes(router *gin.Engine, cfg *config.Config) {
+func SetupRoutes(router *gin.Engine, cfg *config.Config, mediaStore storage.Storage) {
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(database.DB)
 	trainerRepo := repository.NewTrainerRepository(database.DB)
 	traineeRepo := repository.NewTraineeRepository(database.DB)
 	scheduleRepo := repository.NewScheduleRepository(database.DB)
+	seriesRepo := repository.NewScheduleSeriesRepository(database.DB)
 	programRepo := repository.NewProgramRepository(database.DB)
 	sessionCardRepo := repository.NewSessionCardRepository(database.DB)
 	notificationRepo := repository.NewNotificationRepository(database.DB)
 	metricRepo := repository.NewMetricRepository(database.DB)
+	metricGoalRepo := repository.NewMetricGoalRepository(database.DB)
 	locationRepo := repository.NewLocationRepository(database.DB)
+	exerciseRepo := repository.NewExerciseRepository(database.DB)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(database.DB)
+	calendarFeedRepo := repository.NewCalendarFeedRepository(database.DB)
+	availabilityRepo := repository.NewAvailabilityRepository(database.DB)
+	bookingRepo := repository.NewBookingRequestRepository(database.DB)
+	recordRepo := repository.NewPersonalRecordRepository(database.DB)
+	achievementRepo := repository.NewAchievementRepository(database.DB)
+	achievementRuleRepo := repository.NewAchievementRuleRepository(database.DB)
+	pushSubscriptionRepo := repository.NewPushSubscriptionRepository(database.DB)
+	notificationSettingsRepo := repository.NewNotificationSettingsRepository(database.DB)
+	conversationRepo := repository.NewConversationRepository(database.DB)
+	messageRepo := repository.NewMessageRepository(database.DB)
+	programPlanRepo := repository.NewProgramPlanRepository(database.DB)
+	programVersionRepo := repository.NewProgramVersionRepository(database.DB)
+	mediaRepo := repository.NewMediaRepository(database.DB)
 	
 	// Initialize services
-	authService := service.NewAuthService(userRepo, cfg)
-	traineeService := service.NewTraineeService(traineeRepo, scheduleRepo, programRepo, sessionCardRepo, notificationRepo, metricRepo)
-	trainerService := service.NewTrainerService(trainerRepo, traineeRepo, scheduleRepo, programRepo, sessionCardRepo)
+	authService := service.NewAuthService(userRepo, refreshTokenRepo, cfg)
+	traineeService := service.NewTraineeService(traineeRepo, scheduleRepo, programRepo, sessionCardRepo, notificationRepo, metricRepo, metricGoalRepo, recordRepo, achievementRepo)
+	calendarService := service.NewCalendarService(calendarFeedRepo, userRepo, trainerRepo, traineeRepo, scheduleRepo, cfg)
+	trainerService := service.NewTrainerService(trainerRepo, traineeRepo, scheduleRepo, seriesRepo, programRepo, sessionCardRepo, metricRepo, metricGoalRepo, exerciseRepo, recordRepo)
+	programService := service.NewProgramService(trainerRepo, programRepo, programPlanRepo, programVersionRepo, exerciseRepo, scheduleRepo, recordRepo)
+	bookingService := service.NewBookingService(trainerRepo, traineeRepo, scheduleRepo, availabilityRepo, bookingRepo)
+	achievementService := service.NewAchievementService(trainerRepo, traineeRepo, exerciseRepo, achievementRepo, achievementRuleRepo)
+	messageService := service.NewMessageService(userRepo, trainerRepo, traineeRepo, scheduleRepo, sessionCardRepo, conversationRepo, messageRepo, mediaRepo, cfg)
+	notificationService := service.NewNotificationService(notificationRepo, userRepo, pushSubscriptionRepo, notificationSettingsRepo, cfg)
+	mediaService := service.NewMediaService(userRepo, locationRepo, mediaRepo, mediaStore, cfg)
+	workoutService := service.NewWorkoutService(traineeRepo, scheduleRepo, sessionCardRepo, exerciseRepo)
 	
 	// Initialize handlers
 	authHandler := handler.NewAuthHandler(authService, cfg)
 	traineeHandler := handler.NewTraineeHandler(traineeService)
 	trainerHandler := handler.NewTrainerHandler(trainerService)
+	programHandler := handler.NewProgramHandler(programService)
 	locationHandler := handler.NewLocationHandler(locationRepo)
+	calendarHandler := handler.NewCalendarHandler(calendarService)
+	bookingHandler := handler.NewBookingHandler(bookingService)
+	achievementHandler := handler.NewAchievementHandler(achievementService)
+	notificationHandler := handler.NewNotificationHandler(notificationService, cfg)
+	messageHandler := handler.NewMessageHandler(messageService)
+	mediaHandler := handler.NewMediaHandler(mediaService, mediaStore, cfg)
+	workoutHandler := handler.NewWorkoutHandler(workoutService)
 	
 	// API v1 routes
 	v1 := router.Group("/api/v1")
@@ -50,6 +84,9 @@ func SetupRoutes(router *gin.Engine, cfg *config.Config) {
 			auth.GET("/google/login", authHandler.GoogleLogin)
 			auth.GET("/google/callback", authHandler.GoogleCallback)
 			auth.POST("/refresh", authHandler.RefreshToken)
+			auth.GET("/sessions", middleware.AuthMiddleware(cfg), authHandler.GetSessions)
+			auth.DELETE("/sessions", middleware.AuthMiddleware(cfg), authHandler.RevokeOtherSessions)
+			auth.DELETE("/sessions/:id", middleware.AuthMiddleware(cfg), authHandler.RevokeSession)
 		}
 		
 		// ==========================================
@@ -64,6 +101,11 @@ func SetupRoutes(router *gin.Engine, cfg *config.Config) {
 			trainee.GET("/schedules", traineeHandler.GetSchedules)
 			trainee.GET("/schedules/:id", traineeHandler.GetScheduleDetail)
 			
+			// Booking Requests
+			trainee.GET("/booking-requests", bookingHandler.GetTraineeBookingRequests)
+			trainee.POST("/booking-requests", bookingHandler.CreateBookingRequest)
+			trainee.DELETE("/booking-requests/:id", bookingHandler.CancelBookingRequest)
+			
 			// Programs
 			trainee.GET("/programs/current", traineeHandler.GetCurrentProgram)
 			trainee.GET("/programs", traineeHandler.GetPrograms)
@@ -71,6 +113,7 @@ func SetupRoutes(router *gin.Engine, cfg *config.Config) {
 			
 			// Stats
 			trainee.GET("/stats", traineeHandler.GetStats)
+			trainee.GET("/achievements", achievementHandler.GetAchievements)
 			
 			// Notifications
 			trainee.GET("/notifications", traineeHandler.GetNotifications)
@@ -82,8 +125,13 @@ func SetupRoutes(router *gin.Engine, cfg *config.Config) {
 			trainee.GET("/sessions/:id", traineeHandler.GetSessionDetail)
 			trainee.GET("/sessions/search", traineeHandler.SearchSessions)
 			
+			// Workout Import (watch and bike computer activities)
+			trainee.POST("/workouts/import", workoutHandler.ImportWorkout)
+			
 			// Metrics
 			trainee.GET("/metrics", traineeHandler.GetMetrics)
+			trainee.GET("/metrics/analytics", traineeHandler.GetMetricAnalytics)
+			trainee.GET("/records", traineeHandler.GetRecords)
 			
 			// Profile
 			trainee.GET("/me", traineeHandler.GetProfile)
@@ -106,14 +154,39 @@ func SetupRoutes(router *gin.Engine, cfg *config.Config) {
 			trainer.PATCH("/clients/:id", trainerHandler.UpdateClient)
 			trainer.DELETE("/clients/:id", trainerHandler.RemoveClient)
 			trainer.GET("/clients/:id/metrics", trainerHandler.GetClientMetrics)
+			trainer.POST("/clients/:id/metrics", trainerHandler.AddClientMetric)
+			trainer.GET("/clients/:id/metrics/analytics", trainerHandler.GetClientMetricAnalytics)
+			trainer.PUT("/clients/:id/metric-goals", trainerHandler.SetClientMetricGoal)
+			trainer.DELETE("/clients/:id/metric-goals/:goalId", trainerHandler.DeleteClientMetricGoal)
+			trainer.POST("/metric-imports/preview", trainerHandler.PreviewMetricImport)
+			trainer.POST("/metric-imports", trainerHandler.CommitMetricImport)
 			trainer.GET("/clients/:id/sessions", trainerHandler.GetClientSessions)
+			trainer.GET("/clients/:id/records", trainerHandler.GetClientRecords)
+			trainer.GET("/clients/:id/achievements", achievementHandler.GetClientAchievements)
 			
 			// Schedules Management
 			trainer.GET("/schedules", trainerHandler.GetSchedules)
 			trainer.GET("/schedules/:id", trainerHandler.GetScheduleDetail)
 			trainer.POST("/schedules", trainerHandler.CreateSchedule)
+			trainer.POST("/schedules/import", calendarHandler.ImportSchedules)
 			trainer.PATCH("/schedules/:id", trainerHandler.UpdateSchedule)
 			trainer.DELETE("/schedules/:id", trainerHandler.CancelSchedule)
+			trainer.GET("/schedules/:id/suggestions", programHandler.GetSessionSuggestions)
+			
+			// Recurring Schedules
+			trainer.GET("/schedule-series", trainerHandler.GetScheduleSeries)
+			trainer.GET("/schedule-series/:id", trainerHandler.GetScheduleSeriesDetail)
+			trainer.POST("/schedule-series", trainerHandler.CreateScheduleSeries)
+			
+			// Availability & Booking Requests
+			trainer.GET("/availability", bookingHandler.GetAvailability)
+			trainer.PUT("/availability", bookingHandler.UpdateAvailability)
+			trainer.GET("/time-off", bookingHandler.GetTimeOff)
+			trainer.POST("/time-off", bookingHandler.CreateTimeOff)
+			trainer.DELETE("/time-off/:id", bookingHandler.DeleteTimeOff)
+			trainer.GET("/booking-requests", bookingHandler.GetTrainerBookingRequests)
+			trainer.POST("/booking-requests/:id/confirm", bookingHandler.ConfirmBookingRequest)
+			trainer.POST("/booking-requests/:id/decline", bookingHandler.DeclineBookingRequest)
 			
 			// Session Cards Management
 			trainer.GET("/sessions", trainerHandler.GetSessions)
@@ -129,6 +202,34 @@ func SetupRoutes(router *gin.Engine, cfg *config.Config) {
 			trainer.PATCH("/programs/:id", trainerHandler.UpdateProgram)
 			trainer.DELETE("/programs/:id", trainerHandler.DeleteProgram)
 			trainer.POST("/programs/:id/assign", trainerHandler.AssignProgram)
+			trainer.POST("/programs/:id/clone", programHandler.CloneProgram)
+			
+			// Program Plans
+			trainer.GET("/programs/:id/weeks", programHandler.GetPlan)
+			trainer.GET("/programs/:id/weeks/:week/days", programHandler.GetWeekDays)
+			trainer.POST("/programs/:id/weeks/:week/days", programHandler.CreateDay)
+			trainer.GET("/programs/:id/weeks/:week/days/:day", programHandler.GetDay)
+			trainer.PUT("/programs/:id/weeks/:week/days/:day", programHandler.UpdateDay)
+			trainer.DELETE("/programs/:id/weeks/:week/days/:day", programHandler.DeleteDay)
+			trainer.POST("/programs/:id/weeks/:week/copy", programHandler.CopyWeek)
+			
+			// Program Versions
+			trainer.GET("/programs/:id/versions", programHandler.GetVersions)
+			trainer.GET("/programs/:id/versions/:version", programHandler.GetVersion)
+			trainer.GET("/programs/:id/versions/:version/diff", programHandler.DiffVersions)
+			trainer.POST("/programs/:id/versions/:version/migrate", programHandler.MigrateAssignments)
+			
+			// Program Assignments & Progress Notes
+			trainer.GET("/assignments", programHandler.GetAssignments)
+			trainer.GET("/assignments/:id/notes", programHandler.GetProgressNotes)
+			trainer.POST("/assignments/:id/notes", programHandler.CreateProgressNote)
+			trainer.DELETE("/assignments/:id/notes/:noteId", programHandler.DeleteProgressNote)
+			
+			// Achievement Rules
+			trainer.GET("/achievement-rules", achievementHandler.GetRules)
+			trainer.POST("/achievement-rules", achievementHandler.CreateRule)
+			trainer.PATCH("/achievement-rules/:id", achievementHandler.UpdateRule)
+			trainer.DELETE("/achievement-rules/:id", achievementHandler.DeleteRule)
 			
 			// Exercise Library
 			trainer.GET("/exercises", trainerHandler.GetExercises)
@@ -141,6 +242,69 @@ func SetupRoutes(router *gin.Engine, cfg *config.Config) {
 			trainer.GET("/analytics/clients/:id", trainerHandler.GetClientAnalytics)
 		}
 		
+		// ==========================================
+		// Current User Routes (any role)
+		// ==========================================
+		me := v1.Group("/me")
+		me.Use(middleware.AuthMiddleware(cfg))
+		{
+			// Calendar subscription
+			me.GET("/calendar-feed", calendarHandler.GetFeed)
+			me.POST("/calendar-feed/rotate", calendarHandler.RotateFeed)
+			me.DELETE("/calendar-feed", calendarHandler.DeleteFeed)
+			
+			// Notification channels & preferences
+			me.GET("/notification-channels", notificationHandler.GetChannels)
+			me.GET("/notification-preferences", notificationHandler.GetPreferences)
+			me.PUT("/notification-preferences", notificationHandler.UpdatePreferences)
+			me.POST("/push-subscriptions", notificationHandler.SubscribePush)
+			me.DELETE("/push-subscriptions", notificationHandler.UnsubscribePush)
+			me.PUT("/line-account", notificationHandler.LinkLINEAccount)
+			me.DELETE("/line-account", notificationHandler.UnlinkLINEAccount)
+			
+			// Units
+			me.GET("/units", authHandler.GetUnits)
+			me.PUT("/units", authHandler.UpdateUnits)
+		}
+		
+		// ==========================================
+		// Conversations (trainer <-> trainee)
+		// ==========================================
+		conversations := v1.Group("/conversations")
+		conversations.Use(middleware.AuthMiddleware(cfg))
+		conversations.Use(middleware.RoleMiddleware("trainer", "trainee"))
+		{
+			conversations.GET("", messageHandler.GetConversations)
+			conversations.POST("", messageHandler.StartConversation)
+			conversations.GET("/:id", messageHandler.GetConversation)
+			conversations.GET("/:id/messages", messageHandler.GetMessages)
+			conversations.POST("/:id/messages", messageHandler.SendMessage)
+			conversations.POST("/:id/read", messageHandler.MarkAsRead)
+		}
+		
+		// ==========================================
+		// Media (any role)
+		// ==========================================
+		media := v1.Group("/media")
+		media.Use(middleware.AuthMiddleware(cfg))
+		{
+			media.POST("", mediaHandler.Upload)
+			media.GET("/:id", mediaHandler.Download)
+		}
+		
+		// Signed download URLs of local media storage (no login)
+		v1.GET("/files/*key", mediaHandler.ServeFile)
+		
+		// ==========================================
+		// Notification Stream (SSE, any role)
+		// ==========================================
+		v1.GET("/notifications/stream", middleware.AuthMiddleware(cfg), notificationHandler.Stream)
+		
+		// ==========================================
+		// Calendar Feeds (token in URL, no login)
+		// ==========================================
+		v1.GET("/calendar/feeds/:token", calendarHandler.Feed)
+		
 		// ==========================================
 		// Shared/Common Routes
 		// ==========================================
@@ -154,9 +318,14 @@ func SetupRoutes(router *gin.Engine, cfg *config.Config) {
 			// Trainers (Public browsing)
 			common.GET("/trainers", trainerHandler.GetTrainers)
 			common.GET("/trainers/:id", trainerHandler.GetTrainerDetail)
+			common.GET("/trainers/:id/slots", bookingHandler.GetOpenSlots)
 			
 			// Exercise Categories
 			common.GET("/exercises/categories", trainerHandler.GetExerciseCategories)
+			
+			// Program Catalog (public, plus gym-wide for trainers)
+			common.GET("/programs", programHandler.GetCatalog)
+			common.GET("/programs/:id", programHandler.GetCatalogProgram)
 		}
 	}
 }
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"fmt"
+
+	"fitness-training-backend/internal/database"
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+	apperrors "fitness-training-backend/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxAchievements        = 200
+	recentAchievementCount = 5
+)
+
+// AchievementService handles earned achievements and trainer-defined rules
+type AchievementService interface {
+	// Earned achievements
+	GetAchievements(userID uint) ([]dto.AchievementResponse, error)
+	GetClientAchievements(userID, traineeID uint) ([]dto.AchievementResponse, error)
+
+	// Rules (trainer)
+	GetRules(userID uint) ([]dto.AchievementRuleResponse, error)
+	CreateRule(userID uint, req *dto.CreateAchievementRuleRequest) (*dto.AchievementRuleResponse, error)
+	UpdateRule(userID, ruleID uint, req *dto.UpdateAchievementRuleRequest) (*dto.AchievementRuleResponse, error)
+	DeleteRule(userID, ruleID uint) error
+}
+
+type achievementService struct {
+	trainerRepo     repository.TrainerRepository
+	traineeRepo     repository.TraineeRepository
+	exerciseRepo    repository.ExerciseRepository
+	achievementRepo repository.AchievementRepository
+	ruleRepo        repository.AchievementRuleRepository
+}
+
+// NewAchievementService creates a new achievement service
+func NewAchievementService(
+	trainerRepo repository.TrainerRepository,
+	traineeRepo repository.TraineeRepository,
+	exerciseRepo repository.ExerciseRepository,
+	achievementRepo repository.AchievementRepository,
+	ruleRepo repository.AchievementRuleRepository,
+) AchievementService {
+	return &achievementService{
+		trainerRepo:     trainerRepo,
+		traineeRepo:     traineeRepo,
+		exerciseRepo:    exerciseRepo,
+		achievementRepo: achievementRepo,
+		ruleRepo:        ruleRepo,
+	}
+}
+
+// ==========================================
+// EARNED ACHIEVEMENTS
+// ==========================================
+
+func (s *achievementService) GetAchievements(userID uint) ([]dto.AchievementResponse, error) {
+	trainee, err := s.traineeRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	achievements, err := s.achievementRepo.FindByTraineeID(trainee.ID, maxAchievements)
+	if err != nil {
+		return nil, err
+	}
+	return toAchievementResponses(achievements), nil
+}
+
+func (s *achievementService) GetClientAchievements(userID, traineeID uint) ([]dto.AchievementResponse, error) {
+	trainer, err := s.trainerRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if _, err := s.getClient(trainer, traineeID); err != nil {
+		return nil, err
+	}
+
+	achievements, err := s.achievementRepo.FindByTraineeID(traineeID, maxAchievements)
+	if err != nil {
+		return nil, err
+	}
+	return toAchievementResponses(achievements), nil
+}
+
+// ==========================================
+// RULES
+// ==========================================
+
+// GetRules lists the built-in rules followed by the trainer's own
+func (s *achievementService) GetRules(userID uint) ([]dto.AchievementRuleResponse, error) {
+	trainer, err := s.trainerRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	rules, err := s.ruleRepo.FindByTrainerID(trainer.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]dto.AchievementRuleResponse, 0, len(builtInAchievementRules)+len(rules))
+	for _, rule := range builtInAchievementRules {
+		resp = append(resp, toBuiltInRuleResponse(rule))
+	}
+	for i := range rules {
+		resp = append(resp, toAchievementRuleResponse(&rules[i]))
+	}
+	return resp, nil
+}
+
+// CreateRule defines a custom rule and awards it right away to clients who
+// already qualify
+func (s *achievementService) CreateRule(userID uint, req *dto.CreateAchievementRuleRequest) (*dto.AchievementRuleResponse, error) {
+	trainer, err := s.trainerRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	if req.TraineeID != nil {
+		if _, err := s.getClient(trainer, *req.TraineeID); err != nil {
+			return nil, err
+		}
+	}
+	if req.ExerciseLibraryID != nil {
+		if req.Metric != metricPRCount {
+			return nil, fmt.Errorf("%w: exerciseLibraryId only applies to the pr_count metric", apperrors.ErrInvalidInput)
+		}
+		exercise, err := s.exerciseRepo.FindByID(*req.ExerciseLibraryID)
+		if err != nil {
+			return nil, notFound(err)
+		}
+		if exercise.TrainerID != nil && *exercise.TrainerID != trainer.ID {
+			return nil, apperrors.ErrForbidden
+		}
+	}
+
+	rule := &models.AchievementRule{
+		TrainerID:         trainer.ID,
+		TraineeID:         req.TraineeID,
+		Title:             req.Title,
+		Description:       req.Description,
+		Metric:            req.Metric,
+		Threshold:         req.Threshold,
+		ExerciseLibraryID: req.ExerciseLibraryID,
+		BadgeIcon:         req.BadgeIcon,
+		BadgeColor:        req.BadgeColor,
+		IsActive:          true,
+	}
+
+	err = database.Transaction(func(tx *gorm.DB) error {
+		if err := repository.NewAchievementRuleRepository(tx).Create(rule); err != nil {
+			return err
+		}
+		return s.evaluateRuleClients(tx, trainer, rule)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toAchievementRuleResponse(rule)
+	return &resp, nil
+}
+
+func (s *achievementService) UpdateRule(userID, ruleID uint, req *dto.UpdateAchievementRuleRequest) (*dto.AchievementRuleResponse, error) {
+	trainer, rule, err := s.getRule(userID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Title != nil {
+		rule.Title = *req.Title
+	}
+	if req.Description != nil {
+		rule.Description = req.Description
+	}
+	if req.Threshold != nil {
+		rule.Threshold = *req.Threshold
+	}
+	if req.BadgeIcon != nil {
+		rule.BadgeIcon = req.BadgeIcon
+	}
+	if req.BadgeColor != nil {
+		rule.BadgeColor = req.BadgeColor
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	err = database.Transaction(func(tx *gorm.DB) error {
+		if err := repository.NewAchievementRuleRepository(tx).Update(rule); err != nil {
+			return err
+		}
+		if !rule.IsActive {
+			return nil
+		}
+		return s.evaluateRuleClients(tx, trainer, rule)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toAchievementRuleResponse(rule)
+	return &resp, nil
+}
+
+// DeleteRule removes a custom rule; achievements already earned are kept
+func (s *achievementService) DeleteRule(userID, ruleID uint) error {
+	_, rule, err := s.getRule(userID, ruleID)
+	if err != nil {
+		return err
+	}
+	return s.ruleRepo.Delete(rule.ID)
+}
+
+// evaluateRuleClients runs the rules engine for the clients a rule applies to
+func (s *achievementService) evaluateRuleClients(tx *gorm.DB, trainer *models.Trainer, rule *models.AchievementRule) error {
+	if rule.TraineeID != nil {
+		return evaluateAchievements(tx, *rule.TraineeID)
+	}
+
+	clients, err := repository.NewTrainerRepository(tx).GetClients(trainer.ID)
+	if err != nil {
+		return err
+	}
+	for _, client := range clients {
+		if err := evaluateAchievements(tx, client.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getRule loads a custom rule owned by the trainer
+func (s *achievementService) getRule(userID, ruleID uint) (*models.Trainer, *models.AchievementRule, error) {
+	trainer, err := s.trainerRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, nil, notFound(err)
+	}
+
+	rule, err := s.ruleRepo.FindByID(ruleID)
+	if err != nil {
+		return nil, nil, notFound(err)
+	}
+	if rule.TrainerID != trainer.ID {
+		return nil, nil, apperrors.ErrNotFound
+	}
+	return trainer, rule, nil
+}
+
+// getClient loads a trainee assigned to the trainer
+func (s *achievementService) getClient(trainer *models.Trainer, traineeID uint) (*models.Trainee, error) {
+	trainee, err := s.traineeRepo.FindByID(traineeID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if trainee.TrainerID == nil || *trainee.TrainerID != trainer.ID {
+		return nil, apperrors.ErrClientNotAssigned
+	}
+	return trainee, nil
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+	"fitness-training-backend/pkg/units"
+
+	"gorm.io/gorm"
+)
+
+// Achievement rule metrics
+const (
+	metricCompletedSessions = "completed_sessions"
+	metricDailyStreak       = "daily_streak"  // longest run of days, see stats.Compute
+	metricWeeklyStreak      = "weekly_streak" // longest run of ISO weeks meeting the target
+	metricTotalHours        = "total_hours"
+	metricProgramsCompleted = "programs_completed"
+	metricPRCount           = "pr_count"
+	metricWeightLost        = "weight_lost" // kg below the weight at join date
+)
+
+// achievementRule is a declarative rule: the trainee earns the badge once the
+// metric reaches the threshold. Key identifies the rule on the achievement so
+// it is awarded only once.
+type achievementRule struct {
+	Key         string
+	Type        string // 'streak', 'milestone', 'pr', 'completion'
+	Title       string
+	Description string
+	BadgeIcon   string
+	BadgeColor  string
+
+	Metric    string
+	Threshold float64
+
+	// pr_count filters
+	ExerciseLibraryID *uint
+	MinMuscleGroups   int // compound lifts work two or more muscle groups
+}
+
+// builtInAchievementRules apply to every trainee
+var builtInAchievementRules = []achievementRule{
+	{Key: "sessions_1", Type: "milestone", Title: "First Session", Description: "Completed your first training session", BadgeIcon: "🎉", BadgeColor: "#10B981", Metric: metricCompletedSessions, Threshold: 1},
+	{Key: "sessions_10", Type: "milestone", Title: "10 Sessions", Description: "Completed 10 training sessions", BadgeIcon: "💪", BadgeColor: "#10B981", Metric: metricCompletedSessions, Threshold: 10},
+	{Key: "sessions_25", Type: "milestone", Title: "25 Sessions", Description: "Completed 25 training sessions", BadgeIcon: "🥉", BadgeColor: "#B45309", Metric: metricCompletedSessions, Threshold: 25},
+	{Key: "sessions_50", Type: "milestone", Title: "50 Sessions", Description: "Completed 50 training sessions", BadgeIcon: "🥈", BadgeColor: "#6B7280", Metric: metricCompletedSessions, Threshold: 50},
+	{Key: "sessions_100", Type: "milestone", Title: "100 Sessions", Description: "Completed 100 training sessions", BadgeIcon: "🥇", BadgeColor: "#F59E0B", Metric: metricCompletedSessions, Threshold: 100},
+	{Key: "daily_streak_7", Type: "streak", Title: "7-Day Streak", Description: "Trained 7 days in a row", BadgeIcon: "🔥", BadgeColor: "#EF4444", Metric: metricDailyStreak, Threshold: 7},
+	{Key: "weekly_streak_4", Type: "streak", Title: "4-Week Streak", Description: "Hit your weekly target 4 weeks in a row", BadgeIcon: "📅", BadgeColor: "#3B82F6", Metric: metricWeeklyStreak, Threshold: 4},
+	{Key: "weekly_streak_12", Type: "streak", Title: "12-Week Streak", Description: "Hit your weekly target 12 weeks in a row", BadgeIcon: "🗓️", BadgeColor: "#6366F1", Metric: metricWeeklyStreak, Threshold: 12},
+	{Key: "program_completed_1", Type: "completion", Title: "Program Completed", Description: "Finished every session of a training program", BadgeIcon: "🏁", BadgeColor: "#8B5CF6", Metric: metricProgramsCompleted, Threshold: 1},
+	{Key: "first_pr", Type: "pr", Title: "First PR", Description: "Set your first personal record", BadgeIcon: "⭐", BadgeColor: "#F59E0B", Metric: metricPRCount, Threshold: 1},
+	{Key: "first_compound_pr", Type: "pr", Title: "Compound Lift PR", Description: "Set your first PR on a compound lift", BadgeIcon: "🏋️", BadgeColor: "#F59E0B", Metric: metricPRCount, Threshold: 1, MinMuscleGroups: 2},
+	{Key: "weight_lost_5", Type: "milestone", Title: "5 kg Down", Description: "Lost 5 kg since joining", BadgeIcon: "⚖️", BadgeColor: "#14B8A6", Metric: metricWeightLost, Threshold: 5},
+}
+
+// customAchievementRule turns a trainer-defined rule into a declarative rule
+func customAchievementRule(rule *models.AchievementRule) achievementRule {
+	r := achievementRule{
+		Key:               fmt.Sprintf("custom_%d", rule.ID),
+		Type:              achievementTypeForMetric(rule.Metric),
+		Title:             rule.Title,
+		BadgeIcon:         "🏅",
+		BadgeColor:        "#F59E0B",
+		Metric:            rule.Metric,
+		Threshold:         float64(rule.Threshold),
+		ExerciseLibraryID: rule.ExerciseLibraryID,
+	}
+	if rule.Description != nil {
+		r.Description = *rule.Description
+	}
+	if rule.BadgeIcon != nil {
+		r.BadgeIcon = *rule.BadgeIcon
+	}
+	if rule.BadgeColor != nil {
+		r.BadgeColor = *rule.BadgeColor
+	}
+	return r
+}
+
+func achievementTypeForMetric(metric string) string {
+	switch metric {
+	case metricDailyStreak, metricWeeklyStreak:
+		return "streak"
+	case metricProgramsCompleted:
+		return "completion"
+	case metricPRCount:
+		return "pr"
+	default:
+		return "milestone"
+	}
+}
+
+// evaluateAchievements awards every rule the trainee now satisfies and has not
+// been awarded yet, notifying the trainee and their trainer. Run it after the
+// cached stats were refreshed, inside the same transaction as the event.
+func evaluateAchievements(tx *gorm.DB, traineeID uint) error {
+	trainee, err := repository.NewTraineeRepository(tx).FindByID(traineeID)
+	if err != nil {
+		return err
+	}
+
+	rules := append([]achievementRule{}, builtInAchievementRules...)
+	if trainee.TrainerID != nil {
+		custom, err := repository.NewAchievementRuleRepository(tx).FindActiveForTrainee(*trainee.TrainerID, trainee.ID)
+		if err != nil {
+			return err
+		}
+		for i := range custom {
+			rules = append(rules, customAchievementRule(&custom[i]))
+		}
+	}
+
+	facts := &achievementFacts{
+		programRepo: repository.NewProgramRepository(tx),
+		recordRepo:  repository.NewPersonalRecordRepository(tx),
+		metricRepo:  repository.NewMetricRepository(tx),
+		trainee:     trainee,
+		cache:       map[string]float64{},
+	}
+	return awardEarnedAchievements(repository.NewAchievementRepository(tx), repository.NewNotificationRepository(tx), facts, rules)
+}
+
+// awardEarnedAchievements awards the rules the trainee of facts reaches and
+// was not awarded before
+func awardEarnedAchievements(achievementRepo repository.AchievementRepository, notificationRepo repository.NotificationRepository, facts *achievementFacts, rules []achievementRule) error {
+	keys, err := achievementRepo.FindRuleKeys(facts.trainee.ID)
+	if err != nil {
+		return err
+	}
+	awarded := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		awarded[key] = true
+	}
+
+	for _, rule := range rules {
+		if awarded[rule.Key] {
+			continue
+		}
+		value, err := facts.value(rule)
+		if err != nil {
+			return err
+		}
+		if value < rule.Threshold {
+			continue
+		}
+		if err := awardAchievement(achievementRepo, notificationRepo, facts.trainee, rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func awardAchievement(achievementRepo repository.AchievementRepository, notificationRepo repository.NotificationRepository, trainee *models.Trainee, rule achievementRule) error {
+	key := rule.Key
+	value := int(math.Round(rule.Threshold))
+	achievement := &models.Achievement{
+		TraineeID:  trainee.ID,
+		RuleKey:    &key,
+		Type:       rule.Type,
+		Title:      rule.Title,
+		BadgeIcon:  &rule.BadgeIcon,
+		BadgeColor: &rule.BadgeColor,
+		Value:      &value,
+		AchievedAt: time.Now(),
+	}
+	if rule.Description != "" {
+		achievement.Description = &rule.Description
+	}
+
+	created, err := achievementRepo.CreateOnce(achievement)
+	if err != nil || !created {
+		return err
+	}
+
+	message := rule.Description
+	if message == "" {
+		message = "You earned a new badge"
+	}
+	if err := notificationRepo.Create(achievementNotification(trainee.UserID, achievement, rule.BadgeIcon+" "+rule.Title, message)); err != nil {
+		return err
+	}
+
+	if trainee.Trainer != nil {
+		message := fmt.Sprintf("%s earned \"%s\"", trainee.User.Name, rule.Title)
+		return notificationRepo.Create(achievementNotification(trainee.Trainer.UserID, achievement, "Client achievement", message))
+	}
+	return nil
+}
+
+func achievementNotification(userID uint, achievement *models.Achievement, title, message string) *models.Notification {
+	relatedType := "achievement"
+	return &models.Notification{
+		UserID:      userID,
+		Type:        "achievement",
+		Title:       strings.TrimSpace(title),
+		Message:     message,
+		RelatedID:   &achievement.ID,
+		RelatedType: &relatedType,
+		Priority:    "medium",
+	}
+}
+
+// achievementFacts resolves metric values for one trainee, querying each
+// fact at most once per evaluation
+type achievementFacts struct {
+	programRepo repository.ProgramRepository
+	recordRepo  repository.PersonalRecordRepository
+	metricRepo  repository.MetricRepository
+	trainee     *models.Trainee
+	cache       map[string]float64
+}
+
+func (f *achievementFacts) value(rule achievementRule) (float64, error) {
+	switch rule.Metric {
+	case metricCompletedSessions:
+		return float64(f.trainee.CompletedSessions), nil
+	case metricDailyStreak:
+		return float64(f.trainee.LongestStreak), nil
+	case metricWeeklyStreak:
+		return float64(f.trainee.LongestWeeklyStreak), nil
+	case metricTotalHours:
+		return float64(f.trainee.TotalWorkoutHours), nil
+	}
+
+	cacheKey := rule.Metric
+	if rule.Metric == metricPRCount {
+		cacheKey = fmt.Sprintf("%s:%d:%d", rule.Metric, derefUint(rule.ExerciseLibraryID), rule.MinMuscleGroups)
+	}
+	if value, ok := f.cache[cacheKey]; ok {
+		return value, nil
+	}
+
+	var value float64
+	switch rule.Metric {
+	case metricProgramsCompleted:
+		count, err := f.programRepo.CountCompletedAssignments(f.trainee.ID)
+		if err != nil {
+			return 0, err
+		}
+		value = float64(count)
+	case metricPRCount:
+		count, err := f.recordRepo.CountPRs(f.trainee.ID, rule.ExerciseLibraryID, rule.MinMuscleGroups)
+		if err != nil {
+			return 0, err
+		}
+		value = float64(count)
+	case metricWeightLost:
+		weightType := "weight"
+		metrics, err := f.metricRepo.FindByTraineeID(f.trainee.ID, &weightType)
+		if err != nil {
+			return 0, err
+		}
+		value = weightLostSince(metrics, f.trainee.JoinDate)
+	default:
+		return 0, fmt.Errorf("unknown achievement metric %q", rule.Metric)
+	}
+
+	f.cache[cacheKey] = value
+	return value, nil
+}
+
+// weightLostSince compares the latest weigh-in with the baseline: the last
+// weigh-in on or before the join date, or the first one after it. Result is
+// in kg; weight gain counts as zero.
+func weightLostSince(metrics []models.Metric, joinDate time.Time) float64 {
+	if len(metrics) < 2 {
+		return 0
+	}
+
+	sorted := append([]models.Metric{}, metrics...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+
+	baseline := sorted[0]
+	for _, metric := range sorted {
+		if metric.Date.After(truncateDate(joinDate)) {
+			break
+		}
+		baseline = metric
+	}
+	latest := sorted[len(sorted)-1]
+
+	lost := metricInCanonical(baseline) - metricInCanonical(latest)
+	if lost < 0 {
+		return 0
+	}
+	return lost
+}
+
+// metricInCanonical converts a metric to the canonical unit of its quantity;
+// values in an unknown unit are left as they are
+func metricInCanonical(metric models.Metric) float64 {
+	unit, _ := units.Parse(metric.Unit)
+	return units.ToCanonical(float64(metric.Value), unit)
+}
+
+func derefUint(value *uint) uint {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+)
+
+// fakeAchievements holds the rule keys a trainee was awarded; keys in raced
+// are inserted by a concurrent evaluation first
+type fakeAchievements struct {
+	repository.AchievementRepository
+	keys    []string
+	raced   map[string]bool
+	created []*models.Achievement
+}
+
+func (f *fakeAchievements) FindRuleKeys(traineeID uint) ([]string, error) {
+	return f.keys, nil
+}
+
+func (f *fakeAchievements) CreateOnce(achievement *models.Achievement) (bool, error) {
+	if f.raced[*achievement.RuleKey] {
+		return false, nil
+	}
+	achievement.ID = uint(len(f.created) + 1)
+	f.created = append(f.created, achievement)
+	return true, nil
+}
+
+type fakeNotifications struct {
+	repository.NotificationRepository
+	sent []*models.Notification
+}
+
+func (f *fakeNotifications) Create(notification *models.Notification) error {
+	f.sent = append(f.sent, notification)
+	return nil
+}
+
+type fakeCompletedPrograms struct {
+	repository.ProgramRepository
+	completed int64
+}
+
+func (f *fakeCompletedPrograms) CountCompletedAssignments(traineeID uint) (int64, error) {
+	return f.completed, nil
+}
+
+// fakePRCounts counts PRs by minimum muscle groups and the queries made
+type fakePRCounts struct {
+	repository.PersonalRecordRepository
+	prs     map[int]int64
+	queries int
+}
+
+func (f *fakePRCounts) CountPRs(traineeID uint, exerciseLibraryID *uint, minMuscleGroups int) (int64, error) {
+	f.queries++
+	return f.prs[minMuscleGroups], nil
+}
+
+type fakeWeighIns struct {
+	repository.MetricRepository
+	metrics []models.Metric
+}
+
+func (f *fakeWeighIns) FindByTraineeID(traineeID uint, metricType *string) ([]models.Metric, error) {
+	return f.metrics, nil
+}
+
+func weighIn(month time.Month, day int, value float32, unit string) models.Metric {
+	return models.Metric{Date: time.Date(2024, month, day, 0, 0, 0, 0, time.UTC), Type: metricTypeWeight, Value: value, Unit: unit}
+}
+
+func newAchievementFacts(trainee *models.Trainee, prs *fakePRCounts, weighIns ...models.Metric) *achievementFacts {
+	return &achievementFacts{
+		programRepo: &fakeCompletedPrograms{},
+		recordRepo:  prs,
+		metricRepo:  &fakeWeighIns{metrics: weighIns},
+		trainee:     trainee,
+		cache:       map[string]float64{},
+	}
+}
+
+func awardedKeys(achievements []*models.Achievement) string {
+	keys := make([]string, 0, len(achievements))
+	for _, achievement := range achievements {
+		keys = append(keys, *achievement.RuleKey)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, " ")
+}
+
+func achievementTestTrainee() *models.Trainee {
+	return &models.Trainee{
+		ID:                  5,
+		UserID:              50,
+		JoinDate:            time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		CompletedSessions:   10,
+		LongestStreak:       3,
+		LongestWeeklyStreak: 4,
+		TotalWorkoutHours:   25,
+		User:                models.User{Name: "Alex"},
+		Trainer:             &models.Trainer{ID: 1, UserID: 20},
+	}
+}
+
+func TestAwardEarnedAchievements(t *testing.T) {
+	trainee := achievementTestTrainee()
+	prs := &fakePRCounts{prs: map[int]int64{0: 2, 2: 0}}
+	achievements := &fakeAchievements{keys: []string{"sessions_1"}}
+	notifications := &fakeNotifications{}
+
+	badge := "💯"
+	rules := append([]achievementRule{}, builtInAchievementRules...)
+	rules = append(rules,
+		customAchievementRule(&models.AchievementRule{ID: 3, Title: "20 Hours", Metric: metricTotalHours, Threshold: 20, BadgeIcon: &badge}),
+		customAchievementRule(&models.AchievementRule{ID: 4, Title: "30 Hours", Metric: metricTotalHours, Threshold: 30}),
+	)
+
+	if err := awardEarnedAchievements(achievements, notifications,
+		newAchievementFacts(trainee, prs, weighIn(2, 28, 82, "kg"), weighIn(4, 1, 76.5, "kg")), rules); err != nil {
+		t.Fatal(err)
+	}
+
+	// sessions_1 was awarded before; streaks, programs, compound PRs and
+	// 30 hours are not reached
+	if got, want := awardedKeys(achievements.created), "custom_3 first_pr sessions_10 weekly_streak_4 weight_lost_5"; got != want {
+		t.Errorf("awarded %s, want %s", got, want)
+	}
+	// One query per PR filter: all PRs and compound lifts
+	if prs.queries != 2 {
+		t.Errorf("PR counts queried %d times, want 2", prs.queries)
+	}
+
+	for _, achievement := range achievements.created {
+		if *achievement.RuleKey == "custom_3" &&
+			(achievement.Type != "milestone" || *achievement.BadgeIcon != badge || *achievement.BadgeColor != "#F59E0B" || *achievement.Value != 20) {
+			t.Errorf("custom achievement = %+v, want a milestone with the trainer's badge", achievement)
+		}
+	}
+
+	// The trainee and their trainer hear about each badge
+	if len(notifications.sent) != 2*len(achievements.created) {
+		t.Fatalf("sent %d notifications for %d achievements", len(notifications.sent), len(achievements.created))
+	}
+	for i, achievement := range achievements.created {
+		client, trainer := notifications.sent[2*i], notifications.sent[2*i+1]
+		if client.UserID != 50 || trainer.UserID != 20 || *client.RelatedID != achievement.ID || *trainer.RelatedID != achievement.ID {
+			t.Errorf("notifications %+v and %+v do not go to the client and trainer about achievement %d", client, trainer, achievement.ID)
+		}
+		if client.Type != "achievement" || !strings.HasSuffix(client.Title, achievement.Title) {
+			t.Errorf("client notification %q, want the badge title %q", client.Title, achievement.Title)
+		}
+		if want := fmt.Sprintf("Alex earned \"%s\"", achievement.Title); trainer.Message != want {
+			t.Errorf("trainer notification %q, want %q", trainer.Message, want)
+		}
+	}
+}
+
+func TestAwardEarnedAchievementsNotifications(t *testing.T) {
+	rule := achievementRule{Key: "sessions_10", Type: "milestone", Title: "10 Sessions", BadgeIcon: "💪", Metric: metricCompletedSessions, Threshold: 10}
+
+	tests := []struct {
+		name       string
+		noTrainer  bool
+		raced      bool
+		wantUsers  string
+		wantAwards int
+	}{
+		{name: "client and trainer", wantUsers: "50 20", wantAwards: 1},
+		{name: "without a trainer", noTrainer: true, wantUsers: "50", wantAwards: 1},
+		// Another evaluation inserted it first and notified
+		{name: "awarded concurrently", raced: true, wantUsers: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trainee := achievementTestTrainee()
+			if tt.noTrainer {
+				trainee.Trainer = nil
+			}
+			achievements := &fakeAchievements{raced: map[string]bool{"sessions_10": tt.raced}}
+			notifications := &fakeNotifications{}
+
+			err := awardEarnedAchievements(achievements, notifications, newAchievementFacts(trainee, &fakePRCounts{}), []achievementRule{rule})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			users := make([]string, 0, len(notifications.sent))
+			for _, notification := range notifications.sent {
+				users = append(users, fmt.Sprint(notification.UserID))
+			}
+			if got := strings.Join(users, " "); got != tt.wantUsers || len(achievements.created) != tt.wantAwards {
+				t.Errorf("%d awarded, notified %q; want %d, %q", len(achievements.created), got, tt.wantAwards, tt.wantUsers)
+			}
+			// Rules without a description still say something
+			if len(notifications.sent) > 0 {
+				if client := notifications.sent[0]; client.Message != "You earned a new badge" || client.Title != "💪 10 Sessions" {
+					t.Errorf("client notification %q: %q", client.Title, client.Message)
+				}
+			}
+		})
+	}
+}
+
+func TestWeightLostSince(t *testing.T) {
+	joined := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		metrics []models.Metric
+		want    float64
+	}{
+		{"no weigh-ins", nil, 0},
+		{"a single weigh-in", []models.Metric{weighIn(3, 1, 80, "kg")}, 0},
+		{"from the last weigh-in before joining", []models.Metric{
+			weighIn(1, 10, 90, "kg"), weighIn(2, 20, 84, "kg"), weighIn(4, 1, 79, "kg")}, 5},
+		{"on the join date", []models.Metric{
+			weighIn(2, 20, 90, "kg"), weighIn(3, 1, 84, "kg"), weighIn(4, 1, 79, "kg")}, 5},
+		{"from the first weigh-in after joining", []models.Metric{
+			weighIn(3, 5, 84, "kg"), weighIn(3, 20, 82, "kg"), weighIn(4, 1, 80, "kg")}, 4},
+		{"unsorted", []models.Metric{
+			weighIn(4, 1, 80, "kg"), weighIn(3, 5, 84, "kg"), weighIn(3, 20, 82, "kg")}, 4},
+		{"weight gained", []models.Metric{weighIn(3, 1, 80, "kg"), weighIn(4, 1, 83, "kg")}, 0},
+		// Rows recorded before values were stored in kg
+		{"in pounds", []models.Metric{weighIn(3, 1, 200, "lbs"), weighIn(4, 1, 88, "kg")}, 2.72},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := weightLostSince(tt.metrics, joined); !closeTo(float32(got), float32(tt.want)) {
+				t.Errorf("weightLostSince = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCustomAchievementRule(t *testing.T) {
+	description, color := "Ten hours of cardio", "#000000"
+
+	tests := []struct {
+		rule     models.AchievementRule
+		wantType string
+	}{
+		{models.AchievementRule{ID: 1, Metric: metricCompletedSessions}, "milestone"},
+		{models.AchievementRule{ID: 2, Metric: metricDailyStreak}, "streak"},
+		{models.AchievementRule{ID: 3, Metric: metricWeeklyStreak}, "streak"},
+		{models.AchievementRule{ID: 4, Metric: metricProgramsCompleted}, "completion"},
+		{models.AchievementRule{ID: 5, Metric: metricPRCount}, "pr"},
+		{models.AchievementRule{ID: 6, Metric: metricTotalHours, Description: &description, BadgeColor: &color}, "milestone"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule.Metric, func(t *testing.T) {
+			rule := customAchievementRule(&tt.rule)
+			if rule.Type != tt.wantType || rule.Key != fmt.Sprintf("custom_%d", tt.rule.ID) {
+				t.Errorf("rule %s of type %s, want custom_%d of type %s", rule.Key, rule.Type, tt.rule.ID, tt.wantType)
+			}
+			if tt.rule.Description != nil && (rule.Description != description || rule.BadgeColor != color || rule.BadgeIcon != "🏅") {
+				t.Errorf("rule = %+v, want the trainer's description and colour with the default icon", rule)
+			}
+		})
+	}
+}
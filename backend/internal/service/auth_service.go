@@ -0,0 +1,569 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"fitness-training-backend/internal/config"
+	"fitness-training-backend/internal/database"
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+	apperrors "fitness-training-backend/pkg/errors"
+	"fitness-training-backend/pkg/units"
+	"fitness-training-backend/pkg/utils"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/endpoints"
+	"gorm.io/gorm"
+)
+
+const googleUserInfoURL = "https://www.googleapis.com/oauth2/v2/userinfo"
+
+// Refresh token revocation reasons
+const (
+	revokeReasonRotated   = "rotated"
+	revokeReasonLogout    = "logout"
+	revokeReasonSignedOut = "signed_out"
+	revokeReasonReuse     = "reuse_detected"
+)
+
+// rotationGracePeriod is how long after a rotation the old refresh token is
+// refused without being treated as reuse: a client that sent the same token
+// twice at once (e.g. from two tabs) gets one new pair and an auth error,
+// and the new pair stays valid
+const rotationGracePeriod = 10 * time.Second
+
+// ClientInfo describes the device a token pair is issued to
+type ClientInfo struct {
+	DeviceType *string
+	DeviceName *string
+	IPAddress  *string
+	UserAgent  *string
+}
+
+// AuthService handles authentication business logic
+type AuthService interface {
+	Register(req *dto.RegisterRequest, client ClientInfo) (*dto.LoginResponse, error)
+	Login(req *dto.LoginRequest, client ClientInfo) (*dto.LoginResponse, error)
+	RefreshToken(refreshToken string, client ClientInfo) (*dto.LoginResponse, error)
+	Logout(refreshToken string) error
+	GetCurrentUser(userID uint) (*dto.UserInfo, error)
+
+	// Sessions (signed-in devices)
+	GetSessions(userID uint, currentSessionID string) ([]dto.SessionResponse, error)
+	RevokeSession(userID uint, sessionID string) error
+	RevokeOtherSessions(userID uint, currentSessionID string) error
+
+	// Units metrics and exercise sets are shown and entered in
+	GetUnits(userID uint) (*dto.UnitPreferences, error)
+	UpdateUnits(userID uint, req *dto.UpdateUnitPreferencesRequest) (*dto.UnitPreferences, error)
+
+	// Google OAuth
+	GoogleLoginURL(state string) string
+	GoogleCallback(ctx context.Context, code string, client ClientInfo) (*dto.GoogleCallbackResponse, error)
+}
+
+type authService struct {
+	userRepo         repository.UserRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	cfg              *config.Config
+	oauthConfig      *oauth2.Config
+}
+
+// NewAuthService creates a new auth service
+func NewAuthService(userRepo repository.UserRepository, refreshTokenRepo repository.RefreshTokenRepository, cfg *config.Config) AuthService {
+	return &authService{
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		cfg:              cfg,
+		oauthConfig: &oauth2.Config{
+			ClientID:     cfg.Google.ClientID,
+			ClientSecret: cfg.Google.ClientSecret,
+			RedirectURL:  cfg.Google.RedirectURL,
+			Scopes:       []string{"openid", "email", "profile"},
+			Endpoint:     endpoints.Google,
+		},
+	}
+}
+
+// Register creates a user together with its trainer/trainee profile
+func (s *authService) Register(req *dto.RegisterRequest, client ClientInfo) (*dto.LoginResponse, error) {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	if _, err := s.userRepo.FindByEmail(email); err == nil {
+		return nil, apperrors.ErrEmailAlreadyExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if err := utils.ValidatePassword(req.Password); err != nil {
+		return nil, err
+	}
+
+	hash, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		Email:        email,
+		PasswordHash: &hash,
+		Name:         req.Name,
+		Role:         req.Role,
+		PhoneNumber:  req.PhoneNumber,
+		DateOfBirth:  req.DateOfBirth,
+		Gender:       req.Gender,
+		IsActive:     true,
+	}
+
+	if err := createUserWithProfile(user); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(user, "", client)
+}
+
+// Login authenticates a user with email and password
+func (s *authService) Login(req *dto.LoginRequest, client ClientInfo) (*dto.LoginResponse, error) {
+	user, err := s.userRepo.FindByEmail(strings.ToLower(strings.TrimSpace(req.Email)))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	if user.PasswordHash == nil || !utils.CheckPassword(req.Password, *user.PasswordHash) {
+		return nil, apperrors.ErrInvalidCredentials
+	}
+
+	if !user.IsActive {
+		return nil, apperrors.ErrForbidden
+	}
+
+	if err := s.userRepo.UpdateLastLogin(user.ID); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(user, "", client)
+}
+
+// RefreshToken rotates a refresh token: the presented token is revoked and a
+// new pair is issued in the same family. Presenting a token that was already
+// rotated means it leaked, so the whole family is revoked.
+func (s *authService) RefreshToken(refreshToken string, client ClientInfo) (*dto.LoginResponse, error) {
+	stored, err := s.refreshTokenRepo.FindByHash(utils.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	if stored.IsRevoked {
+		if stored.RevokedReason != nil && *stored.RevokedReason == revokeReasonRotated &&
+			(stored.RevokedAt == nil || time.Since(*stored.RevokedAt) >= rotationGracePeriod) {
+			return nil, s.revokeReusedFamily(stored)
+		}
+		return nil, apperrors.ErrInvalidToken
+	}
+
+	if stored.IsExpired() {
+		return nil, apperrors.ErrExpiredToken
+	}
+
+	user, err := s.userRepo.FindByID(stored.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	if !user.IsActive {
+		return nil, apperrors.ErrForbidden
+	}
+
+	var resp *dto.LoginResponse
+	err = database.Transaction(func(tx *gorm.DB) error {
+		tokenRepo := repository.NewRefreshTokenRepository(tx)
+
+		next, token, err := s.newRefreshToken(tokenRepo, user.ID, stored.FamilyID, client)
+		if err != nil {
+			return err
+		}
+
+		rotated, err := tokenRepo.RevokeIfActive(stored.ID, revokeReasonRotated, &next.ID)
+		if err != nil {
+			return err
+		}
+		if !rotated {
+			// A concurrent request rotated the same token first; its new
+			// pair stays valid
+			return apperrors.ErrInvalidToken
+		}
+
+		resp, err = s.buildLoginResponse(user, stored.FamilyID, token)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// revokeReusedFamily revokes every token of a family after reuse was detected
+func (s *authService) revokeReusedFamily(token *models.RefreshToken) error {
+	log.Printf("⚠️  Refresh token reuse detected for user %d (family %s), revoking session", token.UserID, token.FamilyID)
+
+	if err := s.refreshTokenRepo.RevokeFamily(token.UserID, token.FamilyID, revokeReasonReuse); err != nil {
+		return err
+	}
+	return apperrors.ErrTokenReused
+}
+
+// Logout revokes the session the refresh token belongs to
+func (s *authService) Logout(refreshToken string) error {
+	if refreshToken == "" {
+		return nil
+	}
+
+	stored, err := s.refreshTokenRepo.FindByHash(utils.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	return s.refreshTokenRepo.RevokeFamily(stored.UserID, stored.FamilyID, revokeReasonLogout)
+}
+
+// GetSessions lists the devices the user is signed in on. A session is a
+// token family, identified by its family ID, which stays the same while its
+// refresh tokens rotate.
+func (s *authService) GetSessions(userID uint, currentSessionID string) ([]dto.SessionResponse, error) {
+	tokens, err := s.refreshTokenRepo.FindActiveByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]dto.SessionResponse, 0, len(tokens))
+	seen := map[string]bool{}
+	for _, token := range tokens {
+		// Newest first: the token a family last rotated to
+		if seen[token.FamilyID] {
+			continue
+		}
+		seen[token.FamilyID] = true
+
+		sessions = append(sessions, dto.SessionResponse{
+			ID:           token.FamilyID,
+			DeviceType:   token.DeviceType,
+			DeviceName:   token.DeviceName,
+			IPAddress:    token.IPAddress,
+			UserAgent:    token.UserAgent,
+			LastActiveAt: token.CreatedAt,
+			ExpiresAt:    token.ExpiresAt,
+			IsCurrent:    token.FamilyID == currentSessionID,
+		})
+	}
+	return sessions, nil
+}
+
+// RevokeSession signs out the device of one of the user's sessions, by the
+// family ID GetSessions returns
+func (s *authService) RevokeSession(userID uint, sessionID string) error {
+	tokens, err := s.refreshTokenRepo.FindActiveByUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		if token.FamilyID == sessionID {
+			return s.refreshTokenRepo.RevokeFamily(userID, token.FamilyID, revokeReasonSignedOut)
+		}
+	}
+	return apperrors.ErrNotFound
+}
+
+// RevokeOtherSessions signs out every device except the current one
+func (s *authService) RevokeOtherSessions(userID uint, currentSessionID string) error {
+	return s.refreshTokenRepo.RevokeAllForUser(userID, currentSessionID, revokeReasonSignedOut)
+}
+
+// GetCurrentUser returns the authenticated user with role profile
+func (s *authService) GetCurrentUser(userID uint) (*dto.UserInfo, error) {
+	user, err := s.userRepo.FindByIDWithRelations(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return toUserInfo(user), nil
+}
+
+// GetUnits returns the units the user reads and enters values in
+func (s *authService) GetUnits(userID uint) (*dto.UnitPreferences, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.ErrUserNotFound
+		}
+		return nil, err
+	}
+	prefs := toUnitPreferences(user.Units())
+	return &prefs, nil
+}
+
+// UpdateUnits applies the requested system, then the units given one by one
+func (s *authService) UpdateUnits(userID uint, req *dto.UpdateUnitPreferencesRequest) (*dto.UnitPreferences, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	prefs := user.Units()
+	if req.System != nil {
+		prefs = units.Metric()
+		if *req.System == "imperial" {
+			prefs = units.Imperial()
+		}
+	}
+	if req.Weight != nil {
+		prefs.Weight = units.Unit(*req.Weight)
+	}
+	if req.Distance != nil {
+		prefs.Distance = units.Unit(*req.Distance)
+	}
+	if req.Length != nil {
+		prefs.Length = units.Unit(*req.Length)
+	}
+	if err := prefs.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", apperrors.ErrInvalidInput, err)
+	}
+
+	if err := s.userRepo.UpdateUnits(userID, prefs); err != nil {
+		return nil, err
+	}
+	resp := toUnitPreferences(prefs)
+	return &resp, nil
+}
+
+// GoogleLoginURL returns the Google consent page URL
+func (s *authService) GoogleLoginURL(state string) string {
+	return s.oauthConfig.AuthCodeURL(state, oauth2.AccessTypeOffline)
+}
+
+// GoogleCallback exchanges the authorization code and signs the user in,
+// creating a trainee account on first login
+func (s *authService) GoogleCallback(ctx context.Context, code string, client ClientInfo) (*dto.GoogleCallbackResponse, error) {
+	token, err := s.oauthConfig.Exchange(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to exchange code", apperrors.ErrUnauthorized)
+	}
+
+	profile, err := s.fetchGoogleProfile(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := "google"
+	isNewUser := false
+
+	user, err := s.userRepo.FindByOAuth(provider, profile.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Linking by email or signing up trusts Google's word for the
+		// address; an unverified one could be someone else's
+		if !profile.VerifiedEmail {
+			return nil, fmt.Errorf("%w: the Google account's email address is not verified", apperrors.ErrUnauthorized)
+		}
+		user, err = s.userRepo.FindByEmail(strings.ToLower(profile.Email))
+	}
+
+	switch {
+	case err == nil:
+		// Link the Google account to an existing user
+		user.OAuthProvider = &provider
+		user.OAuthID = &profile.ID
+		user.OAuthAccessToken = &token.AccessToken
+		if token.RefreshToken != "" {
+			user.OAuthRefreshToken = &token.RefreshToken
+		}
+		user.OAuthTokenExpiry = &token.Expiry
+		if user.ProfileImage == nil && profile.Picture != "" {
+			user.ProfileImage = &profile.Picture
+		}
+		if err := s.userRepo.Update(user); err != nil {
+			return nil, err
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		isNewUser = true
+		user = &models.User{
+			Email:            strings.ToLower(profile.Email),
+			Name:             profile.Name,
+			Role:             "trainee",
+			OAuthProvider:    &provider,
+			OAuthID:          &profile.ID,
+			OAuthAccessToken: &token.AccessToken,
+			OAuthTokenExpiry: &token.Expiry,
+			EmailVerified:    true,
+			IsActive:         true,
+		}
+		if token.RefreshToken != "" {
+			user.OAuthRefreshToken = &token.RefreshToken
+		}
+		if profile.Picture != "" {
+			user.ProfileImage = &profile.Picture
+		}
+		if err := createUserWithProfile(user); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	if !user.IsActive {
+		return nil, apperrors.ErrForbidden
+	}
+
+	if err := s.userRepo.UpdateLastLogin(user.ID); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.issueTokens(user, "", client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.GoogleCallbackResponse{
+		AccessToken:  resp.AccessToken,
+		RefreshToken: resp.RefreshToken,
+		User:         resp.User,
+		IsNewUser:    isNewUser,
+	}, nil
+}
+
+type googleProfile struct {
+	ID            string `json:"id"`
+	Email         string `json:"email"`
+	VerifiedEmail bool   `json:"verified_email"`
+	Name          string `json:"name"`
+	Picture       string `json:"picture"`
+}
+
+func (s *authService) fetchGoogleProfile(ctx context.Context, token *oauth2.Token) (*googleProfile, error) {
+	client := s.oauthConfig.Client(ctx, token)
+
+	resp, err := client.Get(googleUserInfoURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch google profile: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: google profile request returned %d", apperrors.ErrUnauthorized, resp.StatusCode)
+	}
+
+	var profile googleProfile
+	if err := json.NewDecoder(resp.Body).Decode(&profile); err != nil {
+		return nil, fmt.Errorf("failed to decode google profile: %w", err)
+	}
+	if profile.ID == "" || profile.Email == "" {
+		return nil, fmt.Errorf("%w: google profile is missing id or email", apperrors.ErrUnauthorized)
+	}
+
+	return &profile, nil
+}
+
+// issueTokens stores a new refresh token and signs the matching access token.
+// An empty familyID starts a new login session.
+func (s *authService) issueTokens(user *models.User, familyID string, client ClientInfo) (*dto.LoginResponse, error) {
+	if familyID == "" {
+		id, err := utils.GenerateSecureToken(16)
+		if err != nil {
+			return nil, err
+		}
+		familyID = id
+	}
+
+	_, token, err := s.newRefreshToken(s.refreshTokenRepo, user.ID, familyID, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.buildLoginResponse(user, familyID, token)
+}
+
+// newRefreshToken generates an opaque refresh token and persists its hash
+func (s *authService) newRefreshToken(tokenRepo repository.RefreshTokenRepository, userID uint, familyID string, client ClientInfo) (*models.RefreshToken, string, error) {
+	token, err := utils.GenerateSecureToken(utils.RefreshTokenBytes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	stored := &models.RefreshToken{
+		UserID:     userID,
+		Token:      utils.HashToken(token),
+		FamilyID:   familyID,
+		ExpiresAt:  time.Now().Add(s.cfg.JWT.RefreshTokenExpiry),
+		DeviceType: client.DeviceType,
+		DeviceName: client.DeviceName,
+		IPAddress:  client.IPAddress,
+		UserAgent:  client.UserAgent,
+	}
+	if err := tokenRepo.Create(stored); err != nil {
+		return nil, "", err
+	}
+
+	return stored, token, nil
+}
+
+// buildLoginResponse signs the access token and loads the user profile
+func (s *authService) buildLoginResponse(user *models.User, familyID, refreshToken string) (*dto.LoginResponse, error) {
+	accessToken, err := utils.GenerateAccessToken(user.ID, user.Email, user.Role, familyID, s.cfg.JWT.Secret, s.cfg.JWT.AccessTokenExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reload with role profile for the response
+	full, err := s.userRepo.FindByIDWithRelations(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.LoginResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		User:         toUserInfo(full),
+	}, nil
+}
+
+// createUserWithProfile inserts the user and its role profile in one transaction
+func createUserWithProfile(user *models.User) error {
+	return database.Transaction(func(tx *gorm.DB) error {
+		if err := repository.NewUserRepository(tx).Create(user); err != nil {
+			return err
+		}
+
+		switch user.Role {
+		case "trainer":
+			return repository.NewTrainerRepository(tx).Create(&models.Trainer{UserID: user.ID})
+		case "trainee":
+			return repository.NewTraineeRepository(tx).Create(&models.Trainee{UserID: user.ID, Status: "active"})
+		}
+		return nil
+	})
+}
@@ -0,0 +1,335 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"fitness-training-backend/internal/config"
+	"fitness-training-backend/internal/database"
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+	apperrors "fitness-training-backend/pkg/errors"
+	"fitness-training-backend/pkg/utils"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fakeRefreshTokens keeps the active tokens of a user for session listing
+type fakeRefreshTokens struct {
+	repository.RefreshTokenRepository
+	active  []models.RefreshToken
+	byHash  map[string]*models.RefreshToken
+	revoked []string
+}
+
+func (f *fakeRefreshTokens) FindByHash(hash string) (*models.RefreshToken, error) {
+	if token, ok := f.byHash[hash]; ok {
+		return token, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeRefreshTokens) FindActiveByUserID(userID uint) ([]models.RefreshToken, error) {
+	tokens := make([]models.RefreshToken, 0, len(f.active))
+	for _, token := range f.active {
+		if token.UserID == userID {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+func (f *fakeRefreshTokens) RevokeFamily(userID uint, familyID, reason string) error {
+	f.revoked = append(f.revoked, familyID)
+	return nil
+}
+
+func TestSessionsAreTokenFamilies(t *testing.T) {
+	now := time.Now()
+	tokens := &fakeRefreshTokens{active: []models.RefreshToken{
+		// Newest first, as the repository returns them; family "phone"
+		// has a stale second active token
+		{ID: 12, UserID: 1, FamilyID: "phone", CreatedAt: now},
+		{ID: 11, UserID: 1, FamilyID: "laptop", CreatedAt: now.Add(-time.Hour)},
+		{ID: 10, UserID: 1, FamilyID: "phone", CreatedAt: now.Add(-2 * time.Hour)},
+		{ID: 9, UserID: 2, FamilyID: "other-user", CreatedAt: now},
+	}}
+	s := &authService{refreshTokenRepo: tokens}
+
+	sessions, err := s.GetSessions(1, "laptop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("got %d sessions, want one per family: %+v", len(sessions), sessions)
+	}
+	if sessions[0].ID != "phone" || !sessions[0].LastActiveAt.Equal(now) || sessions[0].IsCurrent {
+		t.Errorf("first session = %+v, want the phone's newest token", sessions[0])
+	}
+	if sessions[1].ID != "laptop" || !sessions[1].IsCurrent {
+		t.Errorf("second session = %+v, want the current laptop", sessions[1])
+	}
+
+	if err := s.RevokeSession(1, "phone"); err != nil {
+		t.Fatalf("RevokeSession: %v", err)
+	}
+	if len(tokens.revoked) != 1 || tokens.revoked[0] != "phone" {
+		t.Errorf("revoked %v, want the phone family", tokens.revoked)
+	}
+
+	for _, id := range []string{"12", "other-user", ""} {
+		if err := s.RevokeSession(1, id); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("RevokeSession(%q) = %v, want not found", id, err)
+		}
+	}
+}
+
+func TestRefreshWithRotatedToken(t *testing.T) {
+	rotated := revokeReasonRotated
+	tests := []struct {
+		name        string
+		rotatedAgo  time.Duration
+		want        error
+		wantRevoked bool
+	}{
+		// A request that raced the rotation, e.g. from a second tab
+		{"just rotated", time.Second, apperrors.ErrInvalidToken, false},
+		{"at the end of the grace period", rotationGracePeriod, apperrors.ErrTokenReused, true},
+		{"rotated long ago", time.Hour, apperrors.ErrTokenReused, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revokedAt := time.Now().Add(-tt.rotatedAgo)
+			tokens := &fakeRefreshTokens{byHash: map[string]*models.RefreshToken{
+				utils.HashToken("old"): {
+					UserID: 1, FamilyID: "phone", IsRevoked: true,
+					RevokedAt: &revokedAt, RevokedReason: &rotated,
+				},
+			}}
+			s := &authService{refreshTokenRepo: tokens}
+
+			_, err := s.RefreshToken("old", ClientInfo{})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("RefreshToken = %v, want %v", err, tt.want)
+			}
+			if revoked := len(tokens.revoked) > 0; revoked != tt.wantRevoked {
+				t.Errorf("family revoked = %v, want %v", revoked, tt.wantRevoked)
+			}
+		})
+	}
+}
+
+// openAuthTestDB points the database package at a private schema of the
+// PostgreSQL database in TEST_DATABASE_DSN, dropped when the test ends
+func openAuthTestDB(t *testing.T) {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+	gormConfig := &gorm.Config{
+		Logger:                                   logger.Default.LogMode(logger.Silent),
+		DisableForeignKeyConstraintWhenMigrating: true,
+	}
+
+	admin, err := gorm.Open(postgres.Open(dsn), gormConfig)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	schema := fmt.Sprintf("auth_test_%d", time.Now().UnixNano())
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+
+	scoped := dsn + " search_path=" + schema
+	if strings.Contains(dsn, "://") {
+		separator := "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+		scoped = dsn + separator + "search_path=" + schema
+	}
+	db, err := gorm.Open(postgres.Open(scoped), gormConfig)
+	if err != nil {
+		t.Fatalf("open schema: %v", err)
+	}
+
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() {
+		database.DB = previous
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	if err := db.AutoMigrate(&models.User{}, &models.Trainer{}, &models.Trainee{}, &models.RefreshToken{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+}
+
+// newTestAuthService signs a user in and returns the service and the first
+// refresh token
+func newTestAuthService(t *testing.T) (*authService, *models.User, string) {
+	t.Helper()
+	openAuthTestDB(t)
+
+	user := &models.User{Email: "client@example.com", Name: "Client", Role: "trainee", IsActive: true}
+	if err := database.DB.Create(user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	s := NewAuthService(
+		repository.NewUserRepository(database.DB),
+		repository.NewRefreshTokenRepository(database.DB),
+		&config.Config{JWT: config.JWTConfig{Secret: "test", AccessTokenExpiry: time.Minute, RefreshTokenExpiry: time.Hour}},
+	).(*authService)
+
+	resp, err := s.issueTokens(user, "", ClientInfo{})
+	if err != nil {
+		t.Fatalf("issue tokens: %v", err)
+	}
+	return s, user, resp.RefreshToken
+}
+
+func familyTokens(t *testing.T, userID uint) []models.RefreshToken {
+	t.Helper()
+	var tokens []models.RefreshToken
+	if err := database.DB.Where("user_id = ?", userID).Order("id").Find(&tokens).Error; err != nil {
+		t.Fatal(err)
+	}
+	return tokens
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	s, user, first := newTestAuthService(t)
+
+	resp, err := s.RefreshToken(first, ClientInfo{})
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if resp.RefreshToken == first {
+		t.Fatal("refresh returned the same token")
+	}
+
+	tokens := familyTokens(t, user.ID)
+	if len(tokens) != 2 {
+		t.Fatalf("got %d tokens, want 2", len(tokens))
+	}
+	old, next := tokens[0], tokens[1]
+	if !old.IsRevoked || old.RevokedReason == nil || *old.RevokedReason != revokeReasonRotated {
+		t.Errorf("old token = %+v, want revoked as rotated", old)
+	}
+	if old.ReplacedByID == nil || *old.ReplacedByID != next.ID || next.IsRevoked {
+		t.Errorf("old token replaced by %v, want the active %d", old.ReplacedByID, next.ID)
+	}
+	if old.FamilyID != next.FamilyID || next.Token != utils.HashToken(resp.RefreshToken) {
+		t.Errorf("new token %+v is not the issued one of the same family", next)
+	}
+
+	// The session keeps its ID across the rotation
+	sessions, err := s.GetSessions(user.ID, old.FamilyID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != old.FamilyID || !sessions[0].IsCurrent {
+		t.Errorf("sessions = %+v, want the one family", sessions)
+	}
+
+	if _, err := s.RefreshToken(resp.RefreshToken, ClientInfo{}); err != nil {
+		t.Errorf("refresh with the new token: %v", err)
+	}
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	s, user, first := newTestAuthService(t)
+
+	resp, err := s.RefreshToken(first, ClientInfo{})
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	// The rotated token comes back after the grace period: it leaked
+	if err := database.DB.Model(&models.RefreshToken{}).Where("token = ?", utils.HashToken(first)).
+		Update("revoked_at", time.Now().Add(-rotationGracePeriod)).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RefreshToken(first, ClientInfo{}); !errors.Is(err, apperrors.ErrTokenReused) {
+		t.Fatalf("reusing a rotated token = %v, want ErrTokenReused", err)
+	}
+	for _, token := range familyTokens(t, user.ID) {
+		if !token.IsRevoked {
+			t.Errorf("token %d is still active after reuse", token.ID)
+		}
+	}
+	if _, err := s.RefreshToken(resp.RefreshToken, ClientInfo{}); !errors.Is(err, apperrors.ErrInvalidToken) {
+		t.Errorf("refresh with the revoked successor = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestConcurrentRefreshOfOneToken(t *testing.T) {
+	s, user, first := newTestAuthService(t)
+
+	const attempts = 2
+	var wg sync.WaitGroup
+	responses := make([]*dto.LoginResponse, attempts)
+	errs := make([]error, attempts)
+	start := make(chan struct{})
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			responses[i], errs[i] = s.RefreshToken(first, ClientInfo{})
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	// One request rotates the token; the other is refused without revoking
+	// the pair the first one returned
+	var winner *dto.LoginResponse
+	for i, err := range errs {
+		switch {
+		case err == nil && winner != nil:
+			t.Fatal("more than one concurrent refresh of a token succeeded")
+		case err == nil:
+			winner = responses[i]
+		case !errors.Is(err, apperrors.ErrInvalidToken):
+			t.Errorf("losing refresh = %v, want ErrInvalidToken", err)
+		}
+	}
+	if winner == nil {
+		t.Fatal("no concurrent refresh succeeded")
+	}
+
+	tokens := familyTokens(t, user.ID)
+	rotated, active := 0, 0
+	for _, token := range tokens {
+		if token.ReplacedByID != nil {
+			rotated++
+		}
+		if !token.IsRevoked {
+			active++
+		}
+	}
+	if rotated != 1 || active != 1 {
+		t.Errorf("%d rotations and %d active tokens, want 1 and 1", rotated, active)
+	}
+
+	if _, err := s.RefreshToken(winner.RefreshToken, ClientInfo{}); err != nil {
+		t.Errorf("refresh with the winning pair: %v", err)
+	}
+}
@@ -0,0 +1,816 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"fitness-training-backend/internal/database"
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+	apperrors "fitness-training-backend/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+const (
+	slotStepMinutes  = 30
+	defaultSlotDays  = 7
+	maxSlotRangeDays = 31
+)
+
+// BookingService handles trainer availability, open slots and booking requests
+type BookingService interface {
+	// Trainer availability
+	GetAvailability(userID uint) (*dto.AvailabilityResponse, error)
+	UpdateAvailability(userID uint, req *dto.UpdateAvailabilityRequest) (*dto.AvailabilityResponse, error)
+	GetTimeOff(userID uint) ([]dto.TimeOffResponse, error)
+	CreateTimeOff(userID uint, req *dto.CreateTimeOffRequest) (*dto.TimeOffResponse, error)
+	DeleteTimeOff(userID, timeOffID uint) error
+
+	// Public slots
+	GetOpenSlots(trainerID uint, from, to *time.Time, duration int, locationID *uint) ([]dto.AvailableSlot, error)
+
+	// Trainee booking requests
+	CreateBookingRequest(userID uint, req *dto.CreateBookingRequest) (*dto.BookingRequestResponse, error)
+	GetTraineeBookingRequests(userID uint, status string) ([]dto.BookingRequestResponse, error)
+	CancelBookingRequest(userID, requestID uint) error
+
+	// Trainer decisions
+	GetTrainerBookingRequests(userID uint, status string) ([]dto.BookingRequestResponse, error)
+	ConfirmBookingRequest(userID, requestID uint, req *dto.RespondBookingRequest) (*dto.BookingRequestResponse, error)
+	DeclineBookingRequest(userID, requestID uint, req *dto.RespondBookingRequest) (*dto.BookingRequestResponse, error)
+}
+
+type bookingService struct {
+	trainerRepo      repository.TrainerRepository
+	traineeRepo      repository.TraineeRepository
+	scheduleRepo     repository.ScheduleRepository
+	availabilityRepo repository.AvailabilityRepository
+	bookingRepo      repository.BookingRequestRepository
+}
+
+// NewBookingService creates a new booking service
+func NewBookingService(
+	trainerRepo repository.TrainerRepository,
+	traineeRepo repository.TraineeRepository,
+	scheduleRepo repository.ScheduleRepository,
+	availabilityRepo repository.AvailabilityRepository,
+	bookingRepo repository.BookingRequestRepository,
+) BookingService {
+	return &bookingService{
+		trainerRepo:      trainerRepo,
+		traineeRepo:      traineeRepo,
+		scheduleRepo:     scheduleRepo,
+		availabilityRepo: availabilityRepo,
+		bookingRepo:      bookingRepo,
+	}
+}
+
+// timeRange is a half-open [start, end) interval
+type timeRange struct {
+	start time.Time
+	end   time.Time
+}
+
+func (r timeRange) overlaps(other timeRange) bool {
+	return r.start.Before(other.end) && other.start.Before(r.end)
+}
+
+// ==========================================
+// AVAILABILITY
+// ==========================================
+
+func (s *bookingService) GetAvailability(userID uint) (*dto.AvailabilityResponse, error) {
+	trainer, err := s.trainerRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return s.availabilityResponse(trainer)
+}
+
+// UpdateAvailability replaces the trainer's weekly windows
+func (s *bookingService) UpdateAvailability(userID uint, req *dto.UpdateAvailabilityRequest) (*dto.AvailabilityResponse, error) {
+	trainer, err := s.trainerRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	windows := make([]models.TrainerAvailability, 0, len(req.Windows))
+	for _, w := range req.Windows {
+		start, err := minutesOfDay(w.StartTime)
+		if err != nil {
+			return nil, err
+		}
+		end, err := minutesOfDay(w.EndTime)
+		if err != nil {
+			return nil, err
+		}
+		if end <= start {
+			return nil, fmt.Errorf("%w: window end time must be after start time", apperrors.ErrInvalidInput)
+		}
+		windows = append(windows, models.TrainerAvailability{
+			TrainerID:  trainer.ID,
+			LocationID: w.LocationID,
+			Weekday:    w.Weekday,
+			StartTime:  formatMinutes(start),
+			EndTime:    formatMinutes(end),
+		})
+	}
+
+	if err := validateWindowOverlap(windows); err != nil {
+		return nil, err
+	}
+
+	err = database.Transaction(func(tx *gorm.DB) error {
+		if err := repository.NewAvailabilityRepository(tx).ReplaceForTrainer(trainer.ID, windows); err != nil {
+			return err
+		}
+		if req.BufferMinutes != nil {
+			trainer.BufferMinutes = *req.BufferMinutes
+			return repository.NewTrainerRepository(tx).Update(trainer)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.availabilityResponse(trainer)
+}
+
+func (s *bookingService) availabilityResponse(trainer *models.Trainer) (*dto.AvailabilityResponse, error) {
+	windows, err := s.availabilityRepo.FindByTrainerID(trainer.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.AvailabilityResponse{
+		Windows:       make([]dto.AvailabilityWindow, 0, len(windows)),
+		BufferMinutes: trainer.BufferMinutes,
+		Timezone:      defaultTimezone,
+	}
+	for _, w := range windows {
+		resp.Windows = append(resp.Windows, dto.AvailabilityWindow{
+			Weekday:    w.Weekday,
+			StartTime:  trimSeconds(w.StartTime),
+			EndTime:    trimSeconds(w.EndTime),
+			LocationID: w.LocationID,
+		})
+	}
+	return resp, nil
+}
+
+func (s *bookingService) GetTimeOff(userID uint) ([]dto.TimeOffResponse, error) {
+	trainer, err := s.trainerRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	// Upcoming and ongoing periods only
+	periods, err := s.availabilityRepo.FindTimeOff(trainer.ID, time.Now(), time.Now().AddDate(10, 0, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]dto.TimeOffResponse, 0, len(periods))
+	for i := range periods {
+		resp = append(resp, toTimeOffResponse(&periods[i]))
+	}
+	return resp, nil
+}
+
+func (s *bookingService) CreateTimeOff(userID uint, req *dto.CreateTimeOffRequest) (*dto.TimeOffResponse, error) {
+	trainer, err := s.trainerRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	if !req.EndAt.After(req.StartAt) {
+		return nil, fmt.Errorf("%w: endAt must be after startAt", apperrors.ErrInvalidInput)
+	}
+
+	timeOff := &models.TrainerTimeOff{
+		TrainerID:  trainer.ID,
+		LocationID: req.LocationID,
+		StartAt:    req.StartAt,
+		EndAt:      req.EndAt,
+		Reason:     req.Reason,
+	}
+	if err := s.availabilityRepo.CreateTimeOff(timeOff); err != nil {
+		return nil, err
+	}
+
+	resp := toTimeOffResponse(timeOff)
+	return &resp, nil
+}
+
+func (s *bookingService) DeleteTimeOff(userID, timeOffID uint) error {
+	trainer, err := s.trainerRepo.FindByUserID(userID)
+	if err != nil {
+		return notFound(err)
+	}
+
+	timeOff, err := s.availabilityRepo.FindTimeOffByID(timeOffID)
+	if err != nil {
+		return notFound(err)
+	}
+	if timeOff.TrainerID != trainer.ID {
+		return apperrors.ErrForbidden
+	}
+
+	return s.availabilityRepo.DeleteTimeOff(timeOff.ID)
+}
+
+// ==========================================
+// OPEN SLOTS
+// ==========================================
+
+// GetOpenSlots lists the bookable slots of a trainer between two dates
+// (default: the next 7 days)
+func (s *bookingService) GetOpenSlots(trainerID uint, from, to *time.Time, duration int, locationID *uint) ([]dto.AvailableSlot, error) {
+	trainer, err := s.trainerRepo.FindByID(trainerID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	loc, err := time.LoadLocation(defaultTimezone)
+	if err != nil {
+		return nil, err
+	}
+
+	start := localDate(time.Now().In(loc), loc)
+	if from != nil {
+		start = localDate(*from, loc)
+	}
+	end := start.AddDate(0, 0, defaultSlotDays-1)
+	if to != nil {
+		end = localDate(*to, loc)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("%w: toDate must not be before fromDate", apperrors.ErrInvalidInput)
+	}
+	if end.Sub(start) > maxSlotRangeDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: slots can be listed for at most %d days", apperrors.ErrInvalidInput, maxSlotRangeDays)
+	}
+
+	return s.openSlots(trainer, start, end, duration, locationID, loc)
+}
+
+// openSlots subtracts time off and existing sessions (plus the trainer's
+// buffer on both sides) from the weekly windows of each day in [from, to]
+func (s *bookingService) openSlots(trainer *models.Trainer, from, to time.Time, duration int, locationID *uint, loc *time.Location) ([]dto.AvailableSlot, error) {
+	if trainer.Availability == "unavailable" {
+		return []dto.AvailableSlot{}, nil
+	}
+
+	windows, err := s.availabilityRepo.FindByTrainerID(trainer.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	rangeEnd := to.AddDate(0, 0, 1)
+	timeOff, err := s.availabilityRepo.FindTimeOff(trainer.ID, from, rangeEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sessions from the previous day may run past midnight
+	schedules, err := s.scheduleRepo.FindByTrainerID(trainer.ID, map[string]interface{}{
+		"fromDate": from.AddDate(0, 0, -1),
+		"toDate":   to,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	busy := make([]timeRange, 0, len(schedules))
+	for i := range schedules {
+		schedule := &schedules[i]
+		if !schedule.CanBeCancelled() {
+			continue
+		}
+		// Sessions of a series keep the series' timezone
+		start, err := scheduleStart(schedule)
+		if err != nil {
+			return nil, err
+		}
+		busy = append(busy, timeRange{start: start, end: start.Add(time.Duration(schedule.Duration) * time.Minute)})
+	}
+
+	return findOpenSlots(windows, timeOff, busy, from, to, duration, trainer.BufferMinutes, locationID, time.Now(), loc)
+}
+
+// findOpenSlots walks every matching weekly window in slotStepMinutes steps
+// and keeps the slots that are in the future, outside time off and at least
+// bufferMinutes away from busy ranges
+func findOpenSlots(
+	windows []models.TrainerAvailability,
+	timeOff []models.TrainerTimeOff,
+	busy []timeRange,
+	from, to time.Time,
+	duration, bufferMinutes int,
+	locationID *uint,
+	now time.Time,
+	loc *time.Location,
+) ([]dto.AvailableSlot, error) {
+	length := time.Duration(duration) * time.Minute
+	buffer := time.Duration(bufferMinutes) * time.Minute
+	step := slotStepMinutes * time.Minute
+
+	slots := make([]dto.AvailableSlot, 0)
+	seen := map[string]bool{}
+
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		for _, window := range windows {
+			if window.Weekday != int(day.Weekday()) {
+				continue
+			}
+			if locationID != nil && window.LocationID != nil && *window.LocationID != *locationID {
+				continue
+			}
+
+			slotLocation := window.LocationID
+			if locationID != nil {
+				slotLocation = locationID
+			}
+
+			startHour, startMinute, err := parseTimeOfDay(window.StartTime)
+			if err != nil {
+				return nil, err
+			}
+			endHour, endMinute, err := parseTimeOfDay(window.EndTime)
+			if err != nil {
+				return nil, err
+			}
+			windowStart := time.Date(day.Year(), day.Month(), day.Day(), startHour, startMinute, 0, 0, loc)
+			windowEnd := time.Date(day.Year(), day.Month(), day.Day(), endHour, endMinute, 0, 0, loc)
+
+			for start := windowStart; !start.Add(length).After(windowEnd); start = start.Add(step) {
+				slot := timeRange{start: start, end: start.Add(length)}
+				if !start.After(now) || blockedByTimeOff(slot, timeOff, slotLocation) {
+					continue
+				}
+
+				padded := timeRange{start: slot.start.Add(-buffer), end: slot.end.Add(buffer)}
+				if overlapsAny(padded, busy) {
+					continue
+				}
+
+				key := fmt.Sprintf("%d", start.Unix())
+				if slotLocation != nil {
+					key = fmt.Sprintf("%d-%d", start.Unix(), *slotLocation)
+				}
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+
+				slots = append(slots, dto.AvailableSlot{
+					Date:       start.Format("2006-01-02"),
+					StartTime:  start.Format("15:04"),
+					EndTime:    slot.end.Format("15:04"),
+					StartsAt:   start,
+					LocationID: slotLocation,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(slots, func(i, j int) bool {
+		return slots[i].StartsAt.Before(slots[j].StartsAt)
+	})
+	return slots, nil
+}
+
+// checkSlotStillFree rechecks a requested slot when it is confirmed: time
+// off added since the request blocks it, and so does any session within the
+// trainer's buffer, on each day the padded slot covers
+func checkSlotStillFree(
+	scheduleRepo repository.ScheduleRepository,
+	availabilityRepo repository.AvailabilityRepository,
+	trainer *models.Trainer,
+	slot timeRange,
+	locationID *uint,
+	loc *time.Location,
+) error {
+	timeOff, err := availabilityRepo.FindTimeOff(trainer.ID, slot.start, slot.end)
+	if err != nil {
+		return err
+	}
+	if blockedByTimeOff(slot, timeOff, locationID) {
+		return fmt.Errorf("%w: the trainer is away at that time", apperrors.ErrScheduleConflict)
+	}
+
+	buffer := time.Duration(trainer.BufferMinutes) * time.Minute
+	end := slot.end.Add(buffer)
+	for start := slot.start.Add(-buffer); start.Before(end); {
+		day := localDate(start, loc)
+		next := day.AddDate(0, 0, 1)
+		if next.After(end) {
+			next = end
+		}
+		conflict, err := scheduleRepo.CheckConflict(trainer.ID, day, start.Format("15:04"), int(next.Sub(start)/time.Minute), nil)
+		if err != nil {
+			return err
+		}
+		if conflict {
+			return fmt.Errorf("%w: the slot is no longer free", apperrors.ErrScheduleConflict)
+		}
+		start = next
+	}
+	return nil
+}
+
+// blockedByTimeOff checks time off that applies to the slot's location
+func blockedByTimeOff(slot timeRange, periods []models.TrainerTimeOff, locationID *uint) bool {
+	for _, period := range periods {
+		if period.LocationID != nil && (locationID == nil || *period.LocationID != *locationID) {
+			continue
+		}
+		if slot.overlaps(timeRange{start: period.StartAt, end: period.EndAt}) {
+			return true
+		}
+	}
+	return false
+}
+
+func overlapsAny(slot timeRange, ranges []timeRange) bool {
+	for _, r := range ranges {
+		if slot.overlaps(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateWindowOverlap rejects windows on the same weekday that overlap
+func validateWindowOverlap(windows []models.TrainerAvailability) error {
+	for i := range windows {
+		for j := i + 1; j < len(windows); j++ {
+			a, b := windows[i], windows[j]
+			if a.Weekday != b.Weekday {
+				continue
+			}
+			aStart, aEnd, err := windowMinutes(a)
+			if err != nil {
+				return err
+			}
+			bStart, bEnd, err := windowMinutes(b)
+			if err != nil {
+				return err
+			}
+			if aStart < bEnd && bStart < aEnd {
+				return fmt.Errorf("%w: availability windows on the same day must not overlap", apperrors.ErrInvalidInput)
+			}
+		}
+	}
+	return nil
+}
+
+// windowMinutes returns a window's start and end in minutes after midnight
+func windowMinutes(window models.TrainerAvailability) (int, int, error) {
+	start, err := minutesOfDay(window.StartTime)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := minutesOfDay(window.EndTime)
+	if err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+// ==========================================
+// BOOKING REQUESTS (TRAINEE)
+// ==========================================
+
+// CreateBookingRequest asks a trainer for one of their open slots
+func (s *bookingService) CreateBookingRequest(userID uint, req *dto.CreateBookingRequest) (*dto.BookingRequestResponse, error) {
+	trainee, err := s.traineeRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	trainer, err := s.trainerRepo.FindByID(req.TrainerID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	if trainee.TrainerID != nil && *trainee.TrainerID != trainer.ID {
+		return nil, fmt.Errorf("%w: sessions can only be booked with your own trainer", apperrors.ErrForbidden)
+	}
+
+	startMinutes, err := minutesOfDay(req.Time)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := time.LoadLocation(defaultTimezone)
+	if err != nil {
+		return nil, err
+	}
+	date := localDate(req.Date, loc)
+
+	slots, err := s.openSlots(trainer, date, date, req.Duration, req.LocationID, loc)
+	if err != nil {
+		return nil, err
+	}
+	slot := findSlot(slots, startMinutes)
+	if slot == nil {
+		return nil, fmt.Errorf("%w: the requested slot is not available", apperrors.ErrScheduleConflict)
+	}
+
+	pending, err := s.bookingRepo.FindByTraineeID(trainee.ID, "pending")
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range pending {
+		if existing.TrainerID == trainer.ID && existing.Date.Format("2006-01-02") == slot.Date &&
+			trimSeconds(existing.Time) == slot.StartTime {
+			return nil, fmt.Errorf("%w: you already requested this slot", apperrors.ErrAlreadyExists)
+		}
+	}
+
+	request := &models.BookingRequest{
+		TrainerID:  trainer.ID,
+		TraineeID:  trainee.ID,
+		LocationID: slot.LocationID,
+		Date:       date,
+		Time:       slot.StartTime,
+		Duration:   req.Duration,
+		Notes:      req.Notes,
+		Status:     "pending",
+	}
+
+	err = database.Transaction(func(tx *gorm.DB) error {
+		if err := repository.NewBookingRequestRepository(tx).Create(request); err != nil {
+			return err
+		}
+		return repository.NewNotificationRepository(tx).Create(bookingNotification(
+			trainer.UserID, request,
+			"New booking request",
+			fmt.Sprintf("%s requested a session on %s at %s", trainee.User.Name, slot.Date, slot.StartTime),
+		))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	request.Trainer = *trainer
+	resp := toBookingRequestResponse(request)
+	return &resp, nil
+}
+
+func (s *bookingService) GetTraineeBookingRequests(userID uint, status string) ([]dto.BookingRequestResponse, error) {
+	trainee, err := s.traineeRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	requests, err := s.bookingRepo.FindByTraineeID(trainee.ID, status)
+	if err != nil {
+		return nil, err
+	}
+	return toBookingRequestResponses(requests), nil
+}
+
+// CancelBookingRequest withdraws a pending request
+func (s *bookingService) CancelBookingRequest(userID, requestID uint) error {
+	trainee, err := s.traineeRepo.FindByUserID(userID)
+	if err != nil {
+		return notFound(err)
+	}
+
+	request, err := s.bookingRepo.FindByID(requestID)
+	if err != nil {
+		return notFound(err)
+	}
+	if request.TraineeID != trainee.ID {
+		return apperrors.ErrForbidden
+	}
+	if !request.IsPending() {
+		return fmt.Errorf("%w: only pending requests can be cancelled", apperrors.ErrConflict)
+	}
+
+	request.Status = "cancelled"
+	cancelled, err := s.bookingRepo.UpdateIfPending(request)
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return fmt.Errorf("%w: the trainer already answered the request", apperrors.ErrConflict)
+	}
+	return nil
+}
+
+// ==========================================
+// BOOKING REQUESTS (TRAINER)
+// ==========================================
+
+func (s *bookingService) GetTrainerBookingRequests(userID uint, status string) ([]dto.BookingRequestResponse, error) {
+	trainer, err := s.trainerRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	requests, err := s.bookingRepo.FindByTrainerID(trainer.ID, status)
+	if err != nil {
+		return nil, err
+	}
+	return toBookingRequestResponses(requests), nil
+}
+
+// ConfirmBookingRequest turns a pending request into a Schedule. A trainee
+// without a trainer becomes the trainer's client.
+func (s *bookingService) ConfirmBookingRequest(userID, requestID uint, req *dto.RespondBookingRequest) (*dto.BookingRequestResponse, error) {
+	trainer, request, err := s.getPendingRequest(userID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := time.LoadLocation(defaultTimezone)
+	if err != nil {
+		return nil, err
+	}
+	hour, minute, err := parseTimeOfDay(request.Time)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Date(request.Date.Year(), request.Date.Month(), request.Date.Day(), hour, minute, 0, 0, loc)
+	slot := timeRange{start: start, end: start.Add(time.Duration(request.Duration) * time.Minute)}
+
+	title := "Training session"
+	if req.Title != nil && *req.Title != "" {
+		title = *req.Title
+	}
+
+	err = database.Transaction(func(tx *gorm.DB) error {
+		scheduleRepo := repository.NewScheduleRepository(tx)
+
+		if err := checkSlotStillFree(scheduleRepo, repository.NewAvailabilityRepository(tx), trainer, slot, request.LocationID, loc); err != nil {
+			return err
+		}
+
+		schedule := &models.Schedule{
+			TrainerID:  trainer.ID,
+			TraineeID:  request.TraineeID,
+			LocationID: request.LocationID,
+			Date:       request.Date,
+			Time:       trimSeconds(request.Time),
+			Duration:   request.Duration,
+			Title:      title,
+			Notes:      request.Notes,
+			Status:     "confirmed",
+		}
+		if err := scheduleRepo.Create(schedule); err != nil {
+			return err
+		}
+
+		if request.Trainee.TrainerID == nil {
+			if err := tx.Model(&models.Trainee{}).Where("id = ?", request.TraineeID).
+				Update("trainer_id", trainer.ID).Error; err != nil {
+				return err
+			}
+			if err := repository.NewTrainerRepository(tx).RefreshClientCount(trainer.ID); err != nil {
+				return err
+			}
+		}
+
+		// The trainee may have cancelled, or another confirmation won, since
+		// the request was loaded; the schedule is rolled back then
+		now := time.Now()
+		request.Status = "confirmed"
+		request.ScheduleID = &schedule.ID
+		request.ResponseNote = req.Note
+		request.RespondedAt = &now
+		answered, err := repository.NewBookingRequestRepository(tx).UpdateIfPending(request)
+		if err != nil {
+			return err
+		}
+		if !answered {
+			return fmt.Errorf("%w: the request is no longer pending", apperrors.ErrConflict)
+		}
+
+		return repository.NewNotificationRepository(tx).Create(bookingNotification(
+			request.Trainee.UserID, request,
+			"Booking confirmed",
+			fmt.Sprintf("%s confirmed your session on %s at %s", trainer.User.Name, request.Date.Format("2006-01-02"), trimSeconds(request.Time)),
+		))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.traineeRepo.UpdateStats(request.TraineeID); err != nil {
+		return nil, err
+	}
+
+	resp := toBookingRequestResponse(request)
+	return &resp, nil
+}
+
+// DeclineBookingRequest rejects a pending request
+func (s *bookingService) DeclineBookingRequest(userID, requestID uint, req *dto.RespondBookingRequest) (*dto.BookingRequestResponse, error) {
+	trainer, request, err := s.getPendingRequest(userID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	request.Status = "declined"
+	request.ResponseNote = req.Note
+	request.RespondedAt = &now
+
+	err = database.Transaction(func(tx *gorm.DB) error {
+		answered, err := repository.NewBookingRequestRepository(tx).UpdateIfPending(request)
+		if err != nil {
+			return err
+		}
+		if !answered {
+			return fmt.Errorf("%w: the request is no longer pending", apperrors.ErrConflict)
+		}
+		return repository.NewNotificationRepository(tx).Create(bookingNotification(
+			request.Trainee.UserID, request,
+			"Booking declined",
+			fmt.Sprintf("%s declined your session request for %s at %s", trainer.User.Name, request.Date.Format("2006-01-02"), trimSeconds(request.Time)),
+		))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toBookingRequestResponse(request)
+	return &resp, nil
+}
+
+// getPendingRequest loads a pending request addressed to the trainer
+func (s *bookingService) getPendingRequest(userID, requestID uint) (*models.Trainer, *models.BookingRequest, error) {
+	trainer, err := s.trainerRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, nil, notFound(err)
+	}
+
+	request, err := s.bookingRepo.FindByID(requestID)
+	if err != nil {
+		return nil, nil, notFound(err)
+	}
+	if request.TrainerID != trainer.ID {
+		return nil, nil, apperrors.ErrForbidden
+	}
+	if !request.IsPending() {
+		return nil, nil, fmt.Errorf("%w: request was already %s", apperrors.ErrConflict, request.Status)
+	}
+	return trainer, request, nil
+}
+
+// ==========================================
+// HELPERS
+// ==========================================
+
+// findSlot finds the slot starting startMinutes after midnight
+func findSlot(slots []dto.AvailableSlot, startMinutes int) *dto.AvailableSlot {
+	for i := range slots {
+		if minutes, err := minutesOfDay(slots[i].StartTime); err == nil && minutes == startMinutes {
+			return &slots[i]
+		}
+	}
+	return nil
+}
+
+// minutesOfDay converts an "H:MM", "HH:MM" or "HH:MM:SS" time to minutes
+// after midnight, so times compare correctly whatever their padding
+func minutesOfDay(value string) (int, error) {
+	hour, minute, err := parseTimeOfDay(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: time must be in HH:MM format", apperrors.ErrInvalidInput)
+	}
+	return hour*60 + minute, nil
+}
+
+// formatMinutes formats minutes after midnight as "HH:MM"
+func formatMinutes(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+func bookingNotification(userID uint, request *models.BookingRequest, title, message string) *models.Notification {
+	relatedType := "booking_request"
+	return &models.Notification{
+		UserID:      userID,
+		Type:        "schedule",
+		Title:       title,
+		Message:     message,
+		RelatedID:   &request.ID,
+		RelatedType: &relatedType,
+		Priority:    "medium",
+	}
+}
+
+// trimSeconds turns the "HH:MM:SS" form of TIME columns into "HH:MM"
+func trimSeconds(value string) string {
+	if len(value) > 5 {
+		return value[:5]
+	}
+	return value
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+	apperrors "fitness-training-backend/pkg/errors"
+)
+
+func TestMinutesOfDay(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int
+		ok    bool
+	}{
+		{"09:00", 540, true},
+		{"9:00", 540, true},
+		{"17:30:00", 1050, true},
+		{"00:00", 0, true},
+		{"23:59", 1439, true},
+		{"24:00", 0, false},
+		{"9", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := minutesOfDay(tt.value)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("minutesOfDay(%q) = %d, %v; want %d, ok %v", tt.value, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestValidateWindowOverlap(t *testing.T) {
+	window := func(weekday int, start, end string) models.TrainerAvailability {
+		return models.TrainerAvailability{Weekday: weekday, StartTime: start, EndTime: end}
+	}
+	tests := []struct {
+		name    string
+		windows []models.TrainerAvailability
+		ok      bool
+	}{
+		{"unpadded hours", []models.TrainerAvailability{window(1, "9:00", "12:00"), window(1, "13:00", "17:00")}, true},
+		// As strings "9:00" sorts after "10:00"
+		{"overlap with an unpadded hour", []models.TrainerAvailability{window(1, "9:00", "11:00"), window(1, "10:00", "12:00")}, false},
+		{"touching windows", []models.TrainerAvailability{window(1, "09:00", "12:00"), window(1, "12:00", "15:00")}, true},
+		{"other weekday", []models.TrainerAvailability{window(1, "09:00", "12:00"), window(2, "10:00", "11:00")}, true},
+		{"seconds from the database", []models.TrainerAvailability{window(1, "09:00:00", "12:00:00"), window(1, "11:00", "13:00")}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateWindowOverlap(tt.windows)
+			if (err == nil) != tt.ok {
+				t.Errorf("validateWindowOverlap = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestFindSlot(t *testing.T) {
+	slots := []dto.AvailableSlot{{StartTime: "08:30"}, {StartTime: "09:00"}, {StartTime: "10:00"}}
+
+	minutes, err := minutesOfDay("9:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slot := findSlot(slots, minutes); slot == nil || slot.StartTime != "09:00" {
+		t.Errorf("findSlot(9:00) = %v, want the 09:00 slot", slot)
+	}
+	if slot := findSlot(slots, 9*60+15); slot != nil {
+		t.Errorf("findSlot(9:15) = %v, want none", slot)
+	}
+}
+
+// fakeBusySchedules reports a conflict when the checked range overlaps busy
+type fakeBusySchedules struct {
+	repository.ScheduleRepository
+	loc    *time.Location
+	busy   []timeRange
+	checks []string
+}
+
+func (f *fakeBusySchedules) CheckConflict(trainerID uint, date time.Time, timeStr string, duration int, excludeID *uint) (bool, error) {
+	f.checks = append(f.checks, fmt.Sprintf("%s %s +%d", date.Format("01-02"), timeStr, duration))
+	hour, minute, err := parseTimeOfDay(timeStr)
+	if err != nil {
+		return false, err
+	}
+	start := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, f.loc)
+	return overlapsAny(timeRange{start: start, end: start.Add(time.Duration(duration) * time.Minute)}, f.busy), nil
+}
+
+type fakeTimeOff struct {
+	repository.AvailabilityRepository
+	periods []models.TrainerTimeOff
+}
+
+func (f *fakeTimeOff) FindTimeOff(trainerID uint, from, to time.Time) ([]models.TrainerTimeOff, error) {
+	var periods []models.TrainerTimeOff
+	for _, period := range f.periods {
+		if period.StartAt.Before(to) && period.EndAt.After(from) {
+			periods = append(periods, period)
+		}
+	}
+	return periods, nil
+}
+
+func TestCheckSlotStillFree(t *testing.T) {
+	loc, err := time.LoadLocation(defaultTimezone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 3, day, hour, minute, 0, 0, loc)
+	}
+	span := func(start time.Time, minutes int) timeRange {
+		return timeRange{start: start, end: start.Add(time.Duration(minutes) * time.Minute)}
+	}
+	gym, studio := uint(1), uint(2)
+
+	tests := []struct {
+		name       string
+		slot       timeRange
+		busy       []timeRange
+		timeOff    []models.TrainerTimeOff
+		want       bool // free
+		wantChecks []string
+	}{
+		{name: "free", slot: span(at(20, 10, 0), 60), busy: []timeRange{span(at(20, 12, 0), 60)},
+			want: true, wantChecks: []string{"03-20 09:45 +90"}},
+		{name: "session inside the buffer", slot: span(at(20, 10, 0), 60), busy: []timeRange{span(at(20, 11, 10), 60)}},
+
+		// The padded slot runs past midnight
+		{name: "late slot against an early session", slot: span(at(20, 23, 0), 50), busy: []timeRange{span(at(21, 0, 0), 60)},
+			wantChecks: []string{"03-20 22:45 +75", "03-21 00:00 +5"}},
+		{name: "early slot against a late session", slot: span(at(21, 0, 5), 55), busy: []timeRange{span(at(20, 23, 0), 55)},
+			wantChecks: []string{"03-20 23:50 +10"}},
+		{name: "late slot, next day free", slot: span(at(20, 23, 0), 50), busy: []timeRange{span(at(21, 0, 5), 60)},
+			want: true, wantChecks: []string{"03-20 22:45 +75", "03-21 00:00 +5"}},
+
+		// Time off added after the request was made
+		{name: "time off everywhere", slot: span(at(20, 10, 0), 60),
+			timeOff: []models.TrainerTimeOff{{StartAt: at(20, 0, 0), EndAt: at(21, 0, 0)}}},
+		{name: "time off at the slot's location", slot: span(at(20, 10, 0), 60),
+			timeOff: []models.TrainerTimeOff{{LocationID: &gym, StartAt: at(20, 10, 30), EndAt: at(20, 12, 0)}}},
+		{name: "time off at another location", slot: span(at(20, 10, 0), 60), want: true,
+			timeOff: []models.TrainerTimeOff{{LocationID: &studio, StartAt: at(20, 0, 0), EndAt: at(21, 0, 0)}}},
+		{name: "time off ending as the slot starts", slot: span(at(20, 10, 0), 60), want: true,
+			timeOff: []models.TrainerTimeOff{{StartAt: at(20, 8, 0), EndAt: at(20, 10, 0)}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedules := &fakeBusySchedules{loc: loc, busy: tt.busy}
+			trainer := &models.Trainer{ID: 1, BufferMinutes: 15}
+
+			err := checkSlotStillFree(schedules, &fakeTimeOff{periods: tt.timeOff}, trainer, tt.slot, &gym, loc)
+			switch {
+			case tt.want && err != nil:
+				t.Errorf("checkSlotStillFree = %v, want free", err)
+			case !tt.want && !errors.Is(err, apperrors.ErrScheduleConflict):
+				t.Errorf("checkSlotStillFree = %v, want a schedule conflict", err)
+			}
+			if tt.wantChecks != nil && strings.Join(schedules.checks, ", ") != strings.Join(tt.wantChecks, ", ") {
+				t.Errorf("checked %v, want %v", schedules.checks, tt.wantChecks)
+			}
+		})
+	}
+}
@@ -0,0 +1,516 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"fitness-training-backend/internal/config"
+	"fitness-training-backend/internal/database"
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+	apperrors "fitness-training-backend/pkg/errors"
+	"fitness-training-backend/pkg/ical"
+	"fitness-training-backend/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+const (
+	calendarProdID        = "-//Fitness Training//Schedules//EN"
+	calendarFeedPath      = "/api/v1/calendar/feeds/"
+	calendarFeedHistory   = 90 // days of past sessions kept in feeds
+	calendarFeedTokenSize = 24
+	maxImportEvents       = 500
+)
+
+// errDryRun rolls back an import transaction after a dry run
+var errDryRun = errors.New("dry run")
+
+// CalendarService handles iCalendar feeds and imports
+type CalendarService interface {
+	// Feed management (authenticated user)
+	GetFeed(userID uint) (*dto.CalendarFeedResponse, error)
+	RotateFeed(userID uint) (*dto.CalendarFeedResponse, error)
+	DeleteFeed(userID uint) error
+
+	// Public, token-protected feed
+	RenderFeed(token string) ([]byte, error)
+
+	// Trainer import
+	ImportSchedules(userID uint, req *dto.ImportSchedulesRequest, data io.Reader) (*dto.ImportSchedulesResponse, error)
+}
+
+type calendarService struct {
+	feedRepo     repository.CalendarFeedRepository
+	userRepo     repository.UserRepository
+	trainerRepo  repository.TrainerRepository
+	traineeRepo  repository.TraineeRepository
+	scheduleRepo repository.ScheduleRepository
+	cfg          *config.Config
+}
+
+// NewCalendarService creates a new calendar service
+func NewCalendarService(
+	feedRepo repository.CalendarFeedRepository,
+	userRepo repository.UserRepository,
+	trainerRepo repository.TrainerRepository,
+	traineeRepo repository.TraineeRepository,
+	scheduleRepo repository.ScheduleRepository,
+	cfg *config.Config,
+) CalendarService {
+	return &calendarService{
+		feedRepo:     feedRepo,
+		userRepo:     userRepo,
+		trainerRepo:  trainerRepo,
+		traineeRepo:  traineeRepo,
+		scheduleRepo: scheduleRepo,
+		cfg:          cfg,
+	}
+}
+
+// ==========================================
+// FEED MANAGEMENT
+// ==========================================
+
+// GetFeed returns the user's feed, creating it on first use. The URL is
+// only known, and returned, when the feed is created.
+func (s *calendarService) GetFeed(userID uint) (*dto.CalendarFeedResponse, error) {
+	feed, err := s.feedRepo.FindByUserID(userID)
+	if err == nil {
+		return s.toFeedResponse(feed, ""), nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	token, err := utils.GenerateSecureToken(calendarFeedTokenSize)
+	if err != nil {
+		return nil, err
+	}
+
+	feed = &models.CalendarFeed{UserID: userID, TokenHash: utils.HashToken(token)}
+	if err := s.feedRepo.Create(feed); err != nil {
+		return nil, err
+	}
+	return s.toFeedResponse(feed, token), nil
+}
+
+// RotateFeed replaces the feed token; subscriptions to the old URL stop working
+func (s *calendarService) RotateFeed(userID uint) (*dto.CalendarFeedResponse, error) {
+	feed, err := s.feedRepo.FindByUserID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return s.GetFeed(userID)
+		}
+		return nil, err
+	}
+
+	token, err := utils.GenerateSecureToken(calendarFeedTokenSize)
+	if err != nil {
+		return nil, err
+	}
+
+	feed.TokenHash = utils.HashToken(token)
+	feed.LastAccessedAt = nil
+	if err := s.feedRepo.Update(feed); err != nil {
+		return nil, err
+	}
+	return s.toFeedResponse(feed, token), nil
+}
+
+// DeleteFeed disables the user's feed
+func (s *calendarService) DeleteFeed(userID uint) error {
+	return s.feedRepo.DeleteByUserID(userID)
+}
+
+// toFeedResponse describes a feed, with its URLs when the token is known
+func (s *calendarService) toFeedResponse(feed *models.CalendarFeed, token string) *dto.CalendarFeedResponse {
+	resp := &dto.CalendarFeedResponse{
+		CreatedAt:      feed.CreatedAt,
+		LastAccessedAt: feed.LastAccessedAt,
+	}
+	if token == "" {
+		return resp
+	}
+
+	resp.URL = s.cfg.Server.PublicURL + calendarFeedPath + token + ".ics"
+	resp.WebcalURL = resp.URL
+	if i := strings.Index(resp.URL, "://"); i >= 0 {
+		resp.WebcalURL = "webcal" + resp.URL[i:]
+	}
+	return resp
+}
+
+// ==========================================
+// FEED RENDERING
+// ==========================================
+
+// RenderFeed builds the .ics document for a feed token. Trainers get the
+// sessions they run, trainees the sessions they attend.
+func (s *calendarService) RenderFeed(token string) ([]byte, error) {
+	feed, err := s.feedRepo.FindByTokenHash(utils.HashToken(token))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if !feed.User.IsActive {
+		return nil, apperrors.ErrNotFound
+	}
+
+	filters := map[string]interface{}{
+		"fromDate": truncateDate(time.Now()).AddDate(0, 0, -calendarFeedHistory),
+	}
+
+	var (
+		schedules []models.Schedule
+		name      string
+	)
+	switch {
+	case feed.User.IsTrainer():
+		trainer, err := s.trainerRepo.FindByUserID(feed.UserID)
+		if err != nil {
+			return nil, notFound(err)
+		}
+		if schedules, err = s.scheduleRepo.FindByTrainerID(trainer.ID, filters); err != nil {
+			return nil, err
+		}
+		name = "Training sessions - " + feed.User.Name
+	case feed.User.IsTrainee():
+		trainee, err := s.traineeRepo.FindByUserID(feed.UserID)
+		if err != nil {
+			return nil, notFound(err)
+		}
+		if schedules, err = s.scheduleRepo.FindByTraineeID(trainee.ID, filters); err != nil {
+			return nil, err
+		}
+		name = "My training sessions"
+	default:
+		return nil, apperrors.ErrNotFound
+	}
+
+	calendar := &ical.Calendar{
+		ProdID:          calendarProdID,
+		Name:            name,
+		RefreshInterval: time.Hour,
+		Events:          make([]ical.Event, 0, len(schedules)),
+	}
+	for i := range schedules {
+		event, err := s.toCalendarEvent(&schedules[i], feed.User.IsTrainer())
+		if err != nil {
+			return nil, err
+		}
+		calendar.Events = append(calendar.Events, event)
+	}
+
+	if err := s.feedRepo.TouchAccessed(feed.ID); err != nil {
+		return nil, err
+	}
+
+	return calendar.Encode(), nil
+}
+
+// toCalendarEvent converts a schedule into a VEVENT with a UID that stays the
+// same across edits and a SEQUENCE that grows with them, so calendar clients
+// update the event in place
+func (s *calendarService) toCalendarEvent(schedule *models.Schedule, forTrainer bool) (ical.Event, error) {
+	start, err := scheduleStart(schedule)
+	if err != nil {
+		return ical.Event{}, err
+	}
+
+	summary := schedule.Title
+	if forTrainer && schedule.Trainee.User.Name != "" {
+		summary += " - " + schedule.Trainee.User.Name
+	} else if !forTrainer && schedule.Trainer.User.Name != "" {
+		summary += " with " + schedule.Trainer.User.Name
+	}
+
+	event := ical.Event{
+		UID:         fmt.Sprintf("schedule-%d@%s", schedule.ID, s.uidDomain()),
+		Summary:     summary,
+		Description: scheduleEventDescription(schedule),
+		Start:       start,
+		End:         start.Add(time.Duration(schedule.Duration) * time.Minute),
+		Status:      calendarStatus(schedule.Status),
+		Sequence:    schedule.Revision,
+		Created:     schedule.CreatedAt,
+		Modified:    schedule.UpdatedAt,
+	}
+
+	if schedule.Location != nil {
+		event.Location = schedule.Location.Name
+		if schedule.Location.Address != nil && *schedule.Location.Address != "" {
+			event.Location += ", " + *schedule.Location.Address
+		}
+		event.Latitude = schedule.Location.Latitude
+		event.Longitude = schedule.Location.Longitude
+	}
+
+	return event, nil
+}
+
+// uidDomain is the right-hand side of event UIDs
+func (s *calendarService) uidDomain() string {
+	if u, err := url.Parse(s.cfg.Server.PublicURL); err == nil && u.Hostname() != "" {
+		return u.Hostname()
+	}
+	return "fitness-training"
+}
+
+// scheduleLocation resolves the timezone a schedule's wall-clock time is in
+func scheduleLocation(schedule *models.Schedule) (*time.Location, error) {
+	timezone := defaultTimezone
+	if schedule.Series != nil && schedule.Series.Timezone != "" {
+		timezone = schedule.Series.Timezone
+	}
+	return time.LoadLocation(timezone)
+}
+
+// scheduleStart is the instant a schedule begins
+func scheduleStart(schedule *models.Schedule) (time.Time, error) {
+	loc, err := scheduleLocation(schedule)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	hour, minute, err := parseTimeOfDay(schedule.Time)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(schedule.Date.Year(), schedule.Date.Month(), schedule.Date.Day(), hour, minute, 0, 0, loc), nil
+}
+
+func scheduleEventDescription(schedule *models.Schedule) string {
+	parts := make([]string, 0, 3)
+	if schedule.Description != nil && *schedule.Description != "" {
+		parts = append(parts, *schedule.Description)
+	}
+	if schedule.SessionType != nil && *schedule.SessionType != "" {
+		parts = append(parts, "Session type: "+*schedule.SessionType)
+	}
+	if len(schedule.PlannedExercises) > 0 {
+		parts = append(parts, "Planned exercises: "+strings.Join(schedule.PlannedExercises, ", "))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// calendarStatus maps a schedule status to a VEVENT status
+func calendarStatus(status string) string {
+	switch status {
+	case "cancelled":
+		return ical.StatusCancelled
+	case "scheduled":
+		return ical.StatusTentative
+	default:
+		return ical.StatusConfirmed
+	}
+}
+
+// ==========================================
+// IMPORT
+// ==========================================
+
+// ImportSchedules creates schedules from an exported calendar. Events that
+// clash with existing sessions are reported as conflicts and not created;
+// the rest are imported.
+func (s *calendarService) ImportSchedules(userID uint, req *dto.ImportSchedulesRequest, data io.Reader) (*dto.ImportSchedulesResponse, error) {
+	trainer, err := s.trainerRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	timezone := req.Timezone
+	if timezone == "" {
+		timezone = defaultTimezone
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown timezone %q", apperrors.ErrInvalidInput, timezone)
+	}
+
+	events, err := ical.Parse(data, loc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", apperrors.ErrInvalidInput, err)
+	}
+	if len(events) > maxImportEvents {
+		return nil, fmt.Errorf("%w: a calendar can contain at most %d events", apperrors.ErrInvalidInput, maxImportEvents)
+	}
+
+	clients, err := s.trainerRepo.GetClients(trainer.ID)
+	if err != nil {
+		return nil, err
+	}
+	clientsByID := make(map[uint]*models.Trainee, len(clients))
+	clientsByEmail := make(map[string]*models.Trainee, len(clients))
+	for i := range clients {
+		clientsByID[clients[i].ID] = &clients[i]
+		clientsByEmail[strings.ToLower(clients[i].User.Email)] = &clients[i]
+	}
+
+	var defaultClient *models.Trainee
+	if req.TraineeID != nil {
+		client, ok := clientsByID[*req.TraineeID]
+		if !ok {
+			return nil, apperrors.ErrClientNotAssigned
+		}
+		defaultClient = client
+	}
+
+	resp := &dto.ImportSchedulesResponse{
+		DryRun:    req.DryRun,
+		Total:     len(events),
+		Created:   []dto.ScheduleResponse{},
+		Conflicts: []dto.ScheduleImportIssue{},
+		Skipped:   []dto.ScheduleImportIssue{},
+	}
+	touched := map[uint]bool{}
+
+	err = database.Transaction(func(tx *gorm.DB) error {
+		scheduleRepo := repository.NewScheduleRepository(tx)
+		seen := map[string]bool{}
+
+		for i := range events {
+			event := &events[i]
+			start := event.Start
+			issue := dto.ScheduleImportIssue{UID: event.UID, Summary: event.Summary, Start: &start}
+
+			if reason := unsupportedEventReason(event); reason != "" {
+				issue.Reason = reason
+				resp.Skipped = append(resp.Skipped, issue)
+				continue
+			}
+
+			client := defaultClient
+			if client == nil {
+				client = matchAttendee(event.Attendees, clientsByEmail)
+			}
+			if client == nil {
+				issue.Reason = "no attendee matches one of your clients"
+				resp.Skipped = append(resp.Skipped, issue)
+				continue
+			}
+
+			if event.UID != "" {
+				exists, err := scheduleRepo.ExistsByExternalUID(trainer.ID, event.UID)
+				if err != nil {
+					return err
+				}
+				if exists || seen[event.UID] {
+					issue.Reason = "event was already imported"
+					resp.Skipped = append(resp.Skipped, issue)
+					continue
+				}
+				seen[event.UID] = true
+			}
+
+			local := event.Start.In(loc)
+			date := localDate(local, loc)
+			clock := local.Format("15:04")
+			duration := int(event.End.Sub(event.Start).Minutes())
+
+			conflict, err := scheduleRepo.CheckConflict(trainer.ID, date, clock, duration, nil)
+			if err != nil {
+				return err
+			}
+			if conflict {
+				issue.Reason = "overlaps an existing session"
+				resp.Conflicts = append(resp.Conflicts, issue)
+				continue
+			}
+
+			schedule := &models.Schedule{
+				TrainerID: trainer.ID,
+				TraineeID: client.ID,
+				Date:      date,
+				Time:      clock,
+				Duration:  duration,
+				Title:     event.Summary,
+				Status:    "scheduled",
+			}
+			if schedule.Title == "" {
+				schedule.Title = "Training session"
+			}
+			if event.Description != "" {
+				description := event.Description
+				schedule.Description = &description
+			}
+			if event.UID != "" {
+				uid := event.UID
+				schedule.ExternalUID = &uid
+			}
+
+			if err := scheduleRepo.Create(schedule); err != nil {
+				return err
+			}
+			touched[client.ID] = true
+
+			schedule.Trainer = *trainer
+			schedule.Trainee = *client
+			created := toScheduleResponse(schedule)
+			if req.DryRun {
+				created.ID = 0 // rolled back below
+			}
+			resp.Created = append(resp.Created, created)
+		}
+
+		if req.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+
+	if !req.DryRun {
+		for traineeID := range touched {
+			if err := s.traineeRepo.UpdateStats(traineeID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return resp, nil
+}
+
+// unsupportedEventReason explains why an event cannot become a schedule
+func unsupportedEventReason(event *ical.Event) string {
+	switch {
+	case event.Status == ical.StatusCancelled:
+		return "event is cancelled"
+	case event.RRule != "":
+		return "recurring events are not supported; export individual occurrences or create a schedule series"
+	case event.AllDay:
+		return "all-day events have no session time"
+	case !event.End.After(event.Start):
+		return "event has no duration"
+	case event.End.Sub(event.Start) > 24*time.Hour:
+		return "event is longer than a day"
+	}
+	return ""
+}
+
+// matchAttendee finds the client invited to an event
+func matchAttendee(attendees []string, clientsByEmail map[string]*models.Trainee) *models.Trainee {
+	for _, email := range attendees {
+		if client, ok := clientsByEmail[email]; ok {
+			return client
+		}
+	}
+	return nil
+}
+
+// parseTimeOfDay reads "HH:MM" or the "HH:MM:SS" form returned by TIME columns
+func parseTimeOfDay(value string) (int, int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		if t, err = time.Parse("15:04:05", value); err != nil {
+			return 0, 0, err
+		}
+	}
+	return t.Hour(), t.Minute(), nil
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+
+	"fitness-training-backend/internal/config"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+	"fitness-training-backend/pkg/notify"
+)
+
+const (
+	deliveryLease      = 5 * time.Minute // a claimed delivery is retried after this if its worker dies
+	deliveryTimeout    = time.Minute
+	deliveryBackoff    = 30 * time.Second
+	maxDeliveryBackoff = time.Hour
+)
+
+// errNoRecipient means the user has no address for a channel
+var errNoRecipient = errors.New("no recipient address")
+
+// NewDeliverySenders creates a sender for every configured channel
+func NewDeliverySenders(cfg config.DeliveryConfig) ([]notify.Sender, error) {
+	var senders []notify.Sender
+
+	if cfg.SMTP.Host != "" {
+		senders = append(senders, notify.NewSMTPSender(cfg.SMTP))
+	}
+	if cfg.WebPush.PrivateKey != "" {
+		push, err := notify.NewWebPushSender(cfg.WebPush)
+		if err != nil {
+			return nil, err
+		}
+		senders = append(senders, push)
+	}
+	if cfg.LINE.ChannelAccessToken != "" {
+		senders = append(senders, notify.NewLINESender(cfg.LINE))
+	}
+
+	return senders, nil
+}
+
+// DeliveryWorker drains the notification outbox, sending each notification
+// through the external channels and retrying failures with exponential
+// backoff. Every API replica may run one; deliveries are claimed with row
+// locks.
+type DeliveryWorker struct {
+	deliveryRepo     repository.NotificationDeliveryRepository
+	subscriptionRepo repository.PushSubscriptionRepository
+	settingsRepo     repository.NotificationSettingsRepository
+	senders          map[string]notify.Sender
+	frontendURL      string
+	interval         time.Duration
+	maxAttempts      int
+	batchSize        int
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewDeliveryWorker creates a worker for the given senders
+func NewDeliveryWorker(
+	deliveryRepo repository.NotificationDeliveryRepository,
+	subscriptionRepo repository.PushSubscriptionRepository,
+	settingsRepo repository.NotificationSettingsRepository,
+	senders []notify.Sender,
+	cfg *config.Config,
+) *DeliveryWorker {
+	byChannel := make(map[string]notify.Sender, len(senders))
+	for _, sender := range senders {
+		byChannel[sender.Channel()] = sender
+	}
+
+	return &DeliveryWorker{
+		deliveryRepo:     deliveryRepo,
+		subscriptionRepo: subscriptionRepo,
+		settingsRepo:     settingsRepo,
+		senders:          byChannel,
+		frontendURL:      strings.TrimRight(cfg.Frontend.URL, "/"),
+		interval:         cfg.Delivery.Interval,
+		maxAttempts:      cfg.Delivery.MaxAttempts,
+		batchSize:        cfg.Delivery.BatchSize,
+	}
+}
+
+// Start runs the worker in the background until Stop is called
+func (w *DeliveryWorker) Start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.done = make(chan struct{})
+
+	go w.run(ctx, w.done)
+}
+
+// Stop ends the background loop and waits for sends in progress
+func (w *DeliveryWorker) Stop() {
+	w.mu.Lock()
+	cancel, done := w.cancel, w.done
+	w.cancel, w.done = nil, nil
+	w.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+func (w *DeliveryWorker) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if digests, err := w.QueueDigests(time.Now()); err != nil {
+			log.Printf("⚠️  Notification digests failed: %v", err)
+		} else if digests > 0 {
+			log.Printf("📨 Queued %d daily digest(s)", digests)
+		}
+
+		if sent, err := w.DeliverDue(ctx, time.Now()); err != nil {
+			log.Printf("⚠️  Notification delivery failed: %v", err)
+		} else if sent > 0 {
+			log.Printf("📨 Delivered %d notification(s)", sent)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue sends every delivery due at now, a batch at a time, and returns
+// how many were sent
+func (w *DeliveryWorker) DeliverDue(ctx context.Context, now time.Time) (int, error) {
+	sent := 0
+	for ctx.Err() == nil {
+		deliveries, err := w.deliveryRepo.ClaimDue(now, deliveryLease, w.batchSize)
+		if err != nil {
+			return sent, err
+		}
+
+		for i := range deliveries {
+			ok, err := w.deliver(ctx, &deliveries[i], now)
+			if err != nil {
+				log.Printf("⚠️  Delivery %d could not be updated: %v", deliveries[i].ID, err)
+			}
+			if ok {
+				sent++
+			}
+		}
+
+		if len(deliveries) < w.batchSize {
+			break
+		}
+	}
+	return sent, nil
+}
+
+// deliver sends one delivery and records the outcome. It reports whether the
+// notification was sent.
+func (w *DeliveryWorker) deliver(ctx context.Context, delivery *models.NotificationDelivery, now time.Time) (bool, error) {
+	notification := &delivery.Notification
+	if notification.ID == 0 {
+		return false, w.deliveryRepo.MarkSkipped(delivery.ID, "notification deleted")
+	}
+	if !notification.User.IsActive {
+		return false, w.deliveryRepo.MarkSkipped(delivery.ID, "user inactive")
+	}
+
+	sender, ok := w.senders[delivery.Channel]
+	if !ok {
+		return false, w.deliveryRepo.MarkSkipped(delivery.ID, "channel not configured")
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+	defer cancel()
+
+	err := w.send(sendCtx, sender, delivery, w.message(notification))
+	switch {
+	case err == nil:
+		return true, w.deliveryRepo.MarkSent(delivery, time.Now())
+	case errors.Is(err, errNoRecipient):
+		return false, w.deliveryRepo.MarkSkipped(delivery.ID, err.Error())
+	case notify.IsPermanent(err) || delivery.Attempts >= w.maxAttempts:
+		log.Printf("⚠️  Giving up on %s delivery %d after %d attempt(s): %v", delivery.Channel, delivery.ID, delivery.Attempts, err)
+		return false, w.deliveryRepo.MarkFailed(delivery.ID, err.Error())
+	default:
+		return false, w.deliveryRepo.MarkRetry(delivery.ID, now.Add(retryBackoff(delivery.Attempts)), err.Error())
+	}
+}
+
+// send resolves the user's address for the channel and sends the message
+func (w *DeliveryWorker) send(ctx context.Context, sender notify.Sender, delivery *models.NotificationDelivery, msg notify.Message) error {
+	user := &delivery.Notification.User
+
+	switch delivery.Channel {
+	case notify.ChannelEmail:
+		if user.Email == "" {
+			return errNoRecipient
+		}
+		return sender.Send(ctx, (&mail.Address{Name: user.Name, Address: user.Email}).String(), msg)
+	case notify.ChannelLINE:
+		if user.LineUserID == nil || *user.LineUserID == "" {
+			return errNoRecipient
+		}
+		return sender.Send(ctx, *user.LineUserID, msg)
+	case notify.ChannelPush:
+		return w.sendPush(ctx, sender, user.ID, msg)
+	default:
+		return fmt.Errorf("%w: unknown channel %q", notify.ErrPermanent, delivery.Channel)
+	}
+}
+
+// sendPush sends to every browser the user subscribed. Subscriptions the push
+// service reports gone are removed. Reaching any browser counts as sent, so a
+// retry never shows the notification twice on the others.
+func (w *DeliveryWorker) sendPush(ctx context.Context, sender notify.Sender, userID uint, msg notify.Message) error {
+	subscriptions, err := w.subscriptionRepo.FindByUserID(userID)
+	if err != nil {
+		return err
+	}
+	if len(subscriptions) == 0 {
+		return errNoRecipient
+	}
+
+	delivered := false
+	var lastErr error
+	for _, subscription := range subscriptions {
+		var to notify.PushSubscription
+		to.Endpoint = subscription.Endpoint
+		to.Keys.P256dh = subscription.P256dh
+		to.Keys.Auth = subscription.Auth
+		payload, err := json.Marshal(to)
+		if err != nil {
+			return err
+		}
+
+		err = sender.Send(ctx, string(payload), msg)
+		if errors.Is(err, notify.ErrGone) {
+			if err := w.subscriptionRepo.Delete(subscription.ID); err != nil {
+				log.Printf("⚠️  Could not remove push subscription %d: %v", subscription.ID, err)
+			}
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		delivered = true
+	}
+
+	if delivered {
+		return nil
+	}
+	return lastErr
+}
+
+// message renders a notification for external channels; relative action URLs
+// point into the frontend
+func (w *DeliveryWorker) message(notification *models.Notification) notify.Message {
+	msg := notify.Message{
+		Title: notification.Title,
+		Body:  notification.Message,
+		Tag:   notification.Type,
+	}
+	if notification.RelatedType != nil && notification.RelatedID != nil {
+		msg.Tag = fmt.Sprintf("%s-%d", *notification.RelatedType, *notification.RelatedID)
+	}
+	if notification.ActionURL != nil && *notification.ActionURL != "" {
+		msg.URL = *notification.ActionURL
+		if strings.HasPrefix(msg.URL, "/") {
+			msg.URL = w.frontendURL + msg.URL
+		}
+	}
+	return msg
+}
+
+// retryBackoff doubles the wait after every attempt (30s, 1m, 2m, ...) up to
+// an hour, with up to 20% jitter so failed sends do not retry in lockstep
+func retryBackoff(attempts int) time.Duration {
+	wait := deliveryBackoff
+	for i := 1; i < attempts && wait < maxDeliveryBackoff; i++ {
+		wait *= 2
+	}
+	if wait > maxDeliveryBackoff {
+		wait = maxDeliveryBackoff
+	}
+	return wait + time.Duration(rand.Int63n(int64(wait/5)+1))
+}
@@ -0,0 +1,767 @@
+package service
+
+import (
+	"errors"
+
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/models"
+	apperrors "fitness-training-backend/pkg/errors"
+	"fitness-training-backend/pkg/notify"
+	"fitness-training-backend/pkg/stats"
+	"fitness-training-backend/pkg/units"
+
+	"gorm.io/gorm"
+)
+
+// ==========================================
+// MODEL -> DTO MAPPERS
+// Shared by all services
+// ==========================================
+
+// notFound converts gorm's record-not-found into the app-level error
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperrors.ErrNotFound
+	}
+	return err
+}
+
+func toUserInfo(user *models.User) *dto.UserInfo {
+	info := &dto.UserInfo{
+		ID:            user.ID,
+		Email:         user.Email,
+		Name:          user.Name,
+		Role:          user.Role,
+		ProfileImage:  user.ProfileImage,
+		PhoneNumber:   user.PhoneNumber,
+		DateOfBirth:   user.DateOfBirth,
+		Gender:        user.Gender,
+		OAuthProvider: user.OAuthProvider,
+		EmailVerified: user.EmailVerified,
+		LastLoginAt:   user.LastLoginAt,
+		Units:         toUnitPreferences(user.Units()),
+		CreatedAt:     user.CreatedAt,
+	}
+
+	if user.Trainer != nil {
+		info.Trainer = toTrainerInfo(user.Trainer)
+	}
+	if user.Trainee != nil {
+		info.Trainee = toTraineeInfo(user.Trainee)
+	}
+
+	return info
+}
+
+func toTrainerInfo(trainer *models.Trainer) *dto.TrainerInfo {
+	return &dto.TrainerInfo{
+		ID:              trainer.ID,
+		Bio:             trainer.Bio,
+		Specialization:  trainer.Specialization,
+		Certifications:  trainer.Certifications,
+		ExperienceYears: trainer.ExperienceYears,
+		Rating:          trainer.Rating,
+		TotalClients:    trainer.TotalClients,
+		Availability:    trainer.Availability,
+	}
+}
+
+func toTraineeInfo(trainee *models.Trainee) *dto.TraineeInfo {
+	info := &dto.TraineeInfo{
+		ID:                trainee.ID,
+		MemberID:          trainee.MemberID,
+		Height:            trainee.Height,
+		Weight:            trainee.Weight,
+		Goals:             trainee.Goals,
+		FitnessLevel:      trainee.FitnessLevel,
+		TotalSessions:     trainee.TotalSessions,
+		CompletedSessions: trainee.CompletedSessions,
+		CurrentStreak:     stats.DailyStreakAsOf(trainee.CurrentStreak, trainee.LastSessionDate, stats.Today(models.DefaultTimezone)),
+		PreferredWeekdays: toWeekdayInts(trainee.PreferredWeekdays),
+		PreferredTime:     trainee.PreferredTime,
+	}
+
+	if trainee.Trainer != nil && trainee.Trainer.User.ID != 0 {
+		name := trainee.Trainer.User.Name
+		info.TrainerName = &name
+	}
+
+	return info
+}
+
+// toClientInfo builds the user info of a trainee including the trainee profile
+func toClientInfo(trainee *models.Trainee) dto.UserInfo {
+	info := toUserInfo(&trainee.User)
+	info.Trainee = toTraineeInfo(trainee)
+	return *info
+}
+
+func toTrainerPublicResponse(trainer *models.Trainer) dto.TrainerPublicResponse {
+	return dto.TrainerPublicResponse{
+		ID:              trainer.ID,
+		Name:            trainer.User.Name,
+		ProfileImage:    trainer.User.ProfileImage,
+		Bio:             trainer.Bio,
+		Specialization:  trainer.Specialization,
+		Certifications:  trainer.Certifications,
+		ExperienceYears: trainer.ExperienceYears,
+		Rating:          trainer.Rating,
+		TotalRatings:    trainer.TotalRatings,
+		TotalClients:    trainer.TotalClients,
+		Availability:    trainer.Availability,
+		InstagramURL:    trainer.InstagramURL,
+		FacebookURL:     trainer.FacebookURL,
+		YoutubeURL:      trainer.YoutubeURL,
+	}
+}
+
+func toScheduleResponse(schedule *models.Schedule) dto.ScheduleResponse {
+	resp := dto.ScheduleResponse{
+		ID:           schedule.ID,
+		Date:         schedule.Date,
+		Time:         schedule.Time,
+		Duration:     schedule.Duration,
+		Title:        schedule.Title,
+		Description:  schedule.Description,
+		Status:       schedule.Status,
+		SessionType:  schedule.SessionType,
+		SeriesID:     schedule.SeriesID,
+		IsException:  schedule.IsException,
+		ProgramDayID: schedule.ProgramDayID,
+		Notes:        schedule.Notes,
+		CreatedAt:    schedule.CreatedAt,
+	}
+
+	resp.Trainer.ID = schedule.TrainerID
+	resp.Trainer.Name = schedule.Trainer.User.Name
+	resp.Trainer.ProfileImage = schedule.Trainer.User.ProfileImage
+
+	if schedule.Trainee.User.ID != 0 {
+		resp.Trainee = &dto.ScheduleTraineeInfo{
+			ID:           schedule.TraineeID,
+			Name:         schedule.Trainee.User.Name,
+			ProfileImage: schedule.Trainee.User.ProfileImage,
+		}
+	}
+
+	if schedule.Location != nil {
+		resp.Location = &struct {
+			ID      uint    `json:"id"`
+			Name    string  `json:"name"`
+			Address *string `json:"address"`
+			Floor   *string `json:"floor"`
+		}{
+			ID:      schedule.Location.ID,
+			Name:    schedule.Location.Name,
+			Address: schedule.Location.Address,
+			Floor:   schedule.Location.Floor,
+		}
+	}
+
+	if schedule.ProgramAssignment != nil && schedule.ProgramAssignment.Program.ID != 0 {
+		name := schedule.ProgramAssignment.Program.Name
+		resp.ProgramName = &name
+	}
+
+	return resp
+}
+
+func toScheduleResponses(schedules []models.Schedule) []dto.ScheduleResponse {
+	responses := make([]dto.ScheduleResponse, 0, len(schedules))
+	for i := range schedules {
+		responses = append(responses, toScheduleResponse(&schedules[i]))
+	}
+	return responses
+}
+
+func toScheduleSeriesResponse(series *models.ScheduleSeries, occurrences []models.Schedule) dto.ScheduleSeriesResponse {
+	resp := dto.ScheduleSeriesResponse{
+		ID:               series.ID,
+		LocationID:       series.LocationID,
+		Frequency:        series.Frequency,
+		Weekdays:         toWeekdayInts(series.Weekdays),
+		StartDate:        series.StartDate,
+		Count:            series.Count,
+		Until:            series.Until,
+		Timezone:         series.Timezone,
+		Time:             series.Time,
+		Duration:         series.Duration,
+		Title:            series.Title,
+		Description:      series.Description,
+		SessionType:      series.SessionType,
+		PlannedExercises: series.PlannedExercises,
+		Status:           series.Status,
+		CreatedAt:        series.CreatedAt,
+	}
+
+	if series.Trainee.User.ID != 0 {
+		resp.Trainee = &dto.ScheduleTraineeInfo{
+			ID:           series.TraineeID,
+			Name:         series.Trainee.User.Name,
+			ProfileImage: series.Trainee.User.ProfileImage,
+		}
+	}
+
+	if occurrences != nil {
+		resp.Occurrences = toScheduleResponses(occurrences)
+	}
+
+	return resp
+}
+
+func toTimeOffResponse(timeOff *models.TrainerTimeOff) dto.TimeOffResponse {
+	return dto.TimeOffResponse{
+		ID:         timeOff.ID,
+		StartAt:    timeOff.StartAt,
+		EndAt:      timeOff.EndAt,
+		LocationID: timeOff.LocationID,
+		Reason:     timeOff.Reason,
+	}
+}
+
+func toBookingRequestResponse(request *models.BookingRequest) dto.BookingRequestResponse {
+	resp := dto.BookingRequestResponse{
+		ID:           request.ID,
+		Status:       request.Status,
+		Date:         request.Date,
+		Time:         trimSeconds(request.Time),
+		Duration:     request.Duration,
+		LocationID:   request.LocationID,
+		Notes:        request.Notes,
+		ResponseNote: request.ResponseNote,
+		RespondedAt:  request.RespondedAt,
+		ScheduleID:   request.ScheduleID,
+		CreatedAt:    request.CreatedAt,
+	}
+
+	if request.Trainer.User.ID != 0 {
+		resp.Trainer = &dto.ParticipantInfo{
+			ID:           request.TrainerID,
+			Name:         request.Trainer.User.Name,
+			ProfileImage: request.Trainer.User.ProfileImage,
+		}
+	}
+	if request.Trainee.User.ID != 0 {
+		resp.Trainee = &dto.ParticipantInfo{
+			ID:           request.TraineeID,
+			Name:         request.Trainee.User.Name,
+			ProfileImage: request.Trainee.User.ProfileImage,
+		}
+	}
+
+	return resp
+}
+
+func toBookingRequestResponses(requests []models.BookingRequest) []dto.BookingRequestResponse {
+	responses := make([]dto.BookingRequestResponse, 0, len(requests))
+	for i := range requests {
+		responses = append(responses, toBookingRequestResponse(&requests[i]))
+	}
+	return responses
+}
+
+func toProgramResponse(program *models.Program) dto.ProgramResponse {
+	resp := dto.ProgramResponse{
+		ID:                 program.ID,
+		Name:               program.Name,
+		Description:        program.Description,
+		TotalWeeks:         program.TotalWeeks,
+		SessionsPerWeek:    program.SessionsPerWeek,
+		Goals:              program.Goals,
+		TargetFitnessLevel: program.TargetFitnessLevel,
+		CreatedAt:          program.CreatedAt,
+	}
+
+	resp.Trainer.ID = program.TrainerID
+	resp.Trainer.Name = program.Trainer.User.Name
+	resp.Trainer.ProfileImage = program.Trainer.User.ProfileImage
+
+	return resp
+}
+
+func toTrainerProgramResponse(program *models.Program) dto.TrainerProgramResponse {
+	return dto.TrainerProgramResponse{
+		ProgramResponse:      toProgramResponse(program),
+		Status:               program.Status,
+		Version:              program.Version,
+		TotalAssignments:     program.TotalAssignments,
+		CompletionRate:       program.CompletionRate,
+		AdherenceThreshold:   program.AdherenceThreshold,
+		ProgressionScheme:    program.ProgressionScheme,
+		ProgressionIncrement: program.ProgressionIncrement,
+		Visibility:           program.Visibility,
+		CloneCount:           program.CloneCount,
+		ClonedFrom:           toProgramAttribution(program),
+		UpdatedAt:            program.UpdatedAt,
+	}
+}
+
+func toCatalogProgramResponse(program *models.Program, equipment []string) dto.CatalogProgramResponse {
+	if equipment == nil {
+		equipment = []string{}
+	}
+	return dto.CatalogProgramResponse{
+		ProgramResponse:  toProgramResponse(program),
+		Visibility:       program.Visibility,
+		Version:          program.Version,
+		Equipment:        equipment,
+		TotalAssignments: program.TotalAssignments,
+		CompletionRate:   program.CompletionRate,
+		CloneCount:       program.CloneCount,
+		ClonedFrom:       toProgramAttribution(program),
+		UpdatedAt:        program.UpdatedAt,
+	}
+}
+
+// toProgramAttribution credits the source of a cloned program; nil for
+// originals
+func toProgramAttribution(program *models.Program) *dto.ProgramAttributionResponse {
+	if program.ClonedFromID == nil && program.OriginalAuthorID == nil {
+		return nil
+	}
+
+	attribution := &dto.ProgramAttributionResponse{
+		ProgramID: program.ClonedFromID,
+		Version:   program.ClonedFromVersion,
+	}
+	if author := program.OriginalAuthor; author != nil {
+		attribution.Author = &dto.ParticipantInfo{
+			ID:           author.ID,
+			Name:         author.User.Name,
+			ProfileImage: author.User.ProfileImage,
+		}
+	}
+	return attribution
+}
+
+func toProgramVersionResponse(version *models.ProgramVersion, current int) dto.ProgramVersionResponse {
+	return dto.ProgramVersionResponse{
+		Version:              version.Version,
+		Current:              version.Version == current,
+		Name:                 version.Name,
+		Description:          version.Description,
+		TotalWeeks:           version.TotalWeeks,
+		SessionsPerWeek:      version.SessionsPerWeek,
+		Goals:                version.Goals,
+		TargetFitnessLevel:   version.TargetFitnessLevel,
+		AdherenceThreshold:   version.AdherenceThreshold,
+		ProgressionScheme:    version.ProgressionScheme,
+		ProgressionIncrement: version.ProgressionIncrement,
+		TotalAssignments:     version.TotalAssignments,
+		CompletionRate:       version.CompletionRate,
+		CreatedAt:            version.CreatedAt,
+	}
+}
+
+func toProgramAssignmentResponse(assignment *models.ProgramAssignment) *dto.ProgramAssignmentResponse {
+	resp := &dto.ProgramAssignmentResponse{
+		ID:                 assignment.ID,
+		StartDate:          assignment.StartDate,
+		EndDate:            assignment.EndDate,
+		CurrentWeek:        assignment.CurrentWeek,
+		ProgressPercentage: assignment.ProgressPercentage,
+		SessionsCompleted:  assignment.SessionsCompleted,
+		TotalSessions:      assignment.TotalSessions,
+		Status:             assignment.Status,
+		Notes:              assignment.Notes,
+		ProgramVersion:     assignment.ProgramVersion.Version,
+		AdherenceRate:      assignment.AdherenceRate,
+		BehindPlan:         assignment.BehindPlan,
+		BehindPlanSince:    assignment.BehindPlanSince,
+	}
+	for i := range assignment.ProgressNotes {
+		resp.ProgressNotes = append(resp.ProgressNotes, toProgressNoteResponse(&assignment.ProgressNotes[i]))
+	}
+	return resp
+}
+
+func toProgressNoteResponse(note *models.ProgramProgressNote) dto.ProgressNoteResponse {
+	return dto.ProgressNoteResponse{
+		ID:         note.ID,
+		Week:       note.Week,
+		Date:       note.Date,
+		Note:       note.Note,
+		RecordedBy: note.RecordedBy,
+		CreatedAt:  note.CreatedAt,
+	}
+}
+
+func toProgramDayResponse(day *models.ProgramDay, prefs units.Preferences) dto.ProgramDayResponse {
+	resp := dto.ProgramDayResponse{
+		ID:        day.ID,
+		Week:      day.Week,
+		Day:       day.Day,
+		Name:      day.Name,
+		Weekday:   day.Weekday,
+		Duration:  day.Duration,
+		Notes:     day.Notes,
+		Exercises: make([]dto.PlannedExerciseResponse, 0, len(day.Exercises)),
+	}
+	for _, exercise := range day.Exercises {
+		planned := dto.PlannedExerciseResponse{
+			ID:                exercise.ID,
+			ExerciseLibraryID: exercise.ExerciseLibraryID,
+			Order:             exercise.Order,
+			Notes:             exercise.Notes,
+			RestDuration:      exercise.RestDuration,
+			Sets:              make([]dto.PrescribedSetResponse, 0, len(exercise.Sets)),
+		}
+		if exercise.ExerciseLibrary != nil {
+			planned.ExerciseName = exercise.ExerciseLibrary.Name
+			planned.Category = exercise.ExerciseLibrary.Category
+		}
+		for _, set := range exercise.Sets {
+			planned.Sets = append(planned.Sets, dto.PrescribedSetResponse{
+				SetNumber:    set.SetNumber,
+				Reps:         set.Reps,
+				RepsMax:      set.RepsMax,
+				Duration:     set.Duration,
+				Distance:     displayPtr(set.Distance, prefs.For(units.Distance)),
+				Weight:       displayPtr(set.Weight, prefs.For(units.Mass)),
+				PercentOneRM: set.PercentOneRM,
+				RPE:          set.RPE,
+			})
+		}
+		resp.Exercises = append(resp.Exercises, planned)
+	}
+	return resp
+}
+
+// toAssignedProgramResponse maps an assignment to the program version it is
+// pinned to
+func toAssignedProgramResponse(assignment *models.ProgramAssignment) dto.ProgramResponse {
+	program := assignment.Program
+	applyVersionContent(&program, &assignment.ProgramVersion)
+	resp := toProgramResponse(&program)
+	resp.Assignment = toProgramAssignmentResponse(assignment)
+	return resp
+}
+
+func toSessionCardResponse(card *models.SessionCard, prefs units.Preferences) dto.SessionCardResponse {
+	weightUnit := prefs.For(units.Mass)
+	resp := dto.SessionCardResponse{
+		ID:               card.ID,
+		Date:             card.Date,
+		Title:            card.Title,
+		Duration:         card.Duration,
+		OverallFeedback:  card.OverallFeedback,
+		NextSessionGoals: card.NextSessionGoals,
+		Source:           card.Source,
+		TotalExercises:   card.TotalExercises,
+		TotalSets:        card.TotalSets,
+		TotalVolume:      displayValue(card.TotalVolume, weightUnit),
+		WeightUnit:       string(weightUnit),
+		DistanceUnit:     string(prefs.For(units.Distance)),
+		TrainerRating:    card.TrainerRating,
+		TraineeRating:    card.TraineeRating,
+		Exercises:        make([]dto.SessionExerciseResponse, 0, len(card.Exercises)),
+		CreatedAt:        card.CreatedAt,
+	}
+
+	resp.Trainer.ID = card.TrainerID
+	resp.Trainer.Name = card.Trainer.User.Name
+	resp.Trainer.ProfileImage = card.Trainer.User.ProfileImage
+
+	for i := range card.Exercises {
+		resp.Exercises = append(resp.Exercises, toSessionExerciseResponse(&card.Exercises[i], prefs))
+	}
+
+	return resp
+}
+
+func toSessionCardResponses(cards []models.SessionCard, prefs units.Preferences) []dto.SessionCardResponse {
+	responses := make([]dto.SessionCardResponse, 0, len(cards))
+	for i := range cards {
+		responses = append(responses, toSessionCardResponse(&cards[i], prefs))
+	}
+	return responses
+}
+
+func toSessionExerciseResponse(exercise *models.SessionExercise, prefs units.Preferences) dto.SessionExerciseResponse {
+	weightUnit := prefs.For(units.Mass)
+	distanceUnit := prefs.For(units.Distance)
+	resp := dto.SessionExerciseResponse{
+		ID:            exercise.ID,
+		Name:          exercise.Name,
+		Category:      exercise.Category,
+		ExerciseOrder: exercise.ExerciseOrder,
+		Notes:         exercise.Notes,
+		FormNotes:     exercise.FormNotes,
+		TotalSets:     exercise.TotalSets,
+		TotalReps:     exercise.TotalReps,
+		TotalWeight:   displayValue(exercise.TotalWeight, weightUnit),
+		TotalVolume:   displayValue(exercise.TotalVolume, weightUnit),
+		TotalDuration: exercise.TotalDuration,
+		TotalDistance: displayValue(exercise.TotalDistance, distanceUnit),
+		Pace:          cardioPace(exercise.TotalDuration, exercise.TotalDistance, distanceUnit),
+		AvgHeartRate:  exercise.AvgHeartRate,
+		MaxHeartRate:  exercise.MaxHeartRate,
+		IsPR:          exercise.IsPR,
+		PRNote:        exercise.PRNote,
+		Sets:          make([]dto.ExerciseSetResponse, 0, len(exercise.Sets)),
+	}
+	if len(exercise.HeartRateZones) > 0 {
+		resp.HeartRateZones = exercise.HeartRateZones
+	}
+
+	for _, set := range exercise.Sets {
+		resp.Sets = append(resp.Sets, dto.ExerciseSetResponse{
+			ID:           set.ID,
+			SetNumber:    set.SetNumber,
+			Reps:         set.Reps,
+			Weight:       displayPtr(set.Weight, weightUnit),
+			Duration:     set.Duration,
+			Distance:     displayPtr(set.Distance, distanceUnit),
+			Pace:         setPace(&set, distanceUnit),
+			AvgHeartRate: set.AvgHeartRate,
+			MaxHeartRate: set.MaxHeartRate,
+			RestDuration: set.RestDuration,
+			Completed:    set.Completed,
+			RPE:          set.RPE,
+			Notes:        set.Notes,
+		})
+	}
+
+	return resp
+}
+
+// toMetricResponse shows the metric in the preferred unit of its quantity.
+// Rows not in the canonical unit are shown as they were recorded.
+func toMetricResponse(metric *models.Metric, prefs units.Preferences) dto.MetricResponse {
+	resp := dto.MetricResponse{
+		ID:              metric.ID,
+		Date:            metric.Date,
+		Type:            metric.Type,
+		Value:           metric.Value,
+		Unit:            metric.Unit,
+		MeasurementType: metric.MeasurementType,
+		Notes:           metric.Notes,
+		CreatedAt:       metric.CreatedAt,
+	}
+	if kind := metricKind(metric.Type); kind != "" && metric.Unit == string(units.Canonical(kind)) {
+		unit := prefs.For(kind)
+		resp.Value = displayValue(metric.Value, unit)
+		resp.Unit = string(unit)
+	}
+
+	if metric.Recorder != nil {
+		name := metric.Recorder.Name
+		resp.RecordedBy = &name
+	}
+
+	return resp
+}
+
+func toMetricResponses(metrics []models.Metric, prefs units.Preferences) []dto.MetricResponse {
+	responses := make([]dto.MetricResponse, 0, len(metrics))
+	for i := range metrics {
+		responses = append(responses, toMetricResponse(&metrics[i], prefs))
+	}
+	return responses
+}
+
+func toNotificationResponse(notification *models.Notification) dto.NotificationResponse {
+	return dto.NotificationResponse{
+		ID:          notification.ID,
+		Type:        notification.Type,
+		Title:       notification.Title,
+		Message:     notification.Message,
+		RelatedID:   notification.RelatedID,
+		RelatedType: notification.RelatedType,
+		ActionURL:   notification.ActionURL,
+		Priority:    notification.Priority,
+		IsRead:      notification.IsRead,
+		ReadAt:      notification.ReadAt,
+		CreatedAt:   notification.CreatedAt,
+	}
+}
+
+func toExerciseLibraryResponse(exercise *models.ExerciseLibrary) dto.ExerciseLibraryResponse {
+	resp := dto.ExerciseLibraryResponse{
+		ID:           exercise.ID,
+		Name:         exercise.Name,
+		Category:     exercise.Category,
+		Description:  exercise.Description,
+		MuscleGroups: exercise.MuscleGroups,
+		Equipment:    exercise.Equipment,
+		Difficulty:   exercise.Difficulty,
+		Instructions: exercise.Instructions,
+		VideoURL:     exercise.VideoURL,
+		ThumbnailURL: exercise.ThumbnailURL,
+		Images:       exercise.Images,
+		IsPublic:     exercise.IsPublic,
+		UsageCount:   exercise.UsageCount,
+		CreatedAt:    exercise.CreatedAt,
+	}
+
+	if exercise.Trainer != nil {
+		resp.Creator = &struct {
+			ID   uint   `json:"id"`
+			Name string `json:"name"`
+		}{
+			ID:   exercise.Trainer.ID,
+			Name: exercise.Trainer.User.Name,
+		}
+	}
+
+	return resp
+}
+
+func toAchievementResponse(achievement *models.Achievement) dto.AchievementResponse {
+	return dto.AchievementResponse{
+		ID:          achievement.ID,
+		Type:        achievement.Type,
+		Title:       achievement.Title,
+		Description: achievement.Description,
+		BadgeIcon:   achievement.BadgeIcon,
+		BadgeColor:  achievement.BadgeColor,
+		Value:       achievement.Value,
+		AchievedAt:  achievement.AchievedAt,
+	}
+}
+
+func toAchievementResponses(achievements []models.Achievement) []dto.AchievementResponse {
+	resp := make([]dto.AchievementResponse, 0, len(achievements))
+	for i := range achievements {
+		resp = append(resp, toAchievementResponse(&achievements[i]))
+	}
+	return resp
+}
+
+func toAchievementRuleResponse(rule *models.AchievementRule) dto.AchievementRuleResponse {
+	r := customAchievementRule(rule)
+	return dto.AchievementRuleResponse{
+		ID:                rule.ID,
+		Key:               r.Key,
+		TraineeID:         rule.TraineeID,
+		Type:              r.Type,
+		Title:             rule.Title,
+		Description:       rule.Description,
+		Metric:            rule.Metric,
+		Threshold:         rule.Threshold,
+		ExerciseLibraryID: rule.ExerciseLibraryID,
+		BadgeIcon:         &r.BadgeIcon,
+		BadgeColor:        &r.BadgeColor,
+		IsActive:          rule.IsActive,
+	}
+}
+
+func toBuiltInRuleResponse(rule achievementRule) dto.AchievementRuleResponse {
+	return dto.AchievementRuleResponse{
+		Key:         rule.Key,
+		BuiltIn:     true,
+		Type:        rule.Type,
+		Title:       rule.Title,
+		Description: &rule.Description,
+		Metric:      rule.Metric,
+		Threshold:   float32(rule.Threshold),
+		BadgeIcon:   &rule.BadgeIcon,
+		BadgeColor:  &rule.BadgeColor,
+		IsActive:    true,
+	}
+}
+
+func toNotificationPreferencesResponse(settings *models.NotificationSettings) *dto.NotificationPreferencesResponse {
+	resp := &dto.NotificationPreferencesResponse{
+		Timezone: settings.Timezone,
+		DailyDigest: dto.DailyDigest{
+			Enabled: settings.DailyDigest,
+			Time:    settings.DigestTime,
+		},
+		Types: make(map[string]dto.ChannelPreferences, len(models.NotificationTypes)),
+	}
+	if settings.QuietHoursStart != nil && settings.QuietHoursEnd != nil {
+		resp.QuietHours = dto.QuietHours{Enabled: true, Start: *settings.QuietHoursStart, End: *settings.QuietHoursEnd}
+	}
+	for _, notificationType := range models.NotificationTypes {
+		resp.Types[notificationType] = dto.ChannelPreferences{
+			InApp: settings.Allows(notificationType, channelInApp),
+			Email: settings.Allows(notificationType, notify.ChannelEmail),
+			Push:  settings.Allows(notificationType, notify.ChannelPush),
+			LINE:  settings.Allows(notificationType, notify.ChannelLINE),
+		}
+	}
+	return resp
+}
+
+func toConversationResponse(conversation *models.Conversation) dto.ConversationResponse {
+	return dto.ConversationResponse{
+		ID: conversation.ID,
+		Trainer: dto.ParticipantInfo{
+			ID:           conversation.TrainerID,
+			Name:         conversation.Trainer.User.Name,
+			ProfileImage: conversation.Trainer.User.ProfileImage,
+		},
+		Trainee: dto.ParticipantInfo{
+			ID:           conversation.TraineeID,
+			Name:         conversation.Trainee.User.Name,
+			ProfileImage: conversation.Trainee.User.ProfileImage,
+		},
+		CanSend:       conversationActive(conversation),
+		LastMessageAt: conversation.LastMessageAt,
+		CreatedAt:     conversation.CreatedAt,
+	}
+}
+
+// toMessageResponse maps a message as seen by userID
+func toMessageResponse(message *models.Message, userID uint) dto.MessageResponse {
+	resp := dto.MessageResponse{
+		ID:             message.ID,
+		ConversationID: message.ConversationID,
+		SenderUserID:   message.SenderUserID,
+		Mine:           message.SenderUserID == userID,
+		Body:           message.Body,
+		ScheduleID:     message.ScheduleID,
+		SessionCardID:  message.SessionCardID,
+		Attachments:    make([]dto.MessageAttachmentResponse, 0, len(message.Attachments)),
+		ReadAt:         message.ReadAt,
+		CreatedAt:      message.CreatedAt,
+	}
+	for _, attachment := range message.Attachments {
+		resp.Attachments = append(resp.Attachments, dto.MessageAttachmentResponse{
+			ID:          attachment.ID,
+			URL:         attachment.URL,
+			FileName:    attachment.FileName,
+			ContentType: attachment.ContentType,
+			SizeBytes:   attachment.SizeBytes,
+		})
+	}
+	return resp
+}
+
+// toMediaResponse links a media file and its variants through the stable
+// media URLs under baseURL (…/api/v1/media)
+func toMediaResponse(file *models.MediaFile, baseURL string) dto.MediaResponse {
+	resp := dto.MediaResponse{
+		ID:          file.ID,
+		URL:         mediaURL(baseURL, file.ID, ""),
+		Purpose:     file.Purpose,
+		FileName:    file.FileName,
+		ContentType: file.ContentType,
+		SizeBytes:   file.SizeBytes,
+		Width:       file.Width,
+		Height:      file.Height,
+		Variants:    map[string]string{},
+		CreatedAt:   file.CreatedAt,
+	}
+	for _, variant := range file.Variants {
+		resp.Variants[variant.Variant] = mediaURL(baseURL, file.ID, variant.Variant)
+	}
+	return resp
+}
+
+// newPaginatedResponse wraps a page of data with paging metadata
+func newPaginatedResponse(data interface{}, page, pageSize int, total int64) *dto.PaginatedResponse {
+	totalPages := 0
+	if pageSize > 0 {
+		totalPages = int((total + int64(pageSize) - 1) / int64(pageSize))
+	}
+
+	return &dto.PaginatedResponse{
+		Data:       data,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalItems: total,
+		TotalPages: totalPages,
+	}
+}
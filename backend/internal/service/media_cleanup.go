@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"fitness-training-backend/internal/config"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+	"fitness-training-backend/pkg/storage"
+)
+
+const mediaCleanupBatchSize = 100
+
+// MediaCleanupWorker periodically removes orphaned uploads from storage and
+// the database: media of deleted exercises, locations and users, replaced
+// profile pictures, and uploads never saved anywhere within the grace period
+type MediaCleanupWorker struct {
+	mediaRepo repository.MediaRepository
+	store     storage.Storage
+	interval  time.Duration
+	orphanTTL time.Duration
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewMediaCleanupWorker creates a worker cleaning up at the configured interval
+func NewMediaCleanupWorker(mediaRepo repository.MediaRepository, store storage.Storage, cfg config.MediaConfig) *MediaCleanupWorker {
+	return &MediaCleanupWorker{
+		mediaRepo: mediaRepo,
+		store:     store,
+		interval:  cfg.CleanupInterval,
+		orphanTTL: cfg.OrphanTTL,
+	}
+}
+
+// Start runs the worker in the background until Stop is called
+func (w *MediaCleanupWorker) Start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.done = make(chan struct{})
+
+	go w.run(ctx, w.done)
+}
+
+// Stop ends the background loop and waits for a cleanup in progress
+func (w *MediaCleanupWorker) Stop() {
+	w.mu.Lock()
+	cancel, done := w.cancel, w.done
+	w.cancel, w.done = nil, nil
+	w.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+func (w *MediaCleanupWorker) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if removed, err := w.CleanupOrphans(ctx, time.Now()); err != nil {
+			log.Printf("⚠️  Media cleanup failed: %v", err)
+		} else if removed > 0 {
+			log.Printf("🧹 Removed %d orphaned media file(s)", removed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CleanupOrphans removes orphaned originals with their variants and returns
+// how many were removed. Files whose objects cannot be deleted are kept for
+// the next run.
+func (w *MediaCleanupWorker) CleanupOrphans(ctx context.Context, now time.Time) (int, error) {
+	removed := 0
+	for ctx.Err() == nil {
+		files, err := w.mediaRepo.FindOrphans(now.Add(-w.orphanTTL), mediaCleanupBatchSize)
+		if err != nil {
+			return removed, err
+		}
+
+		ids := make([]uint, 0, len(files))
+		for i := range files {
+			if err := w.deleteObjects(ctx, &files[i]); err != nil {
+				log.Printf("⚠️  Failed to remove media %d: %v", files[i].ID, err)
+				continue
+			}
+			ids = append(ids, files[i].ID)
+		}
+		if err := w.mediaRepo.Delete(ids); err != nil {
+			return removed, err
+		}
+		removed += len(ids)
+
+		// A short batch is the last one; failed files would come back first
+		if len(files) < mediaCleanupBatchSize || len(ids) < len(files) {
+			break
+		}
+	}
+	return removed, nil
+}
+
+// deleteObjects deletes the variants of a file, then the file
+func (w *MediaCleanupWorker) deleteObjects(ctx context.Context, file *models.MediaFile) error {
+	for _, variant := range file.Variants {
+		if err := w.store.Delete(ctx, variant.StorageKey); err != nil {
+			return err
+		}
+	}
+	return w.store.Delete(ctx, file.StorageKey)
+}
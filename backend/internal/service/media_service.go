@@ -0,0 +1,420 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"fitness-training-backend/internal/config"
+	"fitness-training-backend/internal/database"
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+	apperrors "fitness-training-backend/pkg/errors"
+	"fitness-training-backend/pkg/imaging"
+	"fitness-training-backend/pkg/storage"
+
+	"gorm.io/gorm"
+)
+
+// What an upload is for
+const (
+	mediaPurposeExercise = "exercise" // exercise videos, thumbnails and images (trainers)
+	mediaPurposeLocation = "location" // location photos (admins)
+	mediaPurposeProfile  = "profile"  // the uploader's profile picture
+	mediaPurposeMessage  = "message"  // message attachments, sent by their uploader
+)
+
+// mediaTypes are the accepted content types, sniffed from the file itself,
+// with the extension stored files get
+var mediaTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+	"video/mp4":  ".mp4",
+	"video/webm": ".webm",
+}
+
+// mediaVariants are the scaled-down copies made of JPEG, PNG and GIF images,
+// by longest side in pixels
+var mediaVariants = []struct {
+	name    string
+	maxSide int
+}{
+	{"thumb", 200},
+	{"medium", 800},
+}
+
+// mediaPath is where the stable media URLs live, under the public URL
+const mediaPath = "/api/v1/media/"
+
+type MediaService interface {
+	Upload(userID uint, req *dto.UploadMediaRequest, fileName string, file io.ReadSeeker, size int64) (*dto.MediaResponse, error)
+	DownloadURL(userID, mediaID uint, variant string) (string, error)
+}
+
+type mediaService struct {
+	userRepo     repository.UserRepository
+	locationRepo repository.LocationRepository
+	mediaRepo    repository.MediaRepository
+	store        storage.Storage
+	cfg          config.MediaConfig
+	baseURL      string
+}
+
+// NewMediaStorage creates the configured media storage. Local files are
+// served by the API under /api/v1/files.
+func NewMediaStorage(cfg *config.Config) (storage.Storage, error) {
+	if cfg.Media.Storage == "s3" {
+		return storage.NewS3Storage(cfg.Media.S3)
+	}
+	return storage.NewLocalStorage(storage.LocalConfig{
+		Dir:     cfg.Media.LocalDir,
+		BaseURL: cfg.Server.PublicURL + "/api/v1/files",
+		Secret:  cfg.Media.SigningKey,
+	})
+}
+
+func NewMediaService(
+	userRepo repository.UserRepository,
+	locationRepo repository.LocationRepository,
+	mediaRepo repository.MediaRepository,
+	store storage.Storage,
+	cfg *config.Config,
+) MediaService {
+	return &mediaService{
+		userRepo:     userRepo,
+		locationRepo: locationRepo,
+		mediaRepo:    mediaRepo,
+		store:        store,
+		cfg:          cfg.Media,
+		baseURL:      strings.TrimSuffix(cfg.Server.PublicURL+mediaPath, "/"),
+	}
+}
+
+// mediaUpload is a stored object of an upload, kept to undo it on failure
+type mediaUpload struct {
+	file *models.MediaFile
+	body io.ReadSeeker
+}
+
+// Upload checks the file's real type and size, makes the image variants and
+// stores everything. Profile pictures replace the uploader's one and
+// location images are added to the location right away; exercise media are
+// attached when an exercise is saved with their URLs, message attachments
+// when a message is sent with their IDs.
+func (s *mediaService) Upload(userID uint, req *dto.UploadMediaRequest, fileName string, file io.ReadSeeker, size int64) (*dto.MediaResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	switch req.Purpose {
+	case mediaPurposeExercise:
+		if user.Role != "trainer" {
+			return nil, fmt.Errorf("%w: only trainers upload exercise media", apperrors.ErrForbidden)
+		}
+	case mediaPurposeLocation:
+		if !user.IsAdmin() {
+			return nil, fmt.Errorf("%w: only admins upload location images", apperrors.ErrForbidden)
+		}
+		if req.LocationID == nil {
+			return nil, fmt.Errorf("%w: locationId is required for location images", apperrors.ErrInvalidInput)
+		}
+		if _, err := s.locationRepo.FindByID(*req.LocationID); err != nil {
+			return nil, notFound(err)
+		}
+	}
+
+	contentType, err := sniffContentType(file)
+	if err != nil {
+		return nil, err
+	}
+	ext, ok := mediaTypes[contentType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s (accepted: JPEG, PNG, GIF, WebP, MP4, WebM)", apperrors.ErrUnsupportedMedia, contentType)
+	}
+	video := strings.HasPrefix(contentType, "video/")
+	if video && req.Purpose != mediaPurposeExercise && req.Purpose != mediaPurposeMessage {
+		return nil, fmt.Errorf("%w: only exercise media and message attachments can be videos", apperrors.ErrUnsupportedMedia)
+	}
+	limit := s.cfg.MaxImageSize
+	if video {
+		limit = s.cfg.MaxVideoSize
+	}
+	if size > limit {
+		return nil, fmt.Errorf("%w: the limit is %d MB", apperrors.ErrFileTooLarge, limit>>20)
+	}
+
+	name, err := randomMediaName()
+	if err != nil {
+		return nil, err
+	}
+	base := fmt.Sprintf("media/%d/%s", userID, name)
+	original := &models.MediaFile{
+		UserID:      userID,
+		Variant:     "original",
+		Purpose:     req.Purpose,
+		StorageKey:  base + ext,
+		FileName:    uploadFileName(fileName, name+ext),
+		ContentType: contentType,
+		SizeBytes:   size,
+	}
+	uploads := []mediaUpload{{file: original, body: file}}
+
+	if format := imaging.Format(contentType); format != "" {
+		variants, err := imageVariants(original, file, format, base)
+		if err != nil {
+			return nil, err
+		}
+		uploads = append(uploads, variants...)
+	}
+
+	ctx := context.Background()
+	if err := s.storeUploads(ctx, uploads); err != nil {
+		return nil, err
+	}
+
+	for _, upload := range uploads[1:] {
+		original.Variants = append(original.Variants, *upload.file)
+	}
+	err = database.Transaction(func(tx *gorm.DB) error {
+		mediaRepo := repository.NewMediaRepository(tx)
+		if err := mediaRepo.Create(original); err != nil {
+			return err
+		}
+		link := mediaURL(s.baseURL, original.ID, "")
+
+		switch req.Purpose {
+		case mediaPurposeProfile:
+			if err := mediaRepo.Detach(mediaPurposeProfile, userID, []uint{original.ID}); err != nil {
+				return err
+			}
+			if _, err := mediaRepo.Attach(mediaPurposeProfile, userID, userID, []uint{original.ID}); err != nil {
+				return err
+			}
+			return tx.Model(&models.User{}).Where("id = ?", userID).Update("profile_image", link).Error
+		case mediaPurposeLocation:
+			if _, err := mediaRepo.Attach(mediaPurposeLocation, *req.LocationID, userID, []uint{original.ID}); err != nil {
+				return err
+			}
+			return tx.Model(&models.Location{}).Where("id = ?", *req.LocationID).
+				Update("images", gorm.Expr("array_append(images, ?)", link)).Error
+		}
+		return nil
+	})
+	if err != nil {
+		s.deleteUploads(ctx, uploads)
+		return nil, err
+	}
+
+	resp := toMediaResponse(original, s.baseURL)
+	return &resp, nil
+}
+
+// DownloadURL signs a download URL of a media file, or of one of its
+// variants. Images too small for a variant fall back to the original.
+// Message attachments are only shown to the two sides of the conversation.
+func (s *mediaService) DownloadURL(userID, mediaID uint, variant string) (string, error) {
+	file, err := s.mediaRepo.FindByID(mediaID)
+	if err != nil {
+		return "", notFound(err)
+	}
+	if file.Purpose == mediaPurposeMessage && file.UserID != userID {
+		originalID := file.ID
+		if file.ParentID != nil {
+			originalID = *file.ParentID
+		}
+		visible, err := s.mediaRepo.SentToUser(originalID, userID)
+		if err != nil {
+			return "", err
+		}
+		if !visible {
+			return "", apperrors.ErrNotFound
+		}
+	}
+	if variant != "" && variant != "original" {
+		if scaled := file.VariantNamed(variant); scaled != nil {
+			file = scaled
+		}
+	}
+	return s.store.SignedURL(file.StorageKey, s.cfg.URLExpiry)
+}
+
+// storeUploads puts every object of an upload, removing those already
+// stored when one fails
+func (s *mediaService) storeUploads(ctx context.Context, uploads []mediaUpload) error {
+	for i, upload := range uploads {
+		if _, err := upload.body.Seek(0, io.SeekStart); err != nil {
+			s.deleteUploads(ctx, uploads[:i])
+			return err
+		}
+		if err := s.store.Put(ctx, upload.file.StorageKey, upload.file.ContentType, upload.body, upload.file.SizeBytes); err != nil {
+			s.deleteUploads(ctx, uploads[:i])
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *mediaService) deleteUploads(ctx context.Context, uploads []mediaUpload) {
+	for _, upload := range uploads {
+		if err := s.store.Delete(ctx, upload.file.StorageKey); err != nil {
+			log.Printf("⚠️  Failed to remove media %s: %v", upload.file.StorageKey, err)
+		}
+	}
+}
+
+// imageVariants reads the size of an image into the original and scales it
+// down into the variants smaller than it
+func imageVariants(original *models.MediaFile, file io.Reader, format, base string) ([]mediaUpload, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	img, err := imaging.Decode(data)
+	if errors.Is(err, imaging.ErrTooLarge) {
+		return nil, fmt.Errorf("%w: images can have at most %d megapixels", apperrors.ErrFileTooLarge, imaging.MaxPixels/1_000_000)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: the image cannot be read", apperrors.ErrUnsupportedMedia)
+	}
+
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	original.Width, original.Height = &width, &height
+
+	var variants []mediaUpload
+	for _, variant := range mediaVariants {
+		if width <= variant.maxSide && height <= variant.maxSide {
+			continue
+		}
+		scaled := imaging.Fit(img, variant.maxSide)
+		var buf bytes.Buffer
+		contentType, err := imaging.Encode(&buf, scaled, format)
+		if err != nil {
+			return nil, err
+		}
+
+		scaledWidth, scaledHeight := scaled.Bounds().Dx(), scaled.Bounds().Dy()
+		variants = append(variants, mediaUpload{
+			file: &models.MediaFile{
+				UserID:      original.UserID,
+				Variant:     variant.name,
+				Purpose:     original.Purpose,
+				StorageKey:  base + "_" + variant.name + mediaTypes[contentType],
+				FileName:    original.FileName,
+				ContentType: contentType,
+				SizeBytes:   int64(buf.Len()),
+				Width:       &scaledWidth,
+				Height:      &scaledHeight,
+			},
+			body: bytes.NewReader(buf.Bytes()),
+		})
+	}
+	return variants, nil
+}
+
+// sniffContentType detects the type of a file from its first bytes,
+// ignoring what the client claims
+func sniffContentType(file io.ReadSeeker) (string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		if errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("%w: the file is empty", apperrors.ErrInvalidInput)
+		}
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	contentType, _, _ := strings.Cut(http.DetectContentType(head[:n]), ";")
+	return contentType, nil
+}
+
+// uploadFileName keeps the base name of an uploaded file, or fallback when
+// the client sent none
+func uploadFileName(name, fallback string) string {
+	name = strings.TrimSpace(path.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		return fallback
+	}
+	for utf8.RuneCountInString(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
+
+// randomMediaName returns an unguessable file name
+func randomMediaName() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// mediaURL is the stable URL of a media file or one of its variants
+func mediaURL(baseURL string, id uint, variant string) string {
+	link := baseURL + "/" + strconv.FormatUint(uint64(id), 10)
+	if variant != "" {
+		link += "?variant=" + url.QueryEscape(variant)
+	}
+	return link
+}
+
+// mediaIDsFromURLs picks the media files linked by stable media URLs
+func mediaIDsFromURLs(links ...string) []uint {
+	seen := map[uint]bool{}
+	ids := make([]uint, 0)
+	for _, link := range links {
+		parsed, err := url.Parse(link)
+		if err != nil {
+			continue
+		}
+		_, rest, found := strings.Cut(parsed.Path, mediaPath)
+		if !found {
+			continue
+		}
+		rest, _, _ = strings.Cut(rest, "/")
+		id, err := strconv.ParseUint(rest, 10, 64)
+		if err != nil || id == 0 || seen[uint(id)] {
+			continue
+		}
+		seen[uint(id)] = true
+		ids = append(ids, uint(id))
+	}
+	return ids
+}
+
+// attachExerciseMedia attaches the trainer's uploads linked from an
+// exercise to it, and releases those it no longer links
+func attachExerciseMedia(tx *gorm.DB, userID uint, exercise *models.ExerciseLibrary) error {
+	links := append([]string{}, exercise.Images...)
+	for _, link := range []*string{exercise.VideoURL, exercise.ThumbnailURL} {
+		if link != nil {
+			links = append(links, *link)
+		}
+	}
+	ids := mediaIDsFromURLs(links...)
+
+	mediaRepo := repository.NewMediaRepository(tx)
+	if err := mediaRepo.Detach(mediaPurposeExercise, exercise.ID, ids); err != nil {
+		return err
+	}
+	// Links to media the trainer did not upload are left as they are
+	_, err := mediaRepo.Attach(mediaPurposeExercise, exercise.ID, userID, ids)
+	return err
+}
@@ -0,0 +1,443 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"fitness-training-backend/internal/config"
+	"fitness-training-backend/internal/database"
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+	apperrors "fitness-training-backend/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultMessagePageSize = 30
+	maxMessagePageSize     = 100
+	messagePreviewLength   = 100
+)
+
+// MessageService handles the message threads between trainers and trainees
+type MessageService interface {
+	// Conversations
+	GetConversations(userID uint) ([]dto.ConversationResponse, error)
+	StartConversation(userID uint, req *dto.StartConversationRequest) (*dto.ConversationResponse, error)
+	GetConversation(userID, conversationID uint) (*dto.ConversationResponse, error)
+
+	// Messages
+	GetMessages(userID, conversationID, before, after uint, limit int) (*dto.MessagePageResponse, error)
+	SendMessage(userID, conversationID uint, req *dto.SendMessageRequest) (*dto.MessageResponse, error)
+	MarkAsRead(userID, conversationID uint, req *dto.MarkConversationReadRequest) (*dto.MarkConversationReadResponse, error)
+}
+
+type messageService struct {
+	userRepo         repository.UserRepository
+	trainerRepo      repository.TrainerRepository
+	traineeRepo      repository.TraineeRepository
+	scheduleRepo     repository.ScheduleRepository
+	sessionCardRepo  repository.SessionCardRepository
+	conversationRepo repository.ConversationRepository
+	messageRepo      repository.MessageRepository
+	mediaRepo        repository.MediaRepository
+	mediaBaseURL     string
+}
+
+// NewMessageService creates a new message service
+func NewMessageService(
+	userRepo repository.UserRepository,
+	trainerRepo repository.TrainerRepository,
+	traineeRepo repository.TraineeRepository,
+	scheduleRepo repository.ScheduleRepository,
+	sessionCardRepo repository.SessionCardRepository,
+	conversationRepo repository.ConversationRepository,
+	messageRepo repository.MessageRepository,
+	mediaRepo repository.MediaRepository,
+	cfg *config.Config,
+) MessageService {
+	return &messageService{
+		userRepo:         userRepo,
+		trainerRepo:      trainerRepo,
+		traineeRepo:      traineeRepo,
+		scheduleRepo:     scheduleRepo,
+		sessionCardRepo:  sessionCardRepo,
+		conversationRepo: conversationRepo,
+		messageRepo:      messageRepo,
+		mediaRepo:        mediaRepo,
+		mediaBaseURL:     strings.TrimSuffix(cfg.Server.PublicURL+mediaPath, "/"),
+	}
+}
+
+// ==========================================
+// CONVERSATIONS
+// ==========================================
+
+// GetConversations lists the user's threads, most recent first
+func (s *messageService) GetConversations(userID uint) ([]dto.ConversationResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	var conversations []models.Conversation
+	switch {
+	case user.IsTrainer():
+		trainer, err := s.trainerRepo.FindByUserID(userID)
+		if err != nil {
+			return nil, notFound(err)
+		}
+		conversations, err = s.conversationRepo.FindByTrainerID(trainer.ID)
+		if err != nil {
+			return nil, err
+		}
+	case user.IsTrainee():
+		trainee, err := s.traineeRepo.FindByUserID(userID)
+		if err != nil {
+			return nil, notFound(err)
+		}
+		conversations, err = s.conversationRepo.FindByTraineeID(trainee.ID)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, apperrors.ErrForbidden
+	}
+
+	return s.conversationResponses(userID, conversations)
+}
+
+// StartConversation returns the thread between a trainer and their client,
+// creating it on first use. Trainers name the client; trainees always talk to
+// their assigned trainer.
+func (s *messageService) StartConversation(userID uint, req *dto.StartConversationRequest) (*dto.ConversationResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	var trainerID, traineeID uint
+	switch {
+	case user.IsTrainer():
+		if req.TraineeID == nil {
+			return nil, fmt.Errorf("%w: traineeId is required", apperrors.ErrInvalidInput)
+		}
+		trainer, err := s.trainerRepo.FindByUserID(userID)
+		if err != nil {
+			return nil, notFound(err)
+		}
+		trainee, err := s.traineeRepo.FindByID(*req.TraineeID)
+		if err != nil {
+			return nil, notFound(err)
+		}
+		if trainee.TrainerID == nil || *trainee.TrainerID != trainer.ID {
+			return nil, apperrors.ErrClientNotAssigned
+		}
+		trainerID, traineeID = trainer.ID, trainee.ID
+	case user.IsTrainee():
+		trainee, err := s.traineeRepo.FindByUserID(userID)
+		if err != nil {
+			return nil, notFound(err)
+		}
+		if trainee.TrainerID == nil {
+			return nil, fmt.Errorf("%w: you have no assigned trainer", apperrors.ErrInvalidInput)
+		}
+		trainerID, traineeID = *trainee.TrainerID, trainee.ID
+	default:
+		return nil, apperrors.ErrForbidden
+	}
+
+	conversation, err := s.conversationRepo.FindOrCreate(trainerID, traineeID)
+	if err != nil {
+		return nil, err
+	}
+	return s.conversationResponse(userID, conversation)
+}
+
+// GetConversation returns one of the user's threads
+func (s *messageService) GetConversation(userID, conversationID uint) (*dto.ConversationResponse, error) {
+	conversation, err := s.loadConversation(userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	return s.conversationResponse(userID, conversation)
+}
+
+// loadConversation finds a conversation the user takes part in. Others get
+// ErrNotFound so thread IDs cannot be probed.
+func (s *messageService) loadConversation(userID, conversationID uint) (*models.Conversation, error) {
+	conversation, err := s.conversationRepo.FindByID(conversationID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if !conversation.HasParticipant(userID) {
+		return nil, apperrors.ErrNotFound
+	}
+	return conversation, nil
+}
+
+func (s *messageService) conversationResponse(userID uint, conversation *models.Conversation) (*dto.ConversationResponse, error) {
+	responses, err := s.conversationResponses(userID, []models.Conversation{*conversation})
+	if err != nil {
+		return nil, err
+	}
+	return &responses[0], nil
+}
+
+// conversationResponses adds the last message and unread count of each thread
+func (s *messageService) conversationResponses(userID uint, conversations []models.Conversation) ([]dto.ConversationResponse, error) {
+	ids := make([]uint, 0, len(conversations))
+	for i := range conversations {
+		ids = append(ids, conversations[i].ID)
+	}
+	latest, err := s.messageRepo.FindLatest(ids)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := s.messageRepo.CountUnread(ids, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]dto.ConversationResponse, 0, len(conversations))
+	for i := range conversations {
+		resp := toConversationResponse(&conversations[i])
+		resp.UnreadCount = unread[conversations[i].ID]
+		if message, ok := latest[conversations[i].ID]; ok {
+			lastMessage := toMessageResponse(&message, userID)
+			resp.LastMessage = &lastMessage
+		}
+		data = append(data, resp)
+	}
+	return data, nil
+}
+
+// ==========================================
+// MESSAGES
+// ==========================================
+
+// GetMessages returns one page of a conversation. before pages back from the
+// newest message; after returns what arrived since a message.
+func (s *messageService) GetMessages(userID, conversationID, before, after uint, limit int) (*dto.MessagePageResponse, error) {
+	if before > 0 && after > 0 {
+		return nil, fmt.Errorf("%w: use either before or after", apperrors.ErrInvalidInput)
+	}
+	if limit < 1 {
+		limit = defaultMessagePageSize
+	}
+	if limit > maxMessagePageSize {
+		limit = maxMessagePageSize
+	}
+
+	if _, err := s.loadConversation(userID, conversationID); err != nil {
+		return nil, err
+	}
+
+	var messages []models.Message
+	var err error
+	if after > 0 {
+		messages, err = s.messageRepo.FindAfter(conversationID, after, limit+1)
+	} else {
+		messages, err = s.messageRepo.FindBefore(conversationID, before, limit+1)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.MessagePageResponse{Data: make([]dto.MessageResponse, 0, limit)}
+	if len(messages) > limit {
+		messages = messages[:limit]
+		resp.HasMore = true
+	}
+	for i := range messages {
+		resp.Data = append(resp.Data, toMessageResponse(&messages[i], userID))
+	}
+	if len(messages) > 0 && (resp.HasMore || after > 0) {
+		// Polling with after continues from the newest message even when
+		// there is nothing more yet
+		cursor := messages[len(messages)-1].ID
+		resp.NextCursor = &cursor
+	}
+	return resp, nil
+}
+
+// SendMessage adds a message and notifies the other participant. Only the
+// trainee's current trainer can write to a thread.
+func (s *messageService) SendMessage(userID, conversationID uint, req *dto.SendMessageRequest) (*dto.MessageResponse, error) {
+	conversation, err := s.loadConversation(userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if !conversationActive(conversation) {
+		return nil, apperrors.ErrClientNotAssigned
+	}
+
+	body := strings.TrimSpace(req.Body)
+	if body == "" && len(req.Attachments) == 0 {
+		return nil, fmt.Errorf("%w: a message needs a body or an attachment", apperrors.ErrInvalidInput)
+	}
+	if err := s.validateMessageLinks(conversation, req); err != nil {
+		return nil, err
+	}
+
+	message := &models.Message{
+		ConversationID: conversation.ID,
+		SenderUserID:   userID,
+		Body:           body,
+		ScheduleID:     req.ScheduleID,
+		SessionCardID:  req.SessionCardID,
+	}
+	mediaIDs, err := s.loadAttachments(userID, req.Attachments, message)
+	if err != nil {
+		return nil, err
+	}
+
+	sender := conversation.Trainer.User
+	if conversation.Trainee.UserID == userID {
+		sender = conversation.Trainee.User
+	}
+
+	err = database.Transaction(func(tx *gorm.DB) error {
+		if err := repository.NewMessageRepository(tx).Create(message); err != nil {
+			return err
+		}
+		if err := attachMessageMedia(repository.NewMediaRepository(tx), message.ID, userID, mediaIDs); err != nil {
+			return err
+		}
+		if err := repository.NewConversationRepository(tx).TouchLastMessage(conversation.ID, message.CreatedAt); err != nil {
+			return err
+		}
+		return repository.NewNotificationRepository(tx).Create(messageNotification(
+			conversation.RecipientUserID(userID), conversation, sender.Name, message,
+		))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toMessageResponse(message, userID)
+	return &resp, nil
+}
+
+// MarkAsRead sets the read receipts of the messages the user received
+func (s *messageService) MarkAsRead(userID, conversationID uint, req *dto.MarkConversationReadRequest) (*dto.MarkConversationReadResponse, error) {
+	if _, err := s.loadConversation(userID, conversationID); err != nil {
+		return nil, err
+	}
+
+	var upTo uint
+	if req.MessageID != nil {
+		upTo = *req.MessageID
+	}
+	now := time.Now()
+	marked, err := s.messageRepo.MarkRead(conversationID, userID, upTo, now)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.MarkConversationReadResponse{Marked: marked, ReadAt: now}, nil
+}
+
+// loadAttachments adds the sender's message uploads to the message, with
+// the name, type and size recorded when they were uploaded, and returns
+// their IDs to attach
+func (s *messageService) loadAttachments(userID uint, attachments []dto.MessageAttachmentRequest, message *models.Message) ([]uint, error) {
+	ids := make([]uint, 0, len(attachments))
+	seen := map[uint]bool{}
+	for _, attachment := range attachments {
+		if seen[attachment.MediaID] {
+			continue
+		}
+		seen[attachment.MediaID] = true
+
+		file, err := s.mediaRepo.FindByID(attachment.MediaID)
+		if err != nil {
+			return nil, notFound(err)
+		}
+		if file.UserID != userID || file.ParentID != nil {
+			return nil, fmt.Errorf("%w: attachments must be files you uploaded", apperrors.ErrForbidden)
+		}
+		if file.Purpose != mediaPurposeMessage || file.AttachedID != nil {
+			return nil, fmt.Errorf("%w: media %d is not an unsent message attachment", apperrors.ErrInvalidInput, file.ID)
+		}
+
+		contentType, size := file.ContentType, file.SizeBytes
+		message.Attachments = append(message.Attachments, models.MessageAttachment{
+			URL:         mediaURL(s.mediaBaseURL, file.ID, ""),
+			FileName:    file.FileName,
+			ContentType: &contentType,
+			SizeBytes:   &size,
+		})
+		ids = append(ids, file.ID)
+	}
+	return ids, nil
+}
+
+// attachMessageMedia attaches the uploads checked by loadAttachments to the
+// message. A file attached to another message since then is not attached
+// again, which fails the send.
+func attachMessageMedia(mediaRepo repository.MediaRepository, messageID, userID uint, ids []uint) error {
+	attached, err := mediaRepo.Attach(mediaPurposeMessage, messageID, userID, ids)
+	if err != nil {
+		return err
+	}
+	if attached != int64(len(ids)) {
+		return fmt.Errorf("%w: an attachment was already sent with another message", apperrors.ErrInvalidInput)
+	}
+	return nil
+}
+
+// validateMessageLinks checks that the linked schedule and session card are
+// between the same trainer and trainee
+func (s *messageService) validateMessageLinks(conversation *models.Conversation, req *dto.SendMessageRequest) error {
+	if req.ScheduleID != nil {
+		schedule, err := s.scheduleRepo.FindByID(*req.ScheduleID)
+		if err != nil {
+			return notFound(err)
+		}
+		if schedule.TrainerID != conversation.TrainerID || schedule.TraineeID != conversation.TraineeID {
+			return fmt.Errorf("%w: schedule does not belong to this conversation", apperrors.ErrInvalidInput)
+		}
+	}
+	if req.SessionCardID != nil {
+		sessionCard, err := s.sessionCardRepo.FindByID(*req.SessionCardID)
+		if err != nil {
+			return notFound(err)
+		}
+		if sessionCard.TrainerID != conversation.TrainerID || sessionCard.TraineeID != conversation.TraineeID {
+			return fmt.Errorf("%w: session card does not belong to this conversation", apperrors.ErrInvalidInput)
+		}
+	}
+	return nil
+}
+
+// conversationActive reports whether the trainee is still assigned to the
+// conversation's trainer
+func conversationActive(conversation *models.Conversation) bool {
+	return conversation.Trainee.ID != 0 &&
+		conversation.Trainee.TrainerID != nil &&
+		*conversation.Trainee.TrainerID == conversation.TrainerID
+}
+
+func messageNotification(userID uint, conversation *models.Conversation, senderName string, message *models.Message) *models.Notification {
+	relatedType := "conversation"
+	preview := message.Body
+	if preview == "" {
+		preview = "Sent an attachment"
+	}
+	if utf8.RuneCountInString(preview) > messagePreviewLength {
+		preview = string([]rune(preview)[:messagePreviewLength-1]) + "…"
+	}
+
+	return &models.Notification{
+		UserID:      userID,
+		Type:        "message",
+		Title:       "New message from " + senderName,
+		Message:     preview,
+		RelatedID:   &conversation.ID,
+		RelatedType: &relatedType,
+		Priority:    "medium",
+	}
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"fitness-training-backend/internal/repository"
+	apperrors "fitness-training-backend/pkg/errors"
+)
+
+// fakeMedia attaches the files in unattached and records the call
+type fakeMedia struct {
+	repository.MediaRepository
+	unattached map[uint]bool
+	purpose    string
+}
+
+func (f *fakeMedia) Attach(purpose string, attachedID, userID uint, ids []uint) (int64, error) {
+	f.purpose = purpose
+	var attached int64
+	for _, id := range ids {
+		if f.unattached[id] {
+			attached++
+		}
+	}
+	return attached, nil
+}
+
+func TestAttachMessageMedia(t *testing.T) {
+	tests := []struct {
+		name string
+		ids  []uint
+		want error
+	}{
+		{"no attachments", nil, nil},
+		{"all still unattached", []uint{1, 2}, nil},
+		// 3 was sent with another message after loadAttachments checked it
+		{"one sent meanwhile", []uint{1, 3}, apperrors.ErrInvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			media := &fakeMedia{unattached: map[uint]bool{1: true, 2: true}}
+			err := attachMessageMedia(media, 50, 10, tt.ids)
+			if (tt.want == nil && err != nil) || (tt.want != nil && !errors.Is(err, tt.want)) {
+				t.Errorf("attachMessageMedia = %v, want %v", err, tt.want)
+			}
+			if len(tt.ids) > 0 && media.purpose != mediaPurposeMessage {
+				t.Errorf("attached as %q, want %q", media.purpose, mediaPurposeMessage)
+			}
+		})
+	}
+}
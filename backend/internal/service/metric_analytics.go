@@ -0,0 +1,470 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+	"fitness-training-backend/pkg/units"
+)
+
+// Metric types: recorded ones and those derived from them
+const (
+	metricTypeWeight        = "weight"
+	metricTypeBodyFat       = "body_fat"
+	metricTypeMuscleMass    = "muscle_mass"
+	metricTypeMeasurement   = "measurement"
+	metricTypeBMI           = "bmi"
+	metricTypeLeanMass      = "lean_mass"
+	metricTypeWaistToHeight = "waist_to_height"
+)
+
+// Goal statuses
+const (
+	goalReached          = "reached"
+	goalOnTrack          = "on_track"
+	goalBehind           = "behind"
+	goalOffTrack         = "off_track"
+	goalInsufficientData = "insufficient_data"
+)
+
+const (
+	// Rolling averages cover the last week of readings
+	rollingAverageDays = 7
+	// Trends are fitted over the last 4 weeks, which must span at least a
+	// week to say anything
+	trendDays    = 28
+	minTrendDays = 7
+	// Lean mass pairs a body fat reading with the closest weight this near
+	leanMassPairingDays = 7
+	// Trends taking longer than this to reach a goal do not count as heading
+	// there
+	maxProjectionDays = 2 * 365
+)
+
+// metricTypeRank orders the series in a response
+var metricTypeRank = map[string]int{
+	metricTypeWeight:        0,
+	metricTypeBodyFat:       1,
+	metricTypeMuscleMass:    2,
+	metricTypeMeasurement:   3,
+	metricTypeBMI:           4,
+	metricTypeLeanMass:      5,
+	metricTypeWaistToHeight: 6,
+}
+
+// metricPoint is the mean of one day's readings of a metric
+type metricPoint struct {
+	date  time.Time
+	value float64
+}
+
+// metricSeries is the daily history of one metric, oldest first
+type metricSeries struct {
+	metricType      string
+	measurementType string // '' unless metricType = 'measurement'
+	unit            string
+	derived         bool
+	points          []metricPoint
+}
+
+func metricSeriesKey(metricType, measurementType string) string {
+	return metricType + ":" + measurementType
+}
+
+// normalizeMeasurementType makes 'Waist ' and 'waist' the same measurement
+func normalizeMeasurementType(measurementType *string) string {
+	if measurementType == nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(*measurementType))
+}
+
+// dailyMetricSeries groups metrics (oldest first) into one series per type
+// and measurement, averaging readings of the same day. A series takes the
+// unit of its latest reading.
+func dailyMetricSeries(metrics []models.Metric) map[string]*metricSeries {
+	type day struct {
+		sum   float64
+		count int
+	}
+	series := map[string]*metricSeries{}
+	days := map[string][]*day{}
+
+	for _, metric := range metrics {
+		measurementType := ""
+		if metric.Type == metricTypeMeasurement {
+			measurementType = normalizeMeasurementType(metric.MeasurementType)
+		}
+		key := metricSeriesKey(metric.Type, measurementType)
+		s, ok := series[key]
+		if !ok {
+			s = &metricSeries{metricType: metric.Type, measurementType: measurementType}
+			series[key] = s
+		}
+		s.unit = metric.Unit
+
+		date := time.Date(metric.Date.Year(), metric.Date.Month(), metric.Date.Day(), 0, 0, 0, 0, time.UTC)
+		if n := len(s.points); n > 0 && s.points[n-1].date.Equal(date) {
+			d := days[key][n-1]
+			d.sum += float64(metric.Value)
+			d.count++
+			s.points[n-1].value = d.sum / float64(d.count)
+			continue
+		}
+		s.points = append(s.points, metricPoint{date: date, value: float64(metric.Value)})
+		days[key] = append(days[key], &day{sum: float64(metric.Value), count: 1})
+	}
+
+	return series
+}
+
+// addDerivedSeries adds BMI, lean mass and waist-to-height ratio to the
+// recorded series. Weight is in kg, and height and waist in cm.
+func addDerivedSeries(series map[string]*metricSeries, heightCm float64) {
+	weight := series[metricSeriesKey(metricTypeWeight, "")]
+	bodyFat := series[metricSeriesKey(metricTypeBodyFat, "")]
+	waist := series[metricSeriesKey(metricTypeMeasurement, "waist")]
+
+	if weight != nil && heightCm > 0 {
+		heightM := heightCm / 100
+		bmi := &metricSeries{metricType: metricTypeBMI, unit: "kg/m²", derived: true}
+		for _, point := range weight.points {
+			bmi.points = append(bmi.points, metricPoint{date: point.date, value: point.value / (heightM * heightM)})
+		}
+		series[metricSeriesKey(metricTypeBMI, "")] = bmi
+	}
+
+	if weight != nil && bodyFat != nil {
+		leanMass := &metricSeries{metricType: metricTypeLeanMass, unit: weight.unit, derived: true}
+		for _, point := range bodyFat.points {
+			if w, ok := closestPoint(weight.points, point.date, leanMassPairingDays); ok {
+				leanMass.points = append(leanMass.points, metricPoint{date: point.date, value: w.value * (1 - point.value/100)})
+			}
+		}
+		if len(leanMass.points) > 0 {
+			series[metricSeriesKey(metricTypeLeanMass, "")] = leanMass
+		}
+	}
+
+	if waist != nil && heightCm > 0 {
+		ratio := &metricSeries{metricType: metricTypeWaistToHeight, unit: "ratio", derived: true}
+		for _, point := range waist.points {
+			ratio.points = append(ratio.points, metricPoint{date: point.date, value: point.value / heightCm})
+		}
+		series[metricSeriesKey(metricTypeWaistToHeight, "")] = ratio
+	}
+}
+
+// localizeMetricSeries converts a series in the canonical unit of its
+// quantity to the preferred unit. Derived series are computed before this,
+// from canonical values.
+func localizeMetricSeries(s *metricSeries, prefs units.Preferences) {
+	kind := metricKind(s.metricType)
+	if kind == "" || s.unit != string(units.Canonical(kind)) {
+		return
+	}
+	unit := prefs.For(kind)
+	factor := units.FromCanonical(1, unit)
+	for i := range s.points {
+		s.points[i].value *= factor
+	}
+	s.unit = string(unit)
+}
+
+// closestPoint finds the point nearest to date within maxDays, preferring the
+// earlier one on a tie
+func closestPoint(points []metricPoint, date time.Time, maxDays int) (metricPoint, bool) {
+	best, bestDays := metricPoint{}, maxDays+1
+	for _, point := range points {
+		days := daysBetween(point.date, date)
+		if days < 0 {
+			days = -days
+		}
+		if days < bestDays {
+			best, bestDays = point, days
+		}
+	}
+	return best, bestDays <= maxDays
+}
+
+// rollingAverages returns for each point the mean of the points of the
+// rollingAverageDays ending on it
+func rollingAverages(points []metricPoint) []float64 {
+	averages := make([]float64, len(points))
+	start, sum := 0, 0.0
+	for i, point := range points {
+		sum += point.value
+		for daysBetween(points[start].date, point.date) >= rollingAverageDays {
+			sum -= points[start].value
+			start++
+		}
+		averages[i] = sum / float64(i-start+1)
+	}
+	return averages
+}
+
+// trendPerDay fits a least-squares line through the points of the trendDays
+// ending on points[end] and returns its slope per day. It reports false when
+// those points span less than minTrendDays.
+func trendPerDay(points []metricPoint, end int) (float64, bool) {
+	start := end
+	for start > 0 && daysBetween(points[start-1].date, points[end].date) < trendDays {
+		start--
+	}
+	if daysBetween(points[start].date, points[end].date) < minTrendDays {
+		return 0, false
+	}
+
+	window := points[start : end+1]
+	var sumX, sumY float64
+	for _, point := range window {
+		sumX += float64(daysBetween(window[0].date, point.date))
+		sumY += point.value
+	}
+	n := float64(len(window))
+	meanX, meanY := sumX/n, sumY/n
+
+	var covariance, variance float64
+	for _, point := range window {
+		dx := float64(daysBetween(window[0].date, point.date)) - meanX
+		covariance += dx * (point.value - meanY)
+		variance += dx * dx
+	}
+	return covariance / variance, true
+}
+
+// metricPrecision is the number of decimals a metric is reported with
+func metricPrecision(metricType string) int {
+	if metricType == metricTypeWaistToHeight {
+		return 3
+	}
+	return 2
+}
+
+func roundMetric(value float64, decimals int) float32 {
+	scale := math.Pow(10, float64(decimals))
+	return float32(math.Round(value*scale) / scale)
+}
+
+// summarizeMetricSeries describes the points of a series between from and to
+// (inclusive, either open). It returns nil when none are in the range.
+func summarizeMetricSeries(s *metricSeries, from, to *time.Time) *dto.MetricSeriesResponse {
+	first, last := -1, -1
+	for i, point := range s.points {
+		if from != nil && daysBetween(*from, point.date) < 0 {
+			continue
+		}
+		if to != nil && daysBetween(point.date, *to) < 0 {
+			break
+		}
+		if first < 0 {
+			first = i
+		}
+		last = i
+	}
+	if first < 0 {
+		return nil
+	}
+
+	decimals := metricPrecision(s.metricType)
+	value := func(point metricPoint) dto.MetricValueResponse {
+		return dto.MetricValueResponse{Date: point.date, Value: roundMetric(point.value, decimals)}
+	}
+
+	// Averages and trends also use readings from before the range
+	averages := rollingAverages(s.points[:last+1])
+	resp := &dto.MetricSeriesResponse{
+		Type:    s.metricType,
+		Unit:    s.unit,
+		Derived: s.derived,
+		Points:  make([]dto.MetricPointResponse, 0, last-first+1),
+		Latest:  value(s.points[last]),
+		Change:  roundMetric(s.points[last].value-s.points[first].value, decimals),
+	}
+	if s.measurementType != "" {
+		measurementType := s.measurementType
+		resp.MeasurementType = &measurementType
+	}
+
+	lowest, highest := first, first
+	for i := first; i <= last; i++ {
+		point := s.points[i]
+		resp.Points = append(resp.Points, dto.MetricPointResponse{
+			Date:           point.date,
+			Value:          roundMetric(point.value, decimals),
+			RollingAverage: roundMetric(averages[i], decimals),
+		})
+		if point.value < s.points[lowest].value {
+			lowest = i
+		}
+		if point.value > s.points[highest].value {
+			highest = i
+		}
+	}
+	resp.Min = value(s.points[lowest])
+	resp.Max = value(s.points[highest])
+
+	if slope, ok := trendPerDay(s.points, last); ok {
+		weekly := roundMetric(slope*7, decimals)
+		resp.WeeklyChange = &weekly
+	}
+
+	return resp
+}
+
+// metricGoalProgress measures a goal against the whole history of its
+// metric. The goal's direction comes from where the metric stood when the
+// goal was set; the projection extends the current trend from the latest
+// reading.
+func metricGoalProgress(goal *models.MetricGoal, s *metricSeries) dto.MetricGoalResponse {
+	resp := dto.MetricGoalResponse{
+		ID:          goal.ID,
+		Type:        goal.Type,
+		TargetValue: goal.TargetValue,
+		TargetDate:  goal.TargetDate,
+		Notes:       goal.Notes,
+		Status:      goalInsufficientData,
+		UpdatedAt:   goal.UpdatedAt,
+	}
+	if goal.MeasurementType != "" {
+		measurementType := goal.MeasurementType
+		resp.MeasurementType = &measurementType
+	}
+	if goal.Setter != nil {
+		name := goal.Setter.Name
+		resp.SetBy = &name
+	}
+	if s == nil || len(s.points) == 0 {
+		return resp
+	}
+
+	decimals := metricPrecision(goal.Type)
+	target := float64(goal.TargetValue)
+	latest := s.points[len(s.points)-1]
+
+	baseline := s.points[0].value
+	for _, point := range s.points {
+		if daysBetween(point.date, goal.UpdatedAt) < 0 {
+			break
+		}
+		baseline = point.value
+	}
+	direction := math.Copysign(1, target-baseline)
+	if target == baseline {
+		direction = math.Copysign(1, target-latest.value)
+	}
+
+	current := roundMetric(latest.value, decimals)
+	remaining := roundMetric(target-latest.value, decimals)
+	resp.Current = &current
+	resp.Remaining = &remaining
+
+	if remaining == 0 || (direction > 0 && latest.value >= target) || (direction < 0 && latest.value <= target) {
+		resp.Status = goalReached
+		return resp
+	}
+
+	if goal.TargetDate != nil {
+		if days := daysBetween(latest.date, *goal.TargetDate); days > 0 {
+			required := roundMetric((target-latest.value)/float64(days)*7, decimals)
+			resp.RequiredWeeklyChange = &required
+		}
+	}
+
+	slope, ok := trendPerDay(s.points, len(s.points)-1)
+	if !ok {
+		return resp
+	}
+	if slope*direction <= 0 {
+		resp.Status = goalOffTrack
+		return resp
+	}
+	days := math.Ceil((target - latest.value) / slope)
+	if days > maxProjectionDays {
+		resp.Status = goalOffTrack
+		return resp
+	}
+
+	projected := latest.date.AddDate(0, 0, int(days))
+	resp.ProjectedDate = &projected
+	resp.Status = goalOnTrack
+	if goal.TargetDate != nil && daysBetween(*goal.TargetDate, projected) > 0 {
+		resp.Status = goalBehind
+	}
+	return resp
+}
+
+// buildMetricAnalytics turns a trainee's metrics (oldest first) and goals
+// into series limited to from/to, and goal progress over the whole history,
+// in the units of prefs
+func buildMetricAnalytics(trainee *models.Trainee, metrics []models.Metric, goals []models.MetricGoal, from, to *time.Time, prefs units.Preferences) *dto.MetricAnalyticsResponse {
+	resp := &dto.MetricAnalyticsResponse{
+		TraineeID: trainee.ID,
+		Series:    make([]dto.MetricSeriesResponse, 0),
+		Goals:     make([]dto.MetricGoalResponse, 0, len(goals)),
+	}
+	if trainee.Height > 0 {
+		height := displayValue(trainee.Height, prefs.For(units.Length))
+		resp.Height = &height
+	}
+
+	series := dailyMetricSeries(metrics)
+	addDerivedSeries(series, float64(trainee.Height))
+	for _, s := range series {
+		localizeMetricSeries(s, prefs)
+	}
+
+	ordered := make([]*metricSeries, 0, len(series))
+	for _, s := range series {
+		ordered = append(ordered, s)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if ordered[i].metricType != ordered[j].metricType {
+			return metricTypeRank[ordered[i].metricType] < metricTypeRank[ordered[j].metricType]
+		}
+		return ordered[i].measurementType < ordered[j].measurementType
+	})
+	for _, s := range ordered {
+		if summary := summarizeMetricSeries(s, from, to); summary != nil {
+			resp.Series = append(resp.Series, *summary)
+		}
+	}
+
+	for i := range goals {
+		// Targets are stored canonically, like the readings
+		goal := goals[i]
+		unit := ""
+		if kind := metricKind(goal.Type); kind != "" {
+			goal.TargetValue = displayValue(goal.TargetValue, prefs.For(kind))
+			unit = string(prefs.For(kind))
+		}
+		s := series[metricSeriesKey(goal.Type, goal.MeasurementType)]
+		if unit == "" && s != nil {
+			unit = s.unit
+		}
+		progress := metricGoalProgress(&goal, s)
+		progress.Unit = unit
+		resp.Goals = append(resp.Goals, progress)
+	}
+
+	return resp
+}
+
+// loadMetricAnalytics reads a trainee's metrics and goals and builds their
+// analytics
+func loadMetricAnalytics(metricRepo repository.MetricRepository, goalRepo repository.MetricGoalRepository, trainee *models.Trainee, from, to *time.Time, prefs units.Preferences) (*dto.MetricAnalyticsResponse, error) {
+	metrics, err := metricRepo.FindHistory(trainee.ID)
+	if err != nil {
+		return nil, err
+	}
+	goals, err := goalRepo.FindByTraineeID(trainee.ID)
+	if err != nil {
+		return nil, err
+	}
+	return buildMetricAnalytics(trainee, metrics, goals, from, to, prefs), nil
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"fitness-training-backend/internal/models"
+)
+
+// metricStart is day 0 of the series in these tests
+var metricStart = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+// readings builds daily points from (day, value) pairs
+func readings(pairs ...float64) []metricPoint {
+	points := make([]metricPoint, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		points = append(points, metricPoint{date: metricStart.AddDate(0, 0, int(pairs[i])), value: pairs[i+1]})
+	}
+	return points
+}
+
+func TestRollingAverages(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []metricPoint
+		want   []float64
+	}{
+		{"empty series", nil, []float64{}},
+		{"single point", readings(0, 80), []float64{80}},
+		{"consecutive days", readings(0, 80, 1, 81, 2, 82), []float64{80, 80.5, 81}},
+		{"six days apart stay in the window", readings(0, 80, 6, 86), []float64{80, 83}},
+		{"seven days apart do not", readings(0, 80, 7, 86), []float64{80, 86}},
+		{"gaps in the window", readings(0, 80, 3, 82, 10, 84), []float64{80, 81, 84}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rollingAverages(tt.points); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("rollingAverages = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrendPerDay(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []metricPoint
+		end    int
+		want   float64
+		ok     bool
+	}{
+		{"single point", readings(0, 80), 0, 0, false},
+		{"less than a week", readings(0, 80, 6, 79), 1, 0, false},
+		{"a week", readings(0, 80, 7, 78.25), 1, -0.25, true},
+		{"gaps in the window", readings(0, 80, 7, 78.25, 14, 76.5), 2, -0.25, true},
+		{"flat", readings(0, 80, 3, 80, 10, 80), 2, 0, true},
+		// The reading 30 days before the end is outside the window
+		{"older readings are left out", readings(0, 100, 20, 80, 27, 79.3, 30, 79), 3, -0.1, true},
+		{"ending before the last point", readings(0, 80, 7, 78.25, 14, 90), 1, -0.25, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := trendPerDay(tt.points, tt.end)
+			if ok != tt.ok || (ok && !closeTo(float32(got), float32(tt.want))) {
+				t.Errorf("trendPerDay = %v, %v; want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestMetricGoalProgress(t *testing.T) {
+	day := func(offset int) *time.Time {
+		date := metricStart.AddDate(0, 0, offset)
+		return &date
+	}
+	// Weight goal of 75 kg set on day 0
+	goal := func(targetDate *time.Time) *models.MetricGoal {
+		return &models.MetricGoal{Type: metricTypeWeight, TargetValue: 75, TargetDate: targetDate, UpdatedAt: metricStart}
+	}
+	series := func(points []metricPoint) *metricSeries {
+		return &metricSeries{metricType: metricTypeWeight, unit: "kg", points: points}
+	}
+	losing := readings(0, 80, 7, 78.25, 14, 76.5) // 0.25 kg a day
+
+	tests := []struct {
+		name          string
+		goal          *models.MetricGoal
+		series        *metricSeries
+		wantStatus    string
+		wantCurrent   float32 // 0 = none
+		wantRemaining float32
+		wantProjected *time.Time
+		wantRequired  *float32
+	}{
+		{name: "no series", goal: goal(nil), wantStatus: goalInsufficientData},
+		{name: "empty series", goal: goal(nil), series: series(nil), wantStatus: goalInsufficientData},
+		{name: "single point", goal: goal(nil), series: series(readings(0, 80)),
+			wantStatus: goalInsufficientData, wantCurrent: 80, wantRemaining: -5},
+		{name: "already met", goal: goal(nil), series: series(readings(0, 80, 7, 74)),
+			wantStatus: goalReached, wantCurrent: 74, wantRemaining: 1},
+		{name: "exactly met", goal: goal(nil), series: series(readings(0, 80, 7, 75)),
+			wantStatus: goalReached, wantCurrent: 75},
+		// Below the target when it was set, so the goal is to gain
+		{name: "met from below", goal: goal(nil), series: series(readings(0, 70, 7, 76)),
+			wantStatus: goalReached, wantCurrent: 76, wantRemaining: -1},
+		{name: "moving the wrong way", goal: goal(nil), series: series(readings(0, 80, 7, 80.5, 14, 81)),
+			wantStatus: goalOffTrack, wantCurrent: 81, wantRemaining: -6},
+		{name: "flat", goal: goal(nil), series: series(readings(0, 80, 7, 80, 14, 80)),
+			wantStatus: goalOffTrack, wantCurrent: 80, wantRemaining: -5},
+		{name: "too slow to project", goal: goal(nil), series: series(readings(0, 80, 7, 79.984375, 14, 79.96875)),
+			wantStatus: goalOffTrack, wantCurrent: 79.97, wantRemaining: -4.97},
+		{name: "on track", goal: goal(nil), series: series(losing),
+			wantStatus: goalOnTrack, wantCurrent: 76.5, wantRemaining: -1.5, wantProjected: day(20)},
+		{name: "on track for the target date", goal: goal(day(28)), series: series(losing),
+			wantStatus: goalOnTrack, wantCurrent: 76.5, wantRemaining: -1.5, wantProjected: day(20), wantRequired: f32(-0.75)},
+		{name: "behind the target date", goal: goal(day(17)), series: series(losing),
+			wantStatus: goalBehind, wantCurrent: 76.5, wantRemaining: -1.5, wantProjected: day(20), wantRequired: f32(-3.5)},
+		{name: "target date passed", goal: goal(day(10)), series: series(losing),
+			wantStatus: goalBehind, wantCurrent: 76.5, wantRemaining: -1.5, wantProjected: day(20)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := metricGoalProgress(tt.goal, tt.series)
+
+			if resp.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", resp.Status, tt.wantStatus)
+			}
+			switch {
+			case tt.wantCurrent == 0 && (resp.Current != nil || resp.Remaining != nil):
+				t.Errorf("current %v, remaining %v; want none", resp.Current, resp.Remaining)
+			case tt.wantCurrent != 0 && (resp.Current == nil || *resp.Current != tt.wantCurrent || *resp.Remaining != tt.wantRemaining):
+				t.Errorf("current %v, remaining %v; want %v, %v", resp.Current, resp.Remaining, tt.wantCurrent, tt.wantRemaining)
+			}
+			if (resp.ProjectedDate == nil) != (tt.wantProjected == nil) ||
+				(tt.wantProjected != nil && !resp.ProjectedDate.Equal(*tt.wantProjected)) {
+				t.Errorf("projected %v, want %v", resp.ProjectedDate, tt.wantProjected)
+			}
+			if (resp.RequiredWeeklyChange == nil) != (tt.wantRequired == nil) ||
+				(tt.wantRequired != nil && *resp.RequiredWeeklyChange != *tt.wantRequired) {
+				t.Errorf("required weekly change %v, want %v", resp.RequiredWeeklyChange, tt.wantRequired)
+			}
+		})
+	}
+}
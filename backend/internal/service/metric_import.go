@@ -0,0 +1,432 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"fitness-training-backend/internal/database"
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+	"fitness-training-backend/pkg/bodycomp"
+	apperrors "fitness-training-backend/pkg/errors"
+	"fitness-training-backend/pkg/units"
+
+	"gorm.io/gorm"
+)
+
+// Statuses of an import row
+const (
+	importRowReady     = "ready"     // previewed, would be stored
+	importRowImported  = "imported"  // stored
+	importRowDuplicate = "duplicate" // every value was imported before
+	importRowUnmatched = "unmatched" // no client of the trainer matches
+	importRowInvalid   = "invalid"
+)
+
+// phoneMatchDigits is how many trailing digits of a phone number are compared,
+// so +66 81 234 5678 and 081-234-5678 are the same number
+const phoneMatchDigits = 9
+
+// PreviewMetricImport reads a body-composition export and returns the metrics
+// committing it would create, without storing anything
+func (s *trainerService) PreviewMetricImport(userID uint, file io.Reader, mapping *dto.MetricImportMapping) (*dto.MetricImportResponse, error) {
+	return s.importMetrics(userID, file, mapping, false)
+}
+
+// CommitMetricImport stores the metrics of a body-composition export. Values
+// imported before, from this or an earlier upload, are skipped.
+func (s *trainerService) CommitMetricImport(userID uint, file io.Reader, mapping *dto.MetricImportMapping) (*dto.MetricImportResponse, error) {
+	return s.importMetrics(userID, file, mapping, true)
+}
+
+// importRow is a row of the response with the metrics it adds
+type importRow struct {
+	resp  dto.MetricImportRowResponse
+	first int // index of the row's first metric in the import
+	count int
+}
+
+func (s *trainerService) importMetrics(userID uint, file io.Reader, mapping *dto.MetricImportMapping, commit bool) (*dto.MetricImportResponse, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	parseMapping, err := toBodycompMapping(mapping)
+	if err != nil {
+		return nil, err
+	}
+	result, err := bodycomp.Parse(file, parseMapping)
+	if errors.Is(err, bodycomp.ErrInvalidFile) {
+		return nil, fmt.Errorf("%w: %v", apperrors.ErrInvalidInput, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	clients, err := s.trainerRepo.GetClients(trainer.ID)
+	if err != nil {
+		return nil, err
+	}
+	matcher := newClientMatcher(clients)
+
+	rows := make([]importRow, 0, len(result.Readings)+len(result.Errors))
+	for _, rowErr := range result.Errors {
+		rows = append(rows, importRow{resp: dto.MetricImportRowResponse{
+			Row:    rowErr.Row,
+			Status: importRowInvalid,
+			Errors: []string{rowErr.Err},
+		}})
+	}
+
+	var metrics []models.Metric
+	traineeIDs := map[uint]bool{}
+	for _, reading := range result.Readings {
+		takenAt := reading.TakenAt
+		row := importRow{resp: dto.MetricImportRowResponse{Row: reading.Row, Date: &takenAt}}
+
+		trainee, matchedBy, err := matcher.match(reading)
+		if err != nil {
+			row.resp.Status = importRowInvalid
+			row.resp.Errors = []string{err.Error()}
+			rows = append(rows, row)
+			continue
+		}
+		if trainee == nil {
+			row.resp.Status = importRowUnmatched
+			row.resp.Errors = []string{"no client has this member ID, email or phone"}
+			rows = append(rows, row)
+			continue
+		}
+		row.resp.MatchedBy = &matchedBy
+		row.resp.TraineeID = &trainee.ID
+		row.resp.TraineeName = &trainee.User.Name
+
+		var rowMetrics []models.Metric
+		var problems []string
+		for _, value := range reading.Values {
+			metric, err := importedMetric(trainee.ID, userID, takenAt, value)
+			if err != nil {
+				problems = append(problems, err.Error())
+				continue
+			}
+			rowMetrics = append(rowMetrics, metric)
+		}
+		if len(problems) > 0 {
+			row.resp.Status = importRowInvalid
+			row.resp.Errors = problems
+			rows = append(rows, row)
+			continue
+		}
+
+		row.first, row.count = len(metrics), len(rowMetrics)
+		metrics = append(metrics, rowMetrics...)
+		traineeIDs[trainee.ID] = true
+		rows = append(rows, row)
+	}
+
+	// Skip values imported before and repeated within the file
+	imported, err := s.findImported(traineeIDs, metrics)
+	if err != nil {
+		return nil, err
+	}
+	duplicates := 0
+	var pending []models.Metric
+	for i := range rows {
+		row := &rows[i]
+		if row.resp.TraineeID == nil || row.resp.Status == importRowInvalid {
+			continue
+		}
+		first := len(pending)
+		for _, metric := range metrics[row.first : row.first+row.count] {
+			key := importedKey(metric.TraineeID, *metric.ImportKey)
+			if imported[key] {
+				row.resp.Duplicates++
+				continue
+			}
+			imported[key] = true
+			pending = append(pending, metric)
+		}
+		duplicates += row.resp.Duplicates
+		row.first, row.count = first, len(pending)-first
+	}
+
+	created := len(pending)
+	if commit && len(pending) > 0 {
+		err = database.Transaction(func(tx *gorm.DB) error {
+			inserted, err := repository.NewMetricRepository(tx).CreateImported(pending)
+			if err != nil {
+				return err
+			}
+			created = int(inserted)
+
+			// Metrics can complete achievements of the trainees they belong to
+			evaluated := map[uint]bool{}
+			for _, metric := range pending {
+				if evaluated[metric.TraineeID] {
+					continue
+				}
+				evaluated[metric.TraineeID] = true
+				if err := evaluateAchievements(tx, metric.TraineeID); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	prefs := trainer.User.Units()
+	resp := &dto.MetricImportResponse{
+		Committed:  commit,
+		TotalRows:  len(rows),
+		Created:    created,
+		Duplicates: duplicates,
+		Columns:    result.Columns,
+		Rows:       make([]dto.MetricImportRowResponse, 0, len(rows)),
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].resp.Row < rows[j].resp.Row })
+	for _, row := range rows {
+		if row.resp.TraineeID != nil && row.resp.Status != importRowInvalid {
+			resp.MatchedRows++
+			row.resp.Metrics = make([]dto.MetricResponse, 0, row.count)
+			for i := row.first; i < row.first+row.count; i++ {
+				pending[i].Recorder = &trainer.User
+				row.resp.Metrics = append(row.resp.Metrics, toMetricResponse(&pending[i], prefs))
+			}
+			switch {
+			case row.count == 0:
+				row.resp.Status = importRowDuplicate
+			case commit:
+				row.resp.Status = importRowImported
+			default:
+				row.resp.Status = importRowReady
+			}
+		}
+		resp.Rows = append(resp.Rows, row.resp)
+	}
+	return resp, nil
+}
+
+// findImported returns the trainee and import key pairs of metrics already
+// stored
+func (s *trainerService) findImported(traineeIDs map[uint]bool, metrics []models.Metric) (map[string]bool, error) {
+	imported := map[string]bool{}
+	if len(metrics) == 0 {
+		return imported, nil
+	}
+
+	ids := make([]uint, 0, len(traineeIDs))
+	for id := range traineeIDs {
+		ids = append(ids, id)
+	}
+	keys := make([]string, 0, len(metrics))
+	for _, metric := range metrics {
+		keys = append(keys, *metric.ImportKey)
+	}
+
+	existing, err := s.metricRepo.FindImported(ids, keys)
+	if err != nil {
+		return nil, err
+	}
+	for _, metric := range existing {
+		if metric.ImportKey != nil {
+			imported[importedKey(metric.TraineeID, *metric.ImportKey)] = true
+		}
+	}
+	return imported, nil
+}
+
+func importedKey(traineeID uint, importKey string) string {
+	return fmt.Sprintf("%d|%s", traineeID, importKey)
+}
+
+// importedMetric builds the metric of a value read from an export, in the
+// canonical unit of its type
+func importedMetric(traineeID, recordedBy uint, takenAt time.Time, value bodycomp.Value) (models.Metric, error) {
+	if value.Type == metricTypeBodyFat && value.Value >= 100 {
+		return models.Metric{}, fmt.Errorf("body fat of %.1f%% is not possible", value.Value)
+	}
+
+	metric := models.Metric{
+		TraineeID:  traineeID,
+		Date:       takenAt,
+		Type:       value.Type,
+		Value:      canonicalValue(float32(value.Value), value.Unit),
+		Unit:       string(units.Canonical(value.Unit.Kind())),
+		RecordedBy: &recordedBy,
+	}
+	if value.MeasurementType != "" {
+		measurementType := value.MeasurementType
+		metric.MeasurementType = &measurementType
+	}
+
+	// The key identifies the value by scan rather than by file, so the same
+	// scan in a later, longer export is recognised too
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		"bodycomp", takenAt.Format(time.RFC3339), value.Type, value.MeasurementType,
+	}, "|")))
+	key := hex.EncodeToString(sum[:])
+	metric.ImportKey = &key
+	return metric, nil
+}
+
+// toBodycompMapping applies the trainer's overrides to the InBody defaults
+func toBodycompMapping(req *dto.MetricImportMapping) (bodycomp.Mapping, error) {
+	mapping := bodycomp.DefaultMapping()
+	if req == nil {
+		return mapping, nil
+	}
+
+	for _, field := range []struct {
+		header  *string
+		headers *[]string
+	}{
+		{req.MemberID, &mapping.MemberID},
+		{req.Phone, &mapping.Phone},
+		{req.Email, &mapping.Email},
+		{req.Date, &mapping.Date},
+		{req.Weight, &mapping.Weight},
+		{req.BodyFat, &mapping.BodyFat},
+		{req.MuscleMass, &mapping.MuscleMass},
+	} {
+		if field.header == nil {
+			continue
+		}
+		*field.headers = nil
+		if header := strings.TrimSpace(*field.header); header != "" {
+			*field.headers = []string{header}
+		}
+	}
+
+	for name, header := range req.Measurements {
+		measurementType := normalizeMeasurementType(&name)
+		if measurementType == "" {
+			return mapping, fmt.Errorf("%w: measurement mappings need a measurement type", apperrors.ErrInvalidInput)
+		}
+		if header = strings.TrimSpace(header); header == "" {
+			delete(mapping.Measurements, measurementType)
+			continue
+		}
+		mapping.Measurements[measurementType] = []string{header}
+	}
+
+	if req.WeightUnit != nil {
+		unit, err := units.ParseKind(*req.WeightUnit, units.Mass)
+		if err != nil {
+			return mapping, fmt.Errorf("%w: %s is not a unit of weight", apperrors.ErrInvalidInput, *req.WeightUnit)
+		}
+		mapping.WeightUnit = unit
+	}
+	if req.LengthUnit != nil {
+		unit, err := units.ParseKind(*req.LengthUnit, units.Length)
+		if err != nil {
+			return mapping, fmt.Errorf("%w: %s is not a unit of length", apperrors.ErrInvalidInput, *req.LengthUnit)
+		}
+		mapping.LengthUnit = unit
+	}
+	if req.DayFirst != nil {
+		mapping.DayFirst = *req.DayFirst
+	}
+	return mapping, nil
+}
+
+// clientMatcher finds the client of a reading by member ID, email or phone
+type clientMatcher struct {
+	byMemberID map[string]*models.Trainee
+	byEmail    map[string]*models.Trainee
+	byPhone    map[string][]*models.Trainee
+}
+
+func newClientMatcher(clients []models.Trainee) *clientMatcher {
+	matcher := &clientMatcher{
+		byMemberID: map[string]*models.Trainee{},
+		byEmail:    map[string]*models.Trainee{},
+		byPhone:    map[string][]*models.Trainee{},
+	}
+	for i := range clients {
+		client := &clients[i]
+		if client.MemberID != nil {
+			matcher.byMemberID[strings.ToLower(*client.MemberID)] = client
+		}
+		if email := strings.ToLower(strings.TrimSpace(client.User.Email)); email != "" {
+			matcher.byEmail[email] = client
+		}
+		if client.User.PhoneNumber != nil {
+			if phone := phoneKey(*client.User.PhoneNumber); phone != "" {
+				matcher.byPhone[phone] = append(matcher.byPhone[phone], client)
+			}
+		}
+	}
+	return matcher
+}
+
+// match returns the client a reading belongs to and the identifier that
+// matched, or nil when none does. Identifiers that match different clients
+// are an error.
+func (m *clientMatcher) match(reading bodycomp.Reading) (*models.Trainee, string, error) {
+	var match *models.Trainee
+	matchedBy := ""
+	found := func(client *models.Trainee, by string) error {
+		if match != nil && match.ID != client.ID {
+			return fmt.Errorf("%s and %s match different clients", matchedBy, by)
+		}
+		if match == nil {
+			match, matchedBy = client, by
+		}
+		return nil
+	}
+
+	if reading.MemberID != "" {
+		if client, ok := m.byMemberID[strings.ToLower(reading.MemberID)]; ok {
+			if err := found(client, "memberId"); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	if reading.Email != "" {
+		if client, ok := m.byEmail[reading.Email]; ok {
+			if err := found(client, "email"); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	if phone := phoneKey(reading.Phone); phone != "" {
+		switch clients := m.byPhone[phone]; {
+		case len(clients) == 1:
+			if err := found(clients[0], "phone"); err != nil {
+				return nil, "", err
+			}
+		case len(clients) > 1 && match == nil:
+			return nil, "", fmt.Errorf("phone %s matches %d clients", reading.Phone, len(clients))
+		}
+	}
+	return match, matchedBy, nil
+}
+
+// phoneKey reduces a phone number to its last digits; numbers too short to
+// identify anyone give ""
+func phoneKey(phone string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phone)
+	if len(digits) < 6 {
+		return ""
+	}
+	if len(digits) > phoneMatchDigits {
+		digits = digits[len(digits)-phoneMatchDigits:]
+	}
+	return digits
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"fitness-training-backend/internal/database"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+const maxDigestItems = 10
+
+// QueueDigests creates the daily digest of every user whose digest time has
+// passed today, and returns how many were queued. The digest is delivered
+// like any other notification; the held deliveries it replaces are closed.
+func (w *DeliveryWorker) QueueDigests(now time.Time) (int, error) {
+	settings, err := w.settingsRepo.FindDigestEnabled()
+	if err != nil {
+		return 0, err
+	}
+
+	queued := 0
+	for i := range settings {
+		digestAt, due := settings[i].DigestDue(now)
+		if !due {
+			continue
+		}
+
+		ok, err := queueDigest(&settings[i], digestAt, now)
+		if err != nil {
+			log.Printf("⚠️  Digest failed for user %d: %v", settings[i].UserID, err)
+			continue
+		}
+		if ok {
+			queued++
+		}
+	}
+	return queued, nil
+}
+
+// queueDigest claims the user's digest and replaces their held deliveries with
+// one digest notification, in one transaction. It reports false when there
+// was nothing to send or another replica claimed it.
+func queueDigest(settings *models.NotificationSettings, digestAt, now time.Time) (bool, error) {
+	queued := false
+	err := database.Transaction(func(tx *gorm.DB) error {
+		claimed, err := repository.NewNotificationSettingsRepository(tx).ClaimDigest(settings.ID, digestAt, now)
+		if err != nil || !claimed {
+			return err
+		}
+
+		deliveryRepo := repository.NewNotificationDeliveryRepository(tx)
+		held, err := deliveryRepo.FindDigestHeld(settings.UserID)
+		if err != nil || len(held) == 0 {
+			return err
+		}
+
+		notification, channels := digestNotification(settings.UserID, held)
+		if err := repository.NewNotificationRepository(tx).CreateWithChannels(notification, channels); err != nil {
+			return err
+		}
+
+		ids := make([]uint, 0, len(held))
+		for _, delivery := range held {
+			ids = append(ids, delivery.ID)
+		}
+		queued = true
+		return deliveryRepo.MarkDigested(ids, notification.ID)
+	})
+	return queued, err
+}
+
+// digestNotification summarizes held notifications. It goes out through the
+// channels they were held for and is not added to the in-app list, which
+// already shows them one by one.
+func digestNotification(userID uint, held []models.NotificationDelivery) (*models.Notification, []string) {
+	var (
+		titles   []string
+		channels []string
+		seen     = map[uint]bool{}
+		hasChan  = map[string]bool{}
+	)
+	for _, delivery := range held {
+		if !hasChan[delivery.Channel] {
+			hasChan[delivery.Channel] = true
+			channels = append(channels, delivery.Channel)
+		}
+		if delivery.Notification.ID == 0 || seen[delivery.NotificationID] {
+			continue
+		}
+		seen[delivery.NotificationID] = true
+		titles = append(titles, delivery.Notification.Title)
+	}
+
+	var body strings.Builder
+	for i, title := range titles {
+		if i == maxDigestItems {
+			fmt.Fprintf(&body, "…and %d more", len(titles)-maxDigestItems)
+			break
+		}
+		fmt.Fprintf(&body, "• %s\n", title)
+	}
+
+	title := "Your daily summary"
+	if len(titles) == 1 {
+		title += ": 1 update"
+	} else if len(titles) > 1 {
+		title += fmt.Sprintf(": %d updates", len(titles))
+	}
+
+	return &models.Notification{
+		UserID:   userID,
+		Type:     "system",
+		Title:    title,
+		Message:  strings.TrimRight(body.String(), "\n"),
+		Priority: "low",
+		SentVia:  pq.StringArray{},
+	}, channels
+}
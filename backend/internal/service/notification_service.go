@@ -0,0 +1,285 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"fitness-training-backend/internal/config"
+	"fitness-training-backend/internal/database"
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/realtime"
+	"fitness-training-backend/internal/repository"
+	apperrors "fitness-training-backend/pkg/errors"
+	"fitness-training-backend/pkg/notify"
+	"fitness-training-backend/pkg/pubsub"
+
+	"gorm.io/gorm"
+)
+
+// streamBatchSize caps how many notifications one stream query returns
+const streamBatchSize = 100
+
+// NotificationService manages how a user is reached, in the app and outside it
+type NotificationService interface {
+	GetChannels(userID uint) (*dto.NotificationChannelsResponse, error)
+
+	// Stream
+	Subscribe(userID uint) (*pubsub.Subscription, error)
+	GetLatestNotificationID(userID uint) (uint, error)
+	GetNotificationsSince(userID, afterID uint) ([]dto.NotificationResponse, error)
+	GetUnreadCount(userID uint) (int64, error)
+
+	// Preferences
+	GetPreferences(userID uint) (*dto.NotificationPreferencesResponse, error)
+	UpdatePreferences(userID uint, req *dto.UpdateNotificationPreferencesRequest) (*dto.NotificationPreferencesResponse, error)
+
+	// Web Push
+	SubscribePush(userID uint, req *dto.PushSubscriptionRequest, userAgent string) error
+	UnsubscribePush(userID uint, endpoint string) error
+
+	// LINE
+	LinkLINEAccount(userID uint, req *dto.LinkLINEAccountRequest) error
+	UnlinkLINEAccount(userID uint) error
+}
+
+type notificationService struct {
+	notificationRepo repository.NotificationRepository
+	userRepo         repository.UserRepository
+	subscriptionRepo repository.PushSubscriptionRepository
+	settingsRepo     repository.NotificationSettingsRepository
+	cfg              *config.Config
+}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService(
+	notificationRepo repository.NotificationRepository,
+	userRepo repository.UserRepository,
+	subscriptionRepo repository.PushSubscriptionRepository,
+	settingsRepo repository.NotificationSettingsRepository,
+	cfg *config.Config,
+) NotificationService {
+	return &notificationService{
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		subscriptionRepo: subscriptionRepo,
+		settingsRepo:     settingsRepo,
+		cfg:              cfg,
+	}
+}
+
+// GetChannels reports which channels are configured and connected
+func (s *notificationService) GetChannels(userID uint) (*dto.NotificationChannelsResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	subscriptions, err := s.subscriptionRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := s.cfg.Delivery
+	resp := &dto.NotificationChannelsResponse{
+		Email: dto.NotificationChannelStatus{
+			Available: delivery.Enabled && delivery.SMTP.Host != "",
+			Connected: user.Email != "",
+		},
+		LINE: dto.NotificationChannelStatus{
+			Available: delivery.Enabled && delivery.LINE.ChannelAccessToken != "",
+			Connected: user.LineUserID != nil,
+		},
+	}
+	resp.Push.Available = delivery.Enabled && delivery.WebPush.PrivateKey != ""
+	resp.Push.Connected = len(subscriptions) > 0
+	resp.Push.Subscriptions = len(subscriptions)
+	if resp.Push.Available {
+		resp.Push.PublicKey = delivery.WebPush.PublicKey
+	}
+
+	return resp, nil
+}
+
+// Subscribe returns the user's realtime events
+func (s *notificationService) Subscribe(userID uint) (*pubsub.Subscription, error) {
+	return realtime.Subscribe(userID)
+}
+
+// GetLatestNotificationID returns where a new stream starts
+func (s *notificationService) GetLatestNotificationID(userID uint) (uint, error) {
+	return s.notificationRepo.LatestID(userID)
+}
+
+// GetNotificationsSince returns the in-app notifications created after
+// afterID, oldest first, at most streamBatchSize at a time
+func (s *notificationService) GetNotificationsSince(userID, afterID uint) ([]dto.NotificationResponse, error) {
+	notifications, err := s.notificationRepo.FindSince(userID, afterID, streamBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]dto.NotificationResponse, 0, len(notifications))
+	for i := range notifications {
+		data = append(data, toNotificationResponse(&notifications[i]))
+	}
+	return data, nil
+}
+
+// GetUnreadCount returns the number on the unread badge
+func (s *notificationService) GetUnreadCount(userID uint) (int64, error) {
+	return s.notificationRepo.CountUnread(userID)
+}
+
+// GetPreferences returns the user's preferences, or the defaults
+func (s *notificationService) GetPreferences(userID uint) (*dto.NotificationPreferencesResponse, error) {
+	settings, err := s.settingsRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return toNotificationPreferencesResponse(settings), nil
+}
+
+// UpdatePreferences applies the fields present in the request. Turning the
+// daily digest off sends the notifications it was holding right away.
+func (s *notificationService) UpdatePreferences(userID uint, req *dto.UpdateNotificationPreferencesRequest) (*dto.NotificationPreferencesResponse, error) {
+	settings, err := s.settingsRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyPreferenceChanges(settings, req); err != nil {
+		return nil, err
+	}
+
+	err = database.Transaction(func(tx *gorm.DB) error {
+		if err := repository.NewNotificationSettingsRepository(tx).Save(settings); err != nil {
+			return err
+		}
+		if settings.DailyDigest {
+			return nil
+		}
+		return repository.NewNotificationDeliveryRepository(tx).ReleaseDigestHeld(userID, time.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toNotificationPreferencesResponse(settings), nil
+}
+
+// applyPreferenceChanges validates the request and copies it onto settings.
+// Every type ends up with an explicit preference row.
+func applyPreferenceChanges(settings *models.NotificationSettings, req *dto.UpdateNotificationPreferencesRequest) error {
+	if req.Timezone != nil {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "" {
+			return fmt.Errorf("%w: unknown timezone %q", apperrors.ErrInvalidInput, *req.Timezone)
+		}
+		settings.Timezone = *req.Timezone
+	}
+
+	if req.QuietHours != nil {
+		if req.QuietHours.Enabled {
+			if err := validateTimeOfDay(req.QuietHours.Start); err != nil {
+				return err
+			}
+			if err := validateTimeOfDay(req.QuietHours.End); err != nil {
+				return err
+			}
+			if req.QuietHours.Start == req.QuietHours.End {
+				return fmt.Errorf("%w: quiet hours must not start and end at the same time", apperrors.ErrInvalidInput)
+			}
+			start, end := req.QuietHours.Start, req.QuietHours.End
+			settings.QuietHoursStart, settings.QuietHoursEnd = &start, &end
+		} else {
+			settings.QuietHoursStart, settings.QuietHoursEnd = nil, nil
+		}
+	}
+
+	if req.DailyDigest != nil {
+		if req.DailyDigest.Time != "" {
+			if err := validateTimeOfDay(req.DailyDigest.Time); err != nil {
+				return err
+			}
+			settings.DigestTime = req.DailyDigest.Time
+		}
+		settings.DailyDigest = req.DailyDigest.Enabled
+	}
+
+	for notificationType := range req.Types {
+		if !models.IsNotificationType(notificationType) {
+			return fmt.Errorf("%w: unknown notification type %q", apperrors.ErrInvalidInput, notificationType)
+		}
+	}
+
+	preferences := make([]models.NotificationPreference, 0, len(models.NotificationTypes))
+	for _, notificationType := range models.NotificationTypes {
+		preference := models.NotificationPreference{
+			UserID: settings.UserID,
+			Type:   notificationType,
+			InApp:  settings.Allows(notificationType, channelInApp),
+			Email:  settings.Allows(notificationType, notify.ChannelEmail),
+			Push:   settings.Allows(notificationType, notify.ChannelPush),
+			LINE:   settings.Allows(notificationType, notify.ChannelLINE),
+		}
+		if change, ok := req.Types[notificationType]; ok {
+			setIfPresent(&preference.InApp, change.InApp)
+			setIfPresent(&preference.Email, change.Email)
+			setIfPresent(&preference.Push, change.Push)
+			setIfPresent(&preference.LINE, change.LINE)
+		}
+		preferences = append(preferences, preference)
+	}
+	settings.Preferences = preferences
+
+	return nil
+}
+
+func setIfPresent(target *bool, value *bool) {
+	if value != nil {
+		*target = *value
+	}
+}
+
+// SubscribePush registers the browser for Web Push
+func (s *notificationService) SubscribePush(userID uint, req *dto.PushSubscriptionRequest, userAgent string) error {
+	endpoint, err := url.Parse(req.Endpoint)
+	if err != nil || endpoint.Scheme != "https" {
+		return fmt.Errorf("%w: push endpoint must be an https URL", apperrors.ErrInvalidInput)
+	}
+
+	subscription := &models.PushSubscription{
+		UserID:   userID,
+		Endpoint: req.Endpoint,
+		P256dh:   req.Keys.P256dh,
+		Auth:     req.Keys.Auth,
+	}
+	if userAgent != "" {
+		subscription.UserAgent = &userAgent
+	}
+	return s.subscriptionRepo.Upsert(subscription)
+}
+
+// UnsubscribePush removes the browser's subscription
+func (s *notificationService) UnsubscribePush(userID uint, endpoint string) error {
+	return s.subscriptionRepo.DeleteByEndpoint(userID, endpoint)
+}
+
+// LinkLINEAccount stores the user's LINE user ID. A LINE account can only be
+// linked to one user.
+func (s *notificationService) LinkLINEAccount(userID uint, req *dto.LinkLINEAccountRequest) error {
+	owner, err := s.userRepo.FindByLineUserID(req.LineUserID)
+	switch {
+	case err == nil && owner.ID != userID:
+		return fmt.Errorf("%w: LINE account is linked to another user", apperrors.ErrConflict)
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		return err
+	}
+
+	return s.userRepo.UpdateLineUserID(userID, &req.LineUserID)
+}
+
+// UnlinkLINEAccount stops LINE notifications for the user
+func (s *notificationService) UnlinkLINEAccount(userID uint) error {
+	return s.userRepo.UpdateLineUserID(userID, nil)
+}
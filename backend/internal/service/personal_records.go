@@ -0,0 +1,546 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+	"fitness-training-backend/pkg/units"
+
+	"gorm.io/gorm"
+)
+
+// Record types tracked per exercise
+const (
+	recordMaxWeight    = "max_weight"
+	recordRepsAtWeight = "reps_at_weight"
+	recordEstimated1RM = "estimated_1rm"
+	recordMaxDistance  = "max_distance"
+	recordBestPace     = "best_pace"
+)
+
+// Sets with more reps than this say little about a one-rep max
+const maxRepsFor1RM = 12
+
+// estimateOneRepMax uses Brzycki up to 10 reps, where it is the more
+// conservative formula, and Epley above
+func estimateOneRepMax(weight float32, reps int) float32 {
+	switch {
+	case reps <= 0 || weight <= 0:
+		return 0
+	case reps == 1:
+		return weight
+	case reps <= 10:
+		return weight * 36 / float32(37-reps)
+	default:
+		return weight * (1 + float32(reps)/30)
+	}
+}
+
+// exerciseBest is the best performance within one logged exercise
+type exerciseBest struct {
+	maxWeight     float32
+	maxWeightReps int
+	e1rm          float32
+	e1rmWeight    float32
+	e1rmReps      int
+	repsAtWeight  map[float32]int
+	maxDistance   float32
+	bestPace      float32 // seconds per km, 0 = none
+}
+
+// bestOfSets summarises the completed sets of an exercise. Sets without a
+// weight count as bodyweight (0 kg) for reps-at-weight.
+func bestOfSets(sets []models.ExerciseSet) exerciseBest {
+	best := exerciseBest{repsAtWeight: map[float32]int{}}
+
+	for _, set := range sets {
+		if !set.Completed {
+			continue
+		}
+
+		var weight float32
+		if set.Weight != nil && *set.Weight > 0 {
+			weight = *set.Weight
+		}
+
+		if set.Reps != nil && *set.Reps > 0 {
+			reps := *set.Reps
+			if reps > best.repsAtWeight[weight] {
+				best.repsAtWeight[weight] = reps
+			}
+			if weight > best.maxWeight || (weight == best.maxWeight && reps > best.maxWeightReps) {
+				best.maxWeight = weight
+				best.maxWeightReps = reps
+			}
+			if reps <= maxRepsFor1RM {
+				if e1rm := estimateOneRepMax(weight, reps); e1rm > best.e1rm {
+					best.e1rm = e1rm
+					best.e1rmWeight = weight
+					best.e1rmReps = reps
+				}
+			}
+		}
+
+		if set.Distance != nil && *set.Distance > 0 {
+			distance := *set.Distance
+			if distance > best.maxDistance {
+				best.maxDistance = distance
+			}
+			if set.Duration != nil && *set.Duration > 0 {
+				pace := float32(*set.Duration) / distance
+				if best.bestPace == 0 || pace < best.bestPace {
+					best.bestPace = pace
+				}
+			}
+		}
+	}
+
+	return best
+}
+
+// prEvent is a record broken by a logged exercise
+type prEvent struct {
+	recordType string
+	value      float32
+	previous   float32
+	weight     float32
+}
+
+// recordHistory replays the history of one exercise and returns the records
+// standing at the end plus the records each session exercise broke. The first
+// time a value is logged sets a baseline and is not reported as broken.
+func recordHistory(traineeID, exerciseLibraryID uint, history []models.SessionExercise) ([]models.PersonalRecord, map[uint][]prEvent) {
+	current := map[string]*models.PersonalRecord{}
+	// Rep records: only entries not beaten by more reps at a heavier weight
+	frontier := map[float32]*models.PersonalRecord{}
+	events := map[uint][]prEvent{}
+
+	for _, exercise := range history {
+		best := bestOfSets(exercise.Sets)
+		card := exercise.SessionCard
+
+		newRecord := func(recordType string, value, weight float32, reps *int) *models.PersonalRecord {
+			return &models.PersonalRecord{
+				TraineeID:         traineeID,
+				ExerciseLibraryID: exerciseLibraryID,
+				RecordType:        recordType,
+				Value:             value,
+				Weight:            weight,
+				Reps:              reps,
+				SessionCardID:     card.ID,
+				SessionExerciseID: exercise.ID,
+				AchievedAt:        card.Date,
+			}
+		}
+
+		// Higher is better for everything but pace
+		check := func(recordType string, value, weight float32, reps *int, lowerIsBetter bool) {
+			if value <= 0 {
+				return
+			}
+			prev, ok := current[recordType]
+			if ok {
+				improved := value > prev.Value
+				if lowerIsBetter {
+					improved = value < prev.Value
+				}
+				if !improved {
+					return
+				}
+				events[exercise.ID] = append(events[exercise.ID], prEvent{recordType: recordType, value: value, previous: prev.Value, weight: weight})
+			}
+			current[recordType] = newRecord(recordType, value, weight, reps)
+		}
+
+		if best.maxWeight > 0 {
+			reps := best.maxWeightReps
+			check(recordMaxWeight, best.maxWeight, best.maxWeight, &reps, false)
+		}
+		if best.e1rm > 0 {
+			reps := best.e1rmReps
+			check(recordEstimated1RM, best.e1rm, best.e1rmWeight, &reps, false)
+		}
+		check(recordMaxDistance, best.maxDistance, 0, nil, false)
+		check(recordBestPace, best.bestPace, 0, nil, true)
+
+		weights := make([]float32, 0, len(best.repsAtWeight))
+		for weight := range best.repsAtWeight {
+			weights = append(weights, weight)
+		}
+		sort.Slice(weights, func(i, j int) bool { return weights[i] > weights[j] })
+
+		// Compare against earlier sessions only, not other sets of this one
+		earlier := make(map[float32]int, len(frontier))
+		for w, record := range frontier {
+			earlier[w] = int(record.Value)
+		}
+
+		sessionBest := 0
+		for _, weight := range weights {
+			reps := best.repsAtWeight[weight]
+
+			// Beaten by more reps at a heavier weight in this session
+			if reps <= sessionBest {
+				continue
+			}
+			sessionBest = reps
+
+			// Best earlier reps at this weight or heavier
+			var previous int
+			for w, count := range earlier {
+				if w >= weight && count > previous {
+					previous = count
+				}
+			}
+			if reps <= previous {
+				continue
+			}
+			if previous > 0 {
+				events[exercise.ID] = append(events[exercise.ID], prEvent{recordType: recordRepsAtWeight, value: float32(reps), previous: float32(previous), weight: weight})
+			}
+
+			for w, record := range frontier {
+				if w <= weight && int(record.Value) <= reps {
+					delete(frontier, w)
+				}
+			}
+			count := reps
+			frontier[weight] = newRecord(recordRepsAtWeight, float32(reps), weight, &count)
+		}
+	}
+
+	records := make([]models.PersonalRecord, 0, len(current)+len(frontier))
+	for _, record := range current {
+		records = append(records, *record)
+	}
+	for _, record := range frontier {
+		records = append(records, *record)
+	}
+	return records, events
+}
+
+// syncPersonalRecords rebuilds the records of every exercise on the card and
+// flags the exercises holding one. Records the card's exercises broke become
+// achievements and a notification for the trainee; re-running it on an
+// unchanged or edited card does not repeat them. Use inside a transaction.
+func syncPersonalRecords(tx *gorm.DB, card *models.SessionCard) error {
+	recordRepo := repository.NewPersonalRecordRepository(tx)
+
+	seen := map[uint]bool{}
+	var broken []prResult
+
+	for i := range card.Exercises {
+		exercise := &card.Exercises[i]
+		if exercise.ExerciseLibraryID == nil || seen[*exercise.ExerciseLibraryID] {
+			continue
+		}
+		exerciseLibraryID := *exercise.ExerciseLibraryID
+		seen[exerciseLibraryID] = true
+
+		events, err := rebuildExerciseRecords(tx, recordRepo, card.TraineeID, exerciseLibraryID)
+		if err != nil {
+			return err
+		}
+
+		for j := range card.Exercises {
+			candidate := &card.Exercises[j]
+			if candidate.ExerciseLibraryID == nil || *candidate.ExerciseLibraryID != exerciseLibraryID {
+				continue
+			}
+			if prs := events[candidate.ID]; len(prs) > 0 {
+				broken = append(broken, prResult{exercise: candidate, events: prs})
+			}
+		}
+	}
+
+	if len(broken) == 0 {
+		return nil
+	}
+
+	trainee, err := repository.NewTraineeRepository(tx).FindByID(card.TraineeID)
+	if err != nil {
+		return err
+	}
+
+	for _, result := range broken {
+		if err := createPRAchievements(tx, trainee, card, result.exercise, result.events); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// prResult ties broken records to the session exercise that broke them
+type prResult struct {
+	exercise *models.SessionExercise
+	events   []prEvent
+}
+
+// rebuildPersonalRecords recomputes the records of the given exercises without
+// awarding anything, e.g. after a session card was deleted
+func rebuildPersonalRecords(tx *gorm.DB, traineeID uint, exerciseLibraryIDs []uint) error {
+	recordRepo := repository.NewPersonalRecordRepository(tx)
+	for _, exerciseLibraryID := range exerciseLibraryIDs {
+		if _, err := rebuildExerciseRecords(tx, recordRepo, traineeID, exerciseLibraryID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rebuildExerciseRecords replays the history of an exercise, stores the
+// standing records and updates the PR flags of every logged instance. It
+// returns the records each session exercise broke.
+func rebuildExerciseRecords(tx *gorm.DB, recordRepo repository.PersonalRecordRepository, traineeID, exerciseLibraryID uint) (map[uint][]prEvent, error) {
+	history, err := recordRepo.FindExerciseHistory(traineeID, exerciseLibraryID)
+	if err != nil {
+		return nil, err
+	}
+	records, events := recordHistory(traineeID, exerciseLibraryID, history)
+	if err := recordRepo.ReplaceForExercise(traineeID, exerciseLibraryID, records); err != nil {
+		return nil, err
+	}
+	if err := flagPRs(tx, history, records, events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// flagPRs marks the logged exercises that broke a record still standing, with
+// a note on those records, and clears the flag of the rest, whose records were
+// beaten since
+func flagPRs(tx *gorm.DB, history []models.SessionExercise, records []models.PersonalRecord, events map[uint][]prEvent) error {
+	standing := map[uint]map[string]bool{}
+	for _, record := range records {
+		if standing[record.SessionExerciseID] == nil {
+			standing[record.SessionExerciseID] = map[string]bool{}
+		}
+		standing[record.SessionExerciseID][recordKey(record.RecordType, record.Weight)] = true
+	}
+
+	for _, exercise := range history {
+		held := make([]prEvent, 0, len(events[exercise.ID]))
+		for _, event := range events[exercise.ID] {
+			if standing[exercise.ID][recordKey(event.recordType, event.weight)] {
+				held = append(held, event)
+			}
+		}
+
+		isPR := len(held) > 0
+		var note *string
+		if isPR {
+			described := describePRs(held)
+			note = &described
+		}
+		if exercise.IsPR == isPR && equalStringPtr(exercise.PRNote, note) {
+			continue
+		}
+		if err := tx.Model(&models.SessionExercise{}).Where("id = ?", exercise.ID).
+			Updates(map[string]interface{}{"is_pr": isPR, "pr_note": note}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordKey tells records of one type apart; rep records are kept per weight
+func recordKey(recordType string, weight float32) string {
+	if recordType == recordRepsAtWeight {
+		return recordType + "@" + formatAmount(weight)
+	}
+	return recordType
+}
+
+func equalStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// prAchievementKey identifies the achievement for a record type broken by an
+// exercise of a card. Editing a card recreates its exercise rows, so the key
+// uses the card and the library exercise rather than the row.
+func prAchievementKey(card *models.SessionCard, exercise *models.SessionExercise, recordType string) string {
+	return fmt.Sprintf("pr_%d_%d_%s", card.ID, *exercise.ExerciseLibraryID, recordType)
+}
+
+// createPRAchievements awards an achievement per record type the exercise
+// broke, once per card, and notifies the trainee of the new ones
+func createPRAchievements(tx *gorm.DB, trainee *models.Trainee, card *models.SessionCard, exercise *models.SessionExercise, events []prEvent) error {
+	achievementRepo := repository.NewAchievementRepository(tx)
+	icon := "🏆"
+	color := "#F59E0B"
+	title := "New PR: " + exercise.Name
+
+	byType := map[string][]prEvent{}
+	types := make([]string, 0, len(events))
+	for _, event := range events {
+		if _, ok := byType[event.recordType]; !ok {
+			types = append(types, event.recordType)
+		}
+		byType[event.recordType] = append(byType[event.recordType], event)
+	}
+
+	var awarded []prEvent
+	for _, recordType := range types {
+		typeEvents := byType[recordType]
+		note := describePRs(typeEvents)
+		value := int(math.Round(float64(typeEvents[0].value)))
+		key := prAchievementKey(card, exercise, recordType)
+
+		created, err := achievementRepo.CreateOnce(&models.Achievement{
+			TraineeID:   trainee.ID,
+			RuleKey:     &key,
+			Type:        "pr",
+			Title:       title,
+			Description: &note,
+			BadgeIcon:   &icon,
+			BadgeColor:  &color,
+			Value:       &value,
+			AchievedAt:  card.Date,
+		})
+		if err != nil {
+			return err
+		}
+		if created {
+			awarded = append(awarded, typeEvents...)
+		}
+	}
+	if len(awarded) == 0 {
+		return nil
+	}
+
+	relatedType := "session_card"
+	return repository.NewNotificationRepository(tx).Create(&models.Notification{
+		UserID:      trainee.UserID,
+		Type:        "achievement",
+		Title:       title,
+		Message:     describePRs(awarded),
+		RelatedID:   &card.ID,
+		RelatedType: &relatedType,
+		Priority:    "medium",
+	})
+}
+
+// describePRs renders the broken records, e.g.
+// "Heaviest weight: 100 kg (previous 95 kg); 8 reps at 80 kg (previous 6)"
+func describePRs(events []prEvent) string {
+	parts := make([]string, 0, len(events))
+	for _, event := range events {
+		switch event.recordType {
+		case recordMaxWeight:
+			parts = append(parts, fmt.Sprintf("Heaviest weight: %s kg (previous %s kg)", formatAmount(event.value), formatAmount(event.previous)))
+		case recordEstimated1RM:
+			parts = append(parts, fmt.Sprintf("Estimated 1RM: %s kg (previous %s kg)", formatAmount(event.value), formatAmount(event.previous)))
+		case recordRepsAtWeight:
+			if event.weight > 0 {
+				parts = append(parts, fmt.Sprintf("%d reps at %s kg (previous %d)", int(event.value), formatAmount(event.weight), int(event.previous)))
+			} else {
+				parts = append(parts, fmt.Sprintf("%d bodyweight reps (previous %d)", int(event.value), int(event.previous)))
+			}
+		case recordMaxDistance:
+			parts = append(parts, fmt.Sprintf("Longest distance: %s km (previous %s km)", formatAmount(event.value), formatAmount(event.previous)))
+		case recordBestPace:
+			parts = append(parts, fmt.Sprintf("Fastest pace: %s /km (previous %s /km)", formatPace(event.value), formatPace(event.previous)))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// formatAmount prints at most two decimals without trailing zeros
+func formatAmount(value float32) string {
+	return strconv.FormatFloat(math.Round(float64(value)*100)/100, 'f', -1, 64)
+}
+
+// formatPace prints seconds per km as m:ss
+func formatPace(secondsPerKm float32) string {
+	total := int(math.Round(float64(secondsPerKm)))
+	return fmt.Sprintf("%d:%02d", total/60, total%60)
+}
+
+// toPersonalRecordResponses groups records by exercise, heaviest rep records
+// first, with weights and distances in prefs
+func toPersonalRecordResponses(records []models.PersonalRecord, prefs units.Preferences) []dto.ExerciseRecordsResponse {
+	responses := make([]dto.ExerciseRecordsResponse, 0)
+	index := map[uint]int{}
+
+	for _, record := range records {
+		i, ok := index[record.ExerciseLibraryID]
+		if !ok {
+			i = len(responses)
+			index[record.ExerciseLibraryID] = i
+			responses = append(responses, dto.ExerciseRecordsResponse{
+				ExerciseLibraryID: record.ExerciseLibraryID,
+				ExerciseName:      record.ExerciseLibrary.Name,
+				Category:          record.ExerciseLibrary.Category,
+				Records:           []dto.PersonalRecordResponse{},
+			})
+		}
+
+		resp := dto.PersonalRecordResponse{
+			Type:          record.RecordType,
+			Value:         recordValue(record.RecordType, record.Value, prefs),
+			Unit:          recordUnit(record.RecordType, prefs),
+			Reps:          record.Reps,
+			SessionCardID: record.SessionCardID,
+			SessionTitle:  record.SessionCard.Title,
+			AchievedAt:    record.AchievedAt,
+		}
+		if record.RecordType == recordRepsAtWeight || record.RecordType == recordMaxWeight || record.RecordType == recordEstimated1RM {
+			weight := displayValue(record.Weight, prefs.For(units.Mass))
+			resp.Weight = &weight
+		}
+		responses[i].Records = append(responses[i].Records, resp)
+	}
+
+	return responses
+}
+
+// recordValue converts a stored record (kg, km or seconds per km) to prefs
+func recordValue(recordType string, value float32, prefs units.Preferences) float32 {
+	switch recordType {
+	case recordRepsAtWeight:
+		return value
+	case recordMaxDistance:
+		return displayValue(value, prefs.For(units.Distance))
+	case recordBestPace:
+		// Seconds per km become seconds per preferred distance unit
+		return roundUnitValue(float64(value) * units.ToCanonical(1, prefs.For(units.Distance)))
+	default:
+		return displayValue(value, prefs.For(units.Mass))
+	}
+}
+
+func recordUnit(recordType string, prefs units.Preferences) string {
+	switch recordType {
+	case recordRepsAtWeight:
+		return "reps"
+	case recordMaxDistance:
+		return string(prefs.For(units.Distance))
+	case recordBestPace:
+		return "sec/" + string(prefs.For(units.Distance))
+	default:
+		return string(prefs.For(units.Mass))
+	}
+}
+
+// cardExerciseIDs lists the distinct library exercises on a card
+func cardExerciseIDs(card *models.SessionCard) []uint {
+	seen := map[uint]bool{}
+	ids := make([]uint, 0, len(card.Exercises))
+	for _, exercise := range card.Exercises {
+		if exercise.ExerciseLibraryID != nil && !seen[*exercise.ExerciseLibraryID] {
+			seen[*exercise.ExerciseLibraryID] = true
+			ids = append(ids, *exercise.ExerciseLibraryID)
+		}
+	}
+	return ids
+}
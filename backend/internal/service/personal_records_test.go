@@ -0,0 +1,206 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"fitness-training-backend/internal/models"
+)
+
+func cardioSet(distance float32, seconds int) models.ExerciseSet {
+	return models.ExerciseSet{Distance: &distance, Duration: &seconds, Completed: true}
+}
+
+func TestEstimateOneRepMax(t *testing.T) {
+	tests := []struct {
+		name   string
+		weight float32
+		reps   int
+		want   float32
+	}{
+		{"no reps", 100, 0, 0},
+		{"no weight", 0, 5, 0},
+		{"single", 100, 1, 100},
+		{"Brzycki", 100, 5, 112.5},
+		{"Brzycki at 10 reps", 100, 10, 133.33},
+		{"Epley above 10 reps", 100, 11, 136.67},
+		{"Epley at 12 reps", 100, 12, 140},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := estimateOneRepMax(tt.weight, tt.reps); !closeTo(got, tt.want) {
+				t.Errorf("estimateOneRepMax(%v, %d) = %v, want %v", tt.weight, tt.reps, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBestOfSets(t *testing.T) {
+	skipped := lifted(1, 1, 200, 0)
+	skipped.Completed = false
+
+	tests := []struct {
+		name          string
+		sets          []models.ExerciseSet
+		maxWeight     float32
+		maxWeightReps int
+		e1rm          float32
+		e1rmWeight    float32
+		e1rmReps      int
+		repsAtWeight  map[float32]int
+	}{
+		{name: "no sets", repsAtWeight: map[float32]int{}},
+		{name: "incomplete sets are ignored", sets: []models.ExerciseSet{skipped, lifted(1, 5, 100, 0)},
+			maxWeight: 100, maxWeightReps: 5, e1rm: 112.5, e1rmWeight: 100, e1rmReps: 5,
+			repsAtWeight: map[float32]int{100: 5}},
+		{name: "more reps at the top weight", sets: []models.ExerciseSet{lifted(1, 3, 100, 0), lifted(1, 5, 100, 0), lifted(1, 4, 100, 0)},
+			maxWeight: 100, maxWeightReps: 5, e1rm: 112.5, e1rmWeight: 100, e1rmReps: 5,
+			repsAtWeight: map[float32]int{100: 5}},
+
+		// Sets above maxRepsFor1RM reps do not estimate a one-rep max
+		{name: "12 reps still estimate", sets: []models.ExerciseSet{lifted(1, 12, 60, 0)},
+			maxWeight: 60, maxWeightReps: 12, e1rm: 84, e1rmWeight: 60, e1rmReps: 12,
+			repsAtWeight: map[float32]int{60: 12}},
+		{name: "13 reps do not", sets: []models.ExerciseSet{lifted(1, 13, 60, 0), lifted(1, 5, 50, 0)},
+			maxWeight: 60, maxWeightReps: 13, e1rm: 56.25, e1rmWeight: 50, e1rmReps: 5,
+			repsAtWeight: map[float32]int{60: 13, 50: 5}},
+
+		// 80 kg x 5 and 90 kg x 1 both estimate 90 kg: the first set stands
+		{name: "tied estimates keep the first set", sets: []models.ExerciseSet{lifted(1, 5, 80, 0), lifted(1, 1, 90, 0)},
+			maxWeight: 90, maxWeightReps: 1, e1rm: 90, e1rmWeight: 80, e1rmReps: 5,
+			repsAtWeight: map[float32]int{80: 5, 90: 1}},
+		{name: "bodyweight", sets: []models.ExerciseSet{lifted(1, 15, 0, 0), lifted(1, 12, 0, 0)},
+			maxWeightReps: 15, repsAtWeight: map[float32]int{0: 15}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			best := bestOfSets(tt.sets)
+			if best.maxWeight != tt.maxWeight || best.maxWeightReps != tt.maxWeightReps {
+				t.Errorf("max weight %v x %d, want %v x %d", best.maxWeight, best.maxWeightReps, tt.maxWeight, tt.maxWeightReps)
+			}
+			if !closeTo(best.e1rm, tt.e1rm) || best.e1rmWeight != tt.e1rmWeight || best.e1rmReps != tt.e1rmReps {
+				t.Errorf("e1RM %v from %v x %d, want %v from %v x %d",
+					best.e1rm, best.e1rmWeight, best.e1rmReps, tt.e1rm, tt.e1rmWeight, tt.e1rmReps)
+			}
+			if fmt.Sprint(best.repsAtWeight) != fmt.Sprint(tt.repsAtWeight) {
+				t.Errorf("reps at weight %v, want %v", best.repsAtWeight, tt.repsAtWeight)
+			}
+		})
+	}
+}
+
+func TestBestOfSetsCardio(t *testing.T) {
+	best := bestOfSets([]models.ExerciseSet{cardioSet(5, 1500), cardioSet(10, 3300), {Distance: f32(3)}})
+	if best.maxDistance != 10 || best.bestPace != 300 {
+		t.Errorf("max distance %v, best pace %v; want 10 and 300", best.maxDistance, best.bestPace)
+	}
+}
+
+// loggedExercise is session exercise id logged on day day of March 2024
+func loggedExercise(id uint, day int, sets ...models.ExerciseSet) models.SessionExercise {
+	return models.SessionExercise{
+		ID:          id,
+		SessionCard: models.SessionCard{ID: 10 + id, Date: time.Date(2024, 3, day, 0, 0, 0, 0, time.UTC)},
+		Sets:        sets,
+	}
+}
+
+func describeRecords(records []models.PersonalRecord) string {
+	lines := make([]string, 0, len(records))
+	for _, record := range records {
+		lines = append(lines, fmt.Sprintf("%s %.2f@%v from %d", record.RecordType, record.Value, record.Weight, record.SessionExerciseID))
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "; ")
+}
+
+func describeEvents(events []prEvent) string {
+	lines := make([]string, 0, len(events))
+	for _, event := range events {
+		lines = append(lines, fmt.Sprintf("%s %.2f (was %.2f)@%v", event.recordType, event.value, event.previous, event.weight))
+	}
+	return strings.Join(lines, "; ")
+}
+
+func TestRecordHistory(t *testing.T) {
+	history := []models.SessionExercise{
+		loggedExercise(1, 4, lifted(1, 5, 100, 0)),
+		// Heavier, but a lower estimate
+		loggedExercise(2, 6, lifted(1, 3, 105, 0)),
+		// More reps at 100 kg
+		loggedExercise(3, 8, lifted(1, 6, 100, 0)),
+		// Equalling a record does not break it
+		loggedExercise(4, 11, lifted(1, 3, 105, 0), lifted(1, 6, 100, 0)),
+	}
+
+	records, events := recordHistory(7, 3, history)
+
+	want := "estimated_1rm 116.13@100 from 3; max_weight 105.00@105 from 2; " +
+		"reps_at_weight 3.00@105 from 2; reps_at_weight 6.00@100 from 3"
+	if got := describeRecords(records); got != want {
+		t.Errorf("records = %s\nwant %s", got, want)
+	}
+	for _, record := range records {
+		if record.TraineeID != 7 || record.ExerciseLibraryID != 3 || record.SessionCardID != 10+record.SessionExerciseID {
+			t.Errorf("record %+v is not linked to its trainee, exercise and card", record)
+		}
+	}
+
+	wantEvents := map[uint]string{
+		// The first session sets the baseline
+		1: "",
+		2: "max_weight 105.00 (was 100.00)@105",
+		3: "estimated_1rm 116.13 (was 112.50)@100; reps_at_weight 6.00 (was 5.00)@100",
+		4: "",
+	}
+	for id, want := range wantEvents {
+		if got := describeEvents(events[id]); got != want {
+			t.Errorf("session exercise %d broke %q, want %q", id, got, want)
+		}
+	}
+}
+
+func TestRecordHistoryRepsBeatenByHeavierSet(t *testing.T) {
+	history := []models.SessionExercise{
+		loggedExercise(1, 4, lifted(1, 5, 100, 0), lifted(1, 8, 80, 0)),
+		// 9 reps at 90 kg beat the best earlier at 90 kg or heavier (5 at
+		// 100 kg), and replace the 8 at 80 kg
+		loggedExercise(2, 6, lifted(1, 9, 90, 0), lifted(1, 9, 80, 0)),
+	}
+
+	records, events := recordHistory(7, 3, history)
+
+	var reps []string
+	for _, record := range records {
+		if record.RecordType == recordRepsAtWeight {
+			reps = append(reps, fmt.Sprintf("%v x %v", record.Weight, record.Value))
+		}
+	}
+	sort.Strings(reps)
+	if got := strings.Join(reps, ", "); got != "100 x 5, 90 x 9" {
+		t.Errorf("rep records = %s, want 100 x 5, 90 x 9", got)
+	}
+	if got := describeEvents(events[2]); !strings.Contains(got, "reps_at_weight 9.00 (was 5.00)@90") || strings.Contains(got, "@80") {
+		t.Errorf("session 2 broke %q, want one rep record at 90 kg", got)
+	}
+}
+
+func TestRecordHistoryPace(t *testing.T) {
+	history := []models.SessionExercise{
+		loggedExercise(1, 4, cardioSet(5, 1500)),
+		loggedExercise(2, 6, cardioSet(5, 1400)),
+		loggedExercise(3, 8, cardioSet(8, 2800)),
+	}
+
+	_, events := recordHistory(7, 3, history)
+
+	if got, want := describeEvents(events[2]), "best_pace 280.00 (was 300.00)@0"; got != want {
+		t.Errorf("session 2 broke %q, want %q", got, want)
+	}
+	if got, want := describeEvents(events[3]), "max_distance 8.00 (was 5.00)@0"; got != want {
+		t.Errorf("session 3 broke %q, want %q", got, want)
+	}
+}
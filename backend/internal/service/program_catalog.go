@@ -0,0 +1,269 @@
+package service
+
+import (
+	"errors"
+
+	"fitness-training-backend/internal/database"
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+	apperrors "fitness-training-backend/pkg/errors"
+	"fitness-training-backend/pkg/units"
+
+	"gorm.io/gorm"
+)
+
+// Program visibilities. Only active programs are shared.
+const (
+	programVisibilityPrivate = "private" // the owner only
+	programVisibilityGym     = "gym"     // every trainer of the gym
+	programVisibilityPublic  = "public"  // anyone, signed in or not
+)
+
+// ==========================================
+// CATALOG
+// ==========================================
+
+// GetCatalog pages through the programs shared with the caller: public ones
+// for everybody, gym-wide ones too for trainers. userID is 0 for visitors.
+func (s *programService) GetCatalog(userID uint, filters map[string]interface{}, page, pageSize int) (*dto.PaginatedResponse, error) {
+	visibilities, _, err := s.catalogVisibilities(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	programs, total, err := s.programRepo.FindCatalog(visibilities, filters, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(programs))
+	for i := range programs {
+		ids = append(ids, programs[i].ID)
+	}
+	equipment, err := s.programRepo.FindEquipment(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]dto.CatalogProgramResponse, 0, len(programs))
+	for i := range programs {
+		data = append(data, toCatalogProgramResponse(&programs[i], equipment[programs[i].ID]))
+	}
+	return newPaginatedResponse(data, page, pageSize, total), nil
+}
+
+// GetCatalogProgram returns a shared program with the plan of its newest
+// version. Trainers also see their own programs, shared or not.
+func (s *programService) GetCatalogProgram(userID, programID uint) (*dto.CatalogProgramResponse, error) {
+	visibilities, trainer, err := s.catalogVisibilities(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	program, err := s.programRepo.FindByID(programID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if !canBrowseProgram(program, trainer, visibilities) {
+		return nil, apperrors.ErrNotFound
+	}
+
+	_, days, err := s.getVersionPlan(program, program.Version)
+	if err != nil {
+		return nil, err
+	}
+	equipment, err := s.programRepo.FindEquipment([]uint{program.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	prefs := units.Metric()
+	if trainer != nil {
+		prefs = trainer.User.Units()
+	}
+	resp := toCatalogProgramResponse(program, equipment[program.ID])
+	resp.Weeks = toProgramWeeks(program.TotalWeeks, days, prefs)
+	return &resp, nil
+}
+
+// CloneProgram copies the newest version of a program the trainer can browse
+// into a private draft of their own, with its weeks, days, exercises and
+// prescribed sets. The copy credits the program it came from and the author
+// of the original. Exercises from another trainer's private library are
+// copied into the caller's library so the plan stays editable.
+func (s *programService) CloneProgram(userID, programID uint, req *dto.CloneProgramRequest) (*dto.TrainerProgramResponse, error) {
+	visibilities, trainer, err := s.catalogVisibilities(userID)
+	if err != nil {
+		return nil, err
+	}
+	if trainer == nil {
+		return nil, apperrors.ErrNotFound
+	}
+
+	source, err := s.programRepo.FindByID(programID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if !canBrowseProgram(source, trainer, visibilities) {
+		return nil, apperrors.ErrNotFound
+	}
+
+	head, days, err := s.getVersionPlan(source, source.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	clone := newProgramClone(source, head, trainer.ID, req.Name)
+
+	err = database.Transaction(func(tx *gorm.DB) error {
+		exerciseRepo := repository.NewExerciseRepository(tx)
+
+		if err := repository.NewProgramRepository(tx).Create(clone); err != nil {
+			return err
+		}
+		version := newProgramVersion(clone)
+		if err := repository.NewProgramVersionRepository(tx).Create(&version); err != nil {
+			return err
+		}
+
+		clonedDays, err := cloneProgramDays(repository.NewProgramPlanRepository(tx), exerciseRepo, trainer, clone.ID, version.ID, days)
+		if err != nil {
+			return err
+		}
+		if err := exerciseRepo.RefreshUsage(plannedExerciseIDs(clonedDays)); err != nil {
+			return err
+		}
+
+		// Copies of one's own programs do not make them popular
+		if source.TrainerID == trainer.ID {
+			return nil
+		}
+		return tx.Model(&models.Program{}).Where("id = ?", source.ID).
+			UpdateColumn("clone_count", gorm.Expr("clone_count + 1")).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	program, err := s.programRepo.FindByID(clone.ID)
+	if err != nil {
+		return nil, err
+	}
+	resp := toTrainerProgramResponse(program)
+	return &resp, nil
+}
+
+// newProgramClone returns a private draft of the trainer with the content of
+// the source's newest version, crediting the source and the author of the
+// first program it was cloned from
+func newProgramClone(source *models.Program, head *models.ProgramVersion, trainerID uint, name *string) *models.Program {
+	clone := &models.Program{
+		TrainerID:         trainerID,
+		Status:            "draft",
+		Visibility:        programVisibilityPrivate,
+		Version:           1,
+		ClonedFromID:      &source.ID,
+		ClonedFromVersion: &source.Version,
+		OriginalAuthorID:  source.OriginalAuthorID,
+	}
+	applyVersionContent(clone, head)
+	if name != nil {
+		clone.Name = *name
+	}
+	if clone.OriginalAuthorID == nil {
+		clone.OriginalAuthorID = &source.TrainerID
+	}
+	return clone
+}
+
+// cloneProgramDays copies days into a version of the clone. Exercises the
+// trainer cannot use are copied into their library once and the plan points
+// to the copies.
+func cloneProgramDays(planRepo repository.ProgramPlanRepository, exerciseRepo repository.ExerciseRepository, trainer *models.Trainer, programID, versionID uint, days []models.ProgramDay) ([]models.ProgramDay, error) {
+	copied := map[uint]uint{}
+	clonedDays := make([]models.ProgramDay, 0, len(days))
+	for i := range days {
+		day := copyProgramDay(&days[i], versionID, days[i].Week)
+		day.ProgramID = programID
+
+		for j := range day.Exercises {
+			exercise := days[i].Exercises[j].ExerciseLibrary
+			if exercise == nil || canUseExercise(trainer, exercise) {
+				continue
+			}
+			if _, ok := copied[exercise.ID]; !ok {
+				own := copyExercise(exercise, trainer.ID)
+				if err := exerciseRepo.Create(&own); err != nil {
+					return nil, err
+				}
+				copied[exercise.ID] = own.ID
+			}
+			day.Exercises[j].ExerciseLibraryID = copied[exercise.ID]
+		}
+
+		if err := planRepo.CreateDay(&day); err != nil {
+			return nil, err
+		}
+		clonedDays = append(clonedDays, day)
+	}
+	return clonedDays, nil
+}
+
+// catalogVisibilities returns what the caller may browse, and the caller's
+// trainer profile if they have one
+func (s *programService) catalogVisibilities(userID uint) ([]string, *models.Trainer, error) {
+	visibilities := []string{programVisibilityPublic}
+	if userID == 0 {
+		return visibilities, nil, nil
+	}
+
+	trainer, err := s.trainerRepo.FindByUserID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return visibilities, nil, nil
+		}
+		return nil, nil, err
+	}
+	return append(visibilities, programVisibilityGym), trainer, nil
+}
+
+// canBrowseProgram allows a trainer's own programs and active programs
+// shared with one of the visibilities
+func canBrowseProgram(program *models.Program, trainer *models.Trainer, visibilities []string) bool {
+	if trainer != nil && program.TrainerID == trainer.ID {
+		return true
+	}
+	if program.Status != "active" {
+		return false
+	}
+	for _, visibility := range visibilities {
+		if program.Visibility == visibility {
+			return true
+		}
+	}
+	return false
+}
+
+// canUseExercise allows the shared library, public exercises and the
+// trainer's own
+func canUseExercise(trainer *models.Trainer, exercise *models.ExerciseLibrary) bool {
+	return exercise.TrainerID == nil || *exercise.TrainerID == trainer.ID || exercise.IsPublic
+}
+
+// copyExercise copies an exercise into a trainer's private library
+func copyExercise(source *models.ExerciseLibrary, trainerID uint) models.ExerciseLibrary {
+	return models.ExerciseLibrary{
+		TrainerID:    &trainerID,
+		Name:         source.Name,
+		Category:     source.Category,
+		Description:  source.Description,
+		MuscleGroups: source.MuscleGroups,
+		Equipment:    source.Equipment,
+		Difficulty:   source.Difficulty,
+		Instructions: source.Instructions,
+		VideoURL:     source.VideoURL,
+		ThumbnailURL: source.ThumbnailURL,
+		Images:       source.Images,
+	}
+}
@@ -0,0 +1,412 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+	apperrors "fitness-training-backend/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+// fakeCatalog holds programs by ID and one catalog page, and records the
+// catalog query
+type fakeCatalog struct {
+	repository.ProgramRepository
+	programs  map[uint]*models.Program
+	page      []models.Program
+	total     int64
+	equipment map[uint][]string
+
+	visibilities  []string
+	filters       map[string]interface{}
+	limit, offset int
+}
+
+func (f *fakeCatalog) FindByID(id uint) (*models.Program, error) {
+	program, ok := f.programs[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return program, nil
+}
+
+func (f *fakeCatalog) FindCatalog(visibilities []string, filters map[string]interface{}, limit, offset int) ([]models.Program, int64, error) {
+	f.visibilities, f.filters, f.limit, f.offset = visibilities, filters, limit, offset
+	return f.page, f.total, nil
+}
+
+func (f *fakeCatalog) FindEquipment(programIDs []uint) (map[uint][]string, error) {
+	equipment := map[uint][]string{}
+	for _, id := range programIDs {
+		if list, ok := f.equipment[id]; ok {
+			equipment[id] = list
+		}
+	}
+	return equipment, nil
+}
+
+// fakeVersions holds the newest version of each program
+type fakeVersions struct {
+	repository.ProgramVersionRepository
+	versions map[uint]*models.ProgramVersion
+}
+
+func (f *fakeVersions) FindByNumber(programID uint, version int) (*models.ProgramVersion, error) {
+	head, ok := f.versions[programID]
+	if !ok || head.Version != version {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return head, nil
+}
+
+// fakePlans holds the days of each version and records the days created
+type fakePlans struct {
+	repository.ProgramPlanRepository
+	days    map[uint][]models.ProgramDay
+	created []models.ProgramDay
+}
+
+func (f *fakePlans) FindDays(versionID uint, week int) ([]models.ProgramDay, error) {
+	return f.days[versionID], nil
+}
+
+func (f *fakePlans) CreateDay(day *models.ProgramDay) error {
+	day.ID = uint(100 + len(f.created))
+	f.created = append(f.created, *day)
+	return nil
+}
+
+// fakeExerciseCopies records the exercises copied into a trainer's library
+type fakeExerciseCopies struct {
+	repository.ExerciseRepository
+	created []models.ExerciseLibrary
+}
+
+func (f *fakeExerciseCopies) Create(exercise *models.ExerciseLibrary) error {
+	exercise.ID = uint(200 + len(f.created))
+	f.created = append(f.created, *exercise)
+	return nil
+}
+
+// Trainer 1 (user 10) browses; trainer 2 (user 20) wrote the programs
+func catalogTestTrainer() *models.Trainer {
+	return &models.Trainer{ID: 1, UserID: 10, User: models.User{Name: "Sam", WeightUnit: "lb"}}
+}
+
+func sharedProgram(id, trainerID uint, status, visibility string) *models.Program {
+	return &models.Program{
+		ID: id, TrainerID: trainerID, Name: fmt.Sprintf("Program %d", id), Status: status, Visibility: visibility,
+		Version: 2, TotalWeeks: 2, SessionsPerWeek: 3,
+	}
+}
+
+func TestCanBrowseProgram(t *testing.T) {
+	visitor := []string{programVisibilityPublic}
+	trainer := []string{programVisibilityPublic, programVisibilityGym}
+
+	tests := []struct {
+		name         string
+		program      *models.Program
+		trainer      *models.Trainer
+		visibilities []string
+		want         bool
+	}{
+		{"public for visitors", sharedProgram(4, 2, "active", "public"), nil, visitor, true},
+		{"gym-wide hidden from visitors", sharedProgram(4, 2, "active", "gym"), nil, visitor, false},
+		{"gym-wide for trainers", sharedProgram(4, 2, "active", "gym"), catalogTestTrainer(), trainer, true},
+		{"another trainer's private program", sharedProgram(4, 2, "active", "private"), catalogTestTrainer(), trainer, false},
+		{"public draft", sharedProgram(4, 2, "draft", "public"), catalogTestTrainer(), trainer, false},
+		{"public archived", sharedProgram(4, 2, "archived", "public"), nil, visitor, false},
+		{"own private draft", sharedProgram(4, 1, "draft", "private"), catalogTestTrainer(), trainer, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canBrowseProgram(tt.program, tt.trainer, tt.visibilities); got != tt.want {
+				t.Errorf("canBrowseProgram = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCatalogVisibilities(t *testing.T) {
+	s := &programService{trainerRepo: &fakeTrainers{trainer: catalogTestTrainer()}}
+
+	tests := []struct {
+		name        string
+		userID      uint
+		want        string
+		wantTrainer bool
+	}{
+		{"visitor", 0, "public", false},
+		{"client", 30, "public", false},
+		{"trainer", 10, "public gym", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			visibilities, trainer, err := s.catalogVisibilities(tt.userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(visibilities, " "); got != tt.want || (trainer != nil) != tt.wantTrainer {
+				t.Errorf("visibilities %s with trainer %v, want %s with trainer %v", got, trainer != nil, tt.want, tt.wantTrainer)
+			}
+		})
+	}
+}
+
+func TestGetCatalog(t *testing.T) {
+	sourceID, authorID := uint(9), uint(3)
+	cloned := *sharedProgram(5, 2, "active", "public")
+	cloned.ClonedFromID = &sourceID
+	cloned.ClonedFromVersion = intPtr(1)
+	cloned.OriginalAuthorID = &authorID
+	cloned.OriginalAuthor = &models.Trainer{ID: 3, User: models.User{Name: "Robin"}}
+
+	programs := &fakeCatalog{
+		page:      []models.Program{*sharedProgram(4, 2, "active", "gym"), cloned},
+		total:     5,
+		equipment: map[uint][]string{4: {"barbell", "bench"}},
+	}
+	s := &programService{trainerRepo: &fakeTrainers{trainer: catalogTestTrainer()}, programRepo: programs}
+	filters := map[string]interface{}{"goal": "strength", "level": "beginner", "maxWeeks": 8, "equipment": []string{"barbell", "bench"}}
+
+	resp, err := s.GetCatalog(10, filters, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(programs.visibilities, " "); got != "public gym" {
+		t.Errorf("queried visibilities %s, want public gym", got)
+	}
+	if fmt.Sprint(programs.filters) != fmt.Sprint(filters) || programs.limit != 2 || programs.offset != 2 {
+		t.Errorf("queried %v limit %d offset %d, want the filters of page 2", programs.filters, programs.limit, programs.offset)
+	}
+	if resp.Page != 2 || resp.TotalItems != 5 || resp.TotalPages != 3 {
+		t.Errorf("page %d of %d with %d programs, want 2 of 3 with 5", resp.Page, resp.TotalPages, resp.TotalItems)
+	}
+
+	data := resp.Data.([]dto.CatalogProgramResponse)
+	if len(data) != 2 {
+		t.Fatalf("listed %d programs, want 2", len(data))
+	}
+	if data[0].ID != 4 || fmt.Sprint(data[0].Equipment) != "[barbell bench]" || data[0].ClonedFrom != nil {
+		t.Errorf("first program = %+v, want program 4 needing a barbell and a bench", data[0])
+	}
+	// Programs without equipment list none rather than null
+	if data[1].Equipment == nil || len(data[1].Equipment) != 0 {
+		t.Errorf("second program needs %#v, want an empty list", data[1].Equipment)
+	}
+	if from := data[1].ClonedFrom; from == nil || *from.ProgramID != 9 || *from.Version != 1 || from.Author.Name != "Robin" {
+		t.Errorf("second program credits %+v, want version 1 of program 9 by Robin", from)
+	}
+}
+
+func TestGetCatalogProgram(t *testing.T) {
+	squat := models.PlannedExercise{ExerciseLibraryID: 10, ExerciseLibrary: &models.ExerciseLibrary{ID: 10, Name: "Squat"},
+		Sets: []models.PrescribedSet{{SetNumber: 1, Reps: intPtr(5), Weight: f32(100)}}}
+	legs := programDay(2, 1, "Legs", 60)
+	legs.Exercises = []models.PlannedExercise{squat}
+
+	newService := func() *programService {
+		return &programService{
+			trainerRepo: &fakeTrainers{trainer: catalogTestTrainer()},
+			programRepo: &fakeCatalog{programs: map[uint]*models.Program{
+				4: sharedProgram(4, 2, "active", "gym"),
+				5: sharedProgram(5, 2, "active", "public"),
+				6: sharedProgram(6, 1, "draft", "private"),
+				7: sharedProgram(7, 2, "draft", "public"),
+			}},
+			versionRepo: &fakeVersions{versions: map[uint]*models.ProgramVersion{
+				4: {ID: 40, ProgramID: 4, Version: 2},
+				5: {ID: 50, ProgramID: 5, Version: 2},
+				6: {ID: 60, ProgramID: 6, Version: 2},
+			}},
+			planRepo: &fakePlans{days: map[uint][]models.ProgramDay{40: {legs}, 50: {legs}}},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		userID     uint
+		programID  uint
+		wantErr    error
+		wantWeight float32 // of the squat, in the caller's unit
+	}{
+		{name: "public for visitors", userID: 0, programID: 5, wantWeight: 100},
+		{name: "gym-wide for trainers in their unit", userID: 10, programID: 4, wantWeight: 220.5},
+		{name: "gym-wide hidden from visitors", userID: 0, programID: 4, wantErr: apperrors.ErrNotFound},
+		{name: "gym-wide hidden from clients", userID: 30, programID: 4, wantErr: apperrors.ErrNotFound},
+		{name: "own draft", userID: 10, programID: 6},
+		{name: "another trainer's draft", userID: 10, programID: 7, wantErr: apperrors.ErrNotFound},
+		{name: "missing", userID: 10, programID: 8, wantErr: apperrors.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := newService().GetCatalogProgram(tt.userID, tt.programID)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("GetCatalogProgram = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Every week is listed, with the days of the newest version
+			if len(resp.Weeks) != 2 || len(resp.Weeks[0].Days) != 0 {
+				t.Fatalf("weeks = %+v, want 2 with nothing in week 1", resp.Weeks)
+			}
+			if tt.wantWeight == 0 {
+				if len(resp.Weeks[1].Days) != 0 {
+					t.Errorf("week 2 = %+v, want no days", resp.Weeks[1].Days)
+				}
+				return
+			}
+			day := resp.Weeks[1].Days[0]
+			if day.Name != "Legs" || !closeTo(*day.Exercises[0].Sets[0].Weight, tt.wantWeight) {
+				t.Errorf("week 2 has %q with a %v squat, want Legs with %v", day.Name, *day.Exercises[0].Sets[0].Weight, tt.wantWeight)
+			}
+		})
+	}
+}
+
+func TestCloneProgramRefusesHiddenPrograms(t *testing.T) {
+	s := &programService{
+		trainerRepo: &fakeTrainers{trainer: catalogTestTrainer()},
+		programRepo: &fakeCatalog{programs: map[uint]*models.Program{
+			4: sharedProgram(4, 2, "active", "private"),
+			5: sharedProgram(5, 2, "draft", "gym"),
+		}},
+	}
+
+	tests := []struct {
+		name      string
+		userID    uint
+		programID uint
+	}{
+		{"clients cannot clone", 30, 4},
+		{"another trainer's private program", 10, 4},
+		{"another trainer's draft", 10, 5},
+		{"missing", 10, 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.CloneProgram(tt.userID, tt.programID, &dto.CloneProgramRequest{}); !errors.Is(err, apperrors.ErrNotFound) {
+				t.Errorf("CloneProgram = %v, want not found", err)
+			}
+		})
+	}
+}
+
+func TestNewProgramClone(t *testing.T) {
+	description, level := "Three days a week", "beginner"
+	head := &models.ProgramVersion{
+		ID: 40, ProgramID: 4, Version: 3, Name: "Strength", Description: &description,
+		TotalWeeks: 8, SessionsPerWeek: 3, Goals: []string{"strength"}, TargetFitnessLevel: &level,
+		ProgressionScheme: progressionDoubleProgression, ProgressionIncrement: 2.5, AdherenceThreshold: 70,
+	}
+	renamed := "My Strength"
+	originalAuthor := uint(3)
+
+	tests := []struct {
+		name       string
+		source     *models.Program
+		rename     *string
+		wantName   string
+		wantAuthor uint
+	}{
+		{name: "an original credits its trainer", source: sharedProgram(4, 2, "active", "public"),
+			wantName: "Strength", wantAuthor: 2},
+		{name: "a clone credits the first author", source: &models.Program{ID: 4, TrainerID: 2, Version: 3, OriginalAuthorID: &originalAuthor},
+			wantName: "Strength", wantAuthor: 3},
+		{name: "renamed", source: sharedProgram(4, 2, "active", "public"), rename: &renamed,
+			wantName: "My Strength", wantAuthor: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clone := newProgramClone(tt.source, head, 1, tt.rename)
+
+			if clone.TrainerID != 1 || clone.Status != "draft" || clone.Visibility != programVisibilityPrivate || clone.Version != 1 {
+				t.Errorf("clone is %s/%s v%d of trainer %d, want a private draft v1 of trainer 1",
+					clone.Status, clone.Visibility, clone.Version, clone.TrainerID)
+			}
+			if *clone.ClonedFromID != 4 || *clone.ClonedFromVersion != tt.source.Version || *clone.OriginalAuthorID != tt.wantAuthor {
+				t.Errorf("clone credits program %d v%d by %d, want program 4 v%d by %d",
+					*clone.ClonedFromID, *clone.ClonedFromVersion, *clone.OriginalAuthorID, tt.source.Version, tt.wantAuthor)
+			}
+			// The content is that of the newest version
+			if clone.Name != tt.wantName || clone.TotalWeeks != 8 || clone.SessionsPerWeek != 3 ||
+				*clone.Description != description || *clone.TargetFitnessLevel != level || fmt.Sprint(clone.Goals) != "[strength]" {
+				t.Errorf("clone = %+v, want the content of version 3 named %q", clone, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestCloneProgramDays(t *testing.T) {
+	own, other := uint(1), uint(2)
+	description := "Hinge at the hips"
+	shared := &models.ExerciseLibrary{ID: 10, Name: "Squat"}
+	mine := &models.ExerciseLibrary{ID: 11, TrainerID: &own, Name: "Sled Push"}
+	private := &models.ExerciseLibrary{ID: 12, TrainerID: &other, Name: "Secret Hinge", Category: "strength", Description: &description}
+	public := &models.ExerciseLibrary{ID: 13, TrainerID: &other, Name: "Shared Row", IsPublic: true}
+	planned := func(exercise *models.ExerciseLibrary) models.PlannedExercise {
+		return models.PlannedExercise{ID: 90, ExerciseLibraryID: exercise.ID, ExerciseLibrary: exercise,
+			Sets: []models.PrescribedSet{{ID: 91, PlannedExerciseID: 90, SetNumber: 1, Reps: intPtr(5)}}}
+	}
+
+	push := programDay(1, 1, "Push", 60)
+	push.Exercises = []models.PlannedExercise{planned(shared), planned(private), planned(mine)}
+	pull := programDay(2, 2, "Pull", 45)
+	pull.Exercises = []models.PlannedExercise{planned(public), planned(private)}
+
+	plans := &fakePlans{}
+	library := &fakeExerciseCopies{}
+	days, err := cloneProgramDays(plans, library, catalogTestTrainer(), 7, 70, []models.ProgramDay{push, pull})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Another trainer's private exercise is copied once, into trainer 1's
+	// library
+	if len(library.created) != 1 {
+		t.Fatalf("copied %d exercises, want 1", len(library.created))
+	}
+	if copied := library.created[0]; *copied.TrainerID != 1 || copied.Name != "Secret Hinge" || copied.Category != "strength" ||
+		*copied.Description != description || copied.IsPublic {
+		t.Errorf("copied exercise = %+v, want a private copy of Secret Hinge for trainer 1", copied)
+	}
+
+	if len(days) != 2 || len(plans.created) != 2 {
+		t.Fatalf("cloned %d days, created %d; want 2", len(days), len(plans.created))
+	}
+	var lines []string
+	for _, day := range days {
+		if day.ProgramID != 7 || day.ProgramVersionID != 70 {
+			t.Errorf("day %d/%d belongs to program %d version %d, want 7 and 70", day.Week, day.Day, day.ProgramID, day.ProgramVersionID)
+		}
+		ids := make([]string, 0, len(day.Exercises))
+		for _, exercise := range day.Exercises {
+			ids = append(ids, fmt.Sprint(exercise.ExerciseLibraryID))
+			if exercise.ID != 0 || exercise.Sets[0].ID != 0 || exercise.Sets[0].PlannedExerciseID != 0 {
+				t.Errorf("exercise %+v keeps the IDs of the source", exercise)
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%d/%d %s %d min: %s", day.Week, day.Day, day.Name, day.Duration, strings.Join(ids, ",")))
+	}
+	if got, want := strings.Join(lines, "; "), "1/1 Push 60 min: 10,200,11; 2/2 Pull 45 min: 13,200"; got != want {
+		t.Errorf("cloned %s, want %s", got, want)
+	}
+
+	// The source plan is left as it was
+	if push.Exercises[1].ExerciseLibraryID != 12 || pull.Exercises[1].ExerciseLibraryID != 12 {
+		t.Error("cloning changed the exercises of the source")
+	}
+}
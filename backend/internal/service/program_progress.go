@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"fitness-training-backend/internal/config"
+	"fitness-training-backend/internal/database"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultAdherenceThreshold = 70 // % of due sessions completed
+
+	// adherenceMinDueSessions is how many sessions must be due before an
+	// assignment can be flagged, so missing the very first session does not
+	// flag it
+	adherenceMinDueSessions = 3
+)
+
+// refreshAssignmentProgress recomputes an assignment's completed sessions,
+// progress, current week and adherence from its schedules, and tells the
+// trainer when it falls behind plan. It reports whether it just did. The
+// program version the assignment is pinned to sets its length and threshold.
+// Use inside a transaction.
+func refreshAssignmentProgress(tx *gorm.DB, assignmentID uint, today time.Time) (bool, error) {
+	programRepo := repository.NewProgramRepository(tx)
+
+	assignment, err := programRepo.FindAssignmentByID(assignmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	applyVersionContent(&assignment.Program, &assignment.ProgramVersion)
+
+	completed, due, err := programRepo.CountAssignmentSessions(assignment.ID, today)
+	if err != nil {
+		return false, err
+	}
+
+	status, wasBehind := assignment.Status, assignment.BehindPlan
+	applyAssignmentProgress(assignment, completed, due, today)
+
+	flagged := false
+	if assignment.BehindPlan && !wasBehind {
+		// Claimed with a conditional update so concurrent refreshes notify once
+		if flagged, err = programRepo.ClaimBehindPlan(assignment.ID, today); err != nil {
+			return false, err
+		}
+	}
+	if err := programRepo.UpdateAssignment(assignment); err != nil {
+		return false, err
+	}
+	if assignment.Status != status {
+		if err := repository.NewProgramVersionRepository(tx).RefreshStats(assignment.ProgramID); err != nil {
+			return false, err
+		}
+	}
+
+	if flagged && assignment.Program.Trainer.UserID != 0 {
+		notification := behindPlanNotification(assignment, completed, due)
+		if err := repository.NewNotificationRepository(tx).Create(notification); err != nil {
+			return false, err
+		}
+	}
+	return flagged, nil
+}
+
+// applyAssignmentProgress derives an assignment's progress from its completed
+// and due session counts. Adherence is completed / due; an active assignment
+// is behind plan once enough sessions are due and adherence is below the
+// program's threshold.
+func applyAssignmentProgress(assignment *models.ProgramAssignment, completed, due int64, today time.Time) {
+	assignment.SessionsCompleted = int(completed)
+
+	assignment.ProgressPercentage = 0
+	if assignment.TotalSessions > 0 {
+		assignment.ProgressPercentage = percentage(completed, int64(assignment.TotalSessions))
+		if assignment.Status == "active" && assignment.SessionsCompleted >= assignment.TotalSessions {
+			assignment.Status = "completed"
+		}
+	}
+
+	week := daysBetween(assignment.StartDate, today)/7 + 1
+	if totalWeeks := assignment.Program.TotalWeeks; totalWeeks > 0 && week > totalWeeks {
+		week = totalWeeks
+	}
+	if week < 1 {
+		week = 1
+	}
+	assignment.CurrentWeek = week
+
+	assignment.AdherenceRate = nil
+	if due > 0 {
+		rate := percentage(completed, due)
+		assignment.AdherenceRate = &rate
+	}
+
+	behind := assignment.Status == "active" &&
+		due >= adherenceMinDueSessions &&
+		assignment.AdherenceRate != nil &&
+		*assignment.AdherenceRate < assignment.Program.AdherenceThreshold
+	if !behind {
+		assignment.BehindPlanSince = nil
+	} else if !assignment.BehindPlan {
+		since := today
+		assignment.BehindPlanSince = &since
+	}
+	assignment.BehindPlan = behind
+}
+
+// behindPlanNotification tells the trainer a client has fallen behind
+func behindPlanNotification(assignment *models.ProgramAssignment, completed, due int64) *models.Notification {
+	relatedType := "program_assignment"
+	return &models.Notification{
+		UserID: assignment.Program.Trainer.UserID,
+		Type:   "progress",
+		Title:  "Behind plan: " + assignment.Trainee.User.Name,
+		Message: fmt.Sprintf("%s has completed %d of %d sessions due in %s (%.0f%%, target %.0f%%)",
+			assignment.Trainee.User.Name, completed, due, assignment.Program.Name,
+			*assignment.AdherenceRate, assignment.Program.AdherenceThreshold),
+		RelatedID:   &assignment.ID,
+		RelatedType: &relatedType,
+		Priority:    "medium",
+		SentVia:     pq.StringArray{channelInApp},
+	}
+}
+
+// percentage returns part / whole in percent, capped at 100 and rounded to
+// the two decimals the columns keep
+func percentage(part, whole int64) float32 {
+	value := math.Min(100, float64(part)*100/float64(whole))
+	return float32(math.Round(value*100) / 100)
+}
+
+// daysBetween counts calendar days from one date to another, ignoring time
+// of day and zone
+func daysBetween(from, to time.Time) int {
+	fromDate := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDate := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(toDate.Sub(fromDate).Hours() / 24)
+}
+
+// gymToday returns today's date in the gym's time zone
+func gymToday() time.Time {
+	loc, err := time.LoadLocation(defaultTimezone)
+	if err != nil {
+		loc = time.Local
+	}
+	return localDate(time.Now().In(loc), loc)
+}
+
+// ==========================================
+// PROGRESS WORKER
+// ==========================================
+
+// ProgramProgressWorker periodically refreshes active assignments, so the
+// current week moves on and missed sessions count against adherence even
+// when no schedule changes
+type ProgramProgressWorker struct {
+	programRepo repository.ProgramRepository
+	interval    time.Duration
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewProgramProgressWorker creates a worker refreshing at the configured interval
+func NewProgramProgressWorker(programRepo repository.ProgramRepository, cfg config.ProgramProgressConfig) *ProgramProgressWorker {
+	return &ProgramProgressWorker{
+		programRepo: programRepo,
+		interval:    cfg.Interval,
+	}
+}
+
+// Start runs the worker in the background until Stop is called
+func (w *ProgramProgressWorker) Start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.done = make(chan struct{})
+
+	go w.run(ctx, w.done)
+}
+
+// Stop ends the background loop and waits for a refresh in progress
+func (w *ProgramProgressWorker) Stop() {
+	w.mu.Lock()
+	cancel, done := w.cancel, w.done
+	w.cancel, w.done = nil, nil
+	w.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+func (w *ProgramProgressWorker) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if flagged, err := w.RefreshActive(ctx, gymToday()); err != nil {
+			log.Printf("⚠️  Program progress refresh failed: %v", err)
+		} else if flagged > 0 {
+			log.Printf("📉 Flagged %d program assignment(s) as behind plan", flagged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RefreshActive refreshes every active assignment and returns how many were
+// newly flagged as behind plan
+func (w *ProgramProgressWorker) RefreshActive(ctx context.Context, today time.Time) (int, error) {
+	ids, err := w.programRepo.FindActiveAssignmentIDs()
+	if err != nil {
+		return 0, err
+	}
+
+	flagged := 0
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			break
+		}
+
+		newlyBehind := false
+		err := database.Transaction(func(tx *gorm.DB) error {
+			var err error
+			newlyBehind, err = refreshAssignmentProgress(tx, id, today)
+			return err
+		})
+		if err != nil {
+			log.Printf("⚠️  Progress refresh failed for assignment %d: %v", id, err)
+			continue
+		}
+		if newlyBehind {
+			flagged++
+		}
+	}
+	return flagged, nil
+}
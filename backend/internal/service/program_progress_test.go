@@ -0,0 +1,127 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"fitness-training-backend/internal/models"
+)
+
+func TestApplyAssignmentProgress(t *testing.T) {
+	// Monday 4 March 2024
+	start := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	day := func(offset int) time.Time { return start.AddDate(0, 0, offset) }
+	flaggedOn := day(6)
+
+	tests := []struct {
+		name          string
+		status        string
+		totalSessions int
+		totalWeeks    int
+		behindSince   *time.Time // already behind plan since
+		completed     int64
+		due           int64
+		today         time.Time
+
+		wantStatus    string
+		wantProgress  float32
+		wantWeek      int
+		wantAdherence float32 // -1 = none
+		wantSince     *time.Time
+	}{
+		{name: "nothing due yet", completed: 0, due: 0, today: day(0),
+			wantProgress: 0, wantWeek: 1, wantAdherence: -1},
+		{name: "before the start date", completed: 0, due: 0, today: day(-10),
+			wantProgress: 0, wantWeek: 1, wantAdherence: -1},
+		{name: "last day of the first week", completed: 2, due: 2, today: day(6),
+			wantProgress: 8.33, wantWeek: 1, wantAdherence: 100},
+		{name: "first day of the second week", completed: 2, due: 2, today: day(7),
+			wantProgress: 8.33, wantWeek: 2, wantAdherence: 100},
+
+		// Adherence is only judged once enough sessions are due
+		{name: "below the minimum due sessions", completed: 0, due: adherenceMinDueSessions - 1, today: day(7),
+			wantProgress: 0, wantWeek: 2, wantAdherence: 0},
+		{name: "behind at the minimum due sessions", completed: 1, due: adherenceMinDueSessions, today: day(7),
+			wantProgress: 4.17, wantWeek: 2, wantAdherence: 33.33, wantSince: ptrTime(day(7))},
+		{name: "exactly at the threshold", completed: 7, due: 10, today: day(28),
+			wantProgress: 29.17, wantWeek: 5, wantAdherence: 70},
+		{name: "still behind keeps the date", behindSince: &flaggedOn, completed: 1, due: 4, today: day(14),
+			wantProgress: 4.17, wantWeek: 3, wantAdherence: 25, wantSince: &flaggedOn},
+		{name: "catching up clears the flag", behindSince: &flaggedOn, completed: 3, due: 4, today: day(14),
+			wantProgress: 12.5, wantWeek: 3, wantAdherence: 75},
+
+		// The current week stops at the program's length
+		{name: "week clamped to the program", completed: 20, due: 24, today: day(70),
+			wantProgress: 83.33, wantWeek: 8, wantAdherence: 83.33},
+		{name: "program without a length", totalWeeks: -1, completed: 20, due: 24, today: day(70),
+			wantProgress: 83.33, wantWeek: 11, wantAdherence: 83.33},
+
+		// Completing every planned session completes the assignment
+		{name: "all sessions completed", behindSince: &flaggedOn, completed: 24, due: 24, today: day(55),
+			wantStatus: "completed", wantProgress: 100, wantWeek: 8, wantAdherence: 100},
+		{name: "more sessions than planned", completed: 26, due: 24, today: day(55),
+			wantStatus: "completed", wantProgress: 100, wantWeek: 8, wantAdherence: 100},
+		{name: "paused is never behind", status: "paused", behindSince: &flaggedOn, completed: 0, due: 5, today: day(21),
+			wantStatus: "paused", wantProgress: 0, wantWeek: 4, wantAdherence: 0},
+		{name: "paused is not completed", status: "paused", completed: 24, due: 24, today: day(55),
+			wantStatus: "paused", wantProgress: 100, wantWeek: 8, wantAdherence: 100},
+		{name: "no planned sessions", totalSessions: -1, completed: 3, due: 3, today: day(14),
+			wantProgress: 0, wantWeek: 3, wantAdherence: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assignment := &models.ProgramAssignment{
+				StartDate:     start,
+				Status:        "active",
+				TotalSessions: 24,
+				Program:       models.Program{TotalWeeks: 8, AdherenceThreshold: defaultAdherenceThreshold},
+			}
+			if tt.status != "" {
+				assignment.Status = tt.status
+			}
+			if tt.totalSessions < 0 {
+				assignment.TotalSessions = 0
+			}
+			if tt.totalWeeks < 0 {
+				assignment.Program.TotalWeeks = 0
+			}
+			if tt.behindSince != nil {
+				assignment.BehindPlan = true
+				assignment.BehindPlanSince = tt.behindSince
+			}
+			wantStatus := tt.wantStatus
+			if wantStatus == "" {
+				wantStatus = "active"
+			}
+
+			applyAssignmentProgress(assignment, tt.completed, tt.due, tt.today)
+
+			if assignment.SessionsCompleted != int(tt.completed) || assignment.Status != wantStatus {
+				t.Errorf("completed %d, status %q; want %d, %q", assignment.SessionsCompleted, assignment.Status, tt.completed, wantStatus)
+			}
+			if assignment.ProgressPercentage != tt.wantProgress || assignment.CurrentWeek != tt.wantWeek {
+				t.Errorf("progress %v%% in week %d, want %v%% in week %d",
+					assignment.ProgressPercentage, assignment.CurrentWeek, tt.wantProgress, tt.wantWeek)
+			}
+			switch {
+			case tt.wantAdherence < 0 && assignment.AdherenceRate != nil:
+				t.Errorf("adherence = %v, want none", *assignment.AdherenceRate)
+			case tt.wantAdherence >= 0 && (assignment.AdherenceRate == nil || *assignment.AdherenceRate != tt.wantAdherence):
+				t.Errorf("adherence = %v, want %v", assignment.AdherenceRate, tt.wantAdherence)
+			}
+			if assignment.BehindPlan != (tt.wantSince != nil) {
+				t.Errorf("behind plan = %v, want %v", assignment.BehindPlan, tt.wantSince != nil)
+			}
+			switch {
+			case tt.wantSince == nil && assignment.BehindPlanSince != nil:
+				t.Errorf("behind plan since %v, want cleared", *assignment.BehindPlanSince)
+			case tt.wantSince != nil && (assignment.BehindPlanSince == nil || !assignment.BehindPlanSince.Equal(*tt.wantSince)):
+				t.Errorf("behind plan since %v, want %v", assignment.BehindPlanSince, *tt.wantSince)
+			}
+		})
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
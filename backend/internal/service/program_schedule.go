@@ -0,0 +1,242 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"fitness-training-backend/internal/models"
+	apperrors "fitness-training-backend/pkg/errors"
+
+	"github.com/lib/pq"
+)
+
+// maxProgramSessions caps the schedules one assignment can lay out
+const maxProgramSessions = 400
+
+// spreadOffsets is the order in which days of a week are used when neither
+// the plan nor the client prefers one: every other day first
+var spreadOffsets = []int{0, 2, 4, 6, 1, 3, 5}
+
+// programSession is one session of an assignment placed on a date. Plan is
+// nil for programs without a weekly plan.
+type programSession struct {
+	Week int
+	Day  int
+	Plan *models.ProgramDay
+	Date time.Time
+}
+
+// Duration returns the session length in minutes
+func (p programSession) Duration() int {
+	if p.Plan != nil && p.Plan.Duration > 0 {
+		return p.Plan.Duration
+	}
+	return defaultProgramDayDuration
+}
+
+// programWeekSessions lists the sessions of each program week in order: its
+// planned days, or sessionsPerWeek unplanned sessions when the program has
+// no plan at all. A planned program with an empty week is a rest week.
+func programWeekSessions(totalWeeks, sessionsPerWeek int, days []models.ProgramDay) [][]programSession {
+	weeks := make([][]programSession, totalWeeks)
+	if len(days) == 0 {
+		for week := range weeks {
+			for day := 1; day <= sessionsPerWeek; day++ {
+				weeks[week] = append(weeks[week], programSession{Week: week + 1, Day: day})
+			}
+		}
+		return weeks
+	}
+
+	for i := range days {
+		day := &days[i]
+		if day.Week < 1 || day.Week > totalWeeks {
+			continue
+		}
+		weeks[day.Week-1] = append(weeks[day.Week-1], programSession{Week: day.Week, Day: day.Day, Plan: day})
+	}
+	for _, sessions := range weeks {
+		sort.Slice(sessions, func(i, j int) bool { return sessions[i].Day < sessions[j].Day })
+	}
+	return weeks
+}
+
+// layoutProgram places every session on a date. Week n starts 7*(n-1) days
+// after start. A planned weekday is kept when the client has no preferred
+// days or prefers it; other sessions take the client's preferred days in
+// order, then days spread across the week. Sessions before from are skipped.
+// When the trainer is busy on a date the session moves to another free day of
+// its week; the dates that could not be moved are returned as conflicts.
+func layoutProgram(weeks [][]programSession, start, from time.Time, preferred []int, isFree func(date time.Time, duration int) (bool, error)) ([]programSession, []string, error) {
+	placed := make([]programSession, 0)
+	conflicts := make([]string, 0)
+
+	for i, sessions := range weeks {
+		if len(sessions) > 7 {
+			return nil, nil, fmt.Errorf("%w: week %d has more than 7 sessions", apperrors.ErrInvalidInput, i+1)
+		}
+
+		weekStart := start.AddDate(0, 0, 7*i)
+		candidates := weekOffsets(weekStart.Weekday(), preferred)
+		used := make(map[int]bool, 7)
+		offsets := make([]int, len(sessions))
+
+		// Planned weekdays the client is fine with
+		for j, session := range sessions {
+			offsets[j] = -1
+			if session.Plan == nil || session.Plan.Weekday == nil {
+				continue
+			}
+			if len(preferred) > 0 && !containsInt(preferred, *session.Plan.Weekday) {
+				continue
+			}
+			offset := weekdayOffset(weekStart.Weekday(), *session.Plan.Weekday)
+			if !used[offset] {
+				offsets[j] = offset
+				used[offset] = true
+			}
+		}
+
+		// The rest take the first free candidates, in session order
+		free := make([]int, 0, len(sessions))
+		for _, offset := range candidates {
+			if len(free) == countInt(offsets, -1) {
+				break
+			}
+			if !used[offset] {
+				free = append(free, offset)
+				used[offset] = true
+			}
+		}
+		sort.Ints(free)
+		for j := range offsets {
+			if offsets[j] < 0 {
+				offsets[j], free = free[0], free[1:]
+			}
+		}
+
+		for j, session := range sessions {
+			date := weekStart.AddDate(0, 0, offsets[j])
+			if date.Before(from) {
+				continue
+			}
+
+			ok, err := isFree(date, session.Duration())
+			if err != nil {
+				return nil, nil, err
+			}
+			if !ok {
+				moved := false
+				for _, offset := range candidates {
+					alternative := weekStart.AddDate(0, 0, offset)
+					if used[offset] || alternative.Before(from) {
+						continue
+					}
+					if ok, err = isFree(alternative, session.Duration()); err != nil {
+						return nil, nil, err
+					}
+					if ok {
+						used[offset] = true
+						date, moved = alternative, true
+						break
+					}
+				}
+				if !moved {
+					conflicts = append(conflicts, date.Format("2006-01-02"))
+					continue
+				}
+			}
+
+			session.Date = date
+			placed = append(placed, session)
+		}
+	}
+
+	sort.SliceStable(placed, func(i, j int) bool { return placed[i].Date.Before(placed[j].Date) })
+	return placed, conflicts, nil
+}
+
+// weekOffsets orders the days of a week starting on first: the preferred
+// weekdays first, then the remaining days spread out
+func weekOffsets(first time.Weekday, preferred []int) []int {
+	offsets := make([]int, 0, 7)
+	seen := make(map[int]bool, 7)
+
+	preferredOffsets := make([]int, 0, len(preferred))
+	for _, weekday := range preferred {
+		preferredOffsets = append(preferredOffsets, weekdayOffset(first, weekday))
+	}
+	sort.Ints(preferredOffsets)
+
+	for _, offset := range append(preferredOffsets, spreadOffsets...) {
+		if !seen[offset] {
+			seen[offset] = true
+			offsets = append(offsets, offset)
+		}
+	}
+	return offsets
+}
+
+// weekdayOffset returns how many days after a first weekday the weekday falls
+func weekdayOffset(first time.Weekday, weekday int) int {
+	return (weekday - int(first) + 7) % 7
+}
+
+// newProgramSchedule builds the schedule of a laid out session
+func newProgramSchedule(assignment *models.ProgramAssignment, program *models.Program, session programSession, timeOfDay string, locationID *uint) *models.Schedule {
+	schedule := &models.Schedule{
+		TrainerID:           program.TrainerID,
+		TraineeID:           assignment.TraineeID,
+		LocationID:          locationID,
+		ProgramAssignmentID: &assignment.ID,
+		Date:                session.Date,
+		Time:                timeOfDay,
+		Duration:            session.Duration(),
+		Title:               fmt.Sprintf("%s: week %d, session %d", program.Name, session.Week, session.Day),
+		Status:              "scheduled",
+	}
+
+	if session.Plan != nil {
+		applyProgramDayToSchedule(schedule, session.Plan)
+	}
+	return schedule
+}
+
+// programDayColumns are the schedule columns applyProgramDayToSchedule sets
+var programDayColumns = []string{"program_day_id", "title", "description", "planned_exercises"}
+
+// applyProgramDayToSchedule links a schedule to the program day it follows
+// and copies the day's name, notes and exercises
+func applyProgramDayToSchedule(schedule *models.Schedule, day *models.ProgramDay) {
+	schedule.ProgramDayID = &day.ID
+	schedule.Title = day.Name
+	schedule.Description = day.Notes
+
+	exercises := make(pq.StringArray, 0, len(day.Exercises))
+	for _, exercise := range day.Exercises {
+		if exercise.ExerciseLibrary != nil {
+			exercises = append(exercises, exercise.ExerciseLibrary.Name)
+		}
+	}
+	schedule.PlannedExercises = exercises
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func countInt(values []int, value int) int {
+	count := 0
+	for _, v := range values {
+		if v == value {
+			count++
+		}
+	}
+	return count
+}
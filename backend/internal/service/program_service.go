@@ -0,0 +1,699 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"fitness-training-backend/internal/database"
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+	apperrors "fitness-training-backend/pkg/errors"
+	"fitness-training-backend/pkg/units"
+
+	"gorm.io/gorm"
+)
+
+const defaultProgramDayDuration = 60 // minutes
+
+// ProgramService handles the weekly plan and versions of a trainer's
+// programs, the program catalog, the progress notes of their assignments and
+// load suggestions for their sessions
+type ProgramService interface {
+	// Plan
+	GetPlan(userID, programID uint) ([]dto.ProgramWeekResponse, error)
+	GetWeekDays(userID, programID uint, week int) ([]dto.ProgramDayResponse, error)
+	GetDay(userID, programID uint, week, day int) (*dto.ProgramDayResponse, error)
+	CreateDay(userID, programID uint, week int, req *dto.CreateProgramDayRequest) (*dto.ProgramDayResponse, error)
+	UpdateDay(userID, programID uint, week, day int, req *dto.ProgramDayRequest) (*dto.ProgramDayResponse, error)
+	DeleteDay(userID, programID uint, week, day int) error
+	CopyWeek(userID, programID uint, week int, req *dto.CopyProgramWeekRequest) ([]dto.ProgramWeekResponse, error)
+
+	// Versions
+	GetVersions(userID, programID uint) ([]dto.ProgramVersionResponse, error)
+	GetVersion(userID, programID uint, version int) (*dto.ProgramVersionResponse, error)
+	DiffVersions(userID, programID uint, version, from int) (*dto.ProgramVersionDiffResponse, error)
+	MigrateAssignments(userID, programID uint, version int, req *dto.MigrateAssignmentsRequest) ([]dto.AssignmentMigrationResponse, error)
+
+	// Catalog
+	GetCatalog(userID uint, filters map[string]interface{}, page, pageSize int) (*dto.PaginatedResponse, error)
+	GetCatalogProgram(userID, programID uint) (*dto.CatalogProgramResponse, error)
+	CloneProgram(userID, programID uint, req *dto.CloneProgramRequest) (*dto.TrainerProgramResponse, error)
+
+	// Assignments
+	GetAssignments(userID uint, filters map[string]interface{}) ([]dto.TrainerAssignmentResponse, error)
+
+	// Progress notes
+	GetProgressNotes(userID, assignmentID uint) ([]dto.ProgressNoteResponse, error)
+	CreateProgressNote(userID, assignmentID uint, req *dto.CreateProgressNoteRequest) (*dto.ProgressNoteResponse, error)
+	DeleteProgressNote(userID, assignmentID, noteID uint) error
+
+	// Progression
+	SuggestSession(userID, scheduleID uint, scheme string) (*dto.SessionSuggestionResponse, error)
+}
+
+type programService struct {
+	trainerRepo  repository.TrainerRepository
+	programRepo  repository.ProgramRepository
+	planRepo     repository.ProgramPlanRepository
+	versionRepo  repository.ProgramVersionRepository
+	exerciseRepo repository.ExerciseRepository
+	scheduleRepo repository.ScheduleRepository
+	recordRepo   repository.PersonalRecordRepository
+}
+
+// NewProgramService creates a new program service
+func NewProgramService(
+	trainerRepo repository.TrainerRepository,
+	programRepo repository.ProgramRepository,
+	planRepo repository.ProgramPlanRepository,
+	versionRepo repository.ProgramVersionRepository,
+	exerciseRepo repository.ExerciseRepository,
+	scheduleRepo repository.ScheduleRepository,
+	recordRepo repository.PersonalRecordRepository,
+) ProgramService {
+	return &programService{
+		trainerRepo:  trainerRepo,
+		programRepo:  programRepo,
+		planRepo:     planRepo,
+		versionRepo:  versionRepo,
+		exerciseRepo: exerciseRepo,
+		scheduleRepo: scheduleRepo,
+		recordRepo:   recordRepo,
+	}
+}
+
+// ==========================================
+// PLAN
+// ==========================================
+
+// GetPlan returns every week of the program's newest version, including
+// empty ones
+func (s *programService) GetPlan(userID, programID uint) ([]dto.ProgramWeekResponse, error) {
+	trainer, program, err := s.getProgram(userID, programID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, days, err := s.getVersionPlan(program, program.Version)
+	if err != nil {
+		return nil, err
+	}
+	return toProgramWeeks(program.TotalWeeks, days, trainer.User.Units()), nil
+}
+
+// GetWeekDays returns the days of one week in order
+func (s *programService) GetWeekDays(userID, programID uint, week int) ([]dto.ProgramDayResponse, error) {
+	trainer, program, err := s.getProgram(userID, programID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateProgramWeek(program, week); err != nil {
+		return nil, err
+	}
+
+	version, err := s.versionRepo.FindByNumber(program.ID, program.Version)
+	if err != nil {
+		return nil, err
+	}
+	days, err := s.planRepo.FindDays(version.ID, week)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]dto.ProgramDayResponse, 0, len(days))
+	for i := range days {
+		resp = append(resp, toProgramDayResponse(&days[i], trainer.User.Units()))
+	}
+	return resp, nil
+}
+
+func (s *programService) GetDay(userID, programID uint, week, day int) (*dto.ProgramDayResponse, error) {
+	trainer, program, err := s.getProgram(userID, programID)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := s.versionRepo.FindByNumber(program.ID, program.Version)
+	if err != nil {
+		return nil, err
+	}
+	programDay, err := s.planRepo.FindDay(version.ID, week, day)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	resp := toProgramDayResponse(programDay, trainer.User.Units())
+	return &resp, nil
+}
+
+// CreateDay adds a day to a week. A week holds at most SessionsPerWeek days.
+// Like every plan edit it goes to the newest version, which is first copied
+// into a new one when clients are assigned to it (see editableVersion).
+func (s *programService) CreateDay(userID, programID uint, week int, req *dto.CreateProgramDayRequest) (*dto.ProgramDayResponse, error) {
+	trainer, program, err := s.getProgram(userID, programID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateProgramWeek(program, week); err != nil {
+		return nil, err
+	}
+
+	programDay := &models.ProgramDay{ProgramID: program.ID, Week: week}
+	if err := s.applyProgramDay(trainer, programDay, &req.ProgramDayRequest); err != nil {
+		return nil, err
+	}
+
+	err = database.Transaction(func(tx *gorm.DB) error {
+		planRepo := repository.NewProgramPlanRepository(tx)
+
+		version, err := editableVersion(tx, program)
+		if err != nil {
+			return err
+		}
+		existing, err := planRepo.FindDays(version.ID, week)
+		if err != nil {
+			return err
+		}
+		programDay.Day, err = programDaySlot(program, week, existing, req.Day)
+		if err != nil {
+			return err
+		}
+
+		programDay.ProgramVersionID = version.ID
+		if err := planRepo.CreateDay(programDay); err != nil {
+			return err
+		}
+		return repository.NewExerciseRepository(tx).RefreshUsage(plannedExerciseIDs([]models.ProgramDay{*programDay}))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetDay(userID, programID, week, programDay.Day)
+}
+
+// UpdateDay replaces the content of a day
+func (s *programService) UpdateDay(userID, programID uint, week, day int, req *dto.ProgramDayRequest) (*dto.ProgramDayResponse, error) {
+	trainer, program, err := s.getProgram(userID, programID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = database.Transaction(func(tx *gorm.DB) error {
+		planRepo := repository.NewProgramPlanRepository(tx)
+
+		version, err := editableVersion(tx, program)
+		if err != nil {
+			return err
+		}
+		programDay, err := planRepo.FindDay(version.ID, week, day)
+		if err != nil {
+			return notFound(err)
+		}
+		usedIDs := plannedExerciseIDs([]models.ProgramDay{*programDay})
+		if err := s.applyProgramDay(trainer, programDay, req); err != nil {
+			return err
+		}
+		if err := planRepo.ReplaceDay(programDay); err != nil {
+			return err
+		}
+		usedIDs = append(usedIDs, plannedExerciseIDs([]models.ProgramDay{*programDay})...)
+		return repository.NewExerciseRepository(tx).RefreshUsage(usedIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetDay(userID, programID, week, day)
+}
+
+func (s *programService) DeleteDay(userID, programID uint, week, day int) error {
+	_, program, err := s.getProgram(userID, programID)
+	if err != nil {
+		return err
+	}
+
+	return database.Transaction(func(tx *gorm.DB) error {
+		planRepo := repository.NewProgramPlanRepository(tx)
+
+		version, err := editableVersion(tx, program)
+		if err != nil {
+			return err
+		}
+		programDay, err := planRepo.FindDay(version.ID, week, day)
+		if err != nil {
+			return notFound(err)
+		}
+		if err := planRepo.DeleteDay(programDay.ID); err != nil {
+			return err
+		}
+		return repository.NewExerciseRepository(tx).RefreshUsage(plannedExerciseIDs([]models.ProgramDay{*programDay}))
+	})
+}
+
+// CopyWeek replaces the days of the target weeks with copies of a week, e.g.
+// to repeat week 1 for the whole program before adjusting the loads
+func (s *programService) CopyWeek(userID, programID uint, week int, req *dto.CopyProgramWeekRequest) ([]dto.ProgramWeekResponse, error) {
+	_, program, err := s.getProgram(userID, programID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateProgramWeek(program, week); err != nil {
+		return nil, err
+	}
+
+	seen := map[int]bool{}
+	for _, target := range req.ToWeeks {
+		if target == week {
+			return nil, fmt.Errorf("%w: cannot copy week %d onto itself", apperrors.ErrInvalidInput, week)
+		}
+		if seen[target] {
+			return nil, fmt.Errorf("%w: week %d is listed twice", apperrors.ErrInvalidInput, target)
+		}
+		seen[target] = true
+		if err := validateProgramWeek(program, target); err != nil {
+			return nil, err
+		}
+	}
+
+	err = database.Transaction(func(tx *gorm.DB) error {
+		planRepo := repository.NewProgramPlanRepository(tx)
+
+		version, err := editableVersion(tx, program)
+		if err != nil {
+			return err
+		}
+		source, err := planRepo.FindDays(version.ID, week)
+		if err != nil {
+			return err
+		}
+		usedIDs := plannedExerciseIDs(source)
+		for _, target := range req.ToWeeks {
+			replaced, err := planRepo.FindDays(version.ID, target)
+			if err != nil {
+				return err
+			}
+			usedIDs = append(usedIDs, plannedExerciseIDs(replaced)...)
+
+			days := make([]models.ProgramDay, 0, len(source))
+			for i := range source {
+				days = append(days, copyProgramDay(&source[i], version.ID, target))
+			}
+			if err := planRepo.ReplaceWeek(version.ID, target, days); err != nil {
+				return err
+			}
+		}
+		return repository.NewExerciseRepository(tx).RefreshUsage(usedIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetPlan(userID, programID)
+}
+
+// applyProgramDay validates the request and copies it onto the day,
+// replacing its exercises
+func (s *programService) applyProgramDay(trainer *models.Trainer, day *models.ProgramDay, req *dto.ProgramDayRequest) error {
+	day.Name = req.Name
+	day.Weekday = req.Weekday
+	day.Duration = req.Duration
+	if day.Duration == 0 {
+		day.Duration = defaultProgramDayDuration
+	}
+	day.Notes = req.Notes
+
+	// Sets are entered in the trainer's units
+	prefs := trainer.User.Units()
+	exercises := make([]models.PlannedExercise, 0, len(req.Exercises))
+	checked := map[uint]bool{}
+	for i, exerciseReq := range req.Exercises {
+		if !checked[exerciseReq.ExerciseLibraryID] {
+			if err := s.checkExerciseAccess(trainer, exerciseReq.ExerciseLibraryID); err != nil {
+				return err
+			}
+			checked[exerciseReq.ExerciseLibraryID] = true
+		}
+
+		exercise := models.PlannedExercise{
+			ExerciseLibraryID: exerciseReq.ExerciseLibraryID,
+			Order:             i + 1,
+			Notes:             exerciseReq.Notes,
+			RestDuration:      exerciseReq.RestDuration,
+		}
+		for j, setReq := range exerciseReq.Sets {
+			if err := validatePrescribedSet(i+1, j+1, &setReq); err != nil {
+				return err
+			}
+			exercise.Sets = append(exercise.Sets, models.PrescribedSet{
+				SetNumber:    j + 1,
+				Reps:         setReq.Reps,
+				RepsMax:      setReq.RepsMax,
+				Duration:     setReq.Duration,
+				Distance:     canonicalPtr(setReq.Distance, prefs.For(units.Distance)),
+				Weight:       canonicalPtr(setReq.Weight, prefs.For(units.Mass)),
+				PercentOneRM: setReq.PercentOneRM,
+				RPE:          setReq.RPE,
+			})
+		}
+		exercises = append(exercises, exercise)
+	}
+	day.Exercises = exercises
+
+	return nil
+}
+
+// checkExerciseAccess allows the shared library, public exercises and the
+// trainer's own
+func (s *programService) checkExerciseAccess(trainer *models.Trainer, exerciseID uint) error {
+	exercise, err := s.exerciseRepo.FindByID(exerciseID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: exercise %d does not exist", apperrors.ErrInvalidInput, exerciseID)
+		}
+		return err
+	}
+	if !canUseExercise(trainer, exercise) {
+		return fmt.Errorf("%w: exercise %d belongs to another trainer", apperrors.ErrInvalidInput, exerciseID)
+	}
+	return nil
+}
+
+// validatePrescribedSet checks the rules the binding tags cannot express
+func validatePrescribedSet(exercise, set int, req *dto.PrescribedSetRequest) error {
+	where := fmt.Sprintf("exercise %d set %d", exercise, set)
+	switch {
+	case req.Reps == nil && req.Duration == nil && req.Distance == nil:
+		return fmt.Errorf("%w: %s needs reps, a duration or a distance", apperrors.ErrInvalidInput, where)
+	case req.RepsMax != nil && req.Reps == nil:
+		return fmt.Errorf("%w: %s has repsMax without reps", apperrors.ErrInvalidInput, where)
+	case req.RepsMax != nil && *req.RepsMax < *req.Reps:
+		return fmt.Errorf("%w: %s has repsMax below reps", apperrors.ErrInvalidInput, where)
+	case req.Weight != nil && req.PercentOneRM != nil:
+		return fmt.Errorf("%w: %s sets both weight and percentOneRm", apperrors.ErrInvalidInput, where)
+	}
+	return nil
+}
+
+// programDaySlot returns the requested day of the week, or the first free
+// one when none is requested
+func programDaySlot(program *models.Program, week int, existing []models.ProgramDay, requested *int) (int, error) {
+	taken := make(map[int]bool, len(existing))
+	for _, day := range existing {
+		taken[day.Day] = true
+	}
+
+	if requested != nil {
+		if *requested > program.SessionsPerWeek {
+			return 0, fmt.Errorf("%w: day must be between 1 and %d (sessions per week)", apperrors.ErrInvalidInput, program.SessionsPerWeek)
+		}
+		if taken[*requested] {
+			return 0, fmt.Errorf("%w: week %d already has day %d", apperrors.ErrConflict, week, *requested)
+		}
+		return *requested, nil
+	}
+	for candidate := 1; candidate <= program.SessionsPerWeek; candidate++ {
+		if !taken[candidate] {
+			return candidate, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: week %d already has %d days (sessions per week)", apperrors.ErrConflict, week, program.SessionsPerWeek)
+}
+
+func validateProgramWeek(program *models.Program, week int) error {
+	if week < 1 || week > program.TotalWeeks {
+		return fmt.Errorf("%w: week must be between 1 and %d", apperrors.ErrInvalidInput, program.TotalWeeks)
+	}
+	return nil
+}
+
+// copyProgramDay copies a day with its exercises and sets into a week of a
+// version of the same program
+func copyProgramDay(source *models.ProgramDay, versionID uint, week int) models.ProgramDay {
+	day := models.ProgramDay{
+		ProgramID:        source.ProgramID,
+		ProgramVersionID: versionID,
+		Week:             week,
+		Day:              source.Day,
+		Name:             source.Name,
+		Weekday:          source.Weekday,
+		Duration:         source.Duration,
+		Notes:            source.Notes,
+		Exercises:        make([]models.PlannedExercise, 0, len(source.Exercises)),
+	}
+	for _, exercise := range source.Exercises {
+		planned := models.PlannedExercise{
+			ExerciseLibraryID: exercise.ExerciseLibraryID,
+			Order:             exercise.Order,
+			Notes:             exercise.Notes,
+			RestDuration:      exercise.RestDuration,
+			Sets:              make([]models.PrescribedSet, 0, len(exercise.Sets)),
+		}
+		for _, set := range exercise.Sets {
+			set.ID = 0
+			set.PlannedExerciseID = 0
+			planned.Sets = append(planned.Sets, set)
+		}
+		day.Exercises = append(day.Exercises, planned)
+	}
+	return day
+}
+
+// plannedExerciseIDs lists the distinct library exercises planned on days
+func plannedExerciseIDs(days []models.ProgramDay) []uint {
+	seen := map[uint]bool{}
+	ids := make([]uint, 0)
+	for i := range days {
+		for _, exercise := range days[i].Exercises {
+			if !seen[exercise.ExerciseLibraryID] {
+				seen[exercise.ExerciseLibraryID] = true
+				ids = append(ids, exercise.ExerciseLibraryID)
+			}
+		}
+	}
+	return ids
+}
+
+// toProgramWeeks groups days by week, listing every week of the program
+func toProgramWeeks(totalWeeks int, days []models.ProgramDay, prefs units.Preferences) []dto.ProgramWeekResponse {
+	weeks := make([]dto.ProgramWeekResponse, totalWeeks)
+	for i := range weeks {
+		weeks[i] = dto.ProgramWeekResponse{Week: i + 1, Days: []dto.ProgramDayResponse{}}
+	}
+	for i := range days {
+		if days[i].Week < 1 || days[i].Week > totalWeeks {
+			continue
+		}
+		weeks[days[i].Week-1].Days = append(weeks[days[i].Week-1].Days, toProgramDayResponse(&days[i], prefs))
+	}
+	return weeks
+}
+
+// ==========================================
+// ASSIGNMENTS
+// ==========================================
+
+// GetAssignments lists the assignments of the trainer's programs, e.g. the
+// active ones behind plan
+func (s *programService) GetAssignments(userID uint, filters map[string]interface{}) ([]dto.TrainerAssignmentResponse, error) {
+	trainer, err := s.trainerRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	assignments, err := s.programRepo.FindAssignmentsByTrainerID(trainer.ID, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]dto.TrainerAssignmentResponse, 0, len(assignments))
+	for i := range assignments {
+		assignment := &assignments[i]
+		resp = append(resp, dto.TrainerAssignmentResponse{
+			ProgramAssignmentResponse: *toProgramAssignmentResponse(assignment),
+			ProgramID:                 assignment.ProgramID,
+			ProgramName:               assignment.Program.Name,
+			Trainee: dto.ScheduleTraineeInfo{
+				ID:           assignment.TraineeID,
+				Name:         assignment.Trainee.User.Name,
+				ProfileImage: assignment.Trainee.User.ProfileImage,
+			},
+		})
+	}
+	return resp, nil
+}
+
+// ==========================================
+// PROGRESS NOTES
+// ==========================================
+
+func (s *programService) GetProgressNotes(userID, assignmentID uint) ([]dto.ProgressNoteResponse, error) {
+	if _, _, err := s.getAssignment(userID, assignmentID); err != nil {
+		return nil, err
+	}
+
+	notes, err := s.programRepo.FindProgressNotes(assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]dto.ProgressNoteResponse, 0, len(notes))
+	for i := range notes {
+		resp = append(resp, toProgressNoteResponse(&notes[i]))
+	}
+	return resp, nil
+}
+
+// CreateProgressNote records a note; the week defaults to the assignment's
+// current week
+func (s *programService) CreateProgressNote(userID, assignmentID uint, req *dto.CreateProgressNoteRequest) (*dto.ProgressNoteResponse, error) {
+	trainer, assignment, err := s.getAssignment(userID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	week := req.Week
+	if week == nil {
+		week = &assignment.CurrentWeek
+	}
+	date := truncateDate(time.Now())
+	if req.Date != nil {
+		date = truncateDate(*req.Date)
+	}
+
+	note := &models.ProgramProgressNote{
+		ProgramAssignmentID: assignment.ID,
+		Week:                week,
+		Date:                date,
+		Note:                req.Note,
+		RecordedByUserID:    &userID,
+		RecordedBy:          trainer.User.Name,
+	}
+	if err := s.programRepo.CreateProgressNote(note); err != nil {
+		return nil, err
+	}
+
+	resp := toProgressNoteResponse(note)
+	return &resp, nil
+}
+
+func (s *programService) DeleteProgressNote(userID, assignmentID, noteID uint) error {
+	if _, _, err := s.getAssignment(userID, assignmentID); err != nil {
+		return err
+	}
+	return notFound(s.programRepo.DeleteProgressNote(assignmentID, noteID))
+}
+
+// ==========================================
+// PROGRESSION
+// ==========================================
+
+// SuggestSession proposes the sets of each exercise planned for an upcoming
+// program session, from the trainee's history of the exercise before it. The
+// progression scheme of the program version the day belongs to is used unless
+// scheme names another.
+func (s *programService) SuggestSession(userID, scheduleID uint, scheme string) (*dto.SessionSuggestionResponse, error) {
+	trainer, err := s.trainerRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	schedule, err := s.scheduleRepo.FindByID(scheduleID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if schedule.TrainerID != trainer.ID {
+		return nil, apperrors.ErrNotFound
+	}
+	if schedule.Status != "scheduled" && schedule.Status != "confirmed" {
+		return nil, fmt.Errorf("%w: suggestions are only made for upcoming sessions", apperrors.ErrInvalidInput)
+	}
+	if schedule.ProgramDayID == nil {
+		return nil, fmt.Errorf("%w: session is not a day of a program plan", apperrors.ErrInvalidInput)
+	}
+
+	day, err := s.planRepo.FindDayByID(*schedule.ProgramDayID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	version, err := s.versionRepo.FindByID(day.ProgramVersionID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	if scheme == "" {
+		scheme = version.ProgressionScheme
+	}
+	if !isProgressionScheme(scheme) {
+		return nil, fmt.Errorf("%w: unknown progression scheme %q", apperrors.ErrInvalidInput, scheme)
+	}
+	increment := version.ProgressionIncrement
+	if increment <= 0 {
+		increment = defaultProgressionIncrement
+	}
+
+	prefs := trainer.User.Units()
+	resp := &dto.SessionSuggestionResponse{
+		ScheduleID:   schedule.ID,
+		TraineeID:    schedule.TraineeID,
+		ProgramDayID: day.ID,
+		Scheme:       scheme,
+		WeightUnit:   string(prefs.For(units.Mass)),
+		DistanceUnit: string(prefs.For(units.Distance)),
+		Exercises:    make([]dto.ExerciseSuggestionResponse, 0, len(day.Exercises)),
+	}
+	for i := range day.Exercises {
+		planned := &day.Exercises[i]
+
+		history, err := s.recordRepo.FindExerciseHistory(schedule.TraineeID, planned.ExerciseLibraryID)
+		if err != nil {
+			return nil, err
+		}
+		before := make([]models.SessionExercise, 0, len(history))
+		for _, exercise := range history {
+			if exercise.SessionCard.ScheduleID != schedule.ID && !exercise.SessionCard.Date.After(schedule.Date) {
+				before = append(before, exercise)
+			}
+		}
+
+		resp.Exercises = append(resp.Exercises, suggestExercise(scheme, increment, planned, before, prefs))
+	}
+	return resp, nil
+}
+
+// ==========================================
+// HELPERS
+// ==========================================
+
+// getProgram loads one of the trainer's programs
+func (s *programService) getProgram(userID, programID uint) (*models.Trainer, *models.Program, error) {
+	trainer, err := s.trainerRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, nil, notFound(err)
+	}
+	program, err := s.programRepo.FindByID(programID)
+	if err != nil {
+		return nil, nil, notFound(err)
+	}
+	if program.TrainerID != trainer.ID {
+		return nil, nil, apperrors.ErrNotFound
+	}
+	return trainer, program, nil
+}
+
+// getAssignment loads an assignment of one of the trainer's programs
+func (s *programService) getAssignment(userID, assignmentID uint) (*models.Trainer, *models.ProgramAssignment, error) {
+	trainer, err := s.trainerRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, nil, notFound(err)
+	}
+	assignment, err := s.programRepo.FindAssignmentByID(assignmentID)
+	if err != nil {
+		return nil, nil, notFound(err)
+	}
+	if assignment.Program.TrainerID != trainer.ID {
+		return nil, nil, apperrors.ErrNotFound
+	}
+	return trainer, assignment, nil
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+	apperrors "fitness-training-backend/pkg/errors"
+	"fitness-training-backend/pkg/units"
+
+	"gorm.io/gorm"
+)
+
+func TestValidatePrescribedSet(t *testing.T) {
+	tests := []struct {
+		name string
+		req  dto.PrescribedSetRequest
+		want string // "" = valid
+	}{
+		{"reps", dto.PrescribedSetRequest{Reps: intPtr(5), Weight: f32(100)}, ""},
+		{"rep range", dto.PrescribedSetRequest{Reps: intPtr(8), RepsMax: intPtr(12)}, ""},
+		{"single rep range", dto.PrescribedSetRequest{Reps: intPtr(8), RepsMax: intPtr(8)}, ""},
+		{"timed", dto.PrescribedSetRequest{Duration: intPtr(60)}, ""},
+		{"distance", dto.PrescribedSetRequest{Distance: f32(5)}, ""},
+		{"percentage of 1RM", dto.PrescribedSetRequest{Reps: intPtr(5), PercentOneRM: f32(80)}, ""},
+		{"nothing to do", dto.PrescribedSetRequest{Weight: f32(100)}, "exercise 2 set 3 needs reps, a duration or a distance"},
+		{"range without reps", dto.PrescribedSetRequest{Duration: intPtr(60), RepsMax: intPtr(10)}, "exercise 2 set 3 has repsMax without reps"},
+		{"range upside down", dto.PrescribedSetRequest{Reps: intPtr(12), RepsMax: intPtr(8)}, "exercise 2 set 3 has repsMax below reps"},
+		{"weight and percentage", dto.PrescribedSetRequest{Reps: intPtr(5), Weight: f32(100), PercentOneRM: f32(80)},
+			"exercise 2 set 3 sets both weight and percentOneRm"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePrescribedSet(2, 3, &tt.req)
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("validatePrescribedSet = %v, want valid", err)
+			case tt.want != "" && (!errors.Is(err, apperrors.ErrInvalidInput) || !strings.Contains(fmt.Sprint(err), tt.want)):
+				t.Errorf("validatePrescribedSet = %v, want invalid input %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateProgramWeek(t *testing.T) {
+	program := &models.Program{TotalWeeks: 4}
+	for week, valid := range map[int]bool{-1: false, 0: false, 1: true, 4: true, 5: false} {
+		err := validateProgramWeek(program, week)
+		if valid != (err == nil) || (err != nil && !errors.Is(err, apperrors.ErrInvalidInput)) {
+			t.Errorf("week %d of 4: %v", week, err)
+		}
+	}
+}
+
+func TestProgramDaySlot(t *testing.T) {
+	program := &models.Program{SessionsPerWeek: 3}
+
+	tests := []struct {
+		name      string
+		existing  []models.ProgramDay
+		requested *int
+		want      int
+		wantErr   error
+	}{
+		{name: "empty week", want: 1},
+		{name: "first free slot", existing: []models.ProgramDay{programDay(2, 1, "Push", 60), programDay(2, 3, "Legs", 60)}, want: 2},
+		{name: "requested slot", existing: []models.ProgramDay{programDay(2, 1, "Push", 60)}, requested: intPtr(3), want: 3},
+		{name: "requested slot taken", existing: []models.ProgramDay{programDay(2, 1, "Push", 60)}, requested: intPtr(1),
+			wantErr: apperrors.ErrConflict},
+		{name: "beyond sessions per week", requested: intPtr(4), wantErr: apperrors.ErrInvalidInput},
+		{name: "full week",
+			existing: []models.ProgramDay{programDay(2, 1, "Push", 60), programDay(2, 2, "Pull", 60), programDay(2, 3, "Legs", 60)},
+			wantErr:  apperrors.ErrConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := programDaySlot(program, 2, tt.existing, tt.requested)
+			if got != tt.want || !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("programDaySlot = %d, %v; want %d, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+// fakeLibrary holds the exercises by ID and counts the lookups
+type fakeLibrary struct {
+	repository.ExerciseRepository
+	exercises map[uint]*models.ExerciseLibrary
+	lookups   int
+}
+
+func (f *fakeLibrary) FindByID(id uint) (*models.ExerciseLibrary, error) {
+	f.lookups++
+	exercise, ok := f.exercises[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return exercise, nil
+}
+
+func newPlanTestService() (*programService, *fakeLibrary) {
+	own, other := uint(1), uint(2)
+	library := &fakeLibrary{exercises: map[uint]*models.ExerciseLibrary{
+		10: {ID: 10, Name: "Squat"},
+		11: {ID: 11, TrainerID: &own, Name: "Sled Push"},
+		12: {ID: 12, TrainerID: &other, Name: "Secret Row"},
+		13: {ID: 13, TrainerID: &other, Name: "Shared Row", IsPublic: true},
+	}}
+	return &programService{exerciseRepo: library}, library
+}
+
+func TestApplyProgramDay(t *testing.T) {
+	s, library := newPlanTestService()
+	trainer := &models.Trainer{ID: 1, User: models.User{WeightUnit: "lb", DistanceUnit: "mi"}}
+	notes := "Brace"
+	day := &models.ProgramDay{ID: 7, Week: 2, Day: 1, Exercises: []models.PlannedExercise{{ExerciseLibraryID: 99}}}
+
+	req := &dto.ProgramDayRequest{
+		Name: "Lower",
+		Exercises: []dto.PlannedExerciseRequest{
+			{ExerciseLibraryID: 10, Notes: &notes, Sets: []dto.PrescribedSetRequest{
+				{Reps: intPtr(5), Weight: f32(225)},
+				{Reps: intPtr(5), PercentOneRM: f32(80)},
+			}},
+			{ExerciseLibraryID: 11, Sets: []dto.PrescribedSetRequest{{Distance: f32(1)}}},
+			{ExerciseLibraryID: 10, Sets: []dto.PrescribedSetRequest{{Reps: intPtr(8), RepsMax: intPtr(10)}}},
+			{ExerciseLibraryID: 13, Sets: []dto.PrescribedSetRequest{{Reps: intPtr(10)}}},
+		},
+	}
+	if err := s.applyProgramDay(trainer, day, req); err != nil {
+		t.Fatal(err)
+	}
+
+	if day.Name != "Lower" || day.Duration != defaultProgramDayDuration || day.Week != 2 || day.Day != 1 {
+		t.Errorf("day = %q of %d min in %d/%d, want Lower of the default length in its slot", day.Name, day.Duration, day.Week, day.Day)
+	}
+	// The exercises are replaced and numbered in order
+	if len(day.Exercises) != 4 {
+		t.Fatalf("day has %d exercises, want 4", len(day.Exercises))
+	}
+	for i, exercise := range day.Exercises {
+		if exercise.Order != i+1 || exercise.Sets[0].SetNumber != 1 {
+			t.Errorf("exercise %d is numbered %d with set %d first", i+1, exercise.Order, exercise.Sets[0].SetNumber)
+		}
+	}
+	// A repeated exercise is looked up once
+	if library.lookups != 3 {
+		t.Errorf("looked up %d exercises, want 3", library.lookups)
+	}
+
+	// Loads are entered in the trainer's units and stored in kg and km
+	squat := day.Exercises[0]
+	if !closeTo(*squat.Sets[0].Weight, 102.06) || squat.Sets[1].Weight != nil || *squat.Sets[1].PercentOneRM != 80 || *squat.Notes != notes {
+		t.Errorf("squat sets %+v, %+v; want 225 lb in kg, then 80%% of 1RM", squat.Sets[0], squat.Sets[1])
+	}
+	if sled := day.Exercises[1].Sets[0]; !closeTo(*sled.Distance, 1.61) {
+		t.Errorf("sled push distance %v, want 1 mi in km", *sled.Distance)
+	}
+	if set := day.Exercises[2].Sets[0]; *set.Reps != 8 || *set.RepsMax != 10 {
+		t.Errorf("rep range %d-%d, want 8-10", *set.Reps, *set.RepsMax)
+	}
+}
+
+func TestApplyProgramDayRejects(t *testing.T) {
+	trainer := &models.Trainer{ID: 1}
+
+	tests := []struct {
+		name     string
+		exercise dto.PlannedExerciseRequest
+		want     string
+	}{
+		{"unknown exercise", dto.PlannedExerciseRequest{ExerciseLibraryID: 99, Sets: []dto.PrescribedSetRequest{{Reps: intPtr(5)}}},
+			"exercise 99 does not exist"},
+		{"another trainer's exercise", dto.PlannedExerciseRequest{ExerciseLibraryID: 12, Sets: []dto.PrescribedSetRequest{{Reps: intPtr(5)}}},
+			"exercise 12 belongs to another trainer"},
+		{"invalid set", dto.PlannedExerciseRequest{ExerciseLibraryID: 10, Sets: []dto.PrescribedSetRequest{{Reps: intPtr(5)}, {}}},
+			"exercise 2 set 2 needs reps"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newPlanTestService()
+			req := &dto.ProgramDayRequest{Name: "Lower", Duration: 45, Exercises: []dto.PlannedExerciseRequest{
+				{ExerciseLibraryID: 10, Sets: []dto.PrescribedSetRequest{{Reps: intPtr(5)}}},
+				tt.exercise,
+			}}
+
+			err := s.applyProgramDay(trainer, &models.ProgramDay{}, req)
+			if !errors.Is(err, apperrors.ErrInvalidInput) || !strings.Contains(fmt.Sprint(err), tt.want) {
+				t.Errorf("applyProgramDay = %v, want invalid input %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCopyProgramDay(t *testing.T) {
+	source := programDay(1, 2, "Pull", 50)
+	source.ProgramID = 4
+	source.ProgramVersionID = 8
+	source.Exercises = []models.PlannedExercise{{
+		ID: 30, ProgramDayID: source.ID, ExerciseLibraryID: 10, Order: 1,
+		Sets: []models.PrescribedSet{
+			{ID: 40, PlannedExerciseID: 30, SetNumber: 1, Reps: intPtr(5), Weight: f32(80)},
+			{ID: 41, PlannedExerciseID: 30, SetNumber: 2, Reps: intPtr(5), Weight: f32(80)},
+		},
+	}}
+
+	day := copyProgramDay(&source, 9, 3)
+
+	if day.ID != 0 || day.ProgramID != 4 || day.ProgramVersionID != 9 || day.Week != 3 || day.Day != 2 ||
+		day.Name != "Pull" || day.Duration != 50 {
+		t.Errorf("copy = %+v, want a new day 3/2 of version 9", day)
+	}
+	exercise := day.Exercises[0]
+	if exercise.ID != 0 || exercise.ProgramDayID != 0 || exercise.ExerciseLibraryID != 10 || len(exercise.Sets) != 2 {
+		t.Fatalf("copied exercise = %+v, want a new one of exercise 10 with 2 sets", exercise)
+	}
+	for i, set := range exercise.Sets {
+		if set.ID != 0 || set.PlannedExerciseID != 0 || set.SetNumber != i+1 || *set.Weight != 80 {
+			t.Errorf("copied set = %+v, want a new set %d at 80 kg", set, i+1)
+		}
+	}
+
+	// The copy does not share sets with the source
+	exercise.Sets[0].SetNumber = 9
+	if source.Exercises[0].Sets[0].SetNumber != 1 {
+		t.Error("changing the copy changed the source")
+	}
+}
+
+func TestPlannedExerciseIDs(t *testing.T) {
+	push, legs := programDay(1, 1, "Push", 60), programDay(1, 2, "Legs", 60)
+	push.Exercises = []models.PlannedExercise{{ExerciseLibraryID: 3}, {ExerciseLibraryID: 1}, {ExerciseLibraryID: 3}}
+	legs.Exercises = []models.PlannedExercise{{ExerciseLibraryID: 2}, {ExerciseLibraryID: 1}}
+
+	if got := fmt.Sprint(plannedExerciseIDs([]models.ProgramDay{push, legs})); got != "[3 1 2]" {
+		t.Errorf("plannedExerciseIDs = %s, want [3 1 2]", got)
+	}
+	if got := plannedExerciseIDs(nil); got == nil || len(got) != 0 {
+		t.Errorf("plannedExerciseIDs(nil) = %#v, want an empty list", got)
+	}
+}
+
+func TestToProgramWeeks(t *testing.T) {
+	days := []models.ProgramDay{
+		programDay(1, 1, "Push", 60),
+		programDay(3, 1, "Push", 60),
+		programDay(1, 2, "Pull", 60),
+		// Left over from a longer program
+		programDay(5, 1, "Legs", 60),
+	}
+
+	weeks := toProgramWeeks(3, days, units.Metric())
+
+	lines := make([]string, 0, len(weeks))
+	for _, week := range weeks {
+		names := make([]string, 0, len(week.Days))
+		for _, day := range week.Days {
+			names = append(names, day.Name)
+		}
+		lines = append(lines, fmt.Sprintf("%d:%s", week.Week, strings.Join(names, ",")))
+	}
+	if got, want := strings.Join(lines, " "), "1:Push,Pull 2: 3:Push"; got != want {
+		t.Errorf("weeks = %s, want %s", got, want)
+	}
+	if weeks[1].Days == nil {
+		t.Error("empty week lists nil days, want an empty list")
+	}
+}
@@ -0,0 +1,592 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"fitness-training-backend/internal/database"
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+	apperrors "fitness-training-backend/pkg/errors"
+	"fitness-training-backend/pkg/units"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// newProgramVersion snapshots the content of a program as its newest version
+func newProgramVersion(program *models.Program) models.ProgramVersion {
+	version := models.ProgramVersion{ProgramID: program.ID, Version: program.Version}
+	setVersionContent(&version, program)
+	return version
+}
+
+// setVersionContent copies the versioned fields of a program into a version
+func setVersionContent(version *models.ProgramVersion, program *models.Program) {
+	version.Name = program.Name
+	version.Description = program.Description
+	version.TotalWeeks = program.TotalWeeks
+	version.SessionsPerWeek = program.SessionsPerWeek
+	version.Goals = program.Goals
+	version.TargetFitnessLevel = program.TargetFitnessLevel
+	version.AdherenceThreshold = program.AdherenceThreshold
+	version.ProgressionScheme = program.ProgressionScheme
+	version.ProgressionIncrement = program.ProgressionIncrement
+}
+
+// applyVersionContent overlays a version's content on its program, e.g. to
+// show trainees the version they are pinned to. A version that was not
+// loaded leaves the program as it is.
+func applyVersionContent(program *models.Program, version *models.ProgramVersion) {
+	if version.ID == 0 {
+		return
+	}
+	program.Name = version.Name
+	program.Description = version.Description
+	program.TotalWeeks = version.TotalWeeks
+	program.SessionsPerWeek = version.SessionsPerWeek
+	program.Goals = version.Goals
+	program.TargetFitnessLevel = version.TargetFitnessLevel
+	program.AdherenceThreshold = version.AdherenceThreshold
+	program.ProgressionScheme = version.ProgressionScheme
+	program.ProgressionIncrement = version.ProgressionIncrement
+}
+
+// editableVersion returns the program's newest version for an edit. A version
+// with assignments is frozen, so it is first copied with its plan into a new
+// version, which becomes the program's newest. Use inside a transaction.
+func editableVersion(tx *gorm.DB, program *models.Program) (*models.ProgramVersion, error) {
+	versionRepo := repository.NewProgramVersionRepository(tx)
+	planRepo := repository.NewProgramPlanRepository(tx)
+
+	head, err := versionRepo.FindByNumber(program.ID, program.Version)
+	if err != nil {
+		return nil, err
+	}
+	assigned, err := versionRepo.CountAssignments(head.ID)
+	if err != nil || assigned == 0 {
+		return head, err
+	}
+
+	next := newProgramVersion(program)
+	next.Version = head.Version + 1
+	if err := versionRepo.Create(&next); err != nil {
+		return nil, err
+	}
+
+	days, err := planRepo.FindDays(head.ID, 0)
+	if err != nil {
+		return nil, err
+	}
+	for i := range days {
+		day := copyProgramDay(&days[i], next.ID, days[i].Week)
+		if err := planRepo.CreateDay(&day); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Model(&models.Program{}).Where("id = ?", program.ID).
+		UpdateColumn("version", next.Version).Error; err != nil {
+		return nil, err
+	}
+	program.Version = next.Version
+	return &next, nil
+}
+
+// ==========================================
+// VERSIONS
+// ==========================================
+
+// GetVersions lists the versions of a program with their assignment stats
+func (s *programService) GetVersions(userID, programID uint) ([]dto.ProgramVersionResponse, error) {
+	_, program, err := s.getProgram(userID, programID)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := s.versionRepo.FindByProgramID(program.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]dto.ProgramVersionResponse, 0, len(versions))
+	for i := range versions {
+		resp = append(resp, toProgramVersionResponse(&versions[i], program.Version))
+	}
+	return resp, nil
+}
+
+// GetVersion returns one version with its plan
+func (s *programService) GetVersion(userID, programID uint, version int) (*dto.ProgramVersionResponse, error) {
+	trainer, program, err := s.getProgram(userID, programID)
+	if err != nil {
+		return nil, err
+	}
+
+	programVersion, days, err := s.getVersionPlan(program, version)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toProgramVersionResponse(programVersion, program.Version)
+	resp.Weeks = toProgramWeeks(programVersion.TotalWeeks, days, trainer.User.Units())
+	return &resp, nil
+}
+
+// DiffVersions lists what changed from one version (default: the previous
+// one) to another
+func (s *programService) DiffVersions(userID, programID uint, version, from int) (*dto.ProgramVersionDiffResponse, error) {
+	trainer, program, err := s.getProgram(userID, programID)
+	if err != nil {
+		return nil, err
+	}
+	if from == 0 {
+		from = version - 1
+	}
+	if from < 1 || from == version {
+		return nil, fmt.Errorf("%w: pick another version to compare version %d with", apperrors.ErrInvalidInput, version)
+	}
+
+	fromVersion, fromDays, err := s.getVersionPlan(program, from)
+	if err != nil {
+		return nil, err
+	}
+	toVersion, toDays, err := s.getVersionPlan(program, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ProgramVersionDiffResponse{
+		From:   from,
+		To:     version,
+		Fields: diffProgramFields(fromVersion, toVersion),
+		Days:   diffProgramDays(fromDays, toDays, trainer.User.Units()),
+	}, nil
+}
+
+// MigrateAssignments moves assignments of older versions to a newer one.
+// Their upcoming sessions follow the new plan: sessions of days that are
+// still planned are updated, those of removed days are cancelled and days
+// added in the new version are laid out (see migrateAssignment). Either every
+// assignment moves or none does.
+func (s *programService) MigrateAssignments(userID, programID uint, version int, req *dto.MigrateAssignmentsRequest) ([]dto.AssignmentMigrationResponse, error) {
+	trainer, program, err := s.getProgram(userID, programID)
+	if err != nil {
+		return nil, err
+	}
+
+	target, days, err := s.getVersionPlan(program, version)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]dto.AssignmentMigrationResponse, 0, len(req.AssignmentIDs))
+	today := gymToday()
+	err = database.Transaction(func(tx *gorm.DB) error {
+		programRepo := repository.NewProgramRepository(tx)
+
+		seen := map[uint]bool{}
+		for _, id := range req.AssignmentIDs {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			assignment, err := programRepo.FindAssignmentByID(id)
+			if err != nil {
+				return notFound(err)
+			}
+			if assignment.ProgramID != program.ID {
+				return apperrors.ErrNotFound
+			}
+			if assignment.Status != "active" && assignment.Status != "paused" {
+				return fmt.Errorf("%w: assignment %d is %s", apperrors.ErrInvalidInput, id, assignment.Status)
+			}
+			if assignment.ProgramVersion.Version >= target.Version {
+				return fmt.Errorf("%w: assignment %d is on version %d; assignments only move forward",
+					apperrors.ErrInvalidInput, id, assignment.ProgramVersion.Version)
+			}
+
+			result, err := migrateAssignment(tx, trainer, program, assignment, target, days, today, userID)
+			if err != nil {
+				return err
+			}
+			resp = append(resp, *result)
+		}
+
+		return repository.NewProgramVersionRepository(tx).RefreshStats(program.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// migrateAssignment pins an assignment to another version. Sessions already
+// done or past stay on the old plan. Upcoming sessions of a day (week, slot)
+// the new version still has are switched to it; those of removed days are
+// cancelled. Days new to the version get sessions at the time and location of
+// the assignment's upcoming sessions (or the client's preferred time), placed
+// like AssignProgram does. Sessions of programs without a plan are left alone.
+func migrateAssignment(tx *gorm.DB, trainer *models.Trainer, program *models.Program, assignment *models.ProgramAssignment, target *models.ProgramVersion, days []models.ProgramDay, today time.Time, userID uint) (*dto.AssignmentMigrationResponse, error) {
+	scheduleRepo := repository.NewScheduleRepository(tx)
+
+	result := &dto.AssignmentMigrationResponse{
+		AssignmentID: assignment.ID,
+		FromVersion:  assignment.ProgramVersion.Version,
+		ToVersion:    target.Version,
+		Unplaced:     []string{},
+	}
+
+	type slot struct{ week, day int }
+	planned := make(map[slot]*models.ProgramDay, len(days))
+	for i := range days {
+		planned[slot{days[i].Week, days[i].Day}] = &days[i]
+	}
+
+	schedules, err := scheduleRepo.FindByAssignment(assignment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	covered := map[slot]bool{}
+	timeOfDay := ""
+	var locationID *uint
+	reason := fmt.Sprintf("Removed in version %d of %s", target.Version, target.Name)
+	for i := range schedules {
+		schedule := &schedules[i]
+		if schedule.Status == "cancelled" || schedule.ProgramDay == nil {
+			continue
+		}
+		key := slot{schedule.ProgramDay.Week, schedule.ProgramDay.Day}
+		if !schedule.CanBeCancelled() || schedule.Date.Before(today) {
+			covered[key] = true
+			continue
+		}
+		if timeOfDay == "" {
+			timeOfDay, locationID = trimSeconds(schedule.Time), schedule.LocationID
+		}
+
+		day, ok := planned[key]
+		if !ok {
+			if err := cancelRemovedSession(scheduleRepo, schedule, reason, userID); err != nil {
+				return nil, err
+			}
+			result.Cancelled++
+			continue
+		}
+
+		covered[key] = true
+		if err := switchSessionToDay(scheduleRepo, trainer.ID, schedule, day); err != nil {
+			return nil, err
+		}
+		result.Updated++
+	}
+
+	// Days new to this version
+	missing := make([]models.ProgramDay, 0)
+	for i := range days {
+		if !covered[slot{days[i].Week, days[i].Day}] {
+			missing = append(missing, days[i])
+		}
+	}
+	if len(missing) > 0 && len(schedules) > 0 {
+		if timeOfDay == "" && assignment.Trainee.PreferredTime != nil {
+			timeOfDay = trimSeconds(*assignment.Trainee.PreferredTime)
+		}
+
+		if timeOfDay == "" {
+			currentWeek := daysBetween(assignment.StartDate, today)/7 + 1
+			for _, day := range missing {
+				if day.Week >= currentWeek {
+					result.Unplaced = append(result.Unplaced, fmt.Sprintf("week %d, day %d: no session time", day.Week, day.Day))
+				}
+			}
+		} else {
+			isFree := func(date time.Time, duration int) (bool, error) {
+				conflict, err := scheduleRepo.CheckConflict(trainer.ID, date, timeOfDay, duration, nil)
+				return !conflict, err
+			}
+			weeks := programWeekSessions(target.TotalWeeks, target.SessionsPerWeek, missing)
+			sessions, conflicts, err := layoutProgram(weeks, assignment.StartDate, today, toWeekdayInts(assignment.Trainee.PreferredWeekdays), isFree)
+			if err != nil {
+				return nil, err
+			}
+			for _, session := range sessions {
+				if err := scheduleRepo.Create(newProgramSchedule(assignment, program, session, timeOfDay, locationID)); err != nil {
+					return nil, err
+				}
+			}
+			result.Added = len(sessions)
+			result.Unplaced = append(result.Unplaced, conflicts...)
+		}
+	}
+
+	assignment.ProgramVersionID = target.ID
+	assignment.EndDate = assignment.StartDate.AddDate(0, 0, target.TotalWeeks*7-1)
+	assignment.TotalSessions += result.Added - result.Cancelled
+	if assignment.TotalSessions < assignment.SessionsCompleted {
+		assignment.TotalSessions = assignment.SessionsCompleted
+	}
+	if assignment.TotalSessions < 1 {
+		assignment.TotalSessions = 1
+	}
+	if err := repository.NewProgramRepository(tx).UpdateAssignment(assignment); err != nil {
+		return nil, err
+	}
+	if _, err := refreshAssignmentProgress(tx, assignment.ID, today); err != nil {
+		return nil, err
+	}
+
+	if result.Updated+result.Cancelled+result.Added > 0 && assignment.Trainee.UserID != 0 {
+		relatedType := "program_assignment"
+		if err := repository.NewNotificationRepository(tx).Create(&models.Notification{
+			UserID: assignment.Trainee.UserID,
+			Type:   "schedule",
+			Title:  "Program updated: " + target.Name,
+			Message: fmt.Sprintf("Your program moved to version %d: %d upcoming sessions updated, %d cancelled, %d added",
+				target.Version, result.Updated, result.Cancelled, result.Added),
+			RelatedID:   &assignment.ID,
+			RelatedType: &relatedType,
+			Priority:    "medium",
+			SentVia:     pq.StringArray{channelInApp},
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// cancelRemovedSession cancels an upcoming session of a day the new version
+// no longer has
+func cancelRemovedSession(scheduleRepo repository.ScheduleRepository, schedule *models.Schedule, reason string, userID uint) error {
+	now := time.Now()
+	schedule.Status = "cancelled"
+	schedule.CancellationReason = &reason
+	schedule.CancelledAt = &now
+	schedule.CancelledBy = &userID
+	return scheduleRepo.UpdateColumns(schedule, cancellationColumns...)
+}
+
+// switchSessionToDay moves an upcoming session to the new version of its
+// day. A longer day keeps the old length when it would overlap.
+func switchSessionToDay(scheduleRepo repository.ScheduleRepository, trainerID uint, schedule *models.Schedule, day *models.ProgramDay) error {
+	applyProgramDayToSchedule(schedule, day)
+	columns := append([]string{}, programDayColumns...)
+	if day.Duration != schedule.Duration {
+		conflict, err := scheduleRepo.CheckConflict(trainerID, schedule.Date, schedule.Time, day.Duration, &schedule.ID)
+		if err != nil {
+			return err
+		}
+		if !conflict {
+			schedule.Duration = day.Duration
+			columns = append(columns, "duration")
+		}
+	}
+	return scheduleRepo.UpdateColumns(schedule, columns...)
+}
+
+// getVersionPlan loads a version of the program with its plan
+func (s *programService) getVersionPlan(program *models.Program, version int) (*models.ProgramVersion, []models.ProgramDay, error) {
+	programVersion, err := s.versionRepo.FindByNumber(program.ID, version)
+	if err != nil {
+		return nil, nil, notFound(err)
+	}
+	days, err := s.planRepo.FindDays(programVersion.ID, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return programVersion, days, nil
+}
+
+// ==========================================
+// DIFF
+// ==========================================
+
+// diffProgramFields lists the program settings that differ
+func diffProgramFields(from, to *models.ProgramVersion) []dto.ProgramFieldChange {
+	changes := make([]dto.ProgramFieldChange, 0)
+	compare := func(field string, a, b interface{}) {
+		if !reflect.DeepEqual(a, b) {
+			changes = append(changes, dto.ProgramFieldChange{Field: field, From: a, To: b})
+		}
+	}
+
+	compare("name", from.Name, to.Name)
+	compare("description", optionalString(from.Description), optionalString(to.Description))
+	compare("totalWeeks", from.TotalWeeks, to.TotalWeeks)
+	compare("sessionsPerWeek", from.SessionsPerWeek, to.SessionsPerWeek)
+	compare("goals", goalList(from.Goals), goalList(to.Goals))
+	compare("targetFitnessLevel", optionalString(from.TargetFitnessLevel), optionalString(to.TargetFitnessLevel))
+	compare("adherenceThreshold", from.AdherenceThreshold, to.AdherenceThreshold)
+	compare("progressionScheme", from.ProgressionScheme, to.ProgressionScheme)
+	compare("progressionIncrement", from.ProgressionIncrement, to.ProgressionIncrement)
+	return changes
+}
+
+// diffProgramDays lists the days added, removed or changed, by week and day,
+// with weights and distances in prefs
+func diffProgramDays(from, to []models.ProgramDay, prefs units.Preferences) []dto.ProgramDayChange {
+	type slot struct{ week, day int }
+	before := make(map[slot]*models.ProgramDay, len(from))
+	for i := range from {
+		before[slot{from[i].Week, from[i].Day}] = &from[i]
+	}
+	after := make(map[slot]*models.ProgramDay, len(to))
+	for i := range to {
+		after[slot{to[i].Week, to[i].Day}] = &to[i]
+	}
+
+	changes := make([]dto.ProgramDayChange, 0)
+	// Both lists are ordered by week and day; removed days are merged in
+	i := 0
+	for j := range to {
+		for i < len(from) && (from[i].Week < to[j].Week || (from[i].Week == to[j].Week && from[i].Day < to[j].Day)) {
+			if after[slot{from[i].Week, from[i].Day}] == nil {
+				changes = append(changes, dto.ProgramDayChange{Week: from[i].Week, Day: from[i].Day, Change: "removed", Name: from[i].Name})
+			}
+			i++
+		}
+
+		day := &to[j]
+		previous := before[slot{day.Week, day.Day}]
+		if previous == nil {
+			changes = append(changes, dto.ProgramDayChange{Week: day.Week, Day: day.Day, Change: "added", Name: day.Name})
+			continue
+		}
+		if details := diffProgramDay(previous, day, prefs); len(details) > 0 {
+			changes = append(changes, dto.ProgramDayChange{Week: day.Week, Day: day.Day, Change: "changed", Name: day.Name, Details: details})
+		}
+	}
+	for ; i < len(from); i++ {
+		if after[slot{from[i].Week, from[i].Day}] == nil {
+			changes = append(changes, dto.ProgramDayChange{Week: from[i].Week, Day: from[i].Day, Change: "removed", Name: from[i].Name})
+		}
+	}
+	return changes
+}
+
+// diffProgramDay describes how a day changed; exercises are compared by
+// position
+func diffProgramDay(from, to *models.ProgramDay, prefs units.Preferences) []string {
+	details := make([]string, 0)
+	if from.Name != to.Name {
+		details = append(details, fmt.Sprintf("Name: %s → %s", from.Name, to.Name))
+	}
+	if !reflect.DeepEqual(from.Weekday, to.Weekday) {
+		details = append(details, fmt.Sprintf("Weekday: %s → %s", weekdayName(from.Weekday), weekdayName(to.Weekday)))
+	}
+	if from.Duration != to.Duration {
+		details = append(details, fmt.Sprintf("Duration: %d → %d min", from.Duration, to.Duration))
+	}
+	if !reflect.DeepEqual(from.Notes, to.Notes) {
+		details = append(details, "Notes changed")
+	}
+
+	count := len(from.Exercises)
+	if len(to.Exercises) > count {
+		count = len(to.Exercises)
+	}
+	for i := 0; i < count; i++ {
+		switch {
+		case i >= len(from.Exercises):
+			added := &to.Exercises[i]
+			details = append(details, fmt.Sprintf("Added %s: %s", plannedExerciseName(added), describePrescription(added.Sets, prefs)))
+		case i >= len(to.Exercises):
+			details = append(details, "Removed "+plannedExerciseName(&from.Exercises[i]))
+		default:
+			a, b := &from.Exercises[i], &to.Exercises[i]
+			before, after := describePrescription(a.Sets, prefs), describePrescription(b.Sets, prefs)
+			switch {
+			case a.ExerciseLibraryID != b.ExerciseLibraryID:
+				details = append(details, fmt.Sprintf("%s replaced by %s: %s", plannedExerciseName(a), plannedExerciseName(b), after))
+			case before != after:
+				details = append(details, fmt.Sprintf("%s: %s → %s", plannedExerciseName(b), before, after))
+			case !reflect.DeepEqual(a.RestDuration, b.RestDuration) || !reflect.DeepEqual(a.Notes, b.Notes):
+				details = append(details, plannedExerciseName(b)+": rest or notes changed")
+			}
+		}
+	}
+	return details
+}
+
+// describePrescription renders prescribed sets, grouping identical ones, e.g.
+// "3×8-12 @ 40 kg, 1×5 @ 85% RPE 9"
+func describePrescription(sets []models.PrescribedSet, prefs units.Preferences) string {
+	parts := make([]string, 0, len(sets))
+	for i := 0; i < len(sets); {
+		set := describeSet(&sets[i], prefs)
+		j := i + 1
+		for j < len(sets) && describeSet(&sets[j], prefs) == set {
+			j++
+		}
+		parts = append(parts, fmt.Sprintf("%d×%s", j-i, set))
+		i = j
+	}
+	if len(parts) == 0 {
+		return "no sets"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func describeSet(set *models.PrescribedSet, prefs units.Preferences) string {
+	var b strings.Builder
+	switch {
+	case set.Reps != nil:
+		b.WriteString(strconv.Itoa(*set.Reps))
+		if set.RepsMax != nil && *set.RepsMax > *set.Reps {
+			b.WriteString("-" + strconv.Itoa(*set.RepsMax))
+		}
+	case set.Duration != nil:
+		b.WriteString(strconv.Itoa(*set.Duration) + " s")
+	case set.Distance != nil:
+		unit := prefs.For(units.Distance)
+		b.WriteString(formatAmount(displayValue(*set.Distance, unit)) + " " + string(unit))
+	}
+
+	switch {
+	case set.Weight != nil:
+		unit := prefs.For(units.Mass)
+		b.WriteString(" @ " + formatAmount(displayValue(*set.Weight, unit)) + " " + string(unit))
+	case set.PercentOneRM != nil:
+		b.WriteString(" @ " + formatAmount(*set.PercentOneRM) + "%")
+	}
+	if set.RPE != nil {
+		b.WriteString(" RPE " + formatAmount(*set.RPE))
+	}
+	return b.String()
+}
+
+func plannedExerciseName(exercise *models.PlannedExercise) string {
+	if exercise.ExerciseLibrary != nil {
+		return exercise.ExerciseLibrary.Name
+	}
+	return fmt.Sprintf("exercise %d", exercise.ExerciseLibraryID)
+}
+
+func weekdayName(weekday *int) string {
+	if weekday == nil {
+		return "any day"
+	}
+	return time.Weekday(*weekday).String()
+}
+
+// optionalString returns the value of a nullable string, or nil
+func optionalString(value *string) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+// goalList treats no goals and an empty list alike
+func goalList(goals pq.StringArray) []string {
+	if len(goals) == 0 {
+		return []string{}
+	}
+	return []string(goals)
+}
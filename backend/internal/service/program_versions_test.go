@@ -0,0 +1,160 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+	"fitness-training-backend/pkg/units"
+)
+
+func programDay(week, day int, name string, duration int) models.ProgramDay {
+	return models.ProgramDay{ID: uint(week*10 + day), Week: week, Day: day, Name: name, Duration: duration}
+}
+
+func describeDayChanges(days []models.ProgramDay, to []models.ProgramDay) string {
+	lines := make([]string, 0)
+	for _, change := range diffProgramDays(days, to, units.Metric()) {
+		line := fmt.Sprintf("%d/%d %s %s", change.Week, change.Day, change.Change, change.Name)
+		if len(change.Details) > 0 {
+			line += " (" + strings.Join(change.Details, "; ") + ")"
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, ", ")
+}
+
+func TestDiffProgramDays(t *testing.T) {
+	squat := models.PlannedExercise{ExerciseLibraryID: 1, ExerciseLibrary: &models.ExerciseLibrary{Name: "Squat"},
+		Sets: []models.PrescribedSet{{Reps: intPtr(5), Weight: f32(100)}, {Reps: intPtr(5), Weight: f32(100)}}}
+	heavier := squat
+	heavier.Sets = []models.PrescribedSet{{Reps: intPtr(5), Weight: f32(105)}, {Reps: intPtr(5), Weight: f32(105)}}
+
+	legs := programDay(1, 1, "Legs", 60)
+	legs.Exercises = []models.PlannedExercise{squat}
+	heavierLegs := legs
+	heavierLegs.Exercises = []models.PlannedExercise{heavier}
+
+	tests := []struct {
+		name     string
+		from, to []models.ProgramDay
+		want     string
+	}{
+		{"unchanged", []models.ProgramDay{legs}, []models.ProgramDay{legs}, ""},
+		{"added at the end",
+			[]models.ProgramDay{legs},
+			[]models.ProgramDay{legs, programDay(2, 1, "Push", 45)},
+			"2/1 added Push"},
+		{"removed at the end",
+			[]models.ProgramDay{legs, programDay(2, 1, "Push", 45)},
+			[]models.ProgramDay{legs},
+			"2/1 removed Push"},
+		// Removed days are listed in week order among the others
+		{"removed in the middle",
+			[]models.ProgramDay{legs, programDay(1, 2, "Pull", 45), programDay(2, 1, "Push", 45)},
+			[]models.ProgramDay{legs, programDay(2, 1, "Push", 45)},
+			"1/2 removed Pull"},
+		{"replaced slot by slot",
+			[]models.ProgramDay{programDay(1, 2, "Pull", 45)},
+			[]models.ProgramDay{programDay(1, 1, "Push", 45)},
+			"1/1 added Push, 1/2 removed Pull"},
+		{"longer", []models.ProgramDay{programDay(1, 1, "Legs", 60)}, []models.ProgramDay{programDay(1, 1, "Legs", 75)},
+			"1/1 changed Legs (Duration: 60 → 75 min)"},
+		{"shorter and renamed", []models.ProgramDay{programDay(1, 1, "Legs", 60)}, []models.ProgramDay{programDay(1, 1, "Lower", 45)},
+			"1/1 changed Lower (Name: Legs → Lower; Duration: 60 → 45 min)"},
+		{"heavier prescription", []models.ProgramDay{legs}, []models.ProgramDay{heavierLegs},
+			"1/1 changed Legs (Squat: 2×5 @ 100 kg → 2×5 @ 105 kg)"},
+		{"exercise dropped", []models.ProgramDay{legs}, []models.ProgramDay{programDay(1, 1, "Legs", 60)},
+			"1/1 changed Legs (Removed Squat)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeDayChanges(tt.from, tt.to); got != tt.want {
+				t.Errorf("diffProgramDays = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeMigratedSchedules reports every length checked as overlapping when
+// overlapping is set, and records the columns written
+type fakeMigratedSchedules struct {
+	repository.ScheduleRepository
+	overlapping bool
+	checked     []int
+	columns     []string
+}
+
+func (f *fakeMigratedSchedules) CheckConflict(trainerID uint, date time.Time, timeStr string, duration int, excludeID *uint) (bool, error) {
+	f.checked = append(f.checked, duration)
+	return f.overlapping, nil
+}
+
+func (f *fakeMigratedSchedules) UpdateColumns(schedule *models.Schedule, columns ...string) error {
+	f.columns = columns
+	return nil
+}
+
+func TestSwitchSessionToDay(t *testing.T) {
+	tests := []struct {
+		name         string
+		duration     int // of the new day
+		overlapping  bool
+		wantDuration int
+		wantChecked  string
+		wantColumns  string
+	}{
+		{name: "same length", duration: 60, wantDuration: 60, wantChecked: "[]",
+			wantColumns: "program_day_id title description planned_exercises"},
+		{name: "longer and free", duration: 90, wantDuration: 90, wantChecked: "[90]",
+			wantColumns: "program_day_id title description planned_exercises duration"},
+		{name: "longer but overlapping keeps its length", duration: 90, overlapping: true, wantDuration: 60, wantChecked: "[90]",
+			wantColumns: "program_day_id title description planned_exercises"},
+		{name: "shorter", duration: 45, wantDuration: 45, wantChecked: "[45]",
+			wantColumns: "program_day_id title description planned_exercises duration"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedules := &fakeMigratedSchedules{overlapping: tt.overlapping}
+			schedule := &models.Schedule{ID: 3, Date: time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC), Time: "09:00", Duration: 60, Title: "Legs"}
+			day := programDay(2, 1, "Lower", tt.duration)
+			day.Exercises = []models.PlannedExercise{{ExerciseLibrary: &models.ExerciseLibrary{Name: "Squat"}}}
+
+			if err := switchSessionToDay(schedules, 1, schedule, &day); err != nil {
+				t.Fatal(err)
+			}
+
+			if schedule.Duration != tt.wantDuration || schedule.Title != "Lower" || *schedule.ProgramDayID != day.ID ||
+				strings.Join(schedule.PlannedExercises, ",") != "Squat" {
+				t.Errorf("schedule = %d min %q of day %d with %v; want %d min of the new day",
+					schedule.Duration, schedule.Title, *schedule.ProgramDayID, schedule.PlannedExercises, tt.wantDuration)
+			}
+			if got := fmt.Sprint(schedules.checked); got != tt.wantChecked {
+				t.Errorf("checked lengths %s, want %s", got, tt.wantChecked)
+			}
+			if got := strings.Join(schedules.columns, " "); got != tt.wantColumns {
+				t.Errorf("wrote %s, want %s", got, tt.wantColumns)
+			}
+		})
+	}
+}
+
+func TestCancelRemovedSession(t *testing.T) {
+	schedules := &fakeMigratedSchedules{}
+	schedule := &models.Schedule{ID: 3, Status: "scheduled"}
+
+	if err := cancelRemovedSession(schedules, schedule, "Removed in version 2 of Strength", 10); err != nil {
+		t.Fatal(err)
+	}
+
+	if schedule.Status != "cancelled" || schedule.CancelledAt == nil || *schedule.CancelledBy != 10 ||
+		*schedule.CancellationReason != "Removed in version 2 of Strength" {
+		t.Errorf("schedule = %+v, want cancelled with the reason", schedule)
+	}
+	if got := strings.Join(schedules.columns, " "); got != strings.Join(cancellationColumns, " ") {
+		t.Errorf("wrote %s, want %v", got, cancellationColumns)
+	}
+}
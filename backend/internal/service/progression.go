@@ -0,0 +1,542 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/pkg/units"
+)
+
+// Progression schemes a program suggests the next session's loads with
+const (
+	progressionDoubleProgression = "double_progression"
+	progressionRPE               = "rpe"
+	progressionPercentOneRM      = "percent_1rm"
+)
+
+const (
+	defaultProgressionIncrement = 2.5 // kg
+	defaultTargetRPE            = 8
+
+	// progressionLookback is how many recent sessions of an exercise the
+	// estimated 1RM is taken from
+	progressionLookback = 3
+
+	deloadFactor = 0.9 // after missing a rep range twice at the same weight
+	weightStep   = 0.5 // kg, suggested weights are rounded to it
+	poundStep    = 1   // lb
+)
+
+// isProgressionScheme reports whether scheme is a known progression scheme
+func isProgressionScheme(scheme string) bool {
+	switch scheme {
+	case progressionDoubleProgression, progressionRPE, progressionPercentOneRM:
+		return true
+	}
+	return false
+}
+
+// suggestExercise proposes the sets of a planned exercise from the trainee's
+// history of it (oldest first) and explains why. Prescribed sets without reps
+// (timed or distance work) are passed through as prescribed. Loads are worked
+// out and reported in the units of prefs.
+func suggestExercise(scheme string, increment float32, planned *models.PlannedExercise, history []models.SessionExercise, prefs units.Preferences) dto.ExerciseSuggestionResponse {
+	unit := prefs.For(units.Mass)
+	planned, history = localizeProgression(planned, history, prefs)
+
+	resp := dto.ExerciseSuggestionResponse{
+		ExerciseLibraryID: planned.ExerciseLibraryID,
+		ExerciseOrder:     planned.Order,
+		Sets:              make([]dto.SuggestedSetResponse, 0, len(planned.Sets)),
+		Reasoning:         []string{},
+	}
+	if planned.ExerciseLibrary != nil {
+		resp.Name = planned.ExerciseLibrary.Name
+		category := planned.ExerciseLibrary.Category
+		resp.Category = &category
+	}
+	for _, set := range planned.Sets {
+		resp.Sets = append(resp.Sets, dto.SuggestedSetResponse{
+			SetNumber:    set.SetNumber,
+			Reps:         set.Reps,
+			RepsMax:      set.RepsMax,
+			Weight:       set.Weight,
+			Duration:     set.Duration,
+			Distance:     set.Distance,
+			RestDuration: planned.RestDuration,
+			TargetRPE:    set.RPE,
+		})
+	}
+
+	// Only sessions where the exercise was actually lifted count
+	recent := make([]models.SessionExercise, 0, len(history))
+	for i := range history {
+		if len(workingSets(&history[i])) > 0 {
+			recent = append(recent, history[i])
+		}
+	}
+	if len(recent) > 0 {
+		date := recent[len(recent)-1].SessionCard.Date
+		resp.LastPerformed = &date
+	}
+	if best, _ := recentOneRepMax(recent); best.e1rm > 0 {
+		e1rm := roundWeight(best.e1rm, unit)
+		resp.EstimatedOneRM = &e1rm
+	}
+
+	if !hasRepSets(planned) {
+		resp.Reasoning = append(resp.Reasoning, "Timed and distance sets are suggested as prescribed")
+		return resp
+	}
+	if len(recent) == 0 {
+		suggestWithoutHistory(&resp, planned)
+		return resp
+	}
+
+	switch scheme {
+	case progressionRPE:
+		suggestRPE(&resp, planned, recent, unit)
+	case progressionPercentOneRM:
+		suggestPercentOneRM(&resp, planned, recent, unit)
+	default:
+		suggestDoubleProgression(&resp, planned, recent, progressionIncrement(increment, unit), unit)
+	}
+	return resp
+}
+
+// suggestWithoutHistory keeps the prescription for an exercise never logged
+func suggestWithoutHistory(resp *dto.ExerciseSuggestionResponse, planned *models.PlannedExercise) {
+	resp.Reasoning = append(resp.Reasoning, "No logged history for this exercise yet")
+
+	open := false
+	for i, set := range planned.Sets {
+		if set.Reps != nil && set.Weight == nil {
+			resp.Sets[i].Weight = nil
+			open = true
+		}
+	}
+	if open {
+		resp.Reasoning = append(resp.Reasoning,
+			fmt.Sprintf("Pick a starting weight that leaves 2-3 reps in reserve (about RPE %d); later sessions build on it", defaultTargetRPE))
+	} else {
+		resp.Reasoning = append(resp.Reasoning, "Use the prescribed weights")
+	}
+}
+
+// suggestDoubleProgression works up a rep range at a fixed weight: once every
+// set reaches the top of its range the weight goes up by the increment and
+// the reps restart at the bottom. Missing the bottom of the range twice in a
+// row at the same weight drops the weight by 10%.
+func suggestDoubleProgression(resp *dto.ExerciseSuggestionResponse, planned *models.PlannedExercise, recent []models.SessionExercise, increment float32, unit units.Unit) {
+	last := &recent[len(recent)-1]
+	weight, top := topSets(workingSets(last))
+	resp.Reasoning = append(resp.Reasoning, "Last session "+describeSets(last, weight, top, unit))
+
+	allTop, missed := rangeResult(planned, top)
+	missedBefore := false
+	if missed && len(recent) > 1 {
+		previousWeight, previousTop := topSets(workingSets(&recent[len(recent)-2]))
+		if previousWeight == weight {
+			_, missedBefore = rangeResult(planned, previousTop)
+		}
+	}
+
+	next := weight
+	switch {
+	case allTop:
+		next = roundWeight(weight+increment, unit)
+		resp.Reasoning = append(resp.Reasoning, fmt.Sprintf(
+			"Every set reached the top of its rep range: add %s (%s) and restart at the bottom of the range",
+			formatWeight(increment, unit), formatWeight(next, unit)))
+	case missed && missedBefore:
+		next = roundWeight(weight*deloadFactor, unit)
+		resp.Reasoning = append(resp.Reasoning, fmt.Sprintf(
+			"Missed the bottom of the rep range two sessions in a row at %s: drop 10%% to %s and build back up",
+			formatWeight(weight, unit), formatWeight(next, unit)))
+	case missed:
+		resp.Reasoning = append(resp.Reasoning, fmt.Sprintf(
+			"Missed the bottom of the rep range: stay at %s and aim for the bottom of the range on every set",
+			formatWeight(weight, unit)))
+	default:
+		resp.Reasoning = append(resp.Reasoning, fmt.Sprintf(
+			"Stay at %s and add a rep to each set until every set reaches the top of its range",
+			formatWeight(weight, unit)))
+	}
+
+	for i, set := range planned.Sets {
+		if set.Reps == nil {
+			continue
+		}
+		low, high := repRange(&set)
+
+		reps := low
+		if !allTop && !missed {
+			reps = *top[minInt(i, len(top)-1)].Reps + 1
+			if reps < low {
+				reps = low
+			}
+			if reps > high {
+				reps = high
+			}
+		}
+
+		resp.Sets[i].Reps = intPtr(reps)
+		resp.Sets[i].Weight = weightPtr(next)
+	}
+}
+
+// suggestRPE estimates today's 1RM from the last session's sets and their
+// logged RPE, then picks the weight that puts each prescribed set at its
+// target RPE. RPE 8 means two reps in reserve, so 5 reps at RPE 8 is the
+// weight good for 7 reps to failure.
+func suggestRPE(resp *dto.ExerciseSuggestionResponse, planned *models.PlannedExercise, recent []models.SessionExercise, unit units.Unit) {
+	last := &recent[len(recent)-1]
+	e1rm, basis, assumed := rpeOneRepMax(workingSets(last))
+	if e1rm == 0 {
+		weight, top := topSets(workingSets(last))
+		resp.Reasoning = append(resp.Reasoning,
+			"Last session "+describeSets(last, weight, top, unit),
+			"No set gives a reliable 1RM estimate (loaded, at most 12 reps): keep the same weight and adjust on the day to the target RPE")
+		for i, set := range planned.Sets {
+			if set.Reps != nil {
+				resp.Sets[i].Weight = weightPtr(weight)
+			}
+		}
+		return
+	}
+
+	rpe := "no logged RPE, taken as RPE 10 so the estimate stays conservative"
+	if !assumed {
+		rpe = fmt.Sprintf("RPE %d", *basis.RPE)
+	}
+	resp.Reasoning = append(resp.Reasoning, fmt.Sprintf(
+		"Last session (%s): %d reps at %s (%s) gives an estimated 1RM of %s",
+		last.SessionCard.Date.Format("2006-01-02"), *basis.Reps, formatWeight(*basis.Weight, unit), rpe, formatWeight(roundWeight(e1rm, unit), unit)))
+
+	explained := map[string]bool{}
+	for i, set := range planned.Sets {
+		if set.Reps == nil {
+			continue
+		}
+		target := targetRPE(&set)
+		reps := *set.Reps
+		weight := weightForReps(e1rm, reps, target, unit)
+
+		resp.Sets[i].Weight = weightPtr(weight)
+		resp.Sets[i].TargetRPE = &target
+
+		reason := fmt.Sprintf("%d reps at RPE %s leaves %d in reserve: %s", reps, formatAmount(target), reserve(target), formatWeight(weight, unit))
+		if !explained[reason] {
+			explained[reason] = true
+			resp.Reasoning = append(resp.Reasoning, reason)
+		}
+	}
+}
+
+// suggestPercentOneRM loads each set at its prescribed percentage of the best
+// 1RM estimated over the recent sessions. Sets without a percentage keep their
+// prescribed weight or, without one, are loaded for their target RPE.
+func suggestPercentOneRM(resp *dto.ExerciseSuggestionResponse, planned *models.PlannedExercise, recent []models.SessionExercise, unit units.Unit) {
+	best, date := recentOneRepMax(recent)
+	if best.e1rm == 0 {
+		resp.Reasoning = append(resp.Reasoning,
+			"No recent set gives a reliable 1RM estimate (loaded, at most 12 reps)")
+		suggestWithoutHistory(resp, planned)
+		return
+	}
+
+	e1rm := best.e1rm
+	resp.Reasoning = append(resp.Reasoning, fmt.Sprintf(
+		"Estimated 1RM %s: best of the last %d session(s), %d reps at %s on %s",
+		formatWeight(roundWeight(e1rm, unit), unit), minInt(len(recent), progressionLookback), best.e1rmReps, formatWeight(best.e1rmWeight, unit), date))
+
+	explained := map[string]bool{}
+	for i, set := range planned.Sets {
+		if set.Reps == nil {
+			continue
+		}
+
+		var reason string
+		switch {
+		case set.PercentOneRM != nil:
+			weight := roundWeight(e1rm**set.PercentOneRM/100, unit)
+			resp.Sets[i].Weight = weightPtr(weight)
+			reason = fmt.Sprintf("%s%% of %s: %s", formatAmount(*set.PercentOneRM), formatWeight(roundWeight(e1rm, unit), unit), formatWeight(weight, unit))
+
+			// More reps than the estimate allows at this percentage
+			if limit := repsToFailure(*set.PercentOneRM); *set.Reps > limit {
+				reason += fmt.Sprintf(" (%d reps at %s%% is more than the estimate allows, about %d; expect to fall short)",
+					*set.Reps, formatAmount(*set.PercentOneRM), limit)
+			}
+		case set.Weight != nil:
+			reason = fmt.Sprintf("No percentage prescribed: keep the prescribed %s", formatWeight(*set.Weight, unit))
+		default:
+			target := targetRPE(&set)
+			weight := weightForReps(e1rm, *set.Reps, target, unit)
+			resp.Sets[i].Weight = weightPtr(weight)
+			resp.Sets[i].TargetRPE = &target
+			reason = fmt.Sprintf("No percentage prescribed: %d reps at RPE %s is %s", *set.Reps, formatAmount(target), formatWeight(weight, unit))
+		}
+
+		if !explained[reason] {
+			explained[reason] = true
+			resp.Reasoning = append(resp.Reasoning, reason)
+		}
+	}
+}
+
+// ==========================================
+// HELPERS
+// ==========================================
+
+// workingSets returns the completed sets with reps of a logged exercise
+func workingSets(exercise *models.SessionExercise) []models.ExerciseSet {
+	sets := make([]models.ExerciseSet, 0, len(exercise.Sets))
+	for _, set := range exercise.Sets {
+		if set.Completed && set.Reps != nil && *set.Reps > 0 {
+			sets = append(sets, set)
+		}
+	}
+	sort.SliceStable(sets, func(i, j int) bool { return sets[i].SetNumber < sets[j].SetNumber })
+	return sets
+}
+
+// topSets returns the heaviest weight of the sets (0 = bodyweight) and the
+// sets done with it
+func topSets(sets []models.ExerciseSet) (float32, []models.ExerciseSet) {
+	var weight float32
+	for _, set := range sets {
+		if w := setWeight(&set); w > weight {
+			weight = w
+		}
+	}
+
+	top := make([]models.ExerciseSet, 0, len(sets))
+	for _, set := range sets {
+		if setWeight(&set) == weight {
+			top = append(top, set)
+		}
+	}
+	return weight, top
+}
+
+// rangeResult compares the sets done at the top weight with the prescribed
+// rep ranges, matching them in order (extra prescribed sets are compared with
+// the last set done)
+func rangeResult(planned *models.PlannedExercise, top []models.ExerciseSet) (allTop, missed bool) {
+	allTop = true
+	for i, set := range planned.Sets {
+		if set.Reps == nil {
+			continue
+		}
+		low, high := repRange(&set)
+		done := *top[minInt(i, len(top)-1)].Reps
+		if done < high {
+			allTop = false
+		}
+		if done < low {
+			missed = true
+		}
+	}
+	return allTop, missed
+}
+
+// rpeOneRepMax estimates a 1RM from each loaded set as if it had been taken
+// to failure: reps done plus reps in reserve. Sets without a logged RPE count
+// as RPE 10, which can only underestimate. It returns the best estimate, the
+// set it came from and whether that set's RPE was assumed.
+func rpeOneRepMax(sets []models.ExerciseSet) (float32, models.ExerciseSet, bool) {
+	var (
+		best    float32
+		basis   models.ExerciseSet
+		assumed bool
+	)
+	for _, set := range sets {
+		weight := setWeight(&set)
+		if weight == 0 || *set.Reps > maxRepsFor1RM {
+			continue
+		}
+
+		rpe, logged := 10, set.RPE != nil && *set.RPE >= 1 && *set.RPE <= 10
+		if logged {
+			rpe = *set.RPE
+		}
+		if e1rm := estimateOneRepMax(weight, *set.Reps+10-rpe); e1rm > best {
+			best, basis, assumed = e1rm, set, !logged
+		}
+	}
+	return best, basis, assumed
+}
+
+// recentOneRepMax returns the best estimated 1RM of the last few sessions and
+// the date it was set
+func recentOneRepMax(recent []models.SessionExercise) (exerciseBest, string) {
+	var (
+		best exerciseBest
+		date string
+	)
+	for i := len(recent) - 1; i >= 0 && i >= len(recent)-progressionLookback; i-- {
+		if session := bestOfSets(recent[i].Sets); session.e1rm > best.e1rm {
+			best = session
+			date = recent[i].SessionCard.Date.Format("2006-01-02")
+		}
+	}
+	return best, date
+}
+
+// weightForReps is the weight for reps at a target RPE given a 1RM
+func weightForReps(e1rm float32, reps int, rpe float32, unit units.Unit) float32 {
+	return roundWeight(e1rm/estimateOneRepMax(1, reps+reserve(rpe)), unit)
+}
+
+// repsToFailure is how many reps a percentage of 1RM allows
+func repsToFailure(percent float32) int {
+	reps := 1
+	for reps < 30 && estimateOneRepMax(1, reps+1)*percent/100 <= 1 {
+		reps++
+	}
+	return reps
+}
+
+// reserve converts an RPE to whole reps in reserve
+func reserve(rpe float32) int {
+	reps := int(math.Round(float64(10 - rpe)))
+	if reps < 0 {
+		return 0
+	}
+	return reps
+}
+
+func targetRPE(set *models.PrescribedSet) float32 {
+	if set.RPE != nil {
+		return *set.RPE
+	}
+	return defaultTargetRPE
+}
+
+// repRange returns the prescribed rep range of a set with reps
+func repRange(set *models.PrescribedSet) (int, int) {
+	low := *set.Reps
+	if set.RepsMax != nil && *set.RepsMax > low {
+		return low, *set.RepsMax
+	}
+	return low, low
+}
+
+func hasRepSets(planned *models.PlannedExercise) bool {
+	for _, set := range planned.Sets {
+		if set.Reps != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func setWeight(set *models.ExerciseSet) float32 {
+	if set.Weight != nil && *set.Weight > 0 {
+		return *set.Weight
+	}
+	return 0
+}
+
+// describeSets renders the top sets of a logged exercise, e.g.
+// "(2026-10-11): 12, 12, 11 reps at 40 kg"
+func describeSets(exercise *models.SessionExercise, weight float32, top []models.ExerciseSet, unit units.Unit) string {
+	reps := make([]string, 0, len(top))
+	for _, set := range top {
+		reps = append(reps, fmt.Sprint(*set.Reps))
+	}
+	return fmt.Sprintf("(%s): %s reps at %s",
+		exercise.SessionCard.Date.Format("2006-01-02"), strings.Join(reps, ", "), formatWeight(weight, unit))
+}
+
+// formatWeight prints a weight in unit, or bodyweight for none
+func formatWeight(weight float32, unit units.Unit) string {
+	if weight <= 0 {
+		return "bodyweight"
+	}
+	return formatAmount(weight) + " " + string(unit)
+}
+
+// loadStep is the smallest weight change suggested in unit
+func loadStep(unit units.Unit) float64 {
+	if unit == units.Pound {
+		return poundStep
+	}
+	return weightStep
+}
+
+// roundWeight rounds a weight in unit to the nearest loadable step
+func roundWeight(weight float32, unit units.Unit) float32 {
+	step := loadStep(unit)
+	return float32(math.Round(float64(weight)/step) * step)
+}
+
+// progressionIncrement converts a program's increment (kg) to unit. Converted
+// increments are rounded down to a loadable step so they never add more than
+// the program asks for.
+func progressionIncrement(increment float32, unit units.Unit) float32 {
+	if unit == units.Kilogram {
+		return increment
+	}
+	step := loadStep(unit)
+	steps := math.Floor(units.FromCanonical(float64(increment), unit) / step)
+	if steps < 1 {
+		steps = 1
+	}
+	return float32(steps * step)
+}
+
+// localizeProgression copies a planned exercise and its history with weights
+// and distances converted to prefs, so loads are worked out in the units
+// they are lifted in
+func localizeProgression(planned *models.PlannedExercise, history []models.SessionExercise, prefs units.Preferences) (*models.PlannedExercise, []models.SessionExercise) {
+	weightUnit, distanceUnit := prefs.For(units.Mass), prefs.For(units.Distance)
+	if weightUnit == units.Kilogram && distanceUnit == units.Kilometer {
+		return planned, history
+	}
+
+	localPlanned := *planned
+	localPlanned.Sets = make([]models.PrescribedSet, 0, len(planned.Sets))
+	for _, set := range planned.Sets {
+		set.Weight = displayPtr(set.Weight, weightUnit)
+		set.Distance = displayPtr(set.Distance, distanceUnit)
+		localPlanned.Sets = append(localPlanned.Sets, set)
+	}
+
+	localHistory := make([]models.SessionExercise, 0, len(history))
+	for _, exercise := range history {
+		sets := make([]models.ExerciseSet, 0, len(exercise.Sets))
+		for _, set := range exercise.Sets {
+			set.Weight = displayPtr(set.Weight, weightUnit)
+			set.Distance = displayPtr(set.Distance, distanceUnit)
+			sets = append(sets, set)
+		}
+		exercise.Sets = sets
+		localHistory = append(localHistory, exercise)
+	}
+	return &localPlanned, localHistory
+}
+
+// weightPtr returns nil for bodyweight
+func weightPtr(weight float32) *float32 {
+	if weight <= 0 {
+		return nil
+	}
+	return &weight
+}
+
+func intPtr(value int) *int {
+	return &value
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,366 @@
+package service
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/pkg/units"
+)
+
+func f32(value float32) *float32 {
+	return &value
+}
+
+func closeTo(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 0.01
+}
+
+// lifted is a completed set; rpe 0 means none was logged
+func lifted(setNumber, reps int, weight float32, rpe int) models.ExerciseSet {
+	set := models.ExerciseSet{SetNumber: setNumber, Reps: intPtr(reps), Completed: true}
+	if weight > 0 {
+		set.Weight = f32(weight)
+	}
+	if rpe > 0 {
+		set.RPE = intPtr(rpe)
+	}
+	return set
+}
+
+// session logs the same weight for each of reps, in March 2024
+func session(day int, weight float32, reps ...int) models.SessionExercise {
+	sets := make([]models.ExerciseSet, 0, len(reps))
+	for i, r := range reps {
+		sets = append(sets, lifted(i+1, r, weight, 0))
+	}
+	return models.SessionExercise{
+		SessionCard: models.SessionCard{Date: time.Date(2024, 3, day, 0, 0, 0, 0, time.UTC)},
+		Sets:        sets,
+	}
+}
+
+// plannedSets prescribes the same set count times
+func plannedSets(count int, set models.PrescribedSet) *models.PlannedExercise {
+	planned := &models.PlannedExercise{}
+	for i := 0; i < count; i++ {
+		set.SetNumber = i + 1
+		planned.Sets = append(planned.Sets, set)
+	}
+	return planned
+}
+
+type suggestedSet struct {
+	reps   int
+	weight float32
+}
+
+func suggestedSets(resp dto.ExerciseSuggestionResponse) []suggestedSet {
+	sets := make([]suggestedSet, 0, len(resp.Sets))
+	for _, set := range resp.Sets {
+		var s suggestedSet
+		if set.Reps != nil {
+			s.reps = *set.Reps
+		}
+		if set.Weight != nil {
+			s.weight = *set.Weight
+		}
+		sets = append(sets, s)
+	}
+	return sets
+}
+
+func equalSets(a, b []suggestedSet) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].reps != b[i].reps || !closeTo(a[i].weight, b[i].weight) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSuggestDoubleProgression(t *testing.T) {
+	planned := plannedSets(3, models.PrescribedSet{Reps: intPtr(8), RepsMax: intPtr(12)})
+	warmedUp := session(10, 40, 12, 12, 12)
+	warmedUp.Sets = append([]models.ExerciseSet{lifted(0, 12, 20, 0)}, warmedUp.Sets...)
+
+	tests := []struct {
+		name    string
+		history []models.SessionExercise
+		want    []suggestedSet
+	}{
+		{"top of the range", []models.SessionExercise{session(10, 40, 12, 12, 12)},
+			[]suggestedSet{{8, 42.5}, {8, 42.5}, {8, 42.5}}},
+		{"within the range", []models.SessionExercise{session(10, 40, 10, 9, 8)},
+			[]suggestedSet{{11, 40}, {10, 40}, {9, 40}}},
+		{"reps capped at the top", []models.SessionExercise{session(10, 40, 12, 10, 9)},
+			[]suggestedSet{{12, 40}, {11, 40}, {10, 40}}},
+		{"fewer sets done than prescribed", []models.SessionExercise{session(10, 40, 10, 9)},
+			[]suggestedSet{{11, 40}, {10, 40}, {10, 40}}},
+		{"missed once", []models.SessionExercise{session(3, 37.5, 12, 12, 12), session(10, 40, 8, 7, 6)},
+			[]suggestedSet{{8, 40}, {8, 40}, {8, 40}}},
+		{"missed twice at the same weight", []models.SessionExercise{session(3, 40, 8, 7, 7), session(10, 40, 8, 7, 6)},
+			[]suggestedSet{{8, 36}, {8, 36}, {8, 36}}},
+		{"lighter sets are ignored", []models.SessionExercise{warmedUp},
+			[]suggestedSet{{8, 42.5}, {8, 42.5}, {8, 42.5}}},
+		{"sessions without working sets are ignored", []models.SessionExercise{session(10, 40, 12, 12, 12), {Sets: []models.ExerciseSet{{SetNumber: 1, Reps: intPtr(12)}}}},
+			[]suggestedSet{{8, 42.5}, {8, 42.5}, {8, 42.5}}},
+		{"bodyweight starts loading", []models.SessionExercise{session(10, 0, 12, 12, 12)},
+			[]suggestedSet{{8, 2.5}, {8, 2.5}, {8, 2.5}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := suggestExercise(progressionDoubleProgression, defaultProgressionIncrement, planned, tt.history, units.Metric())
+			if got := suggestedSets(resp); !equalSets(got, tt.want) {
+				t.Errorf("sets = %v, want %v (%q)", got, tt.want, resp.Reasoning)
+			}
+		})
+	}
+}
+
+func TestRPEOneRepMax(t *testing.T) {
+	tests := []struct {
+		name    string
+		sets    []models.ExerciseSet
+		want    float32
+		basis   int // set number
+		assumed bool
+	}{
+		// 5 reps at RPE 8 is good for 7 to failure
+		{"logged RPE", []models.ExerciseSet{lifted(1, 5, 100, 8)}, 120, 1, false},
+		{"no RPE counts as a max effort", []models.ExerciseSet{lifted(1, 5, 100, 0)}, 112.5, 1, true},
+		{"out of range RPE counts as a max effort", []models.ExerciseSet{lifted(1, 5, 100, 11)}, 112.5, 1, true},
+		{"best set", []models.ExerciseSet{lifted(1, 5, 100, 8), lifted(2, 3, 105, 7)}, 121.94, 2, false},
+		{"too many reps", []models.ExerciseSet{lifted(1, 13, 60, 8)}, 0, 0, false},
+		{"bodyweight", []models.ExerciseSet{lifted(1, 5, 0, 8)}, 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e1rm, basis, assumed := rpeOneRepMax(tt.sets)
+			if !closeTo(e1rm, tt.want) || basis.SetNumber != tt.basis || assumed != tt.assumed {
+				t.Errorf("rpeOneRepMax = %v from set %d (assumed %v), want %v from set %d (assumed %v)",
+					e1rm, basis.SetNumber, assumed, tt.want, tt.basis, tt.assumed)
+			}
+		})
+	}
+}
+
+func TestWeightForReps(t *testing.T) {
+	tests := []struct {
+		e1rm float32
+		reps int
+		rpe  float32
+		unit units.Unit
+		want float32
+	}{
+		{120, 5, 8, units.Kilogram, 100},
+		{120, 1, 10, units.Kilogram, 120},
+		// RPE 8.5 rounds to two reps in reserve; 106.67 rounds to 106.5
+		{120, 3, 8.5, units.Kilogram, 106.5},
+		{265, 5, 8, units.Pound, 221},
+	}
+	for _, tt := range tests {
+		if got := weightForReps(tt.e1rm, tt.reps, tt.rpe, tt.unit); !closeTo(got, tt.want) {
+			t.Errorf("weightForReps(%v, %d, %v, %s) = %v, want %v", tt.e1rm, tt.reps, tt.rpe, tt.unit, got, tt.want)
+		}
+	}
+}
+
+func TestReserve(t *testing.T) {
+	tests := map[float32]int{10: 0, 9: 1, 8: 2, 8.5: 2, 7.4: 3, 6: 4, 11: 0}
+	for rpe, want := range tests {
+		if got := reserve(rpe); got != want {
+			t.Errorf("reserve(%v) = %d, want %d", rpe, got, want)
+		}
+	}
+}
+
+func TestSuggestRPE(t *testing.T) {
+	planned := plannedSets(3, models.PrescribedSet{Reps: intPtr(5), RPE: f32(8)})
+	last := session(10, 0)
+	last.Sets = []models.ExerciseSet{lifted(1, 5, 100, 8), lifted(2, 5, 100, 9)}
+
+	resp := suggestExercise(progressionRPE, defaultProgressionIncrement, planned, []models.SessionExercise{last}, units.Metric())
+	if got, want := suggestedSets(resp), []suggestedSet{{5, 100}, {5, 100}, {5, 100}}; !equalSets(got, want) {
+		t.Errorf("sets = %v, want %v", got, want)
+	}
+	for _, set := range resp.Sets {
+		if set.TargetRPE == nil || *set.TargetRPE != 8 {
+			t.Errorf("target RPE = %v, want 8", set.TargetRPE)
+		}
+	}
+	// The last session, then one explanation for the three identical sets
+	if len(resp.Reasoning) != 2 || !strings.Contains(resp.Reasoning[0], "5 reps at 100 kg (RPE 8) gives an estimated 1RM of 120 kg") {
+		t.Errorf("reasoning = %q", resp.Reasoning)
+	}
+}
+
+func TestSuggestRPEWithoutEstimate(t *testing.T) {
+	planned := plannedSets(2, models.PrescribedSet{Reps: intPtr(5), RPE: f32(8)})
+	history := []models.SessionExercise{session(10, 40, 15, 15)}
+
+	resp := suggestExercise(progressionRPE, defaultProgressionIncrement, planned, history, units.Metric())
+	if got, want := suggestedSets(resp), []suggestedSet{{5, 40}, {5, 40}}; !equalSets(got, want) {
+		t.Errorf("sets = %v, want the last weight %v", got, want)
+	}
+}
+
+func TestSuggestPercentOneRM(t *testing.T) {
+	planned := &models.PlannedExercise{Sets: []models.PrescribedSet{
+		{SetNumber: 1, Reps: intPtr(5), PercentOneRM: f32(80)},
+		{SetNumber: 2, Reps: intPtr(10), PercentOneRM: f32(80)},
+		{SetNumber: 3, Reps: intPtr(5), Weight: f32(70)},
+		{SetNumber: 4, Reps: intPtr(3)},
+		{SetNumber: 5, Duration: intPtr(60)},
+	}}
+	// The single is older than the last three sessions; the best of those
+	// is 100 kg x 5 (112.5 kg)
+	history := []models.SessionExercise{
+		session(1, 120, 1),
+		session(3, 100, 5),
+		session(6, 90, 5),
+		session(9, 95, 5),
+	}
+
+	resp := suggestExercise(progressionPercentOneRM, defaultProgressionIncrement, planned, history, units.Metric())
+	want := []suggestedSet{{5, 90}, {10, 90}, {5, 70}, {3, 100}, {0, 0}}
+	if got := suggestedSets(resp); !equalSets(got, want) {
+		t.Errorf("sets = %v, want %v", got, want)
+	}
+	if resp.Sets[3].TargetRPE == nil || *resp.Sets[3].TargetRPE != defaultTargetRPE {
+		t.Errorf("set without a percentage has target RPE %v, want %d", resp.Sets[3].TargetRPE, defaultTargetRPE)
+	}
+
+	reasoning := strings.Join(resp.Reasoning, "\n")
+	for _, want := range []string{
+		"Estimated 1RM 112.5 kg: best of the last 3 session(s), 5 reps at 100 kg on 2024-03-03",
+		"10 reps at 80% is more than the estimate allows, about 8",
+		"keep the prescribed 70 kg",
+	} {
+		if !strings.Contains(reasoning, want) {
+			t.Errorf("reasoning lacks %q:\n%s", want, reasoning)
+		}
+	}
+}
+
+func TestSuggestPercentOneRMWithoutEstimate(t *testing.T) {
+	planned := plannedSets(2, models.PrescribedSet{Reps: intPtr(5), PercentOneRM: f32(80)})
+	history := []models.SessionExercise{session(10, 40, 15, 15)}
+
+	resp := suggestExercise(progressionPercentOneRM, defaultProgressionIncrement, planned, history, units.Metric())
+	if got, want := suggestedSets(resp), []suggestedSet{{5, 0}, {5, 0}}; !equalSets(got, want) {
+		t.Errorf("sets = %v, want open weights %v", got, want)
+	}
+	if !strings.Contains(strings.Join(resp.Reasoning, "\n"), "Pick a starting weight") {
+		t.Errorf("reasoning = %q", resp.Reasoning)
+	}
+}
+
+func TestSuggestWithoutHistory(t *testing.T) {
+	tests := []struct {
+		name    string
+		planned *models.PlannedExercise
+		history []models.SessionExercise
+		want    []suggestedSet
+		reason  string
+	}{
+		{"open weights", plannedSets(2, models.PrescribedSet{Reps: intPtr(8)}), nil,
+			[]suggestedSet{{8, 0}, {8, 0}}, "Pick a starting weight that leaves 2-3 reps in reserve (about RPE 8)"},
+		{"prescribed weights", plannedSets(2, models.PrescribedSet{Reps: intPtr(8), Weight: f32(60)}), nil,
+			[]suggestedSet{{8, 60}, {8, 60}}, "Use the prescribed weights"},
+		{"nothing completed yet", plannedSets(1, models.PrescribedSet{Reps: intPtr(8), Weight: f32(60)}),
+			[]models.SessionExercise{{Sets: []models.ExerciseSet{{SetNumber: 1, Reps: intPtr(8), Weight: f32(80)}}}},
+			[]suggestedSet{{8, 60}}, "No logged history for this exercise yet"},
+		{"timed sets", plannedSets(2, models.PrescribedSet{Duration: intPtr(60)}), []models.SessionExercise{session(10, 40, 12)},
+			[]suggestedSet{{0, 0}, {0, 0}}, "Timed and distance sets are suggested as prescribed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, scheme := range []string{progressionDoubleProgression, progressionRPE, progressionPercentOneRM} {
+				resp := suggestExercise(scheme, defaultProgressionIncrement, tt.planned, tt.history, units.Metric())
+				if got := suggestedSets(resp); !equalSets(got, tt.want) {
+					t.Errorf("%s: sets = %v, want %v", scheme, got, tt.want)
+				}
+				if !strings.Contains(strings.Join(resp.Reasoning, "\n"), tt.reason) {
+					t.Errorf("%s: reasoning = %q, want %q", scheme, resp.Reasoning, tt.reason)
+				}
+			}
+		})
+	}
+}
+
+func TestRoundWeight(t *testing.T) {
+	tests := []struct {
+		weight float32
+		unit   units.Unit
+		want   float32
+	}{
+		{42.3, units.Kilogram, 42.5},
+		{42.2, units.Kilogram, 42},
+		{36, units.Kilogram, 36},
+		{101.4, units.Pound, 101},
+		{101.6, units.Pound, 102},
+	}
+	for _, tt := range tests {
+		if got := roundWeight(tt.weight, tt.unit); got != tt.want {
+			t.Errorf("roundWeight(%v, %s) = %v, want %v", tt.weight, tt.unit, got, tt.want)
+		}
+	}
+}
+
+func TestProgressionIncrement(t *testing.T) {
+	tests := []struct {
+		increment float32
+		unit      units.Unit
+		want      float32
+	}{
+		{2.5, units.Kilogram, 2.5},
+		{1.25, units.Kilogram, 1.25},
+		// 5.5 lb is rounded down to a loadable 5 lb
+		{2.5, units.Pound, 5},
+		{1, units.Pound, 2},
+		// Never less than one step
+		{0.25, units.Pound, 1},
+	}
+	for _, tt := range tests {
+		if got := progressionIncrement(tt.increment, tt.unit); got != tt.want {
+			t.Errorf("progressionIncrement(%v, %s) = %v, want %v", tt.increment, tt.unit, got, tt.want)
+		}
+	}
+}
+
+func TestSuggestExerciseImperial(t *testing.T) {
+	// 135 lb is stored as 61.24 kg
+	planned := plannedSets(2, models.PrescribedSet{Reps: intPtr(8), RepsMax: intPtr(12)})
+	history := []models.SessionExercise{session(10, 61.24, 12, 12)}
+
+	resp := suggestExercise(progressionDoubleProgression, defaultProgressionIncrement, planned, history, units.Imperial())
+	if got, want := suggestedSets(resp), []suggestedSet{{8, 140}, {8, 140}}; !equalSets(got, want) {
+		t.Errorf("sets = %v, want %v", got, want)
+	}
+	if resp.EstimatedOneRM == nil || *resp.EstimatedOneRM != 189 {
+		t.Errorf("estimated 1RM = %v, want 189 lb", resp.EstimatedOneRM)
+	}
+	if reasoning := strings.Join(resp.Reasoning, "\n"); !strings.Contains(reasoning, "12, 12 reps at 135 lb") ||
+		!strings.Contains(reasoning, "add 5 lb (140 lb)") {
+		t.Errorf("reasoning = %q", resp.Reasoning)
+	}
+
+	// The prescription and history are converted on copies
+	if planned.Sets[0].Weight != nil || *history[0].Sets[0].Weight != 61.24 {
+		t.Error("the inputs were converted in place")
+	}
+
+	// Prescribed kilograms are suggested in pounds too
+	prescribed := plannedSets(1, models.PrescribedSet{Reps: intPtr(5), Weight: f32(100)})
+	resp = suggestExercise(progressionDoubleProgression, defaultProgressionIncrement, prescribed, nil, units.Imperial())
+	if got, want := suggestedSets(resp), []suggestedSet{{5, 220.5}}; !equalSets(got, want) {
+		t.Errorf("prescribed sets = %v, want %v", got, want)
+	}
+}
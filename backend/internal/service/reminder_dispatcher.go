@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"fitness-training-backend/internal/config"
+	"fitness-training-backend/internal/database"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// Channels a notification can be delivered through (Notification.SentVia)
+const (
+	channelInApp = "in_app"
+)
+
+// reminderColumns are the schedule columns Schedule.ResetReminders clears
+var reminderColumns = []string{"reminder_sent", "reminder_sent_at", "reminder_leads_sent"}
+
+// ReminderDispatcher periodically creates reminder notifications for
+// upcoming sessions. Every API replica may run one: each reminder is claimed
+// with an atomic update on the schedule before it is created.
+type ReminderDispatcher struct {
+	scheduleRepo repository.ScheduleRepository
+	leadTimes    []time.Duration // longest first
+	interval     time.Duration
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewReminderDispatcher creates a dispatcher for the configured lead times
+func NewReminderDispatcher(scheduleRepo repository.ScheduleRepository, cfg config.ReminderConfig) *ReminderDispatcher {
+	leadTimes := append([]time.Duration{}, cfg.LeadTimes...)
+	sort.Slice(leadTimes, func(i, j int) bool { return leadTimes[i] > leadTimes[j] })
+
+	return &ReminderDispatcher{
+		scheduleRepo: scheduleRepo,
+		leadTimes:    leadTimes,
+		interval:     cfg.Interval,
+	}
+}
+
+// Start runs the dispatcher in the background until Stop is called
+func (d *ReminderDispatcher) Start() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.cancel != nil || len(d.leadTimes) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	d.done = make(chan struct{})
+
+	go d.run(ctx, d.done)
+}
+
+// Stop ends the background loop and waits for a dispatch in progress
+func (d *ReminderDispatcher) Stop() {
+	d.mu.Lock()
+	cancel, done := d.cancel, d.done
+	d.cancel, d.done = nil, nil
+	d.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+func (d *ReminderDispatcher) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		if sent, err := d.DispatchDue(time.Now()); err != nil {
+			log.Printf("⚠️  Reminder dispatch failed: %v", err)
+		} else if sent > 0 {
+			log.Printf("🔔 Sent %d session reminder(s)", sent)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchDue sends the reminders due at now and returns how many sessions
+// were reminded. A session gets one reminder per lead time; when it is
+// already inside a shorter lead time (e.g. booked two hours ahead) only the
+// shortest due reminder is sent.
+func (d *ReminderDispatcher) DispatchDue(now time.Time) (int, error) {
+	if len(d.leadTimes) == 0 {
+		return 0, nil
+	}
+
+	// Dates are local to the schedule's timezone; pad the range by a day on
+	// each side and compare exact start times below
+	fromDate := truncateDate(now).AddDate(0, 0, -1)
+	toDate := truncateDate(now.Add(d.leadTimes[0])).AddDate(0, 0, 1)
+
+	schedules, err := d.scheduleRepo.FindPendingBetween(fromDate, toDate)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for i := range schedules {
+		schedule := &schedules[i]
+
+		start, err := scheduleStart(schedule)
+		if err != nil {
+			log.Printf("⚠️  Reminder skipped for schedule %d: %v", schedule.ID, err)
+			continue
+		}
+
+		lead, covered, ok := d.dueLead(start.Sub(now), schedule.ReminderLeadsSent)
+		if !ok {
+			continue
+		}
+
+		claimed, err := d.remind(schedule, start, lead, covered, now)
+		if err != nil {
+			log.Printf("⚠️  Reminder failed for schedule %d: %v", schedule.ID, err)
+			continue
+		}
+		if claimed {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// dueLead picks the shortest lead time the session is within, together with
+// the lead times (in minutes) sending it covers: that one and every longer one
+func (d *ReminderDispatcher) dueLead(untilStart time.Duration, alreadySent pq.Int64Array) (time.Duration, []int64, bool) {
+	if untilStart <= 0 {
+		return 0, nil, false
+	}
+
+	due := -1
+	for i, lead := range d.leadTimes {
+		if untilStart <= lead {
+			due = i
+		}
+	}
+	if due < 0 {
+		return 0, nil, false
+	}
+
+	lead := d.leadTimes[due]
+	minutes := int64(lead / time.Minute)
+	for _, sent := range alreadySent {
+		if sent == minutes {
+			return 0, nil, false
+		}
+	}
+
+	covered := make([]int64, 0, due+1)
+	for _, longer := range d.leadTimes[:due+1] {
+		covered = append(covered, int64(longer/time.Minute))
+	}
+	return lead, covered, true
+}
+
+// remind claims the reminder and creates the notifications in one
+// transaction. It reports false when another replica got there first.
+func (d *ReminderDispatcher) remind(schedule *models.Schedule, start time.Time, lead time.Duration, covered []int64, now time.Time) (bool, error) {
+	claimed := false
+	err := database.Transaction(func(tx *gorm.DB) error {
+		ok, err := repository.NewScheduleRepository(tx).ClaimReminder(schedule.ID, int(lead/time.Minute), covered, now)
+		if err != nil || !ok {
+			return err
+		}
+		claimed = true
+
+		notificationRepo := repository.NewNotificationRepository(tx)
+		for _, notification := range reminderNotifications(schedule, start, lead) {
+			if err := notificationRepo.Create(notification); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return claimed, err
+}
+
+// reminderNotifications builds the reminders for the trainee and the trainer
+func reminderNotifications(schedule *models.Schedule, start time.Time, lead time.Duration) []*models.Notification {
+	when := start.Format("Mon 2 Jan at 15:04")
+	where := ""
+	if schedule.Location != nil {
+		where = " at " + schedule.Location.Name
+	}
+
+	priority := "medium"
+	if lead <= 2*time.Hour {
+		priority = "high"
+	}
+
+	newReminder := func(userID uint, message string) *models.Notification {
+		relatedType := "schedule"
+		return &models.Notification{
+			UserID:      userID,
+			Type:        "schedule",
+			Title:       "Upcoming session: " + schedule.Title,
+			Message:     message,
+			RelatedID:   &schedule.ID,
+			RelatedType: &relatedType,
+			Priority:    priority,
+			SentVia:     pq.StringArray{channelInApp},
+		}
+	}
+
+	notifications := []*models.Notification{
+		newReminder(schedule.Trainee.UserID, fmt.Sprintf("Your session with %s is on %s%s", schedule.Trainer.User.Name, when, where)),
+	}
+	if schedule.Trainer.UserID != 0 {
+		notifications = append(notifications,
+			newReminder(schedule.Trainer.UserID, fmt.Sprintf("Session with %s is on %s%s", schedule.Trainee.User.Name, when, where)))
+	}
+	return notifications
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"fitness-training-backend/internal/config"
+
+	"github.com/lib/pq"
+)
+
+func TestDueLead(t *testing.T) {
+	// Configured out of order on purpose
+	d := NewReminderDispatcher(nil, config.ReminderConfig{LeadTimes: []time.Duration{time.Hour, 24 * time.Hour}})
+
+	tests := []struct {
+		name        string
+		untilStart  time.Duration
+		alreadySent pq.Int64Array
+		lead        time.Duration
+		covered     []int64
+		ok          bool
+	}{
+		{"started", 0, nil, 0, nil, false},
+		{"in the past", -time.Minute, nil, 0, nil, false},
+		{"beyond the longest lead", 25 * time.Hour, nil, 0, nil, false},
+		{"exactly the longest lead", 24 * time.Hour, nil, 24 * time.Hour, []int64{1440}, true},
+		{"within the longest lead", 23 * time.Hour, nil, 24 * time.Hour, []int64{1440}, true},
+		{"longest already sent", 23 * time.Hour, pq.Int64Array{1440}, 0, nil, false},
+		{"exactly the shortest lead", time.Hour, pq.Int64Array{1440}, time.Hour, []int64{1440, 60}, true},
+		{"within the shortest lead", 30 * time.Minute, pq.Int64Array{1440}, time.Hour, []int64{1440, 60}, true},
+		// Booked at short notice: only the shortest reminder, covering the longer one
+		{"longer reminder never sent", 30 * time.Minute, nil, time.Hour, []int64{1440, 60}, true},
+		{"shortest already sent", 30 * time.Minute, pq.Int64Array{1440, 60}, 0, nil, false},
+		{"only the shortest recorded", 30 * time.Minute, pq.Int64Array{60}, 0, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lead, covered, ok := d.dueLead(tt.untilStart, tt.alreadySent)
+			if ok != tt.ok || lead != tt.lead || !reflect.DeepEqual(covered, tt.covered) {
+				t.Errorf("dueLead(%v, %v) = %v, %v, %v; want %v, %v, %v",
+					tt.untilStart, tt.alreadySent, lead, covered, ok, tt.lead, tt.covered, tt.ok)
+			}
+		})
+	}
+}
+
+func TestDueLeadWithoutLeadTimes(t *testing.T) {
+	d := NewReminderDispatcher(nil, config.ReminderConfig{})
+	if _, _, ok := d.dueLead(time.Minute, nil); ok {
+		t.Error("reminder due without lead times")
+	}
+}
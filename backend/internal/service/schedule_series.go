@@ -0,0 +1,552 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"fitness-training-backend/internal/database"
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+	apperrors "fitness-training-backend/pkg/errors"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultTimezone      = models.DefaultTimezone
+	maxSeriesOccurrences = 200
+)
+
+// Edit scopes for occurrences of a recurring series
+const (
+	scopeThis      = "this"
+	scopeFollowing = "following"
+	scopeAll       = "all"
+)
+
+// ==========================================
+// SCHEDULE SERIES
+// ==========================================
+
+func (s *trainerService) GetScheduleSeries(userID uint, status string) ([]dto.ScheduleSeriesResponse, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	series, err := s.seriesRepo.FindByTrainerID(trainer.ID, status)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]dto.ScheduleSeriesResponse, 0, len(series))
+	for i := range series {
+		resp = append(resp, toScheduleSeriesResponse(&series[i], nil))
+	}
+	return resp, nil
+}
+
+func (s *trainerService) GetScheduleSeriesDetail(userID, seriesID uint) (*dto.ScheduleSeriesResponse, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	series, err := s.getSeries(trainer, seriesID)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrences, err := s.scheduleRepo.FindBySeriesID(series.ID, nil)
+	if err != nil {
+		return nil, err
+	}
+	for i := range occurrences {
+		occurrences[i].Trainer = *trainer
+	}
+
+	resp := toScheduleSeriesResponse(series, occurrences)
+	return &resp, nil
+}
+
+// CreateScheduleSeries stores a recurring schedule and materialises its
+// occurrences. Nothing is written if any occurrence conflicts.
+func (s *trainerService) CreateScheduleSeries(userID uint, req *dto.CreateScheduleSeriesRequest) (*dto.ScheduleSeriesResponse, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getClient(trainer, req.TraineeID); err != nil {
+		return nil, err
+	}
+	if err := s.checkAssignment(trainer, req.ProgramAssignmentID, req.TraineeID); err != nil {
+		return nil, err
+	}
+
+	if err := validateTimeOfDay(req.Time); err != nil {
+		return nil, err
+	}
+
+	if (req.Count == nil) == (req.Until == nil) {
+		return nil, fmt.Errorf("%w: exactly one of count or until is required", apperrors.ErrInvalidInput)
+	}
+	if req.Count != nil && *req.Count > maxSeriesOccurrences {
+		return nil, fmt.Errorf("%w: a series can have at most %d occurrences", apperrors.ErrInvalidInput, maxSeriesOccurrences)
+	}
+
+	timezone := req.Timezone
+	if timezone == "" {
+		timezone = defaultTimezone
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown timezone %q", apperrors.ErrInvalidInput, timezone)
+	}
+
+	series := &models.ScheduleSeries{
+		TrainerID:           trainer.ID,
+		TraineeID:           req.TraineeID,
+		LocationID:          req.LocationID,
+		ProgramAssignmentID: req.ProgramAssignmentID,
+		Frequency:           req.Frequency,
+		Weekdays:            toWeekdayArray(req.Weekdays),
+		StartDate:           localDate(req.StartDate, loc),
+		Count:               req.Count,
+		Timezone:            timezone,
+		Time:                req.Time,
+		Duration:            req.Duration,
+		Title:               req.Title,
+		Description:         req.Description,
+		SessionType:         req.SessionType,
+		PlannedExercises:    pq.StringArray(req.PlannedExercises),
+		Status:              "active",
+	}
+	if req.Until != nil {
+		until := localDate(*req.Until, loc)
+		if until.Before(series.StartDate) {
+			return nil, fmt.Errorf("%w: until must not be before startDate", apperrors.ErrInvalidInput)
+		}
+		series.Until = &until
+	}
+
+	dates := expandSeries(series, loc)
+	if len(dates) == 0 {
+		return nil, fmt.Errorf("%w: the recurrence rule generates no occurrences", apperrors.ErrInvalidInput)
+	}
+	if len(dates) > maxSeriesOccurrences {
+		return nil, fmt.Errorf("%w: a series can have at most %d occurrences", apperrors.ErrInvalidInput, maxSeriesOccurrences)
+	}
+
+	err = database.Transaction(func(tx *gorm.DB) error {
+		if err := repository.NewScheduleSeriesRepository(tx).Create(series); err != nil {
+			return err
+		}
+		return createSeriesOccurrences(repository.NewScheduleRepository(tx), series, dates)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.traineeRepo.UpdateStats(req.TraineeID); err != nil {
+		return nil, err
+	}
+
+	return s.GetScheduleSeriesDetail(userID, series.ID)
+}
+
+// updateSeriesOccurrences applies an edit to "this and following" or "all"
+// pending occurrences of the schedule's series. Editing from a later
+// occurrence splits the series so the earlier part keeps its rule.
+func (s *trainerService) updateSeriesOccurrences(trainer *models.Trainer, schedule *models.Schedule, req *dto.UpdateScheduleRequest) error {
+	if req.Date != nil || req.Status != nil || req.Notes != nil {
+		return fmt.Errorf("%w: date, status and notes can only be changed for a single occurrence", apperrors.ErrInvalidInput)
+	}
+	if req.Time != nil {
+		if err := validateTimeOfDay(*req.Time); err != nil {
+			return err
+		}
+	}
+
+	series, err := s.getSeries(trainer, *schedule.SeriesID)
+	if err != nil {
+		return err
+	}
+
+	return database.Transaction(func(tx *gorm.DB) error {
+		seriesRepo := repository.NewScheduleSeriesRepository(tx)
+		scheduleRepo := repository.NewScheduleRepository(tx)
+
+		target := series
+		if req.Scope == scopeFollowing && schedule.OccurrenceDate.After(series.StartDate) {
+			split, err := splitSeries(seriesRepo, scheduleRepo, series, *schedule.OccurrenceDate)
+			if err != nil {
+				return err
+			}
+			target = split
+		}
+
+		reschedule := applySeriesTemplate(target, req)
+		if err := seriesRepo.Update(target); err != nil {
+			return err
+		}
+
+		occurrences, err := scheduleRepo.FindBySeriesID(target.ID, nil)
+		if err != nil {
+			return err
+		}
+
+		conflicts := make([]string, 0)
+		for i := range occurrences {
+			occurrence := &occurrences[i]
+			if !occurrence.CanBeCancelled() {
+				continue
+			}
+
+			columns := applyOccurrenceChanges(occurrence, req)
+			if len(columns) == 0 {
+				continue
+			}
+			if reschedule {
+				conflict, err := scheduleRepo.CheckConflict(trainer.ID, occurrence.Date, occurrence.Time, occurrence.Duration, &occurrence.ID)
+				if err != nil {
+					return err
+				}
+				if conflict {
+					conflicts = append(conflicts, occurrence.Date.Format("2006-01-02"))
+					continue
+				}
+			}
+
+			if err := scheduleRepo.UpdateColumns(occurrence, columns...); err != nil {
+				return err
+			}
+		}
+
+		return conflictDatesError(conflicts)
+	})
+}
+
+// cancelSeriesOccurrences cancels the pending occurrences of a series from the
+// schedule onwards (or all of them) and ends the recurrence there
+func (s *trainerService) cancelSeriesOccurrences(userID uint, trainer *models.Trainer, schedule *models.Schedule, req *dto.CancelScheduleRequest) error {
+	series, err := s.getSeries(trainer, *schedule.SeriesID)
+	if err != nil {
+		return err
+	}
+
+	var from *time.Time
+	if req.Scope == scopeFollowing && schedule.OccurrenceDate.After(series.StartDate) {
+		from = schedule.OccurrenceDate
+	}
+
+	return database.Transaction(func(tx *gorm.DB) error {
+		seriesRepo := repository.NewScheduleSeriesRepository(tx)
+		scheduleRepo := repository.NewScheduleRepository(tx)
+
+		occurrences, err := scheduleRepo.FindBySeriesID(series.ID, from)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for i := range occurrences {
+			occurrence := &occurrences[i]
+			if !occurrence.CanBeCancelled() {
+				continue
+			}
+
+			occurrence.Status = "cancelled"
+			occurrence.CancelledAt = &now
+			occurrence.CancelledBy = &userID
+			occurrence.CancellationReason = req.Reason
+			if err := scheduleRepo.UpdateColumns(occurrence, cancellationColumns...); err != nil {
+				return err
+			}
+		}
+
+		if from == nil {
+			series.Status = "cancelled"
+		} else if err := endSeriesBefore(scheduleRepo, series, *from); err != nil {
+			return err
+		}
+		if err := seriesRepo.Update(series); err != nil {
+			return err
+		}
+
+		return repository.NewTraineeRepository(tx).UpdateStats(schedule.TraineeID)
+	})
+}
+
+// getSeries loads a series owned by the trainer
+func (s *trainerService) getSeries(trainer *models.Trainer, seriesID uint) (*models.ScheduleSeries, error) {
+	series, err := s.seriesRepo.FindByID(seriesID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if series.TrainerID != trainer.ID {
+		return nil, apperrors.ErrForbidden
+	}
+	return series, nil
+}
+
+// ==========================================
+// RECURRENCE HELPERS
+// ==========================================
+
+// expandSeries lists the dates a series generates, in order. At most
+// maxSeriesOccurrences+1 dates are returned so callers can detect overflow.
+func expandSeries(series *models.ScheduleSeries, loc *time.Location) []time.Time {
+	interval := 1
+	if series.Frequency == "biweekly" {
+		interval = 2
+	}
+
+	// Offsets from Monday, so weeks line up with startOfWeek
+	offsets := make([]int, 0, len(series.Weekdays))
+	seen := map[int]bool{}
+	for _, day := range series.Weekdays {
+		offset := (int(day) + 6) % 7
+		if !seen[offset] {
+			seen[offset] = true
+			offsets = append(offsets, offset)
+		}
+	}
+	sort.Ints(offsets)
+	if len(offsets) == 0 {
+		return nil
+	}
+
+	start := localDate(series.StartDate, loc)
+	weekStart := startOfWeek(start)
+
+	dates := make([]time.Time, 0)
+	for week := 0; ; week += interval {
+		for _, offset := range offsets {
+			date := weekStart.AddDate(0, 0, week*7+offset)
+			if date.Before(start) {
+				continue
+			}
+			if series.Until != nil && date.After(localDate(*series.Until, loc)) {
+				return dates
+			}
+
+			dates = append(dates, date)
+			if series.Count != nil && len(dates) >= *series.Count {
+				return dates
+			}
+			if len(dates) > maxSeriesOccurrences {
+				return dates
+			}
+		}
+	}
+}
+
+// createSeriesOccurrences creates an occurrence on each date that is free.
+// Dates the trainer is already booked on are skipped and reported together,
+// so the caller's transaction can be rolled back with every conflict listed.
+func createSeriesOccurrences(scheduleRepo repository.ScheduleRepository, series *models.ScheduleSeries, dates []time.Time) error {
+	conflicts := make([]string, 0)
+	for _, date := range dates {
+		conflict, err := scheduleRepo.CheckConflict(series.TrainerID, date, series.Time, series.Duration, nil)
+		if err != nil {
+			return err
+		}
+		if conflict {
+			conflicts = append(conflicts, date.Format("2006-01-02"))
+			continue
+		}
+
+		if err := scheduleRepo.Create(newSeriesOccurrence(series, date)); err != nil {
+			return err
+		}
+	}
+
+	return conflictDatesError(conflicts)
+}
+
+// splitSeries moves the occurrences from a date onwards into a copy of the
+// series and ends the original series the day before
+func splitSeries(seriesRepo repository.ScheduleSeriesRepository, scheduleRepo repository.ScheduleRepository, series *models.ScheduleSeries, from time.Time) (*models.ScheduleSeries, error) {
+	next := &models.ScheduleSeries{
+		TrainerID:           series.TrainerID,
+		TraineeID:           series.TraineeID,
+		LocationID:          series.LocationID,
+		ProgramAssignmentID: series.ProgramAssignmentID,
+		Frequency:           series.Frequency,
+		Weekdays:            series.Weekdays,
+		StartDate:           from,
+		Until:               series.Until,
+		Timezone:            series.Timezone,
+		Time:                series.Time,
+		Duration:            series.Duration,
+		Title:               series.Title,
+		Description:         series.Description,
+		SessionType:         series.SessionType,
+		PlannedExercises:    series.PlannedExercises,
+		Status:              series.Status,
+	}
+
+	if series.Count != nil {
+		before, err := scheduleRepo.CountBySeriesBefore(series.ID, from)
+		if err != nil {
+			return nil, err
+		}
+		remaining := *series.Count - int(before)
+		next.Count = &remaining
+	}
+
+	if err := seriesRepo.Create(next); err != nil {
+		return nil, err
+	}
+	if err := scheduleRepo.MoveToSeries(series.ID, next.ID, from); err != nil {
+		return nil, err
+	}
+
+	if err := endSeriesBefore(scheduleRepo, series, from); err != nil {
+		return nil, err
+	}
+	if err := seriesRepo.Update(series); err != nil {
+		return nil, err
+	}
+
+	return next, nil
+}
+
+// endSeriesBefore rewrites the recurrence rule so it stops before a date
+func endSeriesBefore(scheduleRepo repository.ScheduleRepository, series *models.ScheduleSeries, from time.Time) error {
+	if series.Count != nil {
+		before, err := scheduleRepo.CountBySeriesBefore(series.ID, from)
+		if err != nil {
+			return err
+		}
+		count := int(before)
+		series.Count = &count
+		return nil
+	}
+
+	until := from.AddDate(0, 0, -1)
+	series.Until = &until
+	return nil
+}
+
+// applySeriesTemplate copies the edited fields into the series template and
+// reports whether the time slot changed
+func applySeriesTemplate(series *models.ScheduleSeries, req *dto.UpdateScheduleRequest) bool {
+	reschedule := false
+	if req.Time != nil {
+		series.Time = *req.Time
+		reschedule = true
+	}
+	if req.Duration != nil {
+		series.Duration = *req.Duration
+		reschedule = true
+	}
+	if req.LocationID != nil {
+		series.LocationID = req.LocationID
+	}
+	if req.Title != nil {
+		series.Title = *req.Title
+	}
+	if req.Description != nil {
+		series.Description = req.Description
+	}
+	if req.SessionType != nil {
+		series.SessionType = req.SessionType
+	}
+	if req.PlannedExercises != nil {
+		series.PlannedExercises = pq.StringArray(req.PlannedExercises)
+	}
+	return reschedule
+}
+
+// applyOccurrenceChanges copies the edited template fields into an occurrence
+// and returns the columns to write
+func applyOccurrenceChanges(schedule *models.Schedule, req *dto.UpdateScheduleRequest) []string {
+	columns := make([]string, 0)
+	if req.Time != nil {
+		schedule.Time = *req.Time
+		schedule.ResetReminders()
+		columns = append(columns, "time")
+		columns = append(columns, reminderColumns...)
+	}
+	if req.Duration != nil {
+		schedule.Duration = *req.Duration
+		columns = append(columns, "duration")
+	}
+	if req.LocationID != nil {
+		schedule.LocationID = req.LocationID
+		columns = append(columns, "location_id")
+	}
+	if req.Title != nil {
+		schedule.Title = *req.Title
+		columns = append(columns, "title")
+	}
+	if req.Description != nil {
+		schedule.Description = req.Description
+		columns = append(columns, "description")
+	}
+	if req.SessionType != nil {
+		schedule.SessionType = req.SessionType
+		columns = append(columns, "session_type")
+	}
+	if req.PlannedExercises != nil {
+		schedule.PlannedExercises = pq.StringArray(req.PlannedExercises)
+		columns = append(columns, "planned_exercises")
+	}
+	return columns
+}
+
+// newSeriesOccurrence builds the schedule a series generates on a date
+func newSeriesOccurrence(series *models.ScheduleSeries, date time.Time) *models.Schedule {
+	occurrenceDate := date
+	return &models.Schedule{
+		TrainerID:           series.TrainerID,
+		TraineeID:           series.TraineeID,
+		LocationID:          series.LocationID,
+		ProgramAssignmentID: series.ProgramAssignmentID,
+		SeriesID:            &series.ID,
+		OccurrenceDate:      &occurrenceDate,
+		Date:                date,
+		Time:                series.Time,
+		Duration:            series.Duration,
+		Title:               series.Title,
+		Description:         series.Description,
+		SessionType:         series.SessionType,
+		PlannedExercises:    series.PlannedExercises,
+		Status:              "scheduled",
+	}
+}
+
+// conflictDatesError reports the dates that clash with existing schedules
+func conflictDatesError(dates []string) error {
+	if len(dates) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: trainer is already booked on %s", apperrors.ErrScheduleConflict, strings.Join(dates, ", "))
+}
+
+// localDate returns midnight in loc of the calendar date written in t
+func localDate(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+func toWeekdayInts(days pq.Int64Array) []int {
+	weekdays := make([]int, 0, len(days))
+	for _, day := range days {
+		weekdays = append(weekdays, int(day))
+	}
+	return weekdays
+}
+
+func toWeekdayArray(days []int) pq.Int64Array {
+	weekdays := make(pq.Int64Array, 0, len(days))
+	for _, day := range days {
+		weekdays = append(weekdays, int64(day))
+	}
+	return weekdays
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+	apperrors "fitness-training-backend/pkg/errors"
+
+	"github.com/lib/pq"
+)
+
+func TestCreateScheduleSeriesRejectsForeignAssignment(t *testing.T) {
+	s := newAssignmentTestService(t)
+	own, other := uint(100), uint(200)
+	count := 4
+
+	tests := []struct {
+		name         string
+		traineeID    uint
+		assignmentID *uint
+		want         error
+	}{
+		{"another trainer's assignment", 5, &other, apperrors.ErrNotFound},
+		{"another client's assignment", 6, &own, apperrors.ErrInvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.CreateScheduleSeries(10, &dto.CreateScheduleSeriesRequest{
+				TraineeID:           tt.traineeID,
+				ProgramAssignmentID: tt.assignmentID,
+				Frequency:           "weekly",
+				Weekdays:            []int{1},
+				StartDate:           time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC),
+				Count:               &count,
+				Time:                "09:00",
+				Duration:            60,
+				Title:               "Leg day",
+			})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("CreateScheduleSeries = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandSeries(t *testing.T) {
+	bangkok, err := time.LoadLocation(defaultTimezone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2024, month, day, 0, 0, 0, 0, bangkok)
+	}
+	count := func(n int) *int { return &n }
+	until := func(month time.Month, day int) *time.Time {
+		d := date(month, day)
+		return &d
+	}
+
+	tests := []struct {
+		name      string
+		frequency string
+		weekdays  []int64
+		start     time.Time
+		count     *int
+		until     *time.Time
+		want      []string
+	}{
+		{name: "count", weekdays: []int64{1}, start: date(3, 4), count: count(3),
+			want: []string{"2024-03-04", "2024-03-11", "2024-03-18"}},
+		{name: "until is inclusive", weekdays: []int64{1}, start: date(3, 4), until: until(3, 18),
+			want: []string{"2024-03-04", "2024-03-11", "2024-03-18"}},
+		{name: "until mid-week", weekdays: []int64{1, 3}, start: date(3, 4), until: until(3, 12),
+			want: []string{"2024-03-04", "2024-03-06", "2024-03-11"}},
+		{name: "until before the first occurrence", weekdays: []int64{5}, start: date(3, 4), until: until(3, 7)},
+		{name: "no weekdays", start: date(3, 4), count: count(3)},
+
+		// Days of the start week before the start date are skipped
+		{name: "starting mid-week", weekdays: []int64{1, 3, 5}, start: date(3, 6), count: count(4),
+			want: []string{"2024-03-06", "2024-03-08", "2024-03-11", "2024-03-13"}},
+		{name: "biweekly", frequency: "biweekly", weekdays: []int64{1, 3}, start: date(3, 6), count: count(4),
+			want: []string{"2024-03-06", "2024-03-18", "2024-03-20", "2024-04-01"}},
+		// Weeks start on Monday, so Sunday comes last
+		{name: "sunday ends the week", weekdays: []int64{0, 1}, start: date(3, 4), count: count(3),
+			want: []string{"2024-03-04", "2024-03-10", "2024-03-11"}},
+		{name: "duplicate weekdays", weekdays: []int64{3, 3}, start: date(3, 6), count: count(2),
+			want: []string{"2024-03-06", "2024-03-13"}},
+
+		{name: "across a month end", weekdays: []int64{6}, start: date(1, 27), count: count(3),
+			want: []string{"2024-01-27", "2024-02-03", "2024-02-10"}},
+		{name: "leap day", weekdays: []int64{4}, start: date(2, 22), until: until(3, 7),
+			want: []string{"2024-02-22", "2024-02-29", "2024-03-07"}},
+		{name: "across a year end", weekdays: []int64{2}, start: date(12, 24), count: count(2),
+			want: []string{"2024-12-24", "2024-12-31"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frequency := tt.frequency
+			if frequency == "" {
+				frequency = "weekly"
+			}
+			series := &models.ScheduleSeries{
+				Frequency: frequency,
+				Weekdays:  pq.Int64Array(tt.weekdays),
+				StartDate: tt.start,
+				Count:     tt.count,
+				Until:     tt.until,
+			}
+
+			got := make([]string, 0)
+			for _, d := range expandSeries(series, bangkok) {
+				got = append(got, d.Format("2006-01-02"))
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("expandSeries = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandSeriesAcrossDSTChange(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := 3
+
+	// Clocks went forward on Sunday 10 March 2024
+	dates := expandSeries(&models.ScheduleSeries{
+		Frequency: "weekly",
+		Weekdays:  pq.Int64Array{1, 5},
+		StartDate: time.Date(2024, 3, 8, 0, 0, 0, 0, newYork),
+		Count:     &count,
+	}, newYork)
+
+	want := []time.Time{
+		time.Date(2024, 3, 8, 0, 0, 0, 0, newYork),
+		time.Date(2024, 3, 11, 0, 0, 0, 0, newYork),
+		time.Date(2024, 3, 15, 0, 0, 0, 0, newYork),
+	}
+	if len(dates) != len(want) {
+		t.Fatalf("expandSeries = %v, want %v", dates, want)
+	}
+	for i := range want {
+		if !dates[i].Equal(want[i]) {
+			t.Errorf("occurrence %d = %v, want local midnight %v", i, dates[i], want[i])
+		}
+	}
+}
+
+func TestExpandSeriesOverflow(t *testing.T) {
+	until := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	dates := expandSeries(&models.ScheduleSeries{
+		Frequency: "weekly",
+		Weekdays:  pq.Int64Array{1, 3, 5},
+		StartDate: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC),
+		Until:     &until,
+	}, time.UTC)
+
+	if len(dates) != maxSeriesOccurrences+1 {
+		t.Errorf("got %d dates, want %d to report the overflow", len(dates), maxSeriesOccurrences+1)
+	}
+}
+
+// fakeBookedSchedules reports the dates in booked as taken and records the
+// occurrences created
+type fakeBookedSchedules struct {
+	repository.ScheduleRepository
+	booked  map[string]bool
+	created []*models.Schedule
+}
+
+func (f *fakeBookedSchedules) CheckConflict(trainerID uint, date time.Time, timeStr string, duration int, excludeID *uint) (bool, error) {
+	return f.booked[date.Format("2006-01-02")], nil
+}
+
+func (f *fakeBookedSchedules) Create(schedule *models.Schedule) error {
+	f.created = append(f.created, schedule)
+	return nil
+}
+
+func TestCreateSeriesOccurrences(t *testing.T) {
+	series := &models.ScheduleSeries{ID: 9, TrainerID: 1, TraineeID: 5, Time: "09:00", Duration: 60, Title: "Leg day"}
+	dates := []time.Time{
+		time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 25, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name        string
+		booked      []string
+		wantCreated []string
+	}{
+		{"all free", nil, []string{"2024-03-04", "2024-03-11", "2024-03-18", "2024-03-25"}},
+		{"conflicts are skipped", []string{"2024-03-11", "2024-03-25"}, []string{"2024-03-04", "2024-03-18"}},
+		{"every date taken", []string{"2024-03-04", "2024-03-11", "2024-03-18", "2024-03-25"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedules := &fakeBookedSchedules{booked: map[string]bool{}}
+			for _, date := range tt.booked {
+				schedules.booked[date] = true
+			}
+
+			err := createSeriesOccurrences(schedules, series, dates)
+
+			created := make([]string, 0)
+			for _, schedule := range schedules.created {
+				created = append(created, schedule.Date.Format("2006-01-02"))
+				if schedule.SeriesID == nil || *schedule.SeriesID != series.ID || !schedule.OccurrenceDate.Equal(schedule.Date) {
+					t.Errorf("occurrence %+v is not linked to the series", schedule)
+				}
+			}
+			if strings.Join(created, " ") != strings.Join(tt.wantCreated, " ") {
+				t.Errorf("created %v, want %v", created, tt.wantCreated)
+			}
+
+			if len(tt.booked) == 0 {
+				if err != nil {
+					t.Errorf("createSeriesOccurrences = %v, want nil", err)
+				}
+				return
+			}
+			// Every conflict is reported, not only the first
+			if !errors.Is(err, apperrors.ErrScheduleConflict) || !strings.Contains(err.Error(), strings.Join(tt.booked, ", ")) {
+				t.Errorf("createSeriesOccurrences = %v, want a conflict on %v", err, tt.booked)
+			}
+		})
+	}
+}
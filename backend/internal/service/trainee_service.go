@@ -0,0 +1,448 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+	apperrors "fitness-training-backend/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+// TraineeService handles trainee (READ-ONLY) business logic
+type TraineeService interface {
+	// Schedules
+	GetUpcomingSchedules(userID uint, days int) ([]dto.ScheduleResponse, error)
+	GetSchedules(userID uint, filters map[string]interface{}) ([]dto.ScheduleResponse, error)
+	GetScheduleDetail(userID, scheduleID uint) (*dto.ScheduleResponse, error)
+
+	// Programs
+	GetCurrentProgram(userID uint) (*dto.ProgramResponse, error)
+	GetPrograms(userID uint) ([]dto.ProgramResponse, error)
+	GetProgramDetail(userID, programID uint) (*dto.ProgramResponse, error)
+
+	// Stats
+	GetStats(userID uint) (*dto.StatsResponse, error)
+
+	// Notifications
+	GetNotifications(userID uint, page, pageSize int) (*dto.PaginatedResponse, error)
+	MarkNotificationAsRead(userID, notificationID uint) error
+	MarkAllNotificationsAsRead(userID uint) error
+
+	// Session Cards
+	GetSessions(userID uint, page, pageSize int) (*dto.PaginatedResponse, error)
+	GetSessionDetail(userID, sessionID uint) (*dto.SessionCardResponse, error)
+	SearchSessions(userID uint, req *dto.SearchSessionsRequest) (*dto.PaginatedResponse, error)
+
+	// Metrics & Records
+	GetMetrics(userID uint, metricType *string) ([]dto.MetricResponse, error)
+	GetMetricAnalytics(userID uint, from, to *time.Time) (*dto.MetricAnalyticsResponse, error)
+	GetRecords(userID uint) ([]dto.ExerciseRecordsResponse, error)
+
+	// Profile
+	GetProfile(userID uint) (*dto.ProfileResponse, error)
+}
+
+type traineeService struct {
+	traineeRepo      repository.TraineeRepository
+	scheduleRepo     repository.ScheduleRepository
+	programRepo      repository.ProgramRepository
+	sessionCardRepo  repository.SessionCardRepository
+	notificationRepo repository.NotificationRepository
+	metricRepo       repository.MetricRepository
+	metricGoalRepo   repository.MetricGoalRepository
+	recordRepo       repository.PersonalRecordRepository
+	achievementRepo  repository.AchievementRepository
+}
+
+// NewTraineeService creates a new trainee service
+func NewTraineeService(
+	traineeRepo repository.TraineeRepository,
+	scheduleRepo repository.ScheduleRepository,
+	programRepo repository.ProgramRepository,
+	sessionCardRepo repository.SessionCardRepository,
+	notificationRepo repository.NotificationRepository,
+	metricRepo repository.MetricRepository,
+	metricGoalRepo repository.MetricGoalRepository,
+	recordRepo repository.PersonalRecordRepository,
+	achievementRepo repository.AchievementRepository,
+) TraineeService {
+	return &traineeService{
+		traineeRepo:      traineeRepo,
+		scheduleRepo:     scheduleRepo,
+		programRepo:      programRepo,
+		sessionCardRepo:  sessionCardRepo,
+		notificationRepo: notificationRepo,
+		metricRepo:       metricRepo,
+		metricGoalRepo:   metricGoalRepo,
+		recordRepo:       recordRepo,
+		achievementRepo:  achievementRepo,
+	}
+}
+
+// getTrainee resolves the trainee profile of the authenticated user
+func (s *traineeService) getTrainee(userID uint) (*models.Trainee, error) {
+	trainee, err := s.traineeRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return trainee, nil
+}
+
+// ==========================================
+// SCHEDULES
+// ==========================================
+
+func (s *traineeService) GetUpcomingSchedules(userID uint, days int) ([]dto.ScheduleResponse, error) {
+	trainee, err := s.getTrainee(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules, err := s.scheduleRepo.FindUpcoming(trainee.ID, days)
+	if err != nil {
+		return nil, err
+	}
+	return toScheduleResponses(schedules), nil
+}
+
+func (s *traineeService) GetSchedules(userID uint, filters map[string]interface{}) ([]dto.ScheduleResponse, error) {
+	trainee, err := s.getTrainee(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules, err := s.scheduleRepo.FindByTraineeID(trainee.ID, filters)
+	if err != nil {
+		return nil, err
+	}
+	return toScheduleResponses(schedules), nil
+}
+
+func (s *traineeService) GetScheduleDetail(userID, scheduleID uint) (*dto.ScheduleResponse, error) {
+	trainee, err := s.getTrainee(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := s.scheduleRepo.FindByID(scheduleID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if schedule.TraineeID != trainee.ID {
+		return nil, apperrors.ErrNotFound
+	}
+
+	resp := toScheduleResponse(schedule)
+	return &resp, nil
+}
+
+// ==========================================
+// PROGRAMS
+// ==========================================
+
+func (s *traineeService) GetCurrentProgram(userID uint) (*dto.ProgramResponse, error) {
+	trainee, err := s.getTrainee(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	assignment, err := s.programRepo.FindActiveAssignmentByTraineeID(trainee.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.ErrNoActiveProgram
+		}
+		return nil, err
+	}
+
+	resp := toAssignedProgramResponse(assignment)
+	return &resp, nil
+}
+
+func (s *traineeService) GetPrograms(userID uint) ([]dto.ProgramResponse, error) {
+	trainee, err := s.getTrainee(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments, err := s.programRepo.FindAssignmentsByTraineeID(trainee.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	programs := make([]dto.ProgramResponse, 0, len(assignments))
+	for i := range assignments {
+		programs = append(programs, toAssignedProgramResponse(&assignments[i]))
+	}
+	return programs, nil
+}
+
+// GetProgramDetail returns a program only if it has been assigned to the trainee
+func (s *traineeService) GetProgramDetail(userID, programID uint) (*dto.ProgramResponse, error) {
+	trainee, err := s.getTrainee(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments, err := s.programRepo.FindAssignmentsByTraineeID(trainee.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range assignments {
+		if assignments[i].ProgramID != programID {
+			continue
+		}
+
+		program, err := s.programRepo.FindByID(programID)
+		if err != nil {
+			return nil, notFound(err)
+		}
+
+		// Trainees see the version they were assigned
+		applyVersionContent(program, &assignments[i].ProgramVersion)
+		resp := toProgramResponse(program)
+		resp.Assignment = toProgramAssignmentResponse(&assignments[i])
+		return &resp, nil
+	}
+
+	return nil, apperrors.ErrNotFound
+}
+
+// ==========================================
+// STATS
+// ==========================================
+
+func (s *traineeService) GetStats(userID uint) (*dto.StatsResponse, error) {
+	trainee, err := s.getTrainee(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildStats(trainee)
+}
+
+func (s *traineeService) buildStats(trainee *models.Trainee) (*dto.StatsResponse, error) {
+	stats, err := s.traineeRepo.GetStats(trainee.ID)
+	if err != nil {
+		return nil, err
+	}
+	// Streaks depend on today, not only on the sessions behind the cache
+	summary, err := s.traineeRepo.ComputeStats(trainee.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.StatsResponse{
+		TotalSessions:     trainee.TotalSessions,
+		CompletedSessions: summary.TotalSessions,
+		CancelledSessions: trainee.CancelledSessions,
+		CurrentStreak:     summary.CurrentDailyStreak,
+		LongestStreak:     summary.LongestDailyStreak,
+		TotalWorkoutHours: trainee.TotalWorkoutHours,
+		LastSessionDate:   summary.LastSessionDate,
+
+		WeeklyStreakTarget:     trainee.WeeklyStreakTarget,
+		CurrentWeeklyStreak:    summary.CurrentWeeklyStreak,
+		LongestWeeklyStreak:    summary.LongestWeeklyStreak,
+		AverageSessionsPerWeek: roundUnitValue(summary.AverageSessionsPerWeek),
+	}
+	if upcoming, ok := stats["upcomingSessions"].(int64); ok {
+		resp.UpcomingSessions = int(upcoming)
+	}
+
+	achievements, err := s.achievementRepo.FindByTraineeID(trainee.ID, recentAchievementCount)
+	if err != nil {
+		return nil, err
+	}
+	resp.RecentAchievements = toAchievementResponses(achievements)
+
+	assignment, err := s.programRepo.FindActiveAssignmentByTraineeID(trainee.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		applyVersionContent(&assignment.Program, &assignment.ProgramVersion)
+		resp.CurrentProgram = &struct {
+			ID                 uint    `json:"id"`
+			Name               string  `json:"name"`
+			ProgressPercentage float32 `json:"progressPercentage"`
+			CurrentWeek        int     `json:"currentWeek"`
+			TotalWeeks         int     `json:"totalWeeks"`
+		}{
+			ID:                 assignment.Program.ID,
+			Name:               assignment.Program.Name,
+			ProgressPercentage: assignment.ProgressPercentage,
+			CurrentWeek:        assignment.CurrentWeek,
+			TotalWeeks:         assignment.Program.TotalWeeks,
+		}
+	}
+
+	return resp, nil
+}
+
+// ==========================================
+// NOTIFICATIONS
+// ==========================================
+
+func (s *traineeService) GetNotifications(userID uint, page, pageSize int) (*dto.PaginatedResponse, error) {
+	notifications, total, err := s.notificationRepo.FindByUserID(userID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]dto.NotificationResponse, 0, len(notifications))
+	for i := range notifications {
+		data = append(data, toNotificationResponse(&notifications[i]))
+	}
+	return newPaginatedResponse(data, page, pageSize, total), nil
+}
+
+func (s *traineeService) MarkNotificationAsRead(userID, notificationID uint) error {
+	notification, err := s.notificationRepo.FindByID(notificationID)
+	if err != nil {
+		return notFound(err)
+	}
+	if notification.UserID != userID {
+		return apperrors.ErrNotFound
+	}
+	return s.notificationRepo.MarkAsRead(notificationID)
+}
+
+func (s *traineeService) MarkAllNotificationsAsRead(userID uint) error {
+	return s.notificationRepo.MarkAllAsRead(userID)
+}
+
+// ==========================================
+// SESSION CARDS
+// ==========================================
+
+func (s *traineeService) GetSessions(userID uint, page, pageSize int) (*dto.PaginatedResponse, error) {
+	trainee, err := s.getTrainee(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	cards, total, err := s.sessionCardRepo.FindByTraineeID(trainee.ID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return newPaginatedResponse(toSessionCardResponses(cards, trainee.User.Units()), page, pageSize, total), nil
+}
+
+func (s *traineeService) GetSessionDetail(userID, sessionID uint) (*dto.SessionCardResponse, error) {
+	trainee, err := s.getTrainee(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	card, err := s.sessionCardRepo.FindByID(sessionID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if card.TraineeID != trainee.ID {
+		return nil, apperrors.ErrNotFound
+	}
+
+	resp := toSessionCardResponse(card, trainee.User.Units())
+	return &resp, nil
+}
+
+func (s *traineeService) SearchSessions(userID uint, req *dto.SearchSessionsRequest) (*dto.PaginatedResponse, error) {
+	trainee, err := s.getTrainee(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := map[string]interface{}{}
+	if req.FromDate != nil {
+		filters["fromDate"] = *req.FromDate
+	}
+	if req.ToDate != nil {
+		filters["toDate"] = *req.ToDate
+	}
+	if req.Category != nil {
+		filters["category"] = *req.Category
+	}
+	if req.ExerciseName != nil {
+		filters["exerciseName"] = *req.ExerciseName
+	}
+
+	cards, err := s.sessionCardRepo.Search(trainee.ID, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	total := int64(len(cards))
+	start := (req.Page - 1) * req.PageSize
+	if start > len(cards) {
+		start = len(cards)
+	}
+	end := start + req.PageSize
+	if end > len(cards) {
+		end = len(cards)
+	}
+
+	return newPaginatedResponse(toSessionCardResponses(cards[start:end], trainee.User.Units()), req.Page, req.PageSize, total), nil
+}
+
+// ==========================================
+// METRICS & PROFILE
+// ==========================================
+
+func (s *traineeService) GetMetrics(userID uint, metricType *string) ([]dto.MetricResponse, error) {
+	trainee, err := s.getTrainee(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics, err := s.metricRepo.FindByTraineeID(trainee.ID, metricType)
+	if err != nil {
+		return nil, err
+	}
+	return toMetricResponses(metrics, trainee.User.Units()), nil
+}
+
+// GetMetricAnalytics returns the trainee's metric trends between from and to,
+// with the goals their trainer set
+func (s *traineeService) GetMetricAnalytics(userID uint, from, to *time.Time) (*dto.MetricAnalyticsResponse, error) {
+	trainee, err := s.getTrainee(userID)
+	if err != nil {
+		return nil, err
+	}
+	return loadMetricAnalytics(s.metricRepo, s.metricGoalRepo, trainee, from, to, trainee.User.Units())
+}
+
+// GetRecords lists the trainee's current personal records per exercise
+func (s *traineeService) GetRecords(userID uint) ([]dto.ExerciseRecordsResponse, error) {
+	trainee, err := s.getTrainee(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := s.recordRepo.FindByTraineeID(trainee.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toPersonalRecordResponses(records, trainee.User.Units()), nil
+}
+
+func (s *traineeService) GetProfile(userID uint) (*dto.ProfileResponse, error) {
+	trainee, err := s.getTrainee(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.buildStats(trainee)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ProfileResponse{
+		User:    *toUserInfo(&trainee.User),
+		Trainee: *toTraineeInfo(trainee),
+		Stats:   *stats,
+	}, nil
+}
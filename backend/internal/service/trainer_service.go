@@ -0,0 +1,2117 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"fitness-training-backend/internal/database"
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+	apperrors "fitness-training-backend/pkg/errors"
+	"fitness-training-backend/pkg/units"
+	"fitness-training-backend/pkg/utils"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// TrainerService handles trainer (FULL CRUD) business logic
+type TrainerService interface {
+	// Dashboard
+	GetDashboardStats(userID uint) (*dto.DashboardStatsResponse, error)
+
+	// Clients
+	GetClients(userID uint) ([]dto.UserInfo, error)
+	GetClientDetail(userID, traineeID uint) (*dto.ClientDetailResponse, error)
+	AddClient(userID uint, req *dto.CreateClientRequest) (*dto.UserInfo, error)
+	UpdateClient(userID, traineeID uint, req *dto.UpdateClientRequest) (*dto.UserInfo, error)
+	RemoveClient(userID, traineeID uint) error
+	GetClientMetrics(userID, traineeID uint, metricType *string) ([]dto.MetricResponse, error)
+	AddClientMetric(userID, traineeID uint, req *dto.CreateMetricRequest) (*dto.MetricResponse, error)
+	GetClientMetricAnalytics(userID, traineeID uint, from, to *time.Time) (*dto.MetricAnalyticsResponse, error)
+	SetClientMetricGoal(userID, traineeID uint, req *dto.SetMetricGoalRequest) (*dto.MetricGoalResponse, error)
+	DeleteClientMetricGoal(userID, traineeID, goalID uint) error
+	GetClientSessions(userID, traineeID uint, page, pageSize int) (*dto.PaginatedResponse, error)
+	GetClientRecords(userID, traineeID uint) ([]dto.ExerciseRecordsResponse, error)
+	PreviewMetricImport(userID uint, file io.Reader, mapping *dto.MetricImportMapping) (*dto.MetricImportResponse, error)
+	CommitMetricImport(userID uint, file io.Reader, mapping *dto.MetricImportMapping) (*dto.MetricImportResponse, error)
+
+	// Schedules
+	GetSchedules(userID uint, filters map[string]interface{}) ([]dto.ScheduleResponse, error)
+	GetScheduleDetail(userID, scheduleID uint) (*dto.ScheduleResponse, error)
+	CreateSchedule(userID uint, req *dto.CreateScheduleRequest) (*dto.ScheduleResponse, error)
+	UpdateSchedule(userID, scheduleID uint, req *dto.UpdateScheduleRequest) (*dto.ScheduleResponse, error)
+	CancelSchedule(userID, scheduleID uint, req *dto.CancelScheduleRequest) error
+
+	// Recurring schedules
+	GetScheduleSeries(userID uint, status string) ([]dto.ScheduleSeriesResponse, error)
+	GetScheduleSeriesDetail(userID, seriesID uint) (*dto.ScheduleSeriesResponse, error)
+	CreateScheduleSeries(userID uint, req *dto.CreateScheduleSeriesRequest) (*dto.ScheduleSeriesResponse, error)
+
+	// Session Cards
+	GetSessions(userID uint, page, pageSize int) (*dto.PaginatedResponse, error)
+	GetSessionDetail(userID, sessionID uint) (*dto.SessionCardResponse, error)
+	CreateSessionCard(userID uint, req *dto.CreateSessionCardRequest) (*dto.SessionCardResponse, error)
+	UpdateSessionCard(userID, sessionID uint, req *dto.UpdateSessionCardRequest) (*dto.SessionCardResponse, error)
+	DeleteSessionCard(userID, sessionID uint) error
+
+	// Programs
+	GetPrograms(userID uint) ([]dto.TrainerProgramResponse, error)
+	GetProgramDetail(userID, programID uint) (*dto.TrainerProgramResponse, error)
+	CreateProgram(userID uint, req *dto.CreateProgramRequest) (*dto.TrainerProgramResponse, error)
+	UpdateProgram(userID, programID uint, req *dto.UpdateProgramRequest) (*dto.TrainerProgramResponse, error)
+	DeleteProgram(userID, programID uint) error
+	AssignProgram(userID, programID uint, req *dto.AssignProgramRequest) (*dto.ProgramAssignmentResponse, error)
+
+	// Exercise Library
+	GetExercises(userID uint, filters map[string]interface{}, sort string, page, pageSize int) (*dto.ExerciseSearchResponse, error)
+	CreateExercise(userID uint, req *dto.CreateExerciseRequest) (*dto.ExerciseLibraryResponse, error)
+	UpdateExercise(userID, exerciseID uint, req *dto.UpdateExerciseRequest) (*dto.ExerciseLibraryResponse, error)
+	DeleteExercise(userID, exerciseID uint) error
+	GetExerciseCategories() ([]dto.ExerciseCategoryResponse, error)
+
+	// Analytics
+	GetAnalyticsOverview(userID uint) (*dto.AnalyticsOverviewResponse, error)
+	GetClientAnalytics(userID, traineeID uint) (*dto.ClientAnalyticsResponse, error)
+
+	// Public browsing
+	GetTrainers(filters map[string]interface{}) ([]dto.TrainerPublicResponse, error)
+	GetTrainerDetail(trainerID uint) (*dto.TrainerPublicResponse, error)
+}
+
+type trainerService struct {
+	trainerRepo     repository.TrainerRepository
+	traineeRepo     repository.TraineeRepository
+	scheduleRepo    repository.ScheduleRepository
+	seriesRepo      repository.ScheduleSeriesRepository
+	programRepo     repository.ProgramRepository
+	sessionCardRepo repository.SessionCardRepository
+	metricRepo      repository.MetricRepository
+	metricGoalRepo  repository.MetricGoalRepository
+	exerciseRepo    repository.ExerciseRepository
+	recordRepo      repository.PersonalRecordRepository
+}
+
+// NewTrainerService creates a new trainer service
+func NewTrainerService(
+	trainerRepo repository.TrainerRepository,
+	traineeRepo repository.TraineeRepository,
+	scheduleRepo repository.ScheduleRepository,
+	seriesRepo repository.ScheduleSeriesRepository,
+	programRepo repository.ProgramRepository,
+	sessionCardRepo repository.SessionCardRepository,
+	metricRepo repository.MetricRepository,
+	metricGoalRepo repository.MetricGoalRepository,
+	exerciseRepo repository.ExerciseRepository,
+	recordRepo repository.PersonalRecordRepository,
+) TrainerService {
+	return &trainerService{
+		trainerRepo:     trainerRepo,
+		traineeRepo:     traineeRepo,
+		scheduleRepo:    scheduleRepo,
+		seriesRepo:      seriesRepo,
+		programRepo:     programRepo,
+		sessionCardRepo: sessionCardRepo,
+		metricRepo:      metricRepo,
+		metricGoalRepo:  metricGoalRepo,
+		exerciseRepo:    exerciseRepo,
+		recordRepo:      recordRepo,
+	}
+}
+
+// getTrainer resolves the trainer profile of the authenticated user
+func (s *trainerService) getTrainer(userID uint) (*models.Trainer, error) {
+	trainer, err := s.trainerRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return trainer, nil
+}
+
+// getClient loads a trainee and verifies it is assigned to the trainer
+func (s *trainerService) getClient(trainer *models.Trainer, traineeID uint) (*models.Trainee, error) {
+	trainee, err := s.traineeRepo.FindByID(traineeID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if trainee.TrainerID == nil || *trainee.TrainerID != trainer.ID {
+		return nil, apperrors.ErrClientNotAssigned
+	}
+	return trainee, nil
+}
+
+// getSchedule loads a schedule owned by the trainer
+func (s *trainerService) getSchedule(trainer *models.Trainer, scheduleID uint) (*models.Schedule, error) {
+	schedule, err := s.scheduleRepo.FindByID(scheduleID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if schedule.TrainerID != trainer.ID {
+		return nil, apperrors.ErrNotFound
+	}
+	return schedule, nil
+}
+
+// getSessionCard loads a session card owned by the trainer
+func (s *trainerService) getSessionCard(trainer *models.Trainer, sessionID uint) (*models.SessionCard, error) {
+	card, err := s.sessionCardRepo.FindByID(sessionID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if card.TrainerID != trainer.ID {
+		return nil, apperrors.ErrNotFound
+	}
+	return card, nil
+}
+
+// getProgram loads a program owned by the trainer
+func (s *trainerService) getProgram(trainer *models.Trainer, programID uint) (*models.Program, error) {
+	program, err := s.programRepo.FindByID(programID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if program.TrainerID != trainer.ID {
+		return nil, apperrors.ErrNotFound
+	}
+	return program, nil
+}
+
+// checkAssignment verifies that the program assignment a schedule is linked
+// to belongs to one of the trainer's programs and to the schedule's trainee:
+// completing the schedule counts towards the assignment's progress
+func (s *trainerService) checkAssignment(trainer *models.Trainer, assignmentID *uint, traineeID uint) error {
+	if assignmentID == nil {
+		return nil
+	}
+	assignment, err := s.programRepo.FindAssignmentByID(*assignmentID)
+	if err != nil {
+		return notFound(err)
+	}
+	if assignment.Program.TrainerID != trainer.ID {
+		return apperrors.ErrNotFound
+	}
+	if assignment.TraineeID != traineeID {
+		return fmt.Errorf("%w: the program assignment is for another client", apperrors.ErrInvalidInput)
+	}
+	return nil
+}
+
+// getOwnExercise loads a private exercise owned by the trainer
+func (s *trainerService) getOwnExercise(trainer *models.Trainer, exerciseID uint) (*models.ExerciseLibrary, error) {
+	exercise, err := s.exerciseRepo.FindByID(exerciseID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if exercise.TrainerID == nil || *exercise.TrainerID != trainer.ID {
+		return nil, apperrors.ErrForbidden
+	}
+	return exercise, nil
+}
+
+// ==========================================
+// DASHBOARD
+// ==========================================
+
+func (s *trainerService) GetDashboardStats(userID uint) (*dto.DashboardStatsResponse, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	clients, err := s.trainerRepo.GetClients(trainer.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules, err := s.scheduleRepo.FindByTrainerID(trainer.ID, map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.DashboardStatsResponse{
+		TotalClients:  len(clients),
+		AverageRating: trainer.Rating,
+		TodaySessions: []dto.ScheduleResponse{},
+		WeekSessions:  []dto.ScheduleResponse{},
+	}
+
+	for _, client := range clients {
+		if client.Status == "active" {
+			resp.ActiveClients++
+		}
+	}
+
+	today := truncateDate(time.Now())
+	weekEnd := today.AddDate(0, 0, 7)
+
+	for i := range schedules {
+		schedule := &schedules[i]
+		schedule.Trainer = *trainer
+		date := truncateDate(schedule.Date)
+
+		resp.TotalSessions++
+		switch schedule.Status {
+		case "completed":
+			resp.CompletedSessions++
+		case "scheduled", "confirmed":
+			if !date.Before(today) {
+				resp.UpcomingSessions++
+				if date.Equal(today) {
+					resp.TodaySessions = append(resp.TodaySessions, toScheduleResponse(schedule))
+				}
+				if date.Before(weekEnd) {
+					resp.WeekSessions = append(resp.WeekSessions, toScheduleResponse(schedule))
+				}
+			}
+		}
+	}
+
+	// Most recently trained clients first
+	sort.SliceStable(clients, func(i, j int) bool {
+		a, b := clients[i].LastSessionDate, clients[j].LastSessionDate
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return a.After(*b)
+	})
+
+	for i, client := range clients {
+		if i == 5 {
+			break
+		}
+		resp.RecentClients = append(resp.RecentClients, struct {
+			ID           uint       `json:"id"`
+			Name         string     `json:"name"`
+			ProfileImage *string    `json:"profileImage"`
+			LastSession  *time.Time `json:"lastSession"`
+		}{
+			ID:           client.ID,
+			Name:         client.User.Name,
+			ProfileImage: client.User.ProfileImage,
+			LastSession:  client.LastSessionDate,
+		})
+	}
+
+	return resp, nil
+}
+
+// ==========================================
+// CLIENTS
+// ==========================================
+
+func (s *trainerService) GetClients(userID uint) ([]dto.UserInfo, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	clients, err := s.trainerRepo.GetClients(trainer.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]dto.UserInfo, 0, len(clients))
+	for i := range clients {
+		resp = append(resp, toClientInfo(&clients[i]))
+	}
+	return resp, nil
+}
+
+func (s *trainerService) GetClientDetail(userID, traineeID uint) (*dto.ClientDetailResponse, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	trainee, err := s.getClient(trainer, traineeID)
+	if err != nil {
+		return nil, err
+	}
+
+	client := toClientInfo(trainee)
+	resp := &dto.ClientDetailResponse{
+		User:    client,
+		Trainee: *client.Trainee,
+	}
+
+	assignment, err := s.programRepo.FindActiveAssignmentByTraineeID(trainee.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		resp.CurrentProgram = toProgramAssignmentResponse(assignment)
+	}
+
+	cards, _, err := s.sessionCardRepo.FindByTraineeID(trainee.ID, 5, 0)
+	if err != nil {
+		return nil, err
+	}
+	resp.RecentSessions = toSessionCardResponses(cards, trainer.User.Units())
+
+	metrics, err := s.metricRepo.FindByTraineeID(trainee.ID, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.LatestMetrics = toMetricResponses(latestMetricPerType(metrics), trainer.User.Units())
+
+	upcoming, err := s.scheduleRepo.FindUpcoming(trainee.ID, 14)
+	if err != nil {
+		return nil, err
+	}
+	resp.UpcomingSchedules = toScheduleResponses(upcoming)
+
+	return resp, nil
+}
+
+// AddClient creates a trainee account assigned to the trainer
+func (s *trainerService) AddClient(userID uint, req *dto.CreateClientRequest) (*dto.UserInfo, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := utils.ValidatePassword(req.Password); err != nil {
+		return nil, err
+	}
+
+	hash, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		Email:        strings.ToLower(strings.TrimSpace(req.Email)),
+		PasswordHash: &hash,
+		Name:         req.Name,
+		Role:         "trainee",
+		PhoneNumber:  req.PhoneNumber,
+		DateOfBirth:  req.DateOfBirth,
+		Gender:       req.Gender,
+		IsActive:     true,
+	}
+
+	trainee := &models.Trainee{
+		TrainerID:                    &trainer.ID,
+		MemberID:                     normalizeMemberID(req.MemberID),
+		Goals:                        pq.StringArray(req.Goals),
+		FitnessLevel:                 req.FitnessLevel,
+		MedicalNotes:                 req.MedicalNotes,
+		Injuries:                     pq.StringArray(req.Injuries),
+		Allergies:                    pq.StringArray(req.Allergies),
+		EmergencyContactName:         req.EmergencyContactName,
+		EmergencyContactPhone:        req.EmergencyContactPhone,
+		EmergencyContactRelationship: req.EmergencyContactRelationship,
+		Status:                       "active",
+	}
+	if req.Height != nil {
+		trainee.Height = *req.Height
+	}
+	if req.Weight != nil {
+		trainee.Weight = *req.Weight
+	}
+
+	err = database.Transaction(func(tx *gorm.DB) error {
+		userRepo := repository.NewUserRepository(tx)
+
+		if _, err := userRepo.FindByEmail(user.Email); err == nil {
+			return apperrors.ErrEmailAlreadyExists
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		traineeRepo := repository.NewTraineeRepository(tx)
+		if err := checkMemberID(traineeRepo, trainee); err != nil {
+			return err
+		}
+
+		if err := userRepo.Create(user); err != nil {
+			return err
+		}
+
+		trainee.UserID = user.ID
+		if err := traineeRepo.Create(trainee); err != nil {
+			return err
+		}
+
+		return repository.NewTrainerRepository(tx).RefreshClientCount(trainer.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.traineeRepo.FindByID(trainee.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	info := toClientInfo(created)
+	return &info, nil
+}
+
+func (s *trainerService) UpdateClient(userID, traineeID uint, req *dto.UpdateClientRequest) (*dto.UserInfo, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	trainee, err := s.getClient(trainer, traineeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.MemberID != nil {
+		trainee.MemberID = normalizeMemberID(req.MemberID)
+	}
+	if req.Height != nil {
+		trainee.Height = *req.Height
+	}
+	if req.Weight != nil {
+		trainee.Weight = *req.Weight
+	}
+	if req.Goals != nil {
+		trainee.Goals = pq.StringArray(req.Goals)
+	}
+	if req.FitnessLevel != nil {
+		trainee.FitnessLevel = req.FitnessLevel
+	}
+	if req.MedicalNotes != nil {
+		trainee.MedicalNotes = req.MedicalNotes
+	}
+	if req.Injuries != nil {
+		trainee.Injuries = pq.StringArray(req.Injuries)
+	}
+	if req.Allergies != nil {
+		trainee.Allergies = pq.StringArray(req.Allergies)
+	}
+	if req.EmergencyContactName != nil {
+		trainee.EmergencyContactName = req.EmergencyContactName
+	}
+	if req.EmergencyContactPhone != nil {
+		trainee.EmergencyContactPhone = req.EmergencyContactPhone
+	}
+	if req.EmergencyContactRelationship != nil {
+		trainee.EmergencyContactRelationship = req.EmergencyContactRelationship
+	}
+	if req.WeeklyStreakTarget != nil {
+		trainee.WeeklyStreakTarget = *req.WeeklyStreakTarget
+	}
+	if req.PreferredWeekdays != nil {
+		trainee.PreferredWeekdays = toWeekdayArray(req.PreferredWeekdays)
+	}
+	if req.PreferredTime != nil {
+		trainee.PreferredTime = nil
+		if *req.PreferredTime != "" {
+			if err := validateTimeOfDay(*req.PreferredTime); err != nil {
+				return nil, err
+			}
+			trainee.PreferredTime = req.PreferredTime
+		}
+	}
+
+	user := trainee.User
+	if req.PhoneNumber != nil {
+		user.PhoneNumber = req.PhoneNumber
+	}
+	if req.DateOfBirth != nil {
+		user.DateOfBirth = req.DateOfBirth
+	}
+	if req.Gender != nil {
+		user.Gender = req.Gender
+	}
+
+	err = database.Transaction(func(tx *gorm.DB) error {
+		traineeRepo := repository.NewTraineeRepository(tx)
+		if err := checkMemberID(traineeRepo, trainee); err != nil {
+			return err
+		}
+		if err := traineeRepo.Update(trainee); err != nil {
+			return err
+		}
+		// Save wrote the cached stats we loaded; recompute them (the weekly
+		// target may also have changed)
+		if err := traineeRepo.UpdateStats(trainee.ID); err != nil {
+			return err
+		}
+		return repository.NewUserRepository(tx).Update(&user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	trainee.User = user
+	info := toClientInfo(trainee)
+	return &info, nil
+}
+
+// normalizeMemberID trims a membership number; blank ones are removed
+func normalizeMemberID(memberID *string) *string {
+	if memberID == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*memberID)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+// checkMemberID makes sure no other trainee has the trainee's membership
+// number
+func checkMemberID(traineeRepo repository.TraineeRepository, trainee *models.Trainee) error {
+	if trainee.MemberID == nil {
+		return nil
+	}
+	existing, err := traineeRepo.FindByMemberID(*trainee.MemberID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID != trainee.ID {
+		return fmt.Errorf("%w: member ID %s belongs to another trainee", apperrors.ErrAlreadyExists, *trainee.MemberID)
+	}
+	return nil
+}
+
+// RemoveClient unassigns the trainee from the trainer; the account is kept
+func (s *trainerService) RemoveClient(userID, traineeID uint) error {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return err
+	}
+
+	trainee, err := s.getClient(trainer, traineeID)
+	if err != nil {
+		return err
+	}
+
+	trainee.TrainerID = nil
+	trainee.Trainer = nil
+
+	return database.Transaction(func(tx *gorm.DB) error {
+		if err := repository.NewTraineeRepository(tx).Update(trainee); err != nil {
+			return err
+		}
+		return repository.NewTrainerRepository(tx).RefreshClientCount(trainer.ID)
+	})
+}
+
+func (s *trainerService) GetClientMetrics(userID, traineeID uint, metricType *string) ([]dto.MetricResponse, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getClient(trainer, traineeID); err != nil {
+		return nil, err
+	}
+
+	metrics, err := s.metricRepo.FindByTraineeID(traineeID, metricType)
+	if err != nil {
+		return nil, err
+	}
+	return toMetricResponses(metrics, trainer.User.Units()), nil
+}
+
+func (s *trainerService) AddClientMetric(userID, traineeID uint, req *dto.CreateMetricRequest) (*dto.MetricResponse, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getClient(trainer, traineeID); err != nil {
+		return nil, err
+	}
+
+	if req.Type == "measurement" && (req.MeasurementType == nil || *req.MeasurementType == "") {
+		return nil, fmt.Errorf("%w: measurementType is required for measurements", apperrors.ErrMissingField)
+	}
+	// Metrics are stored in the canonical unit of their quantity
+	unit, err := parseMetricUnit(req.Type, req.Unit)
+	if err != nil {
+		return nil, err
+	}
+
+	metric := &models.Metric{
+		TraineeID:       traineeID,
+		Date:            req.Date,
+		Type:            req.Type,
+		Value:           canonicalValue(req.Value, unit),
+		Unit:            string(units.Canonical(unit.Kind())),
+		MeasurementType: req.MeasurementType,
+		Notes:           req.Notes,
+		RecordedBy:      &userID,
+	}
+	err = database.Transaction(func(tx *gorm.DB) error {
+		if err := repository.NewMetricRepository(tx).Create(metric); err != nil {
+			return err
+		}
+		return evaluateAchievements(tx, traineeID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metric.Recorder = &trainer.User
+	resp := toMetricResponse(metric, trainer.User.Units())
+	return &resp, nil
+}
+
+// GetClientMetricAnalytics returns a client's metric trends between from and
+// to, with their goals
+func (s *trainerService) GetClientMetricAnalytics(userID, traineeID uint, from, to *time.Time) (*dto.MetricAnalyticsResponse, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	trainee, err := s.getClient(trainer, traineeID)
+	if err != nil {
+		return nil, err
+	}
+	return loadMetricAnalytics(s.metricRepo, s.metricGoalRepo, trainee, from, to, trainer.User.Units())
+}
+
+// SetClientMetricGoal creates a client's goal for a metric, or replaces the
+// goal they have for it, and returns its progress
+func (s *trainerService) SetClientMetricGoal(userID, traineeID uint, req *dto.SetMetricGoalRequest) (*dto.MetricGoalResponse, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	trainee, err := s.getClient(trainer, traineeID)
+	if err != nil {
+		return nil, err
+	}
+
+	measurementType := ""
+	if req.Type == metricTypeMeasurement {
+		measurementType = normalizeMeasurementType(req.MeasurementType)
+		if measurementType == "" {
+			return nil, fmt.Errorf("%w: measurementType is required for measurements", apperrors.ErrMissingField)
+		}
+	}
+	if req.Type == metricTypeBodyFat && req.TargetValue >= 100 {
+		return nil, fmt.Errorf("%w: a body fat target must be below 100%%", apperrors.ErrInvalidInput)
+	}
+
+	// Targets default to the trainer's unit and are stored canonically
+	targetValue := req.TargetValue
+	if kind := metricKind(req.Type); kind != "" {
+		unit := trainer.User.Units().For(kind)
+		if req.Unit != nil {
+			if unit, err = parseMetricUnit(req.Type, *req.Unit); err != nil {
+				return nil, err
+			}
+		}
+		targetValue = canonicalValue(targetValue, unit)
+	}
+
+	goal := &models.MetricGoal{
+		TraineeID:       trainee.ID,
+		Type:            req.Type,
+		MeasurementType: measurementType,
+		TargetValue:     targetValue,
+		TargetDate:      req.TargetDate,
+		Notes:           req.Notes,
+		SetBy:           &userID,
+	}
+	if err := s.metricGoalRepo.Save(goal); err != nil {
+		return nil, err
+	}
+
+	analytics, err := loadMetricAnalytics(s.metricRepo, s.metricGoalRepo, trainee, nil, nil, trainer.User.Units())
+	if err != nil {
+		return nil, err
+	}
+	for i := range analytics.Goals {
+		if analytics.Goals[i].ID == goal.ID {
+			return &analytics.Goals[i], nil
+		}
+	}
+	return nil, apperrors.ErrNotFound
+}
+
+// DeleteClientMetricGoal removes one of a client's goals
+func (s *trainerService) DeleteClientMetricGoal(userID, traineeID, goalID uint) error {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.getClient(trainer, traineeID); err != nil {
+		return err
+	}
+
+	goal, err := s.metricGoalRepo.FindByID(traineeID, goalID)
+	if err != nil {
+		return notFound(err)
+	}
+	return s.metricGoalRepo.Delete(goal)
+}
+
+func (s *trainerService) GetClientSessions(userID, traineeID uint, page, pageSize int) (*dto.PaginatedResponse, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getClient(trainer, traineeID); err != nil {
+		return nil, err
+	}
+
+	cards, total, err := s.sessionCardRepo.FindByTraineeID(traineeID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return newPaginatedResponse(toSessionCardResponses(cards, trainer.User.Units()), page, pageSize, total), nil
+}
+
+// GetClientRecords lists the client's current personal records per exercise
+func (s *trainerService) GetClientRecords(userID, traineeID uint) ([]dto.ExerciseRecordsResponse, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getClient(trainer, traineeID); err != nil {
+		return nil, err
+	}
+
+	records, err := s.recordRepo.FindByTraineeID(traineeID)
+	if err != nil {
+		return nil, err
+	}
+	return toPersonalRecordResponses(records, trainer.User.Units()), nil
+}
+
+// ==========================================
+// SCHEDULES
+// ==========================================
+
+func (s *trainerService) GetSchedules(userID uint, filters map[string]interface{}) ([]dto.ScheduleResponse, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules, err := s.scheduleRepo.FindByTrainerID(trainer.ID, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range schedules {
+		schedules[i].Trainer = *trainer
+	}
+	return toScheduleResponses(schedules), nil
+}
+
+func (s *trainerService) GetScheduleDetail(userID, scheduleID uint) (*dto.ScheduleResponse, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := s.getSchedule(trainer, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toScheduleResponse(schedule)
+	return &resp, nil
+}
+
+func (s *trainerService) CreateSchedule(userID uint, req *dto.CreateScheduleRequest) (*dto.ScheduleResponse, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getClient(trainer, req.TraineeID); err != nil {
+		return nil, err
+	}
+	if err := s.checkAssignment(trainer, req.ProgramAssignmentID, req.TraineeID); err != nil {
+		return nil, err
+	}
+
+	if err := validateTimeOfDay(req.Time); err != nil {
+		return nil, err
+	}
+
+	date := truncateDate(req.Date)
+	conflict, err := s.scheduleRepo.CheckConflict(trainer.ID, date, req.Time, req.Duration, nil)
+	if err != nil {
+		return nil, err
+	}
+	if conflict {
+		return nil, apperrors.ErrScheduleConflict
+	}
+
+	schedule := &models.Schedule{
+		TrainerID:           trainer.ID,
+		TraineeID:           req.TraineeID,
+		LocationID:          req.LocationID,
+		ProgramAssignmentID: req.ProgramAssignmentID,
+		Date:                date,
+		Time:                req.Time,
+		Duration:            req.Duration,
+		Title:               req.Title,
+		Description:         req.Description,
+		SessionType:         req.SessionType,
+		PlannedExercises:    pq.StringArray(req.PlannedExercises),
+		Status:              "scheduled",
+	}
+	if err := s.scheduleRepo.Create(schedule); err != nil {
+		return nil, err
+	}
+
+	if err := s.traineeRepo.UpdateStats(req.TraineeID); err != nil {
+		return nil, err
+	}
+
+	return s.GetScheduleDetail(userID, schedule.ID)
+}
+
+func (s *trainerService) UpdateSchedule(userID, scheduleID uint, req *dto.UpdateScheduleRequest) (*dto.ScheduleResponse, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := s.getSchedule(trainer, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if schedule.SeriesID != nil && req.Scope != "" && req.Scope != scopeThis {
+		if err := s.updateSeriesOccurrences(trainer, schedule, req); err != nil {
+			return nil, err
+		}
+		return s.GetScheduleDetail(userID, schedule.ID)
+	}
+
+	// Only the edited columns are written: the reminder dispatcher may claim
+	// a reminder on this row meanwhile
+	columns := make([]string, 0)
+	reschedule := false
+	if req.Date != nil {
+		schedule.Date = truncateDate(*req.Date)
+		columns = append(columns, "date")
+		reschedule = true
+	}
+	if req.Time != nil {
+		if err := validateTimeOfDay(*req.Time); err != nil {
+			return nil, err
+		}
+		schedule.Time = *req.Time
+		columns = append(columns, "time")
+		reschedule = true
+	}
+	if req.Duration != nil {
+		schedule.Duration = *req.Duration
+		columns = append(columns, "duration")
+		reschedule = true
+	}
+	if req.LocationID != nil {
+		schedule.LocationID = req.LocationID
+		columns = append(columns, "location_id")
+	}
+	if req.Title != nil {
+		schedule.Title = *req.Title
+		columns = append(columns, "title")
+	}
+	if req.Description != nil {
+		schedule.Description = req.Description
+		columns = append(columns, "description")
+	}
+	if req.SessionType != nil {
+		schedule.SessionType = req.SessionType
+		columns = append(columns, "session_type")
+	}
+	if req.PlannedExercises != nil {
+		schedule.PlannedExercises = pq.StringArray(req.PlannedExercises)
+		columns = append(columns, "planned_exercises")
+	}
+	if req.Notes != nil {
+		schedule.Notes = req.Notes
+		columns = append(columns, "notes")
+	}
+
+	// Edited occurrences no longer follow the series template
+	if schedule.SeriesID != nil && (reschedule || req.LocationID != nil || req.Title != nil ||
+		req.Description != nil || req.SessionType != nil || req.PlannedExercises != nil) {
+		schedule.IsException = true
+		columns = append(columns, "is_exception")
+	}
+
+	statusChanged := req.Status != nil && *req.Status != schedule.Status
+	if statusChanged {
+		schedule.Status = *req.Status
+		columns = append(columns, "status")
+		if schedule.Status == "cancelled" {
+			now := time.Now()
+			schedule.CancelledAt = &now
+			schedule.CancelledBy = &userID
+			columns = append(columns, "cancelled_at", "cancelled_by")
+		}
+	}
+
+	if reschedule {
+		conflict, err := s.scheduleRepo.CheckConflict(trainer.ID, schedule.Date, schedule.Time, schedule.Duration, &schedule.ID)
+		if err != nil {
+			return nil, err
+		}
+		if conflict {
+			return nil, apperrors.ErrScheduleConflict
+		}
+		schedule.ResetReminders()
+		columns = append(columns, reminderColumns...)
+	}
+	if len(columns) == 0 {
+		return s.GetScheduleDetail(userID, schedule.ID)
+	}
+
+	// Cached trainee stats change together with the status
+	err = database.Transaction(func(tx *gorm.DB) error {
+		if err := repository.NewScheduleRepository(tx).UpdateColumns(schedule, columns...); err != nil {
+			return err
+		}
+		if statusChanged {
+			return refreshScheduleProgress(tx, schedule)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetScheduleDetail(userID, schedule.ID)
+}
+
+// cancellationColumns are the schedule columns cancelling a session sets
+var cancellationColumns = []string{"status", "cancelled_at", "cancelled_by", "cancellation_reason"}
+
+// completionColumns are the schedule columns recording a session card sets
+var completionColumns = []string{"status", "session_card_id"}
+
+func (s *trainerService) CancelSchedule(userID, scheduleID uint, req *dto.CancelScheduleRequest) error {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return err
+	}
+
+	schedule, err := s.getSchedule(trainer, scheduleID)
+	if err != nil {
+		return err
+	}
+
+	if schedule.SeriesID != nil && req.Scope != "" && req.Scope != scopeThis {
+		return s.cancelSeriesOccurrences(userID, trainer, schedule, req)
+	}
+
+	if !schedule.CanBeCancelled() {
+		return fmt.Errorf("%w: only scheduled or confirmed sessions can be cancelled", apperrors.ErrConflict)
+	}
+
+	now := time.Now()
+	schedule.Status = "cancelled"
+	schedule.CancelledAt = &now
+	schedule.CancelledBy = &userID
+	schedule.CancellationReason = req.Reason
+
+	return database.Transaction(func(tx *gorm.DB) error {
+		if err := repository.NewScheduleRepository(tx).UpdateColumns(schedule, cancellationColumns...); err != nil {
+			return err
+		}
+		return repository.NewTraineeRepository(tx).UpdateStats(schedule.TraineeID)
+	})
+}
+
+// ==========================================
+// SESSION CARDS
+// ==========================================
+
+func (s *trainerService) GetSessions(userID uint, page, pageSize int) (*dto.PaginatedResponse, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	cards, total, err := s.sessionCardRepo.FindByTrainerID(trainer.ID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range cards {
+		cards[i].Trainer = *trainer
+	}
+	return newPaginatedResponse(toSessionCardResponses(cards, trainer.User.Units()), page, pageSize, total), nil
+}
+
+func (s *trainerService) GetSessionDetail(userID, sessionID uint) (*dto.SessionCardResponse, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	card, err := s.getSessionCard(trainer, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toSessionCardResponse(card, trainer.User.Units())
+	return &resp, nil
+}
+
+// CreateSessionCard records the summary of a session and completes its schedule
+func (s *trainerService) CreateSessionCard(userID uint, req *dto.CreateSessionCardRequest) (*dto.SessionCardResponse, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := s.getSchedule(trainer, req.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.SessionCardID != nil {
+		return nil, fmt.Errorf("%w: schedule already has a session card", apperrors.ErrAlreadyExists)
+	}
+	if schedule.Status == "cancelled" || schedule.Status == "no_show" {
+		return nil, fmt.Errorf("%w: cannot record a session for a %s schedule", apperrors.ErrConflict, schedule.Status)
+	}
+
+	card := &models.SessionCard{
+		ScheduleID:       schedule.ID,
+		TrainerID:        trainer.ID,
+		TraineeID:        schedule.TraineeID,
+		Date:             schedule.Date,
+		Title:            schedule.Title,
+		Duration:         req.Duration,
+		OverallFeedback:  req.OverallFeedback,
+		NextSessionGoals: pq.StringArray(req.NextSessionGoals),
+		TraineeRating:    req.TraineeRating,
+		Exercises:        newSessionExercises(req.Exercises, trainer.User.Units()),
+	}
+
+	err = database.Transaction(func(tx *gorm.DB) error {
+		card.RecalculateTotals()
+		if err := repository.NewSessionCardRepository(tx).Create(card); err != nil {
+			return err
+		}
+
+		schedule.Status = "completed"
+		schedule.SessionCardID = &card.ID
+		if err := repository.NewScheduleRepository(tx).UpdateColumns(schedule, completionColumns...); err != nil {
+			return err
+		}
+
+		if err := syncPersonalRecords(tx, card); err != nil {
+			return err
+		}
+		if err := repository.NewExerciseRepository(tx).RefreshUsage(cardExerciseIDs(card)); err != nil {
+			return err
+		}
+		return refreshScheduleProgress(tx, schedule)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetSessionDetail(userID, card.ID)
+}
+
+func (s *trainerService) UpdateSessionCard(userID, sessionID uint, req *dto.UpdateSessionCardRequest) (*dto.SessionCardResponse, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	card, err := s.getSessionCard(trainer, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Duration != nil {
+		card.Duration = *req.Duration
+	}
+	if req.OverallFeedback != nil {
+		card.OverallFeedback = req.OverallFeedback
+	}
+	if req.NextSessionGoals != nil {
+		card.NextSessionGoals = pq.StringArray(req.NextSessionGoals)
+	}
+	if req.TrainerRating != nil {
+		card.TrainerRating = req.TrainerRating
+	}
+	if req.TraineeRating != nil {
+		card.TraineeRating = req.TraineeRating
+	}
+
+	// Exercises that leave the card need their records rebuilt too
+	var previousExerciseIDs []uint
+	if req.Exercises != nil {
+		previousExerciseIDs = cardExerciseIDs(card)
+		card.Exercises = newSessionExercises(req.Exercises, trainer.User.Units())
+	}
+
+	err = database.Transaction(func(tx *gorm.DB) error {
+		cardRepo := repository.NewSessionCardRepository(tx)
+
+		if req.Exercises != nil {
+			card.RecalculateTotals()
+			if err := cardRepo.ReplaceExercises(card); err != nil {
+				return err
+			}
+		}
+		if err := cardRepo.Update(card); err != nil {
+			return err
+		}
+
+		if req.Duration != nil || req.Exercises != nil {
+			if err := repository.NewTraineeRepository(tx).UpdateStats(card.TraineeID); err != nil {
+				return err
+			}
+		}
+
+		if err := rebuildPersonalRecords(tx, card.TraineeID, previousExerciseIDs); err != nil {
+			return err
+		}
+		if err := syncPersonalRecords(tx, card); err != nil {
+			return err
+		}
+		if req.Exercises != nil {
+			usedIDs := append(previousExerciseIDs, cardExerciseIDs(card)...)
+			if err := repository.NewExerciseRepository(tx).RefreshUsage(usedIDs); err != nil {
+				return err
+			}
+		}
+		return evaluateAchievements(tx, card.TraineeID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toSessionCardResponse(card, trainer.User.Units())
+	return &resp, nil
+}
+
+// DeleteSessionCard removes a card and reopens its schedule as confirmed
+func (s *trainerService) DeleteSessionCard(userID, sessionID uint) error {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return err
+	}
+
+	card, err := s.getSessionCard(trainer, sessionID)
+	if err != nil {
+		return err
+	}
+
+	return database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Schedule{}).Where("id = ?", card.ScheduleID).
+			Updates(map[string]interface{}{
+				"session_card_id": nil,
+				"status":          "confirmed",
+			}).Error; err != nil {
+			return err
+		}
+		if err := repository.NewSessionCardRepository(tx).Delete(card.ID); err != nil {
+			return err
+		}
+
+		// Records set in this session fall back to the next best
+		if err := rebuildPersonalRecords(tx, card.TraineeID, cardExerciseIDs(card)); err != nil {
+			return err
+		}
+		if err := repository.NewExerciseRepository(tx).RefreshUsage(cardExerciseIDs(card)); err != nil {
+			return err
+		}
+
+		schedule, err := repository.NewScheduleRepository(tx).FindByID(card.ScheduleID)
+		if err != nil {
+			return err
+		}
+		return refreshScheduleProgress(tx, schedule)
+	})
+}
+
+// newSessionExercises builds exercises and sets from the request, entered in
+// prefs. Totals are left to SessionCard.RecalculateTotals.
+func newSessionExercises(reqs []dto.CreateSessionExerciseRequest, prefs units.Preferences) []models.SessionExercise {
+	exercises := make([]models.SessionExercise, 0, len(reqs))
+	for _, exReq := range reqs {
+		exercise := models.SessionExercise{
+			ExerciseLibraryID: exReq.ExerciseLibraryID,
+			Name:              exReq.Name,
+			Category:          exReq.Category,
+			ExerciseOrder:     exReq.ExerciseOrder,
+			Notes:             exReq.Notes,
+			FormNotes:         exReq.FormNotes,
+			AvgHeartRate:      exReq.AvgHeartRate,
+			MaxHeartRate:      exReq.MaxHeartRate,
+			HeartRateZones:    pq.Int64Array(exReq.HeartRateZones),
+			Sets:              make([]models.ExerciseSet, 0, len(exReq.Sets)),
+		}
+
+		for _, setReq := range exReq.Sets {
+			completed := true
+			if setReq.Completed != nil {
+				completed = *setReq.Completed
+			}
+
+			exercise.Sets = append(exercise.Sets, models.ExerciseSet{
+				SetNumber:    setReq.SetNumber,
+				Reps:         setReq.Reps,
+				Weight:       canonicalPtr(setReq.Weight, prefs.For(units.Mass)),
+				Duration:     setReq.Duration,
+				Distance:     canonicalPtr(setReq.Distance, prefs.For(units.Distance)),
+				AvgHeartRate: setReq.AvgHeartRate,
+				MaxHeartRate: setReq.MaxHeartRate,
+				RestDuration: setReq.RestDuration,
+				Completed:    completed,
+				RPE:          setReq.RPE,
+				Notes:        setReq.Notes,
+			})
+		}
+
+		exercises = append(exercises, exercise)
+	}
+	return exercises
+}
+
+// ==========================================
+// PROGRAMS
+// ==========================================
+
+func (s *trainerService) GetPrograms(userID uint) ([]dto.TrainerProgramResponse, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	programs, err := s.programRepo.FindByTrainerID(trainer.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]dto.TrainerProgramResponse, 0, len(programs))
+	for i := range programs {
+		programs[i].Trainer = *trainer
+		resp = append(resp, toTrainerProgramResponse(&programs[i]))
+	}
+	return resp, nil
+}
+
+func (s *trainerService) GetProgramDetail(userID, programID uint) (*dto.TrainerProgramResponse, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	program, err := s.getProgram(trainer, programID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toTrainerProgramResponse(program)
+	return &resp, nil
+}
+
+func (s *trainerService) CreateProgram(userID uint, req *dto.CreateProgramRequest) (*dto.TrainerProgramResponse, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	program := &models.Program{
+		TrainerID:            trainer.ID,
+		Name:                 req.Name,
+		Description:          req.Description,
+		TotalWeeks:           req.TotalWeeks,
+		SessionsPerWeek:      req.SessionsPerWeek,
+		Goals:                pq.StringArray(req.Goals),
+		TargetFitnessLevel:   req.TargetFitnessLevel,
+		AdherenceThreshold:   defaultAdherenceThreshold,
+		ProgressionScheme:    progressionDoubleProgression,
+		ProgressionIncrement: defaultProgressionIncrement,
+		Status:               "draft",
+		Visibility:           programVisibilityPrivate,
+	}
+	if req.AdherenceThreshold != nil {
+		program.AdherenceThreshold = *req.AdherenceThreshold
+	}
+	if req.ProgressionScheme != nil {
+		program.ProgressionScheme = *req.ProgressionScheme
+	}
+	if req.ProgressionIncrement != nil {
+		program.ProgressionIncrement = *req.ProgressionIncrement
+	}
+	if req.Visibility != nil {
+		program.Visibility = *req.Visibility
+	}
+
+	err = database.Transaction(func(tx *gorm.DB) error {
+		program.Version = 1
+		if err := repository.NewProgramRepository(tx).Create(program); err != nil {
+			return err
+		}
+		version := newProgramVersion(program)
+		return repository.NewProgramVersionRepository(tx).Create(&version)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	program.Trainer = *trainer
+	resp := toTrainerProgramResponse(program)
+	return &resp, nil
+}
+
+// UpdateProgram edits a program. Content changes go to its newest version,
+// which is first copied into a new version when clients are assigned to it,
+// so that they keep the program they started (see editableVersion).
+func (s *trainerService) UpdateProgram(userID, programID uint, req *dto.UpdateProgramRequest) (*dto.TrainerProgramResponse, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	program, err := s.getProgram(trainer, programID)
+	if err != nil {
+		return nil, err
+	}
+	before := newProgramVersion(program)
+
+	if req.Name != nil {
+		program.Name = *req.Name
+	}
+	if req.Description != nil {
+		program.Description = req.Description
+	}
+	if req.TotalWeeks != nil {
+		program.TotalWeeks = *req.TotalWeeks
+	}
+	if req.SessionsPerWeek != nil {
+		program.SessionsPerWeek = *req.SessionsPerWeek
+	}
+	if req.Goals != nil {
+		program.Goals = pq.StringArray(req.Goals)
+	}
+	if req.TargetFitnessLevel != nil {
+		program.TargetFitnessLevel = req.TargetFitnessLevel
+	}
+	if req.Status != nil {
+		program.Status = *req.Status
+	}
+	if req.AdherenceThreshold != nil {
+		program.AdherenceThreshold = *req.AdherenceThreshold
+	}
+	if req.ProgressionScheme != nil {
+		program.ProgressionScheme = *req.ProgressionScheme
+	}
+	if req.ProgressionIncrement != nil {
+		program.ProgressionIncrement = *req.ProgressionIncrement
+	}
+	if req.Visibility != nil {
+		program.Visibility = *req.Visibility
+	}
+
+	edited := newProgramVersion(program)
+	err = database.Transaction(func(tx *gorm.DB) error {
+		if !reflect.DeepEqual(before, edited) {
+			version, err := editableVersion(tx, program)
+			if err != nil {
+				return err
+			}
+
+			// The plan must still fit when the program gets shorter
+			outside, err := repository.NewProgramPlanRepository(tx).CountDaysOutside(version.ID, program.TotalWeeks, program.SessionsPerWeek)
+			if err != nil {
+				return err
+			}
+			if outside > 0 {
+				return fmt.Errorf("%w: %d planned days fall outside %d weeks of %d sessions; delete them first",
+					apperrors.ErrInvalidInput, outside, program.TotalWeeks, program.SessionsPerWeek)
+			}
+
+			setVersionContent(version, program)
+			if err := repository.NewProgramVersionRepository(tx).Update(version); err != nil {
+				return err
+			}
+		}
+		return repository.NewProgramRepository(tx).Update(program)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toTrainerProgramResponse(program)
+	return &resp, nil
+}
+
+func (s *trainerService) DeleteProgram(userID, programID uint) error {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return err
+	}
+
+	program, err := s.getProgram(trainer, programID)
+	if err != nil {
+		return err
+	}
+
+	// Its plan no longer counts towards exercise usage
+	return database.Transaction(func(tx *gorm.DB) error {
+		head, err := repository.NewProgramVersionRepository(tx).FindByNumber(program.ID, program.Version)
+		if err != nil {
+			return err
+		}
+		days, err := repository.NewProgramPlanRepository(tx).FindDays(head.ID, 0)
+		if err != nil {
+			return err
+		}
+		if err := repository.NewProgramRepository(tx).Delete(program.ID); err != nil {
+			return err
+		}
+		return repository.NewExerciseRepository(tx).RefreshUsage(plannedExerciseIDs(days))
+	})
+}
+
+// AssignProgram starts the newest version of a program for a client,
+// replacing any active assignment. Unless generateSchedules is false, the
+// program's sessions are laid out as schedules from the start date (see
+// layoutProgram); nothing is written when one of them cannot be placed.
+func (s *trainerService) AssignProgram(userID, programID uint, req *dto.AssignProgramRequest) (*dto.ProgramAssignmentResponse, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	program, err := s.getProgram(trainer, programID)
+	if err != nil {
+		return nil, err
+	}
+	if program.Status != "active" {
+		return nil, apperrors.ErrProgramNotActive
+	}
+
+	trainee, err := s.getClient(trainer, req.TraineeID)
+	if err != nil {
+		return nil, err
+	}
+
+	generate := req.GenerateSchedules == nil || *req.GenerateSchedules
+	timeOfDay := ""
+	preferred := toWeekdayInts(trainee.PreferredWeekdays)
+	if generate {
+		switch {
+		case req.Time != nil:
+			timeOfDay = *req.Time
+		case trainee.PreferredTime != nil:
+			timeOfDay = trimSeconds(*trainee.PreferredTime)
+		default:
+			return nil, fmt.Errorf("%w: time is required to generate schedules when the client has no preferred time", apperrors.ErrInvalidInput)
+		}
+		if err := validateTimeOfDay(timeOfDay); err != nil {
+			return nil, err
+		}
+		if req.Weekdays != nil {
+			preferred = req.Weekdays
+		}
+	} else if req.TotalSessions == 0 {
+		return nil, fmt.Errorf("%w: totalSessions is required when schedules are not generated", apperrors.ErrInvalidInput)
+	}
+
+	startDate := truncateDate(req.StartDate)
+	assignment := &models.ProgramAssignment{
+		ProgramID:     program.ID,
+		TraineeID:     req.TraineeID,
+		StartDate:     startDate,
+		EndDate:       startDate.AddDate(0, 0, program.TotalWeeks*7-1),
+		CurrentWeek:   1,
+		TotalSessions: req.TotalSessions,
+		Status:        "active",
+		Notes:         req.Notes,
+	}
+
+	today := gymToday()
+	err = database.Transaction(func(tx *gorm.DB) error {
+		programRepo := repository.NewProgramRepository(tx)
+		scheduleRepo := repository.NewScheduleRepository(tx)
+		versionRepo := repository.NewProgramVersionRepository(tx)
+
+		version, err := versionRepo.FindByNumber(program.ID, program.Version)
+		if err != nil {
+			return err
+		}
+		assignment.ProgramVersionID = version.ID
+		assignment.ProgramVersion = *version
+
+		var sessions []programSession
+		if generate {
+			days, err := repository.NewProgramPlanRepository(tx).FindDays(version.ID, 0)
+			if err != nil {
+				return err
+			}
+
+			from := startDate
+			if from.Before(today) {
+				from = today
+			}
+			isFree := func(date time.Time, duration int) (bool, error) {
+				conflict, err := scheduleRepo.CheckConflict(trainer.ID, date, timeOfDay, duration, nil)
+				return !conflict, err
+			}
+
+			var conflicts []string
+			sessions, conflicts, err = layoutProgram(programWeekSessions(program.TotalWeeks, program.SessionsPerWeek, days), startDate, from, preferred, isFree)
+			if err != nil {
+				return err
+			}
+			if err := conflictDatesError(conflicts); err != nil {
+				return err
+			}
+			if len(sessions) == 0 {
+				return fmt.Errorf("%w: no program sessions fall on or after %s", apperrors.ErrInvalidInput, from.Format("2006-01-02"))
+			}
+			if len(sessions) > maxProgramSessions {
+				return fmt.Errorf("%w: a program can lay out at most %d sessions", apperrors.ErrInvalidInput, maxProgramSessions)
+			}
+			if assignment.TotalSessions == 0 {
+				assignment.TotalSessions = len(sessions)
+			}
+		}
+
+		// The replaced assignment's upcoming sessions go with it
+		current, err := programRepo.FindActiveAssignmentByTraineeID(req.TraineeID)
+		if err == nil {
+			if _, err := scheduleRepo.CancelPendingByAssignment(current.ID, today, "Replaced by program "+program.Name, userID); err != nil {
+				return err
+			}
+		} else if errors.Is(err, gorm.ErrRecordNotFound) {
+			current = nil
+		} else {
+			return err
+		}
+
+		if err := tx.Model(&models.ProgramAssignment{}).
+			Where("trainee_id = ? AND status = ?", req.TraineeID, "active").
+			Update("status", "cancelled").Error; err != nil {
+			return err
+		}
+
+		if err := programRepo.CreateAssignment(assignment); err != nil {
+			return err
+		}
+
+		for _, session := range sessions {
+			if err := scheduleRepo.Create(newProgramSchedule(assignment, program, session, timeOfDay, req.LocationID)); err != nil {
+				return err
+			}
+		}
+
+		if err := versionRepo.RefreshStats(program.ID); err != nil {
+			return err
+		}
+		if current != nil && current.ProgramID != program.ID {
+			if err := versionRepo.RefreshStats(current.ProgramID); err != nil {
+				return err
+			}
+		}
+
+		if err := repository.NewTraineeRepository(tx).UpdateStats(req.TraineeID); err != nil {
+			return err
+		}
+		return evaluateAchievements(tx, req.TraineeID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toProgramAssignmentResponse(assignment), nil
+}
+
+// ==========================================
+// EXERCISE LIBRARY
+// ==========================================
+
+// GetExercises searches the exercises the trainer can use and counts the
+// matches per facet value
+func (s *trainerService) GetExercises(userID uint, filters map[string]interface{}, sort string, page, pageSize int) (*dto.ExerciseSearchResponse, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	exercises, total, err := s.exerciseRepo.Search(trainer.ID, filters, sort, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.exerciseRepo.Facets(trainer.ID, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]dto.ExerciseLibraryResponse, 0, len(exercises))
+	for i := range exercises {
+		exercise := toExerciseLibraryResponse(&exercises[i])
+		exercise.Ownership = exerciseOwnership(trainer, &exercises[i])
+		data = append(data, exercise)
+	}
+
+	facets := make(map[string][]dto.FacetValueResponse, len(counts))
+	for name, values := range counts {
+		facets[name] = make([]dto.FacetValueResponse, 0, len(values))
+		for _, value := range values {
+			facets[name] = append(facets[name], dto.FacetValueResponse{Value: value.Value, Count: value.Count})
+		}
+	}
+
+	return &dto.ExerciseSearchResponse{
+		PaginatedResponse: *newPaginatedResponse(data, page, pageSize, total),
+		Facets:            facets,
+	}, nil
+}
+
+// exerciseOwnership tells a trainer's own exercises from the shared library
+// and other trainers' public ones
+func exerciseOwnership(trainer *models.Trainer, exercise *models.ExerciseLibrary) string {
+	switch {
+	case exercise.TrainerID == nil:
+		return "library"
+	case *exercise.TrainerID == trainer.ID:
+		return "own"
+	default:
+		return "shared"
+	}
+}
+
+func (s *trainerService) CreateExercise(userID uint, req *dto.CreateExerciseRequest) (*dto.ExerciseLibraryResponse, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	exercise := &models.ExerciseLibrary{
+		TrainerID:    &trainer.ID,
+		Name:         req.Name,
+		Category:     req.Category,
+		Description:  req.Description,
+		MuscleGroups: pq.StringArray(req.MuscleGroups),
+		Equipment:    pq.StringArray(req.Equipment),
+		Difficulty:   req.Difficulty,
+		Instructions: pq.StringArray(req.Instructions),
+		VideoURL:     req.VideoURL,
+		ThumbnailURL: req.ThumbnailURL,
+		Images:       pq.StringArray(req.Images),
+		IsPublic:     req.IsPublic,
+	}
+	err = database.Transaction(func(tx *gorm.DB) error {
+		if err := repository.NewExerciseRepository(tx).Create(exercise); err != nil {
+			return err
+		}
+		return attachExerciseMedia(tx, userID, exercise)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	exercise.Trainer = trainer
+	resp := toExerciseLibraryResponse(exercise)
+	return &resp, nil
+}
+
+func (s *trainerService) UpdateExercise(userID, exerciseID uint, req *dto.UpdateExerciseRequest) (*dto.ExerciseLibraryResponse, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	exercise, err := s.getOwnExercise(trainer, exerciseID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		exercise.Name = *req.Name
+	}
+	if req.Category != nil {
+		exercise.Category = *req.Category
+	}
+	if req.Description != nil {
+		exercise.Description = req.Description
+	}
+	if req.MuscleGroups != nil {
+		exercise.MuscleGroups = pq.StringArray(req.MuscleGroups)
+	}
+	if req.Equipment != nil {
+		exercise.Equipment = pq.StringArray(req.Equipment)
+	}
+	if req.Difficulty != nil {
+		exercise.Difficulty = req.Difficulty
+	}
+	if req.Instructions != nil {
+		exercise.Instructions = pq.StringArray(req.Instructions)
+	}
+	if req.VideoURL != nil {
+		exercise.VideoURL = req.VideoURL
+	}
+	if req.ThumbnailURL != nil {
+		exercise.ThumbnailURL = req.ThumbnailURL
+	}
+	if req.Images != nil {
+		exercise.Images = pq.StringArray(req.Images)
+	}
+	if req.IsPublic != nil {
+		exercise.IsPublic = *req.IsPublic
+	}
+
+	err = database.Transaction(func(tx *gorm.DB) error {
+		if err := repository.NewExerciseRepository(tx).Update(exercise); err != nil {
+			return err
+		}
+		return attachExerciseMedia(tx, userID, exercise)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toExerciseLibraryResponse(exercise)
+	return &resp, nil
+}
+
+// DeleteExercise removes one of the trainer's exercises. Its uploads are
+// left to the media cleanup, which keeps those another exercise still links.
+func (s *trainerService) DeleteExercise(userID, exerciseID uint) error {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.getOwnExercise(trainer, exerciseID); err != nil {
+		return err
+	}
+	return s.exerciseRepo.Delete(exerciseID)
+}
+
+func (s *trainerService) GetExerciseCategories() ([]dto.ExerciseCategoryResponse, error) {
+	categories, err := s.exerciseRepo.GetCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]dto.ExerciseCategoryResponse, 0, len(categories))
+	for _, category := range categories {
+		resp = append(resp, dto.ExerciseCategoryResponse{
+			Category: category.Category,
+			Count:    category.Count,
+		})
+	}
+	return resp, nil
+}
+
+// ==========================================
+// ANALYTICS
+// ==========================================
+
+const analyticsWeeks = 8
+
+func (s *trainerService) GetAnalyticsOverview(userID uint) (*dto.AnalyticsOverviewResponse, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	clients, err := s.trainerRepo.GetClients(trainer.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules, err := s.scheduleRepo.FindByTrainerID(trainer.ID, map[string]interface{}{
+		"status": "completed",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cards, _, err := s.sessionCardRepo.FindByTrainerID(trainer.ID, -1, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.AnalyticsOverviewResponse{
+		SessionsPerWeek:  make([]int, analyticsWeeks),
+		ClientGrowth:     make([]int, analyticsWeeks),
+		PopularExercises: []string{},
+	}
+
+	// Weekly buckets, oldest first, ending with the current week
+	weekStart := startOfWeek(time.Now()).AddDate(0, 0, -7*(analyticsWeeks-1))
+	for _, schedule := range schedules {
+		if week := weeksBetween(weekStart, schedule.Date); week >= 0 && week < analyticsWeeks {
+			resp.SessionsPerWeek[week]++
+		}
+	}
+
+	active := 0
+	for _, client := range clients {
+		if client.Status == "active" {
+			active++
+		}
+		for week := 0; week < analyticsWeeks; week++ {
+			if client.CreatedAt.Before(weekStart.AddDate(0, 0, 7*(week+1))) {
+				resp.ClientGrowth[week]++
+			}
+		}
+	}
+	if len(clients) > 0 {
+		resp.ClientRetentionRate = float32(active) / float32(len(clients)) * 100
+	}
+
+	ratingSum, ratingCount := 0, 0
+	exerciseCounts := map[string]int{}
+	for _, card := range cards {
+		if card.TraineeRating != nil {
+			ratingSum += *card.TraineeRating
+			ratingCount++
+		}
+		for _, exercise := range card.Exercises {
+			exerciseCounts[exercise.Name]++
+		}
+	}
+	if ratingCount > 0 {
+		resp.AverageSessionRate = float32(ratingSum) / float32(ratingCount)
+	}
+
+	resp.PopularExercises = topKeys(exerciseCounts, 5)
+
+	return resp, nil
+}
+
+func (s *trainerService) GetClientAnalytics(userID, traineeID uint) (*dto.ClientAnalyticsResponse, error) {
+	trainer, err := s.getTrainer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	trainee, err := s.getClient(trainer, traineeID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.ClientAnalyticsResponse{
+		TraineeID:     trainee.ID,
+		Name:          trainee.User.Name,
+		TotalSessions: trainee.TotalSessions,
+	}
+
+	schedules, err := s.scheduleRepo.FindByTraineeID(trainee.ID, map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+
+	attended, missed := 0, 0
+	for _, schedule := range schedules {
+		switch schedule.Status {
+		case "completed":
+			attended++
+		case "cancelled", "no_show":
+			missed++
+		}
+	}
+	if attended+missed > 0 {
+		resp.AttendanceRate = float32(attended) / float32(attended+missed) * 100
+	}
+
+	cards, err := s.sessionCardRepo.Search(trainee.ID, map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+
+	totalDuration := 0
+	type exerciseStats struct {
+		maxWeight   float32
+		totalVolume float32
+	}
+	exercises := map[string]*exerciseStats{}
+	for _, card := range cards {
+		totalDuration += card.Duration
+		for _, exercise := range card.Exercises {
+			stats, ok := exercises[exercise.Name]
+			if !ok {
+				stats = &exerciseStats{}
+				exercises[exercise.Name] = stats
+			}
+			stats.totalVolume += exercise.TotalVolume
+			for _, set := range exercise.Sets {
+				if set.Weight != nil && *set.Weight > stats.maxWeight {
+					stats.maxWeight = *set.Weight
+				}
+			}
+		}
+	}
+	if len(cards) > 0 {
+		resp.AverageSessionDuration = totalDuration / len(cards)
+	}
+
+	weightUnit := trainer.User.Units().For(units.Mass)
+	volumes := make(map[string]int, len(exercises))
+	for name, stats := range exercises {
+		volumes[name] = int(stats.totalVolume)
+	}
+	for _, name := range topKeys(volumes, 5) {
+		resp.TopExercises = append(resp.TopExercises, struct {
+			Name        string  `json:"name"`
+			MaxWeight   float32 `json:"maxWeight"`
+			TotalVolume float32 `json:"totalVolume"`
+		}{
+			Name:        name,
+			MaxWeight:   displayValue(exercises[name].maxWeight, weightUnit),
+			TotalVolume: displayValue(exercises[name].totalVolume, weightUnit),
+		})
+	}
+
+	metrics, err := s.metricRepo.FindByTraineeID(trainee.ID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Metrics come newest first; charts want oldest first
+	for i := len(metrics) - 1; i >= 0; i-- {
+		point := struct {
+			Date  time.Time `json:"date"`
+			Value float32   `json:"value"`
+		}{Date: metrics[i].Date, Value: metrics[i].Value}
+		if metrics[i].Unit == string(units.Kilogram) {
+			point.Value = displayValue(point.Value, weightUnit)
+		}
+
+		switch metrics[i].Type {
+		case "weight":
+			resp.WeightProgress = append(resp.WeightProgress, point)
+		case "body_fat":
+			resp.BodyFatProgress = append(resp.BodyFatProgress, point)
+		}
+	}
+
+	return resp, nil
+}
+
+// ==========================================
+// PUBLIC BROWSING
+// ==========================================
+
+func (s *trainerService) GetTrainers(filters map[string]interface{}) ([]dto.TrainerPublicResponse, error) {
+	trainers, err := s.trainerRepo.FindAll(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]dto.TrainerPublicResponse, 0, len(trainers))
+	for i := range trainers {
+		resp = append(resp, toTrainerPublicResponse(&trainers[i]))
+	}
+	return resp, nil
+}
+
+func (s *trainerService) GetTrainerDetail(trainerID uint) (*dto.TrainerPublicResponse, error) {
+	trainer, err := s.trainerRepo.FindByID(trainerID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	resp := toTrainerPublicResponse(trainer)
+	return &resp, nil
+}
+
+// ==========================================
+// HELPERS
+// ==========================================
+
+// refreshScheduleProgress updates everything derived from a schedule's status:
+// the trainee's cached stats, the progress of its program assignment and any
+// achievements this unlocks. Use inside a transaction.
+func refreshScheduleProgress(tx *gorm.DB, schedule *models.Schedule) error {
+	if err := repository.NewTraineeRepository(tx).UpdateStats(schedule.TraineeID); err != nil {
+		return err
+	}
+	if schedule.ProgramAssignmentID != nil {
+		if _, err := refreshAssignmentProgress(tx, *schedule.ProgramAssignmentID, gymToday()); err != nil {
+			return err
+		}
+	}
+	return evaluateAchievements(tx, schedule.TraineeID)
+}
+
+// validateTimeOfDay checks a "HH:MM" time string
+func validateTimeOfDay(value string) error {
+	if _, err := time.Parse("15:04", value); err != nil {
+		return fmt.Errorf("%w: time must be in HH:MM format", apperrors.ErrInvalidInput)
+	}
+	return nil
+}
+
+// truncateDate drops the time-of-day part of t
+func truncateDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// startOfWeek returns the Monday of the week containing t
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return truncateDate(t).AddDate(0, 0, -offset)
+}
+
+// weeksBetween returns how many whole weeks t is after start
+func weeksBetween(start, t time.Time) int {
+	days := int(truncateDate(t).Sub(start).Hours() / 24)
+	if days < 0 {
+		return -1
+	}
+	return days / 7
+}
+
+// latestMetricPerType keeps the newest metric of each type/measurement
+func latestMetricPerType(metrics []models.Metric) []models.Metric {
+	seen := map[string]bool{}
+	latest := make([]models.Metric, 0)
+	for _, metric := range metrics {
+		key := metric.Type
+		if metric.MeasurementType != nil {
+			key += ":" + *metric.MeasurementType
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		latest = append(latest, metric)
+	}
+	return latest
+}
+
+// topKeys returns up to n keys with the highest counts
+func topKeys(counts map[string]int, n int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] == counts[keys[j]] {
+			return keys[i] < keys[j]
+		}
+		return counts[keys[i]] > counts[keys[j]]
+	})
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
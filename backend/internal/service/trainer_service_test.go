@@ -0,0 +1,129 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+	apperrors "fitness-training-backend/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+type fakeTrainers struct {
+	repository.TrainerRepository
+	trainer *models.Trainer
+}
+
+func (f *fakeTrainers) FindByUserID(userID uint) (*models.Trainer, error) {
+	if f.trainer.UserID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return f.trainer, nil
+}
+
+type fakeTrainees struct {
+	repository.TraineeRepository
+	trainees map[uint]*models.Trainee
+}
+
+func (f *fakeTrainees) FindByID(id uint) (*models.Trainee, error) {
+	if trainee, ok := f.trainees[id]; ok {
+		return trainee, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+type fakeAssignments struct {
+	repository.ProgramRepository
+	assignments map[uint]*models.ProgramAssignment
+}
+
+func (f *fakeAssignments) FindAssignmentByID(id uint) (*models.ProgramAssignment, error) {
+	if assignment, ok := f.assignments[id]; ok {
+		return assignment, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// fakeSchedules fails the test when a schedule is written
+type fakeSchedules struct {
+	repository.ScheduleRepository
+	t *testing.T
+}
+
+func (f *fakeSchedules) CheckConflict(trainerID uint, date time.Time, timeStr string, duration int, excludeID *uint) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeSchedules) Create(schedule *models.Schedule) error {
+	f.t.Errorf("schedule created: %+v", schedule)
+	return errors.New("unexpected write")
+}
+
+// newAssignmentTestService has trainer 1 (user 10) with clients 5 and 6, and
+// trainer 2 with client 7. Assignment 100 is client 5's on trainer 1's
+// program, 200 client 7's on trainer 2's.
+func newAssignmentTestService(t *testing.T) *trainerService {
+	one, two := uint(1), uint(2)
+	return &trainerService{
+		trainerRepo: &fakeTrainers{trainer: &models.Trainer{ID: 1, UserID: 10}},
+		traineeRepo: &fakeTrainees{trainees: map[uint]*models.Trainee{
+			5: {ID: 5, TrainerID: &one},
+			6: {ID: 6, TrainerID: &one},
+			7: {ID: 7, TrainerID: &two},
+		}},
+		programRepo: &fakeAssignments{assignments: map[uint]*models.ProgramAssignment{
+			100: {ID: 100, TraineeID: 5, Program: models.Program{TrainerID: 1}},
+			200: {ID: 200, TraineeID: 7, Program: models.Program{TrainerID: 2}},
+		}},
+		scheduleRepo: &fakeSchedules{t: t},
+	}
+}
+
+func TestCheckAssignment(t *testing.T) {
+	s := newAssignmentTestService(t)
+	trainer := &models.Trainer{ID: 1, UserID: 10}
+	id := func(value uint) *uint { return &value }
+
+	tests := []struct {
+		name         string
+		assignmentID *uint
+		traineeID    uint
+		want         error
+	}{
+		{"no assignment", nil, 5, nil},
+		{"own assignment for the client", id(100), 5, nil},
+		{"own assignment for another client", id(100), 6, apperrors.ErrInvalidInput},
+		{"another trainer's assignment", id(200), 7, apperrors.ErrNotFound},
+		{"unknown assignment", id(300), 5, apperrors.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.checkAssignment(trainer, tt.assignmentID, tt.traineeID)
+			if (tt.want == nil && err != nil) || (tt.want != nil && !errors.Is(err, tt.want)) {
+				t.Errorf("checkAssignment = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateScheduleRejectsForeignAssignment(t *testing.T) {
+	s := newAssignmentTestService(t)
+	other := uint(200)
+
+	_, err := s.CreateSchedule(10, &dto.CreateScheduleRequest{
+		TraineeID:           5,
+		ProgramAssignmentID: &other,
+		Date:                time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC),
+		Time:                "09:00",
+		Duration:            60,
+		Title:               "Leg day",
+	})
+	if !errors.Is(err, apperrors.ErrNotFound) {
+		t.Errorf("CreateSchedule = %v, want not found", err)
+	}
+}
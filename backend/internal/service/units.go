@@ -0,0 +1,108 @@
+package service
+
+import (
+	"fmt"
+	"math"
+
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/models"
+	apperrors "fitness-training-backend/pkg/errors"
+	"fitness-training-backend/pkg/units"
+)
+
+// ==========================================
+// UNIT CONVERSION
+// Values are stored in kg, km and cm; requests and responses use the
+// units of the user making them
+// ==========================================
+
+func toUnitPreferences(prefs units.Preferences) dto.UnitPreferences {
+	return dto.UnitPreferences{
+		Weight:   string(prefs.For(units.Mass)),
+		Distance: string(prefs.For(units.Distance)),
+		Length:   string(prefs.For(units.Length)),
+	}
+}
+
+// metricKind returns the quantity a recorded or derived metric measures, or
+// "" for unitless ones
+func metricKind(metricType string) units.Kind {
+	switch metricType {
+	case metricTypeWeight, metricTypeMuscleMass, metricTypeLeanMass:
+		return units.Mass
+	case metricTypeBodyFat:
+		return units.Ratio
+	case metricTypeMeasurement:
+		return units.Length
+	}
+	return ""
+}
+
+// parseMetricUnit validates the unit a metric was recorded in
+func parseMetricUnit(metricType, unit string) (units.Unit, error) {
+	parsed, err := units.ParseKind(unit, metricKind(metricType))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s is not a valid unit for %s", apperrors.ErrInvalidInput, unit, metricType)
+	}
+	return parsed, nil
+}
+
+// displayValue converts a stored value to unit. Stored values have two
+// decimals, which in a unit smaller than the canonical one (lb) only hold one,
+// so 135 lb stored as 61.24 kg reads back as 135 lb.
+func displayValue(value float32, unit units.Unit) float32 {
+	if unit == units.Canonical(unit.Kind()) {
+		return value
+	}
+	converted := units.FromCanonical(float64(value), unit)
+	if units.ToCanonical(1, unit) < 1 {
+		return float32(math.Round(converted*10) / 10)
+	}
+	return roundUnitValue(converted)
+}
+
+func displayPtr(value *float32, unit units.Unit) *float32 {
+	if value == nil {
+		return nil
+	}
+	converted := displayValue(*value, unit)
+	return &converted
+}
+
+// canonicalValue converts a value entered in unit to the unit it is stored in
+func canonicalValue(value float32, unit units.Unit) float32 {
+	if unit == units.Canonical(unit.Kind()) {
+		return value
+	}
+	return roundUnitValue(units.ToCanonical(float64(value), unit))
+}
+
+func canonicalPtr(value *float32, unit units.Unit) *float32 {
+	if value == nil {
+		return nil
+	}
+	converted := canonicalValue(*value, unit)
+	return &converted
+}
+
+// cardioPace returns the seconds per distance unit of a duration (seconds)
+// over a distance (km), or nil without both
+func cardioPace(duration int, distance float32, unit units.Unit) *int {
+	if duration <= 0 || distance <= 0 {
+		return nil
+	}
+	pace := int(math.Round(float64(duration) / units.FromCanonical(float64(distance), unit)))
+	return &pace
+}
+
+func setPace(set *models.ExerciseSet, unit units.Unit) *int {
+	if set.Duration == nil || set.Distance == nil {
+		return nil
+	}
+	return cardioPace(*set.Duration, *set.Distance, unit)
+}
+
+// roundUnitValue rounds to the two decimals values are stored with
+func roundUnitValue(value float64) float32 {
+	return float32(math.Round(value*100) / 100)
+}
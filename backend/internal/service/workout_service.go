@@ -0,0 +1,341 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"fitness-training-backend/internal/database"
+	"fitness-training-backend/internal/dto"
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/internal/repository"
+	apperrors "fitness-training-backend/pkg/errors"
+	"fitness-training-backend/pkg/workout"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// scheduleMatchSlack is how far outside a scheduled slot an activity may
+// start or end and still be the session that was planned
+const scheduleMatchSlack = time.Hour
+
+// workoutSports names the exercise logged for each sport
+var workoutSports = map[string]string{
+	workout.SportRunning:  "Running",
+	workout.SportCycling:  "Cycling",
+	workout.SportSwimming: "Swimming",
+	workout.SportWalking:  "Walking",
+	workout.SportHiking:   "Hiking",
+	workout.SportRowing:   "Rowing",
+	workout.SportOther:    "Cardio",
+}
+
+// WorkoutService imports the activities clients record on watches and bike
+// computers into session cards
+type WorkoutService interface {
+	ImportWorkout(userID uint, data io.Reader) (*dto.WorkoutImportResponse, error)
+}
+
+type workoutService struct {
+	traineeRepo     repository.TraineeRepository
+	scheduleRepo    repository.ScheduleRepository
+	sessionCardRepo repository.SessionCardRepository
+	exerciseRepo    repository.ExerciseRepository
+}
+
+// NewWorkoutService creates a new workout import service
+func NewWorkoutService(
+	traineeRepo repository.TraineeRepository,
+	scheduleRepo repository.ScheduleRepository,
+	sessionCardRepo repository.SessionCardRepository,
+	exerciseRepo repository.ExerciseRepository,
+) WorkoutService {
+	return &workoutService{
+		traineeRepo:     traineeRepo,
+		scheduleRepo:    scheduleRepo,
+		sessionCardRepo: sessionCardRepo,
+		exerciseRepo:    exerciseRepo,
+	}
+}
+
+// ImportWorkout reads a GPX, TCX or FIT file into a session card with one
+// cardio exercise, its laps as sets. The card completes the client's schedule
+// the activity falls in, or a schedule created for it, so the trainer sees
+// it with the sessions they ran.
+func (s *workoutService) ImportWorkout(userID uint, data io.Reader) (*dto.WorkoutImportResponse, error) {
+	trainee, err := s.traineeRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if trainee.TrainerID == nil {
+		return nil, fmt.Errorf("%w: you have no assigned trainer", apperrors.ErrInvalidInput)
+	}
+
+	content, err := io.ReadAll(data)
+	if err != nil {
+		return nil, err
+	}
+	activity, err := workout.Parse(content)
+	if err != nil {
+		if errors.Is(err, workout.ErrInvalidFile) {
+			return nil, fmt.Errorf("%w: %v", apperrors.ErrInvalidInput, err)
+		}
+		return nil, err
+	}
+	if activity.StartTime.After(time.Now()) {
+		return nil, fmt.Errorf("%w: the activity starts in the future", apperrors.ErrInvalidInput)
+	}
+
+	importKey := workoutImportKey(activity)
+	imported, err := s.sessionCardRepo.ExistsByImportKey(trainee.ID, importKey)
+	if err != nil {
+		return nil, err
+	}
+	if imported {
+		return nil, fmt.Errorf("%w: this workout was already imported", apperrors.ErrAlreadyExists)
+	}
+
+	loc, err := time.LoadLocation(defaultTimezone)
+	if err != nil {
+		return nil, err
+	}
+	start := activity.StartTime.In(loc)
+	date := localDate(start, loc)
+
+	schedule, err := s.matchSchedule(trainee.ID, activity, date)
+	if err != nil {
+		return nil, err
+	}
+	matched := schedule != nil
+
+	sport := workoutSports[activity.Sport]
+	duration := int(math.Round(activity.Duration.Minutes()))
+	if duration < 1 {
+		duration = 1
+	}
+	if schedule == nil {
+		title := activity.Name
+		if title == "" {
+			title = sport
+		}
+		sessionType := "Cardio"
+		schedule = &models.Schedule{
+			TrainerID:   *trainee.TrainerID,
+			TraineeID:   trainee.ID,
+			Date:        date,
+			Time:        start.Format("15:04"),
+			Duration:    duration,
+			Title:       title,
+			SessionType: &sessionType,
+			Status:      "completed",
+		}
+	}
+
+	exercise := newWorkoutExercise(activity, sport, maxHeartRate(&trainee.User, activity))
+	if library, err := s.exerciseRepo.FindByName(schedule.TrainerID, sport); err == nil {
+		exercise.ExerciseLibraryID = &library.ID
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	source := activity.Format
+	card := &models.SessionCard{
+		TrainerID: schedule.TrainerID,
+		TraineeID: trainee.ID,
+		Date:      schedule.Date,
+		Title:     schedule.Title,
+		Duration:  duration,
+		Source:    &source,
+		ImportKey: &importKey,
+		Exercises: []models.SessionExercise{exercise},
+	}
+
+	err = database.Transaction(func(tx *gorm.DB) error {
+		scheduleRepo := repository.NewScheduleRepository(tx)
+		if !matched {
+			if err := scheduleRepo.Create(schedule); err != nil {
+				return err
+			}
+		}
+
+		card.ScheduleID = schedule.ID
+		card.RecalculateTotals()
+		if err := repository.NewSessionCardRepository(tx).Create(card); err != nil {
+			return err
+		}
+
+		schedule.Status = "completed"
+		schedule.SessionCardID = &card.ID
+		if err := scheduleRepo.UpdateColumns(schedule, completionColumns...); err != nil {
+			return err
+		}
+
+		if err := syncPersonalRecords(tx, card); err != nil {
+			return err
+		}
+		if err := repository.NewExerciseRepository(tx).RefreshUsage(cardExerciseIDs(card)); err != nil {
+			return err
+		}
+		return refreshScheduleProgress(tx, schedule)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	saved, err := s.sessionCardRepo.FindByID(card.ID)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &dto.WorkoutImportResponse{
+		Session:         toSessionCardResponse(saved, trainee.User.Units()),
+		Sport:           activity.Sport,
+		ScheduleID:      schedule.ID,
+		ScheduleMatched: matched,
+	}, nil
+}
+
+// matchSchedule picks the schedule an activity on date completes, if any
+func (s *workoutService) matchSchedule(traineeID uint, activity *workout.Activity, date time.Time) (*models.Schedule, error) {
+	schedules, err := s.scheduleRepo.FindByTraineeID(traineeID, map[string]interface{}{
+		"fromDate": date,
+		"toDate":   date,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pickSchedule(schedules, activity), nil
+}
+
+// pickSchedule returns the open schedule whose slot, give or take
+// scheduleMatchSlack, overlaps the activity; the closest start wins. When
+// none overlaps, the only open schedule is taken: the client trained that
+// day, just not at the planned time.
+func pickSchedule(schedules []models.Schedule, activity *workout.Activity) *models.Schedule {
+	end := activity.StartTime.Add(activity.Duration)
+	var best, open *models.Schedule
+	var bestGap time.Duration
+	openCount := 0
+	for i := range schedules {
+		schedule := &schedules[i]
+		if schedule.SessionCardID != nil || schedule.Status == "cancelled" || schedule.Status == "no_show" {
+			continue
+		}
+		open = schedule
+		openCount++
+
+		start, err := scheduleStart(schedule)
+		if err != nil {
+			continue
+		}
+		slotStart := start.Add(-scheduleMatchSlack)
+		slotEnd := start.Add(time.Duration(schedule.Duration)*time.Minute + scheduleMatchSlack)
+		if !activity.StartTime.Before(slotEnd) || !end.After(slotStart) {
+			continue
+		}
+		gap := activity.StartTime.Sub(start)
+		if gap < 0 {
+			gap = -gap
+		}
+		if best == nil || gap < bestGap {
+			best, bestGap = schedule, gap
+		}
+	}
+	if best == nil && openCount == 1 {
+		return open
+	}
+	return best
+}
+
+// newWorkoutExercise logs an activity as a cardio exercise with a set per lap
+func newWorkoutExercise(activity *workout.Activity, name string, maxHR int) models.SessionExercise {
+	category := "cardio"
+	exercise := models.SessionExercise{
+		Name:          name,
+		Category:      &category,
+		ExerciseOrder: 1,
+		AvgHeartRate:  heartRatePtr(activity.AvgHeartRate),
+		MaxHeartRate:  heartRatePtr(activity.MaxHeartRate),
+	}
+
+	zones := activity.HeartRateZones(maxHR)
+	var zoned time.Duration
+	for _, zone := range zones {
+		zoned += zone
+	}
+	if zoned > 0 {
+		exercise.HeartRateZones = make(pq.Int64Array, len(zones))
+		for i, zone := range zones {
+			exercise.HeartRateZones[i] = int64(math.Round(zone.Seconds()))
+		}
+	}
+
+	for _, lap := range activity.Laps {
+		if lap.Duration <= 0 && lap.Distance <= 0 {
+			continue
+		}
+		exercise.Sets = append(exercise.Sets, workoutSet(len(exercise.Sets)+1, lap.Duration, lap.Distance, lap.AvgHeartRate, lap.MaxHeartRate))
+	}
+	if len(exercise.Sets) == 0 {
+		exercise.Sets = append(exercise.Sets, workoutSet(1, activity.Duration, activity.Distance, activity.AvgHeartRate, activity.MaxHeartRate))
+	}
+	return exercise
+}
+
+// workoutSet is a completed set of a duration and a distance in meters
+func workoutSet(number int, duration time.Duration, distance float64, avgHR, maxHR int) models.ExerciseSet {
+	set := models.ExerciseSet{
+		SetNumber:    number,
+		AvgHeartRate: heartRatePtr(avgHR),
+		MaxHeartRate: heartRatePtr(maxHR),
+		Completed:    true,
+	}
+	if seconds := int(math.Round(duration.Seconds())); seconds > 0 {
+		set.Duration = &seconds
+	}
+	if km := roundUnitValue(distance / 1000); km > 0 {
+		set.Distance = &km
+	}
+	return set
+}
+
+func heartRatePtr(bpm int) *int {
+	if bpm <= 0 {
+		return nil
+	}
+	return &bpm
+}
+
+// maxHeartRate estimates the client's maximum heart rate as 220 minus their
+// age, raised to the activity's peak when they went above it. Without a date
+// of birth the peak is used.
+func maxHeartRate(user *models.User, activity *workout.Activity) int {
+	if user.DateOfBirth == nil {
+		return activity.MaxHeartRate
+	}
+	estimate := 220 - yearsOld(*user.DateOfBirth, activity.StartTime)
+	if activity.MaxHeartRate > estimate {
+		return activity.MaxHeartRate
+	}
+	return estimate
+}
+
+// yearsOld returns the age on at of someone born on birth
+func yearsOld(birth, at time.Time) int {
+	years := at.Year() - birth.Year()
+	if at.Month() < birth.Month() || (at.Month() == birth.Month() && at.Day() < birth.Day()) {
+		years--
+	}
+	return years
+}
+
+// workoutImportKey identifies an activity by when it started, which the
+// same workout exported in another format shares
+func workoutImportKey(activity *workout.Activity) string {
+	sum := sha256.Sum256([]byte("workout|" + activity.StartTime.UTC().Format(time.RFC3339)))
+	return hex.EncodeToString(sum[:])
+}
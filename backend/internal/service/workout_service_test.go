@@ -0,0 +1,54 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"fitness-training-backend/internal/models"
+	"fitness-training-backend/pkg/workout"
+)
+
+func TestPickSchedule(t *testing.T) {
+	loc, err := time.LoadLocation(defaultTimezone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := time.Date(2024, 3, 20, 0, 0, 0, 0, loc)
+	cardID := uint(9)
+	schedule := func(id uint, clock, status string) models.Schedule {
+		return models.Schedule{ID: id, Date: date, Time: clock, Duration: 60, Status: status}
+	}
+	activityAt := func(hour, minute int) *workout.Activity {
+		return &workout.Activity{StartTime: time.Date(2024, 3, 20, hour, minute, 0, 0, loc), Duration: 45 * time.Minute}
+	}
+	logged := schedule(4, "18:00", "completed")
+	logged.SessionCardID = &cardID
+
+	tests := []struct {
+		name      string
+		schedules []models.Schedule
+		activity  *workout.Activity
+		want      uint
+	}{
+		{"during the slot", []models.Schedule{schedule(1, "07:00", "confirmed")}, activityAt(7, 10), 1},
+		{"within the slack", []models.Schedule{schedule(1, "07:00", "confirmed")}, activityAt(8, 50), 1},
+		{"closest start wins", []models.Schedule{schedule(1, "07:00", "confirmed"), schedule(2, "08:00", "confirmed")}, activityAt(7, 50), 2},
+		{"only open schedule of the day", []models.Schedule{schedule(1, "07:00", "confirmed")}, activityAt(18, 0), 1},
+		{"several open schedules, none overlapping", []models.Schedule{schedule(1, "07:00", "confirmed"), schedule(2, "09:00", "scheduled")}, activityAt(18, 0), 0},
+		{"cancelled schedules are skipped", []models.Schedule{schedule(1, "07:00", "cancelled")}, activityAt(7, 0), 0},
+		{"logged schedules are skipped", []models.Schedule{logged}, activityAt(18, 0), 0},
+		{"closed schedules do not count against the fallback", []models.Schedule{schedule(1, "07:00", "confirmed"), schedule(2, "09:00", "no_show"), logged}, activityAt(18, 0), 1},
+		{"no schedules", nil, activityAt(7, 0), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got uint
+			if picked := pickSchedule(tt.schedules, tt.activity); picked != nil {
+				got = picked.ID
+			}
+			if got != tt.want {
+				t.Errorf("pickSchedule = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
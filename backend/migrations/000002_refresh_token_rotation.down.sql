@@ -0,0 +1,10 @@
+-- ==========================================
+-- Rollback Refresh Token Rotation
+-- ==========================================
+
+DROP INDEX IF EXISTS idx_refresh_tokens_family;
+
+ALTER TABLE refresh_tokens
+    DROP COLUMN IF EXISTS revoked_reason,
+    DROP COLUMN IF EXISTS replaced_by_id,
+    DROP COLUMN IF EXISTS family_id;
@@ -0,0 +1,20 @@
+-- ==========================================
+-- Refresh Token Rotation
+-- ==========================================
+
+-- Tokens are now stored as SHA-256 hashes of opaque values. Rows written
+-- before this migration hold raw JWTs, so they are revoked and users sign in again.
+ALTER TABLE refresh_tokens
+    ADD COLUMN family_id VARCHAR(64),
+    ADD COLUMN replaced_by_id INTEGER REFERENCES refresh_tokens(id) ON DELETE SET NULL,
+    ADD COLUMN revoked_reason VARCHAR(50); -- 'rotated', 'logout', 'signed_out', 'reuse_detected'
+
+UPDATE refresh_tokens
+SET family_id = id::TEXT,
+    is_revoked = TRUE,
+    revoked_at = COALESCE(revoked_at, CURRENT_TIMESTAMP),
+    revoked_reason = 'signed_out';
+
+ALTER TABLE refresh_tokens ALTER COLUMN family_id SET NOT NULL;
+
+CREATE INDEX idx_refresh_tokens_family ON refresh_tokens(user_id, family_id);
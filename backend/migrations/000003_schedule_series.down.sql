@@ -0,0 +1,14 @@
+-- ==========================================
+-- Rollback Recurring Schedule Series
+-- ==========================================
+
+DROP INDEX IF EXISTS idx_schedules_trainer_date;
+DROP INDEX IF EXISTS idx_schedules_series;
+
+ALTER TABLE schedules
+    DROP COLUMN IF EXISTS is_exception,
+    DROP COLUMN IF EXISTS occurrence_date,
+    DROP COLUMN IF EXISTS series_id;
+
+DROP TRIGGER IF EXISTS schedule_series_updated_at ON schedule_series;
+DROP TABLE IF EXISTS schedule_series;
@@ -0,0 +1,49 @@
+-- ==========================================
+-- Recurring Schedule Series
+-- ==========================================
+CREATE TABLE schedule_series (
+    id SERIAL PRIMARY KEY,
+    trainer_id INTEGER NOT NULL REFERENCES trainers(id) ON DELETE CASCADE,
+    trainee_id INTEGER NOT NULL REFERENCES trainees(id) ON DELETE CASCADE,
+    location_id INTEGER REFERENCES locations(id) ON DELETE SET NULL,
+    program_assignment_id INTEGER REFERENCES program_assignments(id) ON DELETE SET NULL,
+    
+    frequency VARCHAR(20) NOT NULL CHECK (frequency IN ('weekly', 'biweekly')),
+    weekdays INTEGER[] NOT NULL, -- 0 = Sunday ... 6 = Saturday
+    start_date DATE NOT NULL,
+    count INTEGER CHECK (count > 0),
+    until DATE,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'Asia/Bangkok',
+    
+    time TIME NOT NULL,
+    duration INTEGER NOT NULL CHECK (duration > 0),
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    session_type VARCHAR(50),
+    planned_exercises TEXT[],
+    
+    status VARCHAR(20) DEFAULT 'active' CHECK (status IN ('active', 'cancelled')),
+    
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    
+    CHECK (count IS NOT NULL OR until IS NOT NULL)
+);
+
+CREATE INDEX idx_schedule_series_trainer ON schedule_series(trainer_id);
+CREATE INDEX idx_schedule_series_trainee ON schedule_series(trainee_id);
+CREATE INDEX idx_schedule_series_status ON schedule_series(status);
+
+CREATE TRIGGER schedule_series_updated_at BEFORE UPDATE ON schedule_series FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Occurrences
+ALTER TABLE schedules
+    ADD COLUMN series_id INTEGER REFERENCES schedule_series(id) ON DELETE SET NULL,
+    ADD COLUMN occurrence_date DATE,
+    ADD COLUMN is_exception BOOLEAN DEFAULT FALSE;
+
+CREATE INDEX idx_schedules_series ON schedules(series_id, occurrence_date);
+
+-- Conflict checks look up a trainer's sessions by day
+CREATE INDEX idx_schedules_trainer_date ON schedules(trainer_id, date);
@@ -0,0 +1,9 @@
+-- ==========================================
+-- Rollback iCalendar Feeds & Import
+-- ==========================================
+
+DROP INDEX IF EXISTS idx_schedules_external_uid;
+ALTER TABLE schedules DROP COLUMN IF EXISTS external_uid;
+
+DROP TRIGGER IF EXISTS calendar_feeds_updated_at ON calendar_feeds;
+DROP TABLE IF EXISTS calendar_feeds;
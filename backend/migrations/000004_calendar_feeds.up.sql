@@ -0,0 +1,20 @@
+-- ==========================================
+-- iCalendar Feeds & Import
+-- ==========================================
+CREATE TABLE calendar_feeds (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER UNIQUE NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    
+    token VARCHAR(64) UNIQUE NOT NULL,
+    last_accessed_at TIMESTAMP,
+    
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER calendar_feeds_updated_at BEFORE UPDATE ON calendar_feeds FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- UID of the imported iCalendar event, so re-importing a file is a no-op
+ALTER TABLE schedules ADD COLUMN external_uid VARCHAR(255);
+
+CREATE INDEX idx_schedules_external_uid ON schedules(trainer_id, external_uid) WHERE external_uid IS NOT NULL;
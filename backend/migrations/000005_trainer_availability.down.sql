@@ -0,0 +1,13 @@
+-- ==========================================
+-- Rollback Trainer Availability & Booking Requests
+-- ==========================================
+
+DROP TRIGGER IF EXISTS booking_requests_updated_at ON booking_requests;
+DROP TRIGGER IF EXISTS trainer_time_off_updated_at ON trainer_time_off;
+DROP TRIGGER IF EXISTS trainer_availabilities_updated_at ON trainer_availabilities;
+
+DROP TABLE IF EXISTS booking_requests;
+DROP TABLE IF EXISTS trainer_time_off;
+DROP TABLE IF EXISTS trainer_availabilities;
+
+ALTER TABLE trainers DROP COLUMN IF EXISTS buffer_minutes;
@@ -0,0 +1,87 @@
+-- ==========================================
+-- Trainer Availability & Booking Requests
+-- ==========================================
+ALTER TABLE trainers ADD COLUMN buffer_minutes INTEGER DEFAULT 15 CHECK (buffer_minutes >= 0);
+
+CREATE TABLE trainer_availabilities (
+    id SERIAL PRIMARY KEY,
+    trainer_id INTEGER NOT NULL REFERENCES trainers(id) ON DELETE CASCADE,
+    location_id INTEGER REFERENCES locations(id) ON DELETE CASCADE, -- NULL = any location
+    
+    weekday INTEGER NOT NULL CHECK (weekday BETWEEN 0 AND 6), -- 0 = Sunday
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL,
+    
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    
+    CHECK (end_time > start_time)
+);
+
+CREATE INDEX idx_trainer_availabilities_trainer ON trainer_availabilities(trainer_id, weekday);
+
+CREATE TABLE trainer_time_off (
+    id SERIAL PRIMARY KEY,
+    trainer_id INTEGER NOT NULL REFERENCES trainers(id) ON DELETE CASCADE,
+    location_id INTEGER REFERENCES locations(id) ON DELETE CASCADE, -- NULL = all locations
+    
+    start_at TIMESTAMP NOT NULL,
+    end_at TIMESTAMP NOT NULL,
+    reason TEXT,
+    
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    
+    CHECK (end_at > start_at)
+);
+
+CREATE INDEX idx_trainer_time_off_range ON trainer_time_off(trainer_id, start_at, end_at);
+
+CREATE TABLE booking_requests (
+    id SERIAL PRIMARY KEY,
+    trainer_id INTEGER NOT NULL REFERENCES trainers(id) ON DELETE CASCADE,
+    trainee_id INTEGER NOT NULL REFERENCES trainees(id) ON DELETE CASCADE,
+    location_id INTEGER REFERENCES locations(id) ON DELETE SET NULL,
+    
+    date DATE NOT NULL,
+    time TIME NOT NULL,
+    duration INTEGER NOT NULL CHECK (duration > 0),
+    notes TEXT,
+    
+    status VARCHAR(20) DEFAULT 'pending' CHECK (status IN ('pending', 'confirmed', 'declined', 'cancelled')),
+    response_note TEXT,
+    responded_at TIMESTAMP,
+    schedule_id INTEGER REFERENCES schedules(id) ON DELETE SET NULL,
+    
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_booking_requests_trainer ON booking_requests(trainer_id, status);
+CREATE INDEX idx_booking_requests_trainee ON booking_requests(trainee_id, status);
+
+CREATE TRIGGER trainer_availabilities_updated_at BEFORE UPDATE ON trainer_availabilities FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER trainer_time_off_updated_at BEFORE UPDATE ON trainer_time_off FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER booking_requests_updated_at BEFORE UPDATE ON booking_requests FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Carry over the legacy working_hours JSON ({"monday": "09:00-18:00", ...}).
+-- The casts sit behind CASE so malformed values are skipped, not fatal.
+INSERT INTO trainer_availabilities (trainer_id, weekday, start_time, end_time)
+SELECT trainer_id, weekday, start_time, end_time
+FROM (
+    SELECT t.id AS trainer_id,
+           CASE lower(w.key)
+               WHEN 'sunday' THEN 0 WHEN 'monday' THEN 1 WHEN 'tuesday' THEN 2
+               WHEN 'wednesday' THEN 3 WHEN 'thursday' THEN 4 WHEN 'friday' THEN 5
+               WHEN 'saturday' THEN 6
+           END AS weekday,
+           CASE WHEN w.value ~ '^\s*([01]?\d|2[0-3]):[0-5]\d\s*-\s*([01]?\d|2[0-3]):[0-5]\d\s*$'
+               THEN trim(split_part(w.value, '-', 1))::TIME END AS start_time,
+           CASE WHEN w.value ~ '^\s*([01]?\d|2[0-3]):[0-5]\d\s*-\s*([01]?\d|2[0-3]):[0-5]\d\s*$'
+               THEN trim(split_part(w.value, '-', 2))::TIME END AS end_time
+    FROM trainers t, jsonb_each_text(t.working_hours) w
+    WHERE jsonb_typeof(t.working_hours) = 'object'
+) legacy
+WHERE weekday IS NOT NULL
+  AND start_time IS NOT NULL
+  AND end_time > start_time;
@@ -0,0 +1,5 @@
+-- ==========================================
+-- Rollback Personal Records
+-- ==========================================
+
+DROP TABLE IF EXISTS personal_records;
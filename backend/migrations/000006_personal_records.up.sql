@@ -0,0 +1,22 @@
+-- ==========================================
+-- Personal Records
+-- ==========================================
+CREATE TABLE personal_records (
+    id SERIAL PRIMARY KEY,
+    trainee_id INTEGER NOT NULL REFERENCES trainees(id) ON DELETE CASCADE,
+    exercise_library_id INTEGER NOT NULL REFERENCES exercise_library(id) ON DELETE CASCADE,
+    
+    record_type VARCHAR(30) NOT NULL CHECK (record_type IN ('max_weight', 'reps_at_weight', 'estimated_1rm', 'max_distance', 'best_pace')),
+    value DECIMAL(10,2) NOT NULL, -- kg, reps, km or seconds per km
+    weight DECIMAL(6,2) DEFAULT 0,
+    reps INTEGER,
+    
+    session_card_id INTEGER NOT NULL REFERENCES session_cards(id) ON DELETE CASCADE,
+    session_exercise_id INTEGER NOT NULL REFERENCES session_exercises(id) ON DELETE CASCADE,
+    achieved_at DATE NOT NULL,
+    
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One record per type, and per weight for rep records
+CREATE UNIQUE INDEX idx_personal_records_unique ON personal_records(trainee_id, exercise_library_id, record_type, weight);
@@ -0,0 +1,6 @@
+-- ==========================================
+-- Rollback Session Totals
+-- ==========================================
+
+ALTER TABLE session_exercises DROP COLUMN IF EXISTS total_distance;
+ALTER TABLE session_exercises DROP COLUMN IF EXISTS total_duration;
@@ -0,0 +1,7 @@
+-- ==========================================
+-- Session Totals (server-side)
+-- ==========================================
+-- Totals are derived from exercise_sets by the API. Existing cards are
+-- recomputed with: go run ./cmd/backfill-totals
+ALTER TABLE session_exercises ADD COLUMN total_duration INTEGER DEFAULT 0; -- seconds
+ALTER TABLE session_exercises ADD COLUMN total_distance DECIMAL(10,2) DEFAULT 0.00; -- km
@@ -0,0 +1,8 @@
+-- ==========================================
+-- Rollback Weekly Consistency
+-- ==========================================
+
+ALTER TABLE trainees DROP COLUMN IF EXISTS average_sessions_per_week;
+ALTER TABLE trainees DROP COLUMN IF EXISTS longest_weekly_streak;
+ALTER TABLE trainees DROP COLUMN IF EXISTS current_weekly_streak;
+ALTER TABLE trainees DROP COLUMN IF EXISTS weekly_streak_target;
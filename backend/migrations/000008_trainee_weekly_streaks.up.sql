@@ -0,0 +1,10 @@
+-- ==========================================
+-- Weekly Consistency (cached on trainees)
+-- ==========================================
+-- Maintained by the stats engine (internal/stats) whenever a schedule is
+-- completed, cancelled or marked no_show. current_streak/longest_streak stay
+-- as the daily streak.
+ALTER TABLE trainees ADD COLUMN weekly_streak_target INTEGER DEFAULT 1 CHECK (weekly_streak_target >= 1);
+ALTER TABLE trainees ADD COLUMN current_weekly_streak INTEGER DEFAULT 0;
+ALTER TABLE trainees ADD COLUMN longest_weekly_streak INTEGER DEFAULT 0;
+ALTER TABLE trainees ADD COLUMN average_sessions_per_week DECIMAL(5,2) DEFAULT 0.00;
@@ -0,0 +1,8 @@
+-- ==========================================
+-- Rollback Achievement Rules
+-- ==========================================
+
+DROP INDEX IF EXISTS idx_achievements_trainee_rule;
+ALTER TABLE achievements DROP COLUMN IF EXISTS rule_key;
+
+DROP TABLE IF EXISTS achievement_rules;
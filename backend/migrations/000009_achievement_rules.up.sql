@@ -0,0 +1,37 @@
+-- ==========================================
+-- Achievement Rules
+-- ==========================================
+-- Built-in rules are defined in code (service/achievements.go); this table
+-- holds the ones trainers define for their own clients.
+CREATE TABLE achievement_rules (
+    id SERIAL PRIMARY KEY,
+    trainer_id INTEGER NOT NULL REFERENCES trainers(id) ON DELETE CASCADE,
+    trainee_id INTEGER REFERENCES trainees(id) ON DELETE CASCADE, -- NULL = all of the trainer's clients
+    
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    metric VARCHAR(30) NOT NULL CHECK (metric IN ('completed_sessions', 'daily_streak', 'weekly_streak', 'total_hours', 'programs_completed', 'pr_count', 'weight_lost')),
+    threshold DECIMAL(10,2) NOT NULL CHECK (threshold > 0),
+    exercise_library_id INTEGER REFERENCES exercise_library(id) ON DELETE CASCADE,
+    
+    badge_icon TEXT,
+    badge_color VARCHAR(7),
+    
+    is_active BOOLEAN DEFAULT TRUE,
+    
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX idx_achievement_rules_trainer ON achievement_rules(trainer_id);
+CREATE INDEX idx_achievement_rules_trainee ON achievement_rules(trainee_id);
+CREATE INDEX idx_achievement_rules_deleted_at ON achievement_rules(deleted_at);
+
+CREATE TRIGGER achievement_rules_updated_at BEFORE UPDATE ON achievement_rules FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- ==========================================
+-- Awarded rules (each rule at most once per trainee)
+-- ==========================================
+ALTER TABLE achievements ADD COLUMN rule_key VARCHAR(50); -- 'sessions_10', 'custom_<rule id>'; NULL for PRs
+CREATE UNIQUE INDEX idx_achievements_trainee_rule ON achievements(trainee_id, rule_key);
@@ -0,0 +1,6 @@
+-- ==========================================
+-- Rollback Session Reminders
+-- ==========================================
+
+DROP INDEX IF EXISTS idx_schedules_pending_date;
+ALTER TABLE schedules DROP COLUMN IF EXISTS reminder_leads_sent;
@@ -0,0 +1,9 @@
+-- ==========================================
+-- Session Reminders
+-- ==========================================
+-- The reminder dispatcher records which lead times (in minutes, e.g. 1440
+-- and 120) a session was reminded for; claiming one is a single UPDATE so
+-- API replicas never send the same reminder twice.
+ALTER TABLE schedules ADD COLUMN reminder_leads_sent INTEGER[];
+
+CREATE INDEX idx_schedules_pending_date ON schedules(date) WHERE status IN ('scheduled', 'confirmed') AND deleted_at IS NULL;
@@ -0,0 +1,9 @@
+-- ==========================================
+-- Rollback Notification Delivery Outbox
+-- ==========================================
+
+DROP INDEX IF EXISTS idx_users_line_user_id;
+ALTER TABLE users DROP COLUMN IF EXISTS line_user_id;
+
+DROP TABLE IF EXISTS push_subscriptions;
+DROP TABLE IF EXISTS notification_deliveries;
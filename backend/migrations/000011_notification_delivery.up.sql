@@ -0,0 +1,54 @@
+-- ==========================================
+-- Notification Delivery Outbox
+-- ==========================================
+-- One row per notification and external channel, written in the same
+-- transaction as the notification. The delivery worker claims due rows with
+-- FOR UPDATE SKIP LOCKED, sends them and retries failures with backoff.
+CREATE TABLE notification_deliveries (
+    id SERIAL PRIMARY KEY,
+    notification_id INTEGER NOT NULL REFERENCES notifications(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel VARCHAR(20) NOT NULL CHECK (channel IN ('email', 'push', 'line')),
+    
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sending', 'sent', 'failed', 'skipped')),
+    attempts INTEGER DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    sent_at TIMESTAMP,
+    
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_notification_deliveries_channel ON notification_deliveries(notification_id, channel);
+CREATE INDEX idx_notification_deliveries_user ON notification_deliveries(user_id);
+CREATE INDEX idx_notification_deliveries_due ON notification_deliveries(status, next_attempt_at) WHERE status IN ('pending', 'sending');
+
+CREATE TRIGGER notification_deliveries_updated_at BEFORE UPDATE ON notification_deliveries FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- ==========================================
+-- Web Push Subscriptions
+-- ==========================================
+CREATE TABLE push_subscriptions (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    
+    endpoint TEXT NOT NULL UNIQUE,
+    p256dh VARCHAR(255) NOT NULL,
+    auth VARCHAR(255) NOT NULL,
+    
+    user_agent TEXT,
+    
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_push_subscriptions_user ON push_subscriptions(user_id);
+
+CREATE TRIGGER push_subscriptions_updated_at BEFORE UPDATE ON push_subscriptions FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- ==========================================
+-- LINE accounts
+-- ==========================================
+ALTER TABLE users ADD COLUMN line_user_id VARCHAR(64);
+CREATE UNIQUE INDEX idx_users_line_user_id ON users(line_user_id);
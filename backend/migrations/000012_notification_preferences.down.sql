@@ -0,0 +1,13 @@
+-- ==========================================
+-- Rollback Notification Preferences
+-- ==========================================
+
+DROP INDEX IF EXISTS idx_notification_deliveries_digest;
+UPDATE notification_deliveries SET status = 'pending', next_attempt_at = CURRENT_TIMESTAMP WHERE status = 'digest';
+UPDATE notification_deliveries SET status = 'skipped' WHERE status = 'digested';
+ALTER TABLE notification_deliveries DROP CONSTRAINT notification_deliveries_status_check;
+ALTER TABLE notification_deliveries ADD CONSTRAINT notification_deliveries_status_check
+    CHECK (status IN ('pending', 'sending', 'sent', 'failed', 'skipped'));
+
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS notification_settings;
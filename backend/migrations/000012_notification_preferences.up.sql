@@ -0,0 +1,56 @@
+-- ==========================================
+-- Notification Preferences
+-- ==========================================
+-- One settings row per user (quiet hours, daily digest) and one preference
+-- row per notification type switching its channels. Users without rows get
+-- everything, everywhere, at any time.
+CREATE TABLE notification_settings (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    
+    timezone VARCHAR(50) NOT NULL DEFAULT 'Asia/Bangkok',
+    
+    quiet_hours_start VARCHAR(5), -- HH:MM, may wrap midnight
+    quiet_hours_end VARCHAR(5),
+    
+    daily_digest BOOLEAN NOT NULL DEFAULT FALSE,
+    digest_time VARCHAR(5) NOT NULL DEFAULT '08:00',
+    last_digest_at TIMESTAMP,
+    
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    
+    CHECK ((quiet_hours_start IS NULL) = (quiet_hours_end IS NULL))
+);
+
+CREATE INDEX idx_notification_settings_digest ON notification_settings(id) WHERE daily_digest = TRUE;
+
+CREATE TRIGGER notification_settings_updated_at BEFORE UPDATE ON notification_settings FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE notification_preferences (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('schedule', 'progress', 'achievement', 'system', 'message')),
+    
+    in_app BOOLEAN NOT NULL DEFAULT TRUE,
+    email BOOLEAN NOT NULL DEFAULT TRUE,
+    push BOOLEAN NOT NULL DEFAULT TRUE,
+    line BOOLEAN NOT NULL DEFAULT TRUE,
+    
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_notification_preferences_type ON notification_preferences(user_id, type);
+
+CREATE TRIGGER notification_preferences_updated_at BEFORE UPDATE ON notification_preferences FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- ==========================================
+-- Digest delivery statuses
+-- ==========================================
+-- 'digest': held for the user's daily digest; 'digested': included in one
+ALTER TABLE notification_deliveries DROP CONSTRAINT notification_deliveries_status_check;
+ALTER TABLE notification_deliveries ADD CONSTRAINT notification_deliveries_status_check
+    CHECK (status IN ('pending', 'sending', 'sent', 'failed', 'skipped', 'digest', 'digested'));
+
+CREATE INDEX idx_notification_deliveries_digest ON notification_deliveries(user_id) WHERE status = 'digest';
@@ -0,0 +1,7 @@
+-- ==========================================
+-- Rollback Trainer <-> Trainee Messaging
+-- ==========================================
+
+DROP TABLE IF EXISTS message_attachments;
+DROP TABLE IF EXISTS messages;
+DROP TABLE IF EXISTS conversations;
@@ -0,0 +1,58 @@
+-- ==========================================
+-- Trainer <-> Trainee Messaging
+-- ==========================================
+-- One conversation per trainer-trainee pair. Messages can refer to the
+-- schedule or session card they are about and carry attachments (URLs of
+-- uploaded files). read_at is the recipient's read receipt.
+CREATE TABLE conversations (
+    id SERIAL PRIMARY KEY,
+    trainer_id INTEGER NOT NULL REFERENCES trainers(id) ON DELETE CASCADE,
+    trainee_id INTEGER NOT NULL REFERENCES trainees(id) ON DELETE CASCADE,
+    
+    last_message_at TIMESTAMP,
+    
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_conversations_pair ON conversations(trainer_id, trainee_id);
+CREATE INDEX idx_conversations_trainee_id ON conversations(trainee_id);
+CREATE INDEX idx_conversations_last_message_at ON conversations(last_message_at);
+
+CREATE TRIGGER conversations_updated_at BEFORE UPDATE ON conversations FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE messages (
+    id SERIAL PRIMARY KEY,
+    conversation_id INTEGER NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    sender_user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    
+    schedule_id INTEGER REFERENCES schedules(id) ON DELETE SET NULL,
+    session_card_id INTEGER REFERENCES session_cards(id) ON DELETE SET NULL,
+    
+    read_at TIMESTAMP,
+    
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Cursor pagination walks (conversation_id, id)
+CREATE INDEX idx_messages_conversation_id ON messages(conversation_id, id);
+CREATE INDEX idx_messages_unread ON messages(conversation_id, sender_user_id) WHERE read_at IS NULL;
+CREATE INDEX idx_messages_schedule_id ON messages(schedule_id);
+CREATE INDEX idx_messages_session_card_id ON messages(session_card_id);
+
+CREATE TRIGGER messages_updated_at BEFORE UPDATE ON messages FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE message_attachments (
+    id SERIAL PRIMARY KEY,
+    message_id INTEGER NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100),
+    size_bytes BIGINT CHECK (size_bytes >= 0),
+    
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_message_attachments_message_id ON message_attachments(message_id);
@@ -0,0 +1,42 @@
+-- ==========================================
+-- Rollback Typed Program Plans
+-- ==========================================
+
+ALTER TABLE programs ADD COLUMN weekly_schedule JSONB;
+ALTER TABLE program_assignments ADD COLUMN progress_notes JSONB;
+
+-- Week 1 stands in for the whole program; exercises and sets have no JSON
+-- equivalent and are lost
+UPDATE programs p SET weekly_schedule = (
+    SELECT jsonb_agg(jsonb_build_object(
+        'day', CASE d.weekday
+            WHEN 0 THEN 'Sunday'
+            WHEN 1 THEN 'Monday'
+            WHEN 2 THEN 'Tuesday'
+            WHEN 3 THEN 'Wednesday'
+            WHEN 4 THEN 'Thursday'
+            WHEN 5 THEN 'Friday'
+            WHEN 6 THEN 'Saturday'
+        END,
+        'focus', d.name,
+        'duration', d.duration
+    ) ORDER BY d.day)
+    FROM program_days d
+    WHERE d.program_id = p.id AND d.week = 1
+);
+
+UPDATE program_assignments a SET progress_notes = (
+    SELECT jsonb_agg(jsonb_build_object(
+        'week', n.week,
+        'date', to_char(n.date, 'YYYY-MM-DD'),
+        'note', n.note,
+        'recordedBy', n.recorded_by
+    ) ORDER BY n.date, n.id)
+    FROM program_progress_notes n
+    WHERE n.program_assignment_id = a.id
+);
+
+DROP TABLE IF EXISTS program_progress_notes;
+DROP TABLE IF EXISTS prescribed_sets;
+DROP TABLE IF EXISTS planned_exercises;
+DROP TABLE IF EXISTS program_days;
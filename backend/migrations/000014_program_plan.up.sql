@@ -0,0 +1,140 @@
+-- ==========================================
+-- Typed Program Plans
+-- ==========================================
+-- Replaces programs.weekly_schedule and program_assignments.progress_notes
+-- (free-form JSONB) with tables: a program has days per week, each day has
+-- exercises from the library and each exercise has prescribed sets.
+CREATE TABLE program_days (
+    id SERIAL PRIMARY KEY,
+    program_id INTEGER NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+    week INTEGER NOT NULL CHECK (week > 0),
+    day INTEGER NOT NULL CHECK (day > 0),
+    
+    name VARCHAR(255) NOT NULL,
+    weekday INTEGER CHECK (weekday BETWEEN 0 AND 6),
+    duration INTEGER NOT NULL CHECK (duration > 0),
+    notes TEXT,
+    
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_program_days_slot ON program_days(program_id, week, day);
+
+CREATE TRIGGER program_days_updated_at BEFORE UPDATE ON program_days FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE planned_exercises (
+    id SERIAL PRIMARY KEY,
+    program_day_id INTEGER NOT NULL REFERENCES program_days(id) ON DELETE CASCADE,
+    exercise_library_id INTEGER NOT NULL REFERENCES exercise_library(id),
+    order_index INTEGER NOT NULL,
+    
+    notes TEXT,
+    rest_duration INTEGER CHECK (rest_duration >= 0)
+);
+
+CREATE INDEX idx_planned_exercises_program_day_id ON planned_exercises(program_day_id);
+CREATE INDEX idx_planned_exercises_exercise_library_id ON planned_exercises(exercise_library_id);
+
+CREATE TABLE prescribed_sets (
+    id SERIAL PRIMARY KEY,
+    planned_exercise_id INTEGER NOT NULL REFERENCES planned_exercises(id) ON DELETE CASCADE,
+    set_number INTEGER NOT NULL,
+    
+    -- Volume
+    reps INTEGER CHECK (reps > 0),
+    reps_max INTEGER CHECK (reps_max >= reps),
+    duration INTEGER CHECK (duration > 0),
+    distance DECIMAL(6,2) CHECK (distance > 0),
+    
+    -- Intensity
+    weight DECIMAL(6,2) CHECK (weight >= 0),
+    percent_one_rm DECIMAL(5,2) CHECK (percent_one_rm > 0),
+    rpe DECIMAL(3,1) CHECK (rpe BETWEEN 1 AND 10),
+    
+    CONSTRAINT prescribed_sets_volume CHECK (reps IS NOT NULL OR duration IS NOT NULL OR distance IS NOT NULL),
+    CONSTRAINT prescribed_sets_load CHECK (weight IS NULL OR percent_one_rm IS NULL)
+);
+
+CREATE INDEX idx_prescribed_sets_planned_exercise_id ON prescribed_sets(planned_exercise_id);
+
+CREATE TABLE program_progress_notes (
+    id SERIAL PRIMARY KEY,
+    program_assignment_id INTEGER NOT NULL REFERENCES program_assignments(id) ON DELETE CASCADE,
+    week INTEGER,
+    date DATE NOT NULL,
+    note TEXT NOT NULL,
+    
+    recorded_by_user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    recorded_by VARCHAR(255) NOT NULL,
+    
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_program_progress_notes_program_assignment_id ON program_progress_notes(program_assignment_id);
+
+-- ==========================================
+-- Convert existing JSONB content
+-- ==========================================
+-- weekly_schedule: [{"day": "Monday", "focus": "Upper Body - Push", "duration": 60}]
+-- The list described every week, so it is repeated for each week of the
+-- program; entries beyond sessions_per_week are dropped.
+CREATE FUNCTION pg_temp.try_date(value TEXT) RETURNS DATE AS $$
+BEGIN
+    RETURN value::DATE;
+EXCEPTION WHEN others THEN
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+INSERT INTO program_days (program_id, week, day, name, weekday, duration)
+SELECT
+    p.id,
+    w.week,
+    e.ord,
+    COALESCE(NULLIF(btrim(e.item->>'focus'), ''), 'Day ' || e.ord),
+    CASE left(lower(btrim(e.item->>'day')), 3)
+        WHEN 'sun' THEN 0
+        WHEN 'mon' THEN 1
+        WHEN 'tue' THEN 2
+        WHEN 'wed' THEN 3
+        WHEN 'thu' THEN 4
+        WHEN 'fri' THEN 5
+        WHEN 'sat' THEN 6
+    END,
+    CASE
+        WHEN (e.item->>'duration') ~ '^[0-9]{1,3}$' AND (e.item->>'duration')::INTEGER > 0
+            THEN (e.item->>'duration')::INTEGER
+        ELSE 60
+    END
+FROM programs p
+CROSS JOIN LATERAL jsonb_array_elements(
+    CASE WHEN jsonb_typeof(p.weekly_schedule) = 'array' THEN p.weekly_schedule ELSE '[]'::JSONB END
+) WITH ORDINALITY AS e(item, ord)
+CROSS JOIN LATERAL generate_series(1, p.total_weeks) AS w(week)
+WHERE jsonb_typeof(e.item) = 'object'
+  AND e.ord <= p.sessions_per_week;
+
+-- progress_notes: [{"week": 4, "date": "2026-01-05", "note": "...", "recordedBy": "..."}]
+-- Unreadable dates fall back to the assignment's creation date and a missing
+-- author to the program's trainer.
+INSERT INTO program_progress_notes (program_assignment_id, week, date, note, recorded_by, created_at)
+SELECT
+    a.id,
+    CASE WHEN (n.item->>'week') ~ '^[0-9]{1,4}$' THEN (n.item->>'week')::INTEGER END,
+    COALESCE(pg_temp.try_date(left(n.item->>'date', 10)), a.created_at::DATE),
+    btrim(n.item->>'note'),
+    COALESCE(NULLIF(btrim(n.item->>'recordedBy'), ''), u.name),
+    COALESCE(pg_temp.try_date(left(n.item->>'date', 10))::TIMESTAMP, a.created_at)
+FROM program_assignments a
+JOIN programs p ON p.id = a.program_id
+JOIN trainers t ON t.id = p.trainer_id
+JOIN users u ON u.id = t.user_id
+CROSS JOIN LATERAL jsonb_array_elements(
+    CASE WHEN jsonb_typeof(a.progress_notes) = 'array' THEN a.progress_notes ELSE '[]'::JSONB END
+) AS n(item)
+WHERE jsonb_typeof(n.item) = 'object'
+  AND NULLIF(btrim(n.item->>'note'), '') IS NOT NULL;
+
+ALTER TABLE programs DROP COLUMN weekly_schedule;
+ALTER TABLE program_assignments DROP COLUMN progress_notes;
@@ -0,0 +1,16 @@
+-- ==========================================
+-- Rollback Program Schedule Generation & Adherence
+-- ==========================================
+
+DROP INDEX IF EXISTS idx_schedules_program_day_id;
+ALTER TABLE schedules DROP COLUMN IF EXISTS program_day_id;
+
+DROP INDEX IF EXISTS idx_program_assignments_behind_plan;
+ALTER TABLE program_assignments DROP COLUMN IF EXISTS behind_plan_since;
+ALTER TABLE program_assignments DROP COLUMN IF EXISTS behind_plan;
+ALTER TABLE program_assignments DROP COLUMN IF EXISTS adherence_rate;
+
+ALTER TABLE programs DROP COLUMN IF EXISTS adherence_threshold;
+
+ALTER TABLE trainees DROP COLUMN IF EXISTS preferred_time;
+ALTER TABLE trainees DROP COLUMN IF EXISTS preferred_weekdays;
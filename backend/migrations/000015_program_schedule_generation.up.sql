@@ -0,0 +1,22 @@
+-- ==========================================
+-- Program Schedule Generation & Adherence
+-- ==========================================
+-- Assigning a program lays out its sessions as schedules, linked to the
+-- program day they come from, on the client's preferred weekdays and time.
+-- Assignments track adherence (completed / due sessions) and are flagged
+-- when it drops below the program's threshold.
+ALTER TABLE trainees ADD COLUMN preferred_weekdays INTEGER[];
+ALTER TABLE trainees ADD COLUMN preferred_time TIME;
+
+ALTER TABLE programs ADD COLUMN adherence_threshold DECIMAL(5,2) NOT NULL DEFAULT 70
+    CHECK (adherence_threshold BETWEEN 0 AND 100);
+
+ALTER TABLE program_assignments ADD COLUMN adherence_rate DECIMAL(5,2);
+ALTER TABLE program_assignments ADD COLUMN behind_plan BOOLEAN DEFAULT FALSE;
+ALTER TABLE program_assignments ADD COLUMN behind_plan_since DATE;
+
+CREATE INDEX idx_program_assignments_behind_plan ON program_assignments(behind_plan) WHERE behind_plan;
+
+ALTER TABLE schedules ADD COLUMN program_day_id INTEGER REFERENCES program_days(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_schedules_program_day_id ON schedules(program_day_id);
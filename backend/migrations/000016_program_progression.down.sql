@@ -0,0 +1,6 @@
+-- ==========================================
+-- Rollback Progressive Overload Suggestions
+-- ==========================================
+
+ALTER TABLE programs DROP COLUMN IF EXISTS progression_increment;
+ALTER TABLE programs DROP COLUMN IF EXISTS progression_scheme;
@@ -0,0 +1,10 @@
+-- ==========================================
+-- Progressive Overload Suggestions
+-- ==========================================
+-- A program picks how the loads of its next sessions are suggested from the
+-- trainee's history: double progression (by increment once a rep range is
+-- mastered), RPE autoregulation or a percentage of the estimated 1RM.
+ALTER TABLE programs ADD COLUMN progression_scheme VARCHAR(20) NOT NULL DEFAULT 'double_progression'
+    CHECK (progression_scheme IN ('double_progression', 'rpe', 'percent_1rm'));
+ALTER TABLE programs ADD COLUMN progression_increment DECIMAL(5,2) NOT NULL DEFAULT 2.5
+    CHECK (progression_increment > 0);
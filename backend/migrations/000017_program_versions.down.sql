@@ -0,0 +1,22 @@
+-- ==========================================
+-- Rollback Program Versions
+-- ==========================================
+
+-- Only version 1 fits the old schema: the program row keeps its newest
+-- content, but the days of later versions are lost and their assignments
+-- fall back to version 1's plan
+DELETE FROM program_days d
+USING program_versions v
+WHERE v.id = d.program_version_id AND v.version > 1;
+
+DROP INDEX IF EXISTS idx_program_assignments_program_version_id;
+ALTER TABLE program_assignments DROP COLUMN program_version_id;
+
+DROP INDEX IF EXISTS idx_program_days_version_slot;
+DROP INDEX IF EXISTS idx_program_days_program_id;
+ALTER TABLE program_days DROP COLUMN program_version_id;
+CREATE UNIQUE INDEX idx_program_days_slot ON program_days(program_id, week, day);
+
+ALTER TABLE programs DROP COLUMN version;
+
+DROP TABLE IF EXISTS program_versions;
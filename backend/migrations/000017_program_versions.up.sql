@@ -0,0 +1,71 @@
+-- ==========================================
+-- Program Versions
+-- ==========================================
+-- A program's content and weekly plan now belong to numbered versions.
+-- Assignments are pinned to the version they started on: once a version is
+-- assigned, editing the program copies it into a new version instead of
+-- changing what clients already follow. The program row mirrors its newest
+-- version.
+CREATE TABLE program_versions (
+    id SERIAL PRIMARY KEY,
+    program_id INTEGER NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL CHECK (version > 0),
+    
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    
+    total_weeks INTEGER NOT NULL CHECK (total_weeks > 0),
+    sessions_per_week INTEGER NOT NULL CHECK (sessions_per_week > 0),
+    
+    goals TEXT[],
+    target_fitness_level VARCHAR(20) CHECK (target_fitness_level IN ('beginner', 'intermediate', 'advanced')),
+    
+    adherence_threshold DECIMAL(5,2) NOT NULL DEFAULT 70 CHECK (adherence_threshold BETWEEN 0 AND 100),
+    progression_scheme VARCHAR(20) NOT NULL DEFAULT 'double_progression'
+        CHECK (progression_scheme IN ('double_progression', 'rpe', 'percent_1rm')),
+    progression_increment DECIMAL(5,2) NOT NULL DEFAULT 2.5 CHECK (progression_increment > 0),
+    
+    total_assignments INTEGER DEFAULT 0,
+    completion_rate DECIMAL(5,2) DEFAULT 0.00,
+    
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_program_versions_number ON program_versions(program_id, version);
+
+CREATE TRIGGER program_versions_updated_at BEFORE UPDATE ON program_versions FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Every existing program becomes version 1 of itself
+INSERT INTO program_versions (
+    program_id, version, name, description, total_weeks, sessions_per_week, goals, target_fitness_level,
+    adherence_threshold, progression_scheme, progression_increment, total_assignments, completion_rate,
+    created_at, updated_at
+)
+SELECT
+    id, 1, name, description, total_weeks, sessions_per_week, goals, target_fitness_level,
+    adherence_threshold, progression_scheme, progression_increment, total_assignments, completion_rate,
+    created_at, updated_at
+FROM programs;
+
+ALTER TABLE programs ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+-- Plan days belong to a version
+ALTER TABLE program_days ADD COLUMN program_version_id INTEGER REFERENCES program_versions(id) ON DELETE CASCADE;
+UPDATE program_days d SET program_version_id = v.id
+FROM program_versions v
+WHERE v.program_id = d.program_id AND v.version = 1;
+ALTER TABLE program_days ALTER COLUMN program_version_id SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_program_days_slot;
+CREATE INDEX idx_program_days_program_id ON program_days(program_id);
+CREATE UNIQUE INDEX idx_program_days_version_slot ON program_days(program_version_id, week, day);
+
+-- Assignments are pinned to a version
+ALTER TABLE program_assignments ADD COLUMN program_version_id INTEGER REFERENCES program_versions(id);
+UPDATE program_assignments a SET program_version_id = v.id
+FROM program_versions v
+WHERE v.program_id = a.program_id AND v.version = 1;
+ALTER TABLE program_assignments ALTER COLUMN program_version_id SET NOT NULL;
+
+CREATE INDEX idx_program_assignments_program_version_id ON program_assignments(program_version_id);
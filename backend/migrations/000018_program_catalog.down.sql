@@ -0,0 +1,12 @@
+-- ==========================================
+-- Rollback Program Catalog
+-- ==========================================
+
+DROP INDEX IF EXISTS idx_programs_cloned_from_id;
+DROP INDEX IF EXISTS idx_programs_visibility;
+
+ALTER TABLE programs DROP COLUMN clone_count;
+ALTER TABLE programs DROP COLUMN original_author_id;
+ALTER TABLE programs DROP COLUMN cloned_from_version;
+ALTER TABLE programs DROP COLUMN cloned_from_id;
+ALTER TABLE programs DROP COLUMN visibility;
@@ -0,0 +1,16 @@
+-- ==========================================
+-- Program Catalog
+-- ==========================================
+-- Active programs can be shared with every trainer of the gym or published
+-- to anyone, and cloned into another trainer's account. A clone remembers
+-- the program and version it was copied from and credits the author of the
+-- original program of the chain.
+ALTER TABLE programs ADD COLUMN visibility VARCHAR(10) NOT NULL DEFAULT 'private'
+    CHECK (visibility IN ('private', 'gym', 'public'));
+ALTER TABLE programs ADD COLUMN cloned_from_id INTEGER REFERENCES programs(id) ON DELETE SET NULL;
+ALTER TABLE programs ADD COLUMN cloned_from_version INTEGER;
+ALTER TABLE programs ADD COLUMN original_author_id INTEGER REFERENCES trainers(id) ON DELETE SET NULL;
+ALTER TABLE programs ADD COLUMN clone_count INTEGER DEFAULT 0;
+
+CREATE INDEX idx_programs_visibility ON programs(visibility);
+CREATE INDEX idx_programs_cloned_from_id ON programs(cloned_from_id);
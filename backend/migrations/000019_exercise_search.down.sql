@@ -0,0 +1,13 @@
+-- ==========================================
+-- Rollback Exercise Search
+-- ==========================================
+
+DROP INDEX IF EXISTS idx_exercise_library_usage_count;
+DROP INDEX IF EXISTS idx_exercise_library_equipment;
+DROP INDEX IF EXISTS idx_exercise_library_muscle_groups;
+DROP INDEX IF EXISTS idx_exercise_library_search;
+
+DROP FUNCTION IF EXISTS search_normalize(TEXT);
+
+DROP EXTENSION IF EXISTS unaccent;
+DROP EXTENSION IF EXISTS pg_trgm;
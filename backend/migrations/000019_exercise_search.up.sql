@@ -0,0 +1,35 @@
+-- ==========================================
+-- Exercise Search
+-- ==========================================
+-- Exercises are searched by words anywhere in their name or description,
+-- ignoring case and accents, with trigram similarity for typos. Substring
+-- matching also covers Thai names, which have no spaces between words.
+-- usage_count now counts the session exercises logged with an exercise plus
+-- its planned exercises in the newest version of each program.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+-- unaccent() is only STABLE, so index expressions go through this wrapper
+-- with the dictionary spelled out
+CREATE OR REPLACE FUNCTION search_normalize(value TEXT)
+RETURNS TEXT AS $$
+    SELECT lower(public.unaccent('public.unaccent'::regdictionary, value))
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE;
+
+CREATE INDEX idx_exercise_library_search ON exercise_library
+    USING GIN (search_normalize(name || ' ' || COALESCE(description, '')) gin_trgm_ops);
+CREATE INDEX idx_exercise_library_muscle_groups ON exercise_library USING GIN (muscle_groups);
+CREATE INDEX idx_exercise_library_equipment ON exercise_library USING GIN (equipment);
+CREATE INDEX idx_exercise_library_usage_count ON exercise_library(usage_count DESC);
+
+UPDATE exercise_library e SET usage_count = (
+    SELECT COUNT(*) FROM session_exercises se
+    JOIN session_cards c ON c.id = se.session_card_id AND c.deleted_at IS NULL
+    WHERE se.exercise_library_id = e.id
+) + (
+    SELECT COUNT(*) FROM planned_exercises pe
+    JOIN program_days d ON d.id = pe.program_day_id
+    JOIN program_versions v ON v.id = d.program_version_id
+    JOIN programs p ON p.id = v.program_id AND p.version = v.version AND p.deleted_at IS NULL
+    WHERE pe.exercise_library_id = e.id
+);
@@ -0,0 +1,5 @@
+-- ==========================================
+-- Rollback Media Files
+-- ==========================================
+
+DROP TABLE IF EXISTS media_files;
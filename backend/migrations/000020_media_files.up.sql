@@ -0,0 +1,26 @@
+-- ==========================================
+-- Media Files
+-- ==========================================
+-- Uploaded files live in media storage (a local directory or an S3 bucket);
+-- this table tracks them. Exercises, locations and profiles keep storing
+-- URLs, now the stable /api/v1/media/:id URLs of these files. Images get
+-- scaled-down variants (thumb, medium) pointing at their original.
+CREATE TABLE media_files (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    variant VARCHAR(20) NOT NULL DEFAULT 'original',
+    parent_id INTEGER REFERENCES media_files(id) ON DELETE CASCADE,
+    purpose VARCHAR(20) NOT NULL CHECK (purpose IN ('exercise', 'location', 'profile')),
+    attached_id INTEGER,
+    storage_key VARCHAR(255) NOT NULL UNIQUE,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    width INTEGER,
+    height INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_media_files_user_id ON media_files(user_id);
+CREATE INDEX idx_media_files_parent_id ON media_files(parent_id);
+CREATE INDEX idx_media_files_attachment ON media_files(purpose, attached_id);
+CREATE INDEX idx_media_files_created_at ON media_files(created_at);
@@ -0,0 +1,6 @@
+-- ==========================================
+-- Rollback Metric Goals
+-- ==========================================
+
+DROP INDEX IF EXISTS idx_metrics_trainee_date;
+DROP TABLE IF EXISTS metric_goals;
@@ -0,0 +1,26 @@
+-- ==========================================
+-- Metric Goals
+-- ==========================================
+-- Trainer-set targets for a trainee's body metrics, on a recorded type or a
+-- derived one (BMI, lean mass, waist-to-height ratio). Progress and the
+-- projected goal date are computed from the metric history when requested.
+CREATE TABLE metric_goals (
+    id SERIAL PRIMARY KEY,
+    trainee_id INTEGER NOT NULL REFERENCES trainees(id) ON DELETE CASCADE,
+    
+    type VARCHAR(30) NOT NULL CHECK (type IN ('weight', 'body_fat', 'muscle_mass', 'measurement', 'bmi', 'lean_mass', 'waist_to_height')),
+    measurement_type VARCHAR(50) NOT NULL DEFAULT '', -- '' unless type = 'measurement'
+    target_value DECIMAL(8,2) NOT NULL,
+    target_date DATE,
+    notes TEXT,
+    
+    set_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_metric_goals_unique ON metric_goals(trainee_id, type, measurement_type);
+
+-- Metric analytics read a trainee's whole history in date order
+CREATE INDEX idx_metrics_trainee_date ON metrics(trainee_id, date);
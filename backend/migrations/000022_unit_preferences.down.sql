@@ -0,0 +1,9 @@
+-- ==========================================
+-- Rollback Unit Preferences
+-- ==========================================
+
+-- Normalised metric values stay in kg and cm
+ALTER TABLE metrics DROP CONSTRAINT IF EXISTS metrics_canonical_unit;
+ALTER TABLE users DROP COLUMN IF EXISTS length_unit;
+ALTER TABLE users DROP COLUMN IF EXISTS distance_unit;
+ALTER TABLE users DROP COLUMN IF EXISTS weight_unit;
@@ -0,0 +1,35 @@
+-- ==========================================
+-- Unit Preferences
+-- ==========================================
+-- Metrics and exercise sets are stored in kg, km, cm and %; each user picks
+-- the units values are entered and shown in.
+ALTER TABLE users ADD COLUMN weight_unit VARCHAR(2) NOT NULL DEFAULT 'kg' CHECK (weight_unit IN ('kg', 'lb'));
+ALTER TABLE users ADD COLUMN distance_unit VARCHAR(2) NOT NULL DEFAULT 'km' CHECK (distance_unit IN ('km', 'mi'));
+ALTER TABLE users ADD COLUMN length_unit VARCHAR(2) NOT NULL DEFAULT 'cm' CHECK (length_unit IN ('cm', 'in'));
+
+-- Normalise metrics recorded in other units or spellings
+UPDATE metrics SET value = ROUND(value * 0.45359237, 2), unit = 'kg'
+WHERE type IN ('weight', 'muscle_mass') AND LOWER(TRIM(unit)) IN ('lb', 'lbs', 'pound', 'pounds');
+
+UPDATE metrics SET unit = 'kg'
+WHERE type IN ('weight', 'muscle_mass') AND LOWER(TRIM(unit)) IN ('kg', 'kgs', 'kilogram', 'kilograms') AND unit <> 'kg';
+
+UPDATE metrics SET unit = '%'
+WHERE type = 'body_fat' AND LOWER(TRIM(unit)) IN ('', 'percent', 'pct') AND unit <> '%';
+
+UPDATE metrics SET value = ROUND(value * 2.54, 2), unit = 'cm'
+WHERE type = 'measurement' AND LOWER(TRIM(unit)) IN ('in', 'inch', 'inches', '"');
+
+UPDATE metrics SET value = ROUND(value / 10, 2), unit = 'cm'
+WHERE type = 'measurement' AND LOWER(TRIM(unit)) = 'mm';
+
+UPDATE metrics SET unit = 'cm'
+WHERE type = 'measurement' AND LOWER(TRIM(unit)) IN ('cm', 'cms', 'centimeter', 'centimeters', 'centimetre', 'centimetres') AND unit <> 'cm';
+
+-- New metrics must use the canonical unit of their type. Rows with units the
+-- statements above cannot interpret are left for a person to fix.
+ALTER TABLE metrics ADD CONSTRAINT metrics_canonical_unit CHECK (
+    (type IN ('weight', 'muscle_mass') AND unit = 'kg')
+    OR (type = 'body_fat' AND unit = '%')
+    OR (type = 'measurement' AND unit = 'cm')
+) NOT VALID;
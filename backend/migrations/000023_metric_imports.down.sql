@@ -0,0 +1,8 @@
+-- ==========================================
+-- Rollback Metric Imports
+-- ==========================================
+
+DROP INDEX IF EXISTS idx_metrics_trainee_import;
+ALTER TABLE metrics DROP COLUMN IF EXISTS import_key;
+DROP INDEX IF EXISTS idx_trainees_member_id;
+ALTER TABLE trainees DROP COLUMN IF EXISTS member_id;
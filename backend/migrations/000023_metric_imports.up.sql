@@ -0,0 +1,11 @@
+-- ==========================================
+-- Metric Imports
+-- ==========================================
+-- Body-composition exports identify clients by membership number, phone or
+-- email, and each imported value carries a key derived from the scan so
+-- importing the same file twice adds nothing.
+ALTER TABLE trainees ADD COLUMN member_id VARCHAR(50);
+CREATE UNIQUE INDEX idx_trainees_member_id ON trainees(member_id);
+
+ALTER TABLE metrics ADD COLUMN import_key VARCHAR(64);
+CREATE UNIQUE INDEX idx_metrics_trainee_import ON metrics(trainee_id, import_key);
@@ -0,0 +1,14 @@
+-- ==========================================
+-- Rollback Workout Imports
+-- ==========================================
+
+ALTER TABLE exercise_sets DROP COLUMN IF EXISTS max_heart_rate;
+ALTER TABLE exercise_sets DROP COLUMN IF EXISTS avg_heart_rate;
+
+ALTER TABLE session_exercises DROP COLUMN IF EXISTS heart_rate_zones;
+ALTER TABLE session_exercises DROP COLUMN IF EXISTS max_heart_rate;
+ALTER TABLE session_exercises DROP COLUMN IF EXISTS avg_heart_rate;
+
+DROP INDEX IF EXISTS idx_session_cards_trainee_import;
+ALTER TABLE session_cards DROP COLUMN IF EXISTS import_key;
+ALTER TABLE session_cards DROP COLUMN IF EXISTS source;
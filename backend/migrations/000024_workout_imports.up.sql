@@ -0,0 +1,15 @@
+-- ==========================================
+-- Workout Imports
+-- ==========================================
+-- Clients upload GPX, TCX and FIT files from their watches; each becomes a
+-- session card with a cardio exercise whose laps are its sets.
+ALTER TABLE session_cards ADD COLUMN source VARCHAR(10) CHECK (source IN ('gpx', 'tcx', 'fit'));
+ALTER TABLE session_cards ADD COLUMN import_key VARCHAR(64);
+CREATE UNIQUE INDEX idx_session_cards_trainee_import ON session_cards(trainee_id, import_key);
+
+ALTER TABLE session_exercises ADD COLUMN avg_heart_rate INTEGER;
+ALTER TABLE session_exercises ADD COLUMN max_heart_rate INTEGER;
+ALTER TABLE session_exercises ADD COLUMN heart_rate_zones INTEGER[];
+
+ALTER TABLE exercise_sets ADD COLUMN avg_heart_rate INTEGER;
+ALTER TABLE exercise_sets ADD COLUMN max_heart_rate INTEGER;
@@ -0,0 +1,5 @@
+-- ==========================================
+-- Rollback PR Achievement Keys
+-- ==========================================
+
+UPDATE achievements SET rule_key = NULL WHERE type = 'pr';
@@ -0,0 +1,26 @@
+-- ==========================================
+-- PR Achievement Keys
+-- ==========================================
+-- PR achievements are keyed pr_<session card>_<exercise>_<record type> so
+-- editing a card does not award them again. Existing ones get a key for the
+-- card and exercise they were awarded for; the copies earlier edits created
+-- are removed.
+UPDATE achievements a
+SET rule_key = m.rule_key
+FROM (
+    SELECT DISTINCT ON (se.session_card_id, se.exercise_library_id)
+        a.id,
+        'pr_' || se.session_card_id || '_' || se.exercise_library_id || '_legacy' AS rule_key
+    FROM achievements a
+    JOIN session_cards c ON c.trainee_id = a.trainee_id AND c.date = a.achieved_at AND c.deleted_at IS NULL
+    JOIN session_exercises se ON se.session_card_id = c.id AND a.title = 'New PR: ' || se.name
+    WHERE a.type = 'pr' AND a.rule_key IS NULL AND se.exercise_library_id IS NOT NULL
+    ORDER BY se.session_card_id, se.exercise_library_id, a.id
+) m
+WHERE a.id = m.id;
+
+DELETE FROM achievements a
+USING achievements kept
+WHERE a.type = 'pr' AND a.rule_key IS NULL
+  AND kept.type = 'pr' AND kept.rule_key IS NOT NULL
+  AND kept.trainee_id = a.trainee_id AND kept.title = a.title AND kept.achieved_at = a.achieved_at;
@@ -0,0 +1,10 @@
+-- ==========================================
+-- Rollback Message Attachment Media
+-- ==========================================
+
+ALTER TABLE media_files DROP COLUMN IF EXISTS file_name;
+
+DELETE FROM media_files WHERE purpose = 'message';
+ALTER TABLE media_files DROP CONSTRAINT IF EXISTS media_files_purpose_check;
+ALTER TABLE media_files ADD CONSTRAINT media_files_purpose_check
+    CHECK (purpose IN ('exercise', 'location', 'profile'));
@@ -0,0 +1,12 @@
+-- ==========================================
+-- Message Attachment Media
+-- ==========================================
+-- Message attachments are uploaded to media storage (purpose 'message') and
+-- sent by media ID; the attachment copies the file's name, type and size
+-- from the upload instead of trusting the client. attached_id is the
+-- message an attachment was sent with.
+ALTER TABLE media_files DROP CONSTRAINT IF EXISTS media_files_purpose_check;
+ALTER TABLE media_files ADD CONSTRAINT media_files_purpose_check
+    CHECK (purpose IN ('exercise', 'location', 'profile', 'message'));
+
+ALTER TABLE media_files ADD COLUMN file_name VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1,7 @@
+-- ==========================================
+-- Rollback Schedule Revisions
+-- ==========================================
+
+DROP TRIGGER IF EXISTS schedules_revision ON schedules;
+DROP FUNCTION IF EXISTS bump_schedule_revision();
+ALTER TABLE schedules DROP COLUMN IF EXISTS revision;
@@ -0,0 +1,26 @@
+-- ==========================================
+-- Schedule Revisions
+-- ==========================================
+-- Calendar feeds send a schedule's revision as the event's SEQUENCE, which
+-- clients use to tell a changed event from a stale copy. The trigger bumps
+-- it whenever something the event shows changes, whichever code path
+-- updated the row; other updates (reminders, links) keep it.
+ALTER TABLE schedules ADD COLUMN revision INTEGER NOT NULL DEFAULT 0;
+
+CREATE OR REPLACE FUNCTION bump_schedule_revision()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF (NEW.date, NEW.time, NEW.duration, NEW.title, NEW.description, NEW.session_type,
+        NEW.planned_exercises, NEW.status, NEW.location_id, NEW.trainer_id, NEW.trainee_id, NEW.series_id)
+       IS DISTINCT FROM
+       (OLD.date, OLD.time, OLD.duration, OLD.title, OLD.description, OLD.session_type,
+        OLD.planned_exercises, OLD.status, OLD.location_id, OLD.trainer_id, OLD.trainee_id, OLD.series_id) THEN
+        NEW.revision = OLD.revision + 1;
+    ELSE
+        NEW.revision = OLD.revision;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER schedules_revision BEFORE UPDATE ON schedules FOR EACH ROW EXECUTE FUNCTION bump_schedule_revision();
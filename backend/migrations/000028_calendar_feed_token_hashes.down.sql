@@ -0,0 +1,8 @@
+-- ==========================================
+-- Rollback Calendar Feed Token Hashes
+-- ==========================================
+
+-- Hashes cannot be turned back into tokens: users get a new feed URL
+DELETE FROM calendar_feeds;
+ALTER INDEX IF EXISTS calendar_feeds_token_hash_key RENAME TO calendar_feeds_token_key;
+ALTER TABLE calendar_feeds RENAME COLUMN token_hash TO token;
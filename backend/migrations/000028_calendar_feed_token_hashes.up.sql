@@ -0,0 +1,10 @@
+-- ==========================================
+-- Calendar Feed Token Hashes
+-- ==========================================
+-- Feed tokens are stored as their SHA-256, like refresh tokens, so a leaked
+-- database does not expose every user's schedule. Hashing the existing
+-- tokens in place keeps subscribed feed URLs working; the URL is only shown
+-- when a feed is created or rotated.
+UPDATE calendar_feeds SET token = encode(sha256(convert_to(token, 'UTF8')), 'hex');
+ALTER TABLE calendar_feeds RENAME COLUMN token TO token_hash;
+ALTER INDEX IF EXISTS calendar_feeds_token_key RENAME TO calendar_feeds_token_hash_key;
@@ -0,0 +1,401 @@
+// Package bodycomp reads the CSV exports of body-composition scanners
+// (InBody and similar) into readings of weight, body fat, muscle mass and
+// circumference measurements. Columns are found by header through a Mapping,
+// so exports of other devices or languages work with a different one.
+package bodycomp
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"fitness-training-backend/pkg/units"
+)
+
+// ErrInvalidFile is returned for files that cannot be imported at all
+var ErrInvalidFile = errors.New("invalid body composition export")
+
+// Metric types readings are made of
+const (
+	TypeWeight      = "weight"
+	TypeBodyFat     = "body_fat"
+	TypeMuscleMass  = "muscle_mass"
+	TypeMeasurement = "measurement"
+)
+
+// Mapping names the columns each field is read from. A field lists the
+// headers it may appear under and the first one present is used. Headers are
+// compared ignoring case, punctuation and the "12. " numbering of InBody
+// exports.
+type Mapping struct {
+	MemberID   []string
+	Phone      []string
+	Email      []string
+	Date       []string // date and time of the scan
+	Weight     []string
+	BodyFat    []string // percent
+	MuscleMass []string
+
+	// Measurements maps a measurement type (e.g. 'waist') to its headers
+	Measurements map[string][]string
+
+	WeightUnit units.Unit // of weight and muscle mass, kg by default
+	LengthUnit units.Unit // of measurements, cm by default
+	DayFirst   bool       // read 03/04/2026 as 3 April rather than March 4
+}
+
+// DefaultMapping reads the exports of InBody's LookinBody software
+func DefaultMapping() Mapping {
+	return Mapping{
+		MemberID:   []string{"ID", "Member ID", "Member No"},
+		Phone:      []string{"Mobile Number", "Phone Number", "Phone", "Mobile"},
+		Email:      []string{"Email", "E-mail"},
+		Date:       []string{"Test Date / Time", "Test Date", "Date"},
+		Weight:     []string{"Weight"},
+		BodyFat:    []string{"PBF(Percent Body Fat)", "Percent Body Fat", "PBF", "Body Fat %"},
+		MuscleMass: []string{"SMM(Skeletal Muscle Mass)", "Skeletal Muscle Mass", "SMM"},
+		Measurements: map[string][]string{
+			"neck":        {"Neck Circumference"},
+			"chest":       {"Chest Circumference"},
+			"waist":       {"Waist Circumference", "Abdomen Circumference"},
+			"hip":         {"Hip Circumference"},
+			"right_arm":   {"Right Arm Circumference"},
+			"left_arm":    {"Left Arm Circumference"},
+			"right_thigh": {"Right Thigh Circumference"},
+			"left_thigh":  {"Left Thigh Circumference"},
+		},
+		WeightUnit: units.Kilogram,
+		LengthUnit: units.Centimeter,
+		DayFirst:   true,
+	}
+}
+
+// Value is one measured value of a reading, in the unit of the file
+type Value struct {
+	Type            string
+	MeasurementType string // '' unless Type is TypeMeasurement
+	Value           float64
+	Unit            units.Unit
+}
+
+// Reading is one scan: a data row of the export
+type Reading struct {
+	Row      int // line in the file, the header being line 1
+	MemberID string
+	Phone    string
+	Email    string
+	TakenAt  time.Time // wall-clock time of the device, as UTC
+	Values   []Value
+}
+
+// RowError explains why a row was skipped
+type RowError struct {
+	Row int
+	Err string
+}
+
+// Result is a parsed export
+type Result struct {
+	Readings []Reading
+	Errors   []RowError
+
+	// Columns maps each field found to the header it was read from
+	Columns map[string]string
+}
+
+// column is a field found in the header
+type column struct {
+	field           string
+	metricType      string
+	measurementType string
+	index           int
+}
+
+// Parse reads an export. Rows that cannot be read are reported in the result;
+// an error is returned only when the file has no header, no date column, no
+// column identifying the member or no value column.
+func Parse(r io.Reader, mapping Mapping) (*Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = detectDelimiter(data)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: no header row", ErrInvalidFile)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		if key := normalizeHeader(name); key != "" {
+			if _, ok := index[key]; !ok {
+				index[key] = i
+			}
+		}
+	}
+
+	result := &Result{Columns: map[string]string{}}
+	find := func(field string, headers []string) (int, bool) {
+		for _, name := range headers {
+			if i, ok := index[normalizeHeader(name)]; ok {
+				result.Columns[field] = strings.TrimSpace(header[i])
+				return i, true
+			}
+		}
+		return 0, false
+	}
+
+	dateIndex, ok := find("date", mapping.Date)
+	if !ok {
+		return nil, fmt.Errorf("%w: no date column (looked for %s)", ErrInvalidFile, strings.Join(mapping.Date, ", "))
+	}
+	identifiers := map[string]int{}
+	for field, headers := range map[string][]string{"memberId": mapping.MemberID, "phone": mapping.Phone, "email": mapping.Email} {
+		if i, ok := find(field, headers); ok {
+			identifiers[field] = i
+		}
+	}
+	if len(identifiers) == 0 {
+		return nil, fmt.Errorf("%w: no member ID, phone or email column", ErrInvalidFile)
+	}
+
+	weightUnit, lengthUnit := mapping.WeightUnit, mapping.LengthUnit
+	if weightUnit == "" {
+		weightUnit = units.Kilogram
+	}
+	if lengthUnit == "" {
+		lengthUnit = units.Centimeter
+	}
+
+	var columns []column
+	for _, c := range []struct {
+		field, metricType string
+		headers           []string
+	}{
+		{"weight", TypeWeight, mapping.Weight},
+		{"bodyFat", TypeBodyFat, mapping.BodyFat},
+		{"muscleMass", TypeMuscleMass, mapping.MuscleMass},
+	} {
+		if i, ok := find(c.field, c.headers); ok {
+			columns = append(columns, column{field: c.field, metricType: c.metricType, index: i})
+		}
+	}
+	for _, measurementType := range sortedKeys(mapping.Measurements) {
+		field := "measurements." + measurementType
+		if i, ok := find(field, mapping.Measurements[measurementType]); ok {
+			columns = append(columns, column{field: field, metricType: TypeMeasurement, measurementType: measurementType, index: i})
+		}
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("%w: no weight, body fat, muscle mass or measurement column", ErrInvalidFile)
+	}
+
+	unitOf := map[string]units.Unit{
+		TypeWeight:      weightUnit,
+		TypeBodyFat:     units.Percent,
+		TypeMuscleMass:  weightUnit,
+		TypeMeasurement: lengthUnit,
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			result.Errors = append(result.Errors, RowError{Row: parseErr.StartLine, Err: parseErr.Err.Error()})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if blank(record) {
+			continue
+		}
+		row, _ := reader.FieldPos(0)
+
+		reading := Reading{Row: row}
+		cell := func(i int) string {
+			if i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		if i, ok := identifiers["memberId"]; ok {
+			reading.MemberID = cell(i)
+		}
+		if i, ok := identifiers["phone"]; ok {
+			reading.Phone = cell(i)
+		}
+		if i, ok := identifiers["email"]; ok {
+			reading.Email = strings.ToLower(cell(i))
+		}
+		if reading.MemberID == "" && reading.Phone == "" && reading.Email == "" {
+			result.Errors = append(result.Errors, RowError{Row: row, Err: "no member ID, phone or email"})
+			continue
+		}
+
+		takenAt, err := ParseTime(cell(dateIndex), mapping.DayFirst)
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Row: row, Err: err.Error()})
+			continue
+		}
+		reading.TakenAt = takenAt
+
+		var problems []string
+		for _, c := range columns {
+			value, ok, err := parseNumber(cell(c.index))
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", result.Columns[c.field], err))
+				continue
+			}
+			if !ok {
+				continue
+			}
+			reading.Values = append(reading.Values, Value{
+				Type:            c.metricType,
+				MeasurementType: c.measurementType,
+				Value:           value,
+				Unit:            unitOf[c.metricType],
+			})
+		}
+		if len(problems) > 0 {
+			result.Errors = append(result.Errors, RowError{Row: row, Err: strings.Join(problems, "; ")})
+			continue
+		}
+		if len(reading.Values) == 0 {
+			result.Errors = append(result.Errors, RowError{Row: row, Err: "no values"})
+			continue
+		}
+		result.Readings = append(result.Readings, reading)
+	}
+
+	return result, nil
+}
+
+// detectDelimiter picks comma, semicolon or tab by which is most common in
+// the header line
+func detectDelimiter(data []byte) rune {
+	line := data
+	if end := bytes.IndexByte(data, '\n'); end >= 0 {
+		line = data[:end]
+	}
+	delimiter, most := ',', bytes.Count(line, []byte{','})
+	for _, candidate := range []rune{';', '\t'} {
+		if n := bytes.Count(line, []byte(string(candidate))); n > most {
+			delimiter, most = candidate, n
+		}
+	}
+	return delimiter
+}
+
+var (
+	headerNumbering = regexp.MustCompile(`^\d+\.\s*`)
+	headerPunct     = regexp.MustCompile(`[^\p{L}\p{N}%]+`)
+)
+
+// normalizeHeader makes "12. PBF(Percent Body Fat)" and "pbf percent body
+// fat" the same header
+func normalizeHeader(name string) string {
+	name = headerNumbering.ReplaceAllString(strings.TrimSpace(name), "")
+	return headerPunct.ReplaceAllString(strings.ToLower(name), "")
+}
+
+// timeLayouts are tried in order; slash dates are handled separately since
+// their day and month order varies
+var timeLayouts = []string{
+	"2006.01.02 15:04:05",
+	"2006.01.02 15:04",
+	"2006.01.02",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02 15:04",
+	"2006/01/02",
+	"20060102150405",
+	"200601021504",
+	"20060102",
+}
+
+// ParseTime reads the date and time of a scan. dayFirst decides how
+// 03/04/2026 is read.
+func ParseTime(value string, dayFirst bool) (time.Time, error) {
+	value = strings.Join(strings.Fields(value), " ")
+	if value == "" {
+		return time.Time{}, errors.New("no date")
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	order := "01/02/2006"
+	if dayFirst {
+		order = "02/01/2006"
+	}
+	for _, layout := range []string{order + " 15:04:05", order + " 15:04", order} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+		if t, err := time.Parse(strings.ReplaceAll(layout, "/", "."), value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised date %q", value)
+}
+
+// parseNumber reads a cell; empty cells, placeholders such as "-" and zeros
+// (not measured) are not values. A decimal comma is accepted.
+func parseNumber(value string) (float64, bool, error) {
+	value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "%"))
+	switch strings.ToLower(value) {
+	case "", "-", "--", "n/a", "na":
+		return 0, false, nil
+	}
+	if !strings.Contains(value, ".") {
+		value = strings.Replace(value, ",", ".", 1)
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("%q is not a number", value)
+	}
+	if number <= 0 {
+		return 0, false, nil
+	}
+	return number, true, nil
+}
+
+func blank(record []string) bool {
+	for _, cell := range record {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,147 @@
+package bodycomp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"fitness-training-backend/pkg/units"
+)
+
+func TestParseInBodyExport(t *testing.T) {
+	data := "\ufeff" +
+		"1. Name,2. ID,3. Mobile Number,14. Test Date / Time,15. Weight,25. SMM(Skeletal Muscle Mass),27. PBF(Percent Body Fat),40. Waist Circumference\n" +
+		"Ana,M-100,+44 7700 900123,2026.03.04 07:30:00,72.4,31.2,21.5,81\n" +
+		",,,,,,,\n" +
+		"Ben,,07700 900456,05/03/2026 18:05,88,-,0,\n" +
+		"Cal,M-102,,2026.03.04,abc,30,20,\n" +
+		"Dee,,,2026.03.04,70,30,20,\n"
+
+	result, err := Parse(strings.NewReader(data), DefaultMapping())
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if got := result.Columns["bodyFat"]; got != "27. PBF(Percent Body Fat)" {
+		t.Errorf("Columns[bodyFat] = %q", got)
+	}
+	if got := result.Columns["measurements.waist"]; got != "40. Waist Circumference" {
+		t.Errorf("Columns[measurements.waist] = %q", got)
+	}
+	if _, ok := result.Columns["email"]; ok {
+		t.Errorf("Columns has email, export has no email column")
+	}
+
+	if len(result.Readings) != 2 {
+		t.Fatalf("len(Readings) = %d, want 2", len(result.Readings))
+	}
+	ana := result.Readings[0]
+	if ana.Row != 2 || ana.MemberID != "M-100" || ana.Phone != "+44 7700 900123" {
+		t.Errorf("Readings[0] = row %d, member %q, phone %q", ana.Row, ana.MemberID, ana.Phone)
+	}
+	if want := time.Date(2026, 3, 4, 7, 30, 0, 0, time.UTC); !ana.TakenAt.Equal(want) {
+		t.Errorf("Readings[0].TakenAt = %v, want %v", ana.TakenAt, want)
+	}
+	want := []Value{
+		{Type: TypeWeight, Value: 72.4, Unit: units.Kilogram},
+		{Type: TypeBodyFat, Value: 21.5, Unit: units.Percent},
+		{Type: TypeMuscleMass, Value: 31.2, Unit: units.Kilogram},
+		{Type: TypeMeasurement, MeasurementType: "waist", Value: 81, Unit: units.Centimeter},
+	}
+	if len(ana.Values) != len(want) {
+		t.Fatalf("Readings[0].Values = %+v, want %+v", ana.Values, want)
+	}
+	for i := range want {
+		if ana.Values[i] != want[i] {
+			t.Errorf("Readings[0].Values[%d] = %+v, want %+v", i, ana.Values[i], want[i])
+		}
+	}
+
+	// Placeholders and zeros are skipped, day-first slash dates are read
+	ben := result.Readings[1]
+	if ben.Row != 4 || len(ben.Values) != 1 || ben.Values[0].Type != TypeWeight {
+		t.Errorf("Readings[1] = row %d, values %+v", ben.Row, ben.Values)
+	}
+	if want := time.Date(2026, 3, 5, 18, 5, 0, 0, time.UTC); !ben.TakenAt.Equal(want) {
+		t.Errorf("Readings[1].TakenAt = %v, want %v", ben.TakenAt, want)
+	}
+
+	if len(result.Errors) != 2 || result.Errors[0].Row != 5 || result.Errors[1].Row != 6 {
+		t.Errorf("Errors = %+v, want rows 5 and 6", result.Errors)
+	}
+}
+
+func TestParseCustomMapping(t *testing.T) {
+	data := "Client Email;Scan Date;Body Weight (lb);Belly (in)\n" +
+		"Ana@Example.com;03/04/2026;160,5;32,5\n"
+
+	mapping := Mapping{
+		Email:        []string{"Client Email"},
+		Date:         []string{"Scan Date"},
+		Weight:       []string{"Body Weight (lb)"},
+		Measurements: map[string][]string{"waist": {"Belly (in)"}},
+		WeightUnit:   units.Pound,
+		LengthUnit:   units.Inch,
+	}
+	result, err := Parse(strings.NewReader(data), mapping)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(result.Readings) != 1 {
+		t.Fatalf("Readings = %+v, Errors = %+v", result.Readings, result.Errors)
+	}
+	reading := result.Readings[0]
+	if reading.Email != "ana@example.com" {
+		t.Errorf("Email = %q", reading.Email)
+	}
+	if want := time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC); !reading.TakenAt.Equal(want) {
+		t.Errorf("TakenAt = %v, want %v (month first)", reading.TakenAt, want)
+	}
+	if len(reading.Values) != 2 ||
+		reading.Values[0] != (Value{Type: TypeWeight, Value: 160.5, Unit: units.Pound}) ||
+		reading.Values[1] != (Value{Type: TypeMeasurement, MeasurementType: "waist", Value: 32.5, Unit: units.Inch}) {
+		t.Errorf("Values = %+v", reading.Values)
+	}
+}
+
+func TestParseInvalidFile(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"empty", ""},
+		{"no date", "ID,Weight\nM-1,70\n"},
+		{"no identifier", "Date,Weight\n2026-03-04,70\n"},
+		{"no values", "ID,Date,Height\nM-1,2026-03-04,180\n"},
+	}
+	for _, tt := range tests {
+		if _, err := Parse(strings.NewReader(tt.data), DefaultMapping()); !errors.Is(err, ErrInvalidFile) {
+			t.Errorf("%s: Parse() error = %v, want ErrInvalidFile", tt.name, err)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		in       string
+		dayFirst bool
+		want     time.Time
+	}{
+		{"2026.03.04 07:30:00", false, time.Date(2026, 3, 4, 7, 30, 0, 0, time.UTC)},
+		{"2026-03-04T07:30:00", false, time.Date(2026, 3, 4, 7, 30, 0, 0, time.UTC)},
+		{"20260304073000", false, time.Date(2026, 3, 4, 7, 30, 0, 0, time.UTC)},
+		{"03/04/2026", false, time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{"03/04/2026", true, time.Date(2026, 4, 3, 0, 0, 0, 0, time.UTC)},
+		{"03.04.2026  09:15", true, time.Date(2026, 4, 3, 9, 15, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParseTime(tt.in, tt.dayFirst)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("ParseTime(%q, %v) = %v, %v, want %v", tt.in, tt.dayFirst, got, err, tt.want)
+		}
+	}
+	if _, err := ParseTime("next tuesday", true); err == nil {
+		t.Error("ParseTime(next tuesday) error = nil")
+	}
+}
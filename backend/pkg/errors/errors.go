@@ -11,12 +11,15 @@ var (
 	ErrForbidden          = errors.New("forbidden")
 	ErrInvalidToken       = errors.New("invalid token")
 	ErrExpiredToken       = errors.New("token has expired")
+	ErrTokenReused        = errors.New("refresh token reuse detected")
 	
 	// Validation errors
 	ErrWeakPassword      = errors.New("password must be at least 8 characters and contain uppercase, lowercase, and number")
 	ErrInvalidEmail      = errors.New("invalid email format")
 	ErrInvalidInput      = errors.New("invalid input")
 	ErrMissingField      = errors.New("required field is missing")
+	ErrFileTooLarge      = errors.New("file too large")
+	ErrUnsupportedMedia  = errors.New("unsupported file type")
 	
 	// Resource errors
 	ErrNotFound          = errors.New("resource not found")
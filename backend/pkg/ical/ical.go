@@ -0,0 +1,148 @@
+// Package ical reads and writes the subset of iCalendar (RFC 5545) used for
+// schedule feeds and imports.
+package ical
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Event statuses
+const (
+	StatusTentative = "TENTATIVE"
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+const (
+	dateTimeUTCFormat = "20060102T150405Z"
+	dateTimeFormat    = "20060102T150405"
+	dateFormat        = "20060102"
+	maxLineOctets     = 75
+)
+
+// Event is a single VEVENT
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Latitude    *float64
+	Longitude   *float64
+	Start       time.Time
+	End         time.Time
+	Status      string
+	Sequence    int
+	Created     time.Time
+	Modified    time.Time
+
+	// Parsed only
+	AllDay    bool
+	RRule     string
+	Attendees []string // email addresses
+}
+
+// Calendar is a VCALENDAR with its events
+type Calendar struct {
+	ProdID          string
+	Name            string
+	RefreshInterval time.Duration
+	Events          []Event
+}
+
+// Encode renders the calendar as an iCalendar document
+func (c *Calendar) Encode() []byte {
+	var b strings.Builder
+
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:"+c.ProdID)
+	writeLine(&b, "CALSCALE:GREGORIAN")
+	writeLine(&b, "METHOD:PUBLISH")
+	if c.Name != "" {
+		writeLine(&b, "X-WR-CALNAME:"+escapeText(c.Name))
+	}
+	if c.RefreshInterval > 0 {
+		interval := formatDuration(c.RefreshInterval)
+		writeLine(&b, "REFRESH-INTERVAL;VALUE=DURATION:"+interval)
+		writeLine(&b, "X-PUBLISHED-TTL:"+interval)
+	}
+
+	stamp := time.Now().UTC().Format(dateTimeUTCFormat)
+	for _, event := range c.Events {
+		writeLine(&b, "BEGIN:VEVENT")
+		writeLine(&b, "UID:"+event.UID)
+		writeLine(&b, "DTSTAMP:"+stamp)
+		writeLine(&b, "DTSTART:"+event.Start.UTC().Format(dateTimeUTCFormat))
+		writeLine(&b, "DTEND:"+event.End.UTC().Format(dateTimeUTCFormat))
+		writeLine(&b, "SUMMARY:"+escapeText(event.Summary))
+		if event.Description != "" {
+			writeLine(&b, "DESCRIPTION:"+escapeText(event.Description))
+		}
+		if event.Location != "" {
+			writeLine(&b, "LOCATION:"+escapeText(event.Location))
+		}
+		if event.Latitude != nil && event.Longitude != nil {
+			writeLine(&b, fmt.Sprintf("GEO:%f;%f", *event.Latitude, *event.Longitude))
+		}
+		if event.Status != "" {
+			writeLine(&b, "STATUS:"+event.Status)
+		}
+		writeLine(&b, fmt.Sprintf("SEQUENCE:%d", event.Sequence))
+		if !event.Created.IsZero() {
+			writeLine(&b, "CREATED:"+event.Created.UTC().Format(dateTimeUTCFormat))
+		}
+		if !event.Modified.IsZero() {
+			writeLine(&b, "LAST-MODIFIED:"+event.Modified.UTC().Format(dateTimeUTCFormat))
+		}
+		writeLine(&b, "END:VEVENT")
+	}
+
+	writeLine(&b, "END:VCALENDAR")
+	return []byte(b.String())
+}
+
+// writeLine writes a content line, folding it at 75 octets without
+// splitting multi-byte characters
+func writeLine(b *strings.Builder, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = maxLineOctets - 1 // continuation lines start with a space
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+var textUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+func escapeText(value string) string {
+	return textEscaper.Replace(value)
+}
+
+func unescapeText(value string) string {
+	return textUnescaper.Replace(value)
+}
+
+// formatDuration renders d as an RFC 5545 duration (hours and minutes)
+func formatDuration(d time.Duration) string {
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+	if minutes == 0 {
+		return fmt.Sprintf("PT%dH", hours)
+	}
+	return fmt.Sprintf("PT%dH%dM", hours, minutes)
+}
@@ -0,0 +1,242 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// property is one unfolded content line: NAME;PARAM=VALUE:value
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Parse reads the VEVENTs of an iCalendar document. Floating times and
+// unknown TZIDs are interpreted in defaultLoc.
+func Parse(r io.Reader, defaultLoc *time.Location) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]Event, 0)
+	var (
+		current  *Event
+		hasEnd   bool
+		duration time.Duration
+		depth    int // nested components inside the VEVENT (e.g. VALARM)
+	)
+
+	for i, line := range lines {
+		prop, err := parseProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		switch {
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VEVENT"):
+			current = &Event{}
+			hasEnd = false
+			duration = 0
+			depth = 0
+			continue
+		case prop.name == "END" && strings.EqualFold(prop.value, "VEVENT"):
+			if current == nil {
+				return nil, fmt.Errorf("line %d: END:VEVENT without BEGIN", i+1)
+			}
+			if !hasEnd {
+				current.End = current.Start.Add(duration)
+			}
+			events = append(events, *current)
+			current = nil
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+		if prop.name == "BEGIN" {
+			depth++
+			continue
+		}
+		if prop.name == "END" {
+			depth--
+			continue
+		}
+		if depth > 0 {
+			continue
+		}
+
+		switch prop.name {
+		case "UID":
+			current.UID = prop.value
+		case "SUMMARY":
+			current.Summary = unescapeText(prop.value)
+		case "DESCRIPTION":
+			current.Description = unescapeText(prop.value)
+		case "LOCATION":
+			current.Location = unescapeText(prop.value)
+		case "STATUS":
+			current.Status = strings.ToUpper(prop.value)
+		case "RRULE":
+			current.RRule = prop.value
+		case "SEQUENCE":
+			current.Sequence, _ = strconv.Atoi(prop.value)
+		case "ATTENDEE":
+			if email := mailto(prop.value); email != "" {
+				current.Attendees = append(current.Attendees, email)
+			}
+		case "DTSTART":
+			start, allDay, err := parseDateTime(prop, defaultLoc)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			current.Start = start
+			current.AllDay = allDay
+		case "DTEND":
+			end, _, err := parseDateTime(prop, defaultLoc)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			current.End = end
+			hasEnd = true
+		case "DURATION":
+			d, err := parseDuration(prop.value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			duration = d
+		}
+	}
+
+	if current != nil {
+		return nil, fmt.Errorf("unterminated VEVENT")
+	}
+	return events, nil
+}
+
+// unfold joins folded continuation lines
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	lines := make([]string, 0)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseProperty splits a content line at the first colon outside quotes
+func parseProperty(line string) (property, error) {
+	inQuotes := false
+	colon := -1
+	for i, c := range line {
+		if c == '"' {
+			inQuotes = !inQuotes
+		} else if c == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return property{}, fmt.Errorf("malformed content line %q", line)
+	}
+
+	parts := splitParams(line[:colon])
+	prop := property{
+		name:   strings.ToUpper(parts[0]),
+		params: map[string]string{},
+		value:  line[colon+1:],
+	}
+	for _, param := range parts[1:] {
+		if key, value, ok := strings.Cut(param, "="); ok {
+			prop.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+		}
+	}
+	return prop, nil
+}
+
+// splitParams splits "NAME;A=1;B="x;y"" at semicolons outside quotes
+func splitParams(s string) []string {
+	parts := make([]string, 0, 2)
+	inQuotes := false
+	start := 0
+	for i, c := range s {
+		if c == '"' {
+			inQuotes = !inQuotes
+		} else if c == ';' && !inQuotes {
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// parseDateTime handles UTC, TZID-qualified, floating and all-day values
+func parseDateTime(prop property, defaultLoc *time.Location) (time.Time, bool, error) {
+	if strings.EqualFold(prop.params["VALUE"], "DATE") || len(prop.value) == len(dateFormat) {
+		t, err := time.ParseInLocation(dateFormat, prop.value, defaultLoc)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(prop.value, "Z") {
+		t, err := time.Parse(dateTimeUTCFormat, prop.value)
+		return t, false, err
+	}
+
+	loc := defaultLoc
+	if tzid := prop.params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation(dateTimeFormat, prop.value, loc)
+	return t, false, err
+}
+
+var durationPattern = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseDuration parses an RFC 5545 duration such as PT1H30M
+func parseDuration(value string) (time.Duration, error) {
+	m := durationPattern.FindStringSubmatch(value)
+	if m == nil || value == "P" || value == "PT" {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, unit := range units {
+		if m[i+2] == "" {
+			continue
+		}
+		n, _ := strconv.Atoi(m[i+2])
+		d += time.Duration(n) * unit
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+// mailto extracts the address of a "mailto:" URI
+func mailto(value string) string {
+	if len(value) > 7 && strings.EqualFold(value[:7], "mailto:") {
+		return strings.ToLower(strings.TrimSpace(value[7:]))
+	}
+	return ""
+}
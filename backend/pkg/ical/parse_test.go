@@ -0,0 +1,213 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func parseOne(t *testing.T, doc string, loc *time.Location) Event {
+	t.Helper()
+	events, err := Parse(strings.NewReader(strings.ReplaceAll(doc, "\n", "\r\n")), loc)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	return events[0]
+}
+
+func TestParseFoldedLines(t *testing.T) {
+	event := parseOne(t, `BEGIN:VCALENDAR
+BEGIN:VEVENT
+UID:folded@example.com
+SUMMARY:Leg day with a very long title that a calendar client wrapped ov
+ er two lines
+DESCRIPTION:Squat\, deadlift\nand lunges,
+	 then stretching
+DTSTART:20240320T070000Z
+DTEND:20240320T080000Z
+END:VEVENT
+END:VCALENDAR
+`, time.UTC)
+
+	if want := "Leg day with a very long title that a calendar client wrapped over two lines"; event.Summary != want {
+		t.Errorf("summary = %q, want %q", event.Summary, want)
+	}
+	if want := "Squat, deadlift\nand lunges, then stretching"; event.Description != want {
+		t.Errorf("description = %q, want %q", event.Description, want)
+	}
+}
+
+func TestParseFoldedEncodeRoundTrip(t *testing.T) {
+	summary := strings.Repeat("Interval run ", 12) + "ภาษาไทย"
+	calendar := &Calendar{ProdID: "-//Test//EN", Events: []Event{{
+		UID:      "roundtrip@example.com",
+		Summary:  summary,
+		Start:    time.Date(2024, 3, 20, 7, 0, 0, 0, time.UTC),
+		End:      time.Date(2024, 3, 20, 8, 0, 0, 0, time.UTC),
+		Sequence: 3,
+	}}}
+	encoded := string(calendar.Encode())
+	for _, line := range strings.Split(encoded, "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Fatalf("line of %d octets: %q", len(line), line)
+		}
+	}
+
+	event := parseOne(t, encoded, time.UTC)
+	if event.Summary != summary {
+		t.Errorf("summary = %q, want %q", event.Summary, summary)
+	}
+	if event.Sequence != 3 {
+		t.Errorf("sequence = %d, want 3", event.Sequence)
+	}
+}
+
+func TestParseDateTimes(t *testing.T) {
+	bangkok := mustLoad(t, "Asia/Bangkok")
+	newYork := mustLoad(t, "America/New_York")
+	tests := []struct {
+		name   string
+		line   string
+		start  time.Time
+		allDay bool
+	}{
+		{"UTC", "DTSTART:20240320T070000Z", time.Date(2024, 3, 20, 7, 0, 0, 0, time.UTC), false},
+		{"TZID", "DTSTART;TZID=America/New_York:20240320T070000", time.Date(2024, 3, 20, 7, 0, 0, 0, newYork), false},
+		{"quoted TZID", `DTSTART;TZID="America/New_York":20240320T070000`, time.Date(2024, 3, 20, 7, 0, 0, 0, newYork), false},
+		{"unknown TZID", "DTSTART;TZID=Gym Standard Time:20240320T070000", time.Date(2024, 3, 20, 7, 0, 0, 0, bangkok), false},
+		{"floating", "DTSTART:20240320T070000", time.Date(2024, 3, 20, 7, 0, 0, 0, bangkok), false},
+		{"all day", "DTSTART;VALUE=DATE:20240320", time.Date(2024, 3, 20, 0, 0, 0, 0, bangkok), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := parseOne(t, "BEGIN:VEVENT\nUID:x\n"+tt.line+"\nDURATION:PT1H\nEND:VEVENT\n", bangkok)
+			if !event.Start.Equal(tt.start) || event.AllDay != tt.allDay {
+				t.Errorf("start = %v (all day %v), want %v (all day %v)", event.Start, event.AllDay, tt.start, tt.allDay)
+			}
+		})
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"PT1H30M", 90 * time.Minute, true},
+		{"PT45M", 45 * time.Minute, true},
+		{"PT90S", 90 * time.Second, true},
+		{"P1D", 24 * time.Hour, true},
+		{"P1W", 7 * 24 * time.Hour, true},
+		{"P1DT2H", 26 * time.Hour, true},
+		{"+PT15M", 15 * time.Minute, true},
+		{"-PT15M", -15 * time.Minute, true},
+		{"P", 0, false},
+		{"PT", 0, false},
+		{"1H", 0, false},
+		{"PT1.5H", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := parseDuration(tt.value)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("parseDuration(%q) = %v, %v; want %v, ok %v", tt.value, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseEndFromDuration(t *testing.T) {
+	event := parseOne(t, `BEGIN:VEVENT
+UID:duration@example.com
+DTSTART:20240320T070000Z
+DURATION:PT1H30M
+END:VEVENT
+`, time.UTC)
+	if want := time.Date(2024, 3, 20, 8, 30, 0, 0, time.UTC); !event.End.Equal(want) {
+		t.Errorf("end = %v, want %v", event.End, want)
+	}
+
+	event = parseOne(t, `BEGIN:VEVENT
+UID:both@example.com
+DTSTART:20240320T070000Z
+DURATION:PT1H30M
+DTEND:20240320T080000Z
+END:VEVENT
+`, time.UTC)
+	if want := time.Date(2024, 3, 20, 8, 0, 0, 0, time.UTC); !event.End.Equal(want) {
+		t.Errorf("end with DTEND = %v, want %v", event.End, want)
+	}
+
+	if _, err := Parse(strings.NewReader("BEGIN:VEVENT\nDURATION:soon\nEND:VEVENT\n"), time.UTC); err == nil {
+		t.Error("invalid DURATION accepted")
+	}
+}
+
+func TestParseNestedAlarm(t *testing.T) {
+	event := parseOne(t, `BEGIN:VCALENDAR
+BEGIN:VEVENT
+UID:alarm@example.com
+SUMMARY:Session
+DTSTART:20240320T070000Z
+BEGIN:VALARM
+ACTION:DISPLAY
+DESCRIPTION:Reminder
+TRIGGER:-PT15M
+DURATION:PT5M
+END:VALARM
+DESCRIPTION:Bring shoes
+DTEND:20240320T080000Z
+END:VEVENT
+END:VCALENDAR
+`, time.UTC)
+
+	if event.Description != "Bring shoes" {
+		t.Errorf("description = %q, the alarm's leaked in", event.Description)
+	}
+	if want := time.Date(2024, 3, 20, 8, 0, 0, 0, time.UTC); !event.End.Equal(want) {
+		t.Errorf("end = %v, want %v", event.End, want)
+	}
+}
+
+func TestParseNestedAlarmWithoutEnd(t *testing.T) {
+	event := parseOne(t, `BEGIN:VEVENT
+UID:alarm@example.com
+DTSTART:20240320T070000Z
+BEGIN:VALARM
+TRIGGER:-PT15M
+DURATION:PT5M
+REPEAT:2
+END:VALARM
+END:VEVENT
+`, time.UTC)
+
+	// The alarm's DURATION is not the event's
+	if !event.End.Equal(event.Start) {
+		t.Errorf("end = %v, want the start %v", event.End, event.Start)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"unterminated event": "BEGIN:VEVENT\nUID:x\n",
+		"end without begin":  "END:VEVENT\n",
+		"malformed line":     "BEGIN:VEVENT\nNO COLON HERE\nEND:VEVENT\n",
+		"invalid date":       "BEGIN:VEVENT\nDTSTART:2024-03-20\nEND:VEVENT\n",
+	}
+	for name, doc := range tests {
+		if _, err := Parse(strings.NewReader(doc), time.UTC); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Package imaging decodes uploaded images and scales them down into
+// thumbnail variants, using the standard library codecs only.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	_ "image/gif" // registers GIF with image.Decode
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// MaxPixels rejects images whose decoded size would not fit comfortably in
+// memory, e.g. tiny files that claim to be 50000x50000
+const MaxPixels = 40_000_000
+
+// ErrTooLarge means the image has more than MaxPixels pixels
+var ErrTooLarge = errors.New("image dimensions too large")
+
+const jpegQuality = 85
+
+// Size reads the dimensions of a JPEG, PNG or GIF without decoding it
+func Size(r io.Reader) (int, int, error) {
+	config, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return 0, 0, err
+	}
+	return config.Width, config.Height, nil
+}
+
+// Decode decodes a JPEG, PNG or GIF (its first frame), checking its
+// dimensions first
+func Decode(data []byte) (image.Image, error) {
+	width, height, err := Size(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if width*height > MaxPixels {
+		return nil, ErrTooLarge
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+// Fit scales an image down so neither side exceeds maxSide, keeping its
+// aspect ratio. Each target pixel averages the source pixels it covers,
+// which keeps thumbnails smooth. Images that already fit are returned as is.
+func Fit(img image.Image, maxSide int) image.Image {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	if srcW <= maxSide && srcH <= maxSide {
+		return img
+	}
+
+	dstW, dstH := maxSide, maxSide
+	if srcW >= srcH {
+		dstH = max(1, srcH*maxSide/srcW)
+	} else {
+		dstW = max(1, srcW*maxSide/srcH)
+	}
+
+	src := image.NewRGBA(image.Rect(0, 0, srcW, srcH))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+
+	for y := 0; y < dstH; y++ {
+		y0, y1 := y*srcH/dstH, max((y+1)*srcH/dstH, y*srcH/dstH+1)
+		for x := 0; x < dstW; x++ {
+			x0, x1 := x*srcW/dstW, max((x+1)*srcW/dstW, x*srcW/dstW+1)
+
+			var r, g, b, a, n int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					r += int(row[sx*4])
+					g += int(row[sx*4+1])
+					b += int(row[sx*4+2])
+					a += int(row[sx*4+3])
+					n++
+				}
+			}
+			offset := y*dst.Stride + x*4
+			dst.Pix[offset] = uint8(r / n)
+			dst.Pix[offset+1] = uint8(g / n)
+			dst.Pix[offset+2] = uint8(b / n)
+			dst.Pix[offset+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+// Encode writes an image as JPEG, or as PNG for formats that may carry
+// transparency (png, gif). It returns the content type written.
+func Encode(w io.Writer, img image.Image, format string) (string, error) {
+	if format == "png" || format == "gif" {
+		return "image/png", png.Encode(w, img)
+	}
+	return "image/jpeg", jpeg.Encode(w, img, &jpeg.Options{Quality: jpegQuality})
+}
+
+// Format names the codec of a sniffed content type, or "" when the image
+// cannot be decoded here (e.g. WebP)
+func Format(contentType string) string {
+	switch contentType {
+	case "image/jpeg":
+		return "jpeg"
+	case "image/png":
+		return "png"
+	case "image/gif":
+		return "gif"
+	default:
+		return ""
+	}
+}
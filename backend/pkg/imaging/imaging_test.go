@@ -0,0 +1,70 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		maxSide       int
+		wantW, wantH  int
+	}{
+		{"landscape", 1600, 900, 200, 200, 112},
+		{"portrait", 900, 1600, 200, 112, 200},
+		{"square", 1000, 1000, 640, 640, 640},
+		{"already fits", 120, 80, 200, 120, 80},
+		{"very thin", 4000, 10, 200, 200, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := Fit(image.NewRGBA(image.Rect(0, 0, tt.width, tt.height)), tt.maxSide)
+			if w, h := img.Bounds().Dx(), img.Bounds().Dy(); w != tt.wantW || h != tt.wantH {
+				t.Errorf("Fit = %dx%d, want %dx%d", w, h, tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestFitAveragesPixels(t *testing.T) {
+	// Black and white columns average to grey
+	src := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			if x%2 == 0 {
+				src.Set(x, y, color.White)
+			} else {
+				src.Set(x, y, color.Black)
+			}
+		}
+	}
+
+	got := Fit(src, 2).At(0, 0).(color.RGBA)
+	if got.R != 127 || got.G != 127 || got.B != 127 || got.A != 255 {
+		t.Errorf("pixel = %+v, want grey", got)
+	}
+}
+
+func TestDecodeRejectsHugeDimensions(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 10, 10))); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	// Claim 10000x10000 in the IHDR chunk; the size check runs before decoding
+	copy(data[16:24], []byte{0, 0, 0x27, 0x10, 0, 0, 0x27, 0x10})
+	binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(data[12:29]))
+
+	if _, err := Decode(data); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Decode = %v, want ErrTooLarge", err)
+	}
+}
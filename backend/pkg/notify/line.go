@@ -0,0 +1,92 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	defaultLINEAPIURL = "https://api.line.me"
+	maxLINETextLength = 5000
+)
+
+// LINEConfig configures the LINE Messaging API sender
+type LINEConfig struct {
+	ChannelAccessToken string
+	APIURL             string // defaults to https://api.line.me
+}
+
+// LINESender pushes text messages to users who added the gym's LINE
+// Official Account
+type LINESender struct {
+	cfg    LINEConfig
+	client *http.Client
+}
+
+// NewLINESender creates a LINE sender
+func NewLINESender(cfg LINEConfig) *LINESender {
+	if cfg.APIURL == "" {
+		cfg.APIURL = defaultLINEAPIURL
+	}
+	cfg.APIURL = strings.TrimRight(cfg.APIURL, "/")
+	return &LINESender{cfg: cfg, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// Channel implements Sender
+func (s *LINESender) Channel() string {
+	return ChannelLINE
+}
+
+// Send implements Sender; to is a LINE user ID
+func (s *LINESender) Send(ctx context.Context, to string, msg Message) error {
+	text := msg.Title
+	if msg.Body != "" {
+		text += "\n" + msg.Body
+	}
+	if msg.URL != "" {
+		text += "\n" + msg.URL
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"to": to,
+		"messages": []map[string]string{
+			{"type": "text", "text": truncateRunes(text, maxLINETextLength)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.APIURL+"/v2/bot/message/push", bytes.NewReader(payload))
+	if err != nil {
+		return permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.cfg.ChannelAccessToken)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return statusError("LINE API", resp)
+}
+
+func truncateRunes(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:max-1]) + "…"
+}
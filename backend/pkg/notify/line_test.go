@@ -0,0 +1,93 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLINESenderSend(t *testing.T) {
+	var (
+		auth string
+		path string
+		body struct {
+			To       string `json:"to"`
+			Messages []struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"messages"`
+		}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		path = r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	sender := NewLINESender(LINEConfig{ChannelAccessToken: "secret-token", APIURL: server.URL + "/"})
+	msg := Message{Title: "ได้รับเหรียญใหม่!", Body: "You unlocked 10 Sessions", URL: "https://app.fitpro.test/achievements"}
+	if err := sender.Send(context.Background(), "U4af4980629", msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if auth != "Bearer secret-token" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	if path != "/v2/bot/message/push" {
+		t.Errorf("path = %q", path)
+	}
+	if body.To != "U4af4980629" || len(body.Messages) != 1 {
+		t.Fatalf("body = %+v", body)
+	}
+	want := "ได้รับเหรียญใหม่!\nYou unlocked 10 Sessions\nhttps://app.fitpro.test/achievements"
+	if body.Messages[0].Type != "text" || body.Messages[0].Text != want {
+		t.Errorf("message = %+v, want text %q", body.Messages[0], want)
+	}
+}
+
+func TestLINESenderErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		permanent bool
+	}{
+		{"bad request", http.StatusBadRequest, true},
+		{"invalid token", http.StatusUnauthorized, true},
+		{"rate limited", http.StatusTooManyRequests, false},
+		{"server error", http.StatusInternalServerError, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(`{"message":"error"}`))
+			}))
+			defer server.Close()
+
+			sender := NewLINESender(LINEConfig{ChannelAccessToken: "token", APIURL: server.URL})
+			err := sender.Send(context.Background(), "U1", Message{Title: "Hi"})
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if IsPermanent(err) != tt.permanent {
+				t.Errorf("IsPermanent(%v) = %v, want %v", err, IsPermanent(err), tt.permanent)
+			}
+		})
+	}
+}
+
+func TestTruncateRunes(t *testing.T) {
+	if got := truncateRunes("สวัสดีครับ", 5); got != "สวัส…" {
+		t.Errorf("truncateRunes = %q", got)
+	}
+	if got := truncateRunes("short", 10); got != "short" {
+		t.Errorf("truncateRunes = %q", got)
+	}
+}
@@ -0,0 +1,72 @@
+// Package notify delivers notifications outside the app: email over SMTP,
+// Web Push (VAPID) and the LINE Messaging API.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Channels, as stored in Notification.SentVia
+const (
+	ChannelEmail = "email"
+	ChannelPush  = "push"
+	ChannelLINE  = "line"
+)
+
+var (
+	// ErrPermanent marks failures that retrying cannot fix, e.g. a rejected
+	// address or invalid credentials
+	ErrPermanent = errors.New("permanent delivery failure")
+
+	// ErrGone means the recipient no longer exists, e.g. an expired push
+	// subscription. It is also permanent.
+	ErrGone = fmt.Errorf("%w: recipient gone", ErrPermanent)
+)
+
+// Message is the channel-neutral content of a notification
+type Message struct {
+	Title string
+	Body  string
+	URL   string // optional link opened from the notification
+	Tag   string // groups or replaces related push notifications
+}
+
+// Sender delivers a message through one channel. The recipient address is
+// channel specific: an email address, a LINE user ID, or a push subscription
+// as JSON (PushSubscription.toJSON() in the browser).
+type Sender interface {
+	Channel() string
+	Send(ctx context.Context, to string, msg Message) error
+}
+
+// IsPermanent reports whether a send failure should not be retried
+func IsPermanent(err error) bool {
+	return errors.Is(err, ErrPermanent)
+}
+
+// permanent wraps err so IsPermanent reports true
+func permanent(err error) error {
+	return fmt.Errorf("%w: %v", ErrPermanent, err)
+}
+
+// statusError turns an unsuccessful HTTP response into an error: 404 and 410
+// mean gone, other 4xx except 408 and 429 are permanent, the rest retryable
+func statusError(service string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err := fmt.Errorf("%s returned %s: %s", service, resp.Status, body)
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return fmt.Errorf("%w: %v", ErrGone, err)
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests:
+		return err
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return permanent(err)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,170 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// SMTPConfig configures the email sender
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string // empty for servers without authentication
+	Password string
+	From     string // e.g. "FitPro <no-reply@example.com>"
+}
+
+// SMTPSender sends plain-text email. STARTTLS is used when the server offers it.
+type SMTPSender struct {
+	cfg     SMTPConfig
+	timeout time.Duration
+}
+
+// NewSMTPSender creates an email sender
+func NewSMTPSender(cfg SMTPConfig) *SMTPSender {
+	return &SMTPSender{cfg: cfg, timeout: 30 * time.Second}
+}
+
+// Channel implements Sender
+func (s *SMTPSender) Channel() string {
+	return ChannelEmail
+}
+
+// Send implements Sender; to is an email address
+func (s *SMTPSender) Send(ctx context.Context, to string, msg Message) error {
+	from, err := mail.ParseAddress(s.cfg.From)
+	if err != nil {
+		return permanent(fmt.Errorf("invalid sender address: %v", err))
+	}
+	rcpt, err := mail.ParseAddress(to)
+	if err != nil {
+		return permanent(fmt.Errorf("invalid recipient address: %v", err))
+	}
+
+	body, err := buildEmail(from, rcpt, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(s.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	addr := net.JoinHostPort(s.cfg.Host, s.cfg.Port)
+	dialer := net.Dialer{Deadline: deadline}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(tlsConfig(s.cfg.Host)); err != nil {
+			return err
+		}
+	}
+	if s.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return smtpError(err)
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return smtpError(err)
+	}
+	if err := client.Rcpt(rcpt.Address); err != nil {
+		return smtpError(err)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return smtpError(err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return smtpError(err)
+	}
+	return client.Quit()
+}
+
+func tlsConfig(host string) *tls.Config {
+	return &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+}
+
+// smtpError marks 5xx replies (mailbox unknown, auth rejected) as permanent
+func smtpError(err error) error {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) && protoErr.Code >= 500 {
+		return permanent(err)
+	}
+	return err
+}
+
+// buildEmail renders a UTF-8 plain-text message; headers are Q-encoded so
+// Thai subjects survive every mail client
+func buildEmail(from, to *mail.Address, msg Message, now time.Time) ([]byte, error) {
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	domain := "localhost"
+	if at := strings.LastIndex(from.Address, "@"); at >= 0 {
+		domain = from.Address[at+1:]
+	}
+
+	var buf bytes.Buffer
+	headers := [][2]string{
+		{"From", from.String()},
+		{"To", to.String()},
+		{"Subject", mime.QEncoding.Encode("utf-8", msg.Title)},
+		{"Date", now.Format(time.RFC1123Z)},
+		{"Message-ID", fmt.Sprintf("<%s@%s>", hex.EncodeToString(id), domain)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", `text/plain; charset="utf-8"`},
+		{"Content-Transfer-Encoding", "quoted-printable"},
+	}
+	for _, h := range headers {
+		fmt.Fprintf(&buf, "%s: %s\r\n", h[0], h[1])
+	}
+	buf.WriteString("\r\n")
+
+	text := msg.Body
+	if msg.URL != "" {
+		text += "\n\n" + msg.URL
+	}
+
+	qp := quotedprintable.NewWriter(&buf)
+	if _, err := qp.Write([]byte(strings.ReplaceAll(text, "\n", "\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\r\n")
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,175 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+// fakeSMTPServer accepts one connection and records the message it receives.
+// rcptReply is sent in answer to RCPT TO.
+type fakeSMTPServer struct {
+	listener  net.Listener
+	rcptReply string
+	from      string
+	rcpt      string
+	data      string
+	done      chan struct{}
+}
+
+func startFakeSMTPServer(t *testing.T, rcptReply string) *fakeSMTPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &fakeSMTPServer{listener: listener, rcptReply: rcptReply, done: make(chan struct{})}
+	t.Cleanup(func() { listener.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *fakeSMTPServer) port() string {
+	_, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return port
+}
+
+func (s *fakeSMTPServer) serve() {
+	defer close(s.done)
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	reply("220 localhost ESMTP fake")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch verb {
+		case "EHLO", "HELO":
+			reply("250-localhost")
+			reply("250 8BITMIME")
+		case "MAIL":
+			s.from = line
+			reply("250 OK")
+		case "RCPT":
+			s.rcpt = line
+			reply(s.rcptReply)
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(dataLine, "."))
+			}
+			s.data = data.String()
+			reply("250 OK queued")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestSMTPSenderSend(t *testing.T) {
+	server := startFakeSMTPServer(t, "250 OK")
+	sender := NewSMTPSender(SMTPConfig{
+		Host: "127.0.0.1",
+		Port: server.port(),
+		From: "FitPro <no-reply@fitpro.test>",
+	})
+
+	msg := Message{
+		Title: "นัดหมายพรุ่งนี้",
+		Body:  "Your session with Coach Ann is on Tue 3 Mar at 07:00\nBring water.",
+		URL:   "https://app.fitpro.test/schedules/12",
+	}
+	if err := sender.Send(context.Background(), "Mint <mint@example.com>", msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	<-server.done
+
+	if server.from != "MAIL FROM:<no-reply@fitpro.test> BODY=8BITMIME" && server.from != "MAIL FROM:<no-reply@fitpro.test>" {
+		t.Errorf("MAIL command = %q", server.from)
+	}
+	if server.rcpt != "RCPT TO:<mint@example.com>" {
+		t.Errorf("RCPT command = %q", server.rcpt)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(server.data))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil {
+		t.Fatalf("decode subject: %v", err)
+	}
+	if subject != msg.Title {
+		t.Errorf("subject = %q, want %q", subject, msg.Title)
+	}
+	if parsed.Header.Get("Message-ID") == "" {
+		t.Error("missing Message-ID")
+	}
+
+	body, err := io.ReadAll(quotedprintable.NewReader(parsed.Body))
+	if err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	want := "Your session with Coach Ann is on Tue 3 Mar at 07:00\r\nBring water.\r\n\r\nhttps://app.fitpro.test/schedules/12\r\n"
+	if string(body) != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+}
+
+func TestSMTPSenderRejectedRecipientIsPermanent(t *testing.T) {
+	server := startFakeSMTPServer(t, "550 5.1.1 No such user")
+	sender := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: server.port(), From: "no-reply@fitpro.test"})
+
+	err := sender.Send(context.Background(), "nobody@example.com", Message{Title: "Hi", Body: "Hello"})
+	if !IsPermanent(err) {
+		t.Fatalf("err = %v, want permanent", err)
+	}
+}
+
+func TestSMTPSenderTemporaryFailureIsRetryable(t *testing.T) {
+	server := startFakeSMTPServer(t, "451 4.3.0 Try again later")
+	sender := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: server.port(), From: "no-reply@fitpro.test"})
+
+	err := sender.Send(context.Background(), "mint@example.com", Message{Title: "Hi", Body: "Hello"})
+	if err == nil || IsPermanent(err) {
+		t.Fatalf("err = %v, want retryable", err)
+	}
+}
+
+func TestSMTPSenderInvalidAddressIsPermanent(t *testing.T) {
+	sender := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: "1", From: "no-reply@fitpro.test"})
+
+	err := sender.Send(context.Background(), "not an address", Message{Title: "Hi"})
+	if !errors.Is(err, ErrPermanent) {
+		t.Fatalf("err = %v, want permanent", err)
+	}
+}
@@ -0,0 +1,283 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	pushRecordSize = 4096
+	pushTTL        = 24 * time.Hour
+	vapidExpiry    = 12 * time.Hour
+
+	// One aes128gcm record: payload + delimiter + 16-byte tag
+	maxPushPayload = pushRecordSize - 17
+)
+
+// WebPushConfig holds the VAPID key pair (base64url, as printed by
+// GenerateVAPIDKeys) and the contact sent to push services
+type WebPushConfig struct {
+	PublicKey  string
+	PrivateKey string
+	Subject    string // "mailto:admin@example.com" or an https URL
+}
+
+// PushSubscription is what the browser's PushSubscription.toJSON() returns
+type PushSubscription struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+// WebPushSender sends encrypted (RFC 8291) Web Push messages signed with
+// VAPID (RFC 8292)
+type WebPushSender struct {
+	cfg        WebPushConfig
+	privateKey *ecdsa.PrivateKey
+	client     *http.Client
+}
+
+// NewWebPushSender validates the VAPID keys and creates a push sender
+func NewWebPushSender(cfg WebPushConfig) (*WebPushSender, error) {
+	d, err := decodeBase64URL(cfg.PrivateKey)
+	if err != nil || len(d) != 32 {
+		return nil, errors.New("VAPID private key must be 32 bytes, base64url encoded")
+	}
+
+	curve := elliptic.P256()
+	key := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(d)}
+	key.PublicKey.Curve = curve
+	key.PublicKey.X, key.PublicKey.Y = curve.ScalarBaseMult(d)
+
+	if cfg.PublicKey != "" {
+		public, err := decodeBase64URL(cfg.PublicKey)
+		if err != nil || !bytes.Equal(public, marshalPublicKey(&key.PublicKey)) {
+			return nil, errors.New("VAPID public key does not match the private key")
+		}
+	}
+	cfg.PublicKey = base64.RawURLEncoding.EncodeToString(marshalPublicKey(&key.PublicKey))
+
+	return &WebPushSender{
+		cfg:        cfg,
+		privateKey: key,
+		client:     &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// GenerateVAPIDKeys creates a new VAPID key pair, base64url encoded
+func GenerateVAPIDKeys() (publicKey, privateKey string, err error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		base64.RawURLEncoding.EncodeToString(key.Bytes()), nil
+}
+
+// PublicKey is the applicationServerKey browsers subscribe with
+func (s *WebPushSender) PublicKey() string {
+	return s.cfg.PublicKey
+}
+
+// Channel implements Sender
+func (s *WebPushSender) Channel() string {
+	return ChannelPush
+}
+
+// Send implements Sender; to is a PushSubscription as JSON
+func (s *WebPushSender) Send(ctx context.Context, to string, msg Message) error {
+	var sub PushSubscription
+	if err := json.Unmarshal([]byte(to), &sub); err != nil {
+		return permanent(fmt.Errorf("invalid push subscription: %v", err))
+	}
+
+	endpoint, err := url.Parse(sub.Endpoint)
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return permanent(fmt.Errorf("invalid push endpoint %q", sub.Endpoint))
+	}
+
+	payload, err := json.Marshal(map[string]string{
+		"title": msg.Title,
+		"body":  msg.Body,
+		"url":   msg.URL,
+		"tag":   msg.Tag,
+	})
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxPushPayload {
+		return permanent(fmt.Errorf("push payload is %d bytes, limit is %d", len(payload), maxPushPayload))
+	}
+
+	body, err := encryptPushPayload(payload, sub)
+	if err != nil {
+		return permanent(err)
+	}
+
+	token, err := s.vapidToken(endpoint.Scheme + "://" + endpoint.Host)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", strconv.Itoa(int(pushTTL.Seconds())))
+	req.Header.Set("Urgency", "normal")
+	req.Header.Set("Authorization", fmt.Sprintf("vapid t=%s, k=%s", token, s.cfg.PublicKey))
+	if msg.Tag != "" {
+		req.Header.Set("Topic", pushTopic(msg.Tag))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return statusError("push service", resp)
+}
+
+// vapidToken signs the JWT push services use to identify the sender
+func (s *WebPushSender) vapidToken(audience string) (string, error) {
+	claims := jwt.MapClaims{
+		"aud": audience,
+		"exp": time.Now().Add(vapidExpiry).Unix(),
+		"sub": s.cfg.Subject,
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodES256, claims).SignedString(s.privateKey)
+}
+
+// encryptPushPayload encrypts a single aes128gcm record for the subscription
+// (RFC 8291 section 3.4)
+func encryptPushPayload(plaintext []byte, sub PushSubscription) ([]byte, error) {
+	uaPublicBytes, err := decodeBase64URL(sub.Keys.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %v", err)
+	}
+	authSecret, err := decodeBase64URL(sub.Keys.Auth)
+	if err != nil || len(authSecret) == 0 {
+		return nil, errors.New("invalid auth secret")
+	}
+
+	curve := ecdh.P256()
+	uaPublic, err := curve.NewPublicKey(uaPublicBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %v", err)
+	}
+	asPrivate, err := curve.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	sharedSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+	asPublicBytes := asPrivate.PublicKey().Bytes()
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	keyInfo := append([]byte("WebPush: info\x00"), uaPublicBytes...)
+	keyInfo = append(keyInfo, asPublicBytes...)
+	ikm, err := hkdfExpand(sharedSecret, authSecret, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := hkdfExpand(ikm, salt, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdfExpand(ikm, salt, []byte("Content-Encoding: nonce\x00"), 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// Header: salt | record size | key id length | key id (our public key)
+	header := make([]byte, 0, 16+4+1+len(asPublicBytes))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, pushRecordSize)
+	header = append(header, byte(len(asPublicBytes)))
+	header = append(header, asPublicBytes...)
+
+	record := append(append([]byte{}, plaintext...), 0x02) // last record delimiter
+	return gcm.Seal(header, nonce, record, nil), nil
+}
+
+func hkdfExpand(secret, salt, info []byte, length int) ([]byte, error) {
+	out := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, info), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// marshalPublicKey encodes a P-256 key in uncompressed form
+func marshalPublicKey(key *ecdsa.PublicKey) []byte {
+	out := make([]byte, 65)
+	out[0] = 4
+	key.X.FillBytes(out[1:33])
+	key.Y.FillBytes(out[33:])
+	return out
+}
+
+// pushTopic keeps a tag within the 32 URL-safe characters allowed in Topic
+func pushTopic(tag string) string {
+	topic := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, tag)
+	if len(topic) > 32 {
+		topic = topic[:32]
+	}
+	return topic
+}
+
+// decodeBase64URL accepts padded or unpadded base64url (browsers send either)
+func decodeBase64URL(value string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+}
@@ -0,0 +1,211 @@
+package notify
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testSubscription plays the browser: it owns the keys the payload is
+// encrypted for
+type testSubscription struct {
+	private *ecdh.PrivateKey
+	auth    []byte
+}
+
+func newTestSubscription(t *testing.T) *testSubscription {
+	t.Helper()
+	private, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := make([]byte, 16)
+	if _, err := rand.Read(auth); err != nil {
+		t.Fatal(err)
+	}
+	return &testSubscription{private: private, auth: auth}
+}
+
+func (s *testSubscription) json(endpoint string) string {
+	var sub PushSubscription
+	sub.Endpoint = endpoint
+	sub.Keys.P256dh = base64.RawURLEncoding.EncodeToString(s.private.PublicKey().Bytes())
+	sub.Keys.Auth = base64.RawURLEncoding.EncodeToString(s.auth)
+	out, _ := json.Marshal(sub)
+	return string(out)
+}
+
+// decrypt reverses encryptPushPayload the way a user agent does
+func (s *testSubscription) decrypt(t *testing.T, body []byte) []byte {
+	t.Helper()
+	if len(body) < 21 {
+		t.Fatalf("body too short: %d bytes", len(body))
+	}
+	salt := body[:16]
+	if rs := binary.BigEndian.Uint32(body[16:20]); rs != pushRecordSize {
+		t.Errorf("record size = %d, want %d", rs, pushRecordSize)
+	}
+	idLen := int(body[20])
+	asPublicBytes := body[21 : 21+idLen]
+	ciphertext := body[21+idLen:]
+
+	asPublic, err := ecdh.P256().NewPublicKey(asPublicBytes)
+	if err != nil {
+		t.Fatalf("server key: %v", err)
+	}
+	shared, err := s.private.ECDH(asPublic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyInfo := append([]byte("WebPush: info\x00"), s.private.PublicKey().Bytes()...)
+	keyInfo = append(keyInfo, asPublicBytes...)
+	ikm, _ := hkdfExpand(shared, s.auth, keyInfo, 32)
+	cek, _ := hkdfExpand(ikm, salt, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce, _ := hkdfExpand(ikm, salt, []byte("Content-Encoding: nonce\x00"), 12)
+
+	block, _ := aes.NewCipher(cek)
+	gcm, _ := cipher.NewGCM(block)
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if len(plaintext) == 0 || plaintext[len(plaintext)-1] != 0x02 {
+		t.Fatalf("missing last record delimiter")
+	}
+	return plaintext[:len(plaintext)-1]
+}
+
+func newTestWebPushSender(t *testing.T) *WebPushSender {
+	t.Helper()
+	public, private, err := GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender, err := NewWebPushSender(WebPushConfig{PublicKey: public, PrivateKey: private, Subject: "mailto:ops@fitpro.test"})
+	if err != nil {
+		t.Fatalf("NewWebPushSender: %v", err)
+	}
+	return sender
+}
+
+func TestWebPushSenderSend(t *testing.T) {
+	sender := newTestWebPushSender(t)
+	browser := newTestSubscription(t)
+
+	var (
+		header http.Header
+		body   []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	msg := Message{Title: "Upcoming session", Body: "Leg day at 07:00", URL: "https://app.fitpro.test/schedules/3", Tag: "schedule:3"}
+	if err := sender.Send(context.Background(), browser.json(server.URL+"/push/abc"), msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if got := header.Get("Content-Encoding"); got != "aes128gcm" {
+		t.Errorf("Content-Encoding = %q", got)
+	}
+	if header.Get("TTL") == "" {
+		t.Error("missing TTL header")
+	}
+	if got := header.Get("Topic"); got != "schedule-3" {
+		t.Errorf("Topic = %q, want schedule-3", got)
+	}
+
+	var payload map[string]string
+	if err := json.Unmarshal(browser.decrypt(t, body), &payload); err != nil {
+		t.Fatalf("payload: %v", err)
+	}
+	if payload["title"] != msg.Title || payload["body"] != msg.Body || payload["url"] != msg.URL {
+		t.Errorf("payload = %v", payload)
+	}
+
+	// Authorization: vapid t=<jwt>, k=<public key>
+	auth := strings.TrimPrefix(header.Get("Authorization"), "vapid ")
+	var token, key string
+	for _, part := range strings.Split(auth, ",") {
+		part = strings.TrimSpace(part)
+		switch {
+		case strings.HasPrefix(part, "t="):
+			token = part[2:]
+		case strings.HasPrefix(part, "k="):
+			key = part[2:]
+		}
+	}
+	if key != sender.PublicKey() {
+		t.Errorf("k = %q, want %q", key, sender.PublicKey())
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return &sender.privateKey.PublicKey, nil
+	}, jwt.WithValidMethods([]string{"ES256"}))
+	if err != nil {
+		t.Fatalf("VAPID token: %v", err)
+	}
+	if claims["aud"] != server.URL {
+		t.Errorf("aud = %v, want %s", claims["aud"], server.URL)
+	}
+	if claims["sub"] != "mailto:ops@fitpro.test" {
+		t.Errorf("sub = %v", claims["sub"])
+	}
+}
+
+func TestWebPushSenderExpiredSubscription(t *testing.T) {
+	sender := newTestWebPushSender(t)
+	browser := newTestSubscription(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer server.Close()
+
+	err := sender.Send(context.Background(), browser.json(server.URL), Message{Title: "Hi"})
+	if !errors.Is(err, ErrGone) || !IsPermanent(err) {
+		t.Fatalf("err = %v, want ErrGone", err)
+	}
+}
+
+func TestWebPushSenderServerErrorIsRetryable(t *testing.T) {
+	sender := newTestWebPushSender(t)
+	browser := newTestSubscription(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	err := sender.Send(context.Background(), browser.json(server.URL), Message{Title: "Hi"})
+	if err == nil || IsPermanent(err) {
+		t.Fatalf("err = %v, want retryable", err)
+	}
+}
+
+func TestNewWebPushSenderRejectsMismatchedKeys(t *testing.T) {
+	public, _, _ := GenerateVAPIDKeys()
+	_, private, _ := GenerateVAPIDKeys()
+
+	if _, err := NewWebPushSender(WebPushConfig{PublicKey: public, PrivateKey: private}); err == nil {
+		t.Fatal("expected an error for mismatched keys")
+	}
+}
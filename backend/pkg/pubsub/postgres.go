@@ -0,0 +1,106 @@
+package pubsub
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// maxNotifyPayload is below Postgres' 8000 byte NOTIFY payload limit
+const maxNotifyPayload = 7900
+
+// PostgresBroker relays messages through a Postgres NOTIFY channel so that
+// subscribers on every API replica receive them. Messages are fanned out
+// locally by a MemoryBroker.
+type PostgresBroker struct {
+	db       *sql.DB
+	channel  string
+	listener *pq.Listener
+	local    *MemoryBroker
+	done     chan struct{}
+}
+
+// NewPostgresBroker listens on channel using its own connection (dsn) and
+// publishes through db
+func NewPostgresBroker(db *sql.DB, dsn, channel string) (*PostgresBroker, error) {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("⚠️  Pub/sub listener: %v", err)
+		}
+	})
+	if err := listener.Listen(channel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("listen on %q: %w", channel, err)
+	}
+
+	b := &PostgresBroker{
+		db:       db,
+		channel:  channel,
+		listener: listener,
+		local:    NewMemoryBroker(),
+		done:     make(chan struct{}),
+	}
+	go b.relay()
+	return b, nil
+}
+
+// Publish implements Broker. The NOTIFY is sent at once on its own
+// connection from db, outside any transaction of the caller, so callers
+// publish only after their transaction has committed.
+func (b *PostgresBroker) Publish(ctx context.Context, topic string, payload []byte) error {
+	message := topic + "\n" + string(payload)
+	if len(message) > maxNotifyPayload {
+		return fmt.Errorf("pubsub: message on %q is %d bytes, limit is %d", topic, len(message), maxNotifyPayload)
+	}
+	_, err := b.db.ExecContext(ctx, "SELECT pg_notify($1, $2)", b.channel, message)
+	return err
+}
+
+// Subscribe implements Broker
+func (b *PostgresBroker) Subscribe(topic string) (*Subscription, error) {
+	return b.local.Subscribe(topic)
+}
+
+// Close implements Broker
+func (b *PostgresBroker) Close() error {
+	err := b.listener.Close()
+	<-b.done
+	b.local.Close()
+	return err
+}
+
+// relay hands notifications from Postgres to local subscribers. A nil
+// notification means the connection was re-established and messages may have
+// been missed, so every subscriber is dropped to make it catch up.
+func (b *PostgresBroker) relay() {
+	defer close(b.done)
+
+	for notification := range b.listener.Notify {
+		if notification == nil {
+			b.local.dropAll()
+			continue
+		}
+
+		topic, payload, ok := strings.Cut(notification.Extra, "\n")
+		if !ok {
+			continue
+		}
+		_ = b.local.Publish(context.Background(), topic, []byte(payload))
+	}
+}
+
+// dropAll closes every subscription but keeps the broker open
+func (b *MemoryBroker) dropAll() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, subs := range b.topics {
+		for sub := range subs {
+			b.dropLocked(sub)
+		}
+	}
+}
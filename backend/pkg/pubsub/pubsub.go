@@ -0,0 +1,120 @@
+// Package pubsub fans out small messages on named topics. The in-memory
+// broker serves a single API process; the Postgres broker relays messages
+// through LISTEN/NOTIFY so every replica sees them.
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// subscriberBuffer is how many messages a slow subscriber may fall behind
+// before it is dropped
+const subscriberBuffer = 16
+
+// ErrClosed is returned after the broker has been closed
+var ErrClosed = errors.New("pubsub: broker closed")
+
+// Broker publishes messages to the current subscribers of a topic. Delivery
+// is best effort: subscribers that fall behind are closed and are expected
+// to catch up from the database when they reconnect.
+type Broker interface {
+	Publish(ctx context.Context, topic string, payload []byte) error
+	Subscribe(topic string) (*Subscription, error)
+	Close() error
+}
+
+// Subscription receives the messages of one topic on C. C is closed when the
+// subscription is closed, falls behind or the broker shuts down.
+type Subscription struct {
+	C <-chan []byte
+
+	ch     chan []byte
+	topic  string
+	broker *MemoryBroker
+	once   sync.Once
+}
+
+// Close stops the subscription
+func (s *Subscription) Close() {
+	s.broker.remove(s)
+}
+
+// MemoryBroker is an in-process Broker
+type MemoryBroker struct {
+	mu     sync.Mutex
+	topics map[string]map[*Subscription]struct{}
+	closed bool
+}
+
+// NewMemoryBroker creates an in-process broker
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{topics: make(map[string]map[*Subscription]struct{})}
+}
+
+// Publish implements Broker
+func (b *MemoryBroker) Publish(_ context.Context, topic string, payload []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrClosed
+	}
+
+	for sub := range b.topics[topic] {
+		select {
+		case sub.ch <- payload:
+		default:
+			b.dropLocked(sub)
+		}
+	}
+	return nil
+}
+
+// Subscribe implements Broker
+func (b *MemoryBroker) Subscribe(topic string) (*Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, ErrClosed
+	}
+
+	ch := make(chan []byte, subscriberBuffer)
+	sub := &Subscription{C: ch, ch: ch, topic: topic, broker: b}
+	if b.topics[topic] == nil {
+		b.topics[topic] = make(map[*Subscription]struct{})
+	}
+	b.topics[topic][sub] = struct{}{}
+	return sub, nil
+}
+
+// Close implements Broker; every subscription is closed
+func (b *MemoryBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for _, subs := range b.topics {
+		for sub := range subs {
+			b.dropLocked(sub)
+		}
+	}
+	return nil
+}
+
+func (b *MemoryBroker) remove(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.dropLocked(sub)
+}
+
+func (b *MemoryBroker) dropLocked(sub *Subscription) {
+	sub.once.Do(func() {
+		if subs := b.topics[sub.topic]; subs != nil {
+			delete(subs, sub)
+			if len(subs) == 0 {
+				delete(b.topics, sub.topic)
+			}
+		}
+		close(sub.ch)
+	})
+}
@@ -0,0 +1,135 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func subscribe(t *testing.T, b *MemoryBroker, topic string) *Subscription {
+	t.Helper()
+	sub, err := b.Subscribe(topic)
+	if err != nil {
+		t.Fatalf("Subscribe(%q): %v", topic, err)
+	}
+	return sub
+}
+
+func publish(t *testing.T, b *MemoryBroker, topic, payload string) {
+	t.Helper()
+	if err := b.Publish(context.Background(), topic, []byte(payload)); err != nil {
+		t.Fatalf("Publish(%q): %v", topic, err)
+	}
+}
+
+// receive returns the next buffered message; ok is false once C is closed
+func receive(t *testing.T, sub *Subscription) (string, bool) {
+	t.Helper()
+	select {
+	case payload, ok := <-sub.C:
+		return string(payload), ok
+	default:
+		t.Fatal("no message buffered")
+		return "", false
+	}
+}
+
+func assertEmpty(t *testing.T, sub *Subscription) {
+	t.Helper()
+	select {
+	case payload, ok := <-sub.C:
+		t.Fatalf("unexpected message %q (open %v)", payload, ok)
+	default:
+	}
+}
+
+func TestMemoryBrokerFanOut(t *testing.T) {
+	b := NewMemoryBroker()
+	first := subscribe(t, b, "user:1")
+	second := subscribe(t, b, "user:1")
+	other := subscribe(t, b, "user:2")
+
+	publish(t, b, "user:1", "hello")
+	publish(t, b, "user:3", "nobody listens")
+
+	for _, sub := range []*Subscription{first, second} {
+		if payload, ok := receive(t, sub); !ok || payload != "hello" {
+			t.Errorf("received %q (open %v), want hello", payload, ok)
+		}
+	}
+	assertEmpty(t, other)
+
+	// A closed subscription no longer receives and leaves the others be
+	first.Close()
+	first.Close()
+	publish(t, b, "user:1", "again")
+	if _, ok := receive(t, first); ok {
+		t.Error("closed subscription is still open")
+	}
+	if payload, ok := receive(t, second); !ok || payload != "again" {
+		t.Errorf("received %q (open %v), want again", payload, ok)
+	}
+}
+
+func TestMemoryBrokerDropsSlowSubscriber(t *testing.T) {
+	b := NewMemoryBroker()
+	slow := subscribe(t, b, "user:1")
+	fast := subscribe(t, b, "user:1")
+
+	for i := 0; i < subscriberBuffer; i++ {
+		publish(t, b, "user:1", "message")
+		if _, ok := receive(t, fast); !ok {
+			t.Fatal("fast subscriber was dropped")
+		}
+	}
+	// The slow subscriber's buffer is full now
+	publish(t, b, "user:1", "overflow")
+
+	for i := 0; i < subscriberBuffer; i++ {
+		if _, ok := receive(t, slow); !ok {
+			t.Fatalf("buffered message %d was lost", i)
+		}
+	}
+	if _, ok := receive(t, slow); ok {
+		t.Error("slow subscriber was not dropped")
+	}
+	if payload, ok := receive(t, fast); !ok || payload != "overflow" {
+		t.Errorf("fast subscriber received %q (open %v), want overflow", payload, ok)
+	}
+
+	// Closing a dropped subscription is harmless
+	slow.Close()
+	publish(t, b, "user:1", "after")
+	if payload, ok := receive(t, fast); !ok || payload != "after" {
+		t.Errorf("fast subscriber received %q (open %v), want after", payload, ok)
+	}
+}
+
+func TestMemoryBrokerClose(t *testing.T) {
+	b := NewMemoryBroker()
+	first := subscribe(t, b, "user:1")
+	second := subscribe(t, b, "user:2")
+	publish(t, b, "user:1", "pending")
+
+	if err := b.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// Messages buffered before the close are still delivered
+	if payload, ok := receive(t, first); !ok || payload != "pending" {
+		t.Errorf("received %q (open %v), want pending", payload, ok)
+	}
+	for _, sub := range []*Subscription{first, second} {
+		if _, ok := receive(t, sub); ok {
+			t.Error("subscription is open after Close")
+		}
+		sub.Close()
+	}
+
+	if err := b.Publish(context.Background(), "user:1", nil); !errors.Is(err, ErrClosed) {
+		t.Errorf("Publish after Close = %v, want ErrClosed", err)
+	}
+	if _, err := b.Subscribe("user:1"); !errors.Is(err, ErrClosed) {
+		t.Errorf("Subscribe after Close = %v, want ErrClosed", err)
+	}
+}
@@ -0,0 +1,185 @@
+// Package stats computes trainee training statistics (streaks, weekly
+// frequency, workout hours) from the list of completed sessions. It is pure
+// so the cached Trainee columns and on-demand reports agree.
+package stats
+
+import (
+	"sort"
+	"time"
+)
+
+// DefaultWeeklyTarget is the number of sessions per ISO week that keeps a
+// weekly streak alive when the trainee has no target of their own
+const DefaultWeeklyTarget = 1
+
+// Session is one completed workout
+type Session struct {
+	Date    time.Time // calendar date in the gym's time zone
+	Minutes int
+}
+
+// Options tunes the streak rules
+type Options struct {
+	// WeeklyTarget is the minimum sessions per ISO week (Monday-Sunday)
+	WeeklyTarget int
+}
+
+// Summary is the result of Compute
+type Summary struct {
+	TotalSessions int
+	TotalMinutes  int
+
+	// Consecutive days with at least one session. The current streak is
+	// still alive if the last session was today or yesterday.
+	CurrentDailyStreak int
+	LongestDailyStreak int
+
+	// Consecutive ISO weeks meeting WeeklyTarget. The week in progress only
+	// counts once it meets the target; until then it does not break the streak.
+	CurrentWeeklyStreak int
+	LongestWeeklyStreak int
+
+	// Sessions per week from the first session's week through today's week
+	AverageSessionsPerWeek float64
+
+	LastSessionDate *time.Time
+}
+
+// TotalHours converts TotalMinutes to hours
+func (s Summary) TotalHours() float64 {
+	return float64(s.TotalMinutes) / 60
+}
+
+// Compute summarises the sessions as of today (a date in the gym's time zone)
+func Compute(sessions []Session, today time.Time, opts Options) Summary {
+	if opts.WeeklyTarget < 1 {
+		opts.WeeklyTarget = DefaultWeeklyTarget
+	}
+	today = dateOnly(today)
+
+	summary := Summary{TotalSessions: len(sessions)}
+	if len(sessions) == 0 {
+		return summary
+	}
+
+	days := map[time.Time]bool{}
+	weeks := map[time.Time]int{}
+	for _, session := range sessions {
+		summary.TotalMinutes += session.Minutes
+		day := dateOnly(session.Date)
+		days[day] = true
+		weeks[weekStart(day)]++
+	}
+
+	ordered := make([]time.Time, 0, len(days))
+	for day := range days {
+		ordered = append(ordered, day)
+	}
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].Before(ordered[j]) })
+
+	last := ordered[len(ordered)-1]
+	summary.LastSessionDate = &last
+
+	summary.LongestDailyStreak, summary.CurrentDailyStreak = dailyStreaks(ordered, today)
+	summary.LongestWeeklyStreak, summary.CurrentWeeklyStreak = weeklyStreaks(weeks, opts.WeeklyTarget, today)
+
+	first := weekStart(ordered[0])
+	current := weekStart(today)
+	if current.Before(first) {
+		current = first
+	}
+	weekCount := int(current.Sub(first).Hours()/24/7) + 1
+	summary.AverageSessionsPerWeek = float64(len(sessions)) / float64(weekCount)
+
+	return summary
+}
+
+// DailyStreakAsOf returns a daily streak cached when the last session was
+// logged as it stands today: lapsed unless that session was today or
+// yesterday
+func DailyStreakAsOf(streak int, lastSession *time.Time, today time.Time) int {
+	if lastSession == nil || dateOnly(*lastSession).Before(addDays(dateOnly(today), -1)) {
+		return 0
+	}
+	return streak
+}
+
+// dailyStreaks walks the ordered training days and returns the longest run
+// and the run ending today or yesterday
+func dailyStreaks(ordered []time.Time, today time.Time) (longest, current int) {
+	run := 0
+	for i, day := range ordered {
+		if i > 0 && addDays(ordered[i-1], 1).Equal(day) {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+	}
+
+	last := ordered[len(ordered)-1]
+	if last.Equal(today) || addDays(last, 1).Equal(today) {
+		current = run
+	}
+	return longest, current
+}
+
+// weeklyStreaks counts consecutive weeks meeting the target
+func weeklyStreaks(weeks map[time.Time]int, target int, today time.Time) (longest, current int) {
+	met := make([]time.Time, 0, len(weeks))
+	for week, count := range weeks {
+		if count >= target {
+			met = append(met, week)
+		}
+	}
+	if len(met) == 0 {
+		return 0, 0
+	}
+	sort.Slice(met, func(i, j int) bool { return met[i].Before(met[j]) })
+
+	run := 0
+	for i, week := range met {
+		if i > 0 && addDays(met[i-1], 7).Equal(week) {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+	}
+
+	thisWeek := weekStart(today)
+	last := met[len(met)-1]
+	if last.Equal(thisWeek) || addDays(last, 7).Equal(thisWeek) {
+		current = run
+	}
+	return longest, current
+}
+
+// weekStart returns the Monday of the ISO week containing day
+func weekStart(day time.Time) time.Time {
+	offset := (int(day.Weekday()) + 6) % 7
+	return addDays(day, -offset)
+}
+
+// dateOnly drops the clock, keeping the calendar date as written
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// addDays moves by calendar days; dates are in UTC so there is no DST drift
+func addDays(day time.Time, n int) time.Time {
+	return day.AddDate(0, 0, n)
+}
+
+// Today returns today's date in the named time zone (UTC if it is unknown)
+func Today(timezone string) time.Time {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	return dateOnly(time.Now().In(loc))
+}
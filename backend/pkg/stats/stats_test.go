@@ -0,0 +1,133 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+// March 2024: the 4th, 11th, 18th and 25th are Mondays
+func day(d int) time.Time {
+	return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC)
+}
+
+func sessionsOn(days ...int) []Session {
+	sessions := make([]Session, 0, len(days))
+	for _, d := range days {
+		sessions = append(sessions, Session{Date: day(d), Minutes: 60})
+	}
+	return sessions
+}
+
+func TestComputeDailyStreaks(t *testing.T) {
+	tests := []struct {
+		name             string
+		days             []int
+		today            int
+		current, longest int
+	}{
+		{"no sessions", nil, 20, 0, 0},
+		{"trained today", []int{18, 19, 20}, 20, 3, 3},
+		{"trained yesterday", []int{18, 19}, 20, 2, 2},
+		{"one day gap ends the streak", []int{17, 18}, 20, 0, 2},
+		{"gap in the middle", []int{10, 11, 12, 14, 15}, 15, 2, 3},
+		{"two sessions on a day count once", []int{19, 19, 20}, 20, 2, 2},
+		{"across a month end", []int{1, 2}, 2, 2, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := Compute(sessionsOn(tt.days...), day(tt.today), Options{})
+			if summary.CurrentDailyStreak != tt.current || summary.LongestDailyStreak != tt.longest {
+				t.Errorf("daily streak = %d (longest %d), want %d (longest %d)",
+					summary.CurrentDailyStreak, summary.LongestDailyStreak, tt.current, tt.longest)
+			}
+		})
+	}
+}
+
+func TestComputeMonthEnd(t *testing.T) {
+	sessions := []Session{
+		{Date: time.Date(2024, 2, 28, 0, 0, 0, 0, time.UTC)},
+		{Date: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{Date: day(1)},
+	}
+	summary := Compute(sessions, day(1), Options{})
+	if summary.CurrentDailyStreak != 3 {
+		t.Errorf("streak over a leap day = %d, want 3", summary.CurrentDailyStreak)
+	}
+}
+
+func TestComputeWeeklyStreaks(t *testing.T) {
+	tests := []struct {
+		name             string
+		days             []int
+		today            int
+		target           int
+		current, longest int
+	}{
+		{"every week", []int{4, 12, 20}, 20, 1, 3, 3},
+		// Sunday the 10th and Monday the 11th are in different weeks
+		{"week boundary", []int{10, 11}, 11, 1, 2, 2},
+		{"week in progress does not break it", []int{4, 11}, 20, 1, 2, 2},
+		{"a missed week breaks it", []int{4}, 20, 1, 0, 1},
+		{"gap in the middle", []int{4, 18, 25}, 25, 1, 2, 2},
+		{"target met", []int{4, 5, 11, 12}, 13, 2, 2, 2},
+		{"target missed last week", []int{4, 5, 11}, 19, 2, 0, 1},
+		{"target not yet met this week", []int{4, 5, 11}, 13, 2, 1, 1},
+		{"week in progress counts once it meets the target", []int{4, 5, 11, 12, 18, 19}, 20, 2, 3, 3},
+		{"sessions on one day count towards the target", []int{4, 4}, 5, 2, 1, 1},
+		{"no target means one a week", []int{4, 11}, 11, 0, 2, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := Compute(sessionsOn(tt.days...), day(tt.today), Options{WeeklyTarget: tt.target})
+			if summary.CurrentWeeklyStreak != tt.current || summary.LongestWeeklyStreak != tt.longest {
+				t.Errorf("weekly streak = %d (longest %d), want %d (longest %d)",
+					summary.CurrentWeeklyStreak, summary.LongestWeeklyStreak, tt.current, tt.longest)
+			}
+		})
+	}
+}
+
+func TestComputeTotals(t *testing.T) {
+	summary := Compute(sessionsOn(4, 5, 12, 20), day(20), Options{})
+	if summary.TotalSessions != 4 || summary.TotalMinutes != 240 || summary.TotalHours() != 4 {
+		t.Errorf("totals = %d sessions, %d minutes", summary.TotalSessions, summary.TotalMinutes)
+	}
+	// Weeks of the 4th, 11th and 18th
+	if summary.AverageSessionsPerWeek != 4.0/3 {
+		t.Errorf("average = %v, want %v", summary.AverageSessionsPerWeek, 4.0/3)
+	}
+	if summary.LastSessionDate == nil || !summary.LastSessionDate.Equal(day(20)) {
+		t.Errorf("last session = %v, want the 20th", summary.LastSessionDate)
+	}
+}
+
+func TestComputeIgnoresClock(t *testing.T) {
+	sessions := []Session{{Date: time.Date(2024, 3, 19, 23, 30, 0, 0, time.FixedZone("ICT", 7*3600))}}
+	summary := Compute(sessions, time.Date(2024, 3, 20, 8, 0, 0, 0, time.UTC), Options{})
+	if summary.CurrentDailyStreak != 1 {
+		t.Errorf("streak = %d, want 1", summary.CurrentDailyStreak)
+	}
+}
+
+func TestDailyStreakAsOf(t *testing.T) {
+	last := day(18)
+	tests := []struct {
+		name  string
+		last  *time.Time
+		today int
+		want  int
+	}{
+		{"no session", nil, 20, 0},
+		{"same day", &last, 18, 5},
+		{"next day", &last, 19, 5},
+		{"lapsed", &last, 20, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DailyStreakAsOf(5, tt.last, day(tt.today)); got != tt.want {
+				t.Errorf("DailyStreakAsOf = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
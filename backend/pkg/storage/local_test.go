@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocalStorageRoundTrip(t *testing.T) {
+	store, err := NewLocalStorage(LocalConfig{Dir: t.TempDir(), BaseURL: "https://api.fitpro.test/api/v1/files/", Secret: "s3cret"})
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+	ctx := context.Background()
+
+	data := []byte("GIF89a")
+	if err := store.Put(ctx, "media/3/a1.gif", "image/gif", bytes.NewReader(data), int64(len(data))); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	reader, err := store.Get(ctx, "media/3/a1.gif")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, _ := io.ReadAll(reader)
+	reader.Close()
+	if !bytes.Equal(got, data) {
+		t.Errorf("Get = %q, want %q", got, data)
+	}
+
+	if err := store.Delete(ctx, "media/3/a1.gif"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Get(ctx, "media/3/a1.gif"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrNotFound", err)
+	}
+	if _, err := store.Get(ctx, "../outside"); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Get(../outside) = %v, want ErrInvalidKey", err)
+	}
+}
+
+func TestLocalStorageSignedURL(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	store, err := NewLocalStorage(LocalConfig{Dir: t.TempDir(), BaseURL: "https://api.fitpro.test/api/v1/files", Secret: "s3cret"})
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+	store.now = func() time.Time { return now }
+
+	signed, err := store.SignedURL("media/3/a1.jpg", 15*time.Minute)
+	if err != nil {
+		t.Fatalf("SignedURL: %v", err)
+	}
+	parsed, err := url.Parse(signed)
+	if err != nil {
+		t.Fatalf("parse %q: %v", signed, err)
+	}
+	if !strings.HasPrefix(signed, "https://api.fitpro.test/api/v1/files/media/3/a1.jpg?") {
+		t.Errorf("SignedURL = %q", signed)
+	}
+	expires, signature := parsed.Query().Get("expires"), parsed.Query().Get("signature")
+
+	if err := store.Verify("media/3/a1.jpg", expires, signature); err != nil {
+		t.Errorf("Verify = %v", err)
+	}
+	if err := store.Verify("media/3/a2.jpg", expires, signature); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify of another key = %v, want ErrInvalidSignature", err)
+	}
+	if err := store.Verify("media/3/a1.jpg", expires+"0", signature); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify of a changed expiry = %v, want ErrInvalidSignature", err)
+	}
+
+	now = now.Add(16 * time.Minute)
+	if err := store.Verify("media/3/a1.jpg", expires, signature); !errors.Is(err, ErrExpired) {
+		t.Errorf("Verify after expiry = %v, want ErrExpired", err)
+	}
+}
@@ -0,0 +1,51 @@
+// Package storage keeps uploaded files in a local directory or an
+// S3-compatible bucket (AWS S3, MinIO, Cloudflare R2...) and hands out
+// time-limited download URLs for them.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound means no object is stored under the key
+	ErrNotFound = errors.New("object not found")
+
+	// ErrInvalidKey rejects keys that are empty, absolute or climb out of
+	// the storage root
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+// Storage stores objects under slash-separated keys such as
+// "media/12/3f9a.jpg"
+type Storage interface {
+	// Put stores an object, replacing any object with the same key
+	Put(ctx context.Context, key, contentType string, body io.ReadSeeker, size int64) error
+
+	// Get opens an object for reading. Local objects are also io.Seeker,
+	// so they can be served with range requests.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete removes an object. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+
+	// SignedURL returns a URL that downloads the object until it expires
+	SignedURL(key string, expiry time.Duration) (string, error)
+}
+
+// validKey accepts relative keys without empty, "." or ".." segments
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, `\`) {
+		return ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+// Package units converts body metrics and exercise sets between the
+// canonical units they are stored in (kg, km, cm) and the units users prefer.
+// Every supported conversion is a plain scale factor, so differences and
+// rates convert like values.
+package units
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownUnit is returned for units that are not supported or do not fit
+// the quantity
+var ErrUnknownUnit = errors.New("unknown unit")
+
+// Unit is a unit of measure
+type Unit string
+
+// Supported units
+const (
+	Kilogram   Unit = "kg"
+	Pound      Unit = "lb"
+	Kilometer  Unit = "km"
+	Mile       Unit = "mi"
+	Centimeter Unit = "cm"
+	Inch       Unit = "in"
+	Percent    Unit = "%"
+)
+
+// Kind is the quantity a unit measures
+type Kind string
+
+// Quantities
+const (
+	Mass     Kind = "mass"     // body weight and loads
+	Distance Kind = "distance" // cardio distances
+	Length   Kind = "length"   // height and body measurements
+	Ratio    Kind = "ratio"    // body fat
+)
+
+// unitInfo describes a unit: its quantity and how many canonical units one of
+// it is
+type unitInfo struct {
+	kind   Kind
+	factor float64
+}
+
+var known = map[Unit]unitInfo{
+	Kilogram:   {Mass, 1},
+	Pound:      {Mass, 0.45359237},
+	Kilometer:  {Distance, 1},
+	Mile:       {Distance, 1.609344},
+	Centimeter: {Length, 1},
+	Inch:       {Length, 2.54},
+	Percent:    {Ratio, 1},
+}
+
+// aliases are the other spellings Parse accepts
+var aliases = map[string]Unit{
+	"kgs":         Kilogram,
+	"kilogram":    Kilogram,
+	"kilograms":   Kilogram,
+	"lbs":         Pound,
+	"pound":       Pound,
+	"pounds":      Pound,
+	"kilometer":   Kilometer,
+	"kilometers":  Kilometer,
+	"kilometre":   Kilometer,
+	"kilometres":  Kilometer,
+	"mile":        Mile,
+	"miles":       Mile,
+	"centimeter":  Centimeter,
+	"centimeters": Centimeter,
+	"centimetre":  Centimeter,
+	"centimetres": Centimeter,
+	"inch":        Inch,
+	"inches":      Inch,
+	"percent":     Percent,
+}
+
+var canonical = map[Kind]Unit{
+	Mass:     Kilogram,
+	Distance: Kilometer,
+	Length:   Centimeter,
+	Ratio:    Percent,
+}
+
+// Parse reads a unit, ignoring case, surrounding space and plurals
+func Parse(value string) (Unit, error) {
+	name := strings.ToLower(strings.TrimSpace(value))
+	if unit, ok := aliases[name]; ok {
+		return unit, nil
+	}
+	if _, ok := known[Unit(name)]; ok {
+		return Unit(name), nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownUnit, value)
+}
+
+// ParseKind reads a unit that must measure kind
+func ParseKind(value string, kind Kind) (Unit, error) {
+	unit, err := Parse(value)
+	if err != nil {
+		return "", err
+	}
+	if unit.Kind() != kind {
+		return "", fmt.Errorf("%w: %s is not a unit of %s", ErrUnknownUnit, unit, kind)
+	}
+	return unit, nil
+}
+
+// Kind returns the quantity the unit measures, or "" for unknown units
+func (u Unit) Kind() Kind {
+	return known[u].kind
+}
+
+// Canonical returns the unit values of a quantity are stored in
+func Canonical(kind Kind) Unit {
+	return canonical[kind]
+}
+
+// ToCanonical converts a value in unit to the canonical unit of its quantity.
+// Unknown units are left as they are.
+func ToCanonical(value float64, unit Unit) float64 {
+	info, ok := known[unit]
+	if !ok {
+		return value
+	}
+	return value * info.factor
+}
+
+// FromCanonical converts a value in the canonical unit of unit's quantity to
+// unit. Unknown units are left as they are.
+func FromCanonical(value float64, unit Unit) float64 {
+	info, ok := known[unit]
+	if !ok {
+		return value
+	}
+	return value / info.factor
+}
+
+// Preferences are the units a user enters and reads values in
+type Preferences struct {
+	Weight   Unit // kg or lb
+	Distance Unit // km or mi
+	Length   Unit // cm or in
+}
+
+// Metric returns the canonical preferences
+func Metric() Preferences {
+	return Preferences{Weight: Kilogram, Distance: Kilometer, Length: Centimeter}
+}
+
+// Imperial returns lb, mi and in
+func Imperial() Preferences {
+	return Preferences{Weight: Pound, Distance: Mile, Length: Inch}
+}
+
+// For returns the preferred unit of a quantity
+func (p Preferences) For(kind Kind) Unit {
+	var unit Unit
+	switch kind {
+	case Mass:
+		unit = p.Weight
+	case Distance:
+		unit = p.Distance
+	case Length:
+		unit = p.Length
+	}
+	if unit.Kind() != kind {
+		return Canonical(kind)
+	}
+	return unit
+}
+
+// Validate checks that every preference is a unit of its quantity
+func (p Preferences) Validate() error {
+	for kind, unit := range map[Kind]Unit{Mass: p.Weight, Distance: p.Distance, Length: p.Length} {
+		if unit.Kind() != kind {
+			return fmt.Errorf("%w: %q is not a unit of %s", ErrUnknownUnit, unit, kind)
+		}
+	}
+	return nil
+}
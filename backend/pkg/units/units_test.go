@@ -0,0 +1,91 @@
+package units
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Unit
+	}{
+		{"kg", Kilogram},
+		{" KG ", Kilogram},
+		{"lbs", Pound},
+		{"Pounds", Pound},
+		{"km", Kilometer},
+		{"miles", Mile},
+		{"cm", Centimeter},
+		{"inches", Inch},
+		{"%", Percent},
+		{"percent", Percent},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("Parse(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "stone", "kgs2", "ft"} {
+		if _, err := Parse(in); !errors.Is(err, ErrUnknownUnit) {
+			t.Errorf("Parse(%q) error = %v, want ErrUnknownUnit", in, err)
+		}
+	}
+}
+
+func TestParseKind(t *testing.T) {
+	if unit, err := ParseKind("lb", Mass); err != nil || unit != Pound {
+		t.Errorf("ParseKind(lb, Mass) = %q, %v", unit, err)
+	}
+	if _, err := ParseKind("cm", Mass); !errors.Is(err, ErrUnknownUnit) {
+		t.Errorf("ParseKind(cm, Mass) error = %v, want ErrUnknownUnit", err)
+	}
+	if _, err := ParseKind("mi", Length); !errors.Is(err, ErrUnknownUnit) {
+		t.Errorf("ParseKind(mi, Length) error = %v, want ErrUnknownUnit", err)
+	}
+}
+
+func TestConversions(t *testing.T) {
+	tests := []struct {
+		value float64
+		unit  Unit
+		want  float64 // canonical
+	}{
+		{100, Kilogram, 100},
+		{220.462262, Pound, 100},
+		{3.10686, Mile, 5},
+		{12, Inch, 30.48},
+		{18.5, Percent, 18.5},
+	}
+	for _, tt := range tests {
+		got := ToCanonical(tt.value, tt.unit)
+		if math.Abs(got-tt.want) > 1e-4 {
+			t.Errorf("ToCanonical(%v, %s) = %v, want %v", tt.value, tt.unit, got, tt.want)
+		}
+		if back := FromCanonical(got, tt.unit); math.Abs(back-tt.value) > 1e-9 {
+			t.Errorf("FromCanonical(ToCanonical(%v, %s)) = %v", tt.value, tt.unit, back)
+		}
+	}
+}
+
+func TestPreferences(t *testing.T) {
+	prefs := Imperial()
+	if err := prefs.Validate(); err != nil {
+		t.Fatalf("Imperial().Validate() = %v", err)
+	}
+	if prefs.For(Mass) != Pound || prefs.For(Distance) != Mile || prefs.For(Length) != Inch || prefs.For(Ratio) != Percent {
+		t.Errorf("Imperial().For = %s %s %s %s", prefs.For(Mass), prefs.For(Distance), prefs.For(Length), prefs.For(Ratio))
+	}
+
+	// Unset or mismatched preferences fall back to the canonical unit
+	mixed := Preferences{Weight: Pound, Distance: Inch}
+	if mixed.For(Distance) != Kilometer || mixed.For(Length) != Centimeter {
+		t.Errorf("For with invalid preferences = %s %s, want km cm", mixed.For(Distance), mixed.For(Length))
+	}
+	if err := mixed.Validate(); !errors.Is(err, ErrUnknownUnit) {
+		t.Errorf("Validate() = %v, want ErrUnknownUnit", err)
+	}
+}
@@ -14,18 +14,20 @@ var (
 
 // JWTClaims represents JWT claims
 type JWTClaims struct {
-	UserID uint   `json:"userId"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	UserID    uint   `json:"userId"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid,omitempty"` // refresh token family the access token belongs to
 	jwt.RegisteredClaims
 }
 
 // GenerateAccessToken generates a new JWT access token
-func GenerateAccessToken(userID uint, email, role, secret string, expiry time.Duration) (string, error) {
+func GenerateAccessToken(userID uint, email, role, sessionID, secret string, expiry time.Duration) (string, error) {
 	claims := JWTClaims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
+		UserID:    userID,
+		Email:     email,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -37,22 +39,6 @@ func GenerateAccessToken(userID uint, email, role, secret string, expiry time.Du
 	return token.SignedString([]byte(secret))
 }
 
-// GenerateRefreshToken generates a new refresh token
-func GenerateRefreshToken(userID uint, email, role, secret string, expiry time.Duration) (string, error) {
-	claims := JWTClaims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
-}
-
 // ValidateToken validates a JWT token and returns claims
 func ValidateToken(tokenString, secret string) (*JWTClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
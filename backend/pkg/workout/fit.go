@@ -0,0 +1,251 @@
+package workout
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// FIT is Garmin's binary activity format: a header followed by definition
+// messages, which lay out the fields of a local message type, and the data
+// messages using them. Only the messages and fields needed here are read.
+
+// Global message numbers
+const (
+	fitMessageSport   = 12
+	fitMessageSession = 18
+	fitMessageLap     = 19
+	fitMessageRecord  = 20
+)
+
+// Field numbers of the session, lap and record messages
+const (
+	fitFieldTimestamp    = 253
+	fitFieldStartTime    = 2
+	fitFieldSessionSport = 5
+	fitFieldElapsedTime  = 7 // ms
+	fitFieldTimerTime    = 8 // ms
+	fitFieldDistance     = 9 // cm
+	fitFieldSessionAvgHR = 16
+	fitFieldSessionMaxHR = 17
+	fitFieldLapAvgHR     = 15
+	fitFieldLapMaxHR     = 16
+	fitFieldRecordHR     = 3
+	fitFieldSportSport   = 0
+)
+
+// fitEpoch is the zero of FIT timestamps
+var fitEpoch = time.Date(1989, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// fitSports maps FIT sport numbers to sports
+var fitSports = map[uint64]string{
+	1:  SportRunning,
+	2:  SportCycling,
+	5:  SportSwimming,
+	11: SportWalking,
+	15: SportRowing,
+	17: SportHiking,
+}
+
+type fitField struct {
+	num  byte
+	size int
+}
+
+type fitDefinition struct {
+	global    uint16
+	bigEndian bool
+	fields    []fitField
+	devSize   int // bytes of developer fields, skipped
+}
+
+func isFIT(data []byte) bool {
+	return len(data) >= 12 && (data[0] == 12 || data[0] == 14) && string(data[8:12]) == ".FIT"
+}
+
+// parseFIT reads the first session of a FIT activity with its laps and
+// heart-rate records. The file CRC is not checked.
+func parseFIT(data []byte) (*Activity, error) {
+	truncated := fmt.Errorf("%w: the FIT file is truncated", ErrInvalidFile)
+
+	headerSize := int(data[0])
+	end := headerSize + int(binary.LittleEndian.Uint32(data[4:8]))
+	if end > len(data) {
+		return nil, truncated
+	}
+
+	activity := &Activity{Format: FormatFIT}
+	var sessionEnd time.Time
+	sessions := 0
+	definitions := map[byte]*fitDefinition{}
+	var lastTimestamp uint32
+
+	for pos := headerSize; pos < end; {
+		header := data[pos]
+		pos++
+
+		var local byte
+		var timestamp uint32
+		switch {
+		case header&0x80 != 0:
+			// Compressed timestamp header: the low 5 bits of the timestamp
+			// as an offset from the last full one
+			local = (header >> 5) & 0x03
+			offset := uint32(header & 0x1f)
+			timestamp = lastTimestamp + ((offset - lastTimestamp) & 0x1f)
+			lastTimestamp = timestamp
+		case header&0x40 != 0:
+			if pos+5 > end {
+				return nil, truncated
+			}
+			def := &fitDefinition{bigEndian: data[pos+1] == 1}
+			if def.bigEndian {
+				def.global = binary.BigEndian.Uint16(data[pos+2:])
+			} else {
+				def.global = binary.LittleEndian.Uint16(data[pos+2:])
+			}
+			count := int(data[pos+4])
+			pos += 5
+			if pos+3*count > end {
+				return nil, truncated
+			}
+			for i := 0; i < count; i++ {
+				def.fields = append(def.fields, fitField{num: data[pos], size: int(data[pos+1])})
+				pos += 3
+			}
+			if header&0x20 != 0 {
+				if pos >= end {
+					return nil, truncated
+				}
+				count := int(data[pos])
+				pos++
+				if pos+3*count > end {
+					return nil, truncated
+				}
+				for i := 0; i < count; i++ {
+					def.devSize += int(data[pos+1])
+					pos += 3
+				}
+			}
+			definitions[header&0x0f] = def
+			continue
+		default:
+			local = header & 0x0f
+		}
+
+		def, ok := definitions[local]
+		if !ok {
+			return nil, fmt.Errorf("%w: FIT data message without a definition", ErrInvalidFile)
+		}
+		values := make(map[byte]uint64, len(def.fields))
+		for _, field := range def.fields {
+			if pos+field.size > end {
+				return nil, truncated
+			}
+			if value, ok := fitValue(data[pos:pos+field.size], def.bigEndian); ok {
+				values[field.num] = value
+			}
+			pos += field.size
+		}
+		pos += def.devSize
+		if pos > end {
+			return nil, truncated
+		}
+		if value, ok := values[fitFieldTimestamp]; ok {
+			timestamp = uint32(value)
+			lastTimestamp = timestamp
+		}
+
+		switch def.global {
+		case fitMessageSport:
+			if sport, ok := fitSports[values[fitFieldSportSport]]; ok && activity.Sport == "" {
+				activity.Sport = sport
+			}
+		case fitMessageSession:
+			sessions++
+			if sessions > 1 {
+				continue
+			}
+			if sport, ok := values[fitFieldSessionSport]; ok {
+				if name, known := fitSports[sport]; known {
+					activity.Sport = name
+				} else {
+					activity.Sport = SportOther
+				}
+			}
+			if start, ok := values[fitFieldStartTime]; ok {
+				activity.StartTime = fitTime(start)
+			}
+			if timer, ok := values[fitFieldTimerTime]; ok {
+				activity.Duration = time.Duration(timer) * time.Millisecond
+			}
+			if elapsed, ok := values[fitFieldElapsedTime]; ok {
+				sessionEnd = activity.StartTime.Add(time.Duration(elapsed) * time.Millisecond)
+				if activity.Duration == 0 {
+					activity.Duration = time.Duration(elapsed) * time.Millisecond
+				}
+			}
+			activity.Distance = float64(values[fitFieldDistance]) / 100
+			activity.AvgHeartRate = int(values[fitFieldSessionAvgHR])
+			activity.MaxHeartRate = int(values[fitFieldSessionMaxHR])
+		case fitMessageLap:
+			lap := Lap{
+				Distance:     float64(values[fitFieldDistance]) / 100,
+				AvgHeartRate: int(values[fitFieldLapAvgHR]),
+				MaxHeartRate: int(values[fitFieldLapMaxHR]),
+			}
+			if start, ok := values[fitFieldStartTime]; ok {
+				lap.StartTime = fitTime(start)
+			}
+			if timer, ok := values[fitFieldTimerTime]; ok {
+				lap.Duration = time.Duration(timer) * time.Millisecond
+			} else if elapsed, ok := values[fitFieldElapsedTime]; ok {
+				lap.Duration = time.Duration(elapsed) * time.Millisecond
+			}
+			activity.Laps = append(activity.Laps, lap)
+		case fitMessageRecord:
+			if hr, ok := values[fitFieldRecordHR]; ok && hr > 0 && timestamp != 0 {
+				activity.Samples = append(activity.Samples, Sample{Time: fitTime(uint64(timestamp)), HeartRate: int(hr)})
+			}
+		}
+	}
+
+	if sessions == 0 && len(activity.Laps) == 0 {
+		return nil, fmt.Errorf("%w: the FIT file has no session or laps", ErrInvalidFile)
+	}
+	// Laps of the legs after the first session of a multisport file
+	if sessions > 1 && !sessionEnd.IsZero() {
+		laps := activity.Laps[:0]
+		for _, lap := range activity.Laps {
+			if lap.StartTime.Before(sessionEnd) {
+				laps = append(laps, lap)
+			}
+		}
+		activity.Laps = laps
+	}
+	return activity, nil
+}
+
+// fitValue reads an unsigned field; all-ones values mean "not recorded".
+// Fields of other sizes (strings, arrays) are not read.
+func fitValue(b []byte, bigEndian bool) (uint64, bool) {
+	var order binary.ByteOrder = binary.LittleEndian
+	if bigEndian {
+		order = binary.BigEndian
+	}
+	switch len(b) {
+	case 1:
+		return uint64(b[0]), b[0] != 0xff
+	case 2:
+		v := order.Uint16(b)
+		return uint64(v), v != 0xffff
+	case 4:
+		v := order.Uint32(b)
+		return uint64(v), v != 0xffffffff
+	}
+	return 0, false
+}
+
+func fitTime(value uint64) time.Time {
+	return fitEpoch.Add(time.Duration(value) * time.Second)
+}
@@ -0,0 +1,237 @@
+// Package workout reads activities recorded by watches and bike computers
+// from GPX, TCX and FIT files into a common form: totals, laps and heart-rate
+// samples.
+package workout
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// ErrInvalidFile is returned for files that are not a supported activity
+var ErrInvalidFile = errors.New("invalid activity file")
+
+// Formats
+const (
+	FormatGPX = "gpx"
+	FormatTCX = "tcx"
+	FormatFIT = "fit"
+)
+
+// Sports an activity is classified as
+const (
+	SportRunning  = "running"
+	SportCycling  = "cycling"
+	SportSwimming = "swimming"
+	SportWalking  = "walking"
+	SportHiking   = "hiking"
+	SportRowing   = "rowing"
+	SportOther    = "other"
+)
+
+// Activity is one recorded workout
+type Activity struct {
+	Format    string
+	Sport     string
+	Name      string // as named on the device or app, if at all
+	StartTime time.Time
+	Duration  time.Duration // time moving (timer time), elapsed time without it
+	Distance  float64       // meters
+
+	AvgHeartRate int // bpm, 0 when not recorded
+	MaxHeartRate int
+
+	Laps    []Lap
+	Samples []Sample // heart-rate samples, in time order
+}
+
+// Lap is a lap or, for GPX, a track segment
+type Lap struct {
+	StartTime    time.Time
+	Duration     time.Duration
+	Distance     float64 // meters
+	AvgHeartRate int
+	MaxHeartRate int
+}
+
+// Sample is a heart-rate reading
+type Sample struct {
+	Time      time.Time
+	HeartRate int
+}
+
+// maxSampleGap is the longest a heart-rate sample counts for; longer gaps are
+// pauses
+const maxSampleGap = 30 * time.Second
+
+// Parse reads an activity, telling the format from the content
+func Parse(data []byte) (*Activity, error) {
+	var (
+		activity *Activity
+		err      error
+	)
+	switch {
+	case isFIT(data):
+		activity, err = parseFIT(data)
+	case bytes.Contains(firstBytes(data, 1024), []byte("<TrainingCenterDatabase")):
+		activity, err = parseTCX(data)
+	case bytes.Contains(firstBytes(data, 1024), []byte("<gpx")):
+		activity, err = parseGPX(data)
+	default:
+		return nil, fmt.Errorf("%w: not a GPX, TCX or FIT file", ErrInvalidFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	activity.complete()
+	if activity.StartTime.IsZero() || activity.Duration <= 0 {
+		return nil, fmt.Errorf("%w: the activity has no recorded time", ErrInvalidFile)
+	}
+	return activity, nil
+}
+
+func firstBytes(data []byte, n int) []byte {
+	if len(data) < n {
+		return data
+	}
+	return data[:n]
+}
+
+// complete derives what the file left out from laps and samples
+func (a *Activity) complete() {
+	if a.Sport == "" {
+		a.Sport = SportOther
+	}
+
+	var lapDuration time.Duration
+	var lapDistance float64
+	for i := range a.Laps {
+		lap := &a.Laps[i]
+		lapDuration += lap.Duration
+		lapDistance += lap.Distance
+		if lap.AvgHeartRate == 0 || lap.MaxHeartRate == 0 {
+			avg, peak := heartRateBetween(a.Samples, lap.StartTime, lap.StartTime.Add(lap.Duration))
+			if lap.AvgHeartRate == 0 {
+				lap.AvgHeartRate = avg
+			}
+			if lap.MaxHeartRate == 0 {
+				lap.MaxHeartRate = peak
+			}
+		}
+	}
+	if a.StartTime.IsZero() && len(a.Laps) > 0 {
+		a.StartTime = a.Laps[0].StartTime
+	}
+	if a.Duration <= 0 {
+		a.Duration = lapDuration
+	}
+	if a.Distance <= 0 {
+		a.Distance = lapDistance
+	}
+	if a.AvgHeartRate == 0 || a.MaxHeartRate == 0 {
+		avg, peak := heartRateBetween(a.Samples, time.Time{}, time.Time{})
+		if a.AvgHeartRate == 0 {
+			a.AvgHeartRate = avg
+		}
+		if a.MaxHeartRate == 0 {
+			a.MaxHeartRate = peak
+		}
+	}
+}
+
+// heartRateBetween averages the samples in [from, to), weighting each by the
+// time until the next one. Zero bounds are open.
+func heartRateBetween(samples []Sample, from, to time.Time) (avg, peak int) {
+	var weighted, total float64
+	for i, sample := range samples {
+		if (!from.IsZero() && sample.Time.Before(from)) || (!to.IsZero() && !sample.Time.Before(to)) {
+			continue
+		}
+		if sample.HeartRate > peak {
+			peak = sample.HeartRate
+		}
+		weight := 1.0
+		if i+1 < len(samples) {
+			if gap := samples[i+1].Time.Sub(sample.Time); gap > 0 && gap <= maxSampleGap {
+				weight = gap.Seconds()
+			}
+		}
+		weighted += float64(sample.HeartRate) * weight
+		total += weight
+	}
+	if total == 0 {
+		return 0, peak
+	}
+	return int(math.Round(weighted / total)), peak
+}
+
+// HeartRateZones returns the time spent in the five heart-rate zones, by
+// percentage of maxHeartRate: 50-60, 60-70, 70-80, 80-90 and 90 and above.
+// Time below zone 1 is not counted.
+func (a *Activity) HeartRateZones(maxHeartRate int) [5]time.Duration {
+	var zones [5]time.Duration
+	if maxHeartRate <= 0 {
+		return zones
+	}
+	for i := 0; i+1 < len(a.Samples); i++ {
+		gap := a.Samples[i+1].Time.Sub(a.Samples[i].Time)
+		if gap <= 0 || gap > maxSampleGap {
+			continue
+		}
+		percent := float64(a.Samples[i].HeartRate) * 100 / float64(maxHeartRate)
+		if percent < 50 {
+			continue
+		}
+		zone := int(percent-50) / 10
+		if zone > 4 {
+			zone = 4
+		}
+		zones[zone] += gap
+	}
+	return zones
+}
+
+// Pace returns the time per kilometer, or 0 without a distance
+func (a *Activity) Pace() time.Duration {
+	if a.Distance <= 0 {
+		return 0
+	}
+	return time.Duration(float64(a.Duration) / (a.Distance / 1000))
+}
+
+// normalizeSport classifies the sport names of GPX, TCX and apps
+func normalizeSport(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	switch {
+	case name == "":
+		return SportOther
+	case strings.Contains(name, "run") || name == "9":
+		return SportRunning
+	case strings.Contains(name, "bik") || strings.Contains(name, "cycl") || strings.Contains(name, "ride") || name == "1":
+		return SportCycling
+	case strings.Contains(name, "swim"):
+		return SportSwimming
+	case strings.Contains(name, "walk"):
+		return SportWalking
+	case strings.Contains(name, "hik"):
+		return SportHiking
+	case strings.Contains(name, "row"):
+		return SportRowing
+	}
+	return SportOther
+}
+
+// haversine returns the distance in meters between two coordinates
+func haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadius = 6371008.8
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
@@ -0,0 +1,198 @@
+package workout
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+)
+
+const testGPX = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="Watch" xmlns="http://www.topografix.com/GPX/1/1"
+  xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v1">
+  <metadata><time>2026-03-04T23:30:00Z</time></metadata>
+  <trk>
+    <name>Morning Run</name>
+    <type>running</type>
+    <trkseg>
+      <trkpt lat="13.7300" lon="100.5200"><time>2026-03-04T23:30:00Z</time>
+        <extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>120</gpxtpx:hr></gpxtpx:TrackPointExtension></extensions></trkpt>
+      <trkpt lat="13.7309" lon="100.5200"><time>2026-03-04T23:30:20Z</time>
+        <extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>150</gpxtpx:hr></gpxtpx:TrackPointExtension></extensions></trkpt>
+      <trkpt lat="13.7318" lon="100.5200"><time>2026-03-04T23:30:40Z</time>
+        <extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>170</gpxtpx:hr></gpxtpx:TrackPointExtension></extensions></trkpt>
+    </trkseg>
+    <trkseg>
+      <trkpt lat="13.7318" lon="100.5200"><time>2026-03-04T23:35:00Z</time></trkpt>
+      <trkpt lat="13.7327" lon="100.5200"><time>2026-03-04T23:35:30Z</time></trkpt>
+    </trkseg>
+  </trk>
+</gpx>`
+
+func TestParseGPX(t *testing.T) {
+	activity, err := Parse([]byte(testGPX))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if activity.Format != FormatGPX || activity.Sport != SportRunning || activity.Name != "Morning Run" {
+		t.Errorf("Parse() = %s %s %q", activity.Format, activity.Sport, activity.Name)
+	}
+	if want := time.Date(2026, 3, 4, 23, 30, 0, 0, time.UTC); !activity.StartTime.Equal(want) {
+		t.Errorf("StartTime = %v, want %v", activity.StartTime, want)
+	}
+	// The pause between segments is not counted
+	if activity.Duration != 70*time.Second {
+		t.Errorf("Duration = %v, want 1m10s", activity.Duration)
+	}
+	if len(activity.Laps) != 2 {
+		t.Fatalf("len(Laps) = %d, want 2", len(activity.Laps))
+	}
+	// 0.0009 degrees of latitude is about 100 m
+	if d := activity.Laps[0].Distance; d < 199 || d > 202 {
+		t.Errorf("Laps[0].Distance = %v, want about 200", d)
+	}
+	if d := activity.Distance; d < 299 || d > 302 {
+		t.Errorf("Distance = %v, want about 300", d)
+	}
+	// 120 and 150 for 20 s each; the last sample counts for a second
+	if activity.AvgHeartRate != 136 || activity.MaxHeartRate != 170 {
+		t.Errorf("heart rate = %d avg, %d max, want 136, 170", activity.AvgHeartRate, activity.MaxHeartRate)
+	}
+	if activity.Laps[1].AvgHeartRate != 0 {
+		t.Errorf("Laps[1].AvgHeartRate = %d, want 0", activity.Laps[1].AvgHeartRate)
+	}
+}
+
+const testTCX = `<?xml version="1.0" encoding="UTF-8"?>
+<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2">
+  <Activities>
+    <Activity Sport="Biking">
+      <Id>2026-03-05T10:00:00.000Z</Id>
+      <Lap StartTime="2026-03-05T10:00:00.000Z">
+        <TotalTimeSeconds>600.0</TotalTimeSeconds>
+        <DistanceMeters>5000.0</DistanceMeters>
+        <AverageHeartRateBpm><Value>140</Value></AverageHeartRateBpm>
+        <MaximumHeartRateBpm><Value>160</Value></MaximumHeartRateBpm>
+        <Track>
+          <Trackpoint><Time>2026-03-05T10:00:00Z</Time><HeartRateBpm><Value>130</Value></HeartRateBpm></Trackpoint>
+          <Trackpoint><Time>2026-03-05T10:00:10Z</Time><HeartRateBpm><Value>180</Value></HeartRateBpm></Trackpoint>
+        </Track>
+      </Lap>
+      <Lap StartTime="2026-03-05T10:10:00.000Z">
+        <TotalTimeSeconds>300.5</TotalTimeSeconds>
+        <DistanceMeters>2500.0</DistanceMeters>
+      </Lap>
+    </Activity>
+  </Activities>
+</TrainingCenterDatabase>`
+
+func TestParseTCX(t *testing.T) {
+	activity, err := Parse([]byte(testTCX))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if activity.Format != FormatTCX || activity.Sport != SportCycling {
+		t.Errorf("Parse() = %s %s", activity.Format, activity.Sport)
+	}
+	if activity.Duration != 900500*time.Millisecond || activity.Distance != 7500 {
+		t.Errorf("totals = %v, %v m", activity.Duration, activity.Distance)
+	}
+	if len(activity.Laps) != 2 || activity.Laps[0].AvgHeartRate != 140 || activity.Laps[0].MaxHeartRate != 160 {
+		t.Errorf("Laps = %+v", activity.Laps)
+	}
+	if activity.MaxHeartRate != 180 {
+		t.Errorf("MaxHeartRate = %d, want 180 from the samples", activity.MaxHeartRate)
+	}
+
+	// 130 bpm is 65% of 200 (zone 2), 180 bpm is 90% (zone 5)
+	zones := activity.HeartRateZones(200)
+	if zones != [5]time.Duration{0, 10 * time.Second, 0, 0, 0} {
+		t.Errorf("HeartRateZones(200) = %v", zones)
+	}
+}
+
+// fitWriter builds FIT files for tests
+type fitWriter struct {
+	bytes.Buffer
+}
+
+func (w *fitWriter) define(local byte, global uint16, fields ...[2]byte) {
+	w.WriteByte(0x40 | local)
+	w.Write([]byte{0, 0})
+	binary.Write(w, binary.LittleEndian, global)
+	w.WriteByte(byte(len(fields)))
+	for _, field := range fields {
+		w.Write([]byte{field[0], field[1], 0})
+	}
+}
+
+func (w *fitWriter) data(header byte, values ...interface{}) {
+	w.WriteByte(header)
+	for _, value := range values {
+		binary.Write(w, binary.LittleEndian, value)
+	}
+}
+
+func (w *fitWriter) file() []byte {
+	header := []byte{14, 0x20, 0, 0, 0, 0, 0, 0, '.', 'F', 'I', 'T', 0, 0}
+	binary.LittleEndian.PutUint32(header[4:], uint32(w.Len()))
+	return append(append(header, w.Bytes()...), 0, 0)
+}
+
+func TestParseFIT(t *testing.T) {
+	start := uint32(time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC).Sub(fitEpoch).Seconds())
+
+	var w fitWriter
+	// record: timestamp, heart rate
+	w.define(0, fitMessageRecord, [2]byte{253, 4}, [2]byte{3, 1})
+	w.data(0x00, start, uint8(150))
+	// compressed timestamps (+2 s, +4 s) on local type 3, the second without
+	// heart rate
+	w.define(3, fitMessageRecord, [2]byte{3, 1})
+	w.data(0x80|3<<5|byte((start+2)&0x1f), uint8(160))
+	w.data(0x80|3<<5|byte((start+4)&0x1f), uint8(0xff))
+	// lap: start, timer time, distance, avg and max heart rate
+	w.define(1, fitMessageLap, [2]byte{2, 4}, [2]byte{8, 4}, [2]byte{9, 4}, [2]byte{15, 1}, [2]byte{16, 1})
+	w.data(0x01, start, uint32(240000), uint32(100000), uint8(155), uint8(170))
+	w.data(0x01, start+240, uint32(60000), uint32(0xffffffff), uint8(0xff), uint8(0xff))
+	// session: start, sport, elapsed, timer, distance
+	w.define(2, fitMessageSession, [2]byte{2, 4}, [2]byte{5, 1}, [2]byte{7, 4}, [2]byte{8, 4}, [2]byte{9, 4})
+	w.data(0x02, start, uint8(1), uint32(330000), uint32(300000), uint32(100000))
+
+	activity, err := Parse(w.file())
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if activity.Format != FormatFIT || activity.Sport != SportRunning {
+		t.Errorf("Parse() = %s %s", activity.Format, activity.Sport)
+	}
+	if !activity.StartTime.Equal(fitTime(uint64(start))) || activity.Duration != 5*time.Minute || activity.Distance != 1000 {
+		t.Errorf("totals = %v, %v, %v m", activity.StartTime, activity.Duration, activity.Distance)
+	}
+	if len(activity.Samples) != 2 || activity.Samples[1].HeartRate != 160 || !activity.Samples[1].Time.Equal(fitTime(uint64(start+2))) {
+		t.Errorf("Samples = %+v", activity.Samples)
+	}
+	if len(activity.Laps) != 2 || activity.Laps[0].Distance != 1000 || activity.Laps[1].Duration != time.Minute || activity.Laps[1].Distance != 0 {
+		t.Errorf("Laps = %+v", activity.Laps)
+	}
+	if activity.Pace() != 5*time.Minute {
+		t.Errorf("Pace() = %v, want 5m0s", activity.Pace())
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := map[string][]byte{
+		"csv":          []byte("date,weight\n"),
+		"empty gpx":    []byte(`<gpx><trk><trkseg></trkseg></trk></gpx>`),
+		"no laps":      []byte(`<TrainingCenterDatabase><Activities><Activity Sport="Running"><Id>2026-03-05T10:00:00Z</Id></Activity></Activities></TrainingCenterDatabase>`),
+		"truncated":    (&fitWriter{}).file()[:13],
+		"fit no laps":  (&fitWriter{}).file(),
+		"bad xml time": []byte(`<gpx><trk><trkseg><trkpt lat="1" lon="1"><time>yesterday</time></trkpt></trkseg></trk></gpx>`),
+	}
+	for name, data := range tests {
+		if _, err := Parse(data); !errors.Is(err, ErrInvalidFile) {
+			t.Errorf("%s: Parse() error = %v, want ErrInvalidFile", name, err)
+		}
+	}
+}
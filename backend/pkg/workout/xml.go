@@ -0,0 +1,210 @@
+package workout
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ==========================================
+// GPX
+// ==========================================
+
+type gpxFile struct {
+	Metadata struct {
+		Name string `xml:"name"`
+		Time string `xml:"time"`
+	} `xml:"metadata"`
+	Tracks []struct {
+		Name     string `xml:"name"`
+		Type     string `xml:"type"`
+		Segments []struct {
+			Points []gpxPoint `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+type gpxPoint struct {
+	Lat        float64 `xml:"lat,attr"`
+	Lon        float64 `xml:"lon,attr"`
+	Time       string  `xml:"time"`
+	Extensions struct {
+		// Garmin's TrackPointExtension, or a bare element from other apps
+		TrackPointHeartRate int `xml:"TrackPointExtension>hr"`
+		HeartRate           int `xml:"hr"`
+		HeartRateLong       int `xml:"heartrate"`
+	} `xml:"extensions"`
+}
+
+func (p gpxPoint) heartRate() int {
+	switch {
+	case p.Extensions.TrackPointHeartRate > 0:
+		return p.Extensions.TrackPointHeartRate
+	case p.Extensions.HeartRate > 0:
+		return p.Extensions.HeartRate
+	}
+	return p.Extensions.HeartRateLong
+}
+
+// parseGPX reads the tracks of a GPX file. GPX has no laps, so each track
+// segment is one; time between segments is not counted.
+func parseGPX(data []byte) (*Activity, error) {
+	var file gpxFile
+	if err := decodeXML(data, &file); err != nil {
+		return nil, err
+	}
+
+	activity := &Activity{Format: FormatGPX, Name: strings.TrimSpace(file.Metadata.Name)}
+	for _, track := range file.Tracks {
+		if activity.Name == "" {
+			activity.Name = strings.TrimSpace(track.Name)
+		}
+		if activity.Sport == "" && track.Type != "" {
+			activity.Sport = normalizeSport(track.Type)
+		}
+
+		for _, segment := range track.Segments {
+			var lap Lap
+			var last *gpxPoint
+			var lastTime time.Time
+			for i := range segment.Points {
+				point := &segment.Points[i]
+				at, err := parseXMLTime(point.Time)
+				if err != nil {
+					return nil, err
+				}
+				if at.IsZero() {
+					continue
+				}
+				if lap.StartTime.IsZero() {
+					lap.StartTime = at
+				}
+				if last != nil {
+					lap.Distance += haversine(last.Lat, last.Lon, point.Lat, point.Lon)
+					lap.Duration = at.Sub(lap.StartTime)
+				}
+				if hr := point.heartRate(); hr > 0 && at.After(lastTime) {
+					activity.Samples = append(activity.Samples, Sample{Time: at, HeartRate: hr})
+				}
+				last, lastTime = point, at
+			}
+			if lap.Duration > 0 {
+				activity.Laps = append(activity.Laps, lap)
+			}
+		}
+	}
+	if len(activity.Laps) == 0 {
+		return nil, fmt.Errorf("%w: no timed track points", ErrInvalidFile)
+	}
+	return activity, nil
+}
+
+// ==========================================
+// TCX
+// ==========================================
+
+type tcxFile struct {
+	Activities []struct {
+		Sport string `xml:"Sport,attr"`
+		ID    string `xml:"Id"`
+		Notes string `xml:"Notes"`
+		Laps  []struct {
+			StartTime        string  `xml:"StartTime,attr"`
+			TotalTimeSeconds float64 `xml:"TotalTimeSeconds"`
+			DistanceMeters   float64 `xml:"DistanceMeters"`
+			AverageHeartRate int     `xml:"AverageHeartRateBpm>Value"`
+			MaximumHeartRate int     `xml:"MaximumHeartRateBpm>Value"`
+			Tracks           []struct {
+				Points []struct {
+					Time      string `xml:"Time"`
+					HeartRate int    `xml:"HeartRateBpm>Value"`
+				} `xml:"Trackpoint"`
+			} `xml:"Track"`
+		} `xml:"Lap"`
+	} `xml:"Activities>Activity"`
+}
+
+// parseTCX reads the first activity of a TCX file; multisport files keep
+// their other legs out
+func parseTCX(data []byte) (*Activity, error) {
+	var file tcxFile
+	if err := decodeXML(data, &file); err != nil {
+		return nil, err
+	}
+	if len(file.Activities) == 0 {
+		return nil, fmt.Errorf("%w: no activity", ErrInvalidFile)
+	}
+	source := file.Activities[0]
+
+	activity := &Activity{
+		Format: FormatTCX,
+		Sport:  normalizeSport(source.Sport),
+		Name:   strings.TrimSpace(source.Notes),
+	}
+	start, err := parseXMLTime(source.ID)
+	if err != nil {
+		return nil, err
+	}
+	activity.StartTime = start
+
+	for _, sourceLap := range source.Laps {
+		lapStart, err := parseXMLTime(sourceLap.StartTime)
+		if err != nil {
+			return nil, err
+		}
+		activity.Laps = append(activity.Laps, Lap{
+			StartTime:    lapStart,
+			Duration:     time.Duration(sourceLap.TotalTimeSeconds * float64(time.Second)),
+			Distance:     sourceLap.DistanceMeters,
+			AvgHeartRate: sourceLap.AverageHeartRate,
+			MaxHeartRate: sourceLap.MaximumHeartRate,
+		})
+		for _, track := range sourceLap.Tracks {
+			for _, point := range track.Points {
+				at, err := parseXMLTime(point.Time)
+				if err != nil {
+					return nil, err
+				}
+				if point.HeartRate > 0 && !at.IsZero() {
+					activity.Samples = append(activity.Samples, Sample{Time: at, HeartRate: point.HeartRate})
+				}
+			}
+		}
+	}
+	if len(activity.Laps) == 0 {
+		return nil, fmt.Errorf("%w: the activity has no laps", ErrInvalidFile)
+	}
+	return activity, nil
+}
+
+func decodeXML(data []byte, v interface{}) error {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	// Timestamps and numbers are ASCII, whatever the declared encoding
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	return nil
+}
+
+// parseXMLTime reads an xsd:dateTime; an empty value is the zero time
+func parseXMLTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		// Some exporters leave out the zone; those times are UTC
+		if t, err = time.Parse("2006-01-02T15:04:05", value); err != nil {
+			return time.Time{}, fmt.Errorf("%w: unrecognised time %q", ErrInvalidFile, value)
+		}
+	}
+	return t.UTC(), nil
+}
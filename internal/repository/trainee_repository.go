@@ -421,6 +421,8 @@ type TraineeStats struct {
 	CancelledSessions       int            `json:"cancelledSessions"`
 	CurrentStreak           int            `json:"currentStreak"`
 	LongestStreak           int            `json:"longestStreak"`
+	CurrentWeeklyStreak     int            `json:"currentWeeklyStreak"`
+	LongestWeeklyStreak     int            `json:"longestWeeklyStreak"`
 	TotalWorkoutHours       float64        `json:"totalWorkoutHours"`
 	AverageSessionsPerWeek  float64        `json:"averageSessionsPerWeek"`
 	CurrentProgram          *ProgramSummary `json:"currentProgram"`
@@ -494,6 +496,12 @@ func (r *TraineeRepository) GetTraineeStats(clientID int) (*TraineeStats, error)
 	stats.TotalWorkoutHours = totalMinutes.Float64 / 60.0
 
 	// 6. Current Program
+	var (
+		programID, currentWeek, totalWeeks int
+		programName                        string
+		sessionsCompleted                  int
+		programTotalCount                  sql.NullInt64 // total_sessions is nullable
+	)
 	err = r.db.QueryRow(`
 		SELECT 
 			id, 
@@ -507,21 +515,154 @@ func (r *TraineeRepository) GetTraineeStats(clientID int) (*TraineeStats, error)
 		ORDER BY start_date DESC
 		LIMIT 1
 	`, clientID).Scan(
-		&stats.CurrentProgram.ID,
-		&stats.CurrentProgram.Name,
-		&stats.CurrentProgram.CurrentWeek,
-		&stats.CurrentProgram.TotalWeeks,
-		// Calculate progress
+		&programID,
+		&programName,
+		&currentWeek,
+		&totalWeeks,
+		&sessionsCompleted,
+		&programTotalCount,
 	)
 	if err != nil && err != sql.ErrNoRows {
 		return nil, err
 	}
+	if err == nil {
+		program := &ProgramSummary{
+			ID:          programID,
+			Name:        programName,
+			CurrentWeek: currentWeek,
+			TotalWeeks:  totalWeeks,
+		}
+		if programTotalCount.Valid && programTotalCount.Int64 > 0 {
+			program.ProgressPercentage = float64(sessionsCompleted) / float64(programTotalCount.Int64) * 100
+		}
+		stats.CurrentProgram = program
+	}
+
+	// 7. Recent Achievements
+	stats.RecentAchievements = []Achievement{}
+	rows, err := r.db.Query(`
+		SELECT id, title, achieved_at, COALESCE(badge, '')
+		FROM achievements
+		WHERE client_id = $1
+		ORDER BY achieved_at DESC
+		LIMIT 5
+	`, clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-	// 7. Recent Achievements (ถ้ามี achievements table)
-	// TODO: ดึงจาก achievements table
+	for rows.Next() {
+		var a Achievement
+		var achievedAt time.Time
+		if err := rows.Scan(&a.ID, &a.Title, &achievedAt, &a.Badge); err != nil {
+			return nil, err
+		}
+		a.Date = achievedAt.Format("2006-01-02")
+		stats.RecentAchievements = append(stats.RecentAchievements, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
-	// 8. Calculate Streaks (ต้องเขียน algorithm ตรวจสอบวันติดต่อกัน)
-	// TODO: Implement streak calculation
+	// 8. Streaks & Average Sessions per Week (วันที่ของ session ที่ completed)
+	dayRows, err := r.db.Query(`
+		SELECT start_time::date
+		FROM schedules
+		WHERE client_id = $1 AND status = 'completed'
+		ORDER BY 1
+	`, clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer dayRows.Close()
+
+	var sessionDates []time.Time
+	for dayRows.Next() {
+		var day time.Time
+		if err := dayRows.Scan(&day); err != nil {
+			return nil, err
+		}
+		sessionDates = append(sessionDates, day)
+	}
+	if err := dayRows.Err(); err != nil {
+		return nil, err
+	}
+
+	applyStreaks(stats, sessionDates, time.Now())
 
 	return stats, nil
 }
+
+// applyStreaks fills the streak fields and average sessions per ISO week from
+// the ascending dates of completed sessions (one entry per session), as of
+// today. A daily streak is still current if the last session was today or
+// yesterday; the week in progress never breaks a weekly streak. Mirrors the
+// backend's stats engine with a weekly target of one session; this module
+// cannot import it.
+func applyStreaks(stats *TraineeStats, sessions []time.Time, today time.Time) {
+	if len(sessions) == 0 {
+		return
+	}
+
+	dateOnly := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	weekStart := func(t time.Time) time.Time {
+		offset := (int(t.Weekday()) + 6) % 7
+		return t.AddDate(0, 0, -offset)
+	}
+	today = dateOnly(today)
+
+	// Daily
+	run := 0
+	var prev time.Time
+	for i, d := range sessions {
+		d = dateOnly(d)
+		if i > 0 && d.Equal(prev) {
+			continue
+		}
+		if i > 0 && d.Equal(prev.AddDate(0, 0, 1)) {
+			run++
+		} else {
+			run = 1
+		}
+		if run > stats.LongestStreak {
+			stats.LongestStreak = run
+		}
+		prev = d
+	}
+	if !prev.Before(today.AddDate(0, 0, -1)) {
+		stats.CurrentStreak = run
+	}
+
+	// Weekly
+	run = 0
+	var prevWeek time.Time
+	for i, d := range sessions {
+		w := weekStart(dateOnly(d))
+		if i > 0 && w.Equal(prevWeek) {
+			continue
+		}
+		if i > 0 && w.Equal(prevWeek.AddDate(0, 0, 7)) {
+			run++
+		} else {
+			run = 1
+		}
+		if run > stats.LongestWeeklyStreak {
+			stats.LongestWeeklyStreak = run
+		}
+		prevWeek = w
+	}
+	if !prevWeek.Before(weekStart(today).AddDate(0, 0, -7)) {
+		stats.CurrentWeeklyStreak = run
+	}
+
+	// Weeks from the first session's week through the current one
+	first := weekStart(dateOnly(sessions[0]))
+	weeks := int(weekStart(today).Sub(first).Hours()/(24*7)) + 1
+	if weeks < 1 {
+		weeks = 1
+	}
+	stats.AverageSessionsPerWeek = float64(len(sessions)) / float64(weeks)
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
@@ -275,6 +276,19 @@ func TestGetTraineeStats_Success(t *testing.T) {
 		WithArgs(1).
 		WillReturnError(sql.ErrNoRows)
 
+	// Mock recent achievements
+	mock.ExpectQuery("SELECT id, title, achieved_at, COALESCE\\(badge, ''\\) FROM achievements").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "achieved_at", "badge"}).
+			AddRow(3, "New PR: Squat", time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC), "🏆"))
+
+	// Mock completed session dates
+	mock.ExpectQuery("SELECT start_time::date FROM schedules WHERE client_id = \\$1 AND status = 'completed'").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"start_time"}).
+			AddRow(time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)).
+			AddRow(time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)))
+
 	// Execute
 	stats, err := repo.GetTraineeStats(1)
 
@@ -286,6 +300,57 @@ func TestGetTraineeStats_Success(t *testing.T) {
 	assert.Equal(t, 6, stats.UpcomingSessions)
 	assert.Equal(t, 20, stats.CancelledSessions)
 	assert.Equal(t, 147.5, stats.TotalWorkoutHours)
+	assert.Nil(t, stats.CurrentProgram)
+	assert.Len(t, stats.RecentAchievements, 1)
+	assert.Equal(t, "2024-03-10", stats.RecentAchievements[0].Date)
+	assert.Equal(t, 2, stats.LongestStreak)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestGetTraineeStats_ProgramWithoutTotal
+func TestGetTraineeStats_ProgramWithoutTotal(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewTraineeRepository(db)
+
+	for _, query := range []string{
+		"SELECT COUNT\\(\\*\\) FROM schedules WHERE client_id = \\$1",
+		"SELECT COUNT\\(\\*\\) FROM schedules WHERE client_id = \\$1 AND status = 'completed'",
+		"SELECT COUNT\\(\\*\\) FROM schedules WHERE client_id = \\$1 AND start_time >= CURRENT_TIMESTAMP",
+		"SELECT COUNT\\(\\*\\) FROM schedules WHERE client_id = \\$1 AND status = 'cancelled'",
+	} {
+		mock.ExpectQuery(query).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	}
+	mock.ExpectQuery("SELECT SUM\\(duration\\) FROM schedules WHERE client_id = \\$1 AND status = 'completed'").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(nil))
+
+	// total_sessions is nullable
+	mock.ExpectQuery("SELECT id, name, current_week, duration_weeks, sessions_completed, total_sessions FROM programs").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "current_week", "duration_weeks", "sessions_completed", "total_sessions"}).
+			AddRow(7, "Strength Base", 2, 8, 3, nil))
+
+	mock.ExpectQuery("SELECT id, title, achieved_at, COALESCE\\(badge, ''\\) FROM achievements").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "achieved_at", "badge"}))
+
+	mock.ExpectQuery("SELECT start_time::date FROM schedules WHERE client_id = \\$1 AND status = 'completed'").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"start_time"}))
+
+	stats, err := repo.GetTraineeStats(1)
+
+	assert.NoError(t, err)
+	if assert.NotNil(t, stats.CurrentProgram) {
+		assert.Equal(t, "Strength Base", stats.CurrentProgram.Name)
+		assert.Equal(t, 0.0, stats.CurrentProgram.ProgressPercentage)
+	}
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -325,6 +390,14 @@ func TestGetTraineeStats_NoData(t *testing.T) {
 		WithArgs(1).
 		WillReturnError(sql.ErrNoRows)
 
+	mock.ExpectQuery("SELECT id, title, achieved_at, COALESCE\\(badge, ''\\) FROM achievements").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "achieved_at", "badge"}))
+
+	mock.ExpectQuery("SELECT start_time::date FROM schedules WHERE client_id = \\$1 AND status = 'completed'").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"start_time"}))
+
 	// Execute
 	stats, err := repo.GetTraineeStats(1)
 
@@ -332,7 +405,36 @@ func TestGetTraineeStats_NoData(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, stats)
 	assert.Equal(t, 0, stats.TotalSessions)
+	assert.Equal(t, 0, stats.CurrentStreak)
+	assert.Empty(t, stats.RecentAchievements)
 	assert.Equal(t, 0.0, stats.TotalWorkoutHours)
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+// TestApplyStreaks
+func TestApplyStreaks(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 18, 0, 0, 0, time.UTC) }
+	today := day(20) // Wednesday
+
+	stats := &TraineeStats{}
+	applyStreaks(stats, []time.Time{
+		day(1), day(2), day(3), // Fri-Sun: 3-day run
+		day(5),           // Tue
+		day(12), day(12), // two sessions on Tue
+		day(18), day(19), // Mon-Tue, streak still current
+	}, today)
+
+	assert.Equal(t, 2, stats.CurrentStreak)
+	assert.Equal(t, 3, stats.LongestStreak)
+	// Weeks of Feb 26, Mar 4, Mar 11, Mar 18 are all covered
+	assert.Equal(t, 4, stats.CurrentWeeklyStreak)
+	assert.Equal(t, 4, stats.LongestWeeklyStreak)
+	assert.Equal(t, 2.0, stats.AverageSessionsPerWeek)
+
+	stats = &TraineeStats{}
+	applyStreaks(stats, []time.Time{day(1)}, today)
+	assert.Equal(t, 0, stats.CurrentStreak)
+	assert.Equal(t, 1, stats.LongestStreak)
+	assert.Equal(t, 0, stats.CurrentWeeklyStreak)
+}